@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// A project to parse in --batch mode
+type batchProject struct {
+	Files            []string `json:"files"`
+	ProjectName      string   `json:"projectName"`
+	ProjectDirectory string   `json:"projectDirectory,omitempty"`
+}
+
+// A line of --batch output. Results are streamed as projects finish parsing,
+// so Index identifies the project in the manifest.
+type batchResult struct {
+	Index         int                                `json:"index"`
+	ProjectName   string                             `json:"projectName"`
+	Project       json.RawMessage                    `json:"project,omitempty"`
+	Warnings      []parser.Warning                   `json:"warnings,omitempty"`
+	EnvResolution []parser.VariableResolution        `json:"env_resolution,omitempty"`
+	Overrides     []parser.Override                  `json:"overrides,omitempty"`
+	Features      map[string][]parser.Feature        `json:"features,omitempty"`
+	Contract      *parser.Contract                   `json:"contract,omitempty"`
+	Builds        map[string]parser.Build            `json:"builds,omitempty"`
+	GPU           map[string][]parser.GPURequest     `json:"gpu,omitempty"`
+	Policy        map[string][]parser.PolicyDecision `json:"policy,omitempty"`
+	Defaults      map[string][]parser.DefaultedField `json:"defaults,omitempty"`
+	Error         *parser.ErrorResponse              `json:"error,omitempty"`
+}
+
+// Read a batch manifest, a JSON array of {"files": [...], "projectName": "..."}, from a path or "-" for stdin
+func readBatchManifest(manifestPath string) ([]batchProject, error) {
+	var r io.Reader = os.Stdin
+	if manifestPath != parser.StdinPath {
+		f, err := os.Open(manifestPath)
+		if err != nil {
+			return nil, &parser.Error{Name: parser.IOError, Message: fmt.Sprintf("Failed to open batch manifest: %v", err), Err: err}
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var projects []batchProject
+	if err := json.NewDecoder(r).Decode(&projects); err != nil {
+		return nil, &parser.Error{Name: parser.ArgumentError, Message: fmt.Sprintf("Invalid batch manifest: %v", err), Err: err}
+	}
+	for i, project := range projects {
+		// Stdin is either the manifest or unavailable, so can't be a compose file
+		if slices.Contains(project.Files, parser.StdinPath) {
+			return nil, &parser.Error{Name: parser.ArgumentError, Message: fmt.Sprintf("Invalid batch manifest: project %d reads from stdin, which isn't supported in --batch mode", i)}
+		}
+	}
+	return projects, nil
+}
+
+// Parse the projects with the given concurrency, writing one NDJSON result line per project to w.
+// Returns the number of projects which failed to parse.
+func runBatch(projects []batchProject, concurrency int, w io.Writer, options parser.Options, canonical bool) int {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	failed := 0
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, project := range projects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			output := batchResult{Index: i, ProjectName: project.ProjectName}
+			projectOptions := options
+			projectOptions.ProjectName = project.ProjectName
+			projectOptions.ProjectDirectory = project.ProjectDirectory
+			projectJSON, result, err := parseBatchProject(parser.New(projectOptions), project.Files, canonical)
+			if err != nil {
+				output.Error = parser.NewErrorResponse(err)
+			} else {
+				output.Project, output.Warnings, output.EnvResolution, output.Overrides = projectJSON, result.Warnings, result.EnvResolution, result.Overrides
+				output.Features, output.Contract, output.Builds, output.Policy, output.Defaults = result.Features, result.Contract, result.Builds, result.Policy, result.Defaults
+				output.GPU = result.GPU
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if output.Error != nil {
+				failed++
+			}
+			encoder.Encode(output)
+		}()
+	}
+	wg.Wait()
+	return failed
+}
+
+func parseBatchProject(p *parser.Parser, composeFiles []string, canonical bool) ([]byte, *parser.Result, error) {
+	result, err := p.Parse(context.Background(), composeFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := marshalProject(result.Project, formatJSON, canonical)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to marshal compose project to JSON: %w", err)
+	}
+	return output, result, nil
+}
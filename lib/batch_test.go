@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Decode NDJSON batch results by index
+func batchResults(t *testing.T, output []byte) map[int]batchResult {
+	t.Helper()
+	results := map[int]batchResult{}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var result batchResult
+		if err := decoder.Decode(&result); err != nil {
+			t.Fatalf("expected NDJSON results, got %s: %v", output, err)
+		}
+		results[result.Index] = result
+	}
+	return results
+}
+
+func TestRunBatch(t *testing.T) {
+	dir := t.TempDir()
+	web := writeFile(t, dir, "web/compose.yml", "services:\n  web:\n    image: nginx\n    env_file: web.env\n")
+	writeFile(t, dir, "web/web.env", "FROM_ENV_FILE=true\n")
+	db := writeFile(t, dir, "db.yml", "services:\n  db:\n    image: postgres\n    cap_add: [SYS_ADMIN, NET_ADMIN]\n")
+	invalid := writeFile(t, dir, "invalid.yml", "services: [\n")
+
+	projects := []batchProject{
+		{Files: []string{web}, ProjectName: "web"},
+		{Files: []string{db}, ProjectName: "db"},
+		{Files: []string{invalid}, ProjectName: "invalid"},
+		{Files: []string{dir + "/missing.yml"}, ProjectName: "missing"},
+	}
+	for _, concurrency := range []int{1, 4} {
+		var output bytes.Buffer
+		if failed := runBatch(projects, concurrency, &output, parser.Options{}, true); failed != 2 {
+			t.Errorf("expected 2 projects to fail with concurrency %d, got %d", concurrency, failed)
+		}
+		results := batchResults(t, output.Bytes())
+		if len(results) != len(projects) {
+			t.Fatalf("expected a result for each of the %d projects, got %s", len(projects), output.String())
+		}
+		for i, project := range projects {
+			if results[i].ProjectName != project.ProjectName {
+				t.Errorf("expected result %d to be of %s, got %+v", i, project.ProjectName, results[i])
+			}
+		}
+
+		var webProject map[string]any
+		if err := json.Unmarshal(results[0].Project, &webProject); err != nil || lookup(webProject, "services.web.environment.FROM_ENV_FILE") != "true" {
+			t.Errorf("expected web to be parsed from its own directory, got %s: %v", results[0].Project, err)
+		}
+		if !strings.Contains(string(results[1].Project), `"cap_add":["NET_ADMIN","SYS_ADMIN"]`) {
+			t.Errorf("expected the canonical db project, got %s", results[1].Project)
+		}
+		for _, i := range []int{2, 3} {
+			if results[i].Error == nil || results[i].Project != nil {
+				t.Errorf("expected result %d to be an error, got %+v", i, results[i])
+			}
+		}
+		if results[3].Error != nil && results[3].Error.Name != parser.IOError {
+			t.Errorf("expected an IOError for the missing file, got %+v", results[3].Error)
+		}
+	}
+}
+
+func TestReadBatchManifest(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		manifest string
+		errName  string
+		message  string
+	}{
+		{name: "manifest", manifest: `[{"files": ["compose.yml"], "projectName": "p", "projectDirectory": "app"}]`},
+		{name: "invalid", manifest: `{"files": []}`, errName: parser.ArgumentError, message: "Invalid batch manifest"},
+		{name: "stdin", manifest: `[{"files": ["compose.yml"]}, {"files": ["-"]}]`, errName: parser.ArgumentError, message: "project 1 reads from stdin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projects, err := readBatchManifest(writeFile(t, dir, tt.name+".json", tt.manifest))
+			if tt.errName == "" {
+				expected := []batchProject{{Files: []string{"compose.yml"}, ProjectName: "p", ProjectDirectory: "app"}}
+				if err != nil || !reflect.DeepEqual(projects, expected) {
+					t.Errorf("expected %+v, got %+v: %v", expected, projects, err)
+				}
+				return
+			}
+			var parserErr *parser.Error
+			if !errors.As(err, &parserErr) || parserErr.Name != tt.errName || !strings.Contains(parserErr.Message, tt.message) {
+				t.Errorf("expected a %s containing %q, got %v", tt.errName, tt.message, err)
+			}
+		})
+	}
+
+	_, err := readBatchManifest(dir + "/missing.json")
+	if response := parser.NewErrorResponse(err); response.Name != parser.IOError {
+		t.Errorf("expected an IOError for a missing manifest, got %+v", response)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	dir := t.TempDir()
+	web := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n")
+	manifest, _ := json.Marshal([]batchProject{{Files: []string{web}, ProjectName: "web"}, {Files: []string{web}, ProjectName: "other"}})
+
+	result := runCLI(t, string(manifest), "--batch", "-", "--batch-concurrency", "2")
+	if result.code != 0 {
+		t.Fatalf("expected batch mode to succeed, got %d: %s", result.code, result.stderr)
+	}
+	results := batchResults(t, []byte(result.stdout))
+	if len(results) != 2 || results[0].ProjectName != "web" || results[1].ProjectName != "other" {
+		t.Errorf("expected a result line per project, got %s", result.stdout)
+	}
+
+	failing, _ := json.Marshal([]batchProject{{Files: []string{web}, ProjectName: "web"}, {Files: []string{dir + "/missing.yml"}, ProjectName: "missing"}})
+	runCLI(t, string(failing), "--batch", "-").expectError(t, parser.ParseError, "1 of 2 projects failed to parse")
+
+	runCLI(t, "[]", "--batch", "-", "-f", web).expectError(t, parser.ArgumentError, "must be provided per project in the manifest")
+	runCLI(t, "[]", "--batch", "-", "p").expectError(t, parser.ArgumentError, "must be provided per project in the manifest")
+	runCLI(t, "[]", "--batch", "-", "--output-format", "yaml").expectError(t, parser.ArgumentError, "--batch only supports JSON output")
+	runCLI(t, "[]", "--batch", "-", "--batch-concurrency", "0").expectError(t, parser.ArgumentError, "Timeout and batch concurrency must be positive")
+}
@@ -0,0 +1,93 @@
+// C shared library build of the parser, so it can be called via N-API/FFI instead of as a subprocess:
+//
+//	char* ParseCompose(char* request);
+//	void FreeResult(char* result);
+//
+// The request is a JSON object {"files": [...], "projectName": "...", "timeout": "10s", "canonical": false}.
+// The result is either the project JSON, or a JSON error response with "error": true, and must be
+// released with FreeResult.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/sirupsen/logrus"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+type parseRequest struct {
+	Files       []string `json:"files"`
+	ProjectName string   `json:"projectName"`
+	// Timeout as a duration string, e.g. "30s", defaulting to parser.DefaultTimeout
+	Timeout   string `json:"timeout,omitempty"`
+	Canonical bool   `json:"canonical,omitempty"`
+}
+
+func init() {
+	// Format logs outputted from compose-go to JSON
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  "time",
+			logrus.FieldKeyLevel: "level",
+			logrus.FieldKeyMsg:   "message",
+		},
+	})
+}
+
+//export ParseCompose
+func ParseCompose(request *C.char) *C.char {
+	output, err := parseCompose(C.GoString(request))
+	if err != nil {
+		return C.CString(errorJSON(err))
+	}
+	return C.CString(string(output))
+}
+
+//export FreeResult
+func FreeResult(result *C.char) {
+	C.free(unsafe.Pointer(result))
+}
+
+func parseCompose(requestJSON string) ([]byte, error) {
+	var request parseRequest
+	if err := json.Unmarshal([]byte(requestJSON), &request); err != nil {
+		return nil, &parser.Error{Name: parser.ArgumentError, Message: fmt.Sprintf("Invalid request: %v", err), Err: err}
+	}
+
+	var timeout time.Duration
+	if request.Timeout != "" {
+		parsed, err := time.ParseDuration(request.Timeout)
+		if err != nil {
+			return nil, &parser.Error{Name: parser.ArgumentError, Message: fmt.Sprintf("Invalid timeout %q: %v", request.Timeout, err), Err: err}
+		}
+		timeout = parsed
+	}
+
+	result, err := parser.New(parser.Options{
+		ProjectName: request.ProjectName,
+		Timeout:     timeout,
+	}).Parse(context.Background(), request.Files)
+	if err != nil {
+		return nil, err
+	}
+	return parser.MarshalProject(result.Project, request.Canonical)
+}
+
+// Encode an error as a JSON error response
+func errorJSON(err error) string {
+	encoded, _ := json.Marshal(parser.NewErrorResponse(err))
+	return string(encoded)
+}
+
+// Required for -buildmode=c-shared
+func main() {}
@@ -0,0 +1,54 @@
+//go:build cgo
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestParseCompose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "compose.yml")
+	if err := os.WriteFile(path, []byte("services:\n  web:\n    image: nginx\n    cap_add: [SYS_ADMIN, NET_ADMIN]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	request, _ := json.Marshal(parseRequest{Files: []string{path}, ProjectName: "p", Timeout: "1m", Canonical: true})
+	output, err := parseCompose(string(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(output), `"cap_add":["NET_ADMIN","SYS_ADMIN"]`) {
+		t.Errorf("expected the canonical project, got %s", output)
+	}
+}
+
+func TestParseComposeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		errName string
+		message string
+	}{
+		{name: "invalid request", request: `{"files":`, errName: parser.ArgumentError, message: "Invalid request"},
+		{name: "invalid timeout", request: `{"files": ["compose.yml"], "projectName": "p", "timeout": "soon"}`, errName: parser.ArgumentError, message: "Invalid timeout \"soon\""},
+		{name: "no compose files", request: `{"projectName": "p"}`, errName: parser.ArgumentError, message: "At least one compose file must be specified"},
+		{name: "missing file", request: `{"files": ["missing.yml"], "projectName": "p"}`, errName: parser.IOError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCompose(tt.request)
+			var response parser.ErrorResponse
+			if err == nil || json.Unmarshal([]byte(errorJSON(err)), &response) != nil {
+				t.Fatalf("expected an error response, got %v", err)
+			}
+			if !response.Error || response.Name != tt.errName || !strings.Contains(response.Message, tt.message) {
+				t.Errorf("expected a %s containing %q, got %+v", tt.errName, tt.message, response)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+//go:build js && wasm
+
+// WebAssembly build of the parser, which registers a global parseCompose function so
+// the parser can run in-process in Node instead of as a subprocess.
+//
+//	parseCompose(files: Array<string | { name: string, content: string }>, projectName: string): Promise<string>
+//
+// Files are either paths, read via Node's fs, or in-memory compose files. The returned promise
+// resolves to the project JSON, or rejects with an Error whose message is the JSON error response.
+// As with wasm_exec_node.js, globalThis.fs must be set to Node's fs module before instantiating.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"github.com/sirupsen/logrus"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func main() {
+	// Format logs outputted from compose-go to JSON
+	logrus.SetFormatter(&logrus.JSONFormatter{
+		FieldMap: logrus.FieldMap{
+			logrus.FieldKeyTime:  "time",
+			logrus.FieldKeyLevel: "level",
+			logrus.FieldKeyMsg:   "message",
+		},
+	})
+
+	js.Global().Set("parseCompose", js.FuncOf(parseCompose))
+
+	// Keep the Go runtime alive so parseCompose remains callable
+	select {}
+}
+
+func parseCompose(_ js.Value, args []js.Value) any {
+	promise := js.Global().Get("Promise")
+	var executor js.Func
+	executor = js.FuncOf(func(_ js.Value, promiseArgs []js.Value) any {
+		resolve, reject := promiseArgs[0], promiseArgs[1]
+
+		// Parsing blocks on Node's async fs callbacks, so must not run on the calling goroutine
+		go func() {
+			// Each call's executor is released once settled, so calls don't leak functions
+			defer executor.Release()
+			output, err := parse(args)
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(errorJSON(err)))
+				return
+			}
+			resolve.Invoke(string(output))
+		}()
+		return nil
+	})
+	return promise.New(executor)
+}
+
+// Parse the compose files given as arguments to parseCompose
+func parse(args []js.Value) ([]byte, error) {
+	if len(args) != 2 || args[0].Type() != js.TypeObject || args[1].Type() != js.TypeString {
+		return nil, &parser.Error{Name: parser.ArgumentError, Message: "Usage: parseCompose(files, projectName)"}
+	}
+	jsFiles, projectName := args[0], args[1].String()
+
+	var paths []string
+	var files []parser.File
+	for i := 0; i < jsFiles.Length(); i++ {
+		file := jsFiles.Index(i)
+		switch file.Type() {
+		case js.TypeString:
+			paths = append(paths, file.String())
+		case js.TypeObject:
+			files = append(files, parser.File{Name: file.Get("name").String(), Content: []byte(file.Get("content").String())})
+		default:
+			return nil, &parser.Error{Name: parser.ArgumentError, Message: fmt.Sprintf("Invalid compose file at index %d, expected a path or {name, content}", i)}
+		}
+	}
+	if len(paths) > 0 && len(files) > 0 {
+		return nil, &parser.Error{Name: parser.ArgumentError, Message: "Compose file paths and in-memory compose files cannot be mixed"}
+	}
+
+	p := parser.New(parser.Options{ProjectName: projectName})
+	var result *parser.Result
+	var err error
+	if len(files) > 0 {
+		result, err = p.ParseContent(context.Background(), files)
+	} else {
+		result, err = p.Parse(context.Background(), paths)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parser.MarshalProject(result.Project, false)
+}
+
+// Encode an error as a JSON error response
+func errorJSON(err error) string {
+	encoded, _ := json.Marshal(parser.NewErrorResponse(err))
+	return string(encoded)
+}
@@ -0,0 +1,81 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestParse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "compose.yml")
+	if err := os.WriteFile(path, []byte("services:\n  web:\n    image: nginx\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		files []any
+	}{
+		{name: "paths", files: []any{path}},
+		{name: "in-memory files", files: []any{map[string]any{"name": "compose.yml", "content": "services:\n  web:\n    image: nginx\n"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := parse([]js.Value{js.ValueOf(tt.files), js.ValueOf("p")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var project struct {
+				Name     string
+				Services map[string]struct{ Image string }
+			}
+			if err := json.Unmarshal(output, &project); err != nil {
+				t.Fatalf("expected the project JSON, got %s", output)
+			}
+			if project.Name != "p" || project.Services["web"].Image != "nginx" {
+				t.Errorf("expected the project p of nginx, got %s", output)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []any
+		errName string
+		message string
+	}{
+		{name: "no project name", args: []any{[]any{"compose.yml"}}, errName: parser.ArgumentError, message: "Usage: parseCompose(files, projectName)"},
+		{name: "invalid file", args: []any{[]any{1}, "p"}, errName: parser.ArgumentError, message: "Invalid compose file at index 0"},
+		{
+			name:    "mixed files",
+			args:    []any{[]any{"compose.yml", map[string]any{"name": "override.yml", "content": "services: {}\n"}}, "p"},
+			errName: parser.ArgumentError,
+			message: "cannot be mixed",
+		},
+		{name: "missing file", args: []any{[]any{"missing.yml"}, "p"}, errName: parser.IOError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args []js.Value
+			for _, arg := range tt.args {
+				args = append(args, js.ValueOf(arg))
+			}
+			_, err := parse(args)
+			var response parser.ErrorResponse
+			if err == nil || json.Unmarshal([]byte(errorJSON(err)), &response) != nil {
+				t.Fatalf("expected an error response, got %v", err)
+			}
+			if !response.Error || response.Name != tt.errName || !strings.Contains(response.Message, tt.message) {
+				t.Errorf("expected a %s containing %q, got %+v", tt.errName, tt.message, response)
+			}
+		})
+	}
+}
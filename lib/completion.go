@@ -0,0 +1,282 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Name of the binary that completion scripts and the man page are generated for
+const commandName = "balena-compose-parser"
+
+// Shells which completion scripts can be generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// Flags whose value is a local file path
+var fileFlags = []string{"f", "o", "tar", "https-ca-cert", "batch", "env-file", "contract", "defaults", "schema", "config", "allow-mount"}
+
+// Allowed values of flags which only accept a fixed set
+var flagValues = map[string][]string{
+	"output-format": outputFormats,
+	"output-schema": parser.TargetStateSchemas,
+	"log-level":     logLevels(),
+	"target":        parser.Targets,
+	"device-type":   parser.DeviceTypes,
+	"arch":          parser.Architectures,
+	"policy":        parser.Policies,
+	"enable":        lintRuleIDs(),
+	"disable":       lintRuleIDs(),
+	"fail-on":       failOnConditions,
+	"report-format": reportFormats,
+}
+
+// commandFlag describes a flag for completion scripts and the man page
+type commandFlag struct {
+	// Name is the flag as written on the command line, e.g. "-f" or "--timeout"
+	Name string
+	// Arg is the name of the flag's value, or "" for boolean flags
+	Arg string
+	// Usage is the one-line description of the flag
+	Usage string
+	// DefValue is the default value as text
+	DefValue string
+	// Values are the allowed values, if the flag only accepts a fixed set
+	Values []string
+	// Files is whether the value is a local file path
+	Files bool
+}
+
+// Describe the flags of a flag set in name order
+func commandFlags(flags *flag.FlagSet) []commandFlag {
+	var result []commandFlag
+	flags.VisitAll(func(f *flag.Flag) {
+		arg, usage := flag.UnquoteUsage(f)
+		name := "--" + f.Name
+		if len(f.Name) == 1 {
+			name = "-" + f.Name
+		}
+		result = append(result, commandFlag{
+			Name:     name,
+			Arg:      arg,
+			Usage:    usage,
+			DefValue: f.DefValue,
+			Values:   flagValues[f.Name],
+			Files:    slices.Contains(fileFlags, f.Name),
+		})
+	})
+	return result
+}
+
+// Flags of the default parse command and the serve, release, migrate and lint subcommands
+func parseCommandFlags() []commandFlag {
+	return commandFlags(newParseFlagSet(&parseFlags{}))
+}
+
+func serveCommandFlags() []commandFlag {
+	return commandFlags(newServeFlagSet(&serveFlags{}))
+}
+
+func releaseCommandFlags() []commandFlag {
+	return commandFlags(newReleaseFlagSet(&releaseFlags{}))
+}
+
+func migrateCommandFlags() []commandFlag {
+	return commandFlags(newMigrateFlagSet(&migrateFlags{}))
+}
+
+func lintCommandFlags() []commandFlag {
+	return commandFlags(newLintFlagSet(&lintFlags{}))
+}
+
+// The subcommands taking flags, in name order
+func flaggedSubcommands() []flaggedSubcommand {
+	return []flaggedSubcommand{{"lint", lintCommandFlags()}, {"migrate", migrateCommandFlags()}, {"release", releaseCommandFlags()}, {"serve", serveCommandFlags()}}
+}
+
+type flaggedSubcommand struct {
+	name  string
+	flags []commandFlag
+}
+
+func lintRuleIDs() []string {
+	var ids []string
+	for _, rule := range parser.LintRules {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+func logLevels() []string {
+	var levels []string
+	for _, level := range logrus.AllLevels {
+		levels = append(levels, level.String())
+	}
+	return levels
+}
+
+// Run the completion subcommand, printing the completion script for a shell
+func runCompletion(args []string) {
+	if len(args) != 1 || !slices.Contains(completionShells, args[0]) {
+		fail(parser.ArgumentError, fmt.Sprintf("Expected one shell to complete, one of: %s\n", strings.Join(completionShells, ", "))+usage)
+	}
+	var script string
+	switch args[0] {
+	case "bash":
+		script = bashCompletion()
+	case "zsh":
+		script = zshCompletion()
+	case "fish":
+		script = fishCompletion()
+	}
+	fmt.Fprint(os.Stdout, script)
+}
+
+func bashCompletion() string {
+	var b strings.Builder
+	function := "_" + strings.ReplaceAll(commandName, "-", "_")
+	commands := slices.Sorted(maps.Keys(subcommands))
+
+	// Options taking a value complete it from the previous word, so a value is never completed as an option
+	valueCases := func(commandFlags []commandFlag) {
+		var files, none []string
+		for _, f := range commandFlags {
+			switch {
+			case f.Arg == "":
+			case f.Files:
+				files = append(files, f.Name)
+			case f.Values != nil:
+				fmt.Fprintf(&b, "\t\t%s)\n\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\t\treturn\n\t\t\t;;\n", f.Name, strings.Join(f.Values, " "))
+			default:
+				none = append(none, f.Name)
+			}
+		}
+		if files != nil {
+			fmt.Fprintf(&b, "\t\t%s)\n\t\t\tcompopt -o filenames\n\t\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\t\t\treturn\n\t\t\t;;\n", strings.Join(files, "|"))
+		}
+		if none != nil {
+			fmt.Fprintf(&b, "\t\t%s)\n\t\t\treturn\n\t\t\t;;\n", strings.Join(none, "|"))
+		}
+	}
+	names := func(commandFlags []commandFlag) string {
+		var names []string
+		for _, f := range commandFlags {
+			names = append(names, f.Name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	fmt.Fprintf(&b, "# bash completion for %s\n\n", commandName)
+	fmt.Fprintf(&b, "%s() {\n", function)
+	b.WriteString("\tlocal cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n\n")
+	b.WriteString("\tcase \"${COMP_WORDS[1]}\" in\n")
+	for _, command := range flaggedSubcommands() {
+		fmt.Fprintf(&b, "\t%s)\n\t\tcase \"$prev\" in\n", command.name)
+		valueCases(command.flags)
+		b.WriteString("\t\tesac\n")
+		fmt.Fprintf(&b, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\t;;\n", names(command.flags))
+	}
+	fmt.Fprintf(&b, "\tcompletion)\n\t\tif [[ $COMP_CWORD -eq 2 ]]; then\n\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\tfi\n\t\t;;\n", strings.Join(completionShells, " "))
+	b.WriteString("\tman)\n\t\t;;\n")
+	b.WriteString("\t*)\n\t\tcase \"$prev\" in\n")
+	valueCases(parseCommandFlags())
+	b.WriteString("\t\tesac\n")
+	fmt.Fprintf(&b, "\t\tif [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then\n\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(commands, " "))
+	fmt.Fprintf(&b, "\t\telse\n\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\tfi\n\t\t;;\n", names(parseCommandFlags()))
+	b.WriteString("\tesac\n}\n\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", function, commandName)
+	return b.String()
+}
+
+func zshCompletion() string {
+	var b strings.Builder
+	function := "_" + strings.ReplaceAll(commandName, "-", "_")
+	// Descriptions are in single quoted _arguments specs, where brackets and colons are also special
+	escape := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`, ":", `\:`).Replace
+
+	specs := func(commandFlags []commandFlag) {
+		for _, f := range commandFlags {
+			spec := fmt.Sprintf("%s[%s]", f.Name, escape(f.Usage))
+			if f.Name == "-f" {
+				// Compose files may be specified multiple times
+				spec = "*" + spec
+			}
+			switch {
+			case f.Arg == "":
+			case f.Files:
+				spec += fmt.Sprintf(":%s:_files", f.Arg)
+			case f.Values != nil:
+				spec += fmt.Sprintf(":%s:(%s)", f.Arg, strings.Join(f.Values, " "))
+			default:
+				spec += fmt.Sprintf(":%s: ", f.Arg)
+			}
+			fmt.Fprintf(&b, "\t\t\t'%s' \\\n", spec)
+		}
+	}
+
+	fmt.Fprintf(&b, "#compdef %s\n\n", commandName)
+	fmt.Fprintf(&b, "%s() {\n", function)
+	b.WriteString("\tlocal -a commands\n\tcommands=(\n")
+	for _, name := range slices.Sorted(maps.Keys(subcommands)) {
+		fmt.Fprintf(&b, "\t\t'%s:%s'\n", name, escape(subcommands[name].description))
+	}
+	b.WriteString("\t)\n\n")
+	b.WriteString("\tcase $words[2] in\n")
+	for _, command := range flaggedSubcommands() {
+		fmt.Fprintf(&b, "\t%s)\n\t\tshift words\n\t\t(( CURRENT-- ))\n\t\t_arguments -S \\\n", command.name)
+		specs(command.flags)
+		b.WriteString("\t\t\t&& return\n\t\t;;\n")
+	}
+	fmt.Fprintf(&b, "\tcompletion)\n\t\t(( CURRENT == 3 )) && _values shell %s\n\t\t;;\n", strings.Join(completionShells, " "))
+	b.WriteString("\tman)\n\t\t;;\n")
+	b.WriteString("\t*)\n\t\tif (( CURRENT == 2 )) && [[ $PREFIX != -* ]]; then\n\t\t\t_describe command commands\n\t\tfi\n")
+	b.WriteString("\t\t_arguments -S \\\n")
+	specs(parseCommandFlags())
+	b.WriteString("\t\t\t':project-name: ' \\\n\t\t\t&& return\n\t\t;;\n")
+	b.WriteString("\tesac\n}\n\n")
+	fmt.Fprintf(&b, "%s \"$@\"\n", function)
+	return b.String()
+}
+
+func fishCompletion() string {
+	var b strings.Builder
+	escape := strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace
+	commands := slices.Sorted(maps.Keys(subcommands))
+
+	completions := func(condition string, commandFlags []commandFlag) {
+		for _, f := range commandFlags {
+			option := "-l " + strings.TrimPrefix(f.Name, "--")
+			if !strings.HasPrefix(f.Name, "--") {
+				option = "-s " + strings.TrimPrefix(f.Name, "-")
+			}
+			switch {
+			case f.Arg == "":
+			case f.Files:
+				option += " -r -F"
+			case f.Values != nil:
+				option += fmt.Sprintf(" -x -a '%s'", strings.Join(f.Values, " "))
+			default:
+				option += " -x"
+			}
+			fmt.Fprintf(&b, "complete -c %s -n '%s' %s -d '%s'\n", commandName, condition, option, escape(f.Usage))
+		}
+	}
+
+	fmt.Fprintf(&b, "# fish completion for %s\n\n", commandName)
+	fmt.Fprintf(&b, "complete -c %s -f\n", commandName)
+	for _, name := range commands {
+		fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a %s -d '%s'\n", commandName, name, escape(subcommands[name].description))
+	}
+	fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from completion' -x -a '%s'\n", commandName, strings.Join(completionShells, " "))
+	for _, command := range flaggedSubcommands() {
+		completions("__fish_seen_subcommand_from "+command.name, command.flags)
+	}
+	completions("not __fish_seen_subcommand_from "+strings.Join(commands, " "), parseCommandFlags())
+	return b.String()
+}
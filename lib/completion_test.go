@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range completionShells {
+		t.Run(shell, func(t *testing.T) {
+			result := runCLI(t, "", "completion", shell)
+			if result.code != 0 {
+				t.Fatalf("expected the %s completion script, got %d: %s", shell, result.code, result.stderr)
+			}
+			// Every flag, subcommand and fixed value is completed
+			for _, f := range append(parseCommandFlags(), serveCommandFlags()...) {
+				name := f.Name
+				if shell == "fish" {
+					name = strings.TrimLeft(f.Name, "-")
+				}
+				if !strings.Contains(result.stdout, name) {
+					t.Errorf("expected %s to be completed", f.Name)
+				}
+			}
+			for name := range subcommands {
+				if !strings.Contains(result.stdout, name) {
+					t.Errorf("expected the %s subcommand to be completed", name)
+				}
+			}
+			if !strings.Contains(result.stdout, strings.Join(outputFormats, " ")) {
+				t.Errorf("expected the output formats to be completed")
+			}
+		})
+	}
+
+	runCLI(t, "", "completion", "powershell").expectError(t, parser.ArgumentError, "Expected one shell to complete, one of: bash, zsh, fish")
+	runCLI(t, "", "completion").expectError(t, parser.ArgumentError, "Expected one shell to complete")
+}
+
+func TestBashCompletion(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	tests := []struct {
+		words    string
+		expected []string
+	}{
+		{words: "balena-compose-parser --output-format y", expected: []string{"yaml"}},
+		{words: "balena-compose-parser --no-os", expected: []string{"--no-os-env"}},
+		{words: "balena-compose-parser com", expected: []string{"completion"}},
+		{words: "balena-compose-parser completion f", expected: []string{"fish"}},
+		{words: "balena-compose-parser serve --grpc-l", expected: []string{"--grpc-listen"}},
+		// Values which aren't files or from a fixed set aren't completed
+		{words: "balena-compose-parser --timeout 1"},
+	}
+	script := bashCompletion()
+	for _, tt := range tests {
+		t.Run(tt.words, func(t *testing.T) {
+			cmd := exec.Command("bash", "-c", script+`
+COMP_WORDS=($WORDS)
+COMP_CWORD=$((${#COMP_WORDS[@]} - 1))
+_balena_compose_parser
+printf '%s\n' "${COMPREPLY[@]}"`)
+			cmd.Env = []string{"WORDS=" + tt.words}
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("expected the completion to run, got %s: %v", output, err)
+			}
+			completions := strings.Fields(string(output))
+			if strings.Join(completions, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("expected %v, got %v", tt.expected, completions)
+			}
+		})
+	}
+}
+
+func TestZshCompletionEscaping(t *testing.T) {
+	script := zshCompletion()
+	// Descriptions are single quoted, so brackets, colons and quotes must be escaped
+	for _, f := range parseCommandFlags() {
+		if strings.ContainsAny(f.Usage, "[]:'") && strings.Contains(script, "["+f.Usage+"]") {
+			t.Errorf("expected the description of %s to be escaped", f.Name)
+		}
+	}
+	if !strings.HasPrefix(script, "#compdef balena-compose-parser\n") || !strings.Contains(script, "'*-f[") {
+		t.Errorf("expected a zsh completion script completing repeated compose files, got %s", script)
+	}
+}
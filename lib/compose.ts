@@ -101,7 +101,9 @@ function toComposeError(stderr: string): ComposeError[] {
 			const parsed = JSON.parse(line);
 
 			// Both our custom error format and logrus JSON format have 'message' field
-			if (parsed.message) {
+			if (parsed.code === PRIVATE_LABEL_CODE) {
+				errors.push(toNamespacedLabelError(parsed.location?.path));
+			} else if (parsed.message) {
 				errors.push(
 					new ComposeError(
 						parsed.message,
@@ -501,6 +503,25 @@ function normalizeServiceBuild(
 
 const NAMESPACED_LABEL_ERROR_MESSAGE =
 	'labels cannot use the "io.balena.private" namespace';
+
+// Code of the parser's errors for io.balena.private labels, which it rejects before normalization
+const PRIVATE_LABEL_CODE = 'private-label';
+
+/**
+ * Convert a parser error for an io.balena.private label into the error normalization would throw,
+ * a ServiceError for labels of services and their builds, and a ValidationError otherwise
+ * @param labelPath - Path of the label, e.g. services.main.labels.io.balena.private.foo
+ */
+function toNamespacedLabelError(labelPath?: string): ComposeError {
+	const serviceName = labelPath?.match(
+		/^services\.(.+?)\.(?:build\.)?labels\.io\.balena\.private/,
+	)?.[1];
+	if (serviceName) {
+		return new ServiceError(NAMESPACED_LABEL_ERROR_MESSAGE, serviceName);
+	}
+	return new ValidationError(NAMESPACED_LABEL_ERROR_MESSAGE);
+}
+
 function validateLabels(labels: Dict<any>, serviceName?: string) {
 	for (const [name, value] of Object.entries(labels)) {
 		// Reject io.balena.private label namespace
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"balena-compose-parser/pkg/parser"
+	"balena-compose-parser/pkg/parserpb"
+)
+
+// grpcServer implements the ComposeParser gRPC service. Parser failures are returned as
+// structured errors in the response, while gRPC status errors are reserved for invalid requests.
+type grpcServer struct {
+	parserpb.UnimplementedComposeParserServer
+	timeout time.Duration
+}
+
+// Serve the gRPC API on the given address until the server fails
+func serveGRPC(listen string, timeout time.Duration) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer()
+	parserpb.RegisterComposeParserServer(server, &grpcServer{timeout: timeout})
+	logrus.Infof("Serving gRPC on %s", listen)
+	return server.Serve(listener)
+}
+
+func (s *grpcServer) Parse(ctx context.Context, request *parserpb.ParseRequest) (*parserpb.ParseResponse, error) {
+	result, err := s.parse(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if result.err != nil {
+		return &parserpb.ParseResponse{Result: &parserpb.ParseResponse_Error{Error: result.err}}, nil
+	}
+
+	output, err := marshalProject(result.parsed.Project, formatJSON, request.GetCanonical())
+	if err != nil {
+		return &parserpb.ParseResponse{Result: &parserpb.ParseResponse_Error{Error: &parserpb.Error{
+			Name:    parser.ParseError,
+			Message: fmt.Sprintf("Failed to marshal compose project to JSON: %v", err),
+		}}}, nil
+	}
+	return &parserpb.ParseResponse{Result: &parserpb.ParseResponse_ProjectJson{ProjectJson: output}}, nil
+}
+
+func (s *grpcServer) Validate(ctx context.Context, request *parserpb.ParseRequest) (*parserpb.ValidateResponse, error) {
+	result, err := s.parse(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &parserpb.ValidateResponse{Valid: result.err == nil, Error: result.err}, nil
+}
+
+type grpcParseResult struct {
+	parsed *parser.Result
+	err    *parserpb.Error
+}
+
+// Parse the request's compose files, returning a gRPC status error only if the request itself is invalid
+func (s *grpcServer) parse(ctx context.Context, request *parserpb.ParseRequest) (*grpcParseResult, error) {
+	timeout := s.timeout
+	if request.GetTimeout() != nil {
+		if err := request.GetTimeout().CheckValid(); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Invalid timeout: %v", err)
+		}
+		timeout = request.GetTimeout().AsDuration()
+	}
+
+	var files []parser.File
+	for _, file := range request.GetFiles() {
+		files = append(files, parser.File{Name: file.GetName(), Content: file.GetContent()})
+	}
+
+	// Requests are from the network, so can't interpolate the environment of the server
+	result, err := parser.New(parser.Options{
+		ProjectName: request.GetProjectName(),
+		Timeout:     timeout,
+		NoOSEnv:     true,
+		NoDotEnv:    true,
+	}).ParseContent(ctx, files)
+	if err != nil {
+		var parserErr *parser.Error
+		if !errors.As(err, &parserErr) {
+			parserErr = &parser.Error{Name: parser.ParseError, Message: err.Error()}
+		}
+		return &grpcParseResult{err: &parserpb.Error{Name: parserErr.Name, Message: parserErr.Message}}, nil
+	}
+	return &grpcParseResult{parsed: result}, nil
+}
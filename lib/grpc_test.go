@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"balena-compose-parser/pkg/parser"
+	"balena-compose-parser/pkg/parserpb"
+)
+
+// Connect a client to a gRPC server served in memory
+func grpcClient(t *testing.T) parserpb.ComposeParserClient {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	parserpb.RegisterComposeParserServer(server, &grpcServer{timeout: time.Minute})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return parserpb.NewComposeParserClient(conn)
+}
+
+func composeFiles(files ...string) []*parserpb.ComposeFile {
+	var composeFiles []*parserpb.ComposeFile
+	for i := 0; i < len(files); i += 2 {
+		composeFiles = append(composeFiles, &parserpb.ComposeFile{Name: files[i], Content: []byte(files[i+1])})
+	}
+	return composeFiles
+}
+
+func TestGRPCParse(t *testing.T) {
+	t.Setenv("GRPC_TEST_VARIABLE", "from the server")
+	client := grpcClient(t)
+	ctx := context.Background()
+
+	response, err := client.Parse(ctx, &parserpb.ParseRequest{
+		ProjectName: "p",
+		Files: composeFiles(
+			"compose.yml", "services:\n  web:\n    image: nginx\n    environment:\n      A: ${GRPC_TEST_VARIABLE:-unset}\n",
+			"override.yml", "services:\n  web:\n    command: [a]\n",
+		),
+		Canonical: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var project map[string]any
+	if err := json.Unmarshal(response.GetProjectJson(), &project); err != nil {
+		t.Fatalf("expected the project, got %v: %v", response, err)
+	}
+	if lookup(project, "services.web.image") != "nginx" || lookup(project, "services.web.command") == nil {
+		t.Errorf("expected the files to be merged, got %v", project)
+	}
+	// Requests can't read the environment of the server
+	if value := lookup(project, "services.web.environment.A"); value != "unset" {
+		t.Errorf("expected the variable to be unset, got %v", value)
+	}
+
+	response, err = client.Parse(ctx, &parserpb.ParseRequest{ProjectName: "p", Files: composeFiles("compose.yml", "services:\n  web:\n    image: nginx\n    env_file: ../.env\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parseErr := response.GetError(); parseErr.GetName() != parser.ValidationError {
+		t.Errorf("expected a ValidationError, got %v", response)
+	}
+}
+
+func TestGRPCValidate(t *testing.T) {
+	client := grpcClient(t)
+	ctx := context.Background()
+
+	response, err := client.Validate(ctx, &parserpb.ParseRequest{ProjectName: "p", Files: composeFiles("compose.yml", "services:\n  web:\n    image: nginx\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.GetValid() || response.GetError() != nil {
+		t.Errorf("expected a valid response, got %v", response)
+	}
+
+	response, err = client.Validate(ctx, &parserpb.ParseRequest{Files: composeFiles("compose.yml", "services:\n  web:\n    image: nginx\n")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.GetValid() || response.GetError().GetName() != parser.ArgumentError {
+		t.Errorf("expected an ArgumentError without a project name, got %v", response)
+	}
+
+	_, err = client.Validate(ctx, &parserpb.ParseRequest{ProjectName: "p", Timeout: &durationpb.Duration{Nanos: -1, Seconds: 1}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an invalid timeout to be an invalid argument, got %v", err)
+	}
+}
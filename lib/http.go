@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Max size of a /parse request body
+const httpMaxRequestSize = 10 * 1024 * 1024
+
+// JSON body accepted by POST /parse
+type httpParseRequest struct {
+	ProjectName string `json:"projectName"`
+	Files       []struct {
+		Name    string `json:"name"`
+		Content string `json:"content"`
+	} `json:"files"`
+	Canonical bool `json:"canonical,omitempty"`
+}
+
+// Serve the HTTP API on the given address until the server fails
+func serveHTTP(listen string, timeout time.Duration) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /parse", func(w http.ResponseWriter, r *http.Request) {
+		handleHTTPParse(w, r, timeout)
+	})
+
+	server := &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	logrus.Infof("Listening on %s", listen)
+	return server.ListenAndServe()
+}
+
+// Handle POST /parse, which accepts compose files either as a JSON body or as multipart form data,
+// where each "file" part is a compose file in override order and the "projectName" field is the project name.
+func handleHTTPParse(w http.ResponseWriter, r *http.Request, timeout time.Duration) {
+	r.Body = http.MaxBytesReader(w, r.Body, httpMaxRequestSize)
+
+	var projectName string
+	var files []parser.File
+	var canonical bool
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(httpMaxRequestSize); err != nil {
+			writeHTTPError(w, http.StatusBadRequest, parser.ArgumentError, fmt.Sprintf("Invalid multipart body: %v", err))
+			return
+		}
+		projectName = r.FormValue("projectName")
+		canonical = r.FormValue("canonical") == "true"
+		for _, header := range r.MultipartForm.File["file"] {
+			f, err := header.Open()
+			if err != nil {
+				writeHTTPError(w, http.StatusBadRequest, parser.ArgumentError, fmt.Sprintf("Failed to read file %q: %v", header.Filename, err))
+				return
+			}
+			content, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				writeHTTPError(w, http.StatusBadRequest, parser.ArgumentError, fmt.Sprintf("Failed to read file %q: %v", header.Filename, err))
+				return
+			}
+			files = append(files, parser.File{Name: header.Filename, Content: content})
+		}
+	case "application/json":
+		var request httpParseRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeHTTPError(w, http.StatusBadRequest, parser.ArgumentError, fmt.Sprintf("Invalid JSON body: %v", err))
+			return
+		}
+		projectName = request.ProjectName
+		canonical = request.Canonical
+		for _, file := range request.Files {
+			files = append(files, parser.File{Name: file.Name, Content: []byte(file.Content)})
+		}
+	default:
+		writeHTTPError(w, http.StatusUnsupportedMediaType, parser.ArgumentError, "Content-Type must be application/json or multipart/form-data")
+		return
+	}
+
+	// Requests are from the network, so can't interpolate the environment of the server
+	result, err := parser.New(parser.Options{
+		ProjectName: projectName,
+		Timeout:     timeout,
+		NoOSEnv:     true,
+		NoDotEnv:    true,
+	}).ParseContent(r.Context(), files)
+	if err != nil {
+		response := parser.NewErrorResponse(err)
+		status := http.StatusUnprocessableEntity
+		switch response.Name {
+		case parser.ArgumentError:
+			status = http.StatusBadRequest
+		case parser.TimeoutError:
+			status = http.StatusGatewayTimeout
+		case parser.ConfigError:
+			status = http.StatusInternalServerError
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	output, err := marshalProject(result.Project, formatJSON, canonical)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, parser.ParseError, fmt.Sprintf("Failed to marshal compose project to JSON: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(output)
+}
+
+// Write an error response using the same structure as errors emitted on stderr
+func writeHTTPError(w http.ResponseWriter, status int, errorName, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(parser.ErrorResponse{
+		Error:   true,
+		Name:    errorName,
+		Message: message,
+	})
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Send a POST /parse request of a content type, returning the response
+func postParse(t *testing.T, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodPost, "/parse", strings.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	recorder := httptest.NewRecorder()
+	handleHTTPParse(recorder, request, time.Minute)
+	return recorder
+}
+
+// Encode a JSON /parse request of files by name, in order
+func parseRequestJSON(t *testing.T, projectName string, files ...string) string {
+	t.Helper()
+	request := httpParseRequest{ProjectName: projectName}
+	for i := 0; i < len(files); i += 2 {
+		request.Files = append(request.Files, struct {
+			Name    string `json:"name"`
+			Content string `json:"content"`
+		}{files[i], files[i+1]})
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestHTTPParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		errorName   string
+		code        string
+	}{
+		{
+			name:        "JSON",
+			contentType: "application/json",
+			body:        parseRequestJSON(t, "p", "compose.yml", "services:\n  web:\n    image: nginx\n", "override.yml", "services:\n  web:\n    command: [a]\n"),
+			status:      http.StatusOK,
+		},
+		{
+			name:        "invalid JSON",
+			contentType: "application/json",
+			body:        `{"files":`,
+			status:      http.StatusBadRequest,
+			errorName:   parser.ArgumentError,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "text/yaml",
+			body:        "services: {}\n",
+			status:      http.StatusUnsupportedMediaType,
+			errorName:   parser.ArgumentError,
+		},
+		{
+			name:        "no project name",
+			contentType: "application/json",
+			body:        parseRequestJSON(t, "", "compose.yml", "services: {}\n"),
+			status:      http.StatusBadRequest,
+			errorName:   parser.ArgumentError,
+		},
+		{
+			name:        "invalid compose file",
+			contentType: "application/json",
+			body:        parseRequestJSON(t, "p", "compose.yml", "services:\n  web:\n    image: nginx\n    ports: true\n"),
+			status:      http.StatusUnprocessableEntity,
+			errorName:   parser.ValidationError,
+		},
+		{
+			name:        "env file outside of the project",
+			contentType: "application/json",
+			body:        parseRequestJSON(t, "p", "compose.yml", "services:\n  web:\n    image: nginx\n    env_file: /etc/hostname\n"),
+			status:      http.StatusUnprocessableEntity,
+			errorName:   parser.ValidationError,
+			code:        parser.ExternalPathCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := postParse(t, tt.contentType, tt.body)
+			if response.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, response.Code, response.Body)
+			}
+			if contentType := response.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("expected a JSON response, got %q", contentType)
+			}
+			if tt.errorName == "" {
+				return
+			}
+			var errorResponse parser.ErrorResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &errorResponse); err != nil {
+				t.Fatalf("expected an error response, got %s", response.Body)
+			}
+			if !errorResponse.Error || errorResponse.Name != tt.errorName || errorResponse.Code != tt.code {
+				t.Errorf("expected a %s with code %q, got %+v", tt.errorName, tt.code, errorResponse)
+			}
+		})
+	}
+}
+
+func TestHTTPParseMultipart(t *testing.T) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("projectName", "p")
+	writer.WriteField("canonical", "true")
+	for name, content := range map[string]string{"compose.yml": "services:\n  web:\n    image: nginx\n"} {
+		part, err := writer.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	writer.Close()
+
+	response := postParse(t, writer.FormDataContentType(), body.String())
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", response.Code, response.Body)
+	}
+	if !strings.HasPrefix(response.Body.String(), `{"name":"p","networks":`) {
+		t.Errorf("expected the canonical project, got %s", response.Body)
+	}
+}
+
+func TestHTTPParseEnvironment(t *testing.T) {
+	// Requests can't read the environment of the server
+	t.Setenv("HTTP_TEST_VARIABLE", "from the server")
+	response := postParse(t, "application/json", parseRequestJSON(t, "p",
+		"compose.yml", "services:\n  web:\n    image: nginx\n    environment:\n      A: ${HTTP_TEST_VARIABLE:-unset}\n",
+	))
+	if response.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", response.Code, response.Body)
+	}
+	var project map[string]any
+	json.Unmarshal(response.Body.Bytes(), &project)
+	if value := lookup(project, "services.web.environment.A"); value != "unset" {
+		t.Errorf("expected the variable to be unset, got %v", value)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/sirupsen/logrus"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// lintFlags are the command line flags of the lint subcommand
+type lintFlags struct {
+	composeFiles     composeFileFlag
+	projectDirectory string
+	envFiles         stringListFlag
+	enable           stringListFlag
+	disable          stringListFlag
+	severities       envFlag
+	failOn           stringListFlag
+	deviceMemory     string
+	allowMounts      stringListFlag
+	configPath       string
+	noConfig         bool
+	listRules        bool
+	reportFormat     string
+	timeout          time.Duration
+	outputPath       string
+	logLevel         string
+	quiet            bool
+}
+
+// Conditions --fail-on accepts, failing the lint subcommand on findings of a severity or more severe, or
+// on findings of a kind regardless of their severity
+var failOnConditions = append(slices.Clone(parser.Severities), "unpinned-images")
+
+// lintReport is the output of the lint subcommand, the findings of the rules and the parser's warnings
+type lintReport struct {
+	Findings []parser.Finding `json:"findings"`
+	Warnings []parser.Warning `json:"warnings"`
+}
+
+// Create the flag set of the lint subcommand, storing values in o
+func newLintFlagSet(o *lintFlags) *flag.FlagSet {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Var(&o.composeFiles, "f", "Path to a `compose-file` to lint, later files overriding earlier ones")
+	flags.StringVar(&o.projectDirectory, "project-directory", "", "Resolve relative paths against the `directory`, instead of that of the first compose file")
+	flags.Var(&o.envFiles, "env-file", "Interpolate variables from an env `file`, e.g. the project's .env file")
+	flags.Var(&o.enable, "enable", "Run a lint `rule` which is disabled by default (can be specified multiple times)")
+	flags.Var(&o.disable, "disable", "Don't run a lint `rule` (can be specified multiple times)")
+	flags.Var(&o.severities, "severity", "Override the severity of a lint rule, as `rule=severity` (can be specified multiple times)")
+	flags.Var(&o.failOn, "fail-on", "Fail on the findings of a `condition`, a severity or \"unpinned-images\" (can be specified multiple times)")
+	flags.StringVar(&o.deviceMemory, "device-memory", "", "Report memory limits exceeding the memory `size` of the devices, e.g. 1g")
+	flags.Var(&o.allowMounts, "allow-mount", "Allow services to bind mount a host `path` and paths beneath it (can be specified multiple times)")
+	flags.StringVar(&o.configPath, "config", "", "Read the lint configuration from `file` instead of the project directory's "+parser.LintConfigFile)
+	flags.BoolVar(&o.noConfig, "no-config", false, "Don't read a lint configuration file")
+	flags.StringVar(&o.reportFormat, "report-format", reportFormatJSON, "Write findings as `format`, \"json\" or \"sarif\"")
+	flags.BoolVar(&o.listRules, "list-rules", false, "Print the lint rules as JSON")
+	flags.DurationVar(&o.timeout, "timeout", parser.DefaultTimeout, "Maximum `duration` to spend parsing")
+	flags.StringVar(&o.outputPath, "o", "", "Write output atomically to `path` instead of stdout")
+	flags.StringVar(&o.logLevel, "log-level", logrus.InfoLevel.String(), "Minimum `level` of logs written to stderr")
+	flags.BoolVar(&o.quiet, "quiet", false, "Don't write any logs to stderr")
+	return flags
+}
+
+// Run the lint subcommand, writing the findings of the lint rules on a project. The project is parsed for
+// balena without compose-go's consistency checks, so references to undeclared resources are findings
+// rather than a parse failure, and the command fails with a ValidationError exit code if any finding is an
+// error, or at least of the --fail-on severity or of another --fail-on condition.
+func runLint(args []string) {
+	var o lintFlags
+	flags := newLintFlagSet(&o)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stdout, usage)
+			return
+		}
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if err := configureLogging(o.logLevel, o.quiet); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if o.listRules {
+		output, _ := json.MarshalIndent(parser.LintRules, "", "  ")
+		if err := writeOutput(o.outputPath, output, false); err != nil {
+			fail(parser.IOError, err.Error())
+		}
+		return
+	}
+	if flags.NArg() > 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Unexpected arguments: %v\n", flags.Args())+usage)
+	}
+	if len(o.composeFiles) == 0 {
+		fail(parser.ArgumentError, "At least one compose file must be specified with -f\n"+usage)
+	}
+	if err := applyTimeoutEnv(flags, &o.timeout); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if o.timeout <= 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Timeout must be positive, got %s\n", o.timeout)+usage)
+	}
+	var deviceMemory int64
+	if o.deviceMemory != "" {
+		var err error
+		if deviceMemory, err = units.RAMInBytes(o.deviceMemory); err != nil || deviceMemory <= 0 {
+			fail(parser.ArgumentError, fmt.Sprintf("Device memory must be a positive size such as 1g, got %q\n", o.deviceMemory)+usage)
+		}
+	}
+	if !slices.Contains(reportFormats, o.reportFormat) {
+		fail(parser.ArgumentError, fmt.Sprintf("Unsupported report format %q, expected one of: %s\n", o.reportFormat, strings.Join(reportFormats, ", "))+usage)
+	}
+	if o.configPath != "" && o.noConfig {
+		fail(parser.ArgumentError, "--config and --no-config can't be combined\n"+usage)
+	}
+	for _, condition := range o.failOn {
+		if !slices.Contains(failOnConditions, condition) {
+			fail(parser.ArgumentError, fmt.Sprintf("Unsupported --fail-on condition %q, expected one of: %s\n", condition, strings.Join(failOnConditions, ", "))+usage)
+		}
+	}
+
+	p := parser.New(parser.Options{
+		ProjectDirectory: o.projectDirectory,
+		Timeout:          o.timeout,
+		EnvFiles:         o.envFiles,
+		RelativePaths:    true,
+		BalenaNormalize:  true,
+		Warnings:         true,
+		// References to undeclared resources are reported by the undefined-references rule
+		SkipConsistency: true,
+	})
+	result, err := p.Parse(context.Background(), o.composeFiles)
+	if err != nil {
+		exitWithError(err)
+	}
+	options := parser.LintOptions{
+		Enable:        o.enable,
+		Disable:       o.disable,
+		Severities:    o.severities,
+		DeviceMemory:  deviceMemory,
+		AllowedMounts: o.allowMounts,
+	}
+	if config, err := readLintConfig(o); err != nil {
+		exitWithError(err)
+	} else if config != nil {
+		options = config.Options(options)
+	}
+	findings, err := parser.Lint(result.Project, o.composeFiles, options)
+	if err != nil {
+		exitWithError(err)
+	}
+	report := lintReport{Findings: findings, Warnings: append([]parser.Warning{}, result.Warnings...)}
+	var document any = report
+	if o.reportFormat == reportFormatSARIF {
+		document = lintSarif(report)
+	}
+	output, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		fail(parser.ParseError, fmt.Sprintf("Failed to marshal findings to JSON: %v", err))
+	}
+	if err := writeOutput(o.outputPath, output, false); err != nil {
+		fail(parser.IOError, err.Error())
+	}
+	if slices.ContainsFunc(findings, func(finding parser.Finding) bool { return failsLint(finding, o.failOn) }) {
+		os.Exit(exitCode(parser.ValidationError))
+	}
+}
+
+// Read the lint configuration set with --config, or that of the project directory if it has one, returning
+// nil if there's none or with --no-config
+func readLintConfig(o lintFlags) (*parser.LintConfig, error) {
+	if o.noConfig {
+		return nil, nil
+	}
+	if o.configPath != "" {
+		return parser.ReadLintConfig(o.configPath)
+	}
+	directory := o.projectDirectory
+	if first := o.composeFiles[0]; directory == "" && first != parser.StdinPath && !strings.HasPrefix(first, "https://") {
+		directory = filepath.Dir(first)
+	}
+	path := filepath.Join(directory, parser.LintConfigFile)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return parser.ReadLintConfig(path)
+}
+
+// Whether a finding fails the lint subcommand, as it's at least as severe as the least severe --fail-on
+// severity, error by default, or a finding of another --fail-on condition
+func failsLint(finding parser.Finding, failOn []string) bool {
+	threshold := slices.Index(parser.Severities, parser.ErrorSeverity)
+	for _, condition := range failOn {
+		threshold = max(threshold, slices.Index(parser.Severities, condition))
+	}
+	return slices.Index(parser.Severities, finding.Severity) <= threshold ||
+		slices.Contains(failOn, "unpinned-images") && finding.Code == parser.UnpinnedImageCode
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:1.25\n    scale: 2\n    mem_limit: 256m\n    cpus: 0.5\n")
+	lint := func(result cliResult) []string {
+		t.Helper()
+		var report struct {
+			Findings []parser.Finding `json:"findings"`
+			Warnings []parser.Warning `json:"warnings"`
+		}
+		if err := json.Unmarshal([]byte(result.stdout), &report); err != nil {
+			t.Fatalf("expected a JSON report, got %q: %v", result.stdout, err)
+		}
+		if report.Warnings == nil {
+			t.Error("expected the warnings of the parse to be reported")
+		}
+		var findings []string
+		for _, finding := range report.Findings {
+			if finding.Location == nil || finding.Location.File != composeFile {
+				t.Fatalf("expected %s to be located in the compose file, got %+v", finding.Rule, finding.Location)
+			}
+			findings = append(findings, finding.Rule+" "+finding.Severity+" "+finding.Location.Path)
+		}
+		return findings
+	}
+
+	// The command fails with the ValidationError exit code if any finding is an error
+	result := runCLI(t, "", "lint", "-f", composeFile)
+	if result.code != exitCode(parser.ValidationError) {
+		t.Errorf("expected the ValidationError exit code, got %d: %s", result.code, result.stderr)
+	}
+	if findings := lint(result); !reflect.DeepEqual(findings, []string{"balena-compatibility error services.web.scale", "restart-policy info services.web"}) {
+		t.Errorf("expected the findings of the default rules, got %v", findings)
+	}
+
+	result = runCLI(t, "", "lint", "-f", composeFile, "--disable", "balena-compatibility", "--severity", "restart-policy=warning")
+	if result.code != 0 {
+		t.Errorf("expected no error findings, got %d: %s", result.code, result.stderr)
+	}
+	if findings := lint(result); !reflect.DeepEqual(findings, []string{"restart-policy warning services.web"}) {
+		t.Errorf("expected the rule to be disabled and the severity overridden, got %v", findings)
+	}
+
+	result = runCLI(t, "", "lint", "--list-rules")
+	var rules []parser.LintRule
+	if err := json.Unmarshal([]byte(result.stdout), &rules); err != nil || result.code != 0 || len(rules) != len(parser.LintRules) {
+		t.Errorf("expected the lint rules, got %d: %s: %v", result.code, result.stdout, err)
+	}
+}
+
+func TestLintErrors(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n")
+	for _, tt := range []struct {
+		args []string
+		name string
+		msg  string
+	}{
+		{args: nil, name: parser.ArgumentError, msg: "At least one compose file must be specified with -f"},
+		{args: []string{"-f", composeFile, "extra"}, name: parser.ArgumentError, msg: "Unexpected arguments: [extra]"},
+		{args: []string{"-f", composeFile, "--enable", "tabs"}, name: parser.ArgumentError, msg: `Unknown lint rule "tabs"`},
+		{args: []string{"-f", composeFile, "--severity", "restart-policy=fatal"}, name: parser.ArgumentError, msg: `Unsupported severity "fatal" of lint rule restart-policy`},
+		{args: []string{"-f", filepath.Join(dir, "missing.yml")}, name: parser.IOError, msg: "no such file or directory"},
+	} {
+		runCLI(t, "", append([]string{"lint"}, tt.args...)...).expectError(t, tt.name, tt.msg)
+	}
+}
+
+func TestLintReferences(t *testing.T) {
+	// References to undeclared resources are findings rather than a parse failure
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:1.25\n    restart: always\n    mem_limit: 256m\n    cpus: 0.5\n    volumes: [data-vol:/data]\nvolumes:\n  data_vol: {}\n")
+	result := runCLI(t, "", "lint", "-f", composeFile)
+	if result.code != exitCode(parser.ValidationError) {
+		t.Fatalf("expected the ValidationError exit code, got %d: %s", result.code, result.stderr)
+	}
+	var report lintReport
+	if err := json.Unmarshal([]byte(result.stdout), &report); err != nil {
+		t.Fatal(err)
+	}
+	var findings []string
+	for _, finding := range report.Findings {
+		findings = append(findings, finding.Rule+" "+finding.Code+" "+finding.Location.Path)
+	}
+	if expected := []string{"undefined-references undefined-resource services.web.volumes.0", "unused-resources unused-resource volumes.data_vol"}; !reflect.DeepEqual(findings, expected) {
+		t.Errorf("expected %v, got %v", expected, findings)
+	}
+}
+
+func TestLintPortConflicts(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n"+
+		"  a:\n    image: nginx:1.25\n    ports: [\"8000-8010:8000-8010\"]\n"+
+		"  b:\n    image: nginx:1.25\n    ports:\n      - \"9000:9000\"\n      - \"8005:80\"\n")
+	result := runCLI(t, "", "lint", "--disable", "restart-policy", "--disable", "resource-limits", "-f", composeFile)
+	var report lintReport
+	if err := json.Unmarshal([]byte(result.stdout), &report); err != nil || result.code != exitCode(parser.ValidationError) {
+		t.Fatalf("expected an error finding, got %d: %s: %v", result.code, result.stderr, err)
+	}
+	if len(report.Findings) != 1 {
+		t.Fatalf("expected a port conflict, got %+v", report.Findings)
+	}
+	if finding := report.Findings[0]; finding.Code != parser.PortConflictCode || finding.Location.Path != "services.b.ports" || finding.Location.Line != 7 {
+		t.Errorf("expected the conflict to be located at the ports of b, got %s at %+v", finding.Code, finding.Location)
+	}
+}
+
+func TestLintFailOn(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    restart: always\n    mem_limit: 256m\n    cpus: 0.5\n")
+	// Unpinned images are warnings, which only fail with --fail-on unpinned-images
+	result := runCLI(t, "", "lint", "-f", composeFile)
+	if result.code != 0 || !strings.Contains(result.stdout, `"code": "unpinned-image"`) {
+		t.Errorf("expected an unpinned image warning, got %d: %s", result.code, result.stdout)
+	}
+	if result := runCLI(t, "", "lint", "--fail-on", "unpinned-images", "-f", composeFile); result.code != exitCode(parser.ValidationError) {
+		t.Errorf("expected the ValidationError exit code, got %d: %s", result.code, result.stderr)
+	}
+	runCLI(t, "", "lint", "--fail-on", "tabs", "-f", composeFile).expectError(t, parser.ArgumentError, `Unsupported --fail-on condition "tabs", expected one of: `)
+
+	// Severities lower the threshold of the findings failing the command
+	for _, tt := range []struct {
+		failOn string
+		code   int
+	}{
+		{failOn: parser.ErrorSeverity, code: 0},
+		{failOn: parser.WarningSeverity, code: exitCode(parser.ValidationError)},
+	} {
+		if result := runCLI(t, "", "lint", "--fail-on", tt.failOn, "-f", composeFile); result.code != tt.code {
+			t.Errorf("expected exit code %d with --fail-on %s, got %d: %s", tt.code, tt.failOn, result.code, result.stderr)
+		}
+	}
+}
+
+func TestFailsLint(t *testing.T) {
+	unpinned := parser.Finding{Rule: "image-pinning", Severity: parser.WarningSeverity, Code: parser.UnpinnedImageCode}
+	for _, tt := range []struct {
+		finding  parser.Finding
+		failOn   []string
+		expected bool
+	}{
+		{finding: parser.Finding{Severity: parser.ErrorSeverity}, expected: true},
+		{finding: parser.Finding{Severity: parser.WarningSeverity}},
+		{finding: parser.Finding{Severity: parser.WarningSeverity}, failOn: []string{parser.WarningSeverity}, expected: true},
+		{finding: parser.Finding{Severity: parser.InfoSeverity}, failOn: []string{parser.WarningSeverity}},
+		{finding: parser.Finding{Severity: parser.InfoSeverity}, failOn: []string{parser.InfoSeverity}, expected: true},
+		// The least severe threshold applies, and errors always fail
+		{finding: parser.Finding{Severity: parser.InfoSeverity}, failOn: []string{parser.InfoSeverity, parser.ErrorSeverity}, expected: true},
+		{finding: parser.Finding{Severity: parser.ErrorSeverity}, failOn: []string{"unpinned-images"}, expected: true},
+		{finding: unpinned, failOn: []string{"unpinned-images"}, expected: true},
+		{finding: unpinned},
+	} {
+		if fails := failsLint(tt.finding, tt.failOn); fails != tt.expected {
+			t.Errorf("expected a %s %s finding to fail %t with %v, got %t", tt.finding.Severity, tt.finding.Code, tt.expected, tt.failOn, fails)
+		}
+	}
+}
+
+func TestLintSecurity(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:1.25\n    restart: always\n    mem_limit: 256m\n    cpus: 0.5\n    volumes: [\"/var/run/docker.sock:/var/run/docker.sock\"]\n")
+	// Mounting the engine socket is an error, which the severity of the rule can lower
+	result := runCLI(t, "", "lint", "-f", composeFile)
+	if result.code != exitCode(parser.ValidationError) || !strings.Contains(result.stdout, `"code": "docker-socket"`) {
+		t.Errorf("expected a docker-socket error, got %d: %s", result.code, result.stdout)
+	}
+	if result := runCLI(t, "", "lint", "--severity", "security-docker-socket=warning", "-f", composeFile); result.code != 0 {
+		t.Errorf("expected no error findings, got %d: %s", result.code, result.stdout)
+	}
+}
+
+func TestLintDeviceMemory(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:1.25\n    restart: always\n    mem_limit: 2g\n    cpus: 0.5\n")
+	if result := runCLI(t, "", "lint", "-f", composeFile); strings.Contains(result.stdout, "excessive-limit") {
+		t.Errorf("expected limits not to be checked against the memory of the device without --device-memory, got %s", result.stdout)
+	}
+	result := runCLI(t, "", "lint", "--device-memory", "1g", "-f", composeFile)
+	if result.code != 0 || !strings.Contains(result.stdout, "services.web.mem_limit: 2GiB exceeds the 1GiB of memory of the device") {
+		t.Errorf("expected an excessive limit warning, got %d: %s", result.code, result.stdout)
+	}
+	for _, size := range []string{"lots", "0"} {
+		runCLI(t, "", "lint", "--device-memory", size, "-f", composeFile).expectError(t, parser.ArgumentError, "Device memory must be a positive size such as 1g")
+	}
+}
+
+func TestLintHealthcheck(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:1.25-alpine\n    restart: always\n    mem_limit: 256m\n    cpus: 0.5\n"+
+		"    healthcheck:\n      test: [CMD, curl, -f, http://localhost]\n      retries: 0\n")
+	result := runCLI(t, "", "lint", "-f", composeFile)
+	var report lintReport
+	if err := json.Unmarshal([]byte(result.stdout), &report); err != nil || result.code != exitCode(parser.ValidationError) {
+		t.Fatalf("expected an invalid healthcheck error, got %d: %s: %v", result.code, result.stderr, err)
+	}
+	var findings []string
+	for _, finding := range report.Findings {
+		findings = append(findings, finding.Rule+" "+finding.Severity+" "+finding.Location.Path)
+	}
+	if expected := []string{"healthcheck error services.web.healthcheck.retries", "healthcheck-tools warning services.web.healthcheck.test"}; !reflect.DeepEqual(findings, expected) {
+		t.Errorf("expected %v, got %v", expected, findings)
+	}
+}
+
+func TestLintConfig(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:1.25\n    scale: 2\n    mem_limit: 256m\n    cpus: 0.5\n")
+	rules := func(result cliResult) []string {
+		t.Helper()
+		var report lintReport
+		if err := json.Unmarshal([]byte(result.stdout), &report); err != nil {
+			t.Fatalf("expected a JSON report, got %d: %s", result.code, result.stderr)
+		}
+		var rules []string
+		for _, finding := range report.Findings {
+			rules = append(rules, finding.Rule+" "+finding.Severity)
+		}
+		return rules
+	}
+	writeFile(t, dir, parser.LintConfigFile, "disable: [balena-compatibility]\nseverity:\n  restart-policy: warning\n")
+	other := writeFile(t, dir, "other.yml", "ignore:\n  web: [restart-policy]\n")
+
+	// The configuration of the project directory is read, beneath the command line flags
+	if result := runCLI(t, "", "lint", "-f", composeFile); result.code != 0 || !reflect.DeepEqual(rules(result), []string{"restart-policy warning"}) {
+		t.Errorf("expected the configuration to be applied, got %d: %v", result.code, rules(result))
+	}
+	if result := runCLI(t, "", "lint", "--enable", "balena-compatibility", "--severity", "restart-policy=error", "-f", composeFile); !reflect.DeepEqual(rules(result), []string{"balena-compatibility error", "restart-policy error"}) {
+		t.Errorf("expected the flags to override the configuration, got %v", rules(result))
+	}
+	if result := runCLI(t, "", "lint", "--config", other, "-f", composeFile); !reflect.DeepEqual(rules(result), []string{"balena-compatibility error"}) {
+		t.Errorf("expected the configuration set with --config to be read instead, got %v", rules(result))
+	}
+	if result := runCLI(t, "", "lint", "--no-config", "-f", composeFile); !reflect.DeepEqual(rules(result), []string{"balena-compatibility error", "restart-policy info"}) {
+		t.Errorf("expected no configuration to be read, got %v", rules(result))
+	}
+
+	runCLI(t, "", "lint", "--config", other, "--no-config", "-f", composeFile).expectError(t, parser.ArgumentError, "--config and --no-config can't be combined")
+	runCLI(t, "", "lint", "--config", writeFile(t, dir, "invalid.yml", "enable: [tabs]\n"), "-f", composeFile).expectError(t, parser.ValidationError, `enable: unknown lint rule "tabs"`)
+}
+
+func TestLintMountPaths(t *testing.T) {
+	// balena-compatibility reports every bind mount
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:1.25\n    restart: always\n    mem_limit: 256m\n    cpus: 0.5\n    volumes: [\"/mnt/boot/config.json:/config.json:ro\", \"../shared:/shared:ro\"]\n")
+	result := runCLI(t, "", "lint", "--no-config", "--disable", "balena-compatibility", "-f", composeFile)
+	if result.code != 0 || !strings.Contains(result.stdout, `"code": "sensitive-mount"`) || !strings.Contains(result.stdout, `"code": "mount-escape"`) {
+		t.Errorf("expected sensitive-mount and mount-escape warnings, got %d: %s", result.code, result.stdout)
+	}
+	result = runCLI(t, "", "lint", "--no-config", "--disable", "balena-compatibility", "--allow-mount", "/mnt/boot", "--allow-mount", "../shared", "-f", composeFile)
+	if result.code != 0 || strings.Contains(result.stdout, "security-mount-paths") {
+		t.Errorf("expected the allowed mounts not to be reported, got %d: %s", result.code, result.stdout)
+	}
+	writeFile(t, dir, parser.LintConfigFile, "disable: [balena-compatibility]\nallow_mounts: [/mnt/boot]\n")
+	result = runCLI(t, "", "lint", "--allow-mount", "../shared", "-f", composeFile)
+	if result.code != 0 || strings.Contains(result.stdout, "security-mount-paths") {
+		t.Errorf("expected the mounts allowed by the configuration and flags not to be reported, got %d: %s", result.code, result.stdout)
+	}
+}
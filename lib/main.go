@@ -1,43 +1,741 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"maps"
+	"net/http"
 	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
-	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/sirupsen/logrus"
-)
 
-// ErrorResponse represents error output from the parser
-type ErrorResponse struct {
-	Error   bool   `json:"error"`
-	Name    string `json:"name"`
-	Message string `json:"message"`
-}
+	"balena-compose-parser/pkg/parser"
+)
 
 // Usage message
 const usage = `
-Usage: balena-compose-parser -f <compose-file> [-f <compose-file>...] <project-name>
+Usage: balena-compose-parser [options] -f <compose-file> [-f <compose-file>...] <project-name>
+  balena-compose-parser [options] --tar <archive> [-f <compose-file>...] <project-name>
+  balena-compose-parser [options] --git <repo>#<ref>[:subdir] [-f <compose-file>...] <project-name>
+  balena-compose-parser [options] --oci <reference> <project-name>
+  balena-compose-parser [options] --batch <manifest>
+  balena-compose-parser [options] --serve-stdio
+  balena-compose-parser --version
+  balena-compose-parser --print-schema
+  balena-compose-parser --help
+  balena-compose-parser serve [--listen <address>] [--grpc-listen <address>] [--timeout <duration>] [--log-level <level>] [--quiet]
+  balena-compose-parser release [--contract <path>] [--project-directory <directory>] [-o <path>] -f <compose-file> [-f <compose-file>...]
+  balena-compose-parser migrate [-o <path>] -f <compose-file>
+  balena-compose-parser lint [--enable <rule>] [--disable <rule>] [--severity <rule>=<severity>] [--fail-on <condition>] [--device-memory <size>] [--allow-mount <path>] [--config <file>|--no-config] [--report-format <format>] -f <compose-file> [-f <compose-file>...]
+  balena-compose-parser completion <bash|zsh|fish>
+  balena-compose-parser man
 
 Parses one or more docker-compose files and outputs a structured response.
 
 Arguments:
-  -f <compose-file>  Path to a docker-compose file to parse (can be specified multiple times with later files overriding earlier ones)
-  <project-name>     Name of the project to use for the parsed output. It is recommended to use a UUID, as any fields which include
-                     the project name need to be removed for normalization into a compose acceptable by balena.
+  -f <compose-file>  Path to a docker-compose file to parse (can be specified multiple times with later files overriding earlier ones).
+                     Use "-" to read the compose file from stdin. Multiple documents may be piped in, separated by "---".
+                     Compose files may also be fetched from https:// URLs, and may be written as JSON, which is detected
+                     by a .json extension or content starting with {"...", and checked to be strictly valid JSON.
+                     Files of the docker-compose 2.x format, with version: "2.x", have the fields the compose spec
+                     dropped converted, net to network_mode, log_driver and log_opt to logging, volume_driver to
+                     the driver of the volumes mounted and init paths to true, reported with --warnings.
+  <project-name>     Name of the project to use for the parsed output. Optional with --balena-normalize, which removes the
+                     project name and the network and volume names compose-go derives from it.
+
+Options:
+  --timeout <duration>        Maximum time to spend parsing, e.g. "30s" or "2m" (default "10s").
+                              The default can also be set with the BALENA_COMPOSE_PARSER_TIMEOUT env var.
+  --output-format <format>    Format of the parsed project written to stdout, "json", "yaml", "target-state",
+                              "compose-2.1", "proto" or "proto-text" (default "json").
+                              YAML output is canonical compose YAML, equivalent to "docker compose config".
+                              Target state output is the JSON services, volumes and networks of a release in the balena
+                              supervisor's target state. It implies --target balena and --balena-normalize.
+                              2.1 output is the JSON composition in the docker-compose 2.1 format the supervisor consumes,
+                              with the short syntaxes of the target state and "version": "2.1". cpus is translated into
+                              cpu_quota, while fields added by later formats, e.g. healthcheck.start_period, fail with a
+                              ValidationError. It also implies --target balena and --balena-normalize.
+                              Proto output is the project as the balena.composeparser.v1.Project message of
+                              lib/proto/project.proto, in the binary encoding, with map entries sorted by key, or the
+                              protobuf text format with proto-text. Variables without a value are listed in
+                              unset_environment. Fields the messages don't have, e.g. develop or the swarm fields of
+                              deploy, fail with a ValidationError listing each, rather than being dropped.
+  --output-schema <version>   Shape of the target state output, for supervisors which don't take the latest, "v1", "v2" or
+                              "v3" (default "v3"). v3 keys services by name with their composition in a "composition" field,
+                              v2 keys them by name with the composition inline, and v1 lists them in name order, inline with
+                              their "serviceName".
+  -o <path>                   Write output to a file instead of stdout. The file is written to a temp file and renamed
+                              into place, so it is never left partially written.
+  --compress                  Gzip compress the output, e.g. to speed up piping large projects between processes. On stdout
+                              the compressed output is preceded by a "Content-Encoding: gzip" line, while -o files are plain
+                              gzip files. In --batch mode, the whole NDJSON stream is compressed.
+  --all-errors                Report every YAML, interpolation and schema error found in the compose files, rather than
+                              stopping at the first. Errors are listed in the "errors" array of the error response.
+  --warnings                  Output {"project": {...}, "warnings": [...]} rather than the project alone, where warnings
+                              are non-fatal issues such as unset variables, the obsolete version attribute and deprecated fields.
+                              Keys defined several times in a mapping of a local compose file keep their last definition, with
+                              a "duplicate-key" warning located at each earlier definition, e.g. of a repeated environment.
+                              Warnings of obsolete and deprecated fields, e.g. links, external_links, pull_policy: if_not_present
+                              and io.resin.features labels, have the "replacement" to use.
+                              Unquoted values of environment, labels and build args which YAML coerces, e.g. 0755 into 493,
+                              1.10 into 1.1 or True into true, or which YAML 1.1 parsers read as booleans, e.g. yes and on,
+                              are "coerced-value" warnings with the quoted literal as the "replacement".
+  --env-resolution            Output {"project": {...}, "env_resolution": [...]} rather than the project alone, recording the
+                              source and value of each substituted variable as {"name": "...", "source": "...", "value": "..."},
+                              where source is "override" (--env or --env-json), "balena-device" or "balena-fleet" (--balena-vars),
+                              "os-env", "env-file", "default" or "unset".
+                              Combines with --warnings.
+  --overrides                 Output {"project": {...}, "overrides": [...]} rather than the project alone, recording each field
+                              of a local compose file which a later file overrides as {"path": "...", "previous": ...,
+                              "value": ..., "previousLocation": {...}, "location": {...}}, with values as written in the files,
+                              to debug surprising merge results. Entries of environment, labels and the other mappings are
+                              overridden one by one, command, entrypoint and healthcheck.test as a whole, while lists which
+                              are appended, e.g. ports, aren't overridden. Combines with --warnings.
+  --canonical                 Emit JSON with sorted keys, sorted set-like arrays and no insignificant whitespace,
+                              so that equivalent projects produce byte-identical output.
+  --stable                    Emit canonical JSON which is also stable across parser versions, for the supervisor to diff target
+                              states without spurious service restarts. Fields set to their default, e.g. protocol: tcp or
+                              read_only: false, empty lists and objects and null fields are removed, and paths are cleaned,
+                              e.g. ./app/ to app. Supports JSON and target state output, but not --batch or --watch.
+  --target <platform>         Validate the project against the subset of the compose spec a platform supports. "balena" rejects
+                              fields balenaOS and the supervisor don't support, e.g. links, and deploy fields other than the
+                              GPU requests of deploy.resources.reservations.devices, with a ValidationError
+                              listing each in its "errors" array, with a "code" such as "unsupported-field" and the location
+                              of the field. Fields which are ignored, e.g. container_name, are reported with --warnings.
+                              Service names must be hostnames of lowercase letters, digits, '-' and '_', up to 63 characters.
+                              Images must be valid references, e.g. lowercase, with the "invalid-image" code otherwise.
+                              Volume driver_opts must be ones the local driver mounts, with the "invalid-driver-option" code
+                              otherwise, e.g. for size, which needs quotas, or bind mounts of relative paths, and o mount
+                              options bind mounts ignore, e.g. uid=1000, are "ignored-driver-option" warnings.
+                              Options the balena engine rejects when creating the container also fail: ulimits of unknown
+                              resources or with a soft limit above the hard one ("invalid-ulimit"), and sysctls which
+                              aren't namespaced or of a namespace shared with the host ("invalid-sysctl"), and pid, ipc,
+                              uts, userns_mode and cgroup values which are unknown, reference an IPC namespace which isn't
+                              shareable, as the engine's default-ipc-mode is private, or conflict, e.g. uts: host with a
+                              hostname ("invalid-namespace-mode").
+  --supervisor-version <ver>  Validate depends_on conditions and healthchecks against the supervisor version devices run,
+                              e.g. "v16.4.0", with --target balena. Conditions the version doesn't support fail with the
+                              "incompatible-supervisor" code, e.g. service_healthy before v16.4.0 and
+                              service_completed_successfully before v16.7.0. Only service_started is allowed without a
+                              version, and target state and 2.1 output list dependencies by name unless some have another
+                              condition. healthcheck start_period and start_interval fail with the same code before v12.0.0
+                              and v16.6.0, and are warnings without a version.
+  --os-version <version>      Validate capabilities against the balenaOS version devices run, e.g. "2.113.18", with --target
+                              balena. Capabilities which the version only grants privileged services, e.g. SYS_RAWIO before
+                              v2.88.0, fail with the "privileged-capability" code, and are reported with --warnings without
+                              a version. --target balena also rejects cap_add and cap_drop entries which aren't capabilities,
+                              devices outside /dev or with permissions other than r, w and m, and invalid device_cgroup_rules.
+  --expand-features           Output {"project": {...}, "features": {...}} rather than the project alone, listing the
+                              io.balena.features labels enabled in each service with the mounts, devices and environment
+                              the supervisor adds for them. Feature labels must be "1", "true", "on", "0", "false" or "off".
+                              Unknown features are reported with --warnings. Combines with --warnings and --env-resolution.
+  --policy <policy>           Govern the fields giving services host access, privileged, network_mode: host, pid: host and cap_add
+                              with broad capabilities such as SYS_ADMIN, outputting {"project": {...}, "policy": {...}} with
+                              the "allow", "warn" or "reject" decision on each field set by each service. "strict" rejects
+                              them all, "fleet-default" allows host networking and warns about the others, and "permissive"
+                              allows them all. Rejected fields fail with a ValidationError, and warnings are reported with --warnings.
+  --max-services <count>      Fail with a ValidationError with the "too-many-services" code if the project has more than count
+                              services, located at the first service over the limit in name order.
+  --max-volumes <count>       Fail with a ValidationError with the "too-many-volumes" code if the project has more than count
+                              top-level volumes. Counts must not be negative, and 0, the default, is unlimited.
+  --builds                    Output {"project": {...}, "builds": {...}} rather than the project alone, describing the build of
+                              each service with a build section as the balena builder takes it: the context relative to the
+                              project directory, dockerfile, args, target, platforms and additionalContexts. Local contexts
+                              outside the project directory fail with a ValidationError. Combines with --warnings.
+                              Dockerfile.template Dockerfiles, also used in place of a missing default Dockerfile, are
+                              reported in "template" with the %%VARIABLES%% they reference, and "rendered" for --device-type.
+  --gpu                       Output {"project": {...}, "gpu": {...}} rather than the project alone, listing the GPU requests of
+                              each service, set with gpus or as deploy.resources.reservations.devices with the gpu capability,
+                              normalized as {"path": "...", "driver": "nvidia", "count": ..., "deviceIds": [...],
+                              "capabilities": [...], "options": {...}}, where count is -1 for all GPUs. Combines with --warnings.
+                              Device requests the engine rejects, e.g. of an unknown driver, or capabilities of
+                              the nvidia driver it doesn't have, always fail with an "invalid-device-request" ValidationError.
+  --contract <path>           Merge a balena.yml contract into the output as {"project": {...}, "contract": {...}}, with its
+                              type, slug, name, version, requires and device types. Fails with a ValidationError if the
+                              contract is invalid, or if services set a platform or build platforms for an architecture
+                              other than the one it requires.
+  --defaults <path>           Apply a YAML file of organization-wide defaults beneath the compose files, with the restart,
+                              logging, labels and tmpfs {"size": ...} every service gets unless it sets them, e.g. restart:
+                              unless-stopped. Labels and logging options are applied one by one, logging options only to
+                              services using the defaults' driver, and the size to tmpfs mounts and entries without one.
+                              Outputs {"project": {...}, "defaults": {...}} with the fields set in each service and their
+                              value. Invalid defaults fail with a ValidationError with the "invalid-defaults" code.
+  --schema <path>             Check the project as output, once normalized, also conforms to a JSON Schema of additional
+                              constraints, e.g. requiring labels or forbidding ports, so organizations can enforce their own
+                              rules. Fails with a ValidationError listing each violation with the "schema-violation" code and
+                              the location of the value. Relative $refs are resolved against the directory of the schema.
+  --extension-schema <x-key=path>
+                              Check every extension of a key, e.g. x-balena, at the top level or within services, networks
+                              and the other definitions, conforms to a JSON Schema, rather than passing it through unchecked
+                              (can be specified multiple times). Fails with a ValidationError listing each violation with the
+                              "extension-violation" code and the location of the value within the extension.
+  --device-type <slug>        Check every service can run on devices of a balena device type, e.g. "raspberrypi4-64", failing
+                              with a ValidationError listing each platform, build platforms, image and option for another
+                              architecture, with a "code" such as "platform-mismatch" and the location of the field.
+  --arch <arch>               Check every service can run on devices of an architecture, "aarch64", "amd64", "armv7hf",
+                              "i386" or "rpi", as with --device-type. Both can be set if the device type is of the architecture.
+  --balena-normalize          Produce the composition balena expects, without the top-level name and the <project>_<key> names
+                              compose-go gives networks and volumes which don't set one, as the supervisor names them itself.
+  --private-label <label>     Add an io.balena.private label, as KEY=VAL, to every service (can be specified multiple times).
+                              The namespace is reserved for the supervisor and trusted callers such as the builder, so labels
+                              in it set in compose files always fail with a ValidationError with the "private-label" code.
+  --balena-defaults           Add the settings the supervisor gives services which don't set them, restart: always, the default
+                              network and the io.balena.supervised and io.balena.service-name labels, so the output is
+                              comparable with what runs on the device.
+  --compat-docker             Output the project exactly as "docker compose config" does, discarding env_file entries once
+                              resolved into the environment and dropping unused networks, volumes, secrets and configs.
+                              Combines with --service and --skip-consistency.
+  --watch                     Watch the compose files, env files, defaults and contract, and output the project again
+                              whenever they change. Results are NDJSON lines of {"project": {...}} or {"error": {...}},
+                              with the reports of --warnings, --overrides, --gpu and the other wrapping flags.
+  --project-directory <path>  Resolve relative paths in the compose files, such as bind mounts and build contexts, against a
+                              directory instead of that of the first compose file.
+  --resolve-paths=false       Preserve relative paths, e.g. of build contexts, bind mounts and env files, as written in the
+                              compose files, rather than resolving them to absolute paths against the project directory.
+  --profile <name>            Enable a profile, so services with it are included in the project, or "*" to enable all.
+                              Can be specified multiple times. Defaults to the COMPOSE_PROFILES variable, comma separated.
+  --service <name>            Only output a service and the services it transitively depends on, enabling it if disabled by
+                              its profiles. Can be specified multiple times. Unused networks and volumes are kept.
+  --env-file <path>           Interpolate variables from an env file, e.g. the .env file in the project directory, which
+                              isn't read unless specified. Can be specified multiple times, with later files overriding
+                              earlier ones.
+  --no-os-env                 Don't interpolate variables from the environment of the parser process, so that the output
+                              doesn't depend on the host it runs on. Variables then only come from env files.
+  --env-allow <pattern>       Only interpolate variables from the environment of the parser process whose names match a glob
+                              pattern, e.g. "BALENA_*", ignoring case. Can be specified multiple times.
+  --env-deny <pattern>        Don't interpolate variables from the environment of the parser process whose names match a
+                              glob pattern, e.g. "AWS_*", even if allowed by --env-allow. Can be specified multiple times.
+  --no-dotenv                 Don't interpolate variables from the .env file in the project directory, e.g. if it's untrusted.
+                              As it's only read if specified with --env-file, which is still used, this is the default.
+  --env <KEY=VAL>             Set a variable to interpolate, overriding the environment and env files. Can be specified
+                              multiple times, e.g. to pass the device UUID or fleet slug without writing an env file.
+  --env-json <path|fd>        Set variables to interpolate from a JSON object of {"KEY": "value", ...} read from a file, "-" for
+                              stdin, or an open file descriptor number such as 3, e.g. to pass many variables at once without
+                              hitting command line limits. Variables have the same precedence as --env, which overrides them.
+  --balena-vars <path|fd>     Interpolate the configuration variables of a device, so the output matches what runs on it, from
+                              a JSON object of {"fleet": [...], "device": [...]} read as --env-json is. Each is an array of the
+                              API's variable resources, {"name": "...", "value": "..."}, or its {"d": [...]} response listing them.
+                              Device variables override fleet variables, and both override the environment and env files but
+                              not --env and --env-json. Their source is "balena-device" or "balena-fleet" with --env-resolution.
+  --no-interpolate            Preserve variable references such as ${VAR} verbatim in the output rather than substituting
+                              them, e.g. for the supervisor to substitute per device. Values must still be valid before
+                              interpolation, e.g. ports can't be a variable.
+  --no-normalize              Skip normalization, such as adding the default network, implied depends_on entries and
+                              default build settings, so the output stays structurally close to the compose files.
+  --skip-consistency          Don't check that references, e.g. to undefined networks, volumes, secrets or services, resolve,
+                              so partial or overlay files that don't stand alone can be parsed. Nor that services don't depend
+                              on each other in a cycle, which otherwise fails with a ValidationError with the "dependency-cycle"
+                              code, listing the depends_on, links, network_mode, pid, ipc and volumes_from dependencies
+                              forming it.
+                              Nor that container names are unique, which otherwise fails with the "duplicate-container-name"
+                              code, naming both services, for container_name values which are the same or are the name
+                              docker compose gives a container of another service, e.g. <project>-web-1.
+  --strict-env                Fail with a ParseError listing the variables in its "errors" array if any variable is unset
+                              and has no default, rather than substituting a blank string.
+  --mask-env <pattern>        Replace the values of service environment variables and build args whose names match a glob
+                              pattern with "***" in the output, error responses and logs, ignoring case, along with the
+                              values of matching variables wherever they're interpolated, e.g. in commands and labels. Can be
+                              specified multiple times. "default" matches *_TOKEN, *_PASSWORD, *_SECRET, *_API_KEY and
+                              *_PRIVATE_KEY.
+  --list-variables            Output every ${VAR} reference in the compose files rather than the project, as a JSON array of
+                              {"name": "...", "resolved": true, "defaultValue": "...", "required": false, "location": {...}}.
+                              The project isn't loaded, so required variables are listed even if unset.
+  --from-parsed               Re-parse projects previously output by the parser as JSON, optionally wrapped as {"project": {...}},
+                              e.g. to normalize and validate stored projects again once the parser is updated. They aren't
+                              interpolated again, and the project name defaults to that of the first project.
+  --validate                  Load, interpolate and validate the project, but output only {"valid": true, "warnings": [...]}
+                              rather than the project, e.g. for fast pre-flight checks. If the project is invalid, the report
+                              is {"valid": false, "error": {...}, "warnings": []} with the error response, and the exit code
+                              is that of the error. Combines with --target, --policy, --contract and the other validations.
+  --report-format <format>    Write the --validate report as "json" (default) or "sarif", a SARIF 2.1.0 log for GitHub code
+                              scanning with a result for each error and warning, by the rule of its code, located at the
+                              line of the original compose file, relative to the working directory if beneath it.
+  --hash <services>           Output a stable SHA256 of the configuration of each service rather than the project, as a JSON
+                              object of {"<service>": "<hash>"}, as computed by "docker compose config --hash". Services are
+                              comma separated, or "*" for all. The hash only changes if the container needs to be recreated.
+  --images                    Output the image of each service rather than the project, as a JSON array of {"service": "...",
+                              "image": "...", "reference": "docker.io/...", "platform": "...", "build": false}, where built
+                              services without an image are named <project>-<service>, as docker compose tags them.
+                              Valid references are split into "registry", "repository", "tag" and "digest", e.g. "docker.io",
+                              "library/nginx" and "latest", defaulting the tag to latest only for references without a digest.
+  --resources                 Output the declared resources rather than the project, as a JSON object of {"volumes": [...],
+                              "networks": [...], "secrets": [...], "configs": [...]}, each an array of {"name": "...",
+                              "resourceName": "...", "driver": "...", "external": false}, with the "file" or "environment"
+                              the content of secrets and configs is read from.
+  --host-access               Output the host access requested by each service rather than the project, to audit what the
+                              composition can touch on the device, as a JSON array of {"service": "...", "privileged": false,
+                              "hostNetwork": false, "hostPid": false, "hostIpc": false, "bindMounts": [...], "devices": [...],
+                              "capabilities": [...], "features": [...]}, where bind mounts and devices are host paths, including
+                              those the supervisor adds for the io.balena.features labels listed in "features".
+  --format <template>         Output the result of executing a Go template against the project rather than the project, e.g.
+                              '{{range $name, $s := .Services}}{{$name}} {{range .Ports}}{{.Published}} {{end}}{{println}}{{end}}'.
+                              Fields are those of compose-go's types.Project. The json, join, split, lower, upper, title and
+                              println functions are available, as in docker's --format.
+  --tar <archive>             Parse the project in a tarball, optionally gzip compressed, or "-" to read it from stdin.
+                              -f paths are relative to the archive root, defaulting to docker-compose.yml and its override file.
+  --git <reference>           Shallow clone the repository at <repo>#<ref>[:subdir] and parse the project in it, recording
+                              the resolved commit in the "x-git" top-level field. -f paths are relative to the subdir.
+  --git-timeout <duration>    Maximum time to spend cloning the repository and parsing (default "60s").
+  --oci <reference>           Pull and parse a compose project published as an OCI artifact, e.g. with "docker compose publish".
+                              Registries are accessed anonymously, using the --https-* options.
+  --batch <manifest>          Parse every project listed in a JSON manifest, or "-" to read it from stdin, of the form
+                              [{"files": [...], "projectName": "...", "projectDirectory": "..."}, ...], where projectDirectory
+                              is optional, as with --project-directory. Results are streamed to stdout as NDJSON
+                              lines of {"index": 0, "projectName": "...", "project": {...}} or {..., "error": {...}},
+                              in the order projects finish. Exits non-zero if any project fails to parse.
+  --batch-concurrency <n>     Number of projects to parse concurrently in --batch mode (default: number of CPUs).
+  --serve-stdio               Stay resident and serve newline-delimited JSON-RPC 2.0 requests on stdin, writing responses to stdout.
+                              The "parse" and "validate" methods accept {"files": [...], "projectName": "...", "timeout": "10s"}.
+                              Batch requests aren't supported, and each request must fit in 1 MiB.
+  --https-timeout <duration>  Maximum time to spend fetching each compose file from an https:// URL (default "10s").
+  --https-ca-cert <path>      PEM encoded CA certificate(s) to trust in addition to the system roots when fetching compose files.
+  --https-insecure            Skip TLS certificate verification when fetching compose files.
+  --progress-fd <fd>          Write progress events as NDJSON to an open file descriptor, e.g. 3, as parsing moves through
+                              the "loading", "fetching", "including", "extending", "interpolating", "validating" and
+                              "done" phases. Events are {"projectName": "...", "phase": "...", "files": [...]}.
+  --log-level <level>         Minimum level of logs written to stderr, including those of compose-go, one of "panic",
+                              "fatal", "error", "warn", "info", "debug" or "trace" (default "info").
+  --quiet                     Don't write any logs to stderr, leaving only the error response if parsing fails.
+  --version                   Print the parser version, compose-go version, compose-spec schema digest and git commit as JSON.
+  --print-schema              Print {"input": {...}, "output": {...}}, the JSON Schemas of the compose files the parser accepts,
+                              the compose spec schema it validates against, and of the documents it outputs, e.g. to generate
+                              types. The output schema defines each document in "$defs", "project", "wrappedProject" (with
+                              --warnings and the other reports), "targetState", "images", "resources", "hostAccess",
+                              "batchResult", "lintReport", "validationReport" and "error", and matches any of them.
+
+Serve options:
+  --listen <address>          Address for the HTTP server to listen on (default ":8080"). POST /parse accepts either
+                              a JSON body {"projectName": "...", "files": [{"name": "...", "content": "..."}]}, or
+                              multipart form data with a "projectName" field and one "file" part per compose file.
+                              Set to "" to disable the HTTP server.
+  --grpc-listen <address>     Address for the gRPC server to listen on, disabled by default. The ComposeParser service
+                              is defined in lib/proto/parser.proto.
+                              Requests of both servers are parsed without the environment and .env file of the server,
+                              and their env_file, label_file, include, extends.file and build context paths must be
+                              relative paths within the project, without variables.
+
+Release options:
+  -f <compose-file>           Path to a compose file of the release, later files overriding earlier ones.
+  --contract <path>           Contract merged into the release, by default the balena.yml or balena.yaml in the project
+                              directory if there is one.
+  --project-directory <dir>   Directory build contexts are made relative to (default the directory of the first compose file).
+  --env-file <file>           Interpolate variables from an env file, e.g. the project's .env file. Variables aren't
+                              interpolated from the environment, which the builder doesn't see.
+
+Migrate options:
+  -f <compose-file>           Path to the compose file to migrate, or "-" to read it from stdin.
+  -o <path>                   Write the migrated file to path instead of stdout, replacing it atomically.
+
+Lint options:
+  -f <compose-file>           Path to a compose file to lint, later files overriding earlier ones.
+  --enable <rule>             Run a rule which is disabled by default (can be specified multiple times).
+  --disable <rule>            Don't run a rule, even if enabled (can be specified multiple times).
+  --severity <rule=severity>  Report the findings of a rule with a severity, "error", "warning" or "info".
+  --fail-on <condition>       Fail with the exit code of a ValidationError on findings of a severity or more severe, "error"
+                              (default), "warning" or "info", e.g. warning in CI to keep development permissive. With
+                              "unpinned-images", also fail on the image-pinning findings of images pulled by the latest tag.
+  --device-memory <size>      Memory of the devices the project runs on, e.g. "1g", for the resource-limits rule to report
+                              mem_limit and mem_reservation exceeding it, besides services without memory and CPU limits.
+  --allow-mount <path>        Allow services to bind mount a host path, and the paths beneath it, which the
+                              security-mount-paths rule otherwise reports if relative and outside the project directory,
+                              e.g. ../shared, or a sensitive balenaOS path, e.g. /mnt/boot or /etc (can be specified multiple
+                              times). Relative paths are relative to the project directory, as in the compose files.
+  --config <file>             Read the lint configuration from a file instead of .balena-compose-lint.yml in the project
+                              directory, read if it exists. The configuration is a YAML mapping of the rules to "enable" and
+                              "disable", the "severity" of rules by ID and the rules to "ignore" the findings of by service,
+                              e.g. {"ignore": {"main": ["security-privileged"]}}, and the mounts to "allow_mounts", as with
+                              --allow-mount. Flags override the configuration.
+  --no-config                 Don't read a lint configuration file.
+  --report-format <format>    Write the findings as "json" (default) or "sarif", a SARIF 2.1.0 log with the lint rules and a
+                              result for each finding and warning, as with --validate.
+  --list-rules                Print the rules as JSON, [{"id": "...", "severity": "...", "description": "..."}], with
+                              "disabled": true for those disabled by default.
+  --project-directory <dir>   Directory relative paths are resolved against (default the directory of the first compose file).
+  --env-file <file>           Interpolate variables from an env file, e.g. the project's .env file.
+
+Commands:
+  serve                       Serve parse requests over HTTP and/or gRPC, see "Serve options".
+  release                     Print {"composition": {...}, "contract": {...}}, the composition document stored with a balenaCloud
+                              release and its contract, see "Release options". The project is validated with --target balena
+                              and normalized with --balena-normalize, build contexts are made relative to the project
+                              directory, and the composition is canonical JSON, as derived by the builder.
+  migrate                     Rewrite a compose file of the 1, 2.x or 3.x format as compose spec YAML, see "Migrate options".
+                              version is removed, services of the 1 format are moved under services, links are converted to
+                              depends_on and network aliases on the default network, cpus, mem_limit, pids_limit and
+                              mem_reservation are moved into deploy.resources, and the fields converted when parsing 2.x
+                              files are converted. Comments are kept, and each field converted is logged as a warning.
+  lint                        Print {"findings": [...], "warnings": [...]}, the issues lint rules find in a project parsed for
+                              balena, see "Lint options". Findings are {"rule": "...", "severity": "...", "message": "..."},
+                              with the "code" of the issue and its "location". Severities are "error", "warning" and "info",
+                              and the exit code is that of a ValidationError if any finding is an error, or of the --fail-on
+                              severity. References to undeclared volumes, networks, configs and secrets are findings of the
+                              undefined-references rule rather than a ValidationError, suggesting a declared resource of a
+                              similar name.
+  completion <shell>          Print a completion script for bash, zsh or fish, e.g. to load it into the current shell:
+                              source <(balena-compose-parser completion bash)
+  man                         Print the balena-compose-parser(1) man page in roff format, e.g. to view it:
+                              balena-compose-parser man | man -l -
+
+Exit codes:
+  0  Success
+  1  ConfigError, the parser failed to set up or run its servers
+  2  ArgumentError, invalid command line arguments or request
+  3  ParseError, a compose file is not valid YAML or failed to load
+  4  TimeoutError, parsing exceeded --timeout
+  5  ValidationError, the project doesn't conform to the compose spec or --target, or sets options the engine rejects
+     when creating the container: invalid GPU requests, and pid and ipc references to undefined services
+     ("invalid-namespace-mode")
+  6  IOError, a file or remote resource couldn't be read or written
 
 Example:
   balena-compose-parser -f docker-compose.yml -f docker-compose.override.yml my-project-name
+  cat docker-compose.yml | balena-compose-parser -f - my-project-name
 `
 
+// Supported values for --output-format
+const (
+	formatJSON        = "json"
+	formatYAML        = "yaml"
+	formatTargetState = "target-state"
+	formatCompose21   = "compose-2.1"
+	formatProto       = "proto"
+	formatProtoText   = "proto-text"
+)
+
+var outputFormats = []string{formatJSON, formatYAML, formatTargetState, formatCompose21, formatProto, formatProtoText}
+
+// Env var which overrides the default parse timeout, superseded by --timeout
+const timeoutEnvVar = "BALENA_COMPOSE_PARSER_TIMEOUT"
+
+// composeFileFlag collects the compose file paths from repeated -f flags, in order
+type composeFileFlag []string
+
+func (f *composeFileFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *composeFileFlag) Set(path string) error {
+	// Stdin can only be consumed once, so multiple documents must be piped in as one stream
+	if path == parser.StdinPath && slices.Contains(*f, parser.StdinPath) {
+		return errors.New("stdin can only be specified once with -f -, separate multiple documents with ---")
+	}
+	*f = append(*f, path)
+	return nil
+}
+
+// stringListFlag collects the values of a repeated flag, in order
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// hashFlag holds the services of --hash, nil for all of them with "*", and whether it was given, as even
+// an empty value selects the hash output
+type hashFlag struct {
+	services []string
+	set      bool
+}
+
+func (f *hashFlag) String() string {
+	if f.set && f.services == nil {
+		return "*"
+	}
+	return strings.Join(f.services, ",")
+}
+
+func (f *hashFlag) Set(value string) error {
+	f.set, f.services = true, nil
+	if value == "*" {
+		return nil
+	}
+	for _, service := range strings.Split(value, ",") {
+		if service == "" {
+			return fmt.Errorf("expected service names, comma separated, or \"*\" for all, got %q", value)
+		}
+		f.services = append(f.services, service)
+	}
+	return nil
+}
+
+// envFlag collects the values of repeated KEY=VAL flags such as --env, later values overriding earlier ones
+type envFlag map[string]string
+
+func (f *envFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	var variables []string
+	for _, key := range slices.Sorted(maps.Keys(*f)) {
+		variables = append(variables, key+"="+(*f)[key])
+	}
+	return strings.Join(variables, ",")
+}
+
+func (f *envFlag) Set(variable string) error {
+	key, value, ok := strings.Cut(variable, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected KEY=VAL, got %q", variable)
+	}
+	if *f == nil {
+		*f = envFlag{}
+	}
+	(*f)[key] = value
+	return nil
+}
+
+// parseFlags are the command line flags for parsing compose files
+type parseFlags struct {
+	composeFiles      composeFileFlag
+	timeout           time.Duration
+	outputFormat      string
+	outputSchema      string
+	canonical         bool
+	stable            bool
+	serveStdioMode    bool
+	printVersion      bool
+	printSchema       bool
+	validate          bool
+	reportFormat      string
+	httpsTimeout      time.Duration
+	httpsCACert       string
+	httpsInsecure     bool
+	tarPath           string
+	gitReference      string
+	gitTimeout        time.Duration
+	ociReference      string
+	outputPath        string
+	allErrors         bool
+	warnings          bool
+	logLevel          string
+	quiet             bool
+	progressFD        int
+	watch             bool
+	batchManifest     string
+	batchConcurrency  int
+	envFiles          stringListFlag
+	noOSEnv           bool
+	noDotEnv          bool
+	env               envFlag
+	listVariables     bool
+	noInterpolate     bool
+	strictEnv         bool
+	maskEnv           stringListFlag
+	envAllow          stringListFlag
+	envDeny           stringListFlag
+	envJSON           string
+	balenaVars        string
+	envResolution     bool
+	overrides         bool
+	expandFeatures    bool
+	contract          string
+	builds            bool
+	gpu               bool
+	policy            string
+	defaults          string
+	extraSchema       string
+	extensionSchemas  envFlag
+	maxServices       int
+	maxVolumes        int
+	supervisorVersion string
+	osVersion         string
+	deviceType        string
+	arch              string
+	projectDirectory  string
+	profiles          stringListFlag
+	services          stringListFlag
+	noNormalize       bool
+	skipConsistency   bool
+	resolvePaths      bool
+	compatDocker      bool
+	hash              hashFlag
+	images            bool
+	resources         bool
+	hostAccess        bool
+	format            string
+	fromParsed        bool
+	compress          bool
+	balenaNormalize   bool
+	balenaDefaults    bool
+	privateLabels     envFlag
+	target            string
+}
+
+// The parser options set by the flags, shared by batch and single project parses, which set the project
+// name and directory
+func (o *parseFlags) parserOptions(httpsClient *http.Client, environment map[string]string, balenaVariables *parser.BalenaVariables, progress func(parser.ProgressEvent)) parser.Options {
+	return parser.Options{
+		Timeout:           o.timeout,
+		HTTPSClient:       httpsClient,
+		AllErrors:         o.allErrors,
+		Warnings:          o.warnings,
+		Progress:          progress,
+		EnvFiles:          o.envFiles,
+		NoOSEnv:           o.noOSEnv,
+		NoDotEnv:          o.noDotEnv,
+		Environment:       environment,
+		BalenaVariables:   balenaVariables,
+		NoInterpolate:     o.noInterpolate,
+		NoNormalize:       o.noNormalize,
+		SkipConsistency:   o.skipConsistency,
+		StrictEnv:         o.strictEnv,
+		MaskEnv:           maskPatterns(o.maskEnv),
+		EnvAllow:          o.envAllow,
+		EnvDeny:           o.envDeny,
+		EnvResolution:     o.envResolution,
+		Overrides:         o.overrides,
+		ExpandFeatures:    o.expandFeatures,
+		Contract:          o.contract,
+		Builds:            o.builds,
+		GPU:               o.gpu,
+		Policy:            o.policy,
+		Defaults:          o.defaults,
+		ExtraSchema:       o.extraSchema,
+		ExtensionSchemas:  o.extensionSchemas,
+		MaxServices:       o.maxServices,
+		MaxVolumes:        o.maxVolumes,
+		SupervisorVersion: o.supervisorVersion,
+		OSVersion:         o.osVersion,
+		DeviceType:        o.deviceType,
+		Arch:              o.arch,
+		Profiles:          o.profiles,
+		RelativePaths:     !o.resolvePaths,
+		DockerCompatible:  o.compatDocker,
+		BalenaNormalize:   o.balenaNormalize,
+		BalenaDefaults:    o.balenaDefaults,
+		PrivateLabels:     o.privateLabels,
+		Target:            o.target,
+		Services:          o.services,
+	}
+}
+
+// Create the flag set for parsing compose files, storing values in o.
+// Usage strings are used for shell completion and the man page, with backquoted argument names.
+func newParseFlagSet(o *parseFlags) *flag.FlagSet {
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	// Errors are reported via outputError instead of the flag package's own output
+	flags.SetOutput(io.Discard)
+	flags.Var(&o.composeFiles, "f", "Path to a `compose-file` to parse, or \"-\" for stdin, later files overriding earlier ones")
+	flags.DurationVar(&o.timeout, "timeout", parser.DefaultTimeout, "Maximum `duration` to spend parsing")
+	flags.StringVar(&o.outputFormat, "output-format", formatJSON, "Output `format`, \"json\", \"yaml\", \"target-state\", \"compose-2.1\", \"proto\" or \"proto-text\"")
+	flags.StringVar(&o.outputSchema, "output-schema", "", "Schema `version` of target state output, \"v1\", \"v2\" or \"v3\"")
+	flags.BoolVar(&o.canonical, "canonical", false, "Emit canonical JSON, so that equivalent projects produce byte-identical output")
+	flags.BoolVar(&o.stable, "stable", false, "Emit canonical JSON without defaults, empty fields and unclean paths, so output can be diffed across parser versions")
+	flags.StringVar(&o.target, "target", "", "Validate the project against the fields a `platform`, e.g. \"balena\", supports")
+	flags.StringVar(&o.supervisorVersion, "supervisor-version", "", "Validate depends_on conditions and healthchecks against the supervisor `version` devices run")
+	flags.StringVar(&o.osVersion, "os-version", "", "Validate capabilities against the balenaOS `version` devices run")
+	flags.BoolVar(&o.expandFeatures, "expand-features", false, "Output the mounts, devices and environment implied by io.balena.features labels alongside the project")
+	flags.StringVar(&o.policy, "policy", "", "Allow, warn about or reject host access fields with a `policy`, \"strict\", \"fleet-default\" or \"permissive\"")
+	flags.IntVar(&o.maxServices, "max-services", 0, "Fail if the project has more than `count` services")
+	flags.IntVar(&o.maxVolumes, "max-volumes", 0, "Fail if the project has more than `count` volumes")
+	flags.BoolVar(&o.builds, "builds", false, "Output the build of each service as the balena builder takes it alongside the project")
+	flags.BoolVar(&o.gpu, "gpu", false, "Output the normalized GPU requests of each service alongside the project")
+	flags.StringVar(&o.defaults, "defaults", "", "Apply the organization-wide defaults at `path` beneath the compose files")
+	flags.StringVar(&o.extraSchema, "schema", "", "Check the output conforms to the additional constraints of the JSON Schema at `path`")
+	flags.Var(&o.extensionSchemas, "extension-schema", "Check extensions of a key conform to a JSON Schema, as `x-key=path` (can be specified multiple times)")
+	flags.StringVar(&o.contract, "contract", "", "Merge the balena.yml contract at `path` into the output, checking the project meets its requirements")
+	flags.StringVar(&o.deviceType, "device-type", "", "Check every service can run on devices of a balena device type `slug`")
+	flags.StringVar(&o.arch, "arch", "", "Check every service can run on devices of an `architecture`, e.g. \"aarch64\"")
+	flags.BoolVar(&o.balenaNormalize, "balena-normalize", false, "Remove the project name and the network and volume names derived from it, as balena expects")
+	flags.Var(&o.privateLabels, "private-label", "Add an io.balena.private `label` as KEY=VAL to every service, for trusted callers")
+	flags.BoolVar(&o.balenaDefaults, "balena-defaults", false, "Add the settings the supervisor gives services which don't set them, e.g. restart: always")
+	flags.BoolVar(&o.compatDocker, "compat-docker", false, "Output the project as \"docker compose config\" would")
+	flags.BoolVar(&o.serveStdioMode, "serve-stdio", false, "Serve newline-delimited JSON-RPC 2.0 requests on stdin")
+	flags.BoolVar(&o.printVersion, "version", false, "Print version information as JSON")
+	flags.BoolVar(&o.printSchema, "print-schema", false, "Print the JSON Schemas of the accepted compose files and of the output documents")
+	flags.DurationVar(&o.httpsTimeout, "https-timeout", 10*time.Second, "Maximum `duration` to spend fetching each compose file from an https:// URL")
+	flags.StringVar(&o.httpsCACert, "https-ca-cert", "", "Additional CA certificates to trust when fetching compose files, as a PEM encoded `path`")
+	flags.BoolVar(&o.httpsInsecure, "https-insecure", false, "Skip TLS certificate verification when fetching compose files")
+	flags.StringVar(&o.tarPath, "tar", "", "Parse the project in a tarball `archive`, or \"-\" for stdin")
+	flags.StringVar(&o.gitReference, "git", "", "Parse the project in a git repository at a <repo>#<ref>[:subdir] `reference`")
+	flags.DurationVar(&o.gitTimeout, "git-timeout", 60*time.Second, "Maximum `duration` to spend cloning the repository and parsing")
+	flags.StringVar(&o.ociReference, "oci", "", "Parse a compose project published as an OCI artifact `reference`")
+	flags.StringVar(&o.outputPath, "o", "", "Write output atomically to `path` instead of stdout")
+	flags.BoolVar(&o.compress, "compress", false, "Gzip compress the output")
+	flags.BoolVar(&o.allErrors, "all-errors", false, "Report every error found, rather than stopping at the first")
+	flags.BoolVar(&o.warnings, "warnings", false, "Output non-fatal warnings alongside the project")
+	flags.BoolVar(&o.envResolution, "env-resolution", false, "Output the source and value of each substituted variable alongside the project")
+	flags.BoolVar(&o.overrides, "overrides", false, "Output the fields of compose files which later files override alongside the project")
+	flags.StringVar(&o.logLevel, "log-level", logrus.InfoLevel.String(), "Minimum `level` of logs written to stderr")
+	flags.BoolVar(&o.quiet, "quiet", false, "Don't write any logs to stderr")
+	flags.IntVar(&o.progressFD, "progress-fd", 0, "Write progress events as NDJSON to the file descriptor `fd`")
+	flags.BoolVar(&o.watch, "watch", false, "Output the project again whenever its files change")
+	flags.StringVar(&o.batchManifest, "batch", "", "Parse every project listed in a JSON `manifest`, or \"-\" for stdin")
+	flags.IntVar(&o.batchConcurrency, "batch-concurrency", runtime.NumCPU(), "Parse `n` projects concurrently in --batch mode")
+	flags.StringVar(&o.projectDirectory, "project-directory", "", "Resolve relative paths against the `directory`, instead of that of the first compose file")
+	flags.BoolVar(&o.resolvePaths, "resolve-paths", true, "Resolve relative paths to absolute paths against the project directory")
+	flags.Var(&o.profiles, "profile", "Enable a `profile`, including its services in the project")
+	flags.Var(&o.services, "service", "Only output the `service` and the services it depends on")
+	flags.Var(&o.envFiles, "env-file", "Interpolate variables from an env `file`, e.g. the project's .env file, later files overriding earlier ones")
+	flags.BoolVar(&o.noOSEnv, "no-os-env", false, "Don't interpolate variables from the environment of the parser")
+	flags.Var(&o.envAllow, "env-allow", "Only interpolate variables from the environment of the parser whose names match a glob `pattern`")
+	flags.Var(&o.envDeny, "env-deny", "Don't interpolate variables from the environment of the parser whose names match a glob `pattern`")
+	flags.BoolVar(&o.noDotEnv, "no-dotenv", false, "Don't interpolate variables from the project's .env file, the default unless specified with --env-file")
+	flags.Var(&o.env, "env", "Set an interpolation `variable` as KEY=VAL, overriding the environment and env files")
+	flags.StringVar(&o.balenaVars, "balena-vars", "", "Interpolate the fleet and device variables of a device, as the balena API returns them, from a JSON `file`")
+	flags.StringVar(&o.envJSON, "env-json", "", "Set interpolation variables from a JSON object in a `file`, or read from a file descriptor number")
+	flags.Var(&o.hash, "hash", "Output the configuration hash of each `service`, comma separated, or \"*\" for all, rather than the project")
+	flags.BoolVar(&o.images, "images", false, "Output the image of each service, rather than the project")
+	flags.BoolVar(&o.resources, "resources", false, "Output the volumes, networks, secrets and configs of the project, rather than the project")
+	flags.BoolVar(&o.hostAccess, "host-access", false, "Output the host access requested by each service, rather than the project")
+	flags.StringVar(&o.format, "format", "", "Output the result of executing a Go `template` against the project, rather than the project")
+	flags.BoolVar(&o.validate, "validate", false, "Output only whether the project is valid, with its warnings, rather than the project")
+	flags.StringVar(&o.reportFormat, "report-format", reportFormatJSON, "Write the --validate report as `format`, \"json\" or \"sarif\"")
+	flags.BoolVar(&o.fromParsed, "from-parsed", false, "Re-parse projects previously output by the parser, e.g. to validate them again")
+	flags.BoolVar(&o.listVariables, "list-variables", false, "Output every variable referenced in the compose files, rather than the project")
+	flags.BoolVar(&o.noInterpolate, "no-interpolate", false, "Preserve variable references such as ${VAR} verbatim in the output")
+	flags.BoolVar(&o.skipConsistency, "skip-consistency", false, "Don't check references to undefined resources, so partial and overlay files can be parsed")
+	flags.BoolVar(&o.noNormalize, "no-normalize", false, "Don't normalize the project, so the output stays structurally close to the compose files")
+	flags.BoolVar(&o.strictEnv, "strict-env", false, "Fail if any variable is unset and has no default, rather than substituting a blank string")
+	flags.Var(&o.maskEnv, "mask-env", "Mask the values of variables whose names match a glob `pattern`, or the default patterns with \"default\"")
+	return flags
+}
+
+// subcommand is run by its name as the first command line argument, with the remaining arguments
+type subcommand struct {
+	run         func(args []string)
+	description string
+}
+
+// Subcommands by name
+var subcommands map[string]subcommand
+
+func init() {
+	// Assigned in init, as the completion and man subcommands refer back to this table
+	subcommands = map[string]subcommand{
+		"serve":      {runServe, "Serve parse requests over HTTP and/or gRPC"},
+		"release":    {runRelease, "Print the composition document stored with a balenaCloud release"},
+		"migrate":    {runMigrate, "Rewrite a legacy compose file as compose spec YAML"},
+		"lint":       {runLint, "Report composition quality issues found by lint rules"},
+		"completion": {runCompletion, "Print a completion script for bash, zsh or fish"},
+		"man":        {runMan, "Print the man page in roff format"},
+	}
+}
+
 func main() {
-	if len(os.Args) < 4 {
-		outputError("ArgumentError", usage)
-		os.Exit(1)
+	if len(os.Args) < 2 {
+		fail(parser.ArgumentError, usage)
 	}
 
 	// Format logs outputted from compose-go to JSON
@@ -49,94 +747,818 @@ func main() {
 		},
 	})
 
-	var composeFiles []string
-	var projectName string
+	if command, ok := subcommands[os.Args[1]]; ok {
+		command.run(os.Args[2:])
+		return
+	}
 
 	// Parse command line arguments
-	i := 1
-	for i < len(os.Args) {
-		if os.Args[i] == "-f" {
-			if i+1 >= len(os.Args) {
-				outputError("ArgumentError", "Missing file path after -f flag\n"+usage)
-				os.Exit(1)
+	var o parseFlags
+	flags := newParseFlagSet(&o)
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stdout, usage)
+			return
+		}
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if err := configureLogging(o.logLevel, o.quiet); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+
+	if o.printVersion {
+		json.NewEncoder(os.Stdout).Encode(versionInfo())
+		return
+	}
+	if o.printSchema {
+		printSchema()
+		return
+	}
+	if err := applyTimeoutEnv(flags, &o.timeout); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if o.compatDocker && o.canonical {
+		fail(parser.ArgumentError, "--canonical can't be used with --compat-docker, which keeps the key order of \"docker compose config\"\n"+usage)
+	}
+	if o.stable && (o.outputFormat != formatJSON && o.outputFormat != formatTargetState || o.compatDocker || o.batchManifest != "" || o.watch || o.hash.set || o.images || o.resources || o.hostAccess || o.format != "") {
+		fail(parser.ArgumentError, "--stable is only supported with JSON and target state output of a single project\n"+usage)
+	}
+
+	if o.validate && (o.batchManifest != "" || o.watch || o.listVariables || o.hash.set || o.images || o.resources || o.hostAccess || o.format != "" ||
+		o.envResolution || o.overrides || o.expandFeatures || o.builds || o.gpu || o.stable || o.canonical || o.compress || o.outputFormat != formatJSON) {
+		fail(parser.ArgumentError, "--validate can't be used with --batch, --watch, --list-variables, the summary modes, --env-resolution, --overrides, --expand-features, --builds, --gpu, --stable, --canonical or --compress, and only supports JSON output\n"+usage)
+	}
+
+	if !slices.Contains(reportFormats, o.reportFormat) {
+		fail(parser.ArgumentError, fmt.Sprintf("Unsupported report format %q, expected one of: %s\n", o.reportFormat, strings.Join(reportFormats, ", "))+usage)
+	}
+	if o.reportFormat != reportFormatJSON && !o.validate {
+		fail(parser.ArgumentError, "--report-format is only supported with --validate\n"+usage)
+	}
+
+	// In daemon mode, compose files and project name are provided per request
+	if o.serveStdioMode {
+		if len(o.composeFiles) > 0 || flags.NArg() > 0 {
+			fail(parser.ArgumentError, "Compose files and project name must be provided per request in --serve-stdio mode\n"+usage)
+		}
+		if o.outputPath != "" || o.compress {
+			fail(parser.ArgumentError, "-o and --compress can't be used with --serve-stdio, responses are written to stdout\n"+usage)
+		}
+		if err := serveStdio(os.Stdin, os.Stdout, o.timeout); err != nil {
+			fail(parser.ArgumentError, fmt.Sprintf("Failed to read requests from stdin: %v", err))
+		}
+		return
+	}
+
+	// Variables from --env-json are overridden by those from --env
+	environment := map[string]string(o.env)
+	if o.envJSON != "" {
+		if (o.envJSON == parser.StdinPath || o.envJSON == "0") && (slices.Contains(o.composeFiles, parser.StdinPath) || o.tarPath == parser.StdinPath || o.batchManifest == parser.StdinPath) {
+			fail(parser.ArgumentError, "Stdin can't be used for both --env-json and the project\n"+usage)
+		}
+		var err error
+		if environment, err = readEnvJSON(o.envJSON); err != nil {
+			exitWithError(err)
+		}
+		maps.Copy(environment, o.env)
+	}
+	var balenaVariables *parser.BalenaVariables
+	if o.balenaVars != "" {
+		if (o.balenaVars == parser.StdinPath || o.balenaVars == "0") && (o.envJSON == parser.StdinPath || o.envJSON == "0" || slices.Contains(o.composeFiles, parser.StdinPath) || o.tarPath == parser.StdinPath || o.batchManifest == parser.StdinPath) {
+			fail(parser.ArgumentError, "Stdin can't be used for both --balena-vars and the project or --env-json\n"+usage)
+		}
+		data, err := readJSONInput(o.balenaVars, "balena variables")
+		if err == nil {
+			balenaVariables, err = parser.ParseBalenaVariables(data)
+		}
+		if err != nil {
+			exitWithError(err)
+		}
+	}
+
+	// In batch mode, compose files and project name are provided per project in the manifest
+	if o.batchManifest != "" {
+		if len(o.composeFiles) > 0 || flags.NArg() > 0 || o.tarPath != "" || o.gitReference != "" || o.ociReference != "" || o.projectDirectory != "" {
+			fail(parser.ArgumentError, "Compose files, project name and project directory must be provided per project in the manifest in --batch mode\n"+usage)
+		}
+		if o.outputFormat != formatJSON {
+			fail(parser.ArgumentError, "--batch only supports JSON output\n"+usage)
+		}
+		if o.timeout <= 0 || o.batchConcurrency <= 0 {
+			fail(parser.ArgumentError, fmt.Sprintf("Timeout and batch concurrency must be positive, got %s and %d\n", o.timeout, o.batchConcurrency)+usage)
+		}
+		httpsClient, err := newHTTPSClient(o.httpsTimeout, o.httpsCACert, o.httpsInsecure)
+		if err != nil {
+			fail(parser.ArgumentError, err.Error()+"\n"+usage)
+		}
+		progress, err := progressWriter(o.progressFD)
+		if err != nil {
+			fail(parser.ArgumentError, err.Error()+"\n"+usage)
+		}
+		projects, err := readBatchManifest(o.batchManifest)
+		if err != nil {
+			exitWithError(err)
+		}
+
+		// With -o, results are only visible at the destination once every project has been parsed
+		var output io.Writer = os.Stdout
+		var outputFile *atomicFile
+		if o.outputPath != "" {
+			if outputFile, err = createAtomicFile(o.outputPath); err != nil {
+				fail(parser.IOError, err.Error())
 			}
-			composeFiles = append(composeFiles, os.Args[i+1])
-			i += 2
-		} else {
-			// The last non-flag argument should be the project name
-			projectName = os.Args[i]
-			i++
-			break
+			output = outputFile
+		}
+		var compressed *gzip.Writer
+		if o.compress {
+			if outputFile == nil {
+				fmt.Fprint(os.Stdout, compressedHeader)
+			}
+			compressed = gzip.NewWriter(output)
+			output = compressed
+		}
+
+		// Projects are parsed from the directories of their own compose files
+		options := o.parserOptions(httpsClient, environment, balenaVariables, progress)
+		failed := runBatch(projects, o.batchConcurrency, output, options, o.canonical)
+		if compressed != nil {
+			if err := compressed.Close(); err != nil {
+				fail(parser.IOError, fmt.Sprintf("Failed to write output: %v", err))
+			}
+		}
+		if outputFile != nil {
+			if err := outputFile.Commit(); err != nil {
+				fail(parser.IOError, err.Error())
+			}
+		}
+		if failed > 0 {
+			fail(parser.ParseError, fmt.Sprintf("%d of %d projects failed to parse", failed, len(projects)))
+		}
+		return
+	}
+
+	// Validate we have at least one compose file and a project name,
+	// with compose files in project archives and repositories discovered by name if not specified
+	if len(o.composeFiles) == 0 && o.tarPath == "" && o.gitReference == "" && o.ociReference == "" {
+		fail(parser.ArgumentError, "At least one compose file must be specified with -f\n"+usage)
+	}
+
+	// Target state and 2.1 compositions are balena releases, so must only use fields balena supports and
+	// omit the project name
+	if o.outputFormat == formatTargetState || o.outputFormat == formatCompose21 {
+		if o.target != "" && o.target != parser.BalenaTarget {
+			fail(parser.ArgumentError, fmt.Sprintf("--output-format %s can only be used with --target %s\n", o.outputFormat, parser.BalenaTarget)+usage)
+		}
+		o.target = parser.BalenaTarget
+		o.balenaNormalize = true
+	}
+
+	// The only non-flag argument should be the project name, which previously parsed projects include
+	if !o.fromParsed && !o.balenaNormalize && (flags.NArg() == 0 || flags.Arg(0) == "") {
+		fail(parser.ArgumentError, "Project name is required\n"+usage)
+	}
+	if flags.NArg() > 1 {
+		fail(parser.ArgumentError, fmt.Sprintf("Unexpected arguments after project name: %v\n", flags.Args()[1:])+usage)
+	}
+	projectName := flags.Arg(0)
+
+	if o.timeout <= 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Timeout must be positive, got %s\n", o.timeout)+usage)
+	}
+
+	if !slices.Contains(outputFormats, o.outputFormat) {
+		fail(parser.ArgumentError, fmt.Sprintf("Unsupported output format %q, expected one of: %s\n", o.outputFormat, strings.Join(outputFormats, ", "))+usage)
+	}
+
+	if o.outputSchema != "" && o.outputFormat != formatTargetState {
+		fail(parser.ArgumentError, fmt.Sprintf("--output-schema is only supported with --output-format %s\n", formatTargetState)+usage)
+	}
+	if o.outputSchema == "" {
+		o.outputSchema = parser.TargetStateV3
+	}
+	if !slices.Contains(parser.TargetStateSchemas, o.outputSchema) {
+		fail(parser.ArgumentError, fmt.Sprintf("Unsupported output schema %q, expected one of: %s\n", o.outputSchema, strings.Join(parser.TargetStateSchemas, ", "))+usage)
+	}
+
+	if o.canonical && o.outputFormat != formatJSON {
+		fail(parser.ArgumentError, "--canonical is only supported with JSON output\n"+usage)
+	}
+	// Modes outputting a summary of the project rather than the project itself
+	var summaries []string
+	for _, mode := range []struct {
+		flag string
+		set  bool
+	}{{"--hash", o.hash.set}, {"--images", o.images}, {"--resources", o.resources}, {"--host-access", o.hostAccess}, {"--format", o.format != ""}} {
+		if mode.set {
+			summaries = append(summaries, mode.flag)
 		}
 	}
+	if len(summaries) > 1 {
+		fail(parser.ArgumentError, fmt.Sprintf("Only one of %s can be specified\n", strings.Join(summaries, ", "))+usage)
+	}
+	if len(summaries) > 0 && (o.listVariables || o.watch || o.warnings || o.envResolution || o.overrides || o.expandFeatures || o.contract != "" || o.builds || o.gpu || o.policy != "" || o.defaults != "" || o.outputFormat != formatJSON) {
+		fail(parser.ArgumentError, summaries[0]+" can't be used with --list-variables, --watch, --warnings, --env-resolution, --overrides, --expand-features, --contract, --builds, --gpu, --policy or --defaults, and only supports JSON output\n"+usage)
+	}
+	var tmpl *template.Template
+	if o.format != "" {
+		var err error
+		if tmpl, err = parseTemplate(o.format); err != nil {
+			fail(parser.ArgumentError, err.Error()+"\n"+usage)
+		}
+	}
+	if o.noOSEnv && (len(o.envAllow) > 0 || len(o.envDeny) > 0) {
+		fail(parser.ArgumentError, "--env-allow and --env-deny can't be used with --no-os-env\n"+usage)
+	}
+	if o.strictEnv && o.noInterpolate {
+		fail(parser.ArgumentError, "--strict-env can't be used with --no-interpolate\n"+usage)
+	}
+	if (o.warnings || o.envResolution || o.overrides || o.expandFeatures || o.contract != "" || o.builds || o.gpu || o.policy != "" || o.defaults != "") &&
+		(o.outputFormat == formatYAML || o.outputFormat == formatProto || o.outputFormat == formatProtoText) {
+		fail(parser.ArgumentError, "--warnings, --env-resolution, --overrides, --expand-features, --contract, --builds, --gpu, --policy and --defaults are only supported with JSON, target state and 2.1 output\n"+usage)
+	}
 
-	// Validate we have at least one compose file and a project name
-	if len(composeFiles) == 0 {
-		outputError("ArgumentError", "At least one compose file must be specified with -f\n"+usage)
-		os.Exit(1)
+	httpsClient, err := newHTTPSClient(o.httpsTimeout, o.httpsCACert, o.httpsInsecure)
+	if err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	progress, err := progressWriter(o.progressFD)
+	if err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
 	}
 
-	if projectName == "" {
-		outputError("ArgumentError", "Project name is required\n"+usage)
-		os.Exit(1)
+	inputSources := 0
+	for _, source := range []string{o.tarPath, o.gitReference, o.ociReference} {
+		if source != "" {
+			inputSources++
+		}
+	}
+	if inputSources > 1 {
+		fail(parser.ArgumentError, "Only one of --tar, --git and --oci can be specified\n"+usage)
+	}
+	// Compose artifacts define their own compose files
+	if inputSources > 0 && o.projectDirectory != "" {
+		fail(parser.ArgumentError, "--project-directory can't be used with --tar, --git or --oci\n"+usage)
+	}
+	if o.ociReference != "" && len(o.composeFiles) > 0 {
+		fail(parser.ArgumentError, "-f can't be used with --oci\n"+usage)
+	}
+	if o.tarPath == parser.StdinPath && slices.Contains(o.composeFiles, parser.StdinPath) {
+		fail(parser.ArgumentError, "Stdin can't be used for both --tar and -f\n"+usage)
+	}
+	if o.fromParsed && (inputSources > 0 || o.listVariables || o.watch) {
+		fail(parser.ArgumentError, "--from-parsed only supports compose files specified with -f, and can't be used with --list-variables or --watch\n"+usage)
 	}
 
-	// Create a timeout context - 10 seconds timeout for parsing
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	options := o.parserOptions(httpsClient, environment, balenaVariables, progress)
+	options.ProjectName = projectName
+	options.ProjectDirectory = o.projectDirectory
+	// Validation reports include the warnings of valid projects
+	options.Warnings = o.warnings || o.validate
+	p := parser.New(options)
+	if o.listVariables {
+		if inputSources > 0 || o.watch || o.warnings || o.outputFormat != formatJSON {
+			fail(parser.ArgumentError, "--list-variables only supports compose files specified with -f, and JSON output\n"+usage)
+		}
+		variables, err := p.ListVariables(context.Background(), o.composeFiles)
+		if err != nil {
+			exitWithError(err)
+		}
+		output, err := json.MarshalIndent(variables, "", "  ")
+		if err != nil {
+			fail(parser.ParseError, fmt.Sprintf("Failed to marshal variables to JSON: %v", err))
+		}
+		if err := writeOutput(o.outputPath, output, o.compress); err != nil {
+			fail(parser.IOError, err.Error())
+		}
+		return
+	}
+	if o.watch {
+		if inputSources > 0 || o.outputPath != "" || o.compress || o.outputFormat != formatJSON {
+			fail(parser.ArgumentError, "--watch only supports local compose files specified with -f, and uncompressed JSON output to stdout\n"+usage)
+		}
+		for _, composeFile := range o.composeFiles {
+			if composeFile == parser.StdinPath || strings.HasPrefix(composeFile, "https://") {
+				fail(parser.ArgumentError, fmt.Sprintf("Can't watch %s, --watch only supports local compose files\n", composeFile)+usage)
+			}
+		}
+		// The defaults and contract are read on every parse, like the env files
+		files := slices.Clone(o.envFiles)
+		for _, file := range []string{o.defaults, o.contract} {
+			if file != "" {
+				files = append(files, file)
+			}
+		}
+		if err := runWatch(p, o.composeFiles, files, o.canonical, os.Stdout); err != nil {
+			fail(parser.IOError, fmt.Sprintf("Failed to watch compose files: %v", err))
+		}
+		return
+	}
+
+	var result *parser.Result
+	switch {
+	case o.tarPath != "":
+		result, err = parseArchive(p, o.tarPath, o.composeFiles)
+	case o.gitReference != "":
+		result, err = parseGit(p, o.gitReference, o.gitTimeout, o.composeFiles)
+	case o.ociReference != "":
+		result, err = p.ParseOCI(context.Background(), o.ociReference)
+	case o.fromParsed:
+		result, err = p.ParseParsed(context.Background(), o.composeFiles)
+	default:
+		result, err = p.Parse(context.Background(), o.composeFiles)
+	}
+	if o.validate {
+		writeValidationReport(o.outputPath, o.reportFormat, result, err)
+		return
+	}
+	if err != nil {
+		exitWithError(err)
+	}
+
+	if o.hash.set {
+		hashes, err := parser.ServiceHashes(result.Project, o.hash.services)
+		if err != nil {
+			exitWithError(err)
+		}
+		output, err := json.MarshalIndent(hashes, "", "  ")
+		if err != nil {
+			fail(parser.ParseError, fmt.Sprintf("Failed to marshal service hashes to JSON: %v", err))
+		}
+		if err := writeOutput(o.outputPath, output, o.compress); err != nil {
+			fail(parser.IOError, err.Error())
+		}
+		return
+	}
+
+	if tmpl != nil {
+		output, err := executeTemplate(tmpl, result.Project)
+		if err != nil {
+			fail(parser.ArgumentError, err.Error())
+		}
+		if err := writeOutput(o.outputPath, output, o.compress); err != nil {
+			fail(parser.IOError, err.Error())
+		}
+		return
+	}
+	if o.images || o.resources || o.hostAccess {
+		var summary any = parser.Images(result.Project)
+		switch {
+		case o.resources:
+			summary = parser.ListResources(result.Project)
+		case o.hostAccess:
+			summary = parser.ListHostAccess(result.Project)
+		}
+		output, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			fail(parser.ParseError, fmt.Sprintf("Failed to marshal %s to JSON: %v", summaries[0], err))
+		}
+		if err := writeOutput(o.outputPath, output, o.compress); err != nil {
+			fail(parser.IOError, err.Error())
+		}
+		return
+	}
+
+	// Get the requested representation using the project's marshal methods
+	var output []byte
+	switch {
+	case o.outputFormat == formatTargetState:
+		output, err = marshalTargetState(result.Project, o.outputSchema, o.stable)
+	case o.outputFormat == formatCompose21:
+		var composition map[string]any
+		if composition, err = parser.ToCompose21(result.Project, o.composeFiles); err != nil {
+			// Fields without a 2.1 equivalent are reported as the ValidationError
+			exitWithError(err)
+		}
+		output, err = json.MarshalIndent(composition, "", "  ")
+	case o.outputFormat == formatProto || o.outputFormat == formatProtoText:
+		if err := parser.CheckProto(result.Project, o.composeFiles); err != nil {
+			// Fields without a message field are reported as the ValidationError, rather than dropped
+			exitWithError(err)
+		}
+		output, err = marshalProject(result.Project, o.outputFormat, o.canonical)
+	case o.stable:
+		if output, err = marshalProject(result.Project, formatJSON, false); err == nil {
+			output, err = parser.StableJSON(output)
+		}
+	default:
+		output, err = marshalProject(result.Project, o.outputFormat, o.canonical)
+	}
+	if err == nil && (o.warnings || o.envResolution || o.overrides || o.expandFeatures || o.builds || o.gpu || result.Contract != nil || result.Policy != nil || result.Defaults != nil) {
+		output, err = wrapProject(output, result, o.warnings, o.envResolution, o.overrides, o.expandFeatures, o.builds, o.gpu)
+	}
+	if err != nil {
+		fail(parser.ParseError, fmt.Sprintf("Failed to marshal compose project to %s: %v", strings.ToUpper(o.outputFormat), err))
+	}
+
+	// Output the parsed project directly to stdout, or the -o file
+	if err := writeOutput(o.outputPath, output, o.compress); err != nil {
+		fail(parser.IOError, err.Error())
+	}
+}
+
+// Parse the project tarball at the given path, or from stdin if "-"
+func parseArchive(p *parser.Parser, tarPath string, composeFiles []string) (*parser.Result, error) {
+	archive := os.Stdin
+	if tarPath != parser.StdinPath {
+		f, err := os.Open(tarPath)
+		if err != nil {
+			return nil, &parser.Error{Name: parser.IOError, Message: fmt.Sprintf("Failed to open project archive: %v", err), Err: err}
+		}
+		defer f.Close()
+		archive = f
+	}
+	return p.ParseArchive(context.Background(), archive, composeFiles)
+}
+
+// Clone and parse the repository at the given <repo>#<ref>[:subdir] reference
+func parseGit(p *parser.Parser, reference string, timeout time.Duration, composeFiles []string) (*parser.Result, error) {
+	source, err := parser.ParseGitSource(reference)
+	if err != nil {
+		return nil, &parser.Error{Name: parser.ArgumentError, Message: err.Error(), Err: err}
+	}
+	if slices.Contains(composeFiles, parser.StdinPath) {
+		return nil, &parser.Error{Name: parser.ArgumentError, Message: "Stdin can't be used as a compose file with --git"}
+	}
+	if timeout <= 0 {
+		return nil, &parser.Error{Name: parser.ArgumentError, Message: fmt.Sprintf("Git timeout must be positive, got %s", timeout)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
+	return p.ParseGit(ctx, *source, composeFiles)
+}
+
+// serveFlags are the command line flags of the serve subcommand
+type serveFlags struct {
+	listen     string
+	grpcListen string
+	timeout    time.Duration
+	logLevel   string
+	quiet      bool
+}
+
+// Create the flag set of the serve subcommand, storing values in o
+func newServeFlagSet(o *serveFlags) *flag.FlagSet {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&o.listen, "listen", ":8080", "`Address` for the HTTP server to listen on, or \"\" to disable it")
+	flags.StringVar(&o.grpcListen, "grpc-listen", "", "`Address` for the gRPC server to listen on")
+	flags.DurationVar(&o.timeout, "timeout", parser.DefaultTimeout, "Maximum `duration` to spend parsing each request")
+	flags.StringVar(&o.logLevel, "log-level", logrus.InfoLevel.String(), "Minimum `level` of logs written to stderr")
+	flags.BoolVar(&o.quiet, "quiet", false, "Don't write any logs to stderr")
+	return flags
+}
+
+// Run the HTTP server subcommand
+func runServe(args []string) {
+	var o serveFlags
+	flags := newServeFlagSet(&o)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stdout, usage)
+			return
+		}
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if err := configureLogging(o.logLevel, o.quiet); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if flags.NArg() > 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Unexpected arguments: %v\n", flags.Args())+usage)
+	}
+	if err := applyTimeoutEnv(flags, &o.timeout); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if o.timeout <= 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Timeout must be positive, got %s\n", o.timeout)+usage)
+	}
+
+	if o.listen == "" && o.grpcListen == "" {
+		fail(parser.ArgumentError, "At least one of --listen or --grpc-listen must be specified\n"+usage)
+	}
+
+	// Run the HTTP and gRPC servers side by side, exiting as soon as either fails
+	errChan := make(chan error, 2)
+	if o.listen != "" {
+		go func() {
+			if err := serveHTTP(o.listen, o.timeout); err != nil {
+				errChan <- fmt.Errorf("HTTP server failed: %w", err)
+			}
+		}()
+	}
+	if o.grpcListen != "" {
+		go func() {
+			if err := serveGRPC(o.grpcListen, o.timeout); err != nil {
+				errChan <- fmt.Errorf("gRPC server failed: %w", err)
+			}
+		}()
+	}
+	fail(parser.ConfigError, (<-errChan).Error())
+}
+
+// Read the JSON of a flag such as --env-json, described by what, from a file, stdin or an open file descriptor
+func readJSONInput(source, what string) ([]byte, error) {
+	var r io.Reader = os.Stdin
+	if fd, err := strconv.Atoi(source); err == nil {
+		f := os.NewFile(uintptr(fd), what)
+		if _, err := f.Stat(); err != nil {
+			return nil, &parser.Error{Name: parser.IOError, Message: fmt.Sprintf("Invalid %s file descriptor %d: %v", what, fd, err), Err: err}
+		}
+		defer f.Close()
+		r = f
+	} else if source != parser.StdinPath {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, &parser.Error{Name: parser.IOError, Message: fmt.Sprintf("Failed to open %s: %v", what, err), Err: err}
+		}
+		defer f.Close()
+		r = f
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &parser.Error{Name: parser.IOError, Message: fmt.Sprintf("Failed to read %s: %v", what, err), Err: err}
+	}
+	return data, nil
+}
+
+// Read the variables of --env-json from a file, stdin or an open file descriptor. Numbers and booleans
+// are converted to their text, as they would be if written in an env file.
+func readEnvJSON(source string) (map[string]string, error) {
+	data, err := readJSONInput(source, "env JSON")
+	if err != nil {
+		return nil, err
+	}
+
+	var variables map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&variables); err != nil {
+		return nil, &parser.Error{Name: parser.ArgumentError, Message: fmt.Sprintf("Invalid env JSON: %v", err), Err: err}
+	}
+	environment := make(map[string]string, len(variables))
+	for name, value := range variables {
+		switch value := value.(type) {
+		case string:
+			environment[name] = value
+		case json.Number, bool:
+			environment[name] = fmt.Sprint(value)
+		default:
+			return nil, &parser.Error{Name: parser.ArgumentError, Message: fmt.Sprintf("Invalid env JSON: value of %q must be a string, number or boolean", name)}
+		}
+	}
+	return environment, nil
+}
+
+// Expand the "default" --mask-env pattern to the parser's default patterns
+func maskPatterns(patterns []string) []string {
+	var expanded []string
+	for _, pattern := range patterns {
+		if pattern == "default" {
+			expanded = append(expanded, parser.DefaultMaskPatterns...)
+		} else {
+			expanded = append(expanded, pattern)
+		}
+	}
+	return expanded
+}
+
+// Create the callback writing progress events as NDJSON to the given file descriptor, or nil if 0.
+// Stdin and stdout are reserved for compose files and output.
+func progressWriter(fd int) (func(parser.ProgressEvent), error) {
+	if fd == 0 {
+		return nil, nil
+	}
+	if fd < 2 {
+		return nil, fmt.Errorf("Progress file descriptor must be 2 or greater, got %d", fd)
+	}
+	f := os.NewFile(uintptr(fd), "progress")
+	if _, err := f.Stat(); err != nil {
+		return nil, fmt.Errorf("Invalid progress file descriptor %d: %v", fd, err)
+	}
+
+	var mu sync.Mutex
+	encoder := json.NewEncoder(f)
+	return func(event parser.ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		encoder.Encode(event)
+	}, nil
+}
 
-	options, err := cli.NewProjectOptions(
-		composeFiles,
-		cli.WithOsEnv,
-		cli.WithDotEnv,
-		cli.WithName(projectName),
-	)
+// Set the verbosity of logs, including those of compose-go, which logs through the standard logrus logger.
+// Quiet discards logs entirely, leaving only error responses on stderr.
+func configureLogging(level string, quiet bool) error {
+	parsed, err := logrus.ParseLevel(level)
 	if err != nil {
-		outputError("ConfigError", fmt.Sprintf("Failed to create compose project options: %v", err))
-		os.Exit(1)
+		return fmt.Errorf("Invalid log level %q, expected one of: panic, fatal, error, warn, info, debug, trace", level)
 	}
+	logrus.SetLevel(parsed)
+	if quiet {
+		logrus.SetOutput(io.Discard)
+	}
+	return nil
+}
 
-	// Channel to receive the result from the goroutine
-	type loadResult struct {
-		project *types.Project
-		err     error
+// Apply the default timeout set by env var, unless --timeout was given. This runs after the flags are parsed,
+// so an invalid value doesn't prevent --help or --version.
+func applyTimeoutEnv(flags *flag.FlagSet, timeout *time.Duration) error {
+	envTimeout, ok := os.LookupEnv(timeoutEnvVar)
+	if !ok {
+		return nil
 	}
-	resultChan := make(chan loadResult, 1)
+	explicit := false
+	flags.Visit(func(f *flag.Flag) {
+		explicit = explicit || f.Name == "timeout"
+	})
+	if explicit {
+		return nil
+	}
+	parsed, err := time.ParseDuration(envTimeout)
+	if err != nil {
+		return fmt.Errorf("Invalid %s value %q: %v", timeoutEnvVar, envTimeout, err)
+	}
+	*timeout = parsed
+	return nil
+}
 
-	// Run LoadProject in a goroutine
-	go func() {
-		project, err := options.LoadProject(ctx)
-		resultChan <- loadResult{project: project, err: err}
-	}()
+// Create the client used to fetch compose files referenced by https:// URL
+func newHTTPSClient(timeout time.Duration, caCertPath string, insecure bool) (*http.Client, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("HTTPS timeout must be positive, got %s", timeout)
+	}
 
-	// Wait for either the result or timeout
-	var project *types.Project
-	select {
-	case result := <-resultChan:
-		if result.err != nil {
-			outputError("ParseError", fmt.Sprintf("Failed to parse compose file: %v", result.err))
-			os.Exit(1)
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Opt-in only, for registries of compose files behind self-signed certificates
+		InsecureSkipVerify: insecure,
+	}
+	if caCertPath != "" {
+		pem, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read CA certificate: %v", err)
 		}
-		project = result.project
-	case <-ctx.Done():
-		outputError("TimeoutError", "Compose file parsing timed out after 10 seconds")
-		os.Exit(1)
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No PEM certificates found in %s", caCertPath)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// Never follow redirects to plain HTTP
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if request.URL.Scheme != "https" {
+				return fmt.Errorf("refusing redirect to non-HTTPS URL %s", request.URL)
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		},
+	}, nil
+}
+
+// Serialize the project in the given output format
+func marshalProject(project *types.Project, format string, canonical bool) ([]byte, error) {
+	switch format {
+	case formatYAML:
+		return project.MarshalYAML()
+	case formatProto, formatProtoText:
+		return marshalProjectProto(project, format == formatProtoText)
+	default:
+		return parser.MarshalProject(project, canonical)
 	}
+}
+
+// Serialize the project as a release in the supervisor's target state, in the shape of a schema version,
+// as canonical JSON without non-semantic variance if stable
+func marshalTargetState(project *types.Project, schema string, stable bool) ([]byte, error) {
+	state, err := parser.ToTargetState(project)
+	if err != nil {
+		return nil, err
+	}
+	if stable {
+		state.Stabilize()
+	}
+	shaped, err := state.Schema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if !stable {
+		return json.MarshalIndent(shaped, "", "  ")
+	}
+	stateJSON, err := json.Marshal(shaped)
+	if err != nil {
+		return nil, err
+	}
+	return parser.CanonicalJSON(stateJSON)
+}
+
+// Wrap marshalled project JSON with the reports requested with --warnings, --env-resolution, --overrides,
+// --expand-features, --builds and --gpu, which are output empty rather than omitted if there's nothing to
+// report, and the --contract contract, --policy decisions and --defaults fields
+func wrapProject(projectJSON []byte, result *parser.Result, warnings, envResolution, overrides, features, builds, gpu bool) ([]byte, error) {
+	output := map[string]any{"project": json.RawMessage(projectJSON)}
+	if warnings {
+		output["warnings"] = append([]parser.Warning{}, result.Warnings...)
+	}
+	if envResolution {
+		output["env_resolution"] = append([]parser.VariableResolution{}, result.EnvResolution...)
+	}
+	if overrides {
+		output["overrides"] = append([]parser.Override{}, result.Overrides...)
+	}
+	if features {
+		output["features"] = result.Features
+	}
+	if builds {
+		output["builds"] = result.Builds
+	}
+	if gpu {
+		output["gpu"] = result.GPU
+	}
+	if result.Contract != nil {
+		output["contract"] = result.Contract
+	}
+	if result.Policy != nil {
+		output["policy"] = result.Policy
+	}
+	if result.Defaults != nil {
+		output["defaults"] = result.Defaults
+	}
+	return json.Marshal(output)
+}
 
-	// Get JSON representation using project's MarshalJSON method
-	projectJSON, err := project.MarshalJSON()
+// validationReport is the output of --validate, whether the project is valid, with the error response if
+// it isn't and the warnings if it is
+type validationReport struct {
+	Valid    bool                  `json:"valid"`
+	Error    *parser.ErrorResponse `json:"error,omitempty"`
+	Warnings []parser.Warning      `json:"warnings"`
+}
+
+// Write the validation report of a parse result or error in a report format, exiting with the exit code of
+// the error if the project isn't valid
+func writeValidationReport(outputPath, format string, result *parser.Result, err error) {
+	report := validationReport{Valid: err == nil, Warnings: []parser.Warning{}}
 	if err != nil {
-		outputError("ParseError", fmt.Sprintf("Failed to marshal compose project to JSON: %v", err))
-		os.Exit(1)
+		report.Error = parser.NewErrorResponse(err)
+	} else {
+		report.Warnings = append(report.Warnings, result.Warnings...)
+	}
+	var document any = report
+	if format == reportFormatSARIF {
+		document = validationSarif(report)
+	}
+	output, _ := json.MarshalIndent(document, "", "  ")
+	if err := writeOutput(outputPath, output, false); err != nil {
+		fail(parser.IOError, err.Error())
+	}
+	if report.Error != nil {
+		os.Exit(exitCode(report.Error.Name))
 	}
+}
 
-	// Output the parsed project directly to stdout
-	os.Stdout.Write(projectJSON)
+// Write the structured error response for a parser error to stderr and exit
+func exitWithError(err error) {
+	response := parser.NewErrorResponse(err)
+	json.NewEncoder(os.Stderr).Encode(response)
+	os.Exit(exitCode(response.Name))
+}
+
+// Write a structured error response to stderr and exit with the error's exit code
+func fail(errorName, message string) {
+	outputError(errorName, message)
+	os.Exit(exitCode(errorName))
+}
+
+// Exit codes for each error name, so that callers can branch on the category of failure
+var exitCodes = map[string]int{
+	parser.ConfigError:     1,
+	parser.ArgumentError:   2,
+	parser.ParseError:      3,
+	parser.TimeoutError:    4,
+	parser.ValidationError: 5,
+	parser.IOError:         6,
+}
+
+func exitCode(errorName string) int {
+	if code, ok := exitCodes[errorName]; ok {
+		return code
+	}
+	return 1
 }
 
 // Write a structured error response to stderr
 func outputError(errorName, message string) {
-	response := ErrorResponse{
+	response := parser.ErrorResponse{
 		Error:   true,
 		Name:    errorName,
 		Message: message,
@@ -0,0 +1,1410 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Set in the environment of the test binary to run the CLI instead of the tests, so tests can check
+// its output and exit code in a subprocess
+const runMainEnv = "BALENA_COMPOSE_PARSER_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) != "" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// The outcome of a run of the CLI
+type cliResult struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+// Run the CLI with arguments, reading stdin, in the current directory
+func runCLI(t *testing.T, stdin string, args ...string) cliResult {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("failed to run %v: %v", args, err)
+	}
+	return cliResult{stdout: stdout.String(), stderr: stderr.String(), code: cmd.ProcessState.ExitCode()}
+}
+
+// Decode the error response of a failed run, which follows any logs on stderr
+func (r cliResult) errorResponse(t *testing.T) parser.ErrorResponse {
+	t.Helper()
+	lines := strings.Split(strings.TrimSpace(r.stderr), "\n")
+	var response parser.ErrorResponse
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &response); err != nil || !response.Error {
+		t.Fatalf("expected an error response on stderr, got %q", r.stderr)
+	}
+	return response
+}
+
+// Check a run failed with an error of a name, exiting with its exit code, whose message contains message
+func (r cliResult) expectError(t *testing.T, name, message string) parser.ErrorResponse {
+	t.Helper()
+	if r.code != exitCode(name) {
+		t.Fatalf("expected exit code %d, got %d: %s", exitCode(name), r.code, r.stderr)
+	}
+	response := r.errorResponse(t)
+	if response.Name != name || !strings.Contains(response.Message, message) {
+		t.Errorf("expected %s containing %q, got %s: %q", name, message, response.Name, response.Message)
+	}
+	return response
+}
+
+// Decode the JSON output of a successful run
+func (r cliResult) output(t *testing.T) map[string]any {
+	t.Helper()
+	if r.code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", r.code, r.stderr)
+	}
+	var output map[string]any
+	if err := json.Unmarshal([]byte(r.stdout), &output); err != nil {
+		t.Fatalf("expected JSON output, got %q: %v", r.stdout, err)
+	}
+	return output
+}
+
+// Write a file into dir, returning its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Get the value at a dotted path of keys and list indices in decoded JSON
+func lookup(value any, path string) any {
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func TestStdin(t *testing.T) {
+	base := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    command: [base]\n")
+
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		image   string
+		command []any
+	}{
+		{
+			name:  "single document",
+			stdin: "services:\n  web:\n    image: alpine\n",
+			args:  []string{"-f", "-", "p"},
+			image: "alpine",
+		},
+		{
+			name:    "documents override earlier ones",
+			stdin:   "services:\n  web:\n    image: alpine\n    command: [first]\n---\nservices:\n  web:\n    command: [second]\n",
+			args:    []string{"-f", "-", "p"},
+			image:   "alpine",
+			command: []any{"second"},
+		},
+		{
+			name:    "overriding a file",
+			stdin:   "services:\n  web:\n    command: [stdin]\n",
+			args:    []string{"-f", base, "-f", "-", "p"},
+			image:   "nginx",
+			command: []any{"stdin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := runCLI(t, tt.stdin, tt.args...).output(t)
+			if image := lookup(output, "services.web.image"); image != tt.image {
+				t.Errorf("expected image %q, got %v", tt.image, image)
+			}
+			if command, ok := lookup(output, "services.web.command").([]any); tt.command != nil && (!ok || len(command) != 1 || command[0] != tt.command[0]) {
+				t.Errorf("expected command %v, got %v", tt.command, command)
+			}
+		})
+	}
+}
+
+func TestStdinOnce(t *testing.T) {
+	runCLI(t, "services: {}\n", "-f", "-", "-f", "-", "p").expectError(t, parser.ArgumentError, "stdin can only be specified once")
+}
+
+func TestTimeout(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n")
+
+	tests := []struct {
+		name    string
+		env     string
+		args    []string
+		err     string
+		message string
+	}{
+		{name: "flag", args: []string{"--timeout", "1m"}},
+		{name: "env var", env: "1m"},
+		{name: "exceeded", args: []string{"--timeout", "1ns"}, err: parser.TimeoutError, message: "timed out after 1ns"},
+		{name: "exceeded with env var", env: "1ns", err: parser.TimeoutError, message: "timed out after 1ns"},
+		{name: "flag overrides env var", env: "1ns", args: []string{"--timeout", "1m"}},
+		{name: "invalid flag", args: []string{"--timeout", "soon"}, err: parser.ArgumentError, message: "invalid value \"soon\" for flag -timeout"},
+		{name: "invalid env var", env: "soon", err: parser.ArgumentError, message: "Invalid BALENA_COMPOSE_PARSER_TIMEOUT value \"soon\""},
+		{name: "not positive", args: []string{"--timeout", "0s"}, err: parser.ArgumentError, message: "Timeout must be positive, got 0s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.env != "" {
+				t.Setenv(timeoutEnvVar, tt.env)
+			}
+			result := runCLI(t, "", append(tt.args, "-f", composeFile, "p")...)
+			if tt.err == "" {
+				result.output(t)
+			} else {
+				result.expectError(t, tt.err, tt.message)
+			}
+		})
+	}
+
+	// The env var is only checked once the flags are parsed, and when it would be used
+	t.Setenv(timeoutEnvVar, "soon")
+	for _, args := range [][]string{{"--help"}, {"--version"}, {"serve", "--help"}, {"lint", "--help"}, {"release", "--help"}} {
+		if result := runCLI(t, "", args...); result.code != 0 {
+			t.Errorf("expected %v to succeed with an invalid env var, got %d: %s", args, result.code, result.stderr)
+		}
+	}
+	runCLI(t, "", "lint", "-f", composeFile).expectError(t, parser.ArgumentError, "Invalid BALENA_COMPOSE_PARSER_TIMEOUT value \"soon\"")
+}
+
+func TestYAMLOutput(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    ports: [\"80:8080\"]\n    environment:\n      A: \"1\"\nvolumes:\n  data: {}\n")
+
+	result := runCLI(t, "", "--output-format", "yaml", "-f", composeFile, "p")
+	if result.code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", result.code, result.stderr)
+	}
+	var document any
+	if err := yaml.Unmarshal([]byte(result.stdout), &document); err != nil {
+		t.Fatalf("expected YAML output, got %q: %v", result.stdout, err)
+	}
+	for path, expected := range map[string]any{
+		"name":                       "p",
+		"services.web.image":         "nginx",
+		"services.web.environment.A": "1",
+		"volumes.data.name":          "p_data",
+		"networks.default.name":      "p_default",
+	} {
+		if value := lookup(document, path); value != expected {
+			t.Errorf("expected %s to be %v, got %v", path, expected, value)
+		}
+	}
+	if ports, _ := lookup(document, "services.web.ports").([]any); len(ports) != 1 || lookup(ports[0], "target") != 8080 {
+		t.Errorf("expected the long syntax of the port, got %v", ports)
+	}
+
+	// The YAML output is itself a compose file
+	reparsed := writeFile(t, t.TempDir(), "compose.yml", result.stdout)
+	if output := runCLI(t, "", "-f", reparsed, "p").output(t); lookup(output, "services.web.environment.A") != "1" {
+		t.Errorf("expected the YAML output to parse, got %v", output)
+	}
+}
+
+func TestUnsupportedOutputFormat(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n")
+	runCLI(t, "", "--output-format", "xml", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Unsupported output format \"xml\"")
+}
+
+func TestCanonical(t *testing.T) {
+	dir := t.TempDir()
+	first := writeFile(t, dir, "first.yml", "services:\n  web:\n    image: nginx\n    cap_add: [SYS_ADMIN, NET_ADMIN]\n    environment:\n      B: b\n      A: a\n")
+	second := writeFile(t, dir, "second.yml", "services:\n  web:\n    environment:\n      A: a\n      B: b\n    cap_add: [NET_ADMIN, SYS_ADMIN]\n    image: nginx\n")
+
+	// Equivalent projects have byte-identical output
+	expected := runCLI(t, "", "--canonical", "-f", first, "p")
+	if expected.code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", expected.code, expected.stderr)
+	}
+	if result := runCLI(t, "", "--canonical", "-f", second, "p"); result.stdout != expected.stdout {
+		t.Errorf("expected %s, got %s", expected.stdout, result.stdout)
+	}
+
+	runCLI(t, "", "--canonical", "--output-format", "yaml", "-f", first, "p").expectError(t, parser.ArgumentError, "--canonical is only supported with JSON output")
+	runCLI(t, "", "--canonical", "--compat-docker", "-f", first, "p").expectError(t, parser.ArgumentError, "--canonical can't be used with --compat-docker")
+}
+
+func TestStable(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "web/Dockerfile", "FROM nginx\n")
+	first := writeFile(t, dir, "first.yml", "services:\n  web:\n    build: ./web/\n    privileged: false\n    ports: [\"8080:80\"]\n    volumes: [\"data:/data/\"]\nvolumes:\n  data: {}\n")
+	second := writeFile(t, dir, "second.yml", "services:\n  web:\n    build:\n      context: web\n      dockerfile: Dockerfile\n    ports:\n      - target: 80\n        published: \"8080\"\n        protocol: tcp\n    volumes: [\"data:/data\"]\nvolumes:\n  data:\n    external: false\n")
+
+	// Projects written differently have byte-identical output, in both output formats
+	for _, format := range []string{"json", "target-state"} {
+		t.Run(format, func(t *testing.T) {
+			expected := runCLI(t, "", "--stable", "--output-format", format, "-f", first, "p")
+			if expected.code != 0 {
+				t.Fatalf("expected exit code 0, got %d: %s", expected.code, expected.stderr)
+			}
+			if result := runCLI(t, "", "--stable", "--output-format", format, "-f", second, "p"); result.stdout != expected.stdout {
+				t.Errorf("expected %s, got %s", expected.stdout, result.stdout)
+			}
+			if strings.Contains(expected.stdout, `"privileged"`) || strings.Contains(expected.stdout, `"protocol"`) {
+				t.Errorf("expected fields set to their default to be removed, got %s", expected.stdout)
+			}
+		})
+	}
+
+	runCLI(t, "", "--stable", "--output-format", "yaml", "-f", first, "p").expectError(t, parser.ArgumentError, "--stable is only supported with JSON and target state output of a single project")
+	runCLI(t, "", "--stable", "--images", "-f", first, "p").expectError(t, parser.ArgumentError, "--stable is only supported")
+}
+
+func TestNewHTTPSClient(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://"+r.Host+"/compose.yml", http.StatusFound)
+			return
+		}
+		w.Write([]byte("services: {}\n"))
+	}))
+	// Clients which don't trust the certificate fail their handshakes
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	dir := t.TempDir()
+	caCert := writeFile(t, dir, "ca.pem", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})))
+	notPEM := writeFile(t, dir, "ca.txt", "certificate")
+
+	tests := []struct {
+		name      string
+		caCert    string
+		insecure  bool
+		path      string
+		clientErr string
+		fetchErr  string
+	}{
+		{name: "CA certificate", caCert: caCert, path: "/compose.yml"},
+		{name: "insecure", insecure: true, path: "/compose.yml"},
+		{name: "untrusted", path: "/compose.yml", fetchErr: "certificate"},
+		{name: "redirect to HTTP", caCert: caCert, path: "/redirect", fetchErr: "refusing redirect to non-HTTPS URL"},
+		{name: "missing CA certificate", caCert: filepath.Join(dir, "missing.pem"), clientErr: "Failed to read CA certificate"},
+		{name: "invalid CA certificate", caCert: notPEM, clientErr: "No PEM certificates found in " + notPEM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := newHTTPSClient(time.Minute, tt.caCert, tt.insecure)
+			if tt.clientErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.clientErr) {
+					t.Errorf("expected an error containing %q, got %v", tt.clientErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			response, err := client.Get(server.URL + tt.path)
+			if tt.fetchErr == "" && err == nil {
+				response.Body.Close()
+				if response.StatusCode != http.StatusOK {
+					t.Errorf("expected status 200, got %s", response.Status)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.fetchErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.fetchErr, err)
+			}
+		})
+	}
+
+	if _, err := newHTTPSClient(0, "", false); err == nil {
+		t.Error("expected an error for a timeout of 0")
+	}
+}
+
+func TestHTTPSComposeFile(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n")
+	server := httptest.NewTLSServer(http.FileServer(http.Dir(filepath.Dir(composeFile))))
+	defer server.Close()
+
+	output := runCLI(t, "", "--https-insecure", "-f", server.URL+"/compose.yml", "p").output(t)
+	if image := lookup(output, "services.web.image"); image != "nginx" {
+		t.Errorf("expected the fetched project, got %v", output)
+	}
+	runCLI(t, "", "--https-timeout", "0s", "-f", server.URL+"/compose.yml", "p").expectError(t, parser.ArgumentError, "HTTPS timeout must be positive")
+}
+
+func TestTarArchive(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	content := "services:\n  web:\n    image: nginx\n"
+	tw.WriteHeader(&tar.Header{Name: "docker-compose.yml", Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write([]byte(content))
+	tw.Close()
+	archivePath := writeFile(t, t.TempDir(), "project.tar", archive.String())
+
+	for name, result := range map[string]cliResult{
+		"file":  runCLI(t, "", "--tar", archivePath, "p"),
+		"stdin": runCLI(t, archive.String(), "--tar", "-", "p"),
+	} {
+		if image := lookup(result.output(t), "services.web.image"); image != "nginx" {
+			t.Errorf("expected the project of the archive from a %s, got %s", name, result.stdout)
+		}
+	}
+
+	runCLI(t, "", "--tar", "-", "-f", "-", "p").expectError(t, parser.ArgumentError, "Stdin can't be used for both --tar and -f")
+	runCLI(t, "", "--tar", archivePath, "--project-directory", ".", "p").expectError(t, parser.ArgumentError, "--project-directory can't be used with --tar")
+	runCLI(t, "", "--tar", archivePath+".missing", "p").expectError(t, parser.IOError, "Failed to open project archive")
+}
+
+func TestGitReference(t *testing.T) {
+	runCLI(t, "", "--git", "https://github.com/balena-io/app.git", "p").expectError(t, parser.ArgumentError, "invalid git reference")
+	runCLI(t, "", "--git", "app#main", "-f", "-", "p").expectError(t, parser.ArgumentError, "Stdin can't be used as a compose file with --git")
+	runCLI(t, "", "--git", "app#main", "--git-timeout", "0s", "p").expectError(t, parser.ArgumentError, "Git timeout must be positive")
+	runCLI(t, "", "--git", "app#main", "--tar", "project.tar", "p").expectError(t, parser.ArgumentError, "Only one of --tar, --git and --oci can be specified")
+}
+
+func TestOCIReference(t *testing.T) {
+	runCLI(t, "", "--oci", "registry.example.com/app:v1", "-f", "compose.yml", "p").expectError(t, parser.ArgumentError, "-f can't be used with --oci")
+	runCLI(t, "", "--oci", "registry.example.com/app:v1", "--project-directory", ".", "p").expectError(t, parser.ArgumentError, "--project-directory can't be used with --tar, --git or --oci")
+	runCLI(t, "", "--oci", "127.0.0.1:1/app:v1", "p").expectError(t, parser.IOError, "Failed to pull compose artifact 127.0.0.1:1/app:v1")
+}
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	valid := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n")
+	tests := []struct {
+		name    string
+		content string
+		args    []string
+		errName string
+		code    int
+	}{
+		{name: "argument", args: []string{"--unknown"}, errName: parser.ArgumentError, code: 2},
+		{name: "parse", content: "services: [\n", errName: parser.ParseError, code: 3},
+		{name: "timeout", args: []string{"--timeout", "1ns", "-f", valid, "p"}, errName: parser.TimeoutError, code: 4},
+		{name: "validation", content: "services:\n  web:\n    image: nginx\n    ports: true\n", errName: parser.ValidationError, code: 5},
+		{name: "io", args: []string{"-f", dir + "/missing.yml", "p"}, errName: parser.IOError, code: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.content != "" {
+				args = []string{"-f", writeFile(t, t.TempDir(), "compose.yml", tt.content), "p"}
+			}
+			result := runCLI(t, "", args...)
+			if response := result.errorResponse(t); response.Name != tt.errName || result.code != tt.code {
+				t.Errorf("expected a %s with exit code %d, got %s with %d", tt.errName, tt.code, response.Name, result.code)
+			}
+		})
+	}
+
+	help := runCLI(t, "", "--help")
+	if help.code != 0 {
+		t.Errorf("expected --help to succeed, got %d", help.code)
+	}
+	for _, documented := range []string{"2  ArgumentError", "3  ParseError", "4  TimeoutError", "5  ValidationError", "6  IOError"} {
+		if !strings.Contains(help.stdout, documented) {
+			t.Errorf("expected --help to document the exit code %q", documented)
+		}
+	}
+}
+
+func TestAllErrors(t *testing.T) {
+	dir := t.TempDir()
+	first := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    ports: true\n")
+	second := writeFile(t, dir, "compose.override.yml", "services: [\n")
+
+	if response := runCLI(t, "", "-f", first, "-f", second, "p").errorResponse(t); response.Errors != nil {
+		t.Errorf("expected only the first error without --all-errors, got %+v", response.Errors)
+	}
+	response := runCLI(t, "", "--all-errors", "-f", first, "-f", second, "p").expectError(t, parser.ValidationError, "")
+	if len(response.Errors) != 2 || response.Errors[0].Location == nil || response.Errors[0].Location.File != first || response.Errors[1].Name != parser.ParseError {
+		t.Errorf("expected the errors of both files, got %+v", response.Errors)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "version: \"3.8\"\nservices:\n  web:\n    image: nginx\n")
+	output := runCLI(t, "", "--warnings", "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.web.image") != "nginx" || lookup(output, "warnings.0.code") != parser.ObsoleteVersionWarning {
+		t.Errorf("expected the project alongside its warnings, got %v", output)
+	}
+	if output := runCLI(t, "", "-f", composeFile, "p").output(t); lookup(output, "services.web.image") != "nginx" {
+		t.Errorf("expected the project alone without --warnings, got %v", output)
+	}
+}
+
+func TestLegacyFiles(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "docker-compose.yml", "version: \"2.1\"\nservices:\n  web:\n    image: nginx\n    net: host\n    log_driver: syslog\n")
+	output := runCLI(t, "", "--warnings", "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.web.network_mode") != "host" || lookup(output, "project.services.web.logging.driver") != "syslog" {
+		t.Errorf("expected the legacy fields to be converted, got %v", lookup(output, "project.services.web"))
+	}
+	codes := map[any]int{}
+	for _, warning := range output["warnings"].([]any) {
+		codes[lookup(warning, "code")]++
+		if file := lookup(warning, "location.file"); file != composeFile {
+			t.Errorf("expected the warning to be located in the compose file, got %v", file)
+		}
+	}
+	if codes[parser.LegacyFieldCode] != 2 || codes[parser.ObsoleteVersionWarning] != 1 {
+		t.Errorf("expected a warning for each converted field and the version, got %v", output["warnings"])
+	}
+	// Logs name the compose file, not its conversion
+	result := runCLI(t, "", "-f", composeFile, "p")
+	if !strings.Contains(result.stderr, composeFile+": the attribute `version` is obsolete") || strings.Contains(result.stderr, "balena-compose-parser-legacy-") {
+		t.Errorf("expected the obsolete version to be logged for the compose file, got %s", result.stderr)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:${UNSET}\n")
+	tests := []struct {
+		name   string
+		args   []string
+		logged bool
+	}{
+		{name: "default", logged: true},
+		{name: "warn level", args: []string{"--log-level", "warn"}, logged: true},
+		{name: "error level", args: []string{"--log-level", "error"}},
+		{name: "quiet", args: []string{"--quiet"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := runCLI(t, "", append(tt.args, "-f", composeFile, "p")...)
+			if result.code != 0 {
+				t.Fatalf("expected the parse to succeed, got %d: %s", result.code, result.stderr)
+			}
+			var log map[string]any
+			logged := json.Unmarshal([]byte(result.stderr), &log) == nil && log["level"] == "warning" && strings.Contains(log["message"].(string), "UNSET")
+			if logged != tt.logged || !logged && result.stderr != "" {
+				t.Errorf("expected the unset variable to be logged %t, got %q", tt.logged, result.stderr)
+			}
+		})
+	}
+
+	// With --quiet, stderr only holds the error response
+	invalid := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:${UNSET}\n    ports: true\n")
+	if result := runCLI(t, "", "--quiet", "-f", invalid, "p"); strings.Count(result.stderr, "\n") != 1 {
+		t.Errorf("expected only the error response on stderr, got %q", result.stderr)
+	}
+	runCLI(t, "", "--log-level", "verbose", "-f", composeFile, "p").expectError(t, parser.ArgumentError, `Invalid log level "verbose"`)
+}
+
+func TestProgressFD(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n")
+	result := runCLI(t, "", "--quiet", "--progress-fd", "2", "-f", composeFile, "p")
+	var phases []string
+	for _, line := range strings.Split(strings.TrimSpace(result.stderr), "\n") {
+		var event parser.ProgressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil || event.ProjectName != "p" {
+			t.Fatalf("expected NDJSON progress events of p, got %q", result.stderr)
+		}
+		phases = append(phases, event.Phase)
+	}
+	if len(phases) < 2 || phases[0] != parser.LoadingPhase || phases[len(phases)-1] != parser.DonePhase {
+		t.Errorf("expected the events from loading to done, got %v", phases)
+	}
+
+	runCLI(t, "", "--progress-fd", "1", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Progress file descriptor must be 2 or greater, got 1")
+	runCLI(t, "", "--progress-fd", "42", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Invalid progress file descriptor 42")
+}
+
+func TestEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:${TAG:-latest}\n    command: [\"${DEVICE:-none}\"]\n")
+	dotEnv := writeFile(t, dir, ".env", "TAG=dotenv\n")
+	deviceEnv := writeFile(t, dir, "device.env", "TAG=device\nDEVICE=device\n")
+
+	if image := lookup(runCLI(t, "", "-f", composeFile, "p").output(t), "services.web.image"); image != "nginx:latest" {
+		t.Errorf("expected the .env file not to be read by default, got %v", image)
+	}
+	output := runCLI(t, "", "--env-file", dotEnv, "--env-file", deviceEnv, "-f", composeFile, "p").output(t)
+	if lookup(output, "services.web.image") != "nginx:device" || lookup(output, "services.web.command.0") != "device" {
+		t.Errorf("expected later env files to override earlier ones, got %v", lookup(output, "services.web"))
+	}
+	runCLI(t, "", "--env-file", filepath.Join(dir, "missing.env"), "-f", composeFile, "p").expectError(t, parser.IOError, "Failed to read env file")
+}
+
+func TestNoOSEnv(t *testing.T) {
+	t.Setenv("BALENA_TEST_TAG", "os")
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:${BALENA_TEST_TAG:-latest}\n")
+	if image := lookup(runCLI(t, "", "-f", composeFile, "p").output(t), "services.web.image"); image != "nginx:os" {
+		t.Errorf("expected the process environment to be interpolated, got %v", image)
+	}
+	if image := lookup(runCLI(t, "", "--no-os-env", "-f", composeFile, "p").output(t), "services.web.image"); image != "nginx:latest" {
+		t.Errorf("expected the process environment not to be interpolated with --no-os-env, got %v", image)
+	}
+}
+
+func TestEnvAllowDeny(t *testing.T) {
+	t.Setenv("BALENA_TEST_TAG", "os")
+	t.Setenv("AWS_TEST_SECRET", "secret")
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:${BALENA_TEST_TAG:-latest}\n    command: [\"${AWS_TEST_SECRET:-none}\"]\n")
+	tests := []struct {
+		args    []string
+		image   string
+		command string
+	}{
+		{args: []string{"--env-allow", "BALENA_*"}, image: "nginx:os", command: "none"},
+		{args: []string{"--env-allow", "BALENA_*", "--env-allow", "AWS_*"}, image: "nginx:os", command: "secret"},
+		{args: []string{"--env-deny", "AWS_*"}, image: "nginx:os", command: "none"},
+		{args: []string{"--env-allow", "*_TEST_*", "--env-deny", "balena_*"}, image: "nginx:latest", command: "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			output := runCLI(t, "", append(tt.args, "-f", composeFile, "p")...).output(t)
+			if image, command := lookup(output, "services.web.image"), lookup(output, "services.web.command.0"); image != tt.image || command != tt.command {
+				t.Errorf("expected %s running %s, got %v running %v", tt.image, tt.command, image, command)
+			}
+		})
+	}
+
+	runCLI(t, "", "--no-os-env", "--env-deny", "AWS_*", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--env-allow and --env-deny can't be used with --no-os-env")
+}
+
+func TestNoDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:${TAG:-latest}\n")
+	writeFile(t, dir, ".env", "TAG=dotenv\n")
+	if image := lookup(runCLI(t, "", "--no-dotenv", "-f", composeFile, "p").output(t), "services.web.image"); image != "nginx:latest" {
+		t.Errorf("expected the .env file not to be read with --no-dotenv, got %v", image)
+	}
+}
+
+func TestEnvFlag(t *testing.T) {
+	var env envFlag
+	for _, variable := range []string{"UUID=a1b2", "FLEET=fleet", "UUID=c3d4", "URL=https://example.com/?a=b", "EMPTY="} {
+		if err := env.Set(variable); err != nil {
+			t.Errorf("expected %q to be set, got %v", variable, err)
+		}
+	}
+	if s := env.String(); s != "EMPTY=,FLEET=fleet,URL=https://example.com/?a=b,UUID=c3d4" {
+		t.Errorf("expected later values to override earlier ones, got %q", s)
+	}
+	for _, variable := range []string{"UUID", "=value"} {
+		if err := env.Set(variable); err == nil {
+			t.Errorf("expected %q to be invalid", variable)
+		}
+	}
+
+	t.Setenv("UUID", "os")
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    command: [\"${UUID}\", \"${FLEET}\"]\n")
+	envFile := writeFile(t, dir, "build.env", "FLEET=file\n")
+	output := runCLI(t, "", "--env-file", envFile, "--env", "UUID=a1b2", "--env", "FLEET=fleet", "-f", composeFile, "p").output(t)
+	if lookup(output, "services.web.command.0") != "a1b2" || lookup(output, "services.web.command.1") != "fleet" {
+		t.Errorf("expected --env to override the environment and env files, got %v", lookup(output, "services.web.command"))
+	}
+	runCLI(t, "", "--env", "UUID", "-f", composeFile, "p").expectError(t, parser.ArgumentError, `expected KEY=VAL, got "UUID"`)
+}
+
+func TestReadEnvJSON(t *testing.T) {
+	dir := t.TempDir()
+	environment, err := readEnvJSON(writeFile(t, dir, "env.json", `{"UUID": "a1b2", "PORT": 8080, "RATIO": 1.5, "DEBUG": true, "EMPTY": ""}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"UUID": "a1b2", "PORT": "8080", "RATIO": "1.5", "DEBUG": "true", "EMPTY": ""}; !maps.Equal(environment, expected) {
+		t.Errorf("expected %v, got %v", expected, environment)
+	}
+
+	tests := []struct {
+		content string
+		name    string
+		message string
+	}{
+		{content: `["UUID"]`, name: parser.ArgumentError, message: "Invalid env JSON"},
+		{content: `{"UUID": `, name: parser.ArgumentError, message: "Invalid env JSON"},
+		{content: `{"UUID": null}`, name: parser.ArgumentError, message: `value of "UUID" must be a string, number or boolean`},
+		{content: `{"TAGS": ["a"]}`, name: parser.ArgumentError, message: `value of "TAGS" must be a string, number or boolean`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			_, err := readEnvJSON(writeFile(t, t.TempDir(), "env.json", tt.content))
+			var parserErr *parser.Error
+			if !errors.As(err, &parserErr) || parserErr.Name != tt.name || !strings.Contains(parserErr.Message, tt.message) {
+				t.Errorf("expected %s: %s, got %v", tt.name, tt.message, err)
+			}
+		})
+	}
+	_, err = readEnvJSON(filepath.Join(dir, "missing.json"))
+	var parserErr *parser.Error
+	if !errors.As(err, &parserErr) || parserErr.Name != parser.IOError {
+		t.Errorf("expected an IOError for a missing file, got %v", err)
+	}
+}
+
+func TestEnvJSON(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:${TAG}\n    command: [\"${UUID}\", \"${PORT}\"]\n")
+	envJSON := writeFile(t, dir, "env.json", `{"TAG": "1.25", "UUID": "a1b2", "PORT": 80}`)
+	// From a file, stdin and a file descriptor, overridden by --env
+	for _, args := range [][]string{{"--env-json", envJSON}, {"--env-json", "-"}, {"--env-json", "0"}} {
+		t.Run(args[1], func(t *testing.T) {
+			output := runCLI(t, `{"TAG": "1.25", "UUID": "a1b2", "PORT": 80}`, append(args, "--env", "UUID=c3d4", "-f", composeFile, "p")...).output(t)
+			if lookup(output, "services.web.image") != "nginx:1.25" || lookup(output, "services.web.command.0") != "c3d4" || lookup(output, "services.web.command.1") != "80" {
+				t.Errorf("expected the variables of the env JSON, overridden by --env, got %v", lookup(output, "services.web"))
+			}
+		})
+	}
+
+	runCLI(t, "", "--env-json", "-", "-f", "-", "p").expectError(t, parser.ArgumentError, "Stdin can't be used for both --env-json and the project")
+	runCLI(t, "", "--env-json", "42", "-f", composeFile, "p").expectError(t, parser.IOError, "Invalid env JSON file descriptor 42")
+	runCLI(t, "[]", "--env-json", "-", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Invalid env JSON")
+}
+
+func TestBalenaVars(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:${TAG}\n    command: [\"${UUID}\", \"${MODE}\"]\n")
+	balenaVars := `{"fleet": [{"name": "TAG", "value": "1.25"}, {"name": "UUID", "value": "fleet"}], "device": {"d": [{"name": "UUID", "value": "a1b2"}, {"name": "MODE", "value": "device"}]}}`
+	varsFile := writeFile(t, dir, "vars.json", balenaVars)
+	for _, args := range [][]string{{"--balena-vars", varsFile}, {"--balena-vars", "-"}} {
+		t.Run(args[1], func(t *testing.T) {
+			output := runCLI(t, balenaVars, append(args, "--env", "MODE=override", "-f", composeFile, "p")...).output(t)
+			if lookup(output, "services.web.image") != "nginx:1.25" || lookup(output, "services.web.command.0") != "a1b2" || lookup(output, "services.web.command.1") != "override" {
+				t.Errorf("expected device variables to override fleet variables, and --env both, got %v", lookup(output, "services.web"))
+			}
+		})
+	}
+	output := runCLI(t, "", "--balena-vars", varsFile, "--env-resolution", "-f", composeFile, "p").output(t)
+	expected := []any{
+		map[string]any{"name": "MODE", "source": "balena-device", "value": "device"},
+		map[string]any{"name": "TAG", "source": "balena-fleet", "value": "1.25"},
+		map[string]any{"name": "UUID", "source": "balena-device", "value": "a1b2"},
+	}
+	if resolution := output["env_resolution"]; !reflect.DeepEqual(resolution, expected) {
+		t.Errorf("expected %v, got %v", expected, resolution)
+	}
+
+	runCLI(t, "", "--balena-vars", "-", "-f", "-", "p").expectError(t, parser.ArgumentError, "Stdin can't be used for both --balena-vars and the project or --env-json")
+	runCLI(t, `{"fleet": 1}`, "--balena-vars", "-", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Invalid balena fleet variables")
+}
+
+func TestListVariables(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:${TAG:-latest}\n    environment:\n      UUID: ${UUID:?required}\n")
+	result := runCLI(t, "", "--list-variables", "--env", "TAG=1.25", "-f", composeFile, "p")
+	var variables []parser.Variable
+	if err := json.Unmarshal([]byte(result.stdout), &variables); err != nil {
+		t.Fatalf("expected the variables, got %s: %v", result.stdout, err)
+	}
+	// Variables are listed even if required ones are unset
+	if len(variables) != 2 || variables[0].Name != "UUID" || !variables[0].Required || variables[0].Resolved || variables[1].Name != "TAG" || !variables[1].Resolved {
+		t.Errorf("expected UUID to be required and TAG to be resolved, got %+v", variables)
+	}
+}
+
+func TestNoInterpolate(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:${TAG:-latest}\n")
+	if image := lookup(runCLI(t, "", "--no-interpolate", "--env", "TAG=1.25", "-f", composeFile, "p").output(t), "services.web.image"); image != "nginx:${TAG:-latest}" {
+		t.Errorf("expected the variable reference to be preserved, got %v", image)
+	}
+}
+
+func TestStrictEnv(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:${TAG}\n")
+	response := runCLI(t, "", "--strict-env", "--no-os-env", "-f", composeFile, "p").expectError(t, parser.ParseError, "variables are not set and have no default value: TAG")
+	if len(response.Errors) != 1 || response.Errors[0].Location == nil || response.Errors[0].Location.Line != 3 {
+		t.Errorf("expected the unset variable to be located, got %+v", response.Errors)
+	}
+	if image := lookup(runCLI(t, "", "--no-os-env", "-f", composeFile, "p").output(t), "services.web.image"); image != "nginx:" {
+		t.Errorf("expected a blank string without --strict-env, got %v", image)
+	}
+}
+
+func TestEnvResolution(t *testing.T) {
+	t.Setenv("BALENA_TEST_TAG", "os")
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:${BALENA_TEST_TAG}\n    command: [\"${UUID}\", \"${PORT:-80}\"]\n")
+	output := runCLI(t, "", "--env-resolution", "--env", "UUID=a1b2", "-f", composeFile, "p").output(t)
+	if image := lookup(output, "project.services.web.image"); image != "nginx:os" {
+		t.Errorf("expected the project alongside the resolution, got %v", image)
+	}
+	expected := []any{
+		map[string]any{"name": "BALENA_TEST_TAG", "source": "os-env", "value": "os"},
+		map[string]any{"name": "PORT", "source": "default", "value": "80"},
+		map[string]any{"name": "UUID", "source": "override", "value": "a1b2"},
+	}
+	if resolution := output["env_resolution"]; !reflect.DeepEqual(resolution, expected) {
+		t.Errorf("expected %v, got %v", expected, resolution)
+	}
+
+	runCLI(t, "", "--env-resolution", "--output-format", "yaml", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--env-resolution")
+}
+
+func TestMaskEnv(t *testing.T) {
+	if patterns := maskPatterns([]string{"MY_*", "default"}); strings.Join(patterns, " ") != "MY_* "+strings.Join(parser.DefaultMaskPatterns, " ") {
+		t.Errorf("expected the default patterns to be expanded, got %v", patterns)
+	}
+
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    command: [\"${GITHUB_TOKEN}\"]\n    environment:\n      GITHUB_TOKEN: ${GITHUB_TOKEN}\n      MY_KEY: my-key-value\n")
+	t.Setenv("GITHUB_TOKEN", "ghp-secret")
+	output := runCLI(t, "", "--mask-env", "default", "--mask-env", "my_*", "-f", composeFile, "p").output(t)
+	if lookup(output, "services.web.environment.GITHUB_TOKEN") != "***" || lookup(output, "services.web.environment.MY_KEY") != "***" || lookup(output, "services.web.command.0") != "***" {
+		t.Errorf("expected the secrets to be masked, got %v", lookup(output, "services.web"))
+	}
+}
+
+func TestProjectDirectory(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose/compose.yml", "services:\n  web:\n    build: ./web\n")
+	output := runCLI(t, "", "--project-directory", dir, "-f", composeFile, "p").output(t)
+	if context := lookup(output, "services.web.build.context"); context != filepath.Join(dir, "web") {
+		t.Errorf("expected the build context relative to the project directory, got %v", context)
+	}
+	runCLI(t, "", "--project-directory", filepath.Join(dir, "missing"), "-f", composeFile, "p").expectError(t, parser.IOError, "Failed to read project directory")
+	runCLI(t, "", "--project-directory", dir, "--tar", "-", "p").expectError(t, parser.ArgumentError, "--project-directory can't be used with --tar, --git or --oci")
+}
+
+func TestProfiles(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n  debug:\n    image: busybox\n    profiles: [debug]\n  test:\n    image: node\n    profiles: [test]\n")
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{args: nil, expected: []string{"web"}},
+		{args: []string{"--profile", "debug"}, expected: []string{"debug", "web"}},
+		{args: []string{"--profile", "debug", "--profile", "test"}, expected: []string{"debug", "test", "web"}},
+		{args: []string{"--env", "COMPOSE_PROFILES=test"}, expected: []string{"test", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			services, _ := lookup(runCLI(t, "", append(tt.args, "-f", composeFile, "p")...).output(t), "services").(map[string]any)
+			if names := slices.Sorted(maps.Keys(services)); !slices.Equal(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestServices(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    depends_on: [db]\n  db:\n    image: postgres\n  worker:\n    image: node\n")
+	services, _ := lookup(runCLI(t, "", "--service", "web", "-f", composeFile, "p").output(t), "services").(map[string]any)
+	if names := slices.Sorted(maps.Keys(services)); !slices.Equal(names, []string{"db", "web"}) {
+		t.Errorf("expected web and its dependencies, got %v", names)
+	}
+	services, _ = lookup(runCLI(t, "", "--service", "web", "--service", "worker", "-f", composeFile, "p").output(t), "services").(map[string]any)
+	if len(services) != 3 {
+		t.Errorf("expected every service, got %v", slices.Sorted(maps.Keys(services)))
+	}
+	runCLI(t, "", "--service", "missing", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "No such service: missing")
+}
+
+func TestNoNormalize(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n")
+	output := runCLI(t, "", "-f", composeFile, "p").output(t)
+	if lookup(output, "networks.default.name") != "p_default" {
+		t.Errorf("expected the default network, got %v", output["networks"])
+	}
+	output = runCLI(t, "", "--no-normalize", "-f", composeFile, "p").output(t)
+	if _, ok := output["networks"]; ok || lookup(output, "services.web.networks") != nil {
+		t.Errorf("expected no default network with --no-normalize, got %v", output)
+	}
+}
+
+func TestSkipConsistency(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    secrets: [token]\n")
+	runCLI(t, "", "-f", composeFile, "p").expectError(t, parser.ValidationError, "undefined secret token")
+	if secret := lookup(runCLI(t, "", "--skip-consistency", "-f", composeFile, "p").output(t), "services.web.secrets.0.source"); secret != "token" {
+		t.Errorf("expected the undefined secret to be kept with --skip-consistency, got %v", secret)
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    build: ./web\n")
+	if context := lookup(runCLI(t, "", "-f", composeFile, "p").output(t), "services.web.build.context"); context != filepath.Join(dir, "web") {
+		t.Errorf("expected the build context to be resolved, got %v", context)
+	}
+	if context := lookup(runCLI(t, "", "--resolve-paths=false", "-f", composeFile, "p").output(t), "services.web.build.context"); context != "./web" {
+		t.Errorf("expected the build context to be preserved with --resolve-paths=false, got %v", context)
+	}
+}
+
+func TestCompatDocker(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    env_file: web.env\nvolumes:\n  unused: {}\n")
+	writeFile(t, dir, "web.env", "FROM_FILE=file\n")
+	output := runCLI(t, "", "--compat-docker", "-f", composeFile, "p").output(t)
+	if lookup(output, "services.web.environment.FROM_FILE") != "file" || lookup(output, "services.web.env_file") != nil || output["volumes"] != nil {
+		t.Errorf("expected the output of docker compose config, got %v", output)
+	}
+	runCLI(t, "", "--compat-docker", "--canonical", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--canonical can't be used with --compat-docker")
+}
+
+func TestHash(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\n  worker:\n    image: node\n")
+	all := runCLI(t, "", "--hash", "*", "-f", composeFile, "p").output(t)
+	if len(all) != 3 {
+		t.Errorf("expected the hash of every service, got %v", all)
+	}
+	hashes := runCLI(t, "", "--hash", "web,db", "-f", composeFile, "p").output(t)
+	if len(hashes) != 2 || hashes["web"] != all["web"] || hashes["db"] != all["db"] {
+		t.Errorf("expected the hashes of web and db, got %v", hashes)
+	}
+	runCLI(t, "", "--hash", "missing", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "No such service: missing")
+	runCLI(t, "", "--hash", "*", "--images", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--hash")
+	// An empty value, e.g. of an unset shell variable, still selects the hash output
+	for _, services := range []string{"", "web,,db"} {
+		runCLI(t, "", "--hash", services, "-f", composeFile, "p").expectError(t, parser.ArgumentError, `expected service names, comma separated, or "*" for all`)
+	}
+}
+
+func TestImages(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx:1.25\n  api:\n    build: .\n")
+	result := runCLI(t, "", "--images", "-f", composeFile, "p")
+	var images []map[string]any
+	if err := json.Unmarshal([]byte(result.stdout), &images); err != nil {
+		t.Fatalf("expected a JSON array of images, got %q: %v", result.stdout, err)
+	}
+	if len(images) != 2 || images[0]["service"] != "api" || images[0]["image"] != "p-api" || images[0]["build"] != true ||
+		images[1]["service"] != "web" || images[1]["reference"] != "docker.io/library/nginx:1.25" || images[1]["build"] != false {
+		t.Errorf("expected the image of each service, got %v", images)
+	}
+	if images[1]["registry"] != "docker.io" || images[1]["repository"] != "library/nginx" || images[1]["tag"] != "1.25" {
+		t.Errorf("expected the components of the reference, got %v", images[1])
+	}
+	runCLI(t, "", "--images", "--output-format", "yaml", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--images")
+}
+
+func TestHostAccess(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    network_mode: host\n    labels:\n      io.balena.features.kernel-modules: \"1\"\n  db:\n    image: postgres\n")
+	result := runCLI(t, "", "--host-access", "-f", composeFile, "p")
+	var accesses []map[string]any
+	if err := json.Unmarshal([]byte(result.stdout), &accesses); err != nil {
+		t.Fatalf("expected a JSON array of host access, got %q: %v", result.stdout, err)
+	}
+	if len(accesses) != 2 || accesses[0]["service"] != "db" || accesses[0]["hostNetwork"] != false ||
+		accesses[1]["hostNetwork"] != true || lookup(accesses[1], "bindMounts.0") != "/lib/modules" || lookup(accesses[1], "features.0") != "io.balena.features.kernel-modules" {
+		t.Errorf("expected the host access of each service, got %v", accesses)
+	}
+	runCLI(t, "", "--host-access", "--images", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--host-access")
+}
+
+func TestResources(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\nvolumes:\n  data:\n    external: true\n")
+	output := runCLI(t, "", "--resources", "-f", composeFile, "p").output(t)
+	if lookup(output, "volumes.0.name") != "data" || lookup(output, "volumes.0.external") != true || lookup(output, "networks.0.resourceName") != "p_default" {
+		t.Errorf("expected the resources of the project, got %v", output)
+	}
+	if secrets, ok := output["secrets"].([]any); !ok || len(secrets) != 0 {
+		t.Errorf("expected no secrets, got %v", output["secrets"])
+	}
+	runCLI(t, "", "--resources", "--images", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--images")
+}
+
+func TestJSONComposeFile(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.json", `{"services": {"web": {"image": "nginx"}}}`)
+	if image := lookup(runCLI(t, "", "-f", composeFile, "p").output(t), "services.web.image"); image != "nginx" {
+		t.Errorf("expected the JSON compose file to be parsed, got %v", image)
+	}
+	if image := lookup(runCLI(t, `{"services": {"web": {"image": "nginx"}}}`, "-f", "-", "p").output(t), "services.web.image"); image != "nginx" {
+		t.Errorf("expected the JSON compose file from stdin to be parsed, got %v", image)
+	}
+	invalid := writeFile(t, t.TempDir(), "compose.json", `{"services": {"web": {"image": "nginx",}}}`)
+	response := runCLI(t, "", "-f", invalid, "p").expectError(t, parser.ParseError, "invalid JSON")
+	if response.Location == nil || response.Location.Line != 1 || response.Location.Column != 40 {
+		t.Errorf("expected the error to be located, got %+v", response.Location)
+	}
+}
+
+func TestFromParsed(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    build: .\n")
+	stored := writeFile(t, dir, "project.json", runCLI(t, "", "-f", composeFile, "p").stdout)
+	output := runCLI(t, "", "--from-parsed", "-f", stored).output(t)
+	if output["name"] != "p" || lookup(output, "services.web.build.context") != dir {
+		t.Errorf("expected the stored project, got %v", output)
+	}
+	if output := runCLI(t, "", "--from-parsed", "-f", stored, "renamed").output(t); output["name"] != "renamed" {
+		t.Errorf("expected the project to be renamed, got %v", output["name"])
+	}
+	runCLI(t, "", "--from-parsed", "--tar", "-", "-f", stored).expectError(t, parser.ArgumentError, "--from-parsed only supports compose files specified with -f")
+}
+
+func TestBalenaNormalize(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    volumes: [\"data:/data\"]\nvolumes:\n  data: {}\n")
+	for _, args := range [][]string{{"--balena-normalize", "-f", composeFile}, {"--balena-normalize", "-f", composeFile, "p"}} {
+		t.Run(strings.Join(args[2:], " "), func(t *testing.T) {
+			output := runCLI(t, "", args...).output(t)
+			if _, ok := output["name"]; ok || lookup(output, "volumes.data.name") != nil || lookup(output, "networks.default.name") != nil {
+				t.Errorf("expected no project name or names derived from it, got %v", output)
+			}
+		})
+	}
+	runCLI(t, "", "-f", composeFile).expectError(t, parser.ArgumentError, "Project name is required")
+}
+
+func TestBalenaDefaults(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\n    restart: \"no\"\n")
+	output := runCLI(t, "", "--balena-defaults", "-f", composeFile, "p").output(t)
+	if lookup(output, "services.web.restart") != "always" || lookup(output, "services.db.restart") != "no" {
+		t.Errorf("expected restart: always unless set, got %v", output["services"])
+	}
+	labels, _ := lookup(output, "services.web.labels").(map[string]any)
+	if labels["io.balena.supervised"] != "true" || labels["io.balena.service-name"] != "web" {
+		t.Errorf("expected the bookkeeping labels, got %v", labels)
+	}
+	if _, ok := lookup(output, "services.web.networks").(map[string]any)["default"]; !ok {
+		t.Errorf("expected the default network, got %v", lookup(output, "services.web.networks"))
+	}
+}
+
+func TestTarget(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    deploy:\n      replicas: 2\n")
+	response := runCLI(t, "", "--target", "balena", "-f", composeFile, "p").expectError(t, parser.ValidationError, "services.web.deploy.replicas is not supported")
+	if response.Code != parser.UnsupportedFieldCode || len(response.Errors) != 1 || response.Location == nil || response.Location.Path != "services.web.deploy.replicas" {
+		t.Errorf("expected an unsupported-field error, got %+v", response)
+	}
+	if result := runCLI(t, "", "-f", composeFile, "p"); result.code != 0 {
+		t.Errorf("expected the project to parse without a target, got %d: %s", result.code, result.stderr)
+	}
+	runCLI(t, "", "--target", "swarm", "-f", composeFile, "p").expectError(t, parser.ArgumentError, `Unsupported target "swarm"`)
+}
+
+func TestTargetStateOutput(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    ports: [\"8080:80\"]\n")
+	output := runCLI(t, "", "--output-format", "target-state", "-f", composeFile).output(t)
+	if lookup(output, "services.web.image") != "nginx" || lookup(output, "services.web.composition.ports.0") != "8080:80" || lookup(output, "networks.default") == nil {
+		t.Errorf("expected the target state of the project, got %v", output)
+	}
+	// Balena validation is implied
+	unsupported := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    scale: 2\n")
+	runCLI(t, "", "--output-format", "target-state", "-f", unsupported).expectError(t, parser.ValidationError, "services.web.scale is not supported")
+}
+
+func TestCompose21Output(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    cpus: 0.5\n    ports: [\"8080:80\"]\n")
+	output := runCLI(t, "", "--output-format", "compose-2.1", "-f", composeFile).output(t)
+	if output["version"] != "2.1" || lookup(output, "services.web.cpu_quota") != float64(50000) || lookup(output, "services.web.ports.0") != "8080:80" {
+		t.Errorf("expected the 2.1 composition of the project, got %v", output)
+	}
+	unsupported := writeFile(t, dir, "unsupported.yml", "services:\n  web:\n    image: nginx\n    uts: host\n")
+	response := runCLI(t, "", "--output-format", "compose-2.1", "-f", unsupported).expectError(t, parser.ValidationError, "services.web.uts has no equivalent in the 2.1 format")
+	if response.Location == nil || response.Location.Line != 4 {
+		t.Errorf("expected the error to be located in the compose file, got %+v", response.Location)
+	}
+}
+
+func TestOutputSchema(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    ports: [\"8080:80\"]\n  db:\n    image: postgres\n")
+	tests := []struct {
+		schema   string
+		image    string
+		ports    string
+		expected any
+	}{
+		{schema: "v3", image: "services.web.image", ports: "services.web.composition.ports.0"},
+		{schema: "v2", image: "services.web.image", ports: "services.web.ports.0"},
+		{schema: "v1", image: "services.1.image", ports: "services.1.ports.0", expected: "web"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			output := runCLI(t, "", "--output-format", "target-state", "--output-schema", tt.schema, "-f", composeFile).output(t)
+			if lookup(output, tt.image) != "nginx" || lookup(output, tt.ports) != "8080:80" {
+				t.Errorf("expected the %s shape, got %v", tt.schema, output)
+			}
+			if tt.expected != nil && lookup(output, "services.1.serviceName") != tt.expected {
+				t.Errorf("expected services in name order with their name, got %v", output["services"])
+			}
+		})
+	}
+	runCLI(t, "", "--output-format", "target-state", "--output-schema", "v4", "-f", composeFile).expectError(t, parser.ArgumentError, `Unsupported output schema "v4", expected one of: v1, v2, v3`)
+	runCLI(t, "", "--output-schema", "v2", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--output-schema is only supported with --output-format target-state")
+}
+
+func TestExpandFeatures(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    labels:\n      io.balena.features.kernel-modules: \"1\"\n  db:\n    image: postgres\n")
+	output := runCLI(t, "", "--expand-features", "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.web.image") != "nginx" || lookup(output, "features.web.0.label") != "io.balena.features.kernel-modules" || lookup(output, "features.web.0.mounts.0") != "/lib/modules:/lib/modules" {
+		t.Errorf("expected the features alongside the project, got %v", output)
+	}
+	if lookup(output, "features.db") != nil {
+		t.Errorf("expected services without features to be omitted, got %v", output["features"])
+	}
+	runCLI(t, "", "--expand-features", "--output-format", "yaml", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--expand-features")
+}
+
+func TestContract(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n")
+	contract := writeFile(t, dir, "balena.yml", "type: sw.application\nslug: balenalabs/browser\nrequires:\n  - type: arch.sw\n    slug: aarch64\n")
+	output := runCLI(t, "", "--contract", contract, "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.web.image") != "nginx" || lookup(output, "contract.slug") != "balenalabs/browser" || lookup(output, "contract.requires.0.slug") != "aarch64" {
+		t.Errorf("expected the contract alongside the project, got %v", output)
+	}
+	invalid := writeFile(t, dir, "invalid.yml", "type: sw.unknown\n")
+	response := runCLI(t, "", "--contract", invalid, "-f", composeFile, "p").expectError(t, parser.ValidationError, "Invalid contract")
+	if response.Code != parser.InvalidContractCode {
+		t.Errorf("expected the %s code, got %s", parser.InvalidContractCode, response.Code)
+	}
+}
+
+func TestDeviceType(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    platform: linux/amd64\n")
+	response := runCLI(t, "", "--device-type", "raspberrypi4-64", "-f", composeFile, "p").expectError(t, parser.ValidationError, "isn't for aarch64 devices")
+	if response.Code != parser.PlatformMismatchCode || response.Location == nil || response.Location.Path != "services.web.platform" {
+		t.Errorf("expected a located platform-mismatch error, got %+v", response)
+	}
+	if result := runCLI(t, "", "--arch", "amd64", "-f", composeFile, "p"); result.code != 0 {
+		t.Errorf("expected the project to be compatible with amd64, got %d: %s", result.code, result.stderr)
+	}
+	runCLI(t, "", "--device-type", "intel-nuc", "--arch", "aarch64", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Device type intel-nuc is amd64, not aarch64")
+}
+
+func TestBuilds(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    build:\n      context: ./web\n      args:\n        VERSION: \"1\"\n  db:\n    image: postgres\n")
+	writeFile(t, dir, "web/Dockerfile", "FROM nginx\n")
+	output := runCLI(t, "", "--builds", "-f", composeFile, "p").output(t)
+	if lookup(output, "builds.web.context") != "web" || lookup(output, "builds.web.dockerfile") != "Dockerfile" || lookup(output, "builds.web.args.VERSION") != "1" {
+		t.Errorf("expected the build of web as the builder takes it, got %v", output["builds"])
+	}
+	if lookup(output, "builds.db") != nil || lookup(output, "project.services.web.build.context") != filepath.Join(dir, "web") {
+		t.Errorf("expected only services with a build section to be described, got %v", output["builds"])
+	}
+}
+
+func TestDockerfileTemplate(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    build: .\n")
+	writeFile(t, dir, "Dockerfile.template", "FROM balenalib/%%BALENA_MACHINE_NAME%%-node\n")
+	output := runCLI(t, "", "--builds", "--device-type", "intel-nuc", "-f", composeFile, "p").output(t)
+	if lookup(output, "builds.web.dockerfile") != "Dockerfile.template" || lookup(output, "builds.web.template.variables.0") != "BALENA_MACHINE_NAME" {
+		t.Errorf("expected the template and its variables, got %v", output["builds"])
+	}
+	if rendered := lookup(output, "builds.web.template.rendered"); rendered != "FROM balenalib/intel-nuc-node\n" {
+		t.Errorf("expected the template rendered for intel-nuc, got %v", rendered)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    privileged: true\n    network_mode: host\n")
+	response := runCLI(t, "", "--policy", "strict", "-f", composeFile, "p").expectError(t, parser.ValidationError, "Project violates the strict policy")
+	if len(response.Errors) != 2 || response.Code != parser.PolicyViolationCode || response.Location == nil || response.Location.Path != "services.web.privileged" {
+		t.Errorf("expected a located error for each rejected field, got %+v", response)
+	}
+	output := runCLI(t, "", "--policy", "fleet-default", "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.web.privileged") != true || lookup(output, "policy.web.0.decision") != "warn" || lookup(output, "policy.web.1.decision") != "allow" {
+		t.Errorf("expected the decision on each field alongside the project, got %v", output["policy"])
+	}
+	runCLI(t, "", "--policy", "lax", "-f", composeFile, "p").expectError(t, parser.ArgumentError, `Unsupported policy "lax"`)
+}
+
+func TestPrivateLabels(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n")
+	output := runCLI(t, "", "--private-label", "io.balena.private.app-id=42", "--private-label", "io.balena.private.release=abc", "-f", composeFile, "p").output(t)
+	labels, _ := lookup(output, "services.web.labels").(map[string]any)
+	if labels["io.balena.private.app-id"] != "42" || labels["io.balena.private.release"] != "abc" {
+		t.Errorf("expected the private labels to be added, got %v", labels)
+	}
+	reserved := writeFile(t, dir, "reserved.yml", "services:\n  web:\n    image: nginx\n    labels:\n      io.balena.private.app-id: \"1\"\n")
+	response := runCLI(t, "", "-f", reserved, "p").expectError(t, parser.ValidationError, "Project uses reserved labels")
+	if response.Code != parser.PrivateLabelCode || response.Location == nil || response.Location.Line != 5 {
+		t.Errorf("expected a located private-label error, got %+v", response)
+	}
+	// Injected labels don't excuse those of the compose files
+	runCLI(t, "", "--private-label", "io.balena.private.app-id=42", "-f", reserved, "p").expectError(t, parser.ValidationError, "Project uses reserved labels")
+	runCLI(t, "", "--private-label", "app-id=42", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Private label app-id must be in the")
+}
+
+func TestLimits(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\nvolumes:\n  data: {}\n")
+	response := runCLI(t, "", "--max-services", "1", "-f", composeFile, "p").expectError(t, parser.ValidationError, "the project has 2 services, more than the limit of 1")
+	if response.Code != parser.TooManyServicesCode || response.Location == nil || response.Location.Path != "services.web" || response.Location.Line != 2 {
+		t.Errorf("expected a too-many-services error located at web, got %+v", response)
+	}
+	if result := runCLI(t, "", "--max-services", "2", "--max-volumes", "1", "-f", composeFile, "p"); result.code != 0 {
+		t.Errorf("expected the project to be within its limits, got %d: %s", result.code, result.stderr)
+	}
+	runCLI(t, "", "--max-volumes", "-1", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Service and volume limits can't be negative")
+}
+
+func TestSupervisorVersion(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    depends_on:\n      db:\n        condition: service_healthy\n  db:\n    image: postgres\n    healthcheck:\n      test: [CMD, pg_isready]\n")
+	response := runCLI(t, "", "--target", "balena", "--supervisor-version", "v14.0.0", "-f", composeFile, "p").expectError(t, parser.ValidationError, "condition service_healthy requires supervisor v16.4.0 or later, targeting v14.0.0")
+	if response.Code != parser.IncompatibleSupervisorCode || response.Location == nil || response.Location.Path != "services.web.depends_on.db" {
+		t.Errorf("expected a located incompatible-supervisor error, got %+v", response)
+	}
+	if result := runCLI(t, "", "--target", "balena", "--supervisor-version", "v16.4.0", "-f", composeFile, "p"); result.code != 0 {
+		t.Errorf("expected the condition to be supported, got %d: %s", result.code, result.stderr)
+	}
+	runCLI(t, "", "--supervisor-version", "v16.4.0", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "The supervisor version can only be set with the balena target")
+}
+
+func TestDefaults(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\n    restart: always\n")
+	defaults := writeFile(t, dir, "defaults.yml", "restart: unless-stopped\nlabels:\n  com.example.team: platform\n")
+	output := runCLI(t, "", "--defaults", defaults, "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.web.restart") != "unless-stopped" || lookup(output, "project.services.db.restart") != "always" {
+		t.Errorf("expected the defaults beneath the compose file, got %v", lookup(output, "project.services"))
+	}
+	if lookup(output, "defaults.web.0.field") != "restart" || lookup(output, "defaults.db.0.field") != "labels.com.example.team" || lookup(output, "defaults.db.1") != nil {
+		t.Errorf("expected the fields set from the defaults alongside the project, got %v", output["defaults"])
+	}
+	invalid := writeFile(t, dir, "invalid.yml", "restart: sometimes\n")
+	response := runCLI(t, "", "--defaults", invalid, "-f", composeFile, "p").expectError(t, parser.ValidationError, `restart must be no, always, unless-stopped or on-failure, got "sometimes"`)
+	if response.Code != parser.InvalidDefaultsCode || response.Location == nil || response.Location.File != invalid {
+		t.Errorf("expected the error to be located in the defaults, got %+v", response)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    cap_add: [SYS_RAWIO]\n")
+	response := runCLI(t, "", "--target", "balena", "--os-version", "2.80.0", "-f", composeFile, "p").expectError(t, parser.ValidationError, "SYS_RAWIO requires privileged on balenaOS before v2.88.0, targeting 2.80.0")
+	if response.Code != parser.PrivilegedCapabilityCode || response.Location == nil || response.Location.Path != "services.web.cap_add.0" {
+		t.Errorf("expected a located privileged-capability error, got %+v", response)
+	}
+	output := runCLI(t, "", "--target", "balena", "--warnings", "-f", composeFile, "p").output(t)
+	if lookup(output, "warnings.0.code") != parser.PrivilegedCapabilityCode {
+		t.Errorf("expected a warning without a balenaOS version, got %v", output["warnings"])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "version: \"2.1\"\nservices:\n  web:\n    image: nginx\n")
+	output := runCLI(t, "", "--validate", "-f", composeFile, "p").output(t)
+	if output["valid"] != true || output["error"] != nil || lookup(output, "warnings.0.code") == nil || output["project"] != nil {
+		t.Errorf("expected a valid report with the warnings only, got %v", output)
+	}
+
+	// Invalid projects are reported on stdout, exiting with the exit code of the error
+	invalid := writeFile(t, dir, "invalid.yml", "services:\n  web:\n    image: nginx\n    ports: [\"x\"]\n")
+	outputPath := filepath.Join(dir, "report.json")
+	result := runCLI(t, "", "--validate", "-f", invalid, "-o", outputPath, "p")
+	if result.code != exitCode(parser.ParseError) || result.stdout != "" {
+		t.Errorf("expected the ParseError exit code with the report in the output file, got %d: %q", result.code, result.stdout)
+	}
+	var report validationReport
+	if content, err := os.ReadFile(outputPath); err != nil || json.Unmarshal(content, &report) != nil {
+		t.Fatalf("expected the report to be written, got %q: %v", content, err)
+	}
+	if report.Valid || report.Error == nil || report.Error.Name != parser.ParseError || report.Warnings == nil {
+		t.Errorf("expected an invalid report with the error response, got %+v", report)
+	}
+
+	runCLI(t, "", "--validate", "--images", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--validate can't be used with")
+	runCLI(t, "", "--validate", "--output-format", "yaml", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "only supports JSON output")
+}
+
+func TestExtraSchema(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    ports: [\"8080:80\"]\n")
+	schema := writeFile(t, dir, "schema.json", `{"properties": {"services": {"additionalProperties": {"properties": {"ports": {"maxItems": 0}}}}}}`)
+	response := runCLI(t, "", "--schema", schema, "-f", composeFile, "p").expectError(t, parser.ValidationError, "Project doesn't conform to schema")
+	if response.Code != parser.SchemaViolationCode || response.Location == nil || response.Location.Path != "services.web.ports" || response.Location.Line != 4 {
+		t.Errorf("expected the violation to be located at the ports, got %s at %+v", response.Code, response.Location)
+	}
+	if output := runCLI(t, "", "--schema", schema, "-f", writeFile(t, dir, "valid.yml", "services:\n  web:\n    image: nginx\n"), "p").output(t); lookup(output, "services.web.image") != "nginx" {
+		t.Errorf("expected a project conforming to the schema to be output, got %v", output)
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    environment:\n      A: \"1\"\n    environment:\n      B: \"2\"\n")
+	output := runCLI(t, "", "--warnings", "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.web.environment.B") != "2" || lookup(output, "project.services.web.environment.A") != nil {
+		t.Errorf("expected the last environment to be kept, got %v", lookup(output, "project.services.web.environment"))
+	}
+	if lookup(output, "warnings.0.code") != parser.DuplicateKeyCode || lookup(output, "warnings.0.location.line") != 4.0 {
+		t.Errorf("expected a duplicate-key warning located at the first environment, got %v", output["warnings"])
+	}
+	// Without --warnings, the last definition is kept silently
+	if output := runCLI(t, "", "-f", composeFile, "p").output(t); lookup(output, "services.web.environment.B") != "2" {
+		t.Errorf("expected the last environment to be kept, got %v", output)
+	}
+}
+
+func TestDependencyCycle(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    links: [db]\n  db:\n    image: postgres\n    depends_on: [web]\n")
+	response := runCLI(t, "", "-f", composeFile, "p").expectError(t, parser.ValidationError, "services db -> web -> db depend on each other, as db depends_on web, web links db")
+	if response.Code != parser.DependencyCycleCode || response.Location == nil || response.Location.Path != "services.db.depends_on" {
+		t.Errorf("expected the cycle to be located at the first dependency, got %s at %+v", response.Code, response.Location)
+	}
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    links: [db]\n  db:\n    image: postgres\n")
+	output := runCLI(t, "", "--warnings", "-f", composeFile, "p").output(t)
+	if lookup(output, "warnings.0.code") != parser.DeprecatedFieldWarning || lookup(output, "warnings.0.replacement") != "depends_on for the start order, and network aliases for link aliases" {
+		t.Errorf("expected a deprecated field warning with its replacement, got %v", output["warnings"])
+	}
+
+	// Fields of 2.x files are replaced as they're converted
+	legacy := writeFile(t, dir, "docker-compose.yml", "version: \"2.1\"\nservices:\n  web:\n    image: nginx\n    volume_driver: local\n    volumes: [data:/data]\nvolumes:\n  data: {}\n")
+	output = runCLI(t, "", "--warnings", "-f", legacy, "p").output(t)
+	found := false
+	for _, warning := range output["warnings"].([]any) {
+		if lookup(warning, "location.path") == "services.web.volume_driver" {
+			found = lookup(warning, "code") == parser.LegacyFieldCode && lookup(warning, "replacement") == "the driver of the top-level volumes"
+		}
+	}
+	if !found {
+		t.Errorf("expected the volume_driver warning with its replacement, got %v", output["warnings"])
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:1.24\n    environment: [A=1]\n")
+	override := writeFile(t, dir, "compose.override.yml", "services:\n  web:\n    image: nginx:1.25\n    environment:\n      A: \"2\"\n")
+	output := runCLI(t, "", "--overrides", "-f", composeFile, "-f", override, "p").output(t)
+	if lookup(output, "project.services.web.image") != "nginx:1.25" {
+		t.Errorf("expected the project alongside the overrides, got %v", output)
+	}
+	expected := []any{
+		map[string]any{"path": "services.web.environment.A", "previous": "1", "value": "2", "previousLocation": map[string]any{"file": composeFile, "line": 4.0, "column": 19.0, "path": "services.web.environment.A"},
+			"location": map[string]any{"file": override, "line": 5.0, "column": 7.0, "path": "services.web.environment.A"}},
+		map[string]any{"path": "services.web.image", "previous": "nginx:1.24", "value": "nginx:1.25", "previousLocation": map[string]any{"file": composeFile, "line": 3.0, "column": 5.0, "path": "services.web.image"},
+			"location": map[string]any{"file": override, "line": 3.0, "column": 5.0, "path": "services.web.image"}},
+	}
+	if !reflect.DeepEqual(output["overrides"], expected) {
+		t.Errorf("expected %v, got %v", expected, output["overrides"])
+	}
+	runCLI(t, "", "--overrides", "--output-format", "yaml", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "are only supported with JSON, target state and 2.1 output")
+}
+
+func TestExtensionSchemas(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    x-balena:\n      fleet: 1\n")
+	schema := writeFile(t, dir, "balena.json", `{"properties": {"fleet": {"type": "string"}}}`)
+	response := runCLI(t, "", "--extension-schema", "x-balena="+schema, "-f", composeFile, "p").expectError(t, parser.ValidationError, "Extensions don't conform to their schemas")
+	if response.Code != parser.ExtensionViolationCode || response.Location == nil || response.Location.Path != "services.web.x-balena.fleet" || response.Location.Line != 5 {
+		t.Errorf("expected the violation to be located within the extension, got %s at %+v", response.Code, response.Location)
+	}
+	if output := runCLI(t, "", "--extension-schema", "x-other="+schema, "-f", composeFile, "p").output(t); lookup(output, "services.web.image") != "nginx" {
+		t.Errorf("expected extensions of other keys not to be checked, got %v", output)
+	}
+	runCLI(t, "", "--extension-schema", "balena="+schema, "-f", composeFile, "p").expectError(t, parser.ArgumentError, `Extension schema key "balena" must start with x-`)
+}
+
+func TestCoercedValues(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    environment:\n      MODE: 0755\n")
+	output := runCLI(t, "", "--warnings", "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.web.environment.MODE") != "493" {
+		t.Errorf("expected the coerced value to be set, got %v", lookup(output, "project.services.web.environment"))
+	}
+	if lookup(output, "warnings.0.code") != parser.CoercedValueWarning || lookup(output, "warnings.0.replacement") != `"0755"` || lookup(output, "warnings.0.location.line") != 5.0 {
+		t.Errorf("expected a coerced value warning with the quoted literal, got %v", output["warnings"])
+	}
+}
+
+func TestGPU(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  ml:\n    image: nvcr.io/nvidia/pytorch\n    gpus: all\n  web:\n    image: nginx\n")
+	output := runCLI(t, "", "--gpu", "-f", composeFile, "p").output(t)
+	if lookup(output, "project.services.ml.image") != "nvcr.io/nvidia/pytorch" {
+		t.Errorf("expected the project, got %v", output["project"])
+	}
+	if lookup(output, "gpu.ml.0.driver") != "nvidia" || lookup(output, "gpu.ml.0.count") != -1.0 || lookup(output, "gpu.ml.0.capabilities.0") != "gpu" || lookup(output, "gpu.web") != nil {
+		t.Errorf("expected all GPUs of the nvidia driver for ml, got %v", output["gpu"])
+	}
+
+	composeFile = writeFile(t, t.TempDir(), "compose.yml", "services:\n  ml:\n    image: nvcr.io/nvidia/pytorch\n    gpus: [{driver: amd}]\n")
+	response := runCLI(t, "", "-f", composeFile, "p").expectError(t, parser.ValidationError, "Invalid device requests")
+	if len(response.Errors) != 1 || response.Errors[0].Code != parser.InvalidDeviceRequestCode || response.Errors[0].Location == nil || response.Errors[0].Location.Line != 4 {
+		t.Errorf("expected a located invalid device request, got %+v", response.Errors)
+	}
+}
+
+func TestKernelOptions(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    sysctls:\n      vm.swappiness: 10\n")
+	response := runCLI(t, "", "--target", "balena", "-f", composeFile, "p").expectError(t, parser.ValidationError, "vm.swappiness isn't namespaced")
+	if len(response.Errors) != 1 || response.Errors[0].Code != parser.InvalidSysctlCode || response.Errors[0].Location == nil || response.Errors[0].Location.Line != 4 {
+		t.Errorf("expected an invalid sysctl located at the sysctls field, got %+v", response.Errors)
+	}
+	if result := runCLI(t, "", "-f", composeFile, "p"); result.code != 0 {
+		t.Errorf("expected sysctls not to be checked without --target balena, got %d: %s", result.code, result.stderr)
+	}
+}
+
+func TestVolumeDriverOptions(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\nvolumes:\n  data:\n    driver_opts:\n      type: none\n      o: bind,uid=1000\n      device: /mnt/data\n")
+	output := runCLI(t, "", "--target", "balena", "--warnings", "-f", composeFile, "p").output(t)
+	warnings, _ := output["warnings"].([]any)
+	found := false
+	for _, warning := range warnings {
+		if lookup(warning, "code") == parser.IgnoredDriverOptionCode {
+			found = lookup(warning, "location.path") == "volumes.data.driver_opts.o" && lookup(warning, "location.line") == 8.0
+		}
+	}
+	if !found {
+		t.Errorf("expected the ignored mount option to be located at driver_opts.o, got %v", output["warnings"])
+	}
+
+	composeFile = writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\nvolumes:\n  data:\n    driver_opts:\n      size: 10G\n")
+	response := runCLI(t, "", "--target", "balena", "-f", composeFile, "p").expectError(t, parser.ValidationError, "driver_opts.size requires quotas")
+	if len(response.Errors) != 1 || response.Errors[0].Code != parser.InvalidDriverOptionCode {
+		t.Errorf("expected an invalid driver option, got %+v", response.Errors)
+	}
+}
+
+func TestContainerNames(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\n    container_name: p-web-1\n")
+	response := runCLI(t, "", "-f", composeFile, "p").expectError(t, parser.ValidationError, `which docker compose gives a container of service "web"`)
+	if len(response.Errors) != 1 || response.Errors[0].Code != parser.DuplicateContainerNameCode || response.Errors[0].Location == nil || response.Errors[0].Location.Line != 6 {
+		t.Errorf("expected a duplicate container name located at db, got %+v", response.Errors)
+	}
+	if result := runCLI(t, "", "-f", composeFile, "other"); result.code != 0 {
+		t.Errorf("expected the name of another project not to collide, got %d: %s", result.code, result.stderr)
+	}
+}
+
+func TestNamespaceModes(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    ipc: service:db\n")
+	response := runCLI(t, "", "-f", composeFile, "p").expectError(t, parser.ValidationError, `services.web.ipc references service "db", which doesn't exist`)
+	if len(response.Errors) != 1 || response.Errors[0].Code != parser.InvalidNamespaceModeCode || response.Errors[0].Location == nil || response.Errors[0].Location.Line != 4 {
+		t.Errorf("expected an invalid namespace mode located at the ipc field, got %+v", response.Errors)
+	}
+
+	composeFile = writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    uts: private\n")
+	runCLI(t, "", "--target", "balena", "-f", composeFile, "p").expectError(t, parser.ValidationError, `services.web.uts must be host, got "private"`)
+	if result := runCLI(t, "", "-f", composeFile, "p"); result.code != 0 {
+		t.Errorf("expected namespace mode values not to be checked without --target balena, got %d: %s", result.code, result.stderr)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Defaults shown in the man page in place of those of the machine generating it
+var manDefaults = map[string]string{
+	"--timeout":           parser.DefaultTimeout.String(),
+	"--batch-concurrency": "number of CPUs",
+}
+
+// Run the man subcommand, printing the balena-compose-parser(1) man page in roff format
+func runMan(args []string) {
+	if len(args) > 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Unexpected arguments: %v\n", args)+usage)
+	}
+	fmt.Fprint(os.Stdout, manPage())
+}
+
+func manPage() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %s 1 \"\" \"%s %s\" \"User Commands\"\n", strings.ToUpper(commandName), commandName, roffEscape(version))
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- parse and validate docker-compose files\n", commandName)
+
+	// The synopsis and exit codes are taken from the usage message, so they're only maintained once
+	b.WriteString(".SH SYNOPSIS\n")
+	for _, line := range usageSection("Usage:") {
+		name, args, _ := strings.Cut(line, " ")
+		fmt.Fprintf(&b, ".B %s\n%s\n.br\n", roffEscape(name), roffEscape(args))
+	}
+	b.WriteString(".SH DESCRIPTION\n")
+	b.WriteString("Parses one or more docker-compose files using compose-go and outputs the normalized project, " +
+		"or a structured error response on stderr if parsing fails.\n")
+	fmt.Fprintf(&b, "Run \\fB%s \\-\\-help\\fR for details of each mode and of the output formats.\n", commandName)
+
+	b.WriteString(".SH OPTIONS\n")
+	manOptions(&b, parseCommandFlags())
+	b.WriteString(".SH COMMANDS\n")
+	for _, name := range slices.Sorted(maps.Keys(subcommands)) {
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s.\n", name, roffEscape(subcommands[name].description))
+	}
+	b.WriteString(".SS Serve options\n")
+	manOptions(&b, serveCommandFlags())
+	b.WriteString(".SS Release options\n")
+	manOptions(&b, releaseCommandFlags())
+	b.WriteString(".SS Migrate options\n")
+	manOptions(&b, migrateCommandFlags())
+	b.WriteString(".SS Lint options\n")
+	manOptions(&b, lintCommandFlags())
+
+	b.WriteString(".SH EXIT STATUS\n")
+	for _, line := range usageSection("Exit codes:") {
+		code, description, _ := strings.Cut(line, " ")
+		if _, err := strconv.Atoi(code); err != nil {
+			// Long descriptions continue on the following lines
+			fmt.Fprintf(&b, "%s\n", roffEscape(line))
+			continue
+		}
+		fmt.Fprintf(&b, ".TP\n.B %s\n%s\n", code, roffEscape(strings.TrimSpace(description)))
+	}
+	b.WriteString(".SH EXAMPLES\n")
+	for _, line := range usageSection("Example:") {
+		fmt.Fprintf(&b, ".PP\n.nf\n%s\n.fi\n", roffEscape(strings.TrimSpace(line)))
+	}
+	b.WriteString(".SH ENVIRONMENT\n")
+	fmt.Fprintf(&b, ".TP\n.B %s\nDefault for \\fB\\-\\-timeout\\fR.\n", timeoutEnvVar)
+	return b.String()
+}
+
+func manOptions(b *strings.Builder, commandFlags []commandFlag) {
+	for _, f := range commandFlags {
+		fmt.Fprintf(b, ".TP\n.B %s", roffEscape(f.Name))
+		if f.Arg != "" {
+			fmt.Fprintf(b, " \\fI%s\\fR", roffEscape(f.Arg))
+		}
+		b.WriteString("\n" + roffEscape(f.Usage))
+		defValue, ok := manDefaults[f.Name]
+		if !ok && f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+			defValue = f.DefValue
+		}
+		if defValue != "" {
+			fmt.Fprintf(b, " (default: %s)", roffEscape(defValue))
+		}
+		b.WriteString(".\n")
+	}
+}
+
+// Lines of a section of the usage message, from its title to the next blank line
+func usageSection(title string) []string {
+	var lines []string
+	inSection := false
+	for _, line := range strings.Split(usage, "\n") {
+		if rest, ok := strings.CutPrefix(line, title); ok {
+			inSection = true
+			line = rest
+		} else if inSection && line == "" {
+			break
+		}
+		if inSection && strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return lines
+}
+
+// Escape text so roff doesn't interpret it as requests, escapes or hyphens
+func roffEscape(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestManPage(t *testing.T) {
+	result := runCLI(t, "", "man")
+	if result.code != 0 {
+		t.Fatalf("expected the man page, got %d: %s", result.code, result.stderr)
+	}
+	page := result.stdout
+	if !strings.HasPrefix(page, ".TH BALENA-COMPOSE-PARSER 1 ") {
+		t.Errorf("expected a balena-compose-parser(1) man page, got %q", page[:min(len(page), 80)])
+	}
+	for _, section := range []string{".SH NAME", ".SH SYNOPSIS", ".SH OPTIONS", ".SH COMMANDS", ".SH EXIT STATUS", ".SH EXAMPLES", ".SH ENVIRONMENT"} {
+		if !strings.Contains(page, "\n"+section+"\n") {
+			t.Errorf("expected the %s section", section)
+		}
+	}
+	for _, f := range parseCommandFlags() {
+		if !strings.Contains(page, ".B "+roffEscape(f.Name)) {
+			t.Errorf("expected %s to be documented", f.Name)
+		}
+	}
+	// Defaults are those of any machine, not the one generating the page
+	if !strings.Contains(page, "(default: number of CPUs)") || !strings.Contains(page, "(default: "+parser.DefaultTimeout.String()+")") {
+		t.Error("expected the machine independent defaults")
+	}
+	if !strings.Contains(page, ".TP\n.B 6\nIOError, a file or remote resource couldn't be read or written\n") {
+		t.Error("expected the exit codes of the usage message")
+	}
+	// Descriptions continued on following lines of the usage message belong to the same exit code
+	if !strings.Contains(page, "or sets options the engine rejects\nwhen creating the container:") || strings.Contains(page, ".B when") {
+		t.Error("expected the continued description of the ValidationError exit code")
+	}
+
+	if _, err := exec.LookPath("groff"); err == nil {
+		cmd := exec.Command("groff", "-man", "-Tutf8", "-ww", "-z")
+		cmd.Stdin = strings.NewReader(page)
+		if output, err := cmd.CombinedOutput(); err != nil || len(output) > 0 {
+			t.Errorf("expected the man page to render without warnings, got %s: %v", output, err)
+		}
+	}
+
+	runCLI(t, "", "man", "extra").expectError(t, parser.ArgumentError, "Unexpected arguments: [extra]")
+}
+
+func TestRoffEscape(t *testing.T) {
+	for text, expected := range map[string]string{
+		"--timeout":     `\-\-timeout`,
+		`C:\path`:       `C:\epath`,
+		".env files":    `\&.env files`,
+		"'quoted'":      `\&'quoted'`,
+		"plain text":    "plain text",
+		"a.b and c'd":   "a.b and c'd",
+		"project-name.": `project\-name.`,
+	} {
+		if escaped := roffEscape(text); escaped != expected {
+			t.Errorf("expected %q to be escaped as %q, got %q", text, expected, escaped)
+		}
+	}
+}
+
+func TestUsageSection(t *testing.T) {
+	lines := usageSection("Exit codes:")
+	if len(lines) == 0 || lines[0] != "0  Success" {
+		t.Errorf("expected the exit codes, got %v", lines)
+	}
+	if lines := usageSection("Missing:"); lines != nil {
+		t.Errorf("expected no lines of a missing section, got %v", lines)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// migrateFlags are the command line flags of the migrate subcommand
+type migrateFlags struct {
+	composeFile string
+	outputPath  string
+	logLevel    string
+	quiet       bool
+}
+
+// Create the flag set of the migrate subcommand, storing values in o
+func newMigrateFlagSet(o *migrateFlags) *flag.FlagSet {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&o.composeFile, "f", "", "Path to the legacy `compose-file` to migrate, or \"-\" for stdin")
+	flags.StringVar(&o.outputPath, "o", "", "Write output atomically to `path` instead of stdout")
+	flags.StringVar(&o.logLevel, "log-level", logrus.InfoLevel.String(), "Minimum `level` of logs written to stderr")
+	flags.BoolVar(&o.quiet, "quiet", false, "Don't write any logs to stderr")
+	return flags
+}
+
+// Run the migrate subcommand, writing a compose file of the 1, 2.x or 3.x format as compose spec YAML. Each
+// field converted is logged as a warning with its code and location.
+func runMigrate(args []string) {
+	var o migrateFlags
+	flags := newMigrateFlagSet(&o)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stdout, usage)
+			return
+		}
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if err := configureLogging(o.logLevel, o.quiet); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if flags.NArg() > 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Unexpected arguments: %v\n", flags.Args())+usage)
+	}
+	if o.composeFile == "" {
+		fail(parser.ArgumentError, "A compose file must be specified with -f\n"+usage)
+	}
+
+	var content []byte
+	var err error
+	if o.composeFile == parser.StdinPath {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(o.composeFile)
+	}
+	if err != nil {
+		fail(parser.IOError, fmt.Sprintf("Failed to read compose file %s: %v", o.composeFile, err))
+	}
+	output, warnings, err := parser.Migrate(content, o.composeFile)
+	if err != nil {
+		exitWithError(err)
+	}
+	for _, warning := range warnings {
+		logrus.WithFields(logrus.Fields{"code": warning.Code, "location": warning.Location}).Warn(warning.Message)
+	}
+	if err := writeOutput(o.outputPath, output, false); err != nil {
+		fail(parser.IOError, err.Error())
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestMigrate(t *testing.T) {
+	dir := t.TempDir()
+	content := "version: \"2.1\"\nservices:\n  web:\n    image: nginx\n    net: host\n"
+	composeFile := writeFile(t, dir, "docker-compose.yml", content)
+	expected := "services:\n  web:\n    image: nginx\n    network_mode: host\n"
+
+	result := runCLI(t, "", "migrate", "-f", composeFile)
+	if result.code != 0 || result.stdout != expected {
+		t.Fatalf("expected the migrated compose file, got %d: %q", result.code, result.stdout)
+	}
+	// Each conversion is logged as a warning located in the compose file
+	if !strings.Contains(result.stderr, parser.MigratedFieldCode) || !strings.Contains(result.stderr, parser.LegacyFieldCode) || !strings.Contains(result.stderr, composeFile) {
+		t.Errorf("expected the warnings of the conversions, got %s", result.stderr)
+	}
+	if result := runCLI(t, content, "migrate", "--quiet", "-f", "-"); result.stdout != expected || result.stderr != "" {
+		t.Errorf("expected the migrated compose file from stdin without logs, got %q and %q", result.stdout, result.stderr)
+	}
+	outputPath := filepath.Join(dir, "compose.yml")
+	if result := runCLI(t, "", "migrate", "-f", composeFile, "-o", outputPath); result.code != 0 {
+		t.Fatalf("expected the migrated compose file to be written, got %d: %s", result.code, result.stderr)
+	}
+	if written, err := os.ReadFile(outputPath); err != nil || string(written) != expected {
+		t.Errorf("expected the output file, got %q: %v", written, err)
+	}
+
+	runCLI(t, "", "migrate").expectError(t, parser.ArgumentError, "A compose file must be specified with -f")
+	runCLI(t, "", "migrate", "-f", composeFile, "extra").expectError(t, parser.ArgumentError, "Unexpected arguments: [extra]")
+	runCLI(t, "", "migrate", "-f", filepath.Join(dir, "missing.yml")).expectError(t, parser.IOError, "Failed to read compose file")
+	runCLI(t, "services: [\n", "migrate", "-f", "-").expectError(t, parser.ParseError, "Failed to parse -")
+}
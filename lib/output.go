@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// atomicFile is written to a temp file alongside its destination, which is renamed into place on Commit,
+// so that readers of the destination never see partial output even if the parser crashes mid-write
+type atomicFile struct {
+	*os.File
+	path string
+}
+
+func createAtomicFile(path string) (*atomicFile, error) {
+	// The temp file must be in the same directory for the rename to be atomic
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create output file: %v", err)
+	}
+	return &atomicFile{File: f, path: path}, nil
+}
+
+// Commit flushes the written content to disk and renames it to the destination
+func (f *atomicFile) Commit() error {
+	if err := f.Chmod(0o644); err != nil {
+		f.Abort()
+		return fmt.Errorf("Failed to write output file: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Abort()
+		return fmt.Errorf("Failed to write output file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("Failed to write output file: %v", err)
+	}
+	if err := os.Rename(f.Name(), f.path); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("Failed to write output file: %v", err)
+	}
+	return nil
+}
+
+// Abort discards the written content, leaving any existing destination file untouched
+func (f *atomicFile) Abort() {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// Header preceding --compress output on stdout, so readers can tell it apart from uncompressed output.
+// Output files aren't given the header, so they remain valid gzip files.
+const compressedHeader = "Content-Encoding: gzip\n"
+
+// Gzip compress output, preceded by compressedHeader if written to stdout
+func compressOutput(output []byte, stdout bool) []byte {
+	var b bytes.Buffer
+	if stdout {
+		b.WriteString(compressedHeader)
+	}
+	gz := gzip.NewWriter(&b)
+	// Writes to a bytes.Buffer can't fail
+	gz.Write(output)
+	gz.Close()
+	return b.Bytes()
+}
+
+// Write output to the given path atomically, or to stdout if the path is empty, gzip compressed if compress is set
+func writeOutput(path string, output []byte, compress bool) error {
+	if compress {
+		output = compressOutput(output, path == "")
+	}
+	if path == "" {
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+	f, err := createAtomicFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(output); err != nil {
+		f.Abort()
+		return fmt.Errorf("Failed to write output file: %v", err)
+	}
+	return f.Commit()
+}
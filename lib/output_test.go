@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Check that dir only contains the given files, e.g. that no temp files were left behind
+func expectFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	for _, entry := range entries {
+		found = append(found, entry.Name())
+	}
+	if !slices.Equal(found, names) {
+		t.Errorf("expected the files %v, got %v", names, found)
+	}
+}
+
+func TestAtomicFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "output.json", "previous")
+
+	f, err := createAtomicFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("partial")
+	if content, _ := os.ReadFile(path); string(content) != "previous" {
+		t.Errorf("expected the destination to be untouched until committed, got %q", content)
+	}
+	f.Abort()
+	if content, _ := os.ReadFile(path); string(content) != "previous" {
+		t.Errorf("expected the destination to be untouched when aborted, got %q", content)
+	}
+	expectFiles(t, dir, "output.json")
+
+	if f, err = createAtomicFile(path); err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("output")
+	if err := f.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "output" {
+		t.Errorf("expected the committed output, got %q", content)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o644 {
+		t.Errorf("expected the output file to be world readable, got %v: %v", info.Mode(), err)
+	}
+	expectFiles(t, dir, "output.json")
+
+	if _, err := createAtomicFile(filepath.Join(dir, "missing", "output.json")); err == nil {
+		t.Error("expected an error creating an output file in a missing directory")
+	}
+}
+
+func TestOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n")
+	outputPath := filepath.Join(dir, "output", "project.json")
+	if err := os.Mkdir(filepath.Dir(outputPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	result := runCLI(t, "", "-o", outputPath, "-f", composeFile, "p")
+	if result.code != 0 || result.stdout != "" {
+		t.Fatalf("expected no output on stdout, got %d: %s%s", result.code, result.stdout, result.stderr)
+	}
+	content, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := runCLI(t, "", "-f", composeFile, "p").stdout
+	if string(content) != stdout {
+		t.Errorf("expected the output on stdout to be written to the file, got %s", content)
+	}
+
+	// A failed parse leaves the previous output in place
+	invalid := writeFile(t, dir, "invalid.yml", "services: [\n")
+	runCLI(t, "", "-o", outputPath, "-f", invalid, "p").errorResponse(t)
+	if previous, _ := os.ReadFile(outputPath); string(previous) != string(content) {
+		t.Errorf("expected the previous output to be kept, got %s", previous)
+	}
+	expectFiles(t, filepath.Dir(outputPath), "project.json")
+
+	runCLI(t, "", "-o", filepath.Join(dir, "missing", "project.json"), "-f", composeFile, "p").expectError(t, parser.IOError, "Failed to create output file")
+}
+
+// Decompress gzip compressed output
+func gunzip(t *testing.T, compressed []byte) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("expected gzip compressed output, got %q: %v", compressed, err)
+	}
+	output, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return output
+}
+
+func TestCompressOutput(t *testing.T) {
+	compressed := compressOutput([]byte(`{"name": "p"}`), true)
+	body, ok := bytes.CutPrefix(compressed, []byte(compressedHeader))
+	if !ok {
+		t.Fatalf("expected the output on stdout to start with the header, got %q", compressed)
+	}
+	if output := gunzip(t, body); string(output) != `{"name": "p"}` {
+		t.Errorf("expected the output to be compressed, got %q", output)
+	}
+	if output := gunzip(t, compressOutput([]byte(`{"name": "p"}`), false)); string(output) != `{"name": "p"}` {
+		t.Errorf("expected a plain gzip file, got %q", output)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n")
+
+	result := runCLI(t, "", "--compress", "-f", composeFile, "p")
+	body, ok := strings.CutPrefix(result.stdout, compressedHeader)
+	if result.code != 0 || !ok {
+		t.Fatalf("expected compressed output on stdout, got %d: %q %s", result.code, result.stdout, result.stderr)
+	}
+	var project map[string]any
+	if err := json.Unmarshal(gunzip(t, []byte(body)), &project); err != nil || lookup(project, "services.web.image") != "nginx" {
+		t.Errorf("expected the compressed project, got %v: %v", project, err)
+	}
+
+	output := filepath.Join(dir, "project.json.gz")
+	if result := runCLI(t, "", "--compress", "-o", output, "-f", composeFile, "p"); result.code != 0 || result.stdout != "" {
+		t.Fatalf("expected the output file to be written, got %d: %q %s", result.code, result.stdout, result.stderr)
+	}
+	compressed, _ := os.ReadFile(output)
+	if err := json.Unmarshal(gunzip(t, compressed), &project); err != nil {
+		t.Errorf("expected the output file to be a plain gzip file, got %v", err)
+	}
+
+	// The whole NDJSON stream of batch mode is compressed
+	manifest, _ := json.Marshal([]batchProject{{Files: []string{composeFile}, ProjectName: "web"}, {Files: []string{composeFile}, ProjectName: "other"}})
+	result = runCLI(t, string(manifest), "--compress", "--batch", "-")
+	body, ok = strings.CutPrefix(result.stdout, compressedHeader)
+	if !ok {
+		t.Fatalf("expected compressed batch output, got %q", result.stdout)
+	}
+	if results := batchResults(t, gunzip(t, []byte(body))); len(results) != 2 {
+		t.Errorf("expected a result line per project, got %+v", results)
+	}
+
+	runCLI(t, "", "--compress", "--watch", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--watch only supports")
+}
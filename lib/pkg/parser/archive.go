@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+)
+
+// Files in a project archive larger than this are skipped, as they can't be compose files,
+// env files or Dockerfiles, and only build context contents which the parser doesn't need
+const maxArchiveFileSize = 1024 * 1024
+
+// Total size of files extracted from a project archive
+const maxArchiveSize = 100 * 1024 * 1024
+
+// ParseArchive parses the compose files of a project tarball, optionally gzip compressed.
+// Compose file paths are relative to the root of the archive, and default to the compose files
+// compose-go discovers by name, e.g. docker-compose.yml and its override file, if empty.
+func (p *Parser) ParseArchive(ctx context.Context, archive io.Reader, composeFiles []string) (*Result, error) {
+	dir, err := os.MkdirTemp("", "balena-compose-parser-")
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to create project directory: %v", err), Err: err}
+	}
+	defer os.RemoveAll(dir)
+
+	if err := extractArchive(archive, dir); err != nil {
+		return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Failed to extract project archive: %v", err), Err: err}
+	}
+
+	var paths []string
+	for _, composeFile := range composeFiles {
+		local, err := containedPath(dir, composeFile)
+		if err != nil {
+			return nil, &Error{Name: ArgumentError, Message: err.Error(), Err: err}
+		}
+		paths = append(paths, local)
+	}
+	if len(paths) == 0 {
+		paths, err = defaultComposeFiles(dir)
+		if err != nil {
+			return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Project archive doesn't contain a compose file: %v", err), Err: err}
+		}
+	}
+
+	return p.Parse(ctx, paths)
+}
+
+// Extract the regular files and directories of a tar stream into dir
+func extractArchive(archive io.Reader, dir string) error {
+	reader := bufio.NewReader(archive)
+	// Detect gzip compression by its magic number
+	if magic, err := reader.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		archive = gz
+	} else {
+		archive = reader
+	}
+
+	var extracted int64
+	tr := tar.NewReader(archive)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		local, err := containedPath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(local, 0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if header.Size > maxArchiveFileSize {
+				continue
+			}
+			extracted += header.Size
+			if extracted > maxArchiveSize {
+				return fmt.Errorf("archive exceeds %d bytes", maxArchiveSize)
+			}
+			if err := os.MkdirAll(filepath.Dir(local), 0o700); err != nil {
+				return err
+			}
+			content, err := io.ReadAll(io.LimitReader(tr, header.Size))
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(local, content, 0o600); err != nil {
+				return err
+			}
+		}
+		// Links and special files are skipped, so nothing in the archive can reference outside of dir
+	}
+}
+
+// Resolve a slash separated path relative to dir, rejecting paths which escape it
+func containedPath(dir, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if slices.Contains(strings.Split(name, "/"), "..") {
+		return "", fmt.Errorf("invalid path %q outside of the project", name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(path.Clean("/"+name))), nil
+}
+
+// Find the compose file and optional override file in the root of dir, as `docker compose` would
+func defaultComposeFiles(dir string) ([]string, error) {
+	find := func(names []string) string {
+		for _, name := range names {
+			local := filepath.Join(dir, name)
+			if _, err := os.Stat(local); err == nil {
+				return local
+			}
+		}
+		return ""
+	}
+
+	composeFile := find(cli.DefaultFileNames)
+	if composeFile == "" {
+		return nil, fmt.Errorf("expected one of %s", strings.Join(cli.DefaultFileNames, ", "))
+	}
+	if override := find(cli.DefaultOverrideFileNames); override != "" {
+		return []string{composeFile, override}, nil
+	}
+	return []string{composeFile}, nil
+}
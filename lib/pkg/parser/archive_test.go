@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// An entry of a project archive, a regular file unless typeflag is set
+type archiveEntry struct {
+	name     string
+	content  string
+	typeflag byte
+	linkname string
+}
+
+// Build a project archive of entries, gzip compressed if compress is set
+func buildArchive(t *testing.T, compress bool, entries ...archiveEntry) *bytes.Buffer {
+	t.Helper()
+	var archive bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(&archive)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&archive)
+	}
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.content)), Typeflag: entry.typeflag, Linkname: entry.linkname}
+		if entry.typeflag == 0 {
+			header.Typeflag = tar.TypeReg
+		} else {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &archive
+}
+
+func TestParseArchive(t *testing.T) {
+	tests := []struct {
+		name         string
+		compress     bool
+		entries      []archiveEntry
+		composeFiles []string
+		command      string
+	}{
+		{
+			name: "discovered compose files",
+			entries: []archiveEntry{
+				{name: "app/", typeflag: tar.TypeDir},
+				{name: "compose.yaml", content: "services:\n  web:\n    image: nginx\n    command: [base]\n    env_file: app/web.env\n"},
+				{name: "compose.override.yaml", content: "services:\n  web:\n    command: [override]\n"},
+				{name: "app/web.env", content: "FROM_ENV_FILE=true\n"},
+			},
+			command: "override",
+		},
+		{
+			name:     "gzip compressed",
+			compress: true,
+			entries:  []archiveEntry{{name: "./docker-compose.yml", content: "services:\n  web:\n    image: nginx\n    env_file: web.env\n"}, {name: "web.env", content: "FROM_ENV_FILE=true\n"}},
+		},
+		{
+			name: "compose files",
+			entries: []archiveEntry{
+				{name: "compose.yaml", content: "services:\n  web:\n    image: alpine\n"},
+				{name: "deploy/compose.yml", content: "services:\n  web:\n    image: nginx\n    env_file: web.env\n"},
+				{name: "deploy/web.env", content: "FROM_ENV_FILE=true\n"},
+				{name: "deploy/arm.yml", content: "services:\n  web:\n    command: [arm]\n"},
+			},
+			composeFiles: []string{"deploy/compose.yml", "/deploy/arm.yml"},
+			command:      "arm",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{ProjectName: "test"}).ParseArchive(context.Background(), buildArchive(t, tt.compress, tt.entries...), tt.composeFiles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			web := result.Project.Services["web"]
+			if web.Image != "nginx" || web.Environment["FROM_ENV_FILE"] == nil {
+				t.Errorf("expected nginx with the variables of its env file, got %+v", web)
+			}
+			if command := strings.Join(web.Command, " "); command != tt.command {
+				t.Errorf("expected the command %q, got %q", tt.command, command)
+			}
+		})
+	}
+}
+
+func TestParseArchiveErrors(t *testing.T) {
+	compose := archiveEntry{name: "compose.yml", content: "services:\n  web:\n    image: nginx\n    env_file: web.env\n"}
+	tests := []struct {
+		name         string
+		entries      []archiveEntry
+		composeFiles []string
+		errName      string
+		message      string
+	}{
+		{name: "no compose file", entries: []archiveEntry{{name: "README.md", content: "#"}}, errName: ArgumentError, message: "Project archive doesn't contain a compose file"},
+		{name: "escaping entry", entries: []archiveEntry{compose, {name: "../web.env", content: "A=a\n"}}, errName: ArgumentError, message: `invalid path "../web.env" outside of the project`},
+		{name: "escaping compose file", entries: []archiveEntry{compose}, composeFiles: []string{"../compose.yml"}, errName: ArgumentError, message: `invalid path "../compose.yml" outside of the project`},
+		{name: "missing compose file", entries: []archiveEntry{compose}, composeFiles: []string{"other.yml"}, errName: IOError},
+		// Links are skipped, so can't read files outside of the archive
+		{name: "symlink", entries: []archiveEntry{compose, {name: "web.env", typeflag: tar.TypeSymlink, linkname: "/etc/hostname"}}, errName: IOError, message: "web.env"},
+		{name: "too large file", entries: []archiveEntry{compose, {name: "web.env", content: strings.Repeat("#", maxArchiveFileSize+1)}}, errName: IOError, message: "web.env"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Options{ProjectName: "test"}).ParseArchive(context.Background(), buildArchive(t, false, tt.entries...), tt.composeFiles)
+			expectError(t, err, tt.errName, tt.message)
+		})
+	}
+
+	_, err := New(Options{ProjectName: "test"}).ParseArchive(context.Background(), strings.NewReader("not an archive"), nil)
+	expectError(t, err, ArgumentError, "Failed to extract project archive")
+}
+
+func TestContainedPath(t *testing.T) {
+	dir := filepath.FromSlash("/project")
+	for name, expected := range map[string]string{
+		"compose.yml":     "/project/compose.yml",
+		"./app//a.yml":    "/project/app/a.yml",
+		"app/../a.yml":    "",
+		"/compose.yml":    "/project/compose.yml",
+		`deploy\arm.yml`:  "/project/deploy/arm.yml",
+		"../compose.yml":  "",
+		`app\..\..\a.yml`: "",
+	} {
+		local, err := containedPath(dir, name)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("expected %q to be rejected, got %q", name, local)
+			}
+		} else if err != nil || local != filepath.FromSlash(expected) {
+			t.Errorf("expected %q to be %q, got %q: %v", name, expected, local, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Balena architecture slugs of the platforms images are built and pulled for. Platforms are matched without
+// their OS, and arm without a variant is v7, as in the engine.
+var balenaArchitectures = map[string]string{
+	"amd64":    "amd64",
+	"arm64":    "aarch64",
+	"arm64/v8": "aarch64",
+	"arm":      "armv7hf",
+	"arm/v7":   "armv7hf",
+	"arm/v6":   "rpi",
+	"386":      "i386",
+}
+
+// The balena architecture of a platform such as linux/arm64, or "" if it isn't one balena supports
+func platformArch(platform string) string {
+	platform = strings.ToLower(platform)
+	if os, arch, ok := strings.Cut(platform, "/"); ok && os == "linux" {
+		platform = arch
+	}
+	return balenaArchitectures[platform]
+}
+
+// Name to parse a project with when normalizing for balena without a project name. It's random, so
+// that names compose-go derives from it can't be confused with names set in the compose files.
+func placeholderProjectName() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "balena-" + hex.EncodeToString(b)
+}
+
+// Normalize a project into the composition balena expects. compose-go names networks and volumes
+// which don't set a name <project>_<key>, but the supervisor names them itself, so these names are
+// removed along with the project name.
+func balenaNormalize(project *types.Project) {
+	derived := func(key string) string {
+		return project.Name + "_" + key
+	}
+	for key, network := range project.Networks {
+		if !network.External && network.Name == derived(key) {
+			network.Name = ""
+			project.Networks[key] = network
+		}
+	}
+	for key, volume := range project.Volumes {
+		if !volume.External && volume.Name == derived(key) {
+			volume.Name = ""
+			project.Volumes[key] = volume
+		}
+	}
+	project.Name = ""
+}
+
+// Restart policy the supervisor gives services which don't set one
+const balenaDefaultRestart = types.RestartPolicyAlways
+
+// Network the supervisor attaches services to which don't set networks or a network mode
+const balenaDefaultNetwork = "default"
+
+// Add the settings the supervisor gives services which don't set them: the always restart policy, the
+// default network, and the io.balena.supervised and io.balena.service-name labels. The ID labels the
+// supervisor also adds are only known on the device, so aren't added.
+func balenaDefaults(project *types.Project) {
+	for name, service := range project.Services {
+		if service.Restart == "" {
+			service.Restart = balenaDefaultRestart
+		}
+		if service.NetworkMode == "" && len(service.Networks) == 0 {
+			service.Networks = map[string]*types.ServiceNetworkConfig{balenaDefaultNetwork: nil}
+			if _, ok := project.Networks[balenaDefaultNetwork]; !ok {
+				if project.Networks == nil {
+					project.Networks = types.Networks{}
+				}
+				project.Networks[balenaDefaultNetwork] = types.NetworkConfig{Name: project.Name + "_" + balenaDefaultNetwork}
+			}
+		}
+		for label, value := range map[string]string{"io.balena.supervised": "true", "io.balena.service-name": name} {
+			if _, ok := service.Labels[label]; !ok {
+				service.Labels = service.Labels.Add(label, value)
+			}
+		}
+		project.Services[name] = service
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+func TestBalenaNormalize(t *testing.T) {
+	compose := "services:\n  web:\n    image: nginx\n    networks: [default, backend, named]\n    volumes: [\"data:/data\", \"named:/named\", \"shared:/shared\"]\n" +
+		"networks:\n  backend: {}\n  named:\n    name: my-network\n" +
+		"volumes:\n  data: {}\n  named:\n    name: my-volume\n  shared:\n    external: true\n"
+	for _, projectName := range []string{"test", ""} {
+		t.Run("project name "+projectName, func(t *testing.T) {
+			project := mustParse(t, Options{ProjectName: projectName, BalenaNormalize: true}, compose).Project
+			if project.Name != "" {
+				t.Errorf("expected the project name to be removed, got %s", project.Name)
+			}
+			// Names derived from the project name are removed, while those set in the compose files are kept
+			for key, expected := range map[string]string{"default": "", "backend": "", "named": "my-network"} {
+				if name := project.Networks[key].Name; name != expected {
+					t.Errorf("expected the %s network to be named %q, got %q", key, expected, name)
+				}
+			}
+			for key, expected := range map[string]string{"data": "", "named": "my-volume", "shared": "shared"} {
+				if name := project.Volumes[key].Name; name != expected {
+					t.Errorf("expected the %s volume to be named %q, got %q", key, expected, name)
+				}
+			}
+		})
+	}
+
+	project := mustParse(t, Options{}, compose).Project
+	if project.Name != "test" || project.Networks["backend"].Name != "test_backend" || project.Volumes["data"].Name != "test_data" {
+		t.Errorf("expected names derived from the project name without Options.BalenaNormalize, got %+v", project)
+	}
+	_, err := New(Options{}).ParseContent(context.Background(), []File{{Name: "compose.yml", Content: []byte(compose)}})
+	expectError(t, err, ArgumentError, "Project name is required")
+}
+
+func TestBalenaDefaults(t *testing.T) {
+	compose := "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\n    restart: unless-stopped\n    networks: [backend]\n    labels:\n      io.balena.service-name: database\n" +
+		"  host:\n    image: alpine\n    network_mode: host\n" +
+		"networks:\n  backend: {}\n"
+	for _, noNormalize := range []bool{false, true} {
+		t.Run(fmt.Sprintf("no normalize %t", noNormalize), func(t *testing.T) {
+			project := mustParse(t, Options{BalenaDefaults: true, NoNormalize: noNormalize}, compose).Project
+			web, db, host := project.Services["web"], project.Services["db"], project.Services["host"]
+			if web.Restart != types.RestartPolicyAlways || db.Restart != types.RestartPolicyUnlessStopped {
+				t.Errorf("expected restart: always unless set, got %s and %s", web.Restart, db.Restart)
+			}
+			// Services join the default network unless they set networks or a network mode
+			if _, ok := web.Networks["default"]; !ok || len(web.Networks) != 1 {
+				t.Errorf("expected web to join the default network, got %v", web.Networks)
+			}
+			if _, ok := db.Networks["default"]; ok || len(host.Networks) != 0 {
+				t.Errorf("expected the networks of db and host to be unchanged, got %v and %v", db.Networks, host.Networks)
+			}
+			if _, ok := project.Networks["default"]; !ok {
+				t.Errorf("expected the default network to be defined, got %v", project.Networks)
+			}
+			if web.Labels["io.balena.supervised"] != "true" || web.Labels["io.balena.service-name"] != "web" || db.Labels["io.balena.service-name"] != "database" {
+				t.Errorf("expected the bookkeeping labels unless set, got %v and %v", web.Labels, db.Labels)
+			}
+		})
+	}
+
+	web := mustParse(t, Options{}, compose).Project.Services["web"]
+	if web.Restart != "" || len(web.Labels) != 0 {
+		t.Errorf("expected no balena defaults without Options.BalenaDefaults, got %+v", web)
+	}
+}
+
+func TestPlaceholderProjectName(t *testing.T) {
+	name := placeholderProjectName()
+	if !strings.HasPrefix(name, "balena-") || len(name) != len("balena-")+32 || name == placeholderProjectName() {
+		t.Errorf("expected a random project name, got %s", name)
+	}
+}
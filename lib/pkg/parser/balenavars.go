@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// BalenaVariables are the fleet and device configuration variables of a device, as the balena API
+// returns them, to interpolate as they are on the device. Device variables override fleet variables.
+type BalenaVariables struct {
+	Fleet  []BalenaVariable
+	Device []BalenaVariable
+}
+
+// BalenaVariable is an environment variable resource of the balena API. Other fields of the resource,
+// e.g. its ID, are ignored.
+type BalenaVariable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Names the balena API accepts for variables
+var balenaVariableNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParseBalenaVariables parses a JSON object of {"fleet": [...], "device": [...]}, where each list is
+// an array of variable resources, or an API response of {"d": [...]} listing them
+func ParseBalenaVariables(data []byte) (*BalenaVariables, error) {
+	var lists struct {
+		Fleet  json.RawMessage `json:"fleet"`
+		Device json.RawMessage `json:"device"`
+	}
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Invalid balena variables: %v", err), Err: err}
+	}
+	variables := &BalenaVariables{}
+	for _, list := range []struct {
+		name      string
+		data      json.RawMessage
+		variables *[]BalenaVariable
+	}{{"fleet", lists.Fleet, &variables.Fleet}, {"device", lists.Device, &variables.Device}} {
+		if list.data == nil {
+			continue
+		}
+		var response struct {
+			D []BalenaVariable `json:"d"`
+		}
+		if err := json.Unmarshal(list.data, list.variables); err != nil {
+			if err := json.Unmarshal(list.data, &response); err != nil {
+				return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Invalid balena %s variables, expected an array of {\"name\": \"...\", \"value\": \"...\"} or {\"d\": [...]}: %v", list.name, err), Err: err}
+			}
+			*list.variables = response.D
+		}
+		for _, variable := range *list.variables {
+			if !balenaVariableNamePattern.MatchString(variable.Name) {
+				return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Invalid balena %s variable name %q", list.name, variable.Name)}
+			}
+		}
+	}
+	return variables, nil
+}
+
+// The variables set on the device by name, with device variables overriding fleet variables, and the
+// source of each
+func (v *BalenaVariables) environment() (map[string]string, map[string]string) {
+	environment, sources := map[string]string{}, map[string]string{}
+	if v == nil {
+		return environment, sources
+	}
+	for _, variable := range v.Fleet {
+		environment[variable.Name], sources[variable.Name] = variable.Value, BalenaFleetSource
+	}
+	for _, variable := range v.Device {
+		environment[variable.Name], sources[variable.Name] = variable.Value, BalenaDeviceSource
+	}
+	return environment, sources
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseBalenaVariables(t *testing.T) {
+	variables, err := ParseBalenaVariables([]byte(`{"fleet": [{"id": 1, "name": "TAG", "value": "1.25"}], "device": {"d": [{"name": "UUID", "value": "abc123"}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &BalenaVariables{Fleet: []BalenaVariable{{Name: "TAG", Value: "1.25"}}, Device: []BalenaVariable{{Name: "UUID", Value: "abc123"}}}
+	if !reflect.DeepEqual(variables, expected) {
+		t.Errorf("expected %+v, got %+v", expected, variables)
+	}
+	if variables, err := ParseBalenaVariables([]byte(`{}`)); err != nil || variables.Fleet != nil || variables.Device != nil {
+		t.Errorf("expected no variables, got %+v: %v", variables, err)
+	}
+
+	for data, msg := range map[string]string{
+		`[]`:                             "Invalid balena variables",
+		`{"fleet": "TAG=1.25"}`:          "Invalid balena fleet variables, expected an array",
+		`{"device": [{"name": "1TAG"}]}`: `Invalid balena device variable name "1TAG"`,
+	} {
+		_, err := ParseBalenaVariables([]byte(data))
+		expectError(t, err, ArgumentError, msg)
+	}
+}
+
+func TestBalenaVariables(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx:${TAG}\n    environment:\n      UUID: ${UUID}\n      REGION: ${REGION}\n      MODE: ${MODE}\n",
+		"build.env":   "TAG=env-file\nUUID=env-file\nREGION=env-file\nMODE=env-file\n",
+	})
+	variables := &BalenaVariables{
+		Fleet:  []BalenaVariable{{Name: "TAG", Value: "fleet"}, {Name: "UUID", Value: "fleet"}, {Name: "MODE", Value: "fleet"}},
+		Device: []BalenaVariable{{Name: "UUID", Value: "device"}, {Name: "MODE", Value: "device"}},
+	}
+	p := New(Options{
+		ProjectName:     "test",
+		NoOSEnv:         true,
+		EnvFiles:        []string{filepath.Join(dir, "build.env")},
+		Environment:     map[string]string{"MODE": "override"},
+		BalenaVariables: variables,
+		EnvResolution:   true,
+	})
+	result, err := p.Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Device variables override fleet variables, which override env files, and Options.Environment overrides both
+	web := result.Project.Services["web"]
+	if web.Image != "nginx:fleet" || *web.Environment["UUID"] != "device" || *web.Environment["REGION"] != "env-file" || *web.Environment["MODE"] != "override" {
+		t.Errorf("expected the variables in order of precedence, got %s and %v", web.Image, web.Environment)
+	}
+	sources := map[string]string{}
+	for _, resolution := range result.EnvResolution {
+		sources[resolution.Name] = resolution.Source
+	}
+	for name, expected := range map[string]string{"TAG": BalenaFleetSource, "UUID": BalenaDeviceSource, "REGION": EnvFileSource, "MODE": OverrideSource} {
+		if sources[name] != expected {
+			t.Errorf("expected %s from %s, got %q", name, expected, sources[name])
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Build is the build of a service as the balena builder takes it, with paths relative to the project
+// directory, which is uploaded to the builder
+type Build struct {
+	// Context is the build context, relative to the project directory with forward slashes, or a URL
+	Context string `json:"context"`
+	// Dockerfile is the path of the Dockerfile, relative to the context
+	Dockerfile string `json:"dockerfile"`
+	// Args are the build args, without those which are unset
+	Args   map[string]string `json:"args"`
+	Target string            `json:"target,omitempty"`
+	// Platforms are the platforms to build for, e.g. linux/arm64
+	Platforms []string `json:"platforms,omitempty"`
+	// AdditionalContexts are the named contexts, as relative paths like Context, or docker-image://,
+	// service: and URL references
+	AdditionalContexts map[string]string `json:"additionalContexts,omitempty"`
+	// Template is set if the Dockerfile is a Dockerfile.template, which the builder renders for the
+	// device type of the fleet
+	Template *DockerfileTemplate `json:"template,omitempty"`
+}
+
+// DockerfileTemplate is a Dockerfile.template, with %%VARIABLE%% references the builder substitutes
+type DockerfileTemplate struct {
+	// Variables are the variables referenced, in order of first reference
+	Variables []string `json:"variables"`
+	// Rendered is the Dockerfile rendered for Options.DeviceType, if set. Unknown variables are left as is.
+	Rendered string `json:"rendered,omitempty"`
+}
+
+// Suffix of Dockerfiles which the builder renders as templates
+const dockerfileTemplateSuffix = ".template"
+
+var templateVariablePattern = regexp.MustCompile(`%%([A-Z0-9_]+)%%`)
+
+// Values of the variables of Dockerfile templates for a device type, including the legacy RESIN_ names
+func templateVariables(deviceType string) map[string]string {
+	arch := deviceTypeArchitectures[deviceType]
+	return map[string]string{
+		"BALENA_MACHINE_NAME": deviceType,
+		"BALENA_ARCH":         arch,
+		"RESIN_MACHINE_NAME":  deviceType,
+		"RESIN_ARCH":          arch,
+	}
+}
+
+// Read the Dockerfile template of a build, rendering it for the device type if set. The builder uses
+// Dockerfile.template in place of a missing default Dockerfile, so the dockerfile is updated to match.
+// Returns nil if the Dockerfile isn't a template, or can't be read.
+func dockerfileTemplate(build *Build, contextDir, deviceType string) *DockerfileTemplate {
+	if build.Dockerfile == "Dockerfile" {
+		if _, err := os.Stat(filepath.Join(contextDir, build.Dockerfile)); errors.Is(err, fs.ErrNotExist) {
+			if _, err := os.Stat(filepath.Join(contextDir, build.Dockerfile+dockerfileTemplateSuffix)); err == nil {
+				build.Dockerfile += dockerfileTemplateSuffix
+			}
+		}
+	}
+	if !strings.HasSuffix(build.Dockerfile, dockerfileTemplateSuffix) {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(contextDir, build.Dockerfile))
+	if err != nil {
+		return nil
+	}
+	template := &DockerfileTemplate{Variables: []string{}}
+	for _, match := range templateVariablePattern.FindAllSubmatch(content, -1) {
+		if name := string(match[1]); !slices.Contains(template.Variables, name) {
+			template.Variables = append(template.Variables, name)
+		}
+	}
+	if deviceType != "" {
+		values := templateVariables(deviceType)
+		template.Rendered = templateVariablePattern.ReplaceAllStringFunc(string(content), func(reference string) string {
+			if value, ok := values[strings.Trim(reference, "%")]; ok {
+				return value
+			}
+			return reference
+		})
+	}
+	return template
+}
+
+// Prefixes of build contexts which aren't local directories, as compose-go recognizes them
+var remoteContextPrefixes = []string{"https://", "http://", "git://", "ssh://", "github.com/", "git@", types.ServicePrefix}
+
+func isLocalContext(context string) bool {
+	if strings.Contains(context, "://") {
+		return false
+	}
+	for _, prefix := range remoteContextPrefixes {
+		if strings.HasPrefix(context, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// A local path relative to the project directory with forward slashes, which must be in the project directory
+func projectRelativePath(p, projectDir string) (string, error) {
+	if p == "" {
+		return ".", nil
+	}
+	if filepath.IsAbs(p) {
+		relative, err := filepath.Rel(projectDir, p)
+		if err != nil {
+			return "", err
+		}
+		p = relative
+	}
+	p = path.Clean(filepath.ToSlash(p))
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("%s is outside the project directory", p)
+	}
+	return p, nil
+}
+
+// Describe the build of each service with a build section, by service name, rendering Dockerfile
+// templates for the device type if set. Local contexts outside the project directory are a
+// ValidationError, as the builder only receives the project directory.
+func describeBuilds(project *types.Project, deviceType string, composeFiles []string) (map[string]Build, *Error) {
+	builds := map[string]Build{}
+	for name, service := range project.Services {
+		if service.Build == nil {
+			continue
+		}
+		build := Build{
+			Context:    service.Build.Context,
+			Dockerfile: service.Build.Dockerfile,
+			Args:       map[string]string{},
+			Target:     service.Build.Target,
+			Platforms:  service.Build.Platforms,
+		}
+		var err error
+		if isLocalContext(build.Context) {
+			if build.Context, err = projectRelativePath(build.Context, project.WorkingDir); err != nil {
+				path := "services." + name + ".build.context"
+				return nil, &Error{Name: ValidationError, Message: fmt.Sprintf("%s: %v", path, err), Location: locatePath(path, composeFiles), Err: err}
+			}
+			build.Template = dockerfileTemplate(&build, filepath.Join(project.WorkingDir, build.Context), deviceType)
+		}
+		for arg, value := range service.Build.Args {
+			if value != nil {
+				build.Args[arg] = *value
+			}
+		}
+		if len(service.Build.AdditionalContexts) > 0 {
+			build.AdditionalContexts = maps.Clone(service.Build.AdditionalContexts)
+			for key, context := range build.AdditionalContexts {
+				if !isLocalContext(context) {
+					continue
+				}
+				if build.AdditionalContexts[key], err = projectRelativePath(context, project.WorkingDir); err != nil {
+					path := "services." + name + ".build.additional_contexts." + key
+					return nil, &Error{Name: ValidationError, Message: fmt.Sprintf("%s: %v", path, err), Location: locatePath(path, composeFiles), Err: err}
+				}
+			}
+		}
+		builds[name] = build
+	}
+	return builds, nil
+}
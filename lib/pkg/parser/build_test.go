@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuilds(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n" +
+			"  web:\n    build:\n      context: ./web\n      dockerfile: Dockerfile.prod\n      args:\n        VERSION: \"1\"\n        UNSET:\n      target: runtime\n      platforms: [linux/arm64]\n" +
+			"      additional_contexts:\n        assets: ./assets\n        base: docker-image://alpine:3.20\n        api: service:api\n" +
+			"  api:\n    build: https://github.com/balena-io/api.git#main\n" +
+			"  db:\n    image: postgres\n",
+		"web/Dockerfile.prod": "FROM nginx\n",
+	})
+	result, err := New(Options{ProjectName: "test", Builds: true}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]Build{
+		"web": {
+			Context:            "web",
+			Dockerfile:         "Dockerfile.prod",
+			Args:               map[string]string{"VERSION": "1"},
+			Target:             "runtime",
+			Platforms:          []string{"linux/arm64"},
+			AdditionalContexts: map[string]string{"assets": "assets", "base": "docker-image://alpine:3.20", "api": "service:api"},
+		},
+		"api": {Context: "https://github.com/balena-io/api.git#main", Dockerfile: "Dockerfile", Args: map[string]string{}},
+	}
+	if !reflect.DeepEqual(result.Builds, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result.Builds)
+	}
+
+	result, err = New(Options{ProjectName: "test"}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if err != nil || result.Builds != nil {
+		t.Errorf("expected no builds without Options.Builds, got %+v: %v", result, err)
+	}
+}
+
+func TestBuildsErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app/compose.yml":  "services:\n  web:\n    build: ../web\n",
+		"app/contexts.yml": "services:\n  web:\n    build:\n      context: .\n      additional_contexts:\n        shared: ../shared\n",
+		"app/Dockerfile":   "FROM nginx\n",
+		"web/Dockerfile":   "FROM nginx\n",
+	})
+	p := New(Options{ProjectName: "test", Builds: true})
+	_, err := p.Parse(context.Background(), []string{filepath.Join(dir, "app", "compose.yml")})
+	if parserErr := expectError(t, err, ValidationError, "services.web.build.context: ../web is outside the project directory"); parserErr.Location == nil || parserErr.Location.Path != "services.web.build.context" {
+		t.Errorf("expected the error to be located at the context, got %+v", parserErr.Location)
+	}
+	_, err = p.Parse(context.Background(), []string{filepath.Join(dir, "app", "contexts.yml")})
+	expectError(t, err, ValidationError, "services.web.build.additional_contexts.shared: ../shared is outside the project directory")
+}
+
+func TestProjectRelativePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		err      bool
+	}{
+		{path: "", expected: "."},
+		{path: "/project", expected: "."},
+		{path: "/project/web/", expected: "web"},
+		{path: "./web/../api", expected: "api"},
+		{path: "/project/../other", err: true},
+		{path: "../web", err: true},
+		{path: "..", err: true},
+		{path: "..web", expected: "..web"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p, err := projectRelativePath(tt.path, "/project")
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected %s to be outside the project directory, got %s", tt.path, p)
+				}
+			} else if err != nil || p != tt.expected {
+				t.Errorf("expected %s, got %s: %v", tt.expected, p, err)
+			}
+		})
+	}
+}
+
+func TestIsLocalContext(t *testing.T) {
+	for context, expected := range map[string]bool{
+		"./web":                            true,
+		"/project/web":                     true,
+		"https://example.com/context.tar":  false,
+		"git@github.com:balena-io/api.git": false,
+		"github.com/balena-io/api":         false,
+		"s3://bucket/context":              false,
+		"service:api":                      false,
+	} {
+		if local := isLocalContext(context); local != expected {
+			t.Errorf("expected %s to be local %t, got %t", context, expected, local)
+		}
+	}
+}
+
+func TestDockerfileTemplate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n" +
+			"  web:\n    build: ./web\n" +
+			"  api:\n    build:\n      context: ./api\n      dockerfile: api.Dockerfile.template\n" +
+			"  db:\n    build: ./db\n",
+		"web/Dockerfile.template":     "FROM balenalib/%%BALENA_MACHINE_NAME%%-node\nRUN echo %%BALENA_ARCH%% %%BALENA_MACHINE_NAME%% %%UNKNOWN%%\n",
+		"api/api.Dockerfile.template": "FROM balenalib/%%RESIN_ARCH%%-alpine\n",
+		"db/Dockerfile":               "FROM postgres\n",
+		"db/Dockerfile.template":      "FROM balenalib/%%BALENA_ARCH%%-postgres\n",
+	})
+	parse := func(deviceType string) map[string]Build {
+		result, err := New(Options{ProjectName: "test", Builds: true, DeviceType: deviceType}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Builds
+	}
+
+	builds := parse("")
+	// The template is used in place of the missing default Dockerfile
+	web := builds["web"]
+	if web.Dockerfile != "Dockerfile.template" || web.Template == nil || !reflect.DeepEqual(web.Template.Variables, []string{"BALENA_MACHINE_NAME", "BALENA_ARCH", "UNKNOWN"}) || web.Template.Rendered != "" {
+		t.Errorf("expected the variables of the template in order of first reference, got %+v", web.Template)
+	}
+	if api := builds["api"]; api.Template == nil || !reflect.DeepEqual(api.Template.Variables, []string{"RESIN_ARCH"}) {
+		t.Errorf("expected the variables of the named template, got %+v", api.Template)
+	}
+	if db := builds["db"]; db.Dockerfile != "Dockerfile" || db.Template != nil {
+		t.Errorf("expected the Dockerfile to be preferred to the template, got %+v", db)
+	}
+
+	builds = parse("raspberrypi4-64")
+	if rendered := builds["web"].Template.Rendered; rendered != "FROM balenalib/raspberrypi4-64-node\nRUN echo aarch64 raspberrypi4-64 %%UNKNOWN%%\n" {
+		t.Errorf("expected the template to be rendered for the device type, leaving unknown variables, got %q", rendered)
+	}
+	if rendered := builds["api"].Template.Rendered; rendered != "FROM balenalib/aarch64-alpine\n" {
+		t.Errorf("expected the legacy variables to be rendered, got %q", rendered)
+	}
+}
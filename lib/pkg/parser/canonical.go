@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Service fields whose values are sets, i.e. where element order carries no meaning
+// and can be sorted without changing the semantics of the composition.
+// Lists where order matters (command, entrypoint, cache_from, dns and dns_search, whose resolvers and search
+// domains are tried in order, ...) are left untouched.
+var serviceSetFields = []string{
+	"cap_add",
+	"cap_drop",
+	"device_cgroup_rules",
+	"expose",
+	"group_add",
+	"profiles",
+	"security_opt",
+	"tmpfs",
+	"volumes_from",
+}
+
+// Build fields whose values are sets
+var buildSetFields = []string{
+	"platforms",
+	"tags",
+}
+
+// MarshalProject serializes the project as JSON, in its canonical form if canonical. The name is removed if
+// empty, e.g. once normalized for balena, as compose-go includes it regardless.
+func MarshalProject(project *types.Project, canonical bool) ([]byte, error) {
+	projectJSON, err := project.MarshalJSON()
+	if err == nil && project.Name == "" {
+		var fields map[string]json.RawMessage
+		if err = json.Unmarshal(projectJSON, &fields); err == nil {
+			delete(fields, "name")
+			projectJSON, err = json.MarshalIndent(fields, "", "  ")
+		}
+	}
+	if err != nil || !canonical {
+		return projectJSON, err
+	}
+	return CanonicalJSON(projectJSON)
+}
+
+// CanonicalJSON converts the project JSON into its canonical form, which is byte-identical
+// for equivalent projects: object keys are sorted, set-like arrays are sorted,
+// and no insignificant whitespace is emitted.
+func CanonicalJSON(projectJSON []byte) ([]byte, error) {
+	value, err := canonicalValue(projectJSON)
+	if err != nil {
+		return nil, err
+	}
+	return encodeCanonical(value)
+}
+
+// Decode the project JSON into generic values with set-like arrays sorted.
+// Maps are sorted by key once re-encoded, so no further ordering is required.
+func canonicalValue(projectJSON []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(projectJSON))
+	// Preserve numbers exactly as compose-go emitted them
+	decoder.UseNumber()
+	var value map[string]any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	services, _ := value["services"].(map[string]any)
+	for _, service := range services {
+		service, ok := service.(map[string]any)
+		if !ok {
+			continue
+		}
+		if err := sortSetFields(service, serviceSetFields); err != nil {
+			return nil, err
+		}
+		if build, ok := service["build"].(map[string]any); ok {
+			if err := sortSetFields(build, buildSetFields); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return value, nil
+}
+
+// Sort the array values of the given fields by their JSON encoding
+func sortSetFields(obj map[string]any, fields []string) error {
+	type entry struct {
+		encoded string
+		value   any
+	}
+
+	for _, field := range fields {
+		values, ok := obj[field].([]any)
+		if !ok {
+			continue
+		}
+		entries := make([]entry, len(values))
+		for i, v := range values {
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s: %w", field, err)
+			}
+			entries[i] = entry{encoded: string(encoded), value: v}
+		}
+		slices.SortStableFunc(entries, func(a, b entry) int {
+			return strings.Compare(a.encoded, b.encoded)
+		})
+		for i, e := range entries {
+			values[i] = e.value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "sorts keys and removes whitespace",
+			input:    `{"services": {"b": {"image": "b"}, "a": {"tty": true, "image": "a"}}, "name": "p"}`,
+			expected: `{"name":"p","services":{"a":{"image":"a","tty":true},"b":{"image":"b"}}}`,
+		},
+		{
+			name:     "sorts set fields",
+			input:    `{"services": {"a": {"cap_add": ["SYS_ADMIN", "NET_ADMIN"], "expose": ["90", "8080"], "build": {"platforms": ["linux/arm64", "linux/amd64"], "tags": ["b", "a"]}}}}`,
+			expected: `{"services":{"a":{"build":{"platforms":["linux/amd64","linux/arm64"],"tags":["a","b"]},"cap_add":["NET_ADMIN","SYS_ADMIN"],"expose":["8080","90"]}}}`,
+		},
+		{
+			name:     "sorts set fields of objects by their encoding",
+			input:    `{"services": {"a": {"tmpfs": [{"target": "/b"}, {"target": "/a"}]}}}`,
+			expected: `{"services":{"a":{"tmpfs":[{"target":"/a"},{"target":"/b"}]}}}`,
+		},
+		{
+			name:     "keeps the order of ordered lists",
+			input:    `{"services": {"a": {"command": ["sh", "-c", "b"], "dns": ["8.8.8.8", "1.1.1.1"], "dns_search": ["b.local", "a.local"], "dns_opt": ["timeout:1", "attempts:2"], "build": {"cache_from": ["b", "a"]}}}}`,
+			expected: `{"services":{"a":{"build":{"cache_from":["b","a"]},"command":["sh","-c","b"],"dns":["8.8.8.8","1.1.1.1"],"dns_opt":["timeout:1","attempts:2"],"dns_search":["b.local","a.local"]}}}`,
+		},
+		{
+			name:     "keeps numbers and HTML characters as emitted",
+			input:    `{"services": {"a": {"cpus": 1.50, "labels": {"a": "<b>&"}}}}`,
+			expected: `{"services":{"a":{"cpus":1.50,"labels":{"a":"<b>&"}}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := CanonicalJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(output) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSONInvalid(t *testing.T) {
+	if _, err := CanonicalJSON([]byte(`{"services":`)); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestMarshalProject(t *testing.T) {
+	compose := "services:\n  web:\n    image: nginx\n    cap_add: [SYS_ADMIN, NET_ADMIN]\n"
+	tests := []struct {
+		name     string
+		options  Options
+		expected any
+	}{
+		{name: "named", expected: "test"},
+		// compose-go includes the name even once removed for balena
+		{name: "normalized for balena", options: Options{BalenaNormalize: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := mustParse(t, tt.options, compose).Project
+			for _, canonical := range []bool{false, true} {
+				output, err := MarshalProject(project, canonical)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var fields map[string]any
+				if err := json.Unmarshal(output, &fields); err != nil {
+					t.Fatal(err)
+				}
+				if fields["name"] != tt.expected || fields["services"] == nil {
+					t.Errorf("expected the project named %v, got %s", tt.expected, output)
+				}
+				if canonical != strings.Contains(string(output), `"cap_add":["NET_ADMIN","SYS_ADMIN"]`) {
+					t.Errorf("expected canonical JSON only if canonical, got %s", output)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Codes of the issues with the capabilities and devices of services, which balenaOS would fail to start
+// the service with
+const (
+	// UnknownCapabilityCode is reported for cap_add and cap_drop entries which aren't Linux capabilities
+	UnknownCapabilityCode = "unknown-capability"
+	// PrivilegedCapabilityCode is reported for cap_add entries which the balenaOS version set with
+	// Options.OSVersion only grants privileged services, or as a warning if it's unset
+	PrivilegedCapabilityCode = "privileged-capability"
+	// InvalidDeviceCode is reported for devices which aren't device nodes or have invalid permissions,
+	// and for invalid device_cgroup_rules
+	InvalidDeviceCode = "invalid-device"
+)
+
+// Capabilities the engine of balenaOS can add, without the CAP_ prefix, which may be written in any case
+var linuxCapabilities = []string{
+	"ALL", "AUDIT_CONTROL", "AUDIT_READ", "AUDIT_WRITE", "BLOCK_SUSPEND", "BPF", "CHECKPOINT_RESTORE",
+	"CHOWN", "DAC_OVERRIDE", "DAC_READ_SEARCH", "FOWNER", "FSETID", "IPC_LOCK", "IPC_OWNER", "KILL", "LEASE",
+	"LINUX_IMMUTABLE", "MAC_ADMIN", "MAC_OVERRIDE", "MKNOD", "NET_ADMIN", "NET_BIND_SERVICE", "NET_BROADCAST",
+	"NET_RAW", "PERFMON", "SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_ADMIN", "SYS_BOOT", "SYS_CHROOT",
+	"SYS_MODULE", "SYS_NICE", "SYS_PACCT", "SYS_PTRACE", "SYS_RAWIO", "SYS_RESOURCE", "SYS_TIME",
+	"SYS_TTY_CONFIG", "SYSLOG", "WAKE_ALARM",
+}
+
+// The first balenaOS version which can add each capability to services which aren't privileged. Older
+// versions only grant them with privileged, as their engine and kernels don't support adding them.
+var privilegedCapabilities = map[string]string{
+	"SYS_RAWIO":          "2.88.0",
+	"BPF":                "2.98.0",
+	"PERFMON":            "2.98.0",
+	"CHECKPOINT_RESTORE": "2.98.0",
+}
+
+// A device cgroup rule, e.g. "c 189:* rmw", of a device type, major and minor numbers and permissions
+var deviceCgroupRulePattern = regexp.MustCompile(`^[abc] (\*|\d+):(\*|\d+) [rwm]{1,3}$`)
+
+// The issues of the capabilities, devices and device cgroup rules of a service as output
+func capabilityIssues(path string, service map[string]any, osVersion string) []targetIssue {
+	var issues []targetIssue
+	fail := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...), warning: true})
+	}
+
+	version, _ := parseVersion(osVersion)
+	for _, field := range []string{"cap_add", "cap_drop"} {
+		for i, capability := range array(service[field]) {
+			capabilityPath := fmt.Sprintf("%s.%s.%d", path, field, i)
+			name := strings.TrimPrefix(strings.ToUpper(text(capability)), "CAP_")
+			if !slices.Contains(linuxCapabilities, name) {
+				fail(UnknownCapabilityCode, capabilityPath, "%s.%s: %s isn't a Linux capability", path, field, text(capability))
+				continue
+			}
+			minimum, ok := privilegedCapabilities[name]
+			if field != "cap_add" || !ok || service["privileged"] == true {
+				continue
+			}
+			required, _ := parseVersion(minimum)
+			switch {
+			case osVersion == "":
+				warn(PrivilegedCapabilityCode, capabilityPath, "%s.cap_add: %s requires privileged on balenaOS before v%s", path, name, minimum)
+			case compareVersions(version, required) < 0:
+				fail(PrivilegedCapabilityCode, capabilityPath, "%s.cap_add: %s requires privileged on balenaOS before v%s, targeting %s", path, name, minimum, osVersion)
+			}
+		}
+	}
+
+	for i, device := range array(service["devices"]) {
+		device := object(device)
+		devicePath := fmt.Sprintf("%s.devices.%d", path, i)
+		source, permissions := text(device["source"]), text(device["permissions"])
+		// Devices using CDI names are reported as unsupported
+		if !strings.HasPrefix(source, "/") {
+			continue
+		}
+		if !strings.HasPrefix(source, "/dev/") {
+			fail(InvalidDeviceCode, devicePath, "%s.devices: %s isn't a device node under /dev", path, source)
+		}
+		if permissions != "" && !validDevicePermissions(permissions) {
+			fail(InvalidDeviceCode, devicePath, "%s.devices: permissions of %s must combine r, w and m, got %q", path, source, permissions)
+		}
+	}
+	for i, rule := range array(service["device_cgroup_rules"]) {
+		rule := text(rule)
+		if !deviceCgroupRulePattern.MatchString(rule) || !validDevicePermissions(rule[strings.LastIndex(rule, " ")+1:]) {
+			fail(InvalidDeviceCode, fmt.Sprintf("%s.device_cgroup_rules.%d", path, i),
+				"%s.device_cgroup_rules: %q must be a device type a, b or c, major and minor numbers or *, and permissions, e.g. \"c 189:* rwm\"", path, rule)
+		}
+	}
+	return issues
+}
+
+// Whether device permissions combine r, w and m, each at most once
+func validDevicePermissions(permissions string) bool {
+	if permissions == "" {
+		return false
+	}
+	for i, permission := range permissions {
+		if !strings.ContainsRune("rwm", permission) || strings.ContainsRune(permissions[i+1:], permission) {
+			return false
+		}
+	}
+	return true
+}
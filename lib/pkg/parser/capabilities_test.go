@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		compose  string
+		errors   []string
+		warnings []string
+	}{
+		{
+			name:    "known capabilities",
+			compose: "services:\n  web:\n    image: nginx\n    cap_add: [NET_ADMIN, cap_sys_time]\n    cap_drop: [ALL]\n",
+		},
+		{
+			name:    "unknown capabilities",
+			compose: "services:\n  web:\n    image: nginx\n    cap_add: [NET_ADMIN, SYS_MAGIC]\n    cap_drop: [CAP_FLY]\n",
+			errors:  []string{"unknown-capability services.web.cap_add.1", "unknown-capability services.web.cap_drop.0"},
+		},
+		{
+			// Without a balenaOS version, capabilities which older versions only grant privileged services are warned about
+			name:     "privileged capabilities",
+			compose:  "services:\n  web:\n    image: nginx\n    cap_add: [SYS_RAWIO, BPF]\n    cap_drop: [SYS_RAWIO]\n  agent:\n    image: alpine\n    privileged: true\n    cap_add: [SYS_RAWIO]\n",
+			warnings: []string{"privileged-capability services.web.cap_add.0", "privileged-capability services.web.cap_add.1"},
+		},
+		{
+			name:    "privileged capabilities on an older balenaOS",
+			options: Options{OSVersion: "2.95.0"},
+			compose: "services:\n  web:\n    image: nginx\n    cap_add: [SYS_RAWIO, BPF]\n",
+			errors:  []string{"privileged-capability services.web.cap_add.1"},
+		},
+		{
+			name:    "privileged capabilities on a newer balenaOS",
+			options: Options{OSVersion: "v2.113.18+rev1"},
+			compose: "services:\n  web:\n    image: nginx\n    cap_add: [SYS_RAWIO, BPF]\n",
+		},
+		{
+			name:    "devices",
+			compose: "services:\n  web:\n    image: nginx\n    devices: [\"/dev/ttyUSB0:/dev/ttyUSB0:rw\", \"/sys/class/gpio:/gpio\", \"/dev/i2c-1:/dev/i2c-1:rwx\"]\n    device_cgroup_rules: [\"c 189:* rmw\", \"a *:* rwm\", \"c 189 rw\", \"b 8:0 rr\"]\n",
+			errors:  []string{"invalid-device services.web.devices.1", "invalid-device services.web.devices.2", "invalid-device services.web.device_cgroup_rules.2", "invalid-device services.web.device_cgroup_rules.3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.Target = BalenaTarget
+			errs, warnings := targetIssues(t, tt.options, tt.compose)
+			if !reflect.DeepEqual(errs, tt.errors) {
+				t.Errorf("expected the errors %v, got %v", tt.errors, errs)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("expected the warnings %v, got %v", tt.warnings, warnings)
+			}
+		})
+	}
+}
+
+func TestValidDevicePermissions(t *testing.T) {
+	for permissions, expected := range map[string]bool{"rwm": true, "r": true, "mw": true, "": false, "rr": false, "rwx": false} {
+		if valid := validDevicePermissions(permissions); valid != expected {
+			t.Errorf("expected %q to be valid %t, got %t", permissions, expected, valid)
+		}
+	}
+}
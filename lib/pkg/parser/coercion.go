@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Unquoted strings YAML 1.1 parsers, e.g. docker-compose v1, read as booleans, while the YAML 1.2 parser
+// of compose-go keeps them as written
+var yaml11BooleanPattern = regexp.MustCompile(`^(y|Y|yes|Yes|YES|n|N|no|No|NO|on|On|ON|off|Off|OFF)$`)
+
+// Fields of services whose values are strings, which YAML coerces unquoted numbers and booleans into
+var stringMappings = [][]string{
+	{"environment"},
+	{"labels"},
+	{"annotations"},
+	{"build", "args"},
+	{"build", "labels"},
+	{"deploy", "labels"},
+}
+
+// Find the unquoted values of the string mappings of services, e.g. environment, which YAML coerces into
+// another string than written, e.g. 0755 into 493 or 1.10 into 1.1, or which YAML 1.1 parsers read as
+// booleans, e.g. yes, each with the original literal and the coerced value. Values are located directly,
+// as the names of labels contain dots.
+func coercedValueWarnings(content []byte) []Warning {
+	var warnings []Warning
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			return warnings
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+		_, services := mappingEntry(document.Content[0], "services")
+		if services == nil || services.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(services.Content); i += 2 {
+			name, service := services.Content[i].Value, services.Content[i+1]
+			for _, field := range stringMappings {
+				mapping := service
+				for _, key := range field {
+					_, mapping = mappingEntry(mapping, key)
+				}
+				if mapping == nil || mapping.Kind != yaml.MappingNode {
+					continue
+				}
+				path := "services." + name
+				for _, key := range field {
+					path += "." + key
+				}
+				for j := 0; j+1 < len(mapping.Content); j += 2 {
+					if warning, ok := coercedValueWarning(path+"."+mapping.Content[j].Value, mapping.Content[j+1]); ok {
+						warnings = append(warnings, warning)
+					}
+				}
+			}
+		}
+	}
+}
+
+// The warning of the value of a string mapping entry, if it's an unquoted scalar which YAML coerces
+func coercedValueWarning(path string, node *yaml.Node) (Warning, bool) {
+	if node.Kind != yaml.ScalarNode || node.Style != 0 {
+		return Warning{}, false
+	}
+	literal := node.Value
+	location := &Location{Path: path, Line: node.Line, Column: node.Column}
+	warning := Warning{Code: CoercedValueWarning, Location: location, Replacement: strconv.Quote(literal)}
+	var value any
+	if node.Decode(&value) != nil {
+		return Warning{}, false
+	}
+	coerced, kind := fmt.Sprint(value), "number"
+	switch value := value.(type) {
+	case nil:
+		// Unset values are taken from the environment, as intended
+		return Warning{}, false
+	case string:
+		if !yaml11BooleanPattern.MatchString(literal) {
+			return Warning{}, false
+		}
+		boolean := strings.ContainsAny(literal[:1], "yYoO") && !strings.EqualFold(literal, "off")
+		warning.Message = fmt.Sprintf("%s: %s is kept as the string %s, but read as the boolean %t by YAML 1.1 parsers, e.g. docker-compose v1; quote it as %q",
+			path, literal, literal, boolean, literal)
+		return warning, true
+	case bool:
+		kind = "boolean"
+	case float64:
+		coerced = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	if coerced == literal {
+		return Warning{}, false
+	}
+	warning.Message = fmt.Sprintf("%s: %s is read by YAML as the %s %s, which is set instead; quote it as %q to keep it", path, literal, kind, coerced, literal)
+	return warning, true
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCoercedValueWarnings(t *testing.T) {
+	result := mustParse(t, Options{Warnings: true}, "services:\n"+
+		"  web:\n    image: nginx\n"+
+		"    environment:\n      MODE: 0755\n      VERSION: 1.10\n      DEBUG: yes\n      ENABLED: True\n      QUOTED: \"0755\"\n      COUNT: 10\n      FLAG: true\n      UNSET:\n      NAME: web\n"+
+		"    labels:\n      io.balena.features.dbus: on\n"+
+		"    build:\n      context: .\n      args:\n        RATIO: 1e3\n")
+	expected := []struct {
+		message     string
+		replacement string
+		path        string
+		line        int
+	}{
+		{message: `services.web.environment.MODE: 0755 is read by YAML as the number 493, which is set instead; quote it as "0755" to keep it`, replacement: `"0755"`, path: "services.web.environment.MODE", line: 5},
+		{message: `services.web.environment.VERSION: 1.10 is read by YAML as the number 1.1, which is set instead; quote it as "1.10" to keep it`, replacement: `"1.10"`, path: "services.web.environment.VERSION", line: 6},
+		{message: `services.web.environment.DEBUG: yes is kept as the string yes, but read as the boolean true by YAML 1.1 parsers, e.g. docker-compose v1; quote it as "yes"`, replacement: `"yes"`, path: "services.web.environment.DEBUG", line: 7},
+		{message: `services.web.environment.ENABLED: True is read by YAML as the boolean true, which is set instead; quote it as "True" to keep it`, replacement: `"True"`, path: "services.web.environment.ENABLED", line: 8},
+		{message: `services.web.labels.io.balena.features.dbus: on is kept as the string on, but read as the boolean true by YAML 1.1 parsers, e.g. docker-compose v1; quote it as "on"`, replacement: `"on"`, path: "services.web.labels.io.balena.features.dbus", line: 15},
+		{message: `services.web.build.args.RATIO: 1e3 is read by YAML as the number 1000, which is set instead; quote it as "1e3" to keep it`, replacement: `"1e3"`, path: "services.web.build.args.RATIO", line: 19},
+	}
+	var warnings []Warning
+	for _, warning := range result.Warnings {
+		if warning.Code == CoercedValueWarning {
+			warnings = append(warnings, warning)
+		}
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d coerced value warnings, got %+v", len(expected), warnings)
+	}
+	for i, warning := range warnings {
+		if warning.Message != expected[i].message || warning.Replacement != expected[i].replacement {
+			t.Errorf("expected %q replaced by %s, got %q replaced by %s", expected[i].message, expected[i].replacement, warning.Message, warning.Replacement)
+		}
+		if location := warning.Location; location == nil || location.File == "" || location.Path != expected[i].path || location.Line != expected[i].line {
+			t.Errorf("expected warning %d to be located at line %d %s, got %+v", i, expected[i].line, expected[i].path, location)
+		}
+	}
+}
+
+func TestCoercedBooleans(t *testing.T) {
+	for literal, expected := range map[string]bool{"y": true, "Yes": true, "ON": true, "n": false, "No": false, "off": false, "OFF": false} {
+		warnings := coercedValueWarnings([]byte("services:\n  web:\n    environment:\n      A: " + literal + "\n"))
+		if len(warnings) != 1 {
+			t.Fatalf("expected a warning for %s, got %+v", literal, warnings)
+		}
+		if message := warnings[0].Message; !strings.Contains(message, fmt.Sprintf("read as the boolean %t", expected)) {
+			t.Errorf("expected %s to be read as %t, got %q", literal, expected, message)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Service fields of the compose spec which the 2.1 format the supervisor consumes doesn't have, as they
+// were added by later formats. Fields balena doesn't support at all are rejected by BalenaTarget.
+var compose21UnsupportedFields = []string{
+	"annotations", "attach", "cgroup", "cpu_rt_period", "cpu_rt_runtime", "device_cgroup_rules", "models",
+	"post_start", "pre_stop", "provider", "use_api_socket", "uts",
+}
+
+// Healthcheck fields added after the 2.1 format
+var compose21UnsupportedHealthcheckFields = []string{"start_period", "start_interval"}
+
+// Options of the networks of services which the 2.1 format has
+var compose21NetworkOptions = []string{"aliases", "ipv4_address", "ipv6_address", "link_local_ips"}
+
+// Top-level network fields added after the 2.1 format
+var compose21UnsupportedNetworkFields = []string{"attachable", "name"}
+
+// CPU period the engine divides cpu_quota by, so translating cpus into a quota
+const compose21CPUPeriod = 100000
+
+// ToCompose21 lowers a project into the composition in the 2.1 format the supervisor consumes, with the
+// short syntaxes of the target state. cpus is translated into cpu_quota, while fields without a 2.1
+// equivalent fail with a ValidationError listing each, located in the compose files. The project should be validated with BalenaTarget and normalized for balena, as
+// for ToTargetState.
+func ToCompose21(project *types.Project, composeFiles []string) (map[string]any, error) {
+	state, err := ToTargetState(project)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []targetIssue
+	reject := func(path, message string, args ...any) {
+		issues = append(issues, targetIssue{code: UnsupportedFieldCode, path: path, message: fmt.Sprintf(message, args...)})
+	}
+	services := map[string]any{}
+	for _, name := range slices.Sorted(maps.Keys(state.Services)) {
+		service, composition := project.Services[name], state.Services[name].Composition
+		path := "services." + name
+		for _, field := range compose21UnsupportedFields {
+			if _, ok := composition[field]; ok {
+				reject(path+"."+field, "%s.%s has no equivalent in the 2.1 format", path, field)
+			}
+		}
+		for _, field := range compose21UnsupportedHealthcheckFields {
+			if _, ok := object(composition["healthcheck"])[field]; ok {
+				reject(path+".healthcheck."+field, "%s.healthcheck.%s has no equivalent in the 2.1 format", path, field)
+			}
+		}
+		networks := object(composition["networks"])
+		for _, network := range sortedKeys(networks) {
+			for _, option := range sortedKeys(object(networks[network])) {
+				if !slices.Contains(compose21NetworkOptions, option) {
+					reject(path+".networks."+network+"."+option, "%s.networks.%s.%s has no equivalent in the 2.1 format", path, network, option)
+				}
+			}
+		}
+
+		if service.CPUS != 0 {
+			if service.CPUQuota != 0 {
+				reject(path+".cpus", "%s.cpus can't be translated into cpu_quota, which is also set", path)
+			}
+			delete(composition, "cpus")
+			composition["cpu_quota"] = int64(math.Round(float64(service.CPUS) * compose21CPUPeriod))
+		}
+
+		// The 2.1 format has the service_started and service_healthy conditions
+		dependencies := object(composition["depends_on"])
+		for _, dependency := range sortedKeys(dependencies) {
+			if condition := object(dependencies[dependency])["condition"]; condition == types.ServiceConditionCompletedSuccessfully {
+				reject(path+".depends_on."+dependency, "%s.depends_on.%s condition %s has no equivalent in the 2.1 format", path, dependency, condition)
+			}
+		}
+		services[name] = composition
+	}
+	for _, name := range slices.Sorted(maps.Keys(state.Networks)) {
+		for _, field := range compose21UnsupportedNetworkFields {
+			if _, ok := state.Networks[name][field]; ok {
+				reject("networks."+name+"."+field, "networks.%s.%s has no equivalent in the 2.1 format", name, field)
+			}
+		}
+	}
+
+	if err, _ := reportIssues("Project can't be converted to the 2.1 format", issues, composeFiles); err != nil {
+		return nil, err
+	}
+	return map[string]any{"version": "2.1", "services": services, "networks": state.Networks, "volumes": state.Volumes}, nil
+}
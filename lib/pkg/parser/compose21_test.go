@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToCompose21(t *testing.T) {
+	project := mustParse(t, Options{BalenaNormalize: true}, "services:\n"+
+		"  web:\n    image: nginx\n    cpus: 1.5\n    depends_on:\n      db:\n        condition: service_healthy\n"+
+		"  db:\n    image: postgres\n    networks:\n      backend:\n        aliases: [database]\n"+
+		"networks:\n  backend: {}\n",
+	).Project
+	composition, err := ToCompose21(project, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if composition["version"] != "2.1" {
+		t.Errorf("expected the 2.1 format, got %v", composition["version"])
+	}
+	web := object(object(composition["services"])["web"])
+	// cpus is translated into a quota of the CPU period
+	if _, ok := web["cpus"]; ok || web["cpu_quota"] != int64(150000) {
+		t.Errorf("expected cpus to be translated into cpu_quota, got %v", web)
+	}
+	if !reflect.DeepEqual(web["depends_on"], map[string]any{"db": map[string]any{"condition": "service_healthy"}}) {
+		t.Errorf("expected the service_healthy condition to be kept, got %v", web["depends_on"])
+	}
+	if db := object(object(composition["services"])["db"]); !reflect.DeepEqual(db["networks"], map[string]any{"backend": map[string]any{"aliases": []any{"database"}}}) {
+		t.Errorf("expected the network aliases to be kept, got %v", db["networks"])
+	}
+}
+
+func TestToCompose21Errors(t *testing.T) {
+	project := mustParse(t, Options{BalenaNormalize: true}, "services:\n"+
+		"  web:\n    image: nginx\n    cpus: 1\n    cpu_quota: 50000\n    uts: host\n    healthcheck:\n      test: [CMD, \"true\"]\n      start_period: 10s\n"+
+		"    depends_on:\n      init:\n        condition: service_completed_successfully\n      db:\n        condition: service_started\n"+
+		"    networks:\n      backend:\n        priority: 10\n        mac_address: 02:42:ac:11:00:02\n"+
+		"  init:\n    image: alpine\n"+
+		"  db:\n    image: postgres\n"+
+		"networks:\n  frontend:\n    attachable: true\n  backend:\n    name: my-backend\n",
+	).Project
+	_, err := ToCompose21(project, nil)
+	parserErr := expectError(t, err, ValidationError, "Project can't be converted to the 2.1 format")
+	var paths []string
+	for _, e := range parserErr.Errors {
+		if e.Code != UnsupportedFieldCode {
+			t.Errorf("expected the %s code, got %s", UnsupportedFieldCode, e.Code)
+		}
+		paths = append(paths, e.Location.Path)
+	}
+	// Issues are listed in a stable order
+	expected := []string{
+		"services.web.uts",
+		"services.web.healthcheck.start_period",
+		"services.web.networks.backend.mac_address",
+		"services.web.networks.backend.priority",
+		"services.web.cpus",
+		"services.web.depends_on.init",
+		"networks.backend.name",
+		"networks.frontend.attachable",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ExternalPathCode is reported for paths of in-memory compose files which would read files outside of the
+// project directory, e.g. env_file: /etc/hostname, or which interpolate variables and so can't be checked
+const ExternalPathCode = "external-path"
+
+// Check the paths of in-memory compose files which compose-go reads files from, or which are sent to the
+// builder, i.e. env_file, label_file, include, extends.file and build contexts, are relative paths within
+// the project directory, returning a ValidationError listing each which isn't. Paths are checked as
+// written, so those interpolating variables are rejected, as they could resolve anywhere. Every document
+// of a file is checked, as compose-go merges them, and files which can't be decoded fail with a
+// ValidationError, as their paths can't be checked.
+func checkContentPaths(files []File, composeFiles []string) *Error {
+	var issues []targetIssue
+	check := func(path string, value any) {
+		p, ok := value.(string)
+		if !ok {
+			return
+		}
+		var reason string
+		switch {
+		case strings.Contains(p, "$"):
+			reason = "can't interpolate variables"
+		case filepath.IsAbs(p) || strings.HasPrefix(p, "~"):
+			reason = "must be relative to the project directory"
+		case isEscapingPath(p):
+			reason = "can't be outside of the project directory"
+		default:
+			return
+		}
+		issues = append(issues, targetIssue{code: ExternalPathCode, path: path, message: fmt.Sprintf("%s: %s %s", path, p, reason)})
+	}
+	// Paths of a string, a list of strings, or a list of mappings with their path in pathKey
+	checkList := func(path string, value any, pathKey string) {
+		if _, ok := value.(string); ok {
+			check(path, value)
+			return
+		}
+		for i, entry := range array(value) {
+			entryPath := fmt.Sprintf("%s.%d", path, i)
+			if m := object(entry); m != nil {
+				check(entryPath+"."+pathKey, m[pathKey])
+			} else {
+				check(entryPath, entry)
+			}
+		}
+	}
+	checkDocument := func(document map[string]any) {
+		for i, include := range array(document["include"]) {
+			path := fmt.Sprintf("include.%d", i)
+			if m := object(include); m != nil {
+				checkList(path+".path", m["path"], "")
+				checkList(path+".env_file", m["env_file"], "")
+				check(path+".project_directory", m["project_directory"])
+			} else {
+				check(path, include)
+			}
+		}
+		services := object(document["services"])
+		for _, name := range sortedKeys(services) {
+			service := object(services[name])
+			path := "services." + name
+			checkList(path+".env_file", service["env_file"], "path")
+			checkList(path+".label_file", service["label_file"], "")
+			check(path+".extends.file", object(service["extends"])["file"])
+			if build := object(service["build"]); build != nil {
+				check(path+".build.context", build["context"])
+			} else {
+				check(path+".build", service["build"])
+			}
+		}
+	}
+	for i, file := range files {
+		decoder := yaml.NewDecoder(bytes.NewReader(file.Content))
+		for {
+			var document map[string]any
+			err := decoder.Decode(&document)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				location := cmp.Or(locate(err.Error(), nil), &Location{})
+				location.File = composeFiles[i]
+				return &Error{Name: ValidationError, Code: ExternalPathCode, Message: fmt.Sprintf(
+					"Failed to parse compose file: the paths of %s can't be checked: %v", file.Name, err), Location: location, Err: err}
+			}
+			checkDocument(document)
+		}
+	}
+	err, _ := reportIssues("Failed to parse compose file", issues, composeFiles)
+	return err
+}
+
+// Whether a relative path leads outside of the directory it's relative to
+func isEscapingPath(path string) bool {
+	path = filepath.Clean(path)
+	return path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseContentPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		path    string
+		message string
+	}{
+		{
+			name:    "absolute env file",
+			content: "services:\n  web:\n    image: nginx\n    env_file: /etc/hostname\n",
+			path:    "services.web.env_file",
+			message: "/etc/hostname must be relative to the project directory",
+		},
+		{
+			name:    "env file in the home directory",
+			content: "services:\n  web:\n    image: nginx\n    env_file:\n      - path: ~/.env\n",
+			path:    "services.web.env_file.0.path",
+			message: "~/.env must be relative to the project directory",
+		},
+		{
+			name:    "escaping label file",
+			content: "services:\n  web:\n    image: nginx\n    label_file: [labels, ../labels]\n",
+			path:    "services.web.label_file.1",
+			message: "../labels can't be outside of the project directory",
+		},
+		{
+			name:    "interpolated build context",
+			content: "services:\n  web:\n    build:\n      context: ${CONTEXT}\n",
+			path:    "services.web.build.context",
+			message: "${CONTEXT} can't interpolate variables",
+		},
+		{
+			name:    "escaping build",
+			content: "services:\n  web:\n    build: a/../..\n",
+			path:    "services.web.build",
+			message: "a/../.. can't be outside of the project directory",
+		},
+		{
+			name:    "extended file",
+			content: "services:\n  web:\n    extends:\n      file: /srv/compose.yml\n      service: web\n",
+			path:    "services.web.extends.file",
+			message: "/srv/compose.yml must be relative to the project directory",
+		},
+		{
+			name:    "include",
+			content: "include:\n  - ../compose.yml\nservices: {}\n",
+			path:    "include.0",
+			message: "../compose.yml can't be outside of the project directory",
+		},
+		{
+			// compose-go merges every document of a file
+			name:    "second document",
+			content: "services:\n  web:\n    image: nginx\n---\nservices:\n  x:\n    image: a\n    env_file: /etc/os-release\n",
+			path:    "services.x.env_file",
+			message: "/etc/os-release must be relative to the project directory",
+		},
+		{
+			name:    "include project directory",
+			content: "include:\n  - path: compose.yml\n    project_directory: /\nservices: {}\n",
+			path:    "include.0.project_directory",
+			message: "/ must be relative to the project directory",
+		},
+	}
+	p := New(Options{ProjectName: "test"})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.ParseContent(context.Background(), []File{{Name: "compose.yml", Content: []byte(tt.content)}})
+			parserErr := expectError(t, err, ValidationError, tt.message)
+			if parserErr.Code != ExternalPathCode {
+				t.Errorf("expected the code %s, got %q", ExternalPathCode, parserErr.Code)
+			}
+			if parserErr.Location == nil || parserErr.Location.Path != tt.path || parserErr.Location.File != "compose.yml" {
+				t.Errorf("expected the location %s of compose.yml, got %+v", tt.path, parserErr.Location)
+			}
+		})
+	}
+}
+
+func TestParseContentInvalidPaths(t *testing.T) {
+	// Files whose paths can't be checked are rejected, even if compose-go would load their first documents
+	_, err := New(Options{ProjectName: "test"}).ParseContent(context.Background(), []File{
+		{Name: "compose.yml", Content: []byte("services:\n  web:\n    image: nginx\n---\nservices: [\n")},
+	})
+	parserErr := expectError(t, err, ValidationError, "Failed to parse compose file: the paths of compose.yml can't be checked")
+	if parserErr.Code != ExternalPathCode || parserErr.Location == nil || parserErr.Location.File != "compose.yml" || parserErr.Location.Line == 0 {
+		t.Errorf("expected an external path error located in compose.yml, got %s at %+v", parserErr.Code, parserErr.Location)
+	}
+}
+
+func TestParseContentRelativePaths(t *testing.T) {
+	result, err := New(Options{ProjectName: "test"}).ParseContent(context.Background(), []File{
+		{Name: "compose.yml", Content: []byte("services:\n  web:\n    build:\n      context: ./app/../web\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if web := result.Project.Services["web"]; web.Build == nil {
+		t.Errorf("expected the build to be kept, got %+v", web)
+	}
+}
+
+func TestIsEscapingPath(t *testing.T) {
+	for path, expected := range map[string]bool{
+		".":          false,
+		"app":        false,
+		"./app/../b": false,
+		"..":         true,
+		"../app":     true,
+		"app/../..":  true,
+		"..app":      false,
+	} {
+		if escaping := isEscapingPath(path); escaping != expected {
+			t.Errorf("expected isEscapingPath(%q) to be %t", path, expected)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.yaml.in/yaml/v3"
+)
+
+// Codes of the issues found with balena.yml contracts
+const (
+	// InvalidContractCode is reported for contracts missing or setting an invalid type, requirement or
+	// device type, located in the contract
+	InvalidContractCode = "invalid-contract"
+	// ContractConflictCode is reported for fields of the project which conflict with the requirements of
+	// the contract, e.g. a platform for another architecture, located in the compose files
+	ContractConflictCode = "contract-conflict"
+)
+
+// Types of contracts which describe a fleet or block release
+var contractTypes = []string{"sw.application", "sw.block"}
+
+// Requirement types balena resolves, and whether they require a slug or a version
+var requirementTypes = map[string]struct{ slug, version bool }{
+	"arch.sw":        {slug: true},
+	"hw.device-type": {slug: true},
+	"sw.os":          {version: true},
+	"sw.supervisor":  {version: true},
+	"sw.l4t":         {version: true},
+}
+
+// A semver range, as space separated comparators, optionally joined with ||
+var versionRangePattern = regexp.MustCompile(`^(\*|(<|<=|>|>=|=|\^|~)?v?(\d+|x|\*)(\.(\d+|x|\*)){0,2}(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?)$`)
+
+// Contract is the release data of a balena.yml contract, merged into the output with Options.Contract
+type Contract struct {
+	// Type is the kind of release, "sw.application" or "sw.block"
+	Type string `json:"type"`
+	// Slug identifies the fleet or block, e.g. balenalabs/browser
+	Slug string `json:"slug,omitempty"`
+	Name string `json:"name,omitempty"`
+	// Version is the version of the release
+	Version string `json:"version,omitempty"`
+	// Requires are the requirements devices must meet to run the release
+	Requires []Requirement `json:"requires,omitempty"`
+	// DefaultDeviceType and SupportedDeviceTypes are the device type slugs of data in the contract
+	DefaultDeviceType    string   `json:"defaultDeviceType,omitempty"`
+	SupportedDeviceTypes []string `json:"supportedDeviceTypes,omitempty"`
+}
+
+// Requirement is a requirement of a contract, on a slug or a version range depending on its type
+type Requirement struct {
+	Type    string `json:"type" yaml:"type"`
+	Slug    string `json:"slug,omitempty" yaml:"slug"`
+	Version string `json:"version,omitempty" yaml:"version"`
+}
+
+// Read a balena.yml contract, which may also be written as JSON
+func readContract(path string) (*Contract, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to read contract: %v", err), Err: err}
+	}
+	var document struct {
+		Type     string        `yaml:"type"`
+		Slug     string        `yaml:"slug"`
+		Name     string        `yaml:"name"`
+		Version  string        `yaml:"version"`
+		Requires []Requirement `yaml:"requires"`
+		Data     struct {
+			DefaultDeviceType    string   `yaml:"defaultDeviceType"`
+			SupportedDeviceTypes []string `yaml:"supportedDeviceTypes"`
+		} `yaml:"data"`
+	}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		location := cmp.Or(locate(err.Error(), nil), &Location{})
+		location.File, _ = filepath.Abs(path)
+		return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse contract %s: %v", path, err), Location: location, Err: err}
+	}
+	return &Contract{
+		Type:                 document.Type,
+		Slug:                 document.Slug,
+		Name:                 document.Name,
+		Version:              document.Version,
+		Requires:             document.Requires,
+		DefaultDeviceType:    document.Data.DefaultDeviceType,
+		SupportedDeviceTypes: document.Data.SupportedDeviceTypes,
+	}, nil
+}
+
+// Check a contract is valid, then that the project is consistent with its requirements, returning a
+// ValidationError listing every issue with whichever is checked first
+func checkContract(contract *Contract, path string, project *types.Project, composeFiles []string) *Error {
+	var issues []targetIssue
+	fail := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...)})
+	}
+
+	if !slices.Contains(contractTypes, contract.Type) {
+		fail(InvalidContractCode, "type", "type must be one of %s, got %q", strings.Join(contractTypes, ", "), contract.Type)
+	}
+	var arches []string
+	for i, requirement := range contract.Requires {
+		requirementPath := fmt.Sprintf("requires.%d", i)
+		kind, ok := requirementTypes[requirement.Type]
+		switch {
+		case !ok:
+			fail(InvalidContractCode, requirementPath+".type", "%s.type %q isn't a requirement balena supports", requirementPath, requirement.Type)
+		case kind.slug && requirement.Slug == "":
+			fail(InvalidContractCode, requirementPath, "%s of type %s must specify a slug", requirementPath, requirement.Type)
+		case kind.version && !validVersionRange(requirement.Version):
+			fail(InvalidContractCode, requirementPath+".version", "%s.version must be a semver range, got %q", requirementPath, requirement.Version)
+		case requirement.Type == "arch.sw" && !slices.Contains(Architectures, requirement.Slug):
+			fail(InvalidContractCode, requirementPath+".slug", "%s.slug %q isn't an architecture balena supports", requirementPath, requirement.Slug)
+		case requirement.Type == "arch.sw":
+			arches = append(arches, requirement.Slug)
+		case requirement.Type == "hw.device-type" && len(contract.SupportedDeviceTypes) > 0 && !slices.Contains(contract.SupportedDeviceTypes, requirement.Slug):
+			fail(InvalidContractCode, requirementPath+".slug", "%s.slug %q isn't one of data.supportedDeviceTypes", requirementPath, requirement.Slug)
+		}
+	}
+	if len(arches) > 1 {
+		fail(InvalidContractCode, "requires", "requires can't require more than one architecture, got %s", strings.Join(arches, ", "))
+	}
+	if contract.DefaultDeviceType != "" && len(contract.SupportedDeviceTypes) > 0 && !slices.Contains(contract.SupportedDeviceTypes, contract.DefaultDeviceType) {
+		fail(InvalidContractCode, "data.defaultDeviceType", "data.defaultDeviceType %q isn't one of data.supportedDeviceTypes", contract.DefaultDeviceType)
+	}
+	if err, _ := reportIssues("Invalid contract "+path, issues, []string{path}); err != nil {
+		return err
+	}
+
+	if len(arches) == 1 {
+		for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+			service := project.Services[name]
+			if service.Platform != "" && platformArch(service.Platform) != arches[0] {
+				fail(ContractConflictCode, "services."+name+".platform", "services.%s.platform %q isn't for the %s architecture the contract requires", name, service.Platform, arches[0])
+			}
+			if service.Build != nil && len(service.Build.Platforms) > 0 && !slices.ContainsFunc(service.Build.Platforms, func(platform string) bool {
+				return platformArch(platform) == arches[0]
+			}) {
+				fail(ContractConflictCode, "services."+name+".build.platforms", "services.%s.build.platforms doesn't include the %s architecture the contract requires", name, arches[0])
+			}
+		}
+	}
+	err, _ := reportIssues("Project is inconsistent with contract "+path, issues, composeFiles)
+	return err
+}
+
+func validVersionRange(version string) bool {
+	if strings.TrimSpace(version) == "" {
+		return false
+	}
+	for _, alternative := range strings.Split(version, "||") {
+		comparators := strings.Fields(alternative)
+		if len(comparators) == 0 {
+			return false
+		}
+		for _, comparator := range comparators {
+			if !versionRangePattern.MatchString(comparator) {
+				return false
+			}
+		}
+	}
+	return true
+}
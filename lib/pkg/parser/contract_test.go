@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContract(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx\n    platform: linux/arm64\n",
+		"balena.yml": "type: sw.application\nslug: balenalabs/browser\nname: Browser\nversion: 2.4.1\n" +
+			"requires:\n  - type: arch.sw\n    slug: aarch64\n  - type: sw.os\n    version: \">=2.88.0 <3\"\n" +
+			"data:\n  defaultDeviceType: raspberrypi4-64\n  supportedDeviceTypes: [raspberrypi4-64, jetson-nano]\n",
+		"balena.json": `{"type": "sw.block", "slug": "balenablocks/dashboard"}`,
+	})
+	parse := func(contract string) (*Result, error) {
+		return New(Options{ProjectName: "test", Contract: filepath.Join(dir, contract)}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	}
+
+	result, err := parse("balena.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Contract{
+		Type:                 "sw.application",
+		Slug:                 "balenalabs/browser",
+		Name:                 "Browser",
+		Version:              "2.4.1",
+		Requires:             []Requirement{{Type: "arch.sw", Slug: "aarch64"}, {Type: "sw.os", Version: ">=2.88.0 <3"}},
+		DefaultDeviceType:    "raspberrypi4-64",
+		SupportedDeviceTypes: []string{"raspberrypi4-64", "jetson-nano"},
+	}
+	if !reflect.DeepEqual(result.Contract, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result.Contract)
+	}
+	if result, err := parse("balena.json"); err != nil || result.Contract.Type != "sw.block" {
+		t.Errorf("expected the JSON contract, got %+v: %v", result, err)
+	}
+
+	_, err = parse("missing.yml")
+	expectError(t, err, IOError, "Failed to read contract")
+}
+
+func TestContractErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		compose  string
+		contract string
+		code     string
+		paths    []string
+	}{
+		{
+			name:     "invalid type",
+			contract: "type: sw.unknown\n",
+			code:     InvalidContractCode,
+			paths:    []string{"type"},
+		},
+		{
+			name:     "invalid requirements",
+			contract: "type: sw.application\nrequires:\n  - type: sw.unknown\n  - type: hw.device-type\n  - type: sw.os\n    version: latest\n  - type: arch.sw\n    slug: sparc\n",
+			code:     InvalidContractCode,
+			paths:    []string{"requires.0.type", "requires.1", "requires.2.version", "requires.3.slug"},
+		},
+		{
+			name:     "several architectures",
+			contract: "type: sw.application\nrequires:\n  - type: arch.sw\n    slug: aarch64\n  - type: arch.sw\n    slug: amd64\n",
+			code:     InvalidContractCode,
+			paths:    []string{"requires"},
+		},
+		{
+			name:     "unsupported device types",
+			contract: "type: sw.application\nrequires:\n  - type: hw.device-type\n    slug: fincm3\ndata:\n  defaultDeviceType: fincm3\n  supportedDeviceTypes: [raspberrypi4-64]\n",
+			code:     InvalidContractCode,
+			paths:    []string{"requires.0.slug", "data.defaultDeviceType"},
+		},
+		{
+			name:     "conflicting platforms",
+			compose:  "services:\n  web:\n    image: nginx\n    platform: linux/amd64\n  api:\n    build:\n      context: .\n      platforms: [linux/amd64, linux/arm/v7]\n  db:\n    image: postgres\n    platform: linux/arm64\n",
+			contract: "type: sw.application\nrequires:\n  - type: arch.sw\n    slug: aarch64\n",
+			code:     ContractConflictCode,
+			paths:    []string{"services.api.build.platforms", "services.web.platform"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compose := tt.compose
+			if compose == "" {
+				compose = "services:\n  web:\n    image: nginx\n"
+			}
+			dir := writeFiles(t, map[string]string{"compose.yml": compose, "balena.yml": tt.contract})
+			_, err := New(Options{ProjectName: "test", Contract: filepath.Join(dir, "balena.yml")}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+			parserErr := expectError(t, err, ValidationError, "")
+			var paths []string
+			for _, e := range parserErr.Errors {
+				if e.Code != tt.code {
+					t.Errorf("expected the %s code, got %s", tt.code, e.Code)
+				}
+				paths = append(paths, e.Location.Path)
+			}
+			if strings.Join(paths, ", ") != strings.Join(tt.paths, ", ") {
+				t.Errorf("expected issues at %v, got %v: %s", tt.paths, paths, parserErr.Message)
+			}
+			// Issues with the contract are located in it, and conflicts in the compose files
+			file := filepath.Join(dir, "balena.yml")
+			if tt.code == ContractConflictCode {
+				file = filepath.Join(dir, "compose.yml")
+			}
+			if parserErr.Location == nil || parserErr.Location.File != file || parserErr.Location.Line == 0 {
+				t.Errorf("expected the error to be located in %s, got %+v", file, parserErr.Location)
+			}
+		})
+	}
+
+	dir := writeFiles(t, map[string]string{"compose.yml": "services:\n  web:\n    image: nginx\n", "balena.yml": "type: [\n"})
+	_, err := New(Options{ProjectName: "test", Contract: filepath.Join(dir, "balena.yml")}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if parserErr := expectError(t, err, ParseError, "Failed to parse contract"); parserErr.Location == nil || parserErr.Location.File != filepath.Join(dir, "balena.yml") {
+		t.Errorf("expected the error to be located in the contract, got %+v", parserErr.Location)
+	}
+}
+
+func TestValidVersionRange(t *testing.T) {
+	for version, expected := range map[string]bool{
+		">=2.88.0":          true,
+		"^2.1":              true,
+		"~v14.4.0":          true,
+		"2.x":               true,
+		"*":                 true,
+		">=2.0.0 <3 || >=4": true,
+		"1.0.0-rc.1+build":  true,
+		"":                  false,
+		"latest":            false,
+		">= 2.0":            false,
+		"1.0 ||":            false,
+	} {
+		if valid := validVersionRange(version); valid != expected {
+			t.Errorf("expected %q to be valid %t, got %t", version, expected, valid)
+		}
+	}
+}
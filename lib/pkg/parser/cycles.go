@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// DependencyCycleCode is reported for services which depend on each other in a cycle, whether through
+// depends_on, links, network_mode or volumes_from
+const DependencyCycleCode = "dependency-cycle"
+
+// The text compose-go's errors for dependency cycles start with, which lack the fields forming the cycle
+const composeCycleError = "dependency cycle detected"
+
+// dependency is the dependency of a service on another, through a field, e.g. links
+type dependency struct {
+	service string
+	field   string
+	on      string
+}
+
+func (d dependency) String() string {
+	switch d.field {
+	case "network_mode", "volumes_from", "pid", "ipc":
+		return fmt.Sprintf("%s has %s service:%s", d.service, d.field, d.on)
+	case "links":
+		return fmt.Sprintf("%s links %s", d.service, d.on)
+	}
+	return fmt.Sprintf("%s depends_on %s", d.service, d.on)
+}
+
+// The services a service depends on, each through the field compose-go implies it from, or depends_on. Only
+// the first field of links, network_mode, pid, ipc and volumes_from which implies each is kept.
+func serviceDependencies(name string, service types.ServiceConfig) map[string]dependency {
+	dependencies := map[string]dependency{}
+	for on := range service.DependsOn {
+		dependencies[on] = dependency{name, "depends_on", on}
+	}
+	for i := len(service.VolumesFrom) - 1; i >= 0; i-- {
+		on, _, _ := strings.Cut(strings.TrimPrefix(service.VolumesFrom[i], "service:"), ":")
+		if !strings.HasPrefix(service.VolumesFrom[i], "container:") {
+			dependencies[on] = dependency{name, "volumes_from", on}
+		}
+	}
+	for _, mode := range []struct{ field, value string }{{"ipc", service.Ipc}, {"pid", service.Pid}, {"network_mode", service.NetworkMode}} {
+		if on, ok := strings.CutPrefix(mode.value, "service:"); ok {
+			dependencies[on] = dependency{name, mode.field, on}
+		}
+	}
+	for i := len(service.Links) - 1; i >= 0; i-- {
+		on, _, _ := strings.Cut(service.Links[i], ":")
+		dependencies[on] = dependency{name, "links", on}
+	}
+	return dependencies
+}
+
+// The first dependency cycle between the services of a project, as the dependencies forming it, searched
+// in service name order as compose-go does, or nil if there's none
+func dependencyCycle(project *types.Project) []dependency {
+	graph := map[string]map[string]dependency{}
+	for name, service := range project.Services {
+		graph[name] = serviceDependencies(name, service)
+	}
+	var search func(path []dependency, service string) []dependency
+	search = func(path []dependency, service string) []dependency {
+		for _, on := range slices.Sorted(maps.Keys(graph[service])) {
+			edge := graph[service][on]
+			for i, previous := range path {
+				if previous.service == on {
+					return append(slices.Clone(path[i:]), edge)
+				}
+			}
+			if on == service {
+				return []dependency{edge}
+			}
+			if _, ok := graph[on]; !ok {
+				continue
+			}
+			if cycle := search(append(path, edge), on); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(graph)) {
+		if cycle := search(nil, name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// The issue of the first dependency cycle of a project, listing the services forming it and the field of
+// each dependency, located at the field of the first
+func dependencyCycleIssues(project *types.Project) []targetIssue {
+	cycle := dependencyCycle(project)
+	if cycle == nil {
+		return nil
+	}
+	services := []string{cycle[0].service}
+	var dependencies []string
+	for _, dependency := range cycle {
+		services = append(services, dependency.on)
+		dependencies = append(dependencies, dependency.String())
+	}
+	return []targetIssue{{code: DependencyCycleCode, path: fmt.Sprintf("services.%s.%s", cycle[0].service, cycle[0].field), message: fmt.Sprintf(
+		"services %s depend on each other, as %s", strings.Join(services, " -> "), strings.Join(dependencies, ", "))}}
+}
+
+// Find the dependency cycle compose-go reported by loading the project again without its consistency
+// checks, returning a ValidationError for it, or nil if the project fails to load again
+func dependencyCycleError(ctx context.Context, options *cli.ProjectOptions, composeFiles []string) *Error {
+	if err := cli.WithConsistency(false)(options); err != nil {
+		return nil
+	}
+	project, err := options.LoadProject(ctx)
+	if err != nil {
+		return nil
+	}
+	cycleErr, _ := reportIssues("Failed to parse compose file", dependencyCycleIssues(project), composeFiles)
+	return cycleErr
+}
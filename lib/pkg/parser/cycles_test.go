@@ -0,0 +1,63 @@
+package parser
+
+import "testing"
+
+func TestDependencyCycle(t *testing.T) {
+	tests := []struct {
+		name     string
+		services string
+		msg      string
+		line     int
+	}{
+		{
+			name:     "depends_on",
+			services: "  a:\n    image: nginx\n    depends_on: [b]\n  b:\n    image: nginx\n    depends_on: [c]\n  c:\n    image: nginx\n    depends_on: [a]\n",
+			msg:      "services a -> b -> c -> a depend on each other, as a depends_on b, b depends_on c, c depends_on a",
+			line:     4,
+		},
+		{
+			name:     "implied",
+			services: "  a:\n    image: nginx\n    links: [\"b:backend\"]\n  b:\n    image: nginx\n    network_mode: service:c\n  c:\n    image: nginx\n    volumes_from: [a]\n",
+			msg:      "services a -> b -> c -> a depend on each other, as a links b, b has network_mode service:c, c has volumes_from service:a",
+			line:     4,
+		},
+		{
+			name:     "ipc and pid",
+			services: "  a:\n    image: nginx\n    ipc: service:b\n  b:\n    image: nginx\n    pid: service:a\n",
+			msg:      "services a -> b -> a depend on each other, as a has ipc service:b, b has pid service:a",
+			line:     4,
+		},
+		{
+			name:     "self",
+			services: "  a:\n    image: nginx\n    depends_on: [a]\n",
+			msg:      "services a -> a depend on each other, as a depends_on a",
+			line:     4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(t, Options{}, "services:\n"+tt.services)
+			parserErr := expectError(t, err, ValidationError, tt.msg)
+			if parserErr.Code != DependencyCycleCode || parserErr.Location == nil || parserErr.Location.Line != tt.line {
+				t.Errorf("expected a dependency cycle located at line %d, got %s at %+v", tt.line, parserErr.Code, parserErr.Location)
+			}
+		})
+	}
+
+	if dependencyCycle(mustParse(t, Options{}, "services:\n  a:\n    image: nginx\n    depends_on: [b, c]\n  b:\n    image: nginx\n    depends_on: [c]\n  c:\n    image: nginx\n").Project) != nil {
+		t.Error("expected no cycle between services depending on a common service")
+	}
+}
+
+func TestDependencyCycleLint(t *testing.T) {
+	findings := lint(t, LintOptions{}, "services:\n  a:\n    image: nginx:1.25\n    depends_on: [b]\n  b:\n    image: nginx:1.25\n    depends_on: [a]\n")
+	for _, finding := range findings {
+		if finding.Rule == "dependency-cycles" {
+			if finding.Code != DependencyCycleCode || finding.Location.Path != "services.a.depends_on" {
+				t.Errorf("expected the cycle to be located at the first dependency, got %s at %+v", finding.Code, finding.Location)
+			}
+			return
+		}
+	}
+	t.Errorf("expected a dependency-cycles finding, got %v", findingPaths(findings))
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/go-units"
+	"go.yaml.in/yaml/v3"
+)
+
+// InvalidDefaultsCode is reported for invalid values in the defaults file set with Options.Defaults
+const InvalidDefaultsCode = "invalid-defaults"
+
+// Restart policies of the compose spec
+var restartPolicyPattern = regexp.MustCompile(`^(no|always|unless-stopped|on-failure(:\d+)?)$`)
+
+// Defaults are the settings of an organization-wide defaults file, applied to every service beneath the
+// compose files, so that only settings the compose files don't set are applied
+type Defaults struct {
+	// Restart is the restart policy of services which don't set one
+	Restart string `yaml:"restart"`
+	// Logging is the logging of services which don't set one, or the options of those using its driver
+	Logging *struct {
+		Driver  string            `yaml:"driver"`
+		Options map[string]string `yaml:"options"`
+	} `yaml:"logging"`
+	// Labels are added to services which don't set them
+	Labels map[string]string `yaml:"labels"`
+	// Tmpfs is the size of tmpfs mounts and tmpfs entries which don't set one, e.g. 64m
+	Tmpfs *struct {
+		Size string `yaml:"size"`
+	} `yaml:"tmpfs"`
+}
+
+// DefaultedField is a service field set from Options.Defaults, as a path within the service
+type DefaultedField struct {
+	Field string `json:"field"`
+	Value any    `json:"value"`
+}
+
+// Read a defaults file, failing with a ValidationError listing every invalid value, located in the file
+func readDefaults(path string) (*Defaults, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to read defaults: %v", err), Err: err}
+	}
+	var defaults Defaults
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&defaults); err != nil && !errors.Is(err, io.EOF) {
+		location := cmp.Or(locate(err.Error(), nil), &Location{})
+		location.File, _ = filepath.Abs(path)
+		return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse defaults %s: %v", path, err), Location: location, Err: err}
+	}
+
+	var issues []targetIssue
+	fail := func(path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: InvalidDefaultsCode, path: path, message: fmt.Sprintf(format, args...)})
+	}
+	if defaults.Restart != "" && !restartPolicyPattern.MatchString(defaults.Restart) {
+		fail("restart", "restart must be no, always, unless-stopped or on-failure, got %q", defaults.Restart)
+	}
+	if defaults.Logging != nil && defaults.Logging.Driver == "" {
+		fail("logging", "logging must set a driver, which its options are for")
+	}
+	for _, name := range slices.Sorted(maps.Keys(defaults.Labels)) {
+		if isPrivateLabel(name) {
+			fail("labels."+name, "labels: label %s can't use the %q namespace", name, balenaPrivateLabelPrefix)
+		}
+	}
+	if defaults.Tmpfs != nil {
+		if _, err := units.RAMInBytes(defaults.Tmpfs.Size); err != nil {
+			fail("tmpfs.size", "tmpfs.size must be a size such as 64m, got %q", defaults.Tmpfs.Size)
+		}
+	}
+	if err, _ := reportIssues("Invalid defaults "+path, issues, []string{path}); err != nil {
+		return nil, err
+	}
+	return &defaults, nil
+}
+
+// Apply defaults to the services of a project, returning the fields set in each service. Labels and
+// logging options are applied one by one, while logging options are only applied to services using the
+// driver of the defaults.
+func applyDefaults(project *types.Project, defaults *Defaults) map[string][]DefaultedField {
+	applied := map[string][]DefaultedField{}
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		service := project.Services[name]
+		var fields []DefaultedField
+		set := func(field string, value any) {
+			fields = append(fields, DefaultedField{Field: field, Value: value})
+		}
+
+		if defaults.Restart != "" && service.Restart == "" {
+			service.Restart = defaults.Restart
+			set("restart", defaults.Restart)
+		}
+		for _, label := range slices.Sorted(maps.Keys(defaults.Labels)) {
+			if _, ok := service.Labels[label]; !ok {
+				service.Labels = service.Labels.Add(label, defaults.Labels[label])
+				set("labels."+label, defaults.Labels[label])
+			}
+		}
+		if logging := defaults.Logging; logging != nil {
+			switch {
+			case service.Logging == nil:
+				service.Logging = &types.LoggingConfig{Driver: logging.Driver, Options: maps.Clone(logging.Options)}
+				set("logging", service.Logging)
+			case service.Logging.Driver == "" || service.Logging.Driver == logging.Driver:
+				if service.Logging.Driver == "" {
+					service.Logging.Driver = logging.Driver
+					set("logging.driver", logging.Driver)
+				}
+				for _, option := range slices.Sorted(maps.Keys(logging.Options)) {
+					if _, ok := service.Logging.Options[option]; !ok {
+						if service.Logging.Options == nil {
+							service.Logging.Options = types.Options{}
+						}
+						service.Logging.Options[option] = logging.Options[option]
+						set("logging.options."+option, logging.Options[option])
+					}
+				}
+			}
+		}
+		if defaults.Tmpfs != nil {
+			size, _ := units.RAMInBytes(defaults.Tmpfs.Size)
+			for i, volume := range service.Volumes {
+				if volume.Type != types.VolumeTypeTmpfs || (volume.Tmpfs != nil && volume.Tmpfs.Size != 0) {
+					continue
+				}
+				if volume.Tmpfs == nil {
+					volume.Tmpfs = &types.ServiceVolumeTmpfs{}
+				}
+				volume.Tmpfs.Size = types.UnitBytes(size)
+				service.Volumes[i] = volume
+				set(fmt.Sprintf("volumes.%d.tmpfs.size", i), size)
+			}
+			// Entries of tmpfs are a path, optionally followed by mount options, e.g. /run:mode=770
+			for i, tmpfs := range service.Tmpfs {
+				path, options, _ := strings.Cut(tmpfs, ":")
+				if slices.ContainsFunc(strings.Split(options, ","), func(option string) bool { return strings.HasPrefix(option, "size=") }) {
+					continue
+				}
+				service.Tmpfs[i] = path + ":" + strings.TrimPrefix(options+",size="+defaults.Tmpfs.Size, ",")
+				set(fmt.Sprintf("tmpfs.%d", i), service.Tmpfs[i])
+			}
+		}
+		if len(fields) > 0 {
+			applied[name] = fields
+		}
+		project.Services[name] = service
+	}
+	return applied
+}
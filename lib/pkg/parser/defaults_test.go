@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+func TestDefaults(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"defaults.yml": "restart: unless-stopped\nlogging:\n  driver: json-file\n  options:\n    max-size: 10m\n    max-file: \"3\"\n" +
+			"labels:\n  com.example.team: platform\n  com.example.tier: backend\ntmpfs:\n  size: 64m\n",
+	})
+	result := mustParse(t, Options{Defaults: filepath.Join(dir, "defaults.yml")}, "services:\n"+
+		"  web:\n    image: nginx\n    tmpfs: [/run, \"/cache:mode=770\", \"/tmp:size=1m\"]\n    volumes: [{type: tmpfs, target: /data}]\n"+
+		"  db:\n    image: postgres\n    restart: always\n    labels:\n      com.example.tier: data\n    logging:\n      options:\n        max-size: 1m\n"+
+		"  cache:\n    image: redis\n    logging:\n      driver: syslog\n")
+	web, db, cache := result.Project.Services["web"], result.Project.Services["db"], result.Project.Services["cache"]
+
+	// Settings of the compose files take precedence
+	if web.Restart != "unless-stopped" || db.Restart != "always" {
+		t.Errorf("expected the default restart policy unless set, got %s and %s", web.Restart, db.Restart)
+	}
+	if web.Labels["com.example.tier"] != "backend" || db.Labels["com.example.tier"] != "data" || db.Labels["com.example.team"] != "platform" {
+		t.Errorf("expected each default label unless set, got %v and %v", web.Labels, db.Labels)
+	}
+	if !reflect.DeepEqual(db.Logging, &types.LoggingConfig{Driver: "json-file", Options: types.Options{"max-size": "1m", "max-file": "3"}}) {
+		t.Errorf("expected the default logging beneath that of db, got %+v", db.Logging)
+	}
+	if !reflect.DeepEqual(cache.Logging, &types.LoggingConfig{Driver: "syslog"}) {
+		t.Errorf("expected the options of another driver not to be applied, got %+v", cache.Logging)
+	}
+	if !reflect.DeepEqual(web.Tmpfs, types.StringList{"/run:size=64m", "/cache:mode=770,size=64m", "/tmp:size=1m"}) || web.Volumes[0].Tmpfs == nil || web.Volumes[0].Tmpfs.Size != 64*1024*1024 {
+		t.Errorf("expected the default tmpfs size unless set, got %v and %+v", web.Tmpfs, web.Volumes[0].Tmpfs)
+	}
+
+	// The fields set from the defaults are recorded per service
+	expected := []DefaultedField{
+		{Field: "labels.com.example.team", Value: "platform"},
+		{Field: "logging.driver", Value: "json-file"},
+		{Field: "logging.options.max-file", Value: "3"},
+	}
+	if !reflect.DeepEqual(result.Defaults["db"], expected) {
+		t.Errorf("expected %+v, got %+v", expected, result.Defaults["db"])
+	}
+	if fields := result.Defaults["web"]; len(fields) != 7 || fields[0].Field != "restart" || fields[4].Field != "volumes.0.tmpfs.size" {
+		t.Errorf("expected the fields of web, got %+v", fields)
+	}
+}
+
+func TestDefaultsErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"invalid.yml": "restart: sometimes\nlogging:\n  options:\n    max-size: 10m\nlabels:\n  io.balena.private.app-id: \"1\"\ntmpfs:\n  size: large\n",
+		"unknown.yml": "restart: always\nhealthcheck:\n  interval: 10s\n",
+	})
+	compose := "services:\n  web:\n    image: nginx\n"
+
+	_, err := parse(t, Options{Defaults: filepath.Join(dir, "invalid.yml")}, compose)
+	parserErr := expectError(t, err, ValidationError, "Invalid defaults")
+	var paths []string
+	for _, e := range parserErr.Errors {
+		if e.Code != InvalidDefaultsCode || e.Location == nil || e.Location.Line == 0 {
+			t.Errorf("expected a located invalid-defaults error, got %+v", e)
+		}
+		paths = append(paths, e.Location.Path)
+	}
+	if expected := []string{"restart", "logging", "labels.io.balena.private.app-id", "tmpfs.size"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+
+	_, err = parse(t, Options{Defaults: filepath.Join(dir, "unknown.yml")}, compose)
+	if parserErr := expectError(t, err, ParseError, "field healthcheck not found"); parserErr.Location == nil || parserErr.Location.Line != 2 {
+		t.Errorf("expected the unknown field to be located, got %+v", parserErr.Location)
+	}
+	_, err = parse(t, Options{Defaults: filepath.Join(dir, "missing.yml")}, compose)
+	expectError(t, err, IOError, "Failed to read defaults")
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The label namespace of the supervisor features balenaOS still accepts under the resin name
+const resinFeaturePrefix = "io.resin.features."
+
+// Service fields obsoleted by the compose spec which compose-go still accepts, with their replacement. The
+// fields of 2.x files which the compose spec dropped, e.g. volume_driver, are reported as they're converted.
+var deprecatedServiceFields = []struct {
+	field       string
+	reason      string
+	replacement string
+}{
+	{"links", "services reach each other by name on the networks they share", "depends_on for the start order, and network aliases for link aliases"},
+	{"external_links", "containers reach each other by name on the networks they share", "an external network shared with the containers"},
+}
+
+// Find the fields of a compose file which are deprecated, each with the recommended replacement: the
+// service fields of deprecatedServiceFields, pull_policy: if_not_present and io.resin.features labels
+func deprecatedFieldWarnings(config map[string]any) []Warning {
+	var warnings []Warning
+	deprecated := func(path, replacement, format string, args ...any) {
+		warnings = append(warnings, Warning{
+			Code:        DeprecatedFieldWarning,
+			Message:     fmt.Sprintf(format, args...) + "; use " + replacement + " instead",
+			Location:    &Location{Path: path},
+			Replacement: replacement,
+		})
+	}
+	services := object(config["services"])
+	for _, name := range sortedKeys(services) {
+		service := object(services[name])
+		path := "services." + name
+		for _, field := range deprecatedServiceFields {
+			if _, ok := service[field.field]; ok {
+				deprecated(path+"."+field.field, field.replacement, "%s.%s is deprecated, as %s", path, field.field, field.reason)
+			}
+		}
+		if service["pull_policy"] == "if_not_present" {
+			deprecated(path+".pull_policy", "missing", "%s.pull_policy: if_not_present is a deprecated alias of missing", path)
+		}
+		// Labels are located at the labels field, as their names contain dots
+		for _, label := range labelNames(service["labels"]) {
+			if feature, ok := strings.CutPrefix(label, resinFeaturePrefix); ok {
+				deprecated(path+".labels", balenaFeaturePrefix+feature, "%s.labels: label %s is of the deprecated io.resin.features namespace", path, label)
+			}
+		}
+	}
+	return warnings
+}
+
+// The names of labels set as a mapping or as a list of name=value entries, in order
+func labelNames(labels any) []string {
+	if list, ok := labels.([]any); ok {
+		var names []string
+		for _, entry := range list {
+			name, _, _ := strings.Cut(text(entry), "=")
+			names = append(names, name)
+		}
+		return names
+	}
+	return sortedKeys(object(labels))
+}
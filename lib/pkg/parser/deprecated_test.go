@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDeprecatedFields(t *testing.T) {
+	result := mustParse(t, Options{Warnings: true}, "services:\n"+
+		"  web:\n    image: nginx\n    links: [db]\n    external_links: [redis]\n    pull_policy: if_not_present\n"+
+		"    labels:\n      io.resin.features.dbus: \"1\"\n      io.balena.features.kernel-modules: \"1\"\n"+
+		"  db:\n    image: postgres\n    labels: [\"io.resin.features.supervisor-api=1\"]\n")
+	expected := []struct {
+		message     string
+		replacement string
+		path        string
+		line        int
+	}{
+		{
+			message:     "services.db.labels: label io.resin.features.supervisor-api is of the deprecated io.resin.features namespace; use io.balena.features.supervisor-api instead",
+			replacement: "io.balena.features.supervisor-api", path: "services.db.labels", line: 12,
+		},
+		{
+			message:     "services.web.links is deprecated, as services reach each other by name on the networks they share; use depends_on for the start order, and network aliases for link aliases instead",
+			replacement: "depends_on for the start order, and network aliases for link aliases", path: "services.web.links", line: 4,
+		},
+		{
+			message:     "services.web.external_links is deprecated, as containers reach each other by name on the networks they share; use an external network shared with the containers instead",
+			replacement: "an external network shared with the containers", path: "services.web.external_links", line: 5,
+		},
+		{
+			message:     "services.web.pull_policy: if_not_present is a deprecated alias of missing; use missing instead",
+			replacement: "missing", path: "services.web.pull_policy", line: 6,
+		},
+		{
+			message:     "services.web.labels: label io.resin.features.dbus is of the deprecated io.resin.features namespace; use io.balena.features.dbus instead",
+			replacement: "io.balena.features.dbus", path: "services.web.labels", line: 7,
+		},
+	}
+	var warnings []Warning
+	for _, warning := range result.Warnings {
+		if warning.Code == DeprecatedFieldWarning {
+			warnings = append(warnings, warning)
+		}
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d deprecated field warnings, got %+v", len(expected), warnings)
+	}
+	for i, warning := range warnings {
+		if warning.Message != expected[i].message || warning.Replacement != expected[i].replacement {
+			t.Errorf("expected %q replaced by %q, got %q replaced by %q", expected[i].message, expected[i].replacement, warning.Message, warning.Replacement)
+		}
+		if location := warning.Location; location == nil || filepath.Base(location.File) != "compose.yml" || location.Path != expected[i].path || location.Line != expected[i].line {
+			t.Errorf("expected warning %d to be located at line %d %s, got %+v", i, expected[i].line, expected[i].path, location)
+		}
+	}
+}
+
+func TestLabelNames(t *testing.T) {
+	for _, tt := range []struct {
+		labels   any
+		expected []string
+	}{
+		{labels: map[string]any{"b": "1", "a": "2"}, expected: []string{"a", "b"}},
+		{labels: []any{"b=1", "a", "c=x=y"}, expected: []string{"b", "a", "c"}},
+		{labels: nil},
+	} {
+		if names := labelNames(tt.labels); !slices.Equal(names, tt.expected) {
+			t.Errorf("expected %v, got %v", tt.expected, names)
+		}
+	}
+}
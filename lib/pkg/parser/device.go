@@ -0,0 +1,166 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+)
+
+// Codes of the issues making services incompatible with the device type or architecture set with
+// Options.DeviceType or Options.Arch
+const (
+	// PlatformMismatchCode is reported for platforms and build platforms for another architecture
+	PlatformMismatchCode = "platform-mismatch"
+	// IncompatibleImageCode is reported for images from a registry namespace for another architecture,
+	// e.g. arm32v7/alpine or balenalib/amd64-debian
+	IncompatibleImageCode = "incompatible-image"
+	// IncompatibleOptionCode is reported for options the architecture's devices don't support
+	IncompatibleOptionCode = "incompatible-option"
+)
+
+// Architectures are the balena architecture slugs Options.Arch accepts
+var Architectures = slices.Compact(slices.Sorted(maps.Values(balenaArchitectures)))
+
+// Architectures of common balena device types, by slug
+var deviceTypeArchitectures = map[string]string{
+	"raspberry-pi":                 "rpi",
+	"raspberry-pi2":                "armv7hf",
+	"raspberrypi3":                 "armv7hf",
+	"raspberrypi3-64":              "aarch64",
+	"raspberrypi4-64":              "aarch64",
+	"raspberrypi5":                 "aarch64",
+	"raspberrypi400-64":            "aarch64",
+	"raspberrypicm4-ioboard":       "aarch64",
+	"fincm3":                       "armv7hf",
+	"beaglebone-black":             "armv7hf",
+	"beaglebone-green":             "armv7hf",
+	"odroid-xu4":                   "armv7hf",
+	"orangepi-plus2":               "armv7hf",
+	"imx8mm-var-dart":              "aarch64",
+	"jetson-nano":                  "aarch64",
+	"jetson-tx2":                   "aarch64",
+	"jetson-xavier":                "aarch64",
+	"jetson-orin-nano-devkit-nvme": "aarch64",
+	"generic-aarch64":              "aarch64",
+	"generic-amd64":                "amd64",
+	"genericx86-64-ext":            "amd64",
+	"intel-nuc":                    "amd64",
+	"up-board":                     "amd64",
+	"surface-pro-6":                "amd64",
+	"qemux86-64":                   "amd64",
+	"qemux86":                      "i386",
+}
+
+// DeviceTypes are the device type slugs Options.DeviceType accepts
+var DeviceTypes = slices.Sorted(maps.Keys(deviceTypeArchitectures))
+
+// Architectures of the official image namespaces of Docker Hub, e.g. arm64v8/alpine
+var imageNamespaceArchitectures = map[string]string{
+	"amd64":   "amd64",
+	"arm64v8": "aarch64",
+	"arm32v7": "armv7hf",
+	"arm32v6": "rpi",
+	"i386":    "i386",
+}
+
+// Architectures whose devices can run GPU runtimes, which require NVIDIA drivers. Of aarch64 device types,
+// only Jetsons have them.
+var gpuArchitectures = []string{"amd64", "aarch64"}
+
+// Resolve the architecture of Options.DeviceType and Options.Arch, which must agree if both are set
+func deviceArch(deviceType, arch string) (string, *Error) {
+	if arch != "" && !slices.Contains(Architectures, arch) {
+		return "", &Error{Name: ArgumentError, Message: fmt.Sprintf("Unsupported architecture %q, expected one of: %s", arch, strings.Join(Architectures, ", "))}
+	}
+	if deviceType == "" {
+		return arch, nil
+	}
+	deviceTypeArch, ok := deviceTypeArchitectures[deviceType]
+	if !ok {
+		return "", &Error{Name: ArgumentError, Message: fmt.Sprintf("Unknown device type %q, set its architecture instead, one of: %s", deviceType, strings.Join(Architectures, ", "))}
+	}
+	if arch != "" && arch != deviceTypeArch {
+		return "", &Error{Name: ArgumentError, Message: fmt.Sprintf("Device type %s is %s, not %s", deviceType, deviceTypeArch, arch)}
+	}
+	return deviceTypeArch, nil
+}
+
+// The architecture an image is for, from its official or balenalib namespace, or "" if unknown
+func imageArch(image string) string {
+	named, err := reference.ParseDockerRef(image)
+	if err != nil || reference.Domain(named) != "docker.io" {
+		return ""
+	}
+	namespace, name, ok := strings.Cut(reference.Path(named), "/")
+	if !ok {
+		return ""
+	}
+	if namespace != "balenalib" {
+		return imageNamespaceArchitectures[namespace]
+	}
+	// balenalib images are named <device type or architecture>-<distro>, where device types may contain
+	// dashes, so the longest match is the device type
+	var match, arch string
+	for slug, slugArch := range deviceTypeArchitectures {
+		if strings.HasPrefix(name, slug+"-") && len(slug) > len(match) {
+			match, arch = slug, slugArch
+		}
+	}
+	for _, slug := range Architectures {
+		if strings.HasPrefix(name, slug+"-") && len(slug) > len(match) {
+			match, arch = slug, slug
+		}
+	}
+	return arch
+}
+
+// Check every service can run on devices of an architecture, returning a ValidationError listing each
+// incompatibility. device describes the device type or architecture in the message.
+func checkDevice(project *types.Project, arch, device string, composeFiles []string) *Error {
+	var issues []targetIssue
+	fail := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...)})
+	}
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		service := project.Services[name]
+		path := "services." + name
+		if service.Platform != "" && platformArch(service.Platform) != arch {
+			fail(PlatformMismatchCode, path+".platform", "%s.platform %q isn't for %s devices", path, service.Platform, arch)
+		}
+		if service.Build != nil && len(service.Build.Platforms) > 0 && !slices.ContainsFunc(service.Build.Platforms, func(platform string) bool {
+			return platformArch(platform) == arch
+		}) {
+			fail(PlatformMismatchCode, path+".build.platforms", "%s.build.platforms doesn't include a platform for %s devices", path, arch)
+		}
+		if imageArch := imageArch(service.Image); imageArch != "" && imageArch != arch {
+			fail(IncompatibleImageCode, path+".image", "%s.image %s is for %s devices, not %s", path, service.Image, imageArch, arch)
+		}
+		if !gpuDevice(arch, device) {
+			if service.Runtime == "nvidia" {
+				fail(IncompatibleOptionCode, path+".runtime", "%s.runtime nvidia isn't available on %s devices", path, device)
+			}
+			for _, r := range deviceRequests(path, service) {
+				if r.isGPU() {
+					fail(IncompatibleOptionCode, r.path, "%s requests GPUs, which %s devices don't have", r.path, device)
+				}
+			}
+		} else if isJetson(device) {
+			issues = append(issues, jetsonGPUIssues(path, service, device)...)
+		}
+		// balenaOS kernels only enable real-time group scheduling on x86
+		if arch != "amd64" && arch != "i386" {
+			if service.CPURTPeriod != 0 {
+				fail(IncompatibleOptionCode, path+".cpu_rt_period", "%s.cpu_rt_period isn't supported on %s devices", path, arch)
+			}
+			if service.CPURTRuntime != 0 {
+				fail(IncompatibleOptionCode, path+".cpu_rt_runtime", "%s.cpu_rt_runtime isn't supported on %s devices", path, arch)
+			}
+		}
+	}
+	err, _ := reportIssues("Project isn't compatible with "+device, issues, composeFiles)
+	return err
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeviceArch(t *testing.T) {
+	tests := []struct {
+		deviceType string
+		arch       string
+		expected   string
+		message    string
+	}{
+		{},
+		{arch: "armv7hf", expected: "armv7hf"},
+		{deviceType: "raspberrypi4-64", expected: "aarch64"},
+		{deviceType: "raspberrypi3", arch: "armv7hf", expected: "armv7hf"},
+		{arch: "sparc", message: `Unsupported architecture "sparc", expected one of: aarch64, amd64, armv7hf, i386, rpi`},
+		{deviceType: "toaster", message: `Unknown device type "toaster", set its architecture instead`},
+		{deviceType: "intel-nuc", arch: "aarch64", message: "Device type intel-nuc is amd64, not aarch64"},
+	}
+	for _, tt := range tests {
+		arch, err := deviceArch(tt.deviceType, tt.arch)
+		if tt.message != "" {
+			expectError(t, err, ArgumentError, tt.message)
+		} else if err != nil || arch != tt.expected {
+			t.Errorf("expected %s and %s to be %q, got %q: %v", tt.deviceType, tt.arch, tt.expected, arch, err)
+		}
+	}
+}
+
+func TestImageArch(t *testing.T) {
+	for image, expected := range map[string]string{
+		"nginx":                                  "",
+		"arm32v7/nginx:1.25":                     "armv7hf",
+		"docker.io/arm64v8/alpine":               "aarch64",
+		"balenalib/raspberrypi3-debian":          "armv7hf",
+		"balenalib/raspberrypi3-64-debian:jammy": "aarch64",
+		"balenalib/amd64-alpine-node":            "amd64",
+		"balenalib/debian":                       "",
+		"registry.example.com/arm32v7/nginx":     "",
+		"Invalid:Image":                          "",
+	} {
+		if arch := imageArch(image); arch != expected {
+			t.Errorf("expected %s to be for %q, got %q", image, expected, arch)
+		}
+	}
+}
+
+func TestCheckDevice(t *testing.T) {
+	compose := "services:\n" +
+		"  web:\n    image: nginx\n    platform: linux/amd64\n" +
+		"  api:\n    build:\n      context: .\n      platforms: [linux/amd64, linux/386]\n" +
+		"  db:\n    image: arm64v8/postgres\n" +
+		"  worker:\n    image: node\n    cpu_rt_runtime: 950000\n    cpu_rt_period: 1000000\n" +
+		"  ok:\n    image: balenalib/raspberrypi3-debian\n    platform: linux/arm/v7\n    build:\n      context: .\n      platforms: [linux/arm64, linux/arm/v7]\n"
+	_, err := parse(t, Options{DeviceType: "raspberrypi3"}, compose)
+	parserErr := expectError(t, err, ValidationError, "Project isn't compatible with raspberrypi3: ")
+	expected := []string{
+		"platform-mismatch services.api.build.platforms",
+		"incompatible-image services.db.image",
+		"platform-mismatch services.web.platform",
+		"incompatible-option services.worker.cpu_rt_period",
+		"incompatible-option services.worker.cpu_rt_runtime",
+	}
+	var issues []string
+	for _, e := range parserErr.Errors {
+		issues = append(issues, e.Code+" "+e.Location.Path)
+	}
+	if strings.Join(issues, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("expected %v, got %v", expected, issues)
+	}
+
+	// Real-time scheduling is only supported on x86, and devices are described by their architecture
+	_, err = parse(t, Options{Arch: "amd64"}, "services:\n  worker:\n    image: node\n    cpu_rt_runtime: 950000\n    platform: linux/arm64\n")
+	expectError(t, err, ValidationError, `Project isn't compatible with amd64: services.worker.platform "linux/arm64" isn't for amd64 devices`)
+	if _, err := parse(t, Options{}, compose); err != nil {
+		t.Errorf("expected devices not to be checked without a device type or architecture, got %v", err)
+	}
+	_, err = parse(t, Options{DeviceType: "toaster"}, compose)
+	expectError(t, err, ArgumentError, "Unknown device type")
+}
@@ -0,0 +1,299 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/schema"
+	"github.com/compose-spec/compose-go/v2/template"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// compose-go stops loading at the first error, so with Options.AllErrors each local compose file is
+// checked again independently, continuing past every YAML, interpolation and schema error in turn.
+// Errors compose-go reports from later stages, e.g. undefined references, are added as is.
+func collectErrors(composeFiles []string, environment types.Mapping, interpolation bool, loadErr error) []*Error {
+	var errs []*Error
+	for _, composeFile := range composeFiles {
+		// Stdin has already been consumed, and remote files are only fetched by compose-go
+		if composeFile == StdinPath || strings.HasPrefix(composeFile, "https://") {
+			continue
+		}
+		errs = append(errs, checkComposeFile(composeFile, environment, interpolation)...)
+	}
+	if len(errs) == 0 || !coveredByDiagnostics(loadErr) {
+		errs = append(errs, &Error{Name: loadErrorName(loadErr), Message: fmt.Sprintf("Failed to parse compose file: %v", loadErr), Err: loadErr})
+	}
+	for _, err := range errs {
+		err.Location = locate(err.Message, composeFiles)
+	}
+	return errs
+}
+
+// Whether a compose-go load error is one checkComposeFile also reports
+func coveredByDiagnostics(err error) bool {
+	var missing template.MissingRequiredError
+	var invalid *template.InvalidTemplateError
+	return isSchemaError(err) || errors.As(err, &missing) || errors.As(err, &invalid) ||
+		strings.HasPrefix(err.Error(), "failed to parse ") ||
+		strings.HasPrefix(err.Error(), "invalid interpolation format for ") ||
+		strings.HasPrefix(err.Error(), "error while interpolating ")
+}
+
+// Check each document of a compose file for YAML, interpolation and schema errors. Variables are
+// only interpolated if interpolation is set, as otherwise compose-go validates the raw values.
+func checkComposeFile(composeFile string, environment types.Mapping, interpolation bool) []*Error {
+	// Paths are made absolute to match the file names in compose-go's errors
+	if abs, err := filepath.Abs(composeFile); err == nil {
+		composeFile = abs
+	}
+	content, err := os.ReadFile(composeFile)
+	if err != nil {
+		return []*Error{{Name: IOError, Message: fmt.Sprintf("Failed to parse compose file: %v", err), Err: err}}
+	}
+
+	documents, err := decodeDocuments(content)
+	var errs []*Error
+	for _, document := range documents {
+		config, ok := document.(map[string]any)
+		if !ok {
+			errs = append(errs, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse compose file: %s: top-level object must be a mapping", composeFile)})
+			continue
+		}
+
+		var interpolated any = config
+		if interpolation {
+			var interpolationErrs []error
+			interpolated, interpolationErrs = interpolate(config, "", environment)
+			for _, err := range interpolationErrs {
+				errs = append(errs, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse compose file: %v", err), Err: err})
+			}
+		}
+		for _, err := range validateSchema(interpolated.(map[string]any)) {
+			errs = append(errs, &Error{Name: ValidationError, Message: fmt.Sprintf("Failed to parse compose file: validating %s: %v", composeFile, err), Err: err})
+		}
+	}
+	if err != nil {
+		errs = append(errs, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse compose file: failed to parse %s: %v", composeFile, err), Err: err})
+	}
+	return errs
+}
+
+// Decode each document of a YAML stream, up to the first malformed document if any
+func decodeDocuments(content []byte) ([]any, error) {
+	var documents []any
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			// The rest of the stream can't be decoded once the YAML is malformed
+			return documents, err
+		}
+
+		// compose-go's !reset and !override tags only affect merging, and would fail to decode
+		clearCustomTags(&node)
+		// Duplicate keys are reported when converting the files to load, and would fail to decode
+		removeDuplicateKeys(&node, "")
+		var raw any
+		if err := node.Decode(&raw); err != nil {
+			return documents, err
+		}
+		documents = append(documents, stringKeys(raw))
+	}
+}
+
+func clearCustomTags(node *yaml.Node) {
+	if strings.HasPrefix(node.Tag, "!") && !strings.HasPrefix(node.Tag, "!!") {
+		node.Tag = ""
+	}
+	for _, child := range node.Content {
+		clearCustomTags(child)
+	}
+}
+
+// Convert YAML mappings with non-string keys, e.g. numbers, to string keyed maps like compose-go does
+func stringKeys(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for k, v := range value {
+			value[k] = stringKeys(v)
+		}
+		return value
+	case map[any]any:
+		converted := make(map[string]any, len(value))
+		for k, v := range value {
+			converted[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return converted
+	case []any:
+		for i, v := range value {
+			value[i] = stringKeys(v)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+// Interpolate every string in the config, collecting errors rather than stopping at the first.
+// Values which fail to interpolate are left as is.
+func interpolate(value any, path string, environment types.Mapping) (any, []error) {
+	switch value := value.(type) {
+	case string:
+		interpolated, err := template.Substitute(value, func(key string) (string, bool) {
+			v, ok := environment[key]
+			return v, ok
+		})
+		if err == nil {
+			return interpolated, nil
+		}
+		var invalid *template.InvalidTemplateError
+		if errors.As(err, &invalid) {
+			return value, []error{fmt.Errorf("invalid interpolation format for %s: %s", path, invalid.Template)}
+		}
+		return value, []error{fmt.Errorf("error while interpolating %s: %w", path, err)}
+	case map[string]any:
+		var errs []error
+		out := make(map[string]any, len(value))
+		// Keys are sorted so that errors are reported in a stable order
+		for _, k := range slices.Sorted(maps.Keys(value)) {
+			var vErrs []error
+			out[k], vErrs = interpolate(value[k], joinPath(path, k), environment)
+			errs = append(errs, vErrs...)
+		}
+		return out, errs
+	case []any:
+		var errs []error
+		out := make([]any, len(value))
+		for i, v := range value {
+			var vErrs []error
+			out[i], vErrs = interpolate(v, joinPath(path, fmt.Sprint(i)), environment)
+			errs = append(errs, vErrs...)
+		}
+		return out, errs
+	default:
+		return value, nil
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// The compose-spec schema, compiled the same way as compose-go's schema package
+var composeSchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	document, err := jsonschema.UnmarshalJSON(strings.NewReader(schema.Schema))
+	if err != nil {
+		return nil, err
+	}
+	if err := compiler.AddResource("compose-spec.json", document); err != nil {
+		return nil, err
+	}
+	compiler.RegisterFormat(&jsonschema.Format{
+		Name: "duration",
+		Validate: func(value any) error {
+			s, ok := value.(string)
+			if !ok {
+				return errors.New("expected string")
+			}
+			_, err := time.ParseDuration(s)
+			return err
+		},
+	})
+	return compiler.Compile("compose-spec.json")
+})
+
+// Validate a config against the compose-spec schema, returning every violation
+func validateSchema(config map[string]any) []error {
+	compiled, err := composeSchema()
+	if err != nil {
+		return []error{err}
+	}
+	err = compiled.Validate(config)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		if err != nil {
+			return []error{err}
+		}
+		return nil
+	}
+
+	printer := message.NewPrinter(language.English)
+	var msgs []string
+	for _, violation := range schemaViolations(validationErr) {
+		location := strings.Join(violation.InstanceLocation, ".")
+		if location == "" {
+			location = "(root)"
+		}
+		msgs = append(msgs, fmt.Sprintf("%s %s", location, violation.ErrorKind.LocalizedString(printer)))
+	}
+	slices.Sort(msgs)
+	var errs []error
+	for _, msg := range slices.Compact(msgs) {
+		errs = append(errs, errors.New(msg))
+	}
+	return errs
+}
+
+// The independent violations in a validation error tree. Only the most specific failure of
+// alternatives is kept, as compose-go does, since e.g. a oneOf of a string or mapping fails both.
+func schemaViolations(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	switch err.ErrorKind.(type) {
+	case *kind.OneOf, *kind.AnyOf:
+		return []*jsonschema.ValidationError{mostSpecificViolation(err)}
+	}
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var violations []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		violations = append(violations, schemaViolations(cause)...)
+	}
+	return violations
+}
+
+func mostSpecificViolation(err *jsonschema.ValidationError) *jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return err
+	}
+	var mostSpecific *jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		cause = mostSpecificViolation(cause)
+		if violationSpecificity(cause) > violationSpecificity(mostSpecific) {
+			mostSpecific = cause
+		}
+	}
+	return mostSpecific
+}
+
+func violationSpecificity(err *jsonschema.ValidationError) int {
+	if err == nil {
+		return -1
+	}
+	if _, ok := err.ErrorKind.(*kind.AdditionalProperties); ok {
+		return len(err.InstanceLocation) + 1
+	}
+	return len(err.InstanceLocation)
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAllErrors(t *testing.T) {
+	contents := []string{
+		"services:\n  web:\n    image: nginx\n    ports: true\n    unknown: true\n",
+		"services:\n  web:\n    environment:\n      A: ${REQUIRED:?is required}\n      B: ${INVALID\n",
+		"services:\n  db:\n    image: postgres\n---\nservices: [\n",
+	}
+
+	_, err := parse(t, Options{}, contents...)
+	if parserErr := expectError(t, err, ValidationError, ""); parserErr.Errors != nil {
+		t.Errorf("expected only the first error without AllErrors, got %+v", parserErr.Errors)
+	}
+
+	_, err = parse(t, Options{AllErrors: true}, contents...)
+	parserErr := expectError(t, err, ValidationError, "")
+	expected := []struct {
+		name    string
+		message string
+		file    string
+	}{
+		{name: ValidationError, message: "services.web additional properties 'unknown' not allowed", file: "compose.yml"},
+		{name: ValidationError, message: "services.web.ports got boolean, want array", file: "compose.yml"},
+		{name: ParseError, message: "REQUIRED", file: "compose-1.yml"},
+		{name: ParseError, message: "invalid interpolation format for services.web.environment.B", file: "compose-1.yml"},
+		{name: ParseError, message: "failed to parse", file: "compose-2.yml"},
+	}
+	if len(parserErr.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(parserErr.Errors), parserErr.Errors)
+	}
+	for i, e := range parserErr.Errors {
+		if e.Name != expected[i].name || !strings.Contains(e.Message, expected[i].message) {
+			t.Errorf("expected error %d to be a %s containing %q, got %s: %s", i, expected[i].name, expected[i].message, e.Name, e.Message)
+		}
+		if e.Location == nil || !strings.HasSuffix(e.Location.File, expected[i].file) {
+			t.Errorf("expected error %d to be located in %s, got %+v", i, expected[i].file, e.Location)
+		}
+	}
+}
+
+func TestAllErrorsFromLaterStages(t *testing.T) {
+	// Undefined references aren't checked per file, so compose-go's error is reported as is
+	_, err := parse(t, Options{AllErrors: true}, "services:\n  web:\n    image: nginx\n    depends_on: [missing]\n")
+	parserErr := expectError(t, err, ValidationError, "undefined service")
+	if len(parserErr.Errors) != 1 || !strings.Contains(parserErr.Errors[0].Message, "missing") {
+		t.Errorf("expected the undefined service error, got %v", parserErr.Errors)
+	}
+}
+
+func TestAllErrorsWithoutInterpolation(t *testing.T) {
+	// Variables aren't interpolated, so the raw values are validated without interpolation errors
+	_, err := parse(t, Options{AllErrors: true, NoInterpolate: true}, "services:\n  web:\n    image: nginx\n    environment:\n      A: ${REQUIRED:?is required}\n    ports: true\n")
+	parserErr := expectError(t, err, ValidationError, "")
+	if len(parserErr.Errors) != 1 || !strings.Contains(parserErr.Errors[0].Message, "services.web.ports") {
+		t.Errorf("expected only the ports validation error, got %v", parserErr.Errors)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	config := map[string]any{
+		"services": map[string]any{
+			"web": map[string]any{
+				"image":   "nginx:${TAG}",
+				"command": []any{"${MISSING:?must be set}", "${ARG}"},
+				"labels":  map[string]any{"a": "${", "b": 1},
+			},
+		},
+	}
+	interpolated, errs := interpolate(config, "", map[string]string{"TAG": "1.25", "ARG": "arg"})
+	web := interpolated.(map[string]any)["services"].(map[string]any)["web"].(map[string]any)
+	if web["image"] != "nginx:1.25" || web["command"].([]any)[1] != "arg" || web["labels"].(map[string]any)["b"] != 1 {
+		t.Errorf("expected the values to be interpolated, got %+v", web)
+	}
+	if web["command"].([]any)[0] != "${MISSING:?must be set}" || web["labels"].(map[string]any)["a"] != "${" {
+		t.Errorf("expected values failing to interpolate to be kept, got %+v", web)
+	}
+	messages := []string{
+		"error while interpolating services.web.command.0",
+		"invalid interpolation format for services.web.labels.a",
+	}
+	if len(errs) != len(messages) {
+		t.Fatalf("expected %d errors, got %v", len(messages), errs)
+	}
+	for i, err := range errs {
+		if !strings.Contains(err.Error(), messages[i]) {
+			t.Errorf("expected %q, got %q", messages[i], err)
+		}
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   map[string]any
+		expected []string
+	}{
+		{name: "valid", config: map[string]any{"services": map[string]any{"web": map[string]any{"image": "nginx"}}}},
+		{
+			name:     "violations",
+			config:   map[string]any{"services": map[string]any{"web": map[string]any{"image": 1, "unknown": true}}, "other": true},
+			expected: []string{"(root) additional properties 'other' not allowed", "services.web additional properties 'unknown' not allowed", "services.web.image got number, want string"},
+		},
+		{
+			// Either a string or a list, so only the violation of the more specific list is reported
+			name:     "alternatives",
+			config:   map[string]any{"services": map[string]any{"web": map[string]any{"image": "nginx", "command": []any{1}}}},
+			expected: []string{"services.web.command.0 got number, want string"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateSchema(tt.config)
+			if len(errs) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, errs)
+			}
+			for i, err := range errs {
+				if err.Error() != tt.expected[i] {
+					t.Errorf("expected %q, got %q", tt.expected[i], err)
+				}
+			}
+		})
+	}
+}
+
+func TestCoveredByDiagnostics(t *testing.T) {
+	for message, expected := range map[string]bool{
+		"failed to parse compose.yml: yaml: line 1":       true,
+		"invalid interpolation format for image":          true,
+		"error while interpolating image":                 true,
+		`service "web" depends on undefined service "db"`: false,
+	} {
+		if covered := coveredByDiagnostics(errors.New(message)); covered != expected {
+			t.Errorf("expected %q to be covered %t, got %t", message, expected, covered)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DuplicateKeyCode is reported as a warning for keys defined several times in a mapping of a compose file,
+// of which only the last definition is kept
+const DuplicateKeyCode = "duplicate-key"
+
+// Remove the earlier definitions of keys defined several times in the mappings of a node, keeping the last
+// as YAML parsers which don't reject duplicate keys do, and returning an issue for each definition removed.
+// Merge keys may be repeated, and aliases are checked where their anchor is defined.
+func removeDuplicateKeys(node *yaml.Node, path string) []targetIssue {
+	var issues []targetIssue
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			issues = append(issues, removeDuplicateKeys(child, path)...)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			issues = append(issues, removeDuplicateKeys(child, joinPath(path, fmt.Sprint(i)))...)
+		}
+	case yaml.MappingNode:
+		last := map[string]*yaml.Node{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if key := node.Content[i]; key.Kind == yaml.ScalarNode && key.Value != "<<" {
+				last[key.Value] = key
+			}
+		}
+		var content []*yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinPath(path, key.Value)
+			if final, ok := last[key.Value]; ok && final != key {
+				issues = append(issues, targetIssue{code: DuplicateKeyCode, path: keyPath, warning: true, message: fmt.Sprintf(
+					"%s is defined again at line %d, so its definition at line %d is ignored", keyPath, final.Line, key.Line)})
+				continue
+			}
+			content = append(content, key, value)
+			issues = append(issues, removeDuplicateKeys(value, keyPath)...)
+		}
+		node.Content = content
+	}
+	return issues
+}
+
+// DuplicateContainerNameCode is reported for services with the container name of another service
+const DuplicateContainerNameCode = "duplicate-container-name"
+
+// Check no two services have the same container name, as "docker compose config" does, and that no
+// container name is one docker compose generates for a container of another service, <project>-<service>-<n>,
+// returning a ValidationError naming both services of each collision. This replaces compose-go's
+// CheckContainerNameUnicity, which doesn't name the other service.
+func checkContainerNames(project *types.Project, composeFiles []string) *Error {
+	var issues []targetIssue
+	services := map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		containerName := project.Services[name].ContainerName
+		if containerName == "" {
+			continue
+		}
+		path := "services." + name + ".container_name"
+		if existing, ok := services[containerName]; ok {
+			issues = append(issues, targetIssue{code: DuplicateContainerNameCode, path: path, message: fmt.Sprintf(
+				"services %q and %q both have container name %q", existing, name, containerName)})
+			continue
+		}
+		services[containerName] = name
+		if generated, ok := generatedContainerService(project, containerName); ok && generated != name {
+			issues = append(issues, targetIssue{code: DuplicateContainerNameCode, path: path, message: fmt.Sprintf(
+				"service %q has container name %q, which docker compose gives a container of service %q", name, containerName, generated)})
+		}
+	}
+	err, _ := reportIssues("Duplicate container names", issues, composeFiles)
+	return err
+}
+
+// The service without a container name which docker compose names a container containerName, as
+// <project>-<service>-<n> for each of its replicas
+func generatedContainerService(project *types.Project, containerName string) (string, bool) {
+	rest, ok := strings.CutPrefix(containerName, project.Name+"-")
+	if !ok {
+		return "", false
+	}
+	index := strings.LastIndex(rest, "-")
+	if index < 0 {
+		return "", false
+	}
+	name, number := rest[:index], rest[index+1:]
+	service, ok := project.Services[name]
+	if !ok || service.ContainerName != "" {
+		return "", false
+	}
+	replica, err := strconv.Atoi(number)
+	if err != nil || replica < 1 || replica > serviceReplicas(service) || number != strconv.Itoa(replica) {
+		return "", false
+	}
+	return name, true
+}
+
+// The number of containers of a service, from deploy.replicas or scale, which defaults to one
+func serviceReplicas(service types.ServiceConfig) int {
+	switch {
+	case service.Deploy != nil && service.Deploy.Replicas != nil:
+		return *service.Deploy.Replicas
+	case service.Scale != nil:
+		return *service.Scale
+	}
+	return 1
+}
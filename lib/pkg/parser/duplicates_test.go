@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDuplicateKeys(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"compose.yml": "x-defaults: &defaults\n  restart: always\n" +
+		"services:\n" +
+		"  web:\n    <<: *defaults\n    image: nginx\n    environment:\n      A: \"1\"\n    environment:\n      B: \"2\"\n" +
+		"  web:\n    image: nginx:1.25\n    <<: *defaults\n    environment:\n      C: \"3\"\n      C: \"4\"\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	result, err := New(Options{ProjectName: "test", Warnings: true}).Parse(context.Background(), []string{composeFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last definition of each key is kept
+	web := result.Project.Services["web"]
+	if web.Image != "nginx:1.25" || len(web.Environment) != 1 || *web.Environment["C"] != "4" || web.Restart != "always" {
+		t.Errorf("expected the last definitions to be kept, got %+v", web)
+	}
+	expected := []struct {
+		path    string
+		line    int
+		message string
+	}{
+		{path: "services.web", line: 4, message: "services.web is defined again at line 11, so its definition at line 4 is ignored"},
+		{path: "services.web.environment.C", line: 15, message: "services.web.environment.C is defined again at line 16, so its definition at line 15 is ignored"},
+	}
+	var warnings []Warning
+	for _, warning := range result.Warnings {
+		if warning.Code == DuplicateKeyCode {
+			warnings = append(warnings, warning)
+		}
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected a warning for each ignored definition, got %+v", warnings)
+	}
+	for i, warning := range warnings {
+		if warning.Message != expected[i].message {
+			t.Errorf("expected %q, got %q", expected[i].message, warning.Message)
+		}
+		if warning.Location == nil || warning.Location.File != composeFile || warning.Location.Line != expected[i].line {
+			t.Errorf("expected %s to be located at line %d, got %+v", expected[i].path, expected[i].line, warning.Location)
+		}
+	}
+}
+
+func TestContainerNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		services string
+		expected []string
+	}{
+		{name: "unique", services: "  web:\n    image: nginx\n    container_name: web\n  db:\n    image: postgres\n    container_name: db\n"},
+		{
+			name:     "shared",
+			services: "  web:\n    image: nginx\n    container_name: app\n  db:\n    image: postgres\n    container_name: app\n",
+			expected: []string{"duplicate-container-name services.web.container_name"},
+		},
+		{
+			name:     "generated",
+			services: "  web:\n    image: nginx\n  db:\n    image: postgres\n    container_name: test-web-1\n",
+			expected: []string{"duplicate-container-name services.db.container_name"},
+		},
+		{
+			name:     "generated replica",
+			services: "  web:\n    image: nginx\n    scale: 3\n  db:\n    image: postgres\n    container_name: test-web-3\n",
+			expected: []string{"duplicate-container-name services.db.container_name"},
+		},
+		// docker compose only generates names of the replicas of services without a container name
+		{name: "beyond the replicas", services: "  web:\n    image: nginx\n  db:\n    image: postgres\n    container_name: test-web-2\n"},
+		{name: "padded number", services: "  web:\n    image: nginx\n  db:\n    image: postgres\n    container_name: test-web-01\n"},
+		{name: "own name", services: "  web:\n    image: nginx\n    container_name: test-web-1\n"},
+		{name: "other project", services: "  web:\n    image: nginx\n  db:\n    image: postgres\n    container_name: other-web-1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, _ := targetIssues(t, Options{}, "services:\n"+tt.services)
+			if strings.Join(errs, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("expected %v, got %v", tt.expected, errs)
+			}
+		})
+	}
+
+	_, err := parse(t, Options{}, "services:\n  web:\n    image: nginx\n    container_name: app\n  db:\n    image: postgres\n    container_name: app\n")
+	parserErr := expectError(t, err, ValidationError, `Duplicate container names: services "db" and "web" both have container name "app"`)
+	if parserErr.Location == nil || parserErr.Location.Line != 4 {
+		t.Errorf("expected the error to be located at the container name of web, got %+v", parserErr.Location)
+	}
+	_, err = parse(t, Options{}, "services:\n  web:\n    image: nginx\n  db:\n    image: postgres\n    container_name: test-web-1\n")
+	expectError(t, err, ValidationError, `service "db" has container name "test-web-1", which docker compose gives a container of service "web"`)
+	if _, err := parse(t, Options{SkipConsistency: true}, "services:\n  web:\n    image: nginx\n    container_name: app\n  db:\n    image: postgres\n    container_name: app\n"); err != nil {
+		t.Errorf("expected container names not to be checked with SkipConsistency, got %v", err)
+	}
+}
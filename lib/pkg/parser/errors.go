@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+)
+
+// Error names, which are surfaced to consumers as the `name` field of error responses
+const (
+	ArgumentError   = "ArgumentError"
+	ConfigError     = "ConfigError"
+	ParseError      = "ParseError"
+	TimeoutError    = "TimeoutError"
+	ValidationError = "ValidationError"
+	IOError         = "IOError"
+)
+
+// Error is returned for all failures while parsing a composition, categorized by Name
+type Error struct {
+	// Name is the error category, e.g. ParseError
+	Name string
+	// Code identifies the kind of error within its category, if known, e.g. UnsupportedFieldCode
+	Code string
+	// Message is the human readable description of the error
+	Message string
+	// Err is the underlying error, if any
+	Err error
+	// Location is where in the compose files the error was found, if known
+	Location *Location
+	// Errors are all the errors found, if Options.AllErrors is set
+	Errors []*Error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Categorize an error returned by compose-go while loading a project
+func loadErrorName(err error) string {
+	switch {
+	case errors.Is(err, errdefs.ErrInvalid), errors.Is(err, errdefs.ErrUnsupported), errors.Is(err, errdefs.ErrIncompatible), isSchemaError(err):
+		return ValidationError
+	case errors.Is(err, errdefs.ErrNotFound), errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrPermission):
+		return IOError
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return IOError
+	}
+	return ParseError
+}
+
+// compose-go doesn't export its schema validation error type, so it's identified by name
+func isSchemaError(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if fmt.Sprintf("%T", err) == "schema.validationError" {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorResponse is the JSON error response of an error, as written by the CLI on stderr and returned by
+// the servers and library builds of the parser
+type ErrorResponse struct {
+	Error   bool   `json:"error"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+	// Code identifies the kind of error within its category, if known
+	Code string `json:"code,omitempty"`
+	// Location is where in the compose files the error was found, if known
+	Location *Location `json:"location,omitempty"`
+	// Errors are all the errors found, if Options.AllErrors is set
+	Errors []ErrorDetail `json:"errors,omitempty"`
+}
+
+// ErrorDetail is a single error in a response with multiple errors
+type ErrorDetail struct {
+	Name     string    `json:"name"`
+	Message  string    `json:"message"`
+	Code     string    `json:"code,omitempty"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// NewErrorResponse builds the error response of an error, which is a ParseError unless it's an Error
+func NewErrorResponse(err error) *ErrorResponse {
+	var parserErr *Error
+	if !errors.As(err, &parserErr) {
+		return &ErrorResponse{Error: true, Name: ParseError, Message: err.Error()}
+	}
+	response := &ErrorResponse{Error: true, Name: parserErr.Name, Message: parserErr.Message, Code: parserErr.Code, Location: parserErr.Location}
+	for _, e := range parserErr.Errors {
+		response.Errors = append(response.Errors, ErrorDetail{Name: e.Name, Message: e.Message, Code: e.Code, Location: e.Location})
+	}
+	return response
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewErrorResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "error",
+			err:      errors.New("failed"),
+			expected: `{"error":true,"name":"ParseError","message":"failed"}`,
+		},
+		{
+			name:     "parser error",
+			err:      fmt.Errorf("wrapped: %w", &Error{Name: IOError, Message: "Failed to read compose file", Err: errors.New("not found")}),
+			expected: `{"error":true,"name":"IOError","message":"Failed to read compose file"}`,
+		},
+		{
+			name: "parser error with errors",
+			err: &Error{
+				Name:     ValidationError,
+				Code:     UnsupportedFieldCode,
+				Message:  "Project is invalid",
+				Location: &Location{File: "compose.yml", Line: 3, Column: 5, Path: "services.web.links"},
+				Errors: []*Error{
+					{Name: ValidationError, Code: UnsupportedFieldCode, Message: "services.web.links is unsupported", Location: &Location{File: "compose.yml", Line: 3, Column: 5, Path: "services.web.links"}},
+					{Name: ValidationError, Message: "services.web.ports is invalid"},
+				},
+			},
+			expected: `{"error":true,"name":"ValidationError","message":"Project is invalid","code":"unsupported-field",` +
+				`"location":{"file":"compose.yml","line":3,"column":5,"path":"services.web.links"},"errors":[` +
+				`{"name":"ValidationError","message":"services.web.links is unsupported","code":"unsupported-field","location":{"file":"compose.yml","line":3,"column":5,"path":"services.web.links"}},` +
+				`{"name":"ValidationError","message":"services.web.ports is invalid"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := json.Marshal(NewErrorResponse(tt.err))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(response) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, response)
+			}
+		})
+	}
+}
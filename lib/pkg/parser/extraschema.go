@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// SchemaViolationCode is reported for each constraint of the schema set with Options.ExtraSchema which the
+// output violates
+const SchemaViolationCode = "schema-violation"
+
+// ExtensionViolationCode is reported for each constraint of a schema set with Options.ExtensionSchemas which
+// an extension violates
+const ExtensionViolationCode = "extension-violation"
+
+// Read and compile the JSON Schema at path. Relative $refs are resolved against its directory.
+func readExtraSchema(path string) (*jsonschema.Schema, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to read schema: %v", err), Err: err}
+	}
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+	if err != nil {
+		location, _ := filepath.Abs(path)
+		return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse schema %s: %v", path, err), Location: &Location{File: location}, Err: err}
+	}
+	url, _ := filepath.Abs(path)
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(url, document); err != nil {
+		return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Invalid schema %s: %v", path, err), Err: err}
+	}
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Invalid schema %s: %v", path, err), Err: err}
+	}
+	return compiled, nil
+}
+
+// Read and compile the JSON Schemas of extensions by key, which must start with x-
+func readExtensionSchemas(paths map[string]string) (map[string]*jsonschema.Schema, error) {
+	schemas := map[string]*jsonschema.Schema{}
+	for _, key := range slices.Sorted(maps.Keys(paths)) {
+		if !strings.HasPrefix(key, "x-") {
+			return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Extension schema key %q must start with x-", key)}
+		}
+		compiled, err := readExtraSchema(paths[key])
+		if err != nil {
+			return nil, err
+		}
+		schemas[key] = compiled
+	}
+	return schemas, nil
+}
+
+// Check the project as output conforms to an extra schema, failing with a ValidationError listing each
+// violation, located by the path of the value violating it
+func checkExtraSchema(project *types.Project, compiled *jsonschema.Schema, schemaPath string, composeFiles []string) *Error {
+	projectJSON, err := project.MarshalJSON()
+	if err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to marshal compose project: %v", err), Err: err}
+	}
+	// Validated as decoded by jsonschema, which keeps numbers exact
+	config, err := jsonschema.UnmarshalJSON(bytes.NewReader(projectJSON))
+	if err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to decode compose project: %v", err), Err: err}
+	}
+	err = compiled.Validate(config)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		if err != nil {
+			return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to validate against schema %s: %v", schemaPath, err), Err: err}
+		}
+		return nil
+	}
+
+	violationsErr, _ := reportIssues("Project doesn't conform to schema "+schemaPath, violationIssues(validationErr, SchemaViolationCode, nil), composeFiles)
+	return violationsErr
+}
+
+// Check every extension of the project as output, at any level, conforms to the schema set for its key,
+// failing with a ValidationError listing each violation, located by the path of the value violating it
+// within the extension. The project is checked as output as YAML, as JSON output only keeps top-level
+// extensions.
+func checkExtensionSchemas(project *types.Project, schemas map[string]*jsonschema.Schema, composeFiles []string) *Error {
+	projectYAML, err := project.MarshalYAML()
+	if err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to marshal compose project: %v", err), Err: err}
+	}
+	var document any
+	if err := yaml.Unmarshal(projectYAML, &document); err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to decode compose project: %v", err), Err: err}
+	}
+	projectJSON, err := json.Marshal(stringKeys(document))
+	if err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to marshal compose project: %v", err), Err: err}
+	}
+	config, err := jsonschema.UnmarshalJSON(bytes.NewReader(projectJSON))
+	if err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to decode compose project: %v", err), Err: err}
+	}
+
+	var issues []targetIssue
+	var check func(value any, path []string)
+	check = func(value any, path []string) {
+		switch value := value.(type) {
+		case map[string]any:
+			for _, key := range sortedKeys(value) {
+				extensionPath := append(slices.Clone(path), key)
+				compiled, ok := schemas[key]
+				if !ok {
+					check(value[key], extensionPath)
+					continue
+				}
+				var validationErr *jsonschema.ValidationError
+				if err := compiled.Validate(value[key]); errors.As(err, &validationErr) {
+					issues = append(issues, violationIssues(validationErr, ExtensionViolationCode, extensionPath)...)
+				}
+			}
+		case []any:
+			for i, item := range value {
+				check(item, append(slices.Clone(path), strconv.Itoa(i)))
+			}
+		}
+	}
+	check(config, nil)
+	violationsErr, _ := reportIssues("Extensions don't conform to their schemas", issues, composeFiles)
+	return violationsErr
+}
+
+// The issues of the violations of a schema by the value at a path, each located at the value violating it
+func violationIssues(validationErr *jsonschema.ValidationError, code string, path []string) []targetIssue {
+	printer := message.NewPrinter(language.English)
+	var issues []targetIssue
+	for _, violation := range schemaViolations(validationErr) {
+		violationPath := strings.Join(slices.Concat(path, violation.InstanceLocation), ".")
+		issues = append(issues, targetIssue{code: code, path: violationPath, message: fmt.Sprintf(
+			"%s %s", cmp.Or(violationPath, "(root)"), violation.ErrorKind.LocalizedString(printer))})
+	}
+	return issues
+}
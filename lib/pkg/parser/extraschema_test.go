@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtraSchema(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx\n    ports: [\"8080:80\"]\n  db:\n    image: postgres\n    labels:\n      team: data\n",
+		"valid.yml":   "services:\n  db:\n    image: postgres\n    labels:\n      team: data\n",
+		// Services must be labelled with their team and mustn't publish ports, as defined in another file
+		"schema.json":  `{"properties": {"services": {"additionalProperties": {"$ref": "service.json"}}}}`,
+		"service.json": `{"required": ["labels"], "properties": {"labels": {"required": ["team"]}, "ports": {"maxItems": 0}}}`,
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	schema := filepath.Join(dir, "schema.json")
+	p := New(Options{ProjectName: "test", ExtraSchema: schema})
+
+	_, err := p.Parse(context.Background(), []string{composeFile})
+	parserErr := expectError(t, err, ValidationError, "Project doesn't conform to schema "+schema+": services.web ")
+	var violations []string
+	for _, e := range parserErr.Errors {
+		if e.Code != SchemaViolationCode || e.Location == nil || e.Location.File != composeFile {
+			t.Errorf("expected a located schema violation, got %s at %+v", e.Code, e.Location)
+			continue
+		}
+		violations = append(violations, e.Location.Path)
+	}
+	if expected := []string{"services.web", "services.web.ports"}; !reflect.DeepEqual(violations, expected) {
+		t.Errorf("expected violations at %v, got %v: %v", expected, violations, parserErr.Message)
+	}
+
+	if _, err := p.Parse(context.Background(), []string{filepath.Join(dir, "valid.yml")}); err != nil {
+		t.Errorf("expected a project conforming to the schema to be parsed, got %v", err)
+	}
+}
+
+func TestExtraSchemaErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml":  "services:\n  web:\n    image: nginx\n",
+		"broken.json":  `{`,
+		"invalid.json": `{"type": 1}`,
+	})
+	for _, tt := range []struct {
+		schema string
+		name   string
+		msg    string
+	}{
+		{schema: "missing.json", name: IOError, msg: "Failed to read schema"},
+		{schema: "broken.json", name: ParseError, msg: "Failed to parse schema"},
+		{schema: "invalid.json", name: ParseError, msg: "Invalid schema"},
+	} {
+		_, err := New(Options{ProjectName: "test", ExtraSchema: filepath.Join(dir, tt.schema)}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+		expectError(t, err, tt.name, tt.msg)
+	}
+}
+
+func TestExtensionSchemas(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "x-balena:\n  fleet: 1\nservices:\n  web:\n    image: nginx\n    x-balena:\n      fleet: web\n      extra: true\n" +
+			"  db:\n    image: postgres\n    x-other: 1\n    x-balena:\n      fleet: db\n",
+		"balena.json": `{"type": "object", "properties": {"fleet": {"type": "string"}}, "additionalProperties": false}`,
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	_, err := New(Options{ProjectName: "test", ExtensionSchemas: map[string]string{"x-balena": filepath.Join(dir, "balena.json")}}).Parse(context.Background(), []string{composeFile})
+	parserErr := expectError(t, err, ValidationError, "Extensions don't conform to their schemas")
+	// Extensions are checked at any level, with violations located within the extension
+	expected := []struct {
+		path string
+		line int
+	}{
+		{path: "services.web.x-balena", line: 6},
+		{path: "x-balena.fleet", line: 2},
+	}
+	if len(parserErr.Errors) != len(expected) {
+		t.Fatalf("expected a violation of each invalid extension, got %v", parserErr.Errors)
+	}
+	for i, e := range parserErr.Errors {
+		if e.Code != ExtensionViolationCode || e.Location == nil || e.Location.Path != expected[i].path || e.Location.Line != expected[i].line {
+			t.Errorf("expected a violation at %s line %d, got %s: %q at %+v", expected[i].path, expected[i].line, e.Code, e.Message, e.Location)
+		}
+	}
+
+	_, err = New(Options{ProjectName: "test", ExtensionSchemas: map[string]string{"balena": filepath.Join(dir, "balena.json")}}).Parse(context.Background(), []string{composeFile})
+	expectError(t, err, ArgumentError, `Extension schema key "balena" must start with x-`)
+	_, err = New(Options{ProjectName: "test", ExtensionSchemas: map[string]string{"x-balena": filepath.Join(dir, "missing.json")}}).Parse(context.Background(), []string{composeFile})
+	expectError(t, err, IOError, "Failed to read schema")
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Codes of the issues found with io.balena.features labels
+const (
+	// UnknownFeatureCode is reported for io.balena.features labels the supervisor doesn't recognize, which
+	// have no effect
+	UnknownFeatureCode = "unknown-feature"
+	// InvalidFeatureValueCode is reported for io.balena.features labels whose value isn't a boolean the
+	// supervisor recognizes, e.g. "1", "true" or "0", which it takes to be disabled
+	InvalidFeatureValueCode = "invalid-feature-value"
+)
+
+// Prefix of the labels enabling the supervisor's features in services
+const balenaFeaturePrefix = "io.balena.features."
+
+// Feature is an io.balena.features label enabled in a service, with the mounts, devices and variables
+// the supervisor adds to the service's container when it's set
+type Feature struct {
+	// Label is the label enabling the feature, e.g. io.balena.features.dbus
+	Label string `json:"label"`
+	// Mounts are bind mounts, as <host path>:<container path>[:ro]
+	Mounts []string `json:"mounts,omitempty"`
+	// Devices are host devices, added if present on the device
+	Devices []string `json:"devices,omitempty"`
+	// Environment are the variables set in the container, which are null if only known on the device,
+	// e.g. API keys
+	Environment map[string]*string `json:"environment,omitempty"`
+	// GPU is whether the container is given access to the device's GPUs
+	GPU bool `json:"gpu,omitempty"`
+}
+
+// What the supervisor adds to containers for each feature it recognizes
+var balenaFeatures = map[string]Feature{
+	"io.balena.features.balena-socket": {
+		Mounts: []string{"/var/run/balena-engine.sock:/var/run/balena-engine.sock", "/var/run/balena-engine.sock:/var/run/balena.sock"},
+		// The supervisor doesn't override DOCKER_HOST if the service sets it
+		Environment: map[string]*string{"DOCKER_HOST": ptr("unix:///var/run/balena-engine.sock")},
+	},
+	"io.balena.features.dbus": {
+		Mounts:      []string{"/run/dbus:/host/run/dbus"},
+		Environment: map[string]*string{"DBUS_SYSTEM_BUS_ADDRESS": ptr("unix:path=/host/run/dbus/system_bus_socket")},
+	},
+	"io.balena.features.sysfs":          {Mounts: []string{"/sys:/sys"}},
+	"io.balena.features.procfs":         {Mounts: []string{"/proc:/proc"}},
+	"io.balena.features.kernel-modules": {Mounts: []string{"/lib/modules:/lib/modules"}},
+	"io.balena.features.firmware":       {Mounts: []string{"/lib/firmware:/lib/firmware"}},
+	"io.balena.features.journal-logs": {
+		Mounts: []string{"/var/log/journal:/var/log/journal:ro", "/run/log/journal:/run/log/journal:ro", "/etc/machine-id:/etc/machine-id:ro"},
+	},
+	"io.balena.features.supervisor-api": {
+		Environment: map[string]*string{
+			"BALENA_SUPERVISOR_ADDRESS": nil, "BALENA_SUPERVISOR_HOST": nil, "BALENA_SUPERVISOR_PORT": nil, "BALENA_SUPERVISOR_API_KEY": nil,
+		},
+	},
+	"io.balena.features.balena-api": {
+		Environment: map[string]*string{"BALENA_API_KEY": nil, "BALENA_API_URL": nil},
+	},
+	"io.balena.features.optee": {Devices: []string{"/dev/tee0:/dev/tee0:rwm", "/dev/teepriv0:/dev/teepriv0:rwm"}},
+	"io.balena.features.gpu":   {GPU: true},
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+// Parse a feature label's value as the supervisor does, which takes values other than these to be false
+func parseFeatureValue(value string) (enabled bool, ok bool) {
+	switch strings.ToLower(value) {
+	case "1", "true", "on":
+		return true, true
+	case "0", "false", "off":
+		return false, true
+	}
+	return false, false
+}
+
+// Check the io.balena.features labels of a service, at the path of its labels
+func featureIssues(path string, labels map[string]any) []targetIssue {
+	var issues []targetIssue
+	for _, name := range sortedKeys(labels) {
+		if !strings.HasPrefix(name, balenaFeaturePrefix) {
+			continue
+		}
+		if _, ok := balenaFeatures[name]; !ok {
+			issues = append(issues, targetIssue{
+				code:    UnknownFeatureCode,
+				path:    path + "." + name,
+				message: fmt.Sprintf("%s: label %s isn't a known balena feature", path, name),
+				warning: true,
+			})
+		} else if _, ok := parseFeatureValue(text(labels[name])); !ok {
+			issues = append(issues, targetIssue{
+				code:    InvalidFeatureValueCode,
+				path:    path + "." + name,
+				message: fmt.Sprintf("%s: label %s must be \"1\", \"true\", \"on\", \"0\", \"false\" or \"off\", got %q", path, name, text(labels[name])),
+			})
+		}
+	}
+	return issues
+}
+
+// Check the io.balena.features labels of every service, returning a ValidationError for invalid values
+// and warnings for unknown features
+func checkFeatures(project *types.Project, composeFiles []string) (*Error, []Warning) {
+	var issues []targetIssue
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		labels := map[string]any{}
+		for key, value := range project.Services[name].Labels {
+			labels[key] = value
+		}
+		issues = append(issues, featureIssues("services."+name+".labels", labels)...)
+	}
+	return reportIssues("Project has invalid balena features", issues, composeFiles)
+}
+
+// The features enabled in each service, in label order. Services without features are omitted.
+func expandFeatures(project *types.Project) map[string][]Feature {
+	features := map[string][]Feature{}
+	for name, service := range project.Services {
+		for _, label := range slices.Sorted(maps.Keys(service.Labels)) {
+			feature, ok := balenaFeatures[label]
+			if enabled, _ := parseFeatureValue(service.Labels[label]); ok && enabled {
+				feature.Label = label
+				features[name] = append(features[name], feature)
+			}
+		}
+	}
+	return features
+}
+
+// Bind mounts implied by each io.balena.features label, which the supervisor adds itself. The balena-socket
+// label is listed once for each engine socket path, as either implies it.
+var balenaFeatureMounts = []struct {
+	label  string
+	mounts []string
+}{
+	{"io.balena.features.balena-socket", []string{"/var/run/docker.sock"}},
+	{"io.balena.features.balena-socket", []string{"/var/run/balena-engine.sock"}},
+	{"io.balena.features.dbus", []string{"/run/dbus"}},
+	{"io.balena.features.sysfs", []string{"/sys"}},
+	{"io.balena.features.procfs", []string{"/proc"}},
+	{"io.balena.features.kernel-modules", []string{"/lib/modules"}},
+	{"io.balena.features.firmware", []string{"/lib/firmware"}},
+	{"io.balena.features.journal-logs", []string{"/var/log/journal", "/run/log/journal", "/etc/machine-id"}},
+}
+
+// Whether a bind mount source is implied by an io.balena.features label
+func isBalenaFeatureMount(source string) bool {
+	for _, feature := range balenaFeatureMounts {
+		if slices.Contains(feature.mounts, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// The io.balena.features labels implied by bind mount sources, where every mount of a label is present
+func balenaFeatureLabels(sources []string) []string {
+	var labels []string
+	for _, feature := range balenaFeatureMounts {
+		if !slices.Contains(labels, feature.label) && !slices.ContainsFunc(feature.mounts, func(mount string) bool {
+			return !slices.Contains(sources, mount)
+		}) {
+			labels = append(labels, feature.label)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseFeatureValue(t *testing.T) {
+	tests := []struct {
+		value   string
+		enabled bool
+		ok      bool
+	}{
+		{value: "1", enabled: true, ok: true},
+		{value: "TRUE", enabled: true, ok: true},
+		{value: "on", enabled: true, ok: true},
+		{value: "0", ok: true},
+		{value: "false", ok: true},
+		{value: "Off", ok: true},
+		{value: "yes"},
+		{value: ""},
+	}
+	for _, tt := range tests {
+		if enabled, ok := parseFeatureValue(tt.value); enabled != tt.enabled || ok != tt.ok {
+			t.Errorf("expected %q to be enabled %t and valid %t, got %t and %t", tt.value, tt.enabled, tt.ok, enabled, ok)
+		}
+	}
+}
+
+func TestExpandFeatures(t *testing.T) {
+	result := mustParse(t, Options{ExpandFeatures: true, Warnings: true}, "services:\n"+
+		"  web:\n    image: nginx\n    labels:\n      io.balena.features.dbus: \"1\"\n      io.balena.features.sysfs: \"0\"\n      io.balena.features.gpu: \"true\"\n      io.balena.features.unknown: \"1\"\n"+
+		"  db:\n    image: postgres\n")
+	expected := map[string][]Feature{"web": {
+		{Label: "io.balena.features.dbus", Mounts: []string{"/run/dbus:/host/run/dbus"}, Environment: map[string]*string{"DBUS_SYSTEM_BUS_ADDRESS": ptr("unix:path=/host/run/dbus/system_bus_socket")}},
+		{Label: "io.balena.features.gpu", GPU: true},
+	}}
+	if !reflect.DeepEqual(result.Features, expected) {
+		t.Errorf("expected the enabled features of each service, got %+v", result.Features)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].Code != UnknownFeatureCode || result.Warnings[0].Location.Path != "services.web.labels.io.balena.features.unknown" {
+		t.Errorf("expected a warning for the unknown feature, got %+v", result.Warnings)
+	}
+
+	_, err := parse(t, Options{ExpandFeatures: true}, "services:\n  web:\n    image: nginx\n    labels:\n      io.balena.features.dbus: \"yes\"\n")
+	if parserErr := expectError(t, err, ValidationError, "label io.balena.features.dbus must be"); parserErr.Code != InvalidFeatureValueCode {
+		t.Errorf("expected the %s code, got %s", InvalidFeatureValueCode, parserErr.Code)
+	}
+	_, err = parse(t, Options{Target: BalenaTarget}, "services:\n  web:\n    image: nginx\n    labels:\n      io.balena.features.dbus: \"yes\"\n")
+	expectError(t, err, ValidationError, "label io.balena.features.dbus must be")
+	if result := mustParse(t, Options{}, "services:\n  web:\n    image: nginx\n    labels:\n      io.balena.features.dbus: \"yes\"\n"); result.Features != nil {
+		t.Errorf("expected features to be ignored without Options.ExpandFeatures, got %v", result.Features)
+	}
+}
+
+func TestBalenaFeatureMounts(t *testing.T) {
+	if !isBalenaFeatureMount("/var/run/docker.sock") || !isBalenaFeatureMount("/etc/machine-id") || isBalenaFeatureMount("/etc") {
+		t.Error("expected only the mounts of features to be feature mounts")
+	}
+	// Labels are implied once every mount of the feature is present
+	labels := balenaFeatureLabels([]string{"/var/run/docker.sock", "/var/run/balena-engine.sock", "/var/log/journal", "/sys"})
+	if !slices.Equal(labels, []string{"io.balena.features.balena-socket", "io.balena.features.sysfs"}) {
+		t.Errorf("expected the balena-socket and sysfs features, got %v", labels)
+	}
+
+	// Feature mounts are supported by balena, and converted to their labels in the target state
+	compose := "services:\n  web:\n    image: nginx\n    volumes: [\"/var/run/docker.sock:/var/run/docker.sock\", \"/lib/modules:/lib/modules\"]\n"
+	state, err := ToTargetState(mustParse(t, Options{Target: BalenaTarget, BalenaNormalize: true}, compose).Project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := state.Services["web"]
+	if !reflect.DeepEqual(web.Labels, map[string]string{"io.balena.features.balena-socket": "1", "io.balena.features.kernel-modules": "1"}) || web.Composition["volumes"] != nil {
+		t.Errorf("expected the mounts to be converted to feature labels, got %v and %v", web.Labels, web.Composition["volumes"])
+	}
+}
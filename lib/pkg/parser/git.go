@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Top-level extension recording the git source of a project parsed with ParseGit
+const gitSourceExtension = "x-git"
+
+// GitSource identifies a composition at a specific git reference
+type GitSource struct {
+	// Repository is the URL of the repository, in any form accepted by `git fetch`
+	Repository string `json:"repository"`
+	// Ref is the branch, tag or commit to parse
+	Ref string `json:"ref"`
+	// Subdir is the project directory within the repository, if not the root
+	Subdir string `json:"subdir,omitempty"`
+	// Commit is the SHA that Ref resolved to, set once fetched
+	Commit string `json:"commit,omitempty"`
+}
+
+// ParseGitSource parses a git reference of the form <repo>#<ref>[:subdir]
+func ParseGitSource(reference string) (*GitSource, error) {
+	i := strings.LastIndex(reference, "#")
+	if i <= 0 || i == len(reference)-1 {
+		return nil, fmt.Errorf("invalid git reference %q, expected <repo>#<ref>[:subdir]", reference)
+	}
+	source := &GitSource{Repository: reference[:i], Ref: reference[i+1:]}
+	// Refs can't contain colons, so the first one separates the subdir
+	if ref, subdir, ok := strings.Cut(source.Ref, ":"); ok {
+		source.Ref, source.Subdir = ref, subdir
+	}
+	if source.Ref == "" {
+		return nil, fmt.Errorf("invalid git reference %q, ref must not be empty", reference)
+	}
+	return source, nil
+}
+
+// ParseGit shallow clones the repository at the given reference and parses its compose files.
+// Compose file paths are relative to the project directory, and default to the compose files
+// compose-go discovers by name if empty. The resolved commit is set in the result, and recorded
+// in the project under the x-git extension.
+func (p *Parser) ParseGit(ctx context.Context, source GitSource, composeFiles []string) (*Result, error) {
+	// Repository and ref are passed to git as arguments, so must not be mistaken for options
+	if source.Repository == "" || source.Ref == "" || strings.HasPrefix(source.Repository, "-") || strings.HasPrefix(source.Ref, "-") {
+		return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Invalid git reference %s#%s", source.Repository, source.Ref)}
+	}
+
+	dir, err := os.MkdirTemp("", "balena-compose-parser-git-")
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to create clone directory: %v", err), Err: err}
+	}
+	defer os.RemoveAll(dir)
+
+	// Fetching the ref directly rather than cloning supports commit SHAs as well as branches and tags
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"fetch", "--quiet", "--depth", "1", "--no-tags", source.Repository, source.Ref},
+		{"-c", "advice.detachedHead=false", "checkout", "--quiet", "FETCH_HEAD"},
+	} {
+		if _, err := git(ctx, dir, args...); err != nil {
+			return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to fetch %s#%s: %v", source.Repository, source.Ref, err), Err: err}
+		}
+	}
+	commit, err := git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to resolve %s#%s: %v", source.Repository, source.Ref, err), Err: err}
+	}
+	source.Commit = commit
+
+	projectDir := dir
+	if source.Subdir != "" {
+		projectDir, err = containedPath(dir, source.Subdir)
+		if err != nil {
+			return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Invalid subdir %q", source.Subdir), Err: err}
+		}
+	}
+
+	var paths []string
+	for _, composeFile := range composeFiles {
+		local, err := containedPath(projectDir, composeFile)
+		if err != nil {
+			return nil, &Error{Name: ArgumentError, Message: err.Error(), Err: err}
+		}
+		paths = append(paths, local)
+	}
+	if len(paths) == 0 {
+		paths, err = defaultComposeFiles(projectDir)
+		if err != nil {
+			return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Repository doesn't contain a compose file: %v", err), Err: err}
+		}
+	}
+
+	result, err := p.Parse(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	result.Git = &source
+	if result.Project.Extensions == nil {
+		result.Project.Extensions = map[string]any{}
+	}
+	result.Project.Extensions[gitSourceExtension] = source
+	return result, nil
+}
+
+// Run a git command in dir, returning its trimmed stdout
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Never prompt for credentials, which would hang a non-interactive parse
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
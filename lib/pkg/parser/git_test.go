@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestParseGitSource(t *testing.T) {
+	tests := []struct {
+		reference string
+		expected  *GitSource
+	}{
+		{reference: "https://github.com/balena-io/app.git#main", expected: &GitSource{Repository: "https://github.com/balena-io/app.git", Ref: "main"}},
+		{reference: "git@github.com:balena-io/app.git#v1.0.0:deploy/arm", expected: &GitSource{Repository: "git@github.com:balena-io/app.git", Ref: "v1.0.0", Subdir: "deploy/arm"}},
+		{reference: "../app#a#b", expected: &GitSource{Repository: "../app#a", Ref: "b"}},
+		{reference: "https://github.com/balena-io/app.git"},
+		{reference: "#main"},
+		{reference: "app#"},
+		{reference: "app#:deploy"},
+	}
+	for _, tt := range tests {
+		source, err := ParseGitSource(tt.reference)
+		if tt.expected == nil {
+			if err == nil {
+				t.Errorf("expected %q to be invalid, got %+v", tt.reference, source)
+			}
+		} else if err != nil || !reflect.DeepEqual(source, tt.expected) {
+			t.Errorf("expected %q to be %+v, got %+v: %v", tt.reference, tt.expected, source, err)
+		}
+	}
+}
+
+// Create a repository of files committed on the main branch, returning its directory and the commit
+func gitRepository(t *testing.T, files map[string]string) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := writeFiles(t, files)
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "compose files"},
+	} {
+		if _, err := git(context.Background(), dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	commit, err := git(context.Background(), dir, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, commit
+}
+
+func TestParseGit(t *testing.T) {
+	repository, commit := gitRepository(t, map[string]string{
+		"docker-compose.yml":     "services:\n  web:\n    image: nginx\n",
+		"deploy/compose.yml":     "services:\n  web:\n    image: nginx\n    env_file: web.env\n",
+		"deploy/web.env":         "FROM_ENV_FILE=true\n",
+		"deploy/arm/compose.yml": "services:\n  web:\n    image: arm64v8/nginx\n",
+	})
+
+	tests := []struct {
+		name         string
+		source       GitSource
+		composeFiles []string
+		image        string
+	}{
+		{name: "branch", source: GitSource{Repository: repository, Ref: "main"}, image: "nginx"},
+		{name: "commit", source: GitSource{Repository: repository, Ref: commit}, image: "nginx"},
+		{name: "subdir", source: GitSource{Repository: repository, Ref: "main", Subdir: "deploy/arm"}, image: "arm64v8/nginx"},
+		{name: "compose files", source: GitSource{Repository: repository, Ref: "main", Subdir: "deploy"}, composeFiles: []string{"compose.yml"}, image: "nginx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{ProjectName: "test"}).ParseGit(context.Background(), tt.source, tt.composeFiles)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if image := result.Project.Services["web"].Image; image != tt.image {
+				t.Errorf("expected the image %s, got %s", tt.image, image)
+			}
+			expected := tt.source
+			expected.Commit = commit
+			if result.Git == nil || *result.Git != expected {
+				t.Errorf("expected the source %+v, got %+v", expected, result.Git)
+			}
+			if source, ok := result.Project.Extensions[gitSourceExtension].(GitSource); !ok || source != expected {
+				t.Errorf("expected the source to be recorded in %s, got %+v", gitSourceExtension, result.Project.Extensions)
+			}
+		})
+	}
+}
+
+func TestParseGitErrors(t *testing.T) {
+	repository, _ := gitRepository(t, map[string]string{"README.md": "#\n", "app/compose.yml": "services: {}\n"})
+	tests := []struct {
+		name         string
+		source       GitSource
+		composeFiles []string
+		errName      string
+		message      string
+	}{
+		{name: "option as repository", source: GitSource{Repository: "--upload-pack=touch", Ref: "main"}, errName: ArgumentError, message: "Invalid git reference"},
+		{name: "option as ref", source: GitSource{Repository: repository, Ref: "--help"}, errName: ArgumentError, message: "Invalid git reference"},
+		{name: "missing ref", source: GitSource{Repository: repository, Ref: "missing"}, errName: IOError, message: "Failed to fetch " + repository + "#missing"},
+		{name: "no compose file", source: GitSource{Repository: repository, Ref: "main"}, errName: ArgumentError, message: "Repository doesn't contain a compose file"},
+		{name: "escaping subdir", source: GitSource{Repository: repository, Ref: "main", Subdir: "../app"}, errName: ArgumentError, message: `Invalid subdir "../app"`},
+		{name: "escaping compose file", source: GitSource{Repository: repository, Ref: "main", Subdir: "app"}, composeFiles: []string{"../README.md"}, errName: ArgumentError, message: "outside of the project"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Options{ProjectName: "test"}).ParseGit(context.Background(), tt.source, tt.composeFiles)
+			expectError(t, err, tt.errName, tt.message)
+		})
+	}
+}
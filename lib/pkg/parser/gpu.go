@@ -0,0 +1,206 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// InvalidDeviceRequestCode is reported for gpus and deploy.resources.reservations.devices entries the
+// engine rejects when creating the container, e.g. of an unknown driver. compose-go already rejects
+// requests with both count and device_ids.
+const InvalidDeviceRequestCode = "invalid-device-request"
+
+// Device drivers of the engine: nvidia, which the engine selects for the gpu or nvidia capability if the
+// driver isn't set, and cdi, of the devices named in the CDI specs of the host
+const (
+	nvidiaDriver = "nvidia"
+	cdiDriver    = "cdi"
+)
+
+// Capabilities selecting the nvidia driver
+var nvidiaSelectingCapabilities = []string{"gpu", "nvidia"}
+
+// Capabilities of the nvidia driver, which the engine ignores unless they select it or are capabilities of
+// the NVIDIA container toolkit
+var nvidiaCapabilities = []string{"gpu", "nvidia", "compute", "compat32", "display", "graphics", "utility", "video"}
+
+// Capabilities the NVIDIA container toolkit of Jetson devices doesn't mount the libraries of, as L4T has
+// no 32-bit libraries and no display driver in containers
+var jetsonUnsupportedCapabilities = []string{"compat32", "display"}
+
+// GPURequest is a request of a service for GPUs, set with gpus or as a device reservation with the gpu
+// capability, normalized to the request the engine receives
+type GPURequest struct {
+	// Path is the dot separated path of the request, e.g. services.ml.gpus.0
+	Path string `json:"path"`
+	// Driver is the device driver, nvidia unless set otherwise
+	Driver string `json:"driver"`
+	// Count is the number of GPUs, -1 for all of them, or 0 if DeviceIDs are set
+	Count int64 `json:"count"`
+	// DeviceIDs are the IDs or UUIDs of the GPUs, if set rather than Count
+	DeviceIDs []string `json:"deviceIds,omitempty"`
+	// Capabilities are the sorted capabilities the GPUs are requested with, including gpu if set with gpus
+	Capabilities []string `json:"capabilities"`
+	// Options are the options of the driver
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// A device request of a service, with its path and whether it was set with gpus, which implies the gpu
+// capability
+type deviceRequest struct {
+	path    string
+	request types.DeviceRequest
+	gpus    bool
+}
+
+// The device requests of a service, those of gpus then the device reservations
+func deviceRequests(path string, service types.ServiceConfig) []deviceRequest {
+	var requests []deviceRequest
+	for i, request := range service.Gpus {
+		requests = append(requests, deviceRequest{fmt.Sprintf("%s.gpus.%d", path, i), request, true})
+	}
+	if service.Deploy != nil && service.Deploy.Resources.Reservations != nil {
+		for i, request := range service.Deploy.Resources.Reservations.Devices {
+			requests = append(requests, deviceRequest{fmt.Sprintf("%s.deploy.resources.reservations.devices.%d", path, i), request, false})
+		}
+	}
+	return requests
+}
+
+// The capabilities of a device request, sorted and with gpu if set with gpus
+func (r deviceRequest) capabilities() []string {
+	capabilities := slices.Clone(r.request.Capabilities)
+	if r.gpus {
+		capabilities = append(capabilities, "gpu")
+	}
+	slices.Sort(capabilities)
+	return slices.Compact(capabilities)
+}
+
+// Whether a device request is for GPUs, which the engine gives the nvidia driver
+func (r deviceRequest) isGPU() bool {
+	return r.request.Driver == nvidiaDriver || r.request.Driver == "" && r.selectsNvidia()
+}
+
+// Whether a device request without a driver has a capability selecting the nvidia driver
+func (r deviceRequest) selectsNvidia() bool {
+	return slices.ContainsFunc(r.capabilities(), func(capability string) bool {
+		return slices.Contains(nvidiaSelectingCapabilities, capability)
+	})
+}
+
+// Check the device requests of every service are ones the engine can create the container with,
+// returning a ValidationError listing each invalid request
+func checkDeviceRequests(project *types.Project, composeFiles []string) *Error {
+	var issues []targetIssue
+	fail := func(path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: InvalidDeviceRequestCode, path: path, message: fmt.Sprintf(format, args...)})
+	}
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		for _, r := range deviceRequests("services."+name, project.Services[name]) {
+			request := r.request
+			if request.Count < -1 {
+				fail(r.path+".count", "%s.count must be a positive number or all, got %d", r.path, request.Count)
+			}
+			switch request.Driver {
+			case "":
+				if !r.selectsNvidia() {
+					fail(r.path+".capabilities", "%s.capabilities: the engine has no device driver with the capabilities %s, set driver or include gpu",
+						r.path, strings.Join(r.capabilities(), ", "))
+					continue
+				}
+				fallthrough
+			case nvidiaDriver:
+				for _, capability := range r.capabilities() {
+					if !slices.Contains(nvidiaCapabilities, capability) {
+						fail(r.path+".capabilities", "%s.capabilities: %s isn't a capability of the %s driver, expected one of: %s",
+							r.path, capability, nvidiaDriver, strings.Join(nvidiaCapabilities, ", "))
+					}
+				}
+			case cdiDriver:
+				if request.Count != 0 || len(request.IDs) == 0 {
+					fail(r.path, "%s: the %s driver only accepts devices by name, set with device_ids", r.path, cdiDriver)
+				}
+				for _, id := range request.IDs {
+					if kind, device, ok := strings.Cut(id, "="); !ok || !strings.Contains(kind, "/") || device == "" {
+						fail(r.path+".device_ids", "%s.device_ids: %q isn't a CDI device name, of the form vendor/class=name", r.path, id)
+					}
+				}
+			default:
+				fail(r.path+".driver", "%s.driver: the engine has no device driver %q, expected %s or %s", r.path, request.Driver, nvidiaDriver, cdiDriver)
+			}
+		}
+	}
+	err, _ := reportIssues("Invalid device requests", issues, composeFiles)
+	return err
+}
+
+// Whether devices of an architecture and device type, which is the architecture if only that is known,
+// can have NVIDIA GPUs: devices of GPU architectures, except aarch64 devices other than Jetsons
+func gpuDevice(arch, device string) bool {
+	if !slices.Contains(gpuArchitectures, arch) {
+		return false
+	}
+	_, known := deviceTypeArchitectures[device]
+	return arch != "aarch64" || !known || isJetson(device)
+}
+
+// Whether a device type is a Jetson, whose single GPU is integrated
+func isJetson(device string) bool {
+	return strings.HasPrefix(device, "jetson-")
+}
+
+// The issues of the GPU requests of a service on Jetson devices, which have a single integrated GPU and
+// don't support all capabilities
+func jetsonGPUIssues(path string, service types.ServiceConfig, device string) []targetIssue {
+	var issues []targetIssue
+	for _, r := range deviceRequests(path, service) {
+		if !r.isGPU() {
+			continue
+		}
+		if r.request.Count > 1 || len(r.request.IDs) > 1 {
+			issues = append(issues, targetIssue{code: IncompatibleOptionCode, path: r.path, message: fmt.Sprintf(
+				"%s requests more than one GPU, but %s devices have a single integrated GPU", r.path, device)})
+		}
+		for _, capability := range r.capabilities() {
+			if slices.Contains(jetsonUnsupportedCapabilities, capability) {
+				issues = append(issues, targetIssue{code: IncompatibleOptionCode, path: r.path + ".capabilities", message: fmt.Sprintf(
+					"%s.capabilities: %s isn't supported on %s devices", r.path, capability, device)})
+			}
+		}
+	}
+	return issues
+}
+
+// The GPU requests of each service with any, in order of the gpus then the device reservations, with
+// the driver, count and capabilities the engine defaults
+func gpuRequests(project *types.Project) map[string][]GPURequest {
+	gpus := map[string][]GPURequest{}
+	for name, service := range project.Services {
+		for _, r := range deviceRequests("services."+name, service) {
+			if !r.isGPU() {
+				continue
+			}
+			gpu := GPURequest{
+				Path:         r.path,
+				Driver:       nvidiaDriver,
+				Count:        int64(r.request.Count),
+				DeviceIDs:    r.request.IDs,
+				Capabilities: r.capabilities(),
+			}
+			// The engine requests all GPUs if neither the count nor the IDs are set
+			if gpu.Count == 0 && len(gpu.DeviceIDs) == 0 {
+				gpu.Count = -1
+			}
+			if len(r.request.Options) > 0 {
+				gpu.Options = maps.Clone(map[string]string(r.request.Options))
+			}
+			gpus[name] = append(gpus[name], gpu)
+		}
+	}
+	return gpus
+}
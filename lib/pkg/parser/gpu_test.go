@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeviceRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		devices  string
+		expected []string
+	}{
+		{name: "gpu capability", devices: "[{capabilities: [gpu], count: 1}]"},
+		{name: "nvidia driver", devices: "[{driver: nvidia, capabilities: [compute, utility], device_ids: [\"0\"]}]"},
+		{name: "cdi devices", devices: "[{driver: cdi, capabilities: [gpu], device_ids: [nvidia.com/gpu=all]}]"},
+		{
+			name:     "count",
+			devices:  "[{capabilities: [gpu], count: -2}]",
+			expected: []string{"invalid-device-request services.ml.deploy.resources.reservations.devices.0.count"},
+		},
+		{
+			name:     "no driver",
+			devices:  "[{capabilities: [tpu]}]",
+			expected: []string{"invalid-device-request services.ml.deploy.resources.reservations.devices.0.capabilities"},
+		},
+		{
+			name:     "nvidia capability",
+			devices:  "[{driver: nvidia, capabilities: [gpu, tpu]}]",
+			expected: []string{"invalid-device-request services.ml.deploy.resources.reservations.devices.0.capabilities"},
+		},
+		{
+			name:     "cdi count",
+			devices:  "[{driver: cdi, capabilities: [gpu], count: 1}]",
+			expected: []string{"invalid-device-request services.ml.deploy.resources.reservations.devices.0"},
+		},
+		{
+			name:     "cdi device name",
+			devices:  "[{driver: cdi, capabilities: [gpu], device_ids: [nvidia.com/gpu, gpu=0]}]",
+			expected: []string{"invalid-device-request services.ml.deploy.resources.reservations.devices.0.device_ids", "invalid-device-request services.ml.deploy.resources.reservations.devices.0.device_ids"},
+		},
+		{
+			name:     "unknown driver",
+			devices:  "[{driver: amd, capabilities: [gpu]}]",
+			expected: []string{"invalid-device-request services.ml.deploy.resources.reservations.devices.0.driver"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, _ := targetIssues(t, Options{}, "services:\n  ml:\n    image: nvcr.io/nvidia/pytorch\n    deploy:\n      resources:\n        reservations:\n          devices: "+tt.devices+"\n")
+			if strings.Join(errs, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("expected %v, got %v", tt.expected, errs)
+			}
+		})
+	}
+
+	_, err := parse(t, Options{}, "services:\n  ml:\n    image: nvcr.io/nvidia/pytorch\n    deploy:\n      resources:\n        reservations:\n          devices: [{driver: amd, capabilities: [gpu]}]\n")
+	expectError(t, err, ValidationError, `Invalid device requests: services.ml.deploy.resources.reservations.devices.0.driver: the engine has no device driver "amd", expected nvidia or cdi`)
+	_, err = parse(t, Options{}, "services:\n  ml:\n    image: nvcr.io/nvidia/pytorch\n    gpus: [{count: 1, device_ids: [\"0\"]}]\n")
+	expectError(t, err, ParseError, `"count" and "device_ids" attributes are exclusive`)
+}
+
+func TestGPURequests(t *testing.T) {
+	result := mustParse(t, Options{GPU: true}, "services:\n"+
+		"  ml:\n    image: nvcr.io/nvidia/pytorch\n    gpus: all\n"+
+		"    deploy:\n      resources:\n        reservations:\n          devices:\n"+
+		"            - capabilities: [utility, gpu]\n              device_ids: [\"0\", GPU-1]\n              options: {mig: \"1\"}\n"+
+		"            - driver: cdi\n              capabilities: [gpu]\n              device_ids: [nvidia.com/gpu=all]\n"+
+		"  web:\n    image: nginx\n")
+	expected := map[string][]GPURequest{
+		"ml": {
+			{Path: "services.ml.gpus.0", Driver: "nvidia", Count: -1, Capabilities: []string{"gpu"}},
+			{Path: "services.ml.deploy.resources.reservations.devices.0", Driver: "nvidia", DeviceIDs: []string{"0", "GPU-1"}, Capabilities: []string{"gpu", "utility"}, Options: map[string]string{"mig": "1"}},
+		},
+	}
+	if !reflect.DeepEqual(result.GPU, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result.GPU)
+	}
+
+	// The engine requests all GPUs if neither the count nor the IDs are set
+	result = mustParse(t, Options{GPU: true}, "services:\n  ml:\n    image: nvcr.io/nvidia/pytorch\n    deploy:\n      resources:\n        reservations:\n          devices: [{capabilities: [nvidia]}]\n")
+	if gpus := result.GPU["ml"]; len(gpus) != 1 || gpus[0].Count != -1 || gpus[0].Driver != "nvidia" {
+		t.Errorf("expected all GPUs of the nvidia driver, got %+v", gpus)
+	}
+	if result := mustParse(t, Options{}, "services:\n  ml:\n    image: nvcr.io/nvidia/pytorch\n    gpus: all\n"); result.GPU != nil {
+		t.Errorf("expected no GPU requests without Options.GPU, got %+v", result.GPU)
+	}
+}
+
+func TestDeviceGPUs(t *testing.T) {
+	compose := "services:\n  ml:\n    image: nvcr.io/nvidia/pytorch\n    runtime: nvidia\n" +
+		"    deploy:\n      resources:\n        reservations:\n          devices: [{capabilities: [gpu, display], count: 2}]\n"
+	tests := []struct {
+		device   string
+		expected []string
+	}{
+		{device: "generic-amd64"},
+		{
+			device: "jetson-orin-nano-devkit-nvme",
+			expected: []string{
+				"incompatible-option services.ml.deploy.resources.reservations.devices.0",
+				"incompatible-option services.ml.deploy.resources.reservations.devices.0.capabilities",
+			},
+		},
+		{
+			device: "raspberrypi4-64",
+			expected: []string{
+				"incompatible-option services.ml.runtime",
+				"incompatible-option services.ml.deploy.resources.reservations.devices.0",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.device, func(t *testing.T) {
+			errs, _ := targetIssues(t, Options{DeviceType: tt.device}, compose)
+			if strings.Join(errs, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("expected %v, got %v", tt.expected, errs)
+			}
+		})
+	}
+}
+
+func TestGPUDevice(t *testing.T) {
+	tests := []struct {
+		arch     string
+		device   string
+		expected bool
+	}{
+		{arch: "amd64", device: "generic-amd64", expected: true},
+		{arch: "aarch64", device: "jetson-xavier", expected: true},
+		{arch: "aarch64", device: "raspberrypi4-64"},
+		// Devices described by their architecture may be Jetsons
+		{arch: "aarch64", device: "aarch64", expected: true},
+		{arch: "armv7hf", device: "armv7hf"},
+	}
+	for _, tt := range tests {
+		if gpu := gpuDevice(tt.arch, tt.device); gpu != tt.expected {
+			t.Errorf("expected %s devices of %s to have GPUs %t, got %t", tt.device, tt.arch, tt.expected, gpu)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// ServiceHash is a stable SHA256 of a service's configuration, as "docker compose config --hash"
+// computes it. Fields which don't require the container to be recreated when changed, such as the
+// build section, scale and dependencies, are excluded.
+func ServiceHash(service types.ServiceConfig) (string, error) {
+	service.Build = nil
+	service.PullPolicy = ""
+	service.Scale = nil
+	service.DependsOn = nil
+	service.Profiles = nil
+	if service.Deploy != nil {
+		deploy := *service.Deploy
+		deploy.Replicas = nil
+		service.Deploy = &deploy
+	}
+	serviceJSON, err := json.Marshal(service)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(serviceJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ServiceHashes are the hashes of the named services of a project by name, or of every service if names is empty
+func ServiceHashes(project *types.Project, names []string) (map[string]string, error) {
+	if len(names) == 0 {
+		names = slices.Collect(maps.Keys(project.Services))
+	}
+	hashes := map[string]string{}
+	for _, name := range names {
+		service, ok := project.Services[name]
+		if !ok {
+			return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("No such service: %s", name)}
+		}
+		hash, err := ServiceHash(service)
+		if err != nil {
+			return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to hash service %s: %v", name, err), Err: err}
+		}
+		hashes[name] = hash
+	}
+	return hashes, nil
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestServiceHash(t *testing.T) {
+	base := "services:\n  web:\n    image: nginx\n    environment:\n      TAG: latest\n    deploy:\n      replicas: 1\n"
+	hash := func(t *testing.T, compose string) string {
+		t.Helper()
+		hashes, err := ServiceHashes(mustParse(t, Options{SkipConsistency: true, Profiles: []string{"*"}}, compose).Project, []string{"web"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return hashes["web"]
+	}
+	expected := hash(t, base)
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(expected) {
+		t.Fatalf("expected a SHA256, got %q", expected)
+	}
+
+	tests := []struct {
+		name      string
+		compose   string
+		recreated bool
+	}{
+		{name: "same configuration", compose: base},
+		{name: "build", compose: base + "    build: .\n"},
+		{name: "pull policy", compose: base + "    pull_policy: always\n"},
+		{name: "scale", compose: base + "    scale: 2\n"},
+		{name: "dependencies", compose: base + "    depends_on: [db]\n"},
+		{name: "profiles", compose: base + "    profiles: [debug]\n"},
+		{name: "replicas", compose: strings.Replace(base, "replicas: 1", "replicas: 3", 1)},
+		{name: "image", compose: strings.Replace(base, "nginx", "nginx:1.25", 1), recreated: true},
+		{name: "environment", compose: strings.Replace(base, "latest", "\"1.25\"", 1), recreated: true},
+		{name: "resources", compose: base + "      resources:\n        limits:\n          memory: 512M\n", recreated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := hash(t, tt.compose) != expected; changed != tt.recreated {
+				t.Errorf("expected the hash to change %t, got %t", tt.recreated, changed)
+			}
+		})
+	}
+}
+
+func TestServiceHashes(t *testing.T) {
+	project := mustParse(t, Options{}, "services:\n  web:\n    image: nginx\n    deploy:\n      replicas: 2\n  db:\n    image: postgres\n").Project
+	hashes, err := ServiceHashes(project, nil)
+	if err != nil || len(hashes) != 2 || hashes["web"] == hashes["db"] {
+		t.Errorf("expected a hash of each service, got %v: %v", hashes, err)
+	}
+	// Hashing doesn't modify the project
+	if replicas := project.Services["web"].Deploy.Replicas; replicas == nil || *replicas != 2 {
+		t.Errorf("expected the replicas to be kept, got %v", replicas)
+	}
+
+	_, err = ServiceHashes(project, []string{"web", "missing"})
+	expectError(t, err, ArgumentError, "No such service: missing")
+}
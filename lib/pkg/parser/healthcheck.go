@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Codes of the issues of service healthchecks
+const (
+	// InvalidHealthcheckCode is reported for healthchecks the engine rejects or runs other than intended
+	InvalidHealthcheckCode = "invalid-healthcheck"
+	// MissingHealthcheckToolCode is reported for healthcheck commands running a tool the image of the
+	// service likely lacks, e.g. curl in alpine
+	MissingHealthcheckToolCode = "missing-healthcheck-tool"
+)
+
+// The first supervisor version supporting each healthcheck field which not every supervisor supports
+var supervisorHealthcheckFields = map[string]string{
+	"start_period":   "12.0.0",
+	"start_interval": "16.6.0",
+}
+
+// Shortest duration the engine accepts for the durations of healthchecks, other than 0 for its default
+const minimumHealthcheckDuration = time.Millisecond
+
+// Tools healthcheck commands commonly run, and those minimal images include, by image family. Images of
+// other families aren't checked.
+var (
+	healthcheckTools  = []string{"curl", "wget", "nc", "bash"}
+	minimalImageTools = map[string][]string{
+		// busybox provides wget and nc
+		"alpine":     {"wget", "nc"},
+		"busybox":    {"wget", "nc"},
+		"distroless": {},
+		"scratch":    {},
+	}
+)
+
+// The issues of the healthcheck fields of a service as output which the supervisor version set with
+// Options.SupervisorVersion doesn't support, or warnings if it's unset
+func supervisorHealthcheckIssues(servicePath string, healthcheck map[string]any, supervisorVersion string) []targetIssue {
+	var issues []targetIssue
+	version, _ := parseVersion(supervisorVersion)
+	for _, field := range sortedKeys(healthcheck) {
+		minimum, ok := supervisorHealthcheckFields[field]
+		if !ok {
+			continue
+		}
+		fieldPath := servicePath + ".healthcheck." + field
+		required, _ := parseVersion(minimum)
+		switch {
+		case supervisorVersion == "":
+			issues = append(issues, targetIssue{code: IncompatibleSupervisorCode, path: fieldPath, warning: true, message: fmt.Sprintf(
+				"%s requires supervisor v%s or later, which older devices ignore", fieldPath, minimum)})
+		case compareVersions(version, required) < 0:
+			issues = append(issues, targetIssue{code: IncompatibleSupervisorCode, path: fieldPath, message: fmt.Sprintf(
+				"%s requires supervisor v%s or later, targeting %s", fieldPath, minimum, supervisorVersion)})
+		}
+	}
+	return issues
+}
+
+// The issues of the form of the healthcheck of a service: the test must be NONE, or CMD or CMD-SHELL with
+// a command, durations at least a millisecond, and retries at least one
+func healthcheckIssues(servicePath string, service types.ServiceConfig) []targetIssue {
+	healthcheck := service.HealthCheck
+	if healthcheck == nil || healthcheck.Disable {
+		return nil
+	}
+	var issues []targetIssue
+	fail := func(field, format string, args ...any) {
+		issues = append(issues, targetIssue{code: InvalidHealthcheckCode, path: servicePath + ".healthcheck." + field, message: fmt.Sprintf(format, args...)})
+	}
+	path := servicePath + ".healthcheck"
+
+	if test := healthcheck.Test; len(test) > 0 {
+		switch test[0] {
+		case "NONE":
+			if len(test) > 1 {
+				fail("test", "%s.test NONE disables the healthcheck, so can't have a command", path)
+			}
+		case "CMD", "CMD-SHELL":
+			if len(test) == 1 || strings.TrimSpace(strings.Join(test[1:], "")) == "" {
+				fail("test", "%s.test %s must be followed by a command", path, test[0])
+			}
+			if test[0] == "CMD-SHELL" && len(test) > 2 {
+				fail("test", "%s.test CMD-SHELL takes the command as a single string, so only %q is run", path, test[1])
+			}
+		default:
+			fail("test", "%s.test must start with NONE, CMD or CMD-SHELL, or be a string run by the shell, got %q", path, test[0])
+		}
+	}
+	for _, duration := range []struct {
+		field string
+		value *types.Duration
+	}{{"interval", healthcheck.Interval}, {"timeout", healthcheck.Timeout}, {"start_period", healthcheck.StartPeriod}, {"start_interval", healthcheck.StartInterval}} {
+		if duration.value != nil && *duration.value != 0 && time.Duration(*duration.value) < minimumHealthcheckDuration {
+			fail(duration.field, "%s.%s must be at least 1ms, got %s", path, duration.field, time.Duration(*duration.value))
+		}
+	}
+	if interval, timeout := healthcheck.Interval, healthcheck.Timeout; interval != nil && timeout != nil && *interval != 0 && *timeout > *interval {
+		fail("timeout", "%s.timeout %s is longer than the interval %s, so checks overlap", path, time.Duration(*timeout), time.Duration(*interval))
+	}
+	if healthcheck.Retries != nil && *healthcheck.Retries == 0 {
+		fail("retries", "%s.retries must be at least 1, as 0 uses the default of 3 retries", path)
+	}
+	return issues
+}
+
+// The issues of healthcheck commands of a service running a tool its image likely lacks, as it's of a
+// minimal image family, e.g. curl in alpine. Built images may install it, so aren't checked.
+func healthcheckToolIssues(servicePath string, service types.ServiceConfig) []targetIssue {
+	healthcheck := service.HealthCheck
+	if healthcheck == nil || healthcheck.Disable || len(healthcheck.Test) < 2 || service.Build != nil {
+		return nil
+	}
+	family := imageFamily(service.Image)
+	tools, ok := minimalImageTools[family]
+	if !ok {
+		return nil
+	}
+	command := healthcheck.Test[1]
+	if healthcheck.Test[0] == "CMD-SHELL" {
+		command, _, _ = strings.Cut(strings.TrimSpace(command), " ")
+	}
+	tool := path.Base(command)
+	if !slices.Contains(healthcheckTools, tool) || slices.Contains(tools, tool) {
+		return nil
+	}
+	testPath := servicePath + ".healthcheck.test"
+	return []targetIssue{{code: MissingHealthcheckToolCode, path: testPath, message: fmt.Sprintf(
+		"%s runs %s, which %s images don't include, so the service never becomes healthy", testPath, tool, family)}}
+}
+
+// The minimal image family of an image, e.g. alpine for nginx:1.25-alpine, or "" if it isn't one
+func imageFamily(image string) string {
+	_, repository, tag, _ := imageComponents(image)
+	if image == "scratch" {
+		return "scratch"
+	}
+	switch name := path.Base(repository); {
+	case strings.Contains(repository, "distroless"):
+		return "distroless"
+	case name == "alpine" || name == "busybox":
+		return name
+	case strings.Contains(tag, "alpine"):
+		return "alpine"
+	}
+	return ""
+}
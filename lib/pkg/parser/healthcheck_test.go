@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHealthcheckIssues(t *testing.T) {
+	tests := []struct {
+		name        string
+		healthcheck string
+		expected    []string
+	}{
+		{name: "valid", healthcheck: "test: [CMD, pg_isready]\n      interval: 30s\n      timeout: 5s\n      retries: 3"},
+		{name: "shell", healthcheck: "test: pg_isready -U postgres"},
+		{name: "disabled", healthcheck: "disable: true"},
+		{name: "none", healthcheck: "test: [NONE, pg_isready]", expected: []string{"services.db.healthcheck.test NONE disables the healthcheck, so can't have a command"}},
+		{name: "no command", healthcheck: "test: [CMD-SHELL, \" \"]", expected: []string{"services.db.healthcheck.test CMD-SHELL must be followed by a command"}},
+		{name: "shell arguments", healthcheck: "test: [CMD-SHELL, pg_isready, -U, postgres]", expected: []string{`services.db.healthcheck.test CMD-SHELL takes the command as a single string, so only "pg_isready" is run`}},
+		{name: "form", healthcheck: "test: [pg_isready]", expected: []string{`services.db.healthcheck.test must start with NONE, CMD or CMD-SHELL, or be a string run by the shell, got "pg_isready"`}},
+		{name: "durations", healthcheck: "test: [CMD, pg_isready]\n      interval: 500us\n      start_period: 0s", expected: []string{"services.db.healthcheck.interval must be at least 1ms, got 500µs"}},
+		{name: "timeout", healthcheck: "test: [CMD, pg_isready]\n      interval: 10s\n      timeout: 1m", expected: []string{"services.db.healthcheck.timeout 1m0s is longer than the interval 10s, so checks overlap"}},
+		{name: "retries", healthcheck: "test: [CMD, pg_isready]\n      retries: 0", expected: []string{"services.db.healthcheck.retries must be at least 1, as 0 uses the default of 3 retries"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Parsed as the lint subcommand parses, as compose-go's consistency checks reject the test's form
+			service := mustParse(t, Options{SkipConsistency: true}, "services:\n  db:\n    image: postgres\n    healthcheck:\n      "+tt.healthcheck+"\n").Project.Services["db"]
+			var messages []string
+			for _, issue := range healthcheckIssues("services.db", service) {
+				if issue.code != InvalidHealthcheckCode {
+					t.Errorf("expected %s, got %s", InvalidHealthcheckCode, issue.code)
+				}
+				messages = append(messages, issue.message)
+			}
+			if !reflect.DeepEqual(messages, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, messages)
+			}
+		})
+	}
+}
+
+func TestHealthcheckTools(t *testing.T) {
+	tests := []struct {
+		image    string
+		test     string
+		build    bool
+		expected string
+	}{
+		{image: "nginx:1.25-alpine", test: "[CMD, curl, -f, http://localhost]", expected: "services.web.healthcheck.test runs curl, which alpine images don't include, so the service never becomes healthy"},
+		{image: "alpine:3.19", test: "[CMD-SHELL, \"/usr/bin/curl -f http://localhost || exit 1\"]", expected: "services.web.healthcheck.test runs curl, which alpine images don't include, so the service never becomes healthy"},
+		{image: "gcr.io/distroless/static", test: "[CMD, wget, localhost]", expected: "services.web.healthcheck.test runs wget, which distroless images don't include, so the service never becomes healthy"},
+		// busybox provides wget, tools which aren't common aren't checked, and neither are built images
+		{image: "busybox", test: "[CMD, wget, localhost]"},
+		{image: "alpine", test: "[CMD, /app/check]"},
+		{image: "nginx:1.25", test: "[CMD, curl, localhost]"},
+		{image: "app:alpine", test: "[CMD, curl, localhost]", build: true},
+	}
+	for _, tt := range tests {
+		compose := "services:\n  web:\n    image: " + tt.image + "\n    healthcheck:\n      test: " + tt.test + "\n"
+		if tt.build {
+			compose += "    build: .\n"
+		}
+		service := mustParse(t, Options{}, compose).Project.Services["web"]
+		var message string
+		for _, issue := range healthcheckToolIssues("services.web", service) {
+			message = issue.message
+		}
+		if message != tt.expected {
+			t.Errorf("expected %q for %s running %s, got %q", tt.expected, tt.image, tt.test, message)
+		}
+	}
+}
+
+func TestImageFamily(t *testing.T) {
+	for image, expected := range map[string]string{
+		"alpine":                          "alpine",
+		"library/busybox:1.36":            "busybox",
+		"nginx:1.25-alpine3.19":           "alpine",
+		"gcr.io/distroless/base-debian12": "distroless",
+		"scratch":                         "scratch",
+		"nginx:1.25":                      "",
+		"alpine-tools/node:20":            "",
+	} {
+		if family := imageFamily(image); family != expected {
+			t.Errorf("expected %s to be of family %q, got %q", image, expected, family)
+		}
+	}
+}
+
+func TestSupervisorHealthcheck(t *testing.T) {
+	compose := "services:\n  db:\n    image: postgres\n    healthcheck:\n      test: [CMD, pg_isready]\n      start_period: 10s\n      start_interval: 1s\n"
+	// Without a version, fields older supervisors ignore are warnings
+	errs, warnings := targetIssues(t, Options{Target: BalenaTarget}, compose)
+	if expected := []string{"incompatible-supervisor services.db.healthcheck.start_interval", "incompatible-supervisor services.db.healthcheck.start_period"}; errs != nil || !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("expected %v, got %v and %v", expected, errs, warnings)
+	}
+	for _, tt := range []struct {
+		version string
+		errors  []string
+	}{
+		{version: "v11.14.0", errors: []string{"incompatible-supervisor services.db.healthcheck.start_interval", "incompatible-supervisor services.db.healthcheck.start_period"}},
+		{version: "v14.0.0", errors: []string{"incompatible-supervisor services.db.healthcheck.start_interval"}},
+		{version: "v16.6.0"},
+	} {
+		if errs, _ := targetIssues(t, Options{Target: BalenaTarget, SupervisorVersion: tt.version}, compose); !reflect.DeepEqual(errs, tt.errors) {
+			t.Errorf("expected %v with supervisor %s, got %v", tt.errors, tt.version, errs)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// HostAccess is the access to the host a service requests, for auditing what a composition can touch
+// on the device
+type HostAccess struct {
+	// Service is the name of the service
+	Service string `json:"service"`
+	// Privileged is whether the service is privileged, giving access to every device and capability
+	Privileged bool `json:"privileged"`
+	// HostNetwork, HostPID and HostIPC are whether the service shares the host's network stack, process
+	// namespace and IPC namespace
+	HostNetwork bool `json:"hostNetwork"`
+	HostPID     bool `json:"hostPid"`
+	HostIPC     bool `json:"hostIpc"`
+	// BindMounts are the host paths bind mounted into the service, including those the supervisor
+	// mounts for io.balena.features labels, in path order
+	BindMounts []string `json:"bindMounts"`
+	// Devices are the host devices given to the service, including those of io.balena.features labels,
+	// in path order
+	Devices []string `json:"devices"`
+	// Capabilities are the capabilities added to the service, as written in cap_add
+	Capabilities []string `json:"capabilities"`
+	// Features are the io.balena.features labels enabled in the service, in label order
+	Features []string `json:"features"`
+}
+
+// ListHostAccess lists the host access requested by each service of a project, in service name order
+func ListHostAccess(project *types.Project) []HostAccess {
+	features := expandFeatures(project)
+	accesses := []HostAccess{}
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		service := project.Services[name]
+		access := HostAccess{
+			Service:      name,
+			Privileged:   service.Privileged,
+			HostNetwork:  service.NetworkMode == "host",
+			HostPID:      service.Pid == "host",
+			HostIPC:      service.Ipc == "host",
+			BindMounts:   []string{},
+			Devices:      []string{},
+			Capabilities: slices.Clone(service.CapAdd),
+			Features:     []string{},
+		}
+		if access.Capabilities == nil {
+			access.Capabilities = []string{}
+		}
+		for _, volume := range service.Volumes {
+			if volume.Type == types.VolumeTypeBind {
+				access.BindMounts = append(access.BindMounts, volume.Source)
+			}
+		}
+		for _, device := range service.Devices {
+			access.Devices = append(access.Devices, device.Source)
+		}
+		// Mounts and devices of features are <host path>:<container path>[:options]
+		hostPath := func(mount string) string {
+			path, _, _ := strings.Cut(mount, ":")
+			return path
+		}
+		for _, feature := range features[name] {
+			access.Features = append(access.Features, feature.Label)
+			for _, mount := range feature.Mounts {
+				access.BindMounts = append(access.BindMounts, hostPath(mount))
+			}
+			for _, device := range feature.Devices {
+				access.Devices = append(access.Devices, hostPath(device))
+			}
+		}
+		slices.Sort(access.BindMounts)
+		access.BindMounts = slices.Compact(access.BindMounts)
+		slices.Sort(access.Devices)
+		access.Devices = slices.Compact(access.Devices)
+		accesses = append(accesses, access)
+	}
+	return accesses
+}
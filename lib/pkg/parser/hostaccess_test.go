@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestListHostAccess(t *testing.T) {
+	project := mustParse(t, Options{}, "services:\n"+
+		"  web:\n    image: nginx\n    volumes: [\"data:/data\", \"/var/log:/logs:ro\", \"/sys:/sys\"]\n"+
+		"    labels:\n      io.balena.features.sysfs: \"1\"\n      io.balena.features.dbus: \"1\"\n"+
+		"  agent:\n    image: alpine\n    privileged: true\n    network_mode: host\n    pid: host\n    ipc: host\n"+
+		"    cap_add: [SYS_ADMIN, NET_ADMIN]\n    devices: [\"/dev/ttyUSB0:/dev/ttyUSB0\", \"/dev/i2c-1\"]\n"+
+		"  db:\n    image: postgres\n"+
+		"volumes:\n  data: {}\n",
+	).Project
+	expected := []HostAccess{
+		{
+			Service:      "agent",
+			Privileged:   true,
+			HostNetwork:  true,
+			HostPID:      true,
+			HostIPC:      true,
+			BindMounts:   []string{},
+			Devices:      []string{"/dev/i2c-1", "/dev/ttyUSB0"},
+			Capabilities: []string{"SYS_ADMIN", "NET_ADMIN"},
+			Features:     []string{},
+		},
+		{Service: "db", BindMounts: []string{}, Devices: []string{}, Capabilities: []string{}, Features: []string{}},
+		// Named volumes aren't host access, and the mounts of features are listed once with bind mounts
+		{
+			Service:      "web",
+			BindMounts:   []string{"/run/dbus", "/sys", "/var/log"},
+			Devices:      []string{},
+			Capabilities: []string{},
+			Features:     []string{"io.balena.features.dbus", "io.balena.features.sysfs"},
+		},
+	}
+	if accesses := ListHostAccess(project); !reflect.DeepEqual(accesses, expected) {
+		t.Errorf("expected %+v, got %+v", expected, accesses)
+	}
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/distribution/reference"
+)
+
+// Image is the image a service runs
+type Image struct {
+	// Service is the name of the service
+	Service string `json:"service"`
+	// Image is the image as written in the compose files, or the name docker compose tags the built
+	// image with, <project>-<service>, if the service is built without one
+	Image string `json:"image"`
+	// Reference is the fully qualified reference to pull, e.g. docker.io/library/nginx:latest,
+	// or "" if the image isn't a valid reference or is unset
+	Reference string `json:"reference"`
+	// Registry, Repository, Tag and Digest are the components of the reference, e.g. docker.io, library/nginx
+	// and latest. The tag defaults to latest only for references without a digest, as in the engine.
+	Registry   string `json:"registry,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+	// Platform is the platform the image is pulled or built for, if set
+	Platform string `json:"platform,omitempty"`
+	// Build is whether the service has a build section, so the image may not exist in a registry
+	Build bool `json:"build"`
+}
+
+// Images lists the image of each service of a project, in service name order
+func Images(project *types.Project) []Image {
+	images := []Image{}
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		service := project.Services[name]
+		image := Image{Service: name, Image: service.Image, Platform: service.Platform, Build: service.Build != nil}
+		if image.Image == "" && image.Build {
+			image.Image = name
+			if project.Name != "" {
+				image.Image = project.Name + "-" + name
+			}
+		}
+		if named, err := reference.ParseDockerRef(image.Image); err == nil {
+			image.Reference = named.String()
+			image.Registry, image.Repository, image.Tag, image.Digest = imageComponents(image.Image)
+		}
+		images = append(images, image)
+	}
+	return images
+}
+
+// InvalidImageCode is reported by BalenaTarget for images which aren't valid references, so can't be
+// pulled by the supervisor or pushed to the balena registry
+const InvalidImageCode = "invalid-image"
+
+// Split a valid image reference into its registry, repository, tag and digest, defaulting the registry
+// to docker.io and the tag to latest if there's no digest
+func imageComponents(image string) (registry, repository, tag, digest string) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", "", "", ""
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		tag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		digest = digested.Digest().String()
+	} else if tag == "" {
+		tag = "latest"
+	}
+	return reference.Domain(named), reference.Path(named), tag, digest
+}
+
+// Check an image is a valid reference, which the balena registry also requires to be lowercase and
+// fully qualified names to be at most 255 characters, returning the issue if it isn't
+func imageIssue(path, image string) (targetIssue, bool) {
+	if _, err := reference.ParseNormalizedNamed(image); err != nil {
+		return targetIssue{code: InvalidImageCode, path: path, message: fmt.Sprintf("%s %q is not a valid image reference: %v", path, image, err)}, true
+	}
+	return targetIssue{}, false
+}
+
+// UnpinnedImageCode is reported for images pulled by the latest tag, whether set or implied, which may run
+// a different image each time a release is deployed
+const UnpinnedImageCode = "unpinned-image"
+
+// The issues of the images of services without a build which are pulled by the latest tag rather than a
+// tag or digest, in service name order
+func unpinnedImageIssues(project *types.Project) []targetIssue {
+	var issues []targetIssue
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		service := project.Services[name]
+		if service.Build != nil || service.Image == "" {
+			continue
+		}
+		_, _, tag, digest := imageComponents(service.Image)
+		if tag != "latest" || digest != "" {
+			continue
+		}
+		path := fmt.Sprintf("services.%s.image", name)
+		message := fmt.Sprintf("%s: %s has no tag, so it's pulled by latest, and releases aren't reproducible", path, service.Image)
+		if strings.HasSuffix(service.Image, ":latest") {
+			message = fmt.Sprintf("%s: %s is pulled by the latest tag, so releases aren't reproducible", path, service.Image)
+		}
+		issues = append(issues, targetIssue{code: UnpinnedImageCode, path: path, message: message + "; pin it to a version tag or digest"})
+	}
+	return issues
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImages(t *testing.T) {
+	project := mustParse(t, Options{SkipConsistency: true}, "services:\n"+
+		"  web:\n    image: nginx\n    platform: linux/arm64\n"+
+		"  api:\n    build: .\n"+
+		"  worker:\n    image: registry.example.com/team/worker:1.2\n    build: .\n"+
+		"  db:\n    image: postgres@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef\n",
+	).Project
+	expected := []Image{
+		{Service: "api", Image: "test-api", Reference: "docker.io/library/test-api:latest", Build: true},
+		{Service: "db", Image: "postgres@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", Reference: "docker.io/library/postgres@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		{Service: "web", Image: "nginx", Reference: "docker.io/library/nginx:latest", Platform: "linux/arm64"},
+		{Service: "worker", Image: "registry.example.com/team/worker:1.2", Reference: "registry.example.com/team/worker:1.2", Build: true},
+	}
+	images := Images(project)
+	if len(images) != len(expected) {
+		t.Fatalf("expected %d images, got %+v", len(expected), images)
+	}
+	for i, image := range images {
+		if image.Service != expected[i].Service || image.Image != expected[i].Image || image.Reference != expected[i].Reference || image.Platform != expected[i].Platform || image.Build != expected[i].Build {
+			t.Errorf("expected %+v, got %+v", expected[i], image)
+		}
+	}
+	// The components of each reference are listed separately
+	if db := images[1]; db.Registry != "docker.io" || db.Repository != "library/postgres" || db.Tag != "" || db.Digest != "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef" {
+		t.Errorf("expected the components of the digest reference, got %+v", db)
+	}
+	if worker := images[3]; worker.Registry != "registry.example.com" || worker.Repository != "team/worker" || worker.Tag != "1.2" {
+		t.Errorf("expected the components of the registry reference, got %+v", worker)
+	}
+
+	// Invalid references are listed without a reference to pull
+	images = Images(mustParse(t, Options{}, "services:\n  web:\n    image: Invalid:Image\n").Project)
+	if len(images) != 1 || images[0].Image != "Invalid:Image" || images[0].Reference != "" {
+		t.Errorf("expected the invalid image without a reference, got %+v", images)
+	}
+	if images := Images(mustParse(t, Options{}, "services: {}\n").Project); images == nil || len(images) != 0 {
+		t.Errorf("expected no images, got %#v", images)
+	}
+}
+
+func TestImageComponents(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected []string
+	}{
+		{image: "nginx", expected: []string{"docker.io", "library/nginx", "latest", ""}},
+		{image: "balenalib/raspberrypi4-64-node:18", expected: []string{"docker.io", "balenalib/raspberrypi4-64-node", "18", ""}},
+		{image: "registry.example.com:5000/team/api", expected: []string{"registry.example.com:5000", "team/api", "latest", ""}},
+		{image: "localhost/api:dev", expected: []string{"localhost", "api", "dev", ""}},
+		{image: "nginx:1.25@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", expected: []string{"docker.io", "library/nginx", "1.25", "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}},
+		{image: "Invalid:Image", expected: []string{"", "", "", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			registry, repository, tag, digest := imageComponents(tt.image)
+			if components := []string{registry, repository, tag, digest}; !reflect.DeepEqual(components, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, components)
+			}
+		})
+	}
+}
+
+func TestInvalidImages(t *testing.T) {
+	errs, _ := targetIssues(t, Options{Target: BalenaTarget}, "services:\n"+
+		"  web:\n    image: Nginx\n"+
+		"  api:\n    image: registry.example.com/"+strings.Repeat("a", 256)+"\n"+
+		"  db:\n    image: postgres:16\n")
+	if expected := []string{"invalid-image services.api.image", "invalid-image services.web.image"}; !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %v, got %v", expected, errs)
+	}
+	if _, invalid := imageIssue("services.web.image", "registry.example.com:5000/team/api:1.0"); invalid {
+		t.Error("expected the fully qualified reference to be valid")
+	}
+}
+
+func TestUnpinnedImages(t *testing.T) {
+	project := mustParse(t, Options{}, "services:\n"+
+		"  a:\n    image: nginx\n"+
+		"  b:\n    image: registry.example.com:5000/app:latest\n"+
+		"  c:\n    image: nginx:1.25\n"+
+		"  d:\n    image: nginx@sha256:0000000000000000000000000000000000000000000000000000000000000000\n"+
+		"  e:\n    image: app\n    build: .\n").Project
+	var messages []string
+	for _, issue := range unpinnedImageIssues(project) {
+		if issue.code != UnpinnedImageCode {
+			t.Errorf("expected %s, got %s", UnpinnedImageCode, issue.code)
+		}
+		messages = append(messages, issue.message)
+	}
+	// Images of services with a build aren't pulled
+	expected := []string{
+		"services.a.image: nginx has no tag, so it's pulled by latest, and releases aren't reproducible; pin it to a version tag or digest",
+		"services.b.image: registry.example.com:5000/app:latest is pulled by the latest tag, so releases aren't reproducible; pin it to a version tag or digest",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("expected %q, got %q", expected, messages)
+	}
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Whether a compose file is written as JSON, by its .json extension or else by it starting with an
+// object with a quoted key. Unquoted keys, e.g. {services: ...}, are taken to be YAML flow mappings.
+func isJSONComposeFile(path string, content []byte) bool {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return true
+	}
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	rest, ok := bytes.CutPrefix(bytes.TrimLeft(content, " \t\r\n"), []byte("{"))
+	rest = bytes.TrimLeft(rest, " \t\r\n")
+	return ok && (bytes.HasPrefix(rest, []byte(`"`)) || bytes.HasPrefix(rest, []byte("}")))
+}
+
+// Check compose files written as JSON are valid JSON. compose-go loads them as YAML, which JSON is
+// a subset of, but it also accepts invalid JSON such as trailing commas and reports YAML errors.
+// Compose files from stdin or https:// URLs aren't checked, as compose-go reads them itself.
+func checkJSONComposeFiles(composeFiles []string) error {
+	for _, composeFile := range composeFiles {
+		if composeFile == StdinPath || strings.HasPrefix(composeFile, "https://") {
+			continue
+		}
+		content, err := os.ReadFile(composeFile)
+		if err != nil || !isJSONComposeFile(composeFile, content) {
+			// Unreadable files are reported by compose-go
+			continue
+		}
+		content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+		var document any
+		err = json.Unmarshal(content, &document)
+		if err == nil {
+			continue
+		}
+		location := &Location{}
+		location.File, _ = filepath.Abs(composeFile)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// The offset is after the byte the error is at
+			location.Line, location.Column = offsetPosition(content, max(syntaxErr.Offset-1, 0))
+		}
+		return &Error{
+			Name:     ParseError,
+			Message:  fmt.Sprintf("Failed to parse compose file: invalid JSON in %s: %v", composeFile, err),
+			Location: location,
+			Err:      err,
+		}
+	}
+	return nil
+}
+
+// 1-based line and column of a byte offset in content
+func offsetPosition(content []byte, offset int64) (line, column int) {
+	before := content[:min(int(offset), len(content))]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsJSONComposeFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		content  string
+		expected bool
+	}{
+		{path: "compose.json", content: "services: {}\n", expected: true},
+		{path: "compose.JSON", content: "", expected: true},
+		{path: "compose.yml", content: `{"services": {}}`, expected: true},
+		{path: "compose.yml", content: "\xef\xbb\xbf\n  {\n  \"services\": {}}", expected: true},
+		{path: "compose.yml", content: "{}", expected: true},
+		{path: "compose.yml", content: "{services: {}}", expected: false},
+		{path: "compose.yml", content: "services: {}\n", expected: false},
+		{path: "-", content: `{"services": {}}`, expected: true},
+	}
+	for _, tt := range tests {
+		if isJSON := isJSONComposeFile(tt.path, []byte(tt.content)); isJSON != tt.expected {
+			t.Errorf("expected %s containing %q to be JSON %t, got %t", tt.path, tt.content, tt.expected, isJSON)
+		}
+	}
+}
+
+func TestJSONComposeFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.json":         `{"services": {"web": {"image": "nginx", "ports": ["8080:80"]}}}`,
+		"compose.override.yml": "services:\n  web:\n    environment:\n      TAG: latest\n",
+		"generated":            "{\n  \"services\": {\"db\": {\"image\": \"postgres\"}}\n}\n",
+		"invalid.json":         "{\n  \"services\": {\n    \"web\": {\"image\": \"nginx\",}\n  }\n}\n",
+	})
+	p := New(Options{ProjectName: "test"})
+
+	result, err := p.Parse(context.Background(), []string{filepath.Join(dir, "compose.json"), filepath.Join(dir, "compose.override.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := result.Project.Services["web"]
+	if web.Image != "nginx" || web.Ports[0].Published != "8080" || web.Environment["TAG"] == nil {
+		t.Errorf("expected the JSON compose file merged with the YAML override, got %+v", web)
+	}
+	// Detected by content without a .json extension
+	if result, err := p.Parse(context.Background(), []string{filepath.Join(dir, "generated")}); err != nil || result.Project.Services["db"].Image != "postgres" {
+		t.Errorf("expected the JSON compose file to be parsed, got %v", err)
+	}
+
+	// compose-go would accept the trailing comma as YAML
+	invalid := filepath.Join(dir, "invalid.json")
+	_, err = p.Parse(context.Background(), []string{invalid})
+	parserErr := expectError(t, err, ParseError, "invalid JSON in "+invalid)
+	if location := parserErr.Location; location == nil || location.File != invalid || location.Line != 3 || location.Column != 30 {
+		t.Errorf("expected the error to be located at the trailing comma, got %+v", location)
+	}
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Codes of the issues of ulimits and sysctls the engine rejects when creating or starting the container
+const (
+	// InvalidUlimitCode is reported for ulimits of unknown resources, or with a soft limit above the hard one
+	InvalidUlimitCode = "invalid-ulimit"
+	// InvalidSysctlCode is reported for sysctls which aren't namespaced, or of a namespace shared with the host
+	InvalidSysctlCode = "invalid-sysctl"
+)
+
+// Resources of the ulimits the engine accepts, as named by docker run --ulimit
+var ulimitNames = []string{
+	"core", "cpu", "data", "fsize", "locks", "memlock", "msgqueue", "nice", "nofile", "nproc", "rss", "rtprio", "rttime",
+	"sigpending", "stack",
+}
+
+// The kernel's default fs.nr_open, above which the nofile limit can't be raised, even by root
+const maxOpenFiles = 1048576
+
+// Sysctls of the IPC namespace runc sets in containers, besides those prefixed with fs.mqueue.
+var ipcSysctls = []string{
+	"kernel.msgmax", "kernel.msgmnb", "kernel.msgmni", "kernel.sem", "kernel.shmall", "kernel.shmmax", "kernel.shmmni",
+	"kernel.shm_rmid_forced",
+}
+
+// Check the ulimits of every service and build are of resources the engine knows with a soft limit at most
+// the hard one, and the sysctls of every service are of namespaces the container doesn't share with the
+// host, returning a ValidationError listing each the engine rejects
+func checkKernelOptions(project *types.Project, composeFiles []string) *Error {
+	var issues []targetIssue
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		service := project.Services[name]
+		path := "services." + name
+		issues = append(issues, ulimitIssues(path+".ulimits", service.Ulimits)...)
+		if service.Build != nil {
+			issues = append(issues, ulimitIssues(path+".build.ulimits", service.Build.Ulimits)...)
+		}
+		// Sysctls are located at the sysctls field, as their names contain dots
+		for _, sysctl := range slices.Sorted(maps.Keys(service.Sysctls)) {
+			if message, ok := sysctlIssue(sysctl, service); ok {
+				issues = append(issues, targetIssue{code: InvalidSysctlCode, path: path + ".sysctls", message: fmt.Sprintf(
+					"%s.sysctls: %s %s", path, sysctl, message)})
+			}
+		}
+	}
+	err, _ := reportIssues("Invalid kernel options", issues, composeFiles)
+	return err
+}
+
+// The issues of ulimits, at the path of the ulimits field
+func ulimitIssues(path string, ulimits map[string]*types.UlimitsConfig) []targetIssue {
+	var issues []targetIssue
+	fail := func(name, format string, args ...any) {
+		issues = append(issues, targetIssue{code: InvalidUlimitCode, path: path + "." + name, message: fmt.Sprintf(format, args...)})
+	}
+	for _, name := range slices.Sorted(maps.Keys(ulimits)) {
+		if !slices.Contains(ulimitNames, name) {
+			fail(name, "%s: unknown ulimit %q, expected one of: %s", path, name, strings.Join(ulimitNames, ", "))
+			continue
+		}
+		ulimit := ulimits[name]
+		if ulimit == nil {
+			continue
+		}
+		soft, hard := ulimit.Soft, ulimit.Hard
+		if ulimit.Single != 0 {
+			soft, hard = ulimit.Single, ulimit.Single
+		}
+		// -1 is unlimited
+		switch {
+		case soft < -1 || hard < -1:
+			fail(name, "%s.%s: limits must be positive, or -1 for unlimited", path, name)
+		case hard != -1 && (soft == -1 || soft > hard):
+			fail(name, "%s.%s: the soft limit %s is above the hard limit %d", path, name, ulimitValue(soft), hard)
+		case name == "nofile" && (hard == -1 || hard > maxOpenFiles):
+			fail(name, "%s.nofile: the hard limit %s is above %d, the most open files the kernel allows by default", path, ulimitValue(hard), maxOpenFiles)
+		}
+	}
+	return issues
+}
+
+// A ulimit value as written, where -1 is unlimited
+func ulimitValue(value int) string {
+	if value == -1 {
+		return "unlimited"
+	}
+	return fmt.Sprint(value)
+}
+
+// Why runc rejects a sysctl of a service, if it does: sysctls of the network, IPC and UTS namespaces are
+// only accepted if the service doesn't share them with the host, and no other sysctls are namespaced
+func sysctlIssue(sysctl string, service types.ServiceConfig) (string, bool) {
+	var namespace, mode string
+	switch {
+	case strings.HasPrefix(sysctl, "net."):
+		namespace, mode = "network", service.NetworkMode
+	case slices.Contains(ipcSysctls, sysctl) || strings.HasPrefix(sysctl, "fs.mqueue."):
+		namespace, mode = "IPC", service.Ipc
+	case sysctl == "kernel.domainname":
+		namespace, mode = "UTS", service.Uts
+	default:
+		return "isn't namespaced, so containers can't set it", true
+	}
+	if mode == "host" {
+		return fmt.Sprintf("can't be set, as the service shares the %s namespace of the host", namespace), true
+	}
+	return "", false
+}
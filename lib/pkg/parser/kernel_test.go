@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKernelOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  string
+		expected []string
+	}{
+		{name: "supported", service: "ulimits:\n      nproc: 65535\n      nofile: {soft: 1024, hard: 4096}\n      core: -1\n    sysctls:\n      net.core.somaxconn: 1024\n      kernel.shmmax: 68719476736\n      fs.mqueue.msg_max: 100\n"},
+		{
+			name:     "unknown ulimit",
+			service:  "ulimits:\n      files: 1024\n",
+			expected: []string{"invalid-ulimit services.web.ulimits.files"},
+		},
+		{
+			name:     "soft limit",
+			service:  "ulimits:\n      nofile: {soft: 4096, hard: 1024}\n      nproc: {soft: -1, hard: 1024}\n",
+			expected: []string{"invalid-ulimit services.web.ulimits.nofile", "invalid-ulimit services.web.ulimits.nproc"},
+		},
+		{
+			name:     "negative limit",
+			service:  "ulimits:\n      stack: -2\n",
+			expected: []string{"invalid-ulimit services.web.ulimits.stack"},
+		},
+		{
+			name:     "open files",
+			service:  "ulimits:\n      nofile: 2000000\n",
+			expected: []string{"invalid-ulimit services.web.ulimits.nofile"},
+		},
+		{
+			name:     "build ulimits",
+			service:  "build:\n      context: .\n      ulimits:\n        files: 1024\n",
+			expected: []string{"invalid-ulimit services.web.build.ulimits.files"},
+		},
+		{
+			name:     "not namespaced",
+			service:  "sysctls:\n      vm.swappiness: 10\n      kernel.pid_max: 65536\n",
+			expected: []string{"invalid-sysctl services.web.sysctls", "invalid-sysctl services.web.sysctls"},
+		},
+		{
+			name:     "host namespaces",
+			service:  "network_mode: host\n    uts: host\n    sysctls:\n      net.ipv4.ip_forward: 1\n      kernel.domainname: example.com\n      kernel.shmmax: 68719476736\n",
+			expected: []string{"invalid-sysctl services.web.sysctls", "invalid-sysctl services.web.sysctls"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, _ := targetIssues(t, Options{Target: BalenaTarget}, "services:\n  web:\n    image: nginx\n    "+tt.service)
+			if strings.Join(errs, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("expected %v, got %v", tt.expected, errs)
+			}
+		})
+	}
+
+	compose := "services:\n  web:\n    image: nginx\n    network_mode: host\n    ulimits:\n      nofile: {soft: 4096, hard: 1024}\n    sysctls:\n      net.ipv4.ip_forward: 1\n"
+	_, err := parse(t, Options{Target: BalenaTarget}, compose)
+	parserErr := expectError(t, err, ValidationError, "Invalid kernel options: ")
+	for i, expected := range []string{
+		"services.web.ulimits.nofile: the soft limit 4096 is above the hard limit 1024",
+		"services.web.sysctls: net.ipv4.ip_forward can't be set, as the service shares the network namespace of the host",
+	} {
+		if len(parserErr.Errors) != 2 || parserErr.Errors[i].Message != expected {
+			t.Errorf("expected %q, got %+v", expected, parserErr.Errors)
+		}
+	}
+	if _, err := parse(t, Options{}, compose); err != nil {
+		t.Errorf("expected kernel options not to be checked without the balena target, got %v", err)
+	}
+}
@@ -0,0 +1,301 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"go.yaml.in/yaml/v3"
+)
+
+// LegacyFieldCode is reported as a warning for fields of docker-compose 2.x files which the compose spec
+// dropped, and which are converted into their compose spec equivalent
+const LegacyFieldCode = "legacy-field"
+
+// Whether the top-level version of a compose file is that of the 2.x file format
+func isLegacyVersion(document *yaml.Node) bool {
+	_, version := mappingEntry(document, "version")
+	return version != nil && version.Kind == yaml.ScalarNode && (version.Value == "2" || strings.HasPrefix(version.Value, "2."))
+}
+
+// The key and value nodes of a key of a mapping node, or nil if it isn't set
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// Remove a key from a mapping node
+func removeMappingEntry(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// Convert the fields of docker-compose 2.x files which the compose spec dropped into their equivalent,
+// returning the converted content and an issue for each field converted, or nil content if no document
+// of the file is of the 2.x format or has such fields. Keys defined several times are also removed but
+// for their last definition, which compose-go would otherwise reject, with an issue for each. Fields of
+// 2.x files are converted as follows:
+//
+//   - net is network_mode
+//   - log_driver and log_opt are the driver and options of logging
+//   - volume_driver is the driver of the top-level volumes the service mounts which don't set one
+//   - init set to the path of an init binary is true, as the path can't be set
+func convertLegacy(content []byte) ([]byte, []targetIssue, error) {
+	var documents []*yaml.Node
+	var issues []targetIssue
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Malformed YAML is reported by compose-go
+			return nil, nil, nil
+		}
+		documents = append(documents, &document)
+		issues = append(issues, removeDuplicateKeys(&document, "")...)
+		if len(document.Content) == 0 || !isLegacyVersion(document.Content[0]) {
+			continue
+		}
+		issues = append(issues, convertLegacyDocument(document.Content[0])...)
+	}
+	if len(issues) == 0 {
+		return nil, nil, nil
+	}
+
+	var converted bytes.Buffer
+	encoder := yaml.NewEncoder(&converted)
+	encoder.SetIndent(2)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+	return converted.Bytes(), issues, nil
+}
+
+func convertLegacyDocument(document *yaml.Node) []targetIssue {
+	_, services := mappingEntry(document, "services")
+	_, volumes := mappingEntry(document, "volumes")
+	return convertLegacyServices(services, volumes, "services.")
+}
+
+// Convert the legacy fields of the services of a mapping node, whose paths are prefixed with prefix
+func convertLegacyServices(services, volumes *yaml.Node, prefix string) []targetIssue {
+	var issues []targetIssue
+	converted := func(path, replacement, message string, args ...any) {
+		issues = append(issues, targetIssue{code: LegacyFieldCode, path: path, message: fmt.Sprintf(message, args...), warning: true, replacement: replacement})
+	}
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, service := services.Content[i].Value, services.Content[i+1]
+		if service.Kind != yaml.MappingNode {
+			continue
+		}
+		path := prefix + name
+
+		if key, net := mappingEntry(service, "net"); net != nil {
+			if _, networkMode := mappingEntry(service, "network_mode"); networkMode == nil {
+				key.Value = "network_mode"
+			} else {
+				removeMappingEntry(service, "net")
+			}
+			converted(path+".net", "network_mode", "%s.net is converted to network_mode", path)
+		}
+
+		_, logDriver := mappingEntry(service, "log_driver")
+		_, logOpt := mappingEntry(service, "log_opt")
+		if logDriver != nil || logOpt != nil {
+			_, logging := mappingEntry(service, "logging")
+			if logging == nil {
+				logging = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				service.Content = append(service.Content, scalarNode("logging"), logging)
+			}
+			for _, field := range []struct {
+				legacy, key string
+				value       *yaml.Node
+			}{{"log_driver", "driver", logDriver}, {"log_opt", "options", logOpt}} {
+				if field.value == nil {
+					continue
+				}
+				if _, set := mappingEntry(logging, field.key); set == nil {
+					logging.Content = append(logging.Content, scalarNode(field.key), field.value)
+				}
+				removeMappingEntry(service, field.legacy)
+				converted(path+"."+field.legacy, "logging."+field.key, "%s.%s is converted to logging.%s", path, field.legacy, field.key)
+			}
+		}
+
+		if _, volumeDriver := mappingEntry(service, "volume_driver"); volumeDriver != nil {
+			_, mounts := mappingEntry(service, "volumes")
+			for _, source := range namedVolumeSources(mounts) {
+				_, volume := mappingEntry(volumes, source)
+				if volume == nil {
+					continue
+				}
+				if volume.Kind != yaml.MappingNode {
+					// Volumes declared without options, e.g. "data:"
+					*volume = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				}
+				if _, driver := mappingEntry(volume, "driver"); driver == nil {
+					volume.Content = append(volume.Content, scalarNode("driver"), volumeDriver)
+				}
+			}
+			removeMappingEntry(service, "volume_driver")
+			converted(path+".volume_driver", "the driver of the top-level volumes", "%s.volume_driver is converted to the driver of the volumes the service mounts", path)
+		}
+
+		if _, init := mappingEntry(service, "init"); init != nil && init.Kind == yaml.ScalarNode && strings.HasPrefix(init.Value, "/") {
+			converted(path+".init", "init: true", "%s.init is converted to true, as the init binary %s can't be set", path, init.Value)
+			*init = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}
+		}
+	}
+	return issues
+}
+
+// The sources of the mounts of a service's volumes which are named volumes, in short or long syntax
+func namedVolumeSources(mounts *yaml.Node) []string {
+	if mounts == nil || mounts.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var sources []string
+	for _, mount := range mounts.Content {
+		var source string
+		switch mount.Kind {
+		case yaml.ScalarNode:
+			if parts := strings.Split(mount.Value, ":"); len(parts) > 1 {
+				source = parts[0]
+			}
+		case yaml.MappingNode:
+			if _, value := mappingEntry(mount, "source"); value != nil {
+				if _, kind := mappingEntry(mount, "type"); kind == nil || kind.Value == "volume" {
+					source = value.Value
+				}
+			}
+		}
+		if source != "" && !strings.ContainsAny(source[:1], "/.~$") {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// Convert the local compose files of the 2.x format, or with duplicate keys, into a temporary directory, returning the files to load
+// in place of the compose files, a replacer of the converted file paths with those of the compose files,
+// and warnings for the fields converted. The directory must be removed once the files are loaded.
+func convertLegacyFiles(composeFiles []string) (files []string, dir string, restore *strings.Replacer, warnings []Warning, err *Error) {
+	files = append([]string{}, composeFiles...)
+	var replacements []string
+	for i, composeFile := range composeFiles {
+		if composeFile == StdinPath || strings.HasPrefix(composeFile, "https://") {
+			continue
+		}
+		content, readErr := os.ReadFile(composeFile)
+		if readErr != nil {
+			// Unreadable files are reported by compose-go
+			continue
+		}
+		converted, issues, convertErr := convertLegacy(content)
+		if convertErr != nil {
+			return nil, dir, nil, nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to convert legacy compose file %s: %v", composeFile, convertErr), Err: convertErr}
+		}
+		if converted == nil {
+			continue
+		}
+		if dir == "" {
+			var dirErr error
+			if dir, dirErr = os.MkdirTemp("", "balena-compose-parser-legacy-"); dirErr != nil {
+				return nil, "", nil, nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to create legacy file directory: %v", dirErr), Err: dirErr}
+			}
+		}
+		// Files are numbered, as several compose files may have the same name
+		files[i] = filepath.Join(dir, fmt.Sprintf("%d-%s", i, filepath.Base(composeFile)))
+		if writeErr := os.WriteFile(files[i], converted, 0o600); writeErr != nil {
+			return nil, dir, nil, nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to write converted legacy file: %v", writeErr), Err: writeErr}
+		}
+		abs, _ := filepath.Abs(composeFile)
+		replacements = append(replacements, files[i], abs)
+		_, fileWarnings := reportIssues("", issues, []string{composeFile})
+		warnings = append(warnings, fileWarnings...)
+	}
+	return files, dir, strings.NewReplacer(replacements...), warnings, nil
+}
+
+// logrus hook restoring the paths of converted legacy files in log messages, e.g. compose-go's warning
+// that the version is obsolete, so they name the compose files rather than their temporary conversions.
+// As with maskHook, the paths of each parse are restored in all entries while it runs.
+type restorePathsHook struct {
+	mu        sync.RWMutex
+	replacers map[*strings.Replacer]bool
+}
+
+var logPaths = sync.OnceValue(func() *restorePathsHook {
+	hook := &restorePathsHook{replacers: map[*strings.Replacer]bool{}}
+	logrus.AddHook(hook)
+	return hook
+})
+
+func (h *restorePathsHook) register(r *strings.Replacer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.replacers[r] = true
+}
+
+func (h *restorePathsHook) unregister(r *strings.Replacer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.replacers, r)
+}
+
+func (h *restorePathsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Restore the paths of the running parses in a message
+func (h *restorePathsHook) restore(message string) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for r := range h.replacers {
+		message = r.Replace(message)
+	}
+	return message
+}
+
+func (h *restorePathsHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.restore(entry.Message)
+	for key, field := range entry.Data {
+		if s, ok := field.(string); ok {
+			entry.Data[key] = h.restore(s)
+		}
+	}
+	return nil
+}
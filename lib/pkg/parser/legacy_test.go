@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestLegacyFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"docker-compose.yml": "version: \"2.1\"\n" +
+			"services:\n" +
+			"  web:\n    image: nginx\n    net: host\n    log_driver: json-file\n    log_opt:\n      max-size: 10m\n    mem_limit: 512m\n    cpu_shares: 512\n" +
+			"  db:\n    image: postgres\n    volume_driver: local-persist\n    volumes: [\"data:/data\", \"logs:/logs\", \"./config:/config\"]\n    init: /usr/bin/tini\n" +
+			"volumes:\n  data:\n  logs:\n    driver: local\n",
+	})
+	composeFile := filepath.Join(dir, "docker-compose.yml")
+	result, err := New(Options{ProjectName: "test", Warnings: true}).Parse(context.Background(), []string{composeFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	web, db := result.Project.Services["web"], result.Project.Services["db"]
+	if web.NetworkMode != "host" || web.Logging == nil || web.Logging.Driver != "json-file" || web.Logging.Options["max-size"] != "10m" {
+		t.Errorf("expected net and the log fields to be converted, got %+v", web)
+	}
+	if web.MemLimit != 512*1024*1024 || web.CPUShares != 512 {
+		t.Errorf("expected the 2.x resource fields the spec kept, got %d and %d", web.MemLimit, web.CPUShares)
+	}
+	// The volume driver is set on the volumes which don't set one
+	if data, logs := result.Project.Volumes["data"], result.Project.Volumes["logs"]; data.Driver != "local-persist" || logs.Driver != "local" {
+		t.Errorf("expected the volume driver of the volumes db mounts, got %s and %s", data.Driver, logs.Driver)
+	}
+	if db.Init == nil || !*db.Init {
+		t.Errorf("expected the init path to be true, got %v", db.Init)
+	}
+
+	// Warnings are located in the compose file, not the converted one
+	var warnings []string
+	for _, warning := range result.Warnings {
+		if warning.Code != LegacyFieldCode {
+			continue
+		}
+		if warning.Location == nil || warning.Location.File != composeFile {
+			t.Errorf("expected a legacy-field warning located in the compose file, got %+v", warning)
+			continue
+		}
+		warnings = append(warnings, warning.Location.Path)
+	}
+	expected := []string{"services.web.net", "services.web.log_driver", "services.web.log_opt", "services.db.volume_driver", "services.db.init"}
+	if !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("expected %v, got %v", expected, warnings)
+	}
+}
+
+func TestLegacyLogs(t *testing.T) {
+	var logs bytes.Buffer
+	logrus.SetOutput(&logs)
+	t.Cleanup(func() { logrus.SetOutput(os.Stderr) })
+
+	dir := writeFiles(t, map[string]string{"docker-compose.yml": "version: \"2.1\"\nservices:\n  web:\n    image: nginx\n    net: host\n"})
+	composeFile := filepath.Join(dir, "docker-compose.yml")
+	if _, err := New(Options{ProjectName: "test"}).Parse(context.Background(), []string{composeFile}); err != nil {
+		t.Fatal(err)
+	}
+	if output := logs.String(); !strings.Contains(output, composeFile+": the attribute `version` is obsolete") || strings.Contains(output, "balena-compose-parser-legacy-") {
+		t.Errorf("expected the logs to name the compose file rather than its conversion, got %q", output)
+	}
+
+	// Paths are only restored while their parse runs
+	logs.Reset()
+	logrus.Warn("loading /tmp/balena-compose-parser-legacy-1/0-docker-compose.yml")
+	if output := logs.String(); !strings.Contains(output, "balena-compose-parser-legacy-1") {
+		t.Errorf("expected paths not to be restored once the parse is done, got %q", output)
+	}
+}
+
+func TestConvertLegacy(t *testing.T) {
+	// Files of the compose spec format are loaded as is
+	for _, content := range []string{"services:\n  web:\n    image: nginx\n    net: host\n", "version: \"3.8\"\nservices:\n  web:\n    log_driver: syslog\n", "services: [\n"} {
+		if converted, issues, err := convertLegacy([]byte(content)); converted != nil || issues != nil || err != nil {
+			t.Errorf("expected %q not to be converted, got %s, %v: %v", content, converted, issues, err)
+		}
+	}
+
+	converted, issues, err := convertLegacy([]byte("version: \"2\"\nservices:\n  web:\n    net: none\n    network_mode: host\n    logging:\n      driver: syslog\n    log_driver: json-file\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields set in both syntaxes keep their compose spec value
+	expected := "version: \"2\"\nservices:\n  web:\n    network_mode: host\n    logging:\n      driver: syslog\n"
+	if string(converted) != expected || len(issues) != 2 {
+		t.Errorf("expected %q, got %q with %v", expected, converted, issues)
+	}
+}
+
+func TestNamedVolumeSources(t *testing.T) {
+	var mounts yaml.Node
+	if err := yaml.Unmarshal([]byte("[\"data:/data\", \"./config:/config\", \"/var/log:/logs\", \"~/cache:/cache\", \"${VOLUME}:/v\", /anonymous, {source: logs, target: /logs}, {type: bind, source: host, target: /host}]"), &mounts); err != nil {
+		t.Fatal(err)
+	}
+	if sources := namedVolumeSources(mounts.Content[0]); !reflect.DeepEqual(sources, []string{"data", "logs"}) {
+		t.Errorf("expected the named volumes, got %v", sources)
+	}
+}
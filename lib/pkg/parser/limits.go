@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/docker/go-units"
+)
+
+// Codes of the issues making projects exceed the limits of Options.MaxServices and Options.MaxVolumes,
+// or use service names balena doesn't accept
+const (
+	// TooManyServicesCode is reported for projects with more services than Options.MaxServices
+	TooManyServicesCode = "too-many-services"
+	// TooManyVolumesCode is reported for projects with more volumes than Options.MaxVolumes
+	TooManyVolumesCode = "too-many-volumes"
+	// InvalidServiceNameCode is reported by BalenaTarget for service names which can't be hostnames
+	InvalidServiceNameCode = "invalid-service-name"
+)
+
+// The supervisor uses service names as hostnames on the services' networks, so they must be DNS labels,
+// except that underscores are also accepted
+var balenaServiceNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9_-]*[a-z0-9])?$`)
+
+// Maximum length of a DNS label
+const balenaServiceNameMaxLength = 63
+
+// Check a service name is one balena accepts, returning the issue if it isn't
+func serviceNameIssue(name string) (targetIssue, bool) {
+	path := "services." + name
+	switch {
+	case len(name) > balenaServiceNameMaxLength:
+		return targetIssue{code: InvalidServiceNameCode, path: path, message: fmt.Sprintf("%s: service names can't be longer than %d characters", path, balenaServiceNameMaxLength)}, true
+	case !balenaServiceNamePattern.MatchString(name):
+		return targetIssue{code: InvalidServiceNameCode, path: path, message: fmt.Sprintf("%s: service names must only contain lowercase letters, digits, '-' and '_', and start and end with a letter or digit", path)}, true
+	}
+	return targetIssue{}, false
+}
+
+// Check a project has at most maxServices services and maxVolumes volumes, where 0 is unlimited,
+// returning a ValidationError reporting those exceeded
+func checkLimits(project *types.Project, maxServices, maxVolumes int, composeFiles []string) *Error {
+	var issues []targetIssue
+	if services := len(project.Services); maxServices > 0 && services > maxServices {
+		// The first service over the limit is located, as it's the one to remove
+		name := slices.Sorted(maps.Keys(project.Services))[maxServices]
+		issues = append(issues, targetIssue{code: TooManyServicesCode, path: "services." + name, message: fmt.Sprintf("the project has %d services, more than the limit of %d", services, maxServices)})
+	}
+	if volumes := len(project.Volumes); maxVolumes > 0 && volumes > maxVolumes {
+		name := slices.Sorted(maps.Keys(project.Volumes))[maxVolumes]
+		issues = append(issues, targetIssue{code: TooManyVolumesCode, path: "volumes." + name, message: fmt.Sprintf("the project has %d volumes, more than the limit of %d", volumes, maxVolumes)})
+	}
+	err, _ := reportIssues("Project exceeds its limits", issues, composeFiles)
+	return err
+}
+
+// Codes of the findings of the resource-limits lint rule
+const (
+	// MissingLimitCode is reported for services without a memory or CPU limit
+	MissingLimitCode = "missing-limit"
+	// ExcessiveLimitCode is reported for memory limits and reservations exceeding LintOptions.DeviceMemory
+	ExcessiveLimitCode = "excessive-limit"
+)
+
+// The issues of the memory and CPU limits of a service, set with mem_limit and cpus or deploy resources,
+// left unset or exceeding the memory of the device if it isn't zero
+func resourceLimitIssues(path string, service types.ServiceConfig, deviceMemory int64) []targetIssue {
+	var issues []targetIssue
+	memory, memoryPath := int64(service.MemLimit), path+".mem_limit"
+	cpus := float64(service.CPUS)
+	reservation, reservationPath := int64(service.MemReservation), path+".mem_reservation"
+	if service.Deploy != nil {
+		if limits := service.Deploy.Resources.Limits; limits != nil {
+			if memory == 0 && limits.MemoryBytes != 0 {
+				memory, memoryPath = int64(limits.MemoryBytes), path+".deploy.resources.limits.memory"
+			}
+			cpus = max(cpus, float64(limits.NanoCPUs))
+		}
+		if reservations := service.Deploy.Resources.Reservations; reservation == 0 && reservations != nil && reservations.MemoryBytes != 0 {
+			reservation, reservationPath = int64(reservations.MemoryBytes), path+".deploy.resources.reservations.memory"
+		}
+	}
+
+	if memory == 0 {
+		issues = append(issues, targetIssue{code: MissingLimitCode, path: path, message: fmt.Sprintf(
+			"%s doesn't set mem_limit, so it can use all the memory of the device and cause OOM loops", path)})
+	}
+	if cpus == 0 {
+		issues = append(issues, targetIssue{code: MissingLimitCode, path: path, message: fmt.Sprintf(
+			"%s doesn't set cpus, so it can use all the CPUs of the device", path)})
+	}
+	if deviceMemory == 0 {
+		return issues
+	}
+	for _, limit := range []struct {
+		path  string
+		bytes int64
+	}{{memoryPath, memory}, {reservationPath, reservation}} {
+		if limit.bytes > deviceMemory {
+			issues = append(issues, targetIssue{code: ExcessiveLimitCode, path: limit.path, message: fmt.Sprintf(
+				"%s: %s exceeds the %s of memory of the device", limit.path, units.BytesSize(float64(limit.bytes)), units.BytesSize(float64(deviceMemory)))})
+		}
+	}
+	return issues
+}
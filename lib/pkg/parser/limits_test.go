@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	compose := "services:\n  web:\n    image: nginx\n  api:\n    image: node\n  db:\n    image: postgres\n" +
+		"volumes:\n  data: {}\n  logs: {}\n"
+	tests := []struct {
+		name        string
+		maxServices int
+		maxVolumes  int
+		errors      []string
+	}{
+		{name: "unlimited"},
+		{name: "within limits", maxServices: 3, maxVolumes: 2},
+		// The first service and volume over the limits by name are located
+		{name: "too many services", maxServices: 2, errors: []string{"too-many-services services.web"}},
+		{name: "too many", maxServices: 1, maxVolumes: 1, errors: []string{"too-many-services services.db", "too-many-volumes volumes.logs"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, _ := targetIssues(t, Options{MaxServices: tt.maxServices, MaxVolumes: tt.maxVolumes}, compose)
+			if !reflect.DeepEqual(errs, tt.errors) {
+				t.Errorf("expected %v, got %v", tt.errors, errs)
+			}
+		})
+	}
+
+	_, err := parse(t, Options{MaxServices: 1}, compose)
+	expectError(t, err, ValidationError, "Project exceeds its limits: the project has 3 services, more than the limit of 1")
+	_, err = parse(t, Options{MaxVolumes: -1}, compose)
+	expectError(t, err, ArgumentError, "Service and volume limits can't be negative, got 0 and -1")
+}
+
+func TestServiceNames(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"web":                   true,
+		"web_1":                 true,
+		"my-api2":               true,
+		"0":                     true,
+		"Web":                   false,
+		"web.api":               false,
+		"-web":                  false,
+		"web_":                  false,
+		strings.Repeat("a", 63): true,
+		strings.Repeat("a", 64): false,
+	} {
+		if _, invalid := serviceNameIssue(name); invalid == valid {
+			t.Errorf("expected %s to be valid %t", name, valid)
+		}
+	}
+
+	errs, _ := targetIssues(t, Options{Target: BalenaTarget}, "services:\n  Web:\n    image: nginx\n  web.api:\n    image: node\n")
+	if expected := []string{"invalid-service-name services.Web", "invalid-service-name services.web.api"}; !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %v, got %v", expected, errs)
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	services := mustParse(t, Options{}, "services:\n"+
+		"  unlimited:\n    image: nginx\n"+
+		"  limited:\n    image: nginx\n    mem_limit: 256m\n    cpus: 0.5\n"+
+		"  deployed:\n    image: nginx\n    deploy:\n      resources:\n        limits: {memory: 2g, cpus: \"1\"}\n        reservations: {memory: 1536m}\n"+
+		"  reserved:\n    image: nginx\n    mem_limit: 512m\n    mem_reservation: 3g\n    cpus: 1\n").Project.Services
+	tests := []struct {
+		service      string
+		deviceMemory int64
+		expected     []string
+	}{
+		{service: "unlimited", expected: []string{
+			"services.unlimited doesn't set mem_limit, so it can use all the memory of the device and cause OOM loops",
+			"services.unlimited doesn't set cpus, so it can use all the CPUs of the device",
+		}},
+		{service: "limited", deviceMemory: 1 << 30},
+		{service: "deployed"},
+		// Limits exceeding the memory of the device are located at the field setting them
+		{service: "deployed", deviceMemory: 1 << 30, expected: []string{
+			"services.deployed.deploy.resources.limits.memory: 2GiB exceeds the 1GiB of memory of the device",
+			"services.deployed.deploy.resources.reservations.memory: 1.5GiB exceeds the 1GiB of memory of the device",
+		}},
+		{service: "reserved", deviceMemory: 1 << 30, expected: []string{"services.reserved.mem_reservation: 3GiB exceeds the 1GiB of memory of the device"}},
+	}
+	for _, tt := range tests {
+		var messages []string
+		for _, issue := range resourceLimitIssues("services."+tt.service, services[tt.service], tt.deviceMemory) {
+			messages = append(messages, issue.message)
+		}
+		if !reflect.DeepEqual(messages, tt.expected) {
+			t.Errorf("expected %q for %s with %d bytes, got %q", tt.expected, tt.service, tt.deviceMemory, messages)
+		}
+	}
+}
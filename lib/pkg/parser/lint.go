@@ -0,0 +1,297 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Severities of lint findings, from the most severe
+const (
+	ErrorSeverity   = "error"
+	WarningSeverity = "warning"
+	InfoSeverity    = "info"
+)
+
+// Severities are the severities LintOptions.Severities accepts, from the most severe
+var Severities = []string{ErrorSeverity, WarningSeverity, InfoSeverity}
+
+// LintRule checks projects for one kind of composition quality issue
+type LintRule struct {
+	// ID identifies the rule, e.g. "restart-policy"
+	ID string `json:"id"`
+	// Severity is the severity of the rule's findings, unless overridden
+	Severity string `json:"severity"`
+	// Description is a one-line description of what the rule checks
+	Description string `json:"description"`
+	// Disabled rules only run if enabled with LintOptions.Enable
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Find the issues of a project as output, located by their path
+	check func(project *types.Project, config map[string]any, options LintOptions) []targetIssue
+}
+
+// Finding is an issue found by a lint rule
+type Finding struct {
+	// Rule is the ID of the rule which found the issue
+	Rule string `json:"rule"`
+	// Severity is "error", "warning" or "info"
+	Severity string `json:"severity"`
+	// Code identifies the kind of issue within the rule, if the rule finds several
+	Code string `json:"code,omitempty"`
+	// Message is the human readable description of the issue
+	Message string `json:"message"`
+	// Location is where in the compose files the issue was found, if known
+	Location *Location `json:"location,omitempty"`
+}
+
+// LintOptions select the rules Lint runs and the severity of their findings
+type LintOptions struct {
+	// Enable are the IDs of rules to run, besides those enabled by default
+	Enable []string
+	// Disable are the IDs of rules not to run, overriding Enable
+	Disable []string
+	// Severities override the severity of rules by ID
+	Severities map[string]string
+	// Ignore are the IDs of rules whose findings aren't reported for a service, by service name
+	Ignore map[string][]string
+	// DeviceMemory is the memory of the devices the project runs on, in bytes, which the memory limits of
+	// services shouldn't exceed. Limits aren't compared if zero.
+	DeviceMemory int64
+	// AllowedMounts are the host paths services may bind mount, with the paths beneath them, which the
+	// security-mount-paths rule doesn't report. Relative paths are relative to the project directory.
+	AllowedMounts []string
+}
+
+// LintRules are the rules Lint runs, in ID order
+var LintRules = []LintRule{
+	{
+		ID:          "balena-compatibility",
+		Severity:    ErrorSeverity,
+		Description: "Services only use fields balena supports, as validated with --target balena",
+		check: func(_ *types.Project, config map[string]any, _ LintOptions) []targetIssue {
+			var issues []targetIssue
+			for _, issue := range balenaIssues(config, "", "") {
+				// Fields balena ignores are reported, but aren't errors
+				if !issue.warning {
+					issues = append(issues, issue)
+				}
+			}
+			return issues
+		},
+	},
+	{
+		ID:          "dependency-cycles",
+		Severity:    ErrorSeverity,
+		Description: "Services don't depend on each other in a cycle, through depends_on, links, network_mode, pid, ipc or volumes_from",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return dependencyCycleIssues(project)
+		},
+	},
+	{
+		ID:          "healthcheck",
+		Severity:    ErrorSeverity,
+		Description: "Healthchecks are of the NONE, CMD or CMD-SHELL form, with durations of at least 1ms and at least one retry",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return serviceIssues(project, healthcheckIssues)
+		},
+	},
+	{
+		ID:          "healthcheck-tools",
+		Severity:    WarningSeverity,
+		Description: "Healthchecks don't run tools the image of the service likely lacks, e.g. curl in alpine",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return serviceIssues(project, healthcheckToolIssues)
+		},
+	},
+	{
+		ID:          "image-pinning",
+		Severity:    WarningSeverity,
+		Description: "Images services pull are pinned to a tag other than latest or a digest, so releases are reproducible",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return unpinnedImageIssues(project)
+		},
+	},
+	{
+		ID:          "port-conflicts",
+		Severity:    ErrorSeverity,
+		Description: "Services don't publish the same host port and protocol, which only fails once containers start",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return portConflictIssues(project)
+		},
+	},
+	{
+		ID:          "resource-limits",
+		Severity:    WarningSeverity,
+		Description: "Services set memory and CPU limits, within the memory of the device if set, to prevent OOM loops",
+		check: func(project *types.Project, _ map[string]any, options LintOptions) []targetIssue {
+			return serviceIssues(project, func(path string, service types.ServiceConfig) []targetIssue {
+				return resourceLimitIssues(path, service, options.DeviceMemory)
+			})
+		},
+	},
+	{
+		ID:          "restart-policy",
+		Severity:    InfoSeverity,
+		Description: "Services set a restart policy, rather than relying on the supervisor restarting them always",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			var issues []targetIssue
+			for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+				if project.Services[name].Restart == "" {
+					issues = append(issues, targetIssue{path: "services." + name, message: fmt.Sprintf(
+						"services.%s doesn't set restart, so the supervisor restarts it always", name)})
+				}
+			}
+			return issues
+		},
+	},
+	{
+		ID:          "security-capabilities",
+		Severity:    WarningSeverity,
+		Description: "Services don't add capabilities giving broad control of the host, e.g. SYS_ADMIN",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return serviceIssues(project, dangerousCapabilityIssues)
+		},
+	},
+	{
+		ID:          "security-docker-socket",
+		Severity:    ErrorSeverity,
+		Description: "Services don't bind mount the engine socket, which gives root access to the host",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return serviceIssues(project, dockerSocketIssues)
+		},
+	},
+	{
+		ID:          "security-host-network-ports",
+		Severity:    WarningSeverity,
+		Description: "Services on the host network don't publish ports, which only expose the ports listened on",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return serviceIssues(project, ignoredPortsIssues)
+		},
+	},
+	{
+		ID:          "security-mount-paths",
+		Severity:    WarningSeverity,
+		Description: "Bind mounts of relative paths stay within the project directory, and don't mount sensitive balenaOS paths, e.g. /mnt/boot",
+		check: func(project *types.Project, _ map[string]any, options LintOptions) []targetIssue {
+			return serviceIssues(project, func(path string, service types.ServiceConfig) []targetIssue {
+				return mountPathIssues(path, service, options.AllowedMounts)
+			})
+		},
+	},
+	{
+		ID:          "security-privileged",
+		Severity:    WarningSeverity,
+		Description: "Services aren't privileged, rather adding the devices and capabilities they need",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return serviceIssues(project, privilegedIssues)
+		},
+	},
+	{
+		ID:          "security-writable-mounts",
+		Severity:    InfoSeverity,
+		Description: "Host paths are bind mounted read-only, unless services write to them",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return serviceIssues(project, writableHostMountIssues)
+		},
+	},
+	{
+		ID:          "undefined-references",
+		Severity:    ErrorSeverity,
+		Description: "Services only use volumes, networks, configs and secrets the project declares, e.g. not data-vol for data_vol",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return undefinedReferenceIssues(project)
+		},
+	},
+	{
+		ID:          "unused-resources",
+		Severity:    WarningSeverity,
+		Description: "Every volume, network, config and secret the project declares is used by a service",
+		check: func(project *types.Project, _ map[string]any, _ LintOptions) []targetIssue {
+			return unusedResourceIssues(project)
+		},
+	},
+}
+
+// The issues of each service of a project, in service name order, found by check
+func serviceIssues(project *types.Project, check func(path string, service types.ServiceConfig) []targetIssue) []targetIssue {
+	var issues []targetIssue
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		issues = append(issues, check("services."+name, project.Services[name])...)
+	}
+	return issues
+}
+
+// Check lint options only refer to rules and severities which exist
+func checkLintOptions(options LintOptions) *Error {
+	ids := make([]string, len(LintRules))
+	for i, rule := range LintRules {
+		ids[i] = rule.ID
+	}
+	for _, id := range slices.Concat(options.Enable, options.Disable, slices.Collect(maps.Keys(options.Severities)), slices.Concat(slices.Collect(maps.Values(options.Ignore))...)) {
+		if !slices.Contains(ids, id) {
+			return &Error{Name: ArgumentError, Message: fmt.Sprintf("Unknown lint rule %q, expected one of: %s", id, strings.Join(ids, ", "))}
+		}
+	}
+	for id, severity := range options.Severities {
+		if !slices.Contains(Severities, severity) {
+			return &Error{Name: ArgumentError, Message: fmt.Sprintf("Unsupported severity %q of lint rule %s, expected one of: %s", severity, id, strings.Join(Severities, ", "))}
+		}
+	}
+	return nil
+}
+
+// Lint runs the enabled rules on a parsed project, returning their findings located in the compose files,
+// in rule order then in the order each rule found them
+func Lint(project *types.Project, composeFiles []string, options LintOptions) ([]Finding, error) {
+	if err := checkLintOptions(options); err != nil {
+		return nil, err
+	}
+	projectJSON, err := project.MarshalJSON()
+	if err != nil {
+		return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to marshal compose project: %v", err), Err: err}
+	}
+	// Rules check fields as output, where unset fields are omitted
+	var config map[string]any
+	json.Unmarshal(projectJSON, &config)
+
+	findings := []Finding{}
+	for _, rule := range LintRules {
+		enabled := !rule.Disabled || slices.Contains(options.Enable, rule.ID)
+		if !enabled || slices.Contains(options.Disable, rule.ID) {
+			continue
+		}
+		severity := rule.Severity
+		if override, ok := options.Severities[rule.ID]; ok {
+			severity = override
+		}
+		for _, issue := range rule.check(project, config, options) {
+			if ignored(issue.path, rule.ID, options.Ignore) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Rule:     rule.ID,
+				Severity: severity,
+				Code:     issue.code,
+				Message:  issue.message,
+				Location: locatePath(issue.path, composeFiles),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// Whether the issue at a path is of a service for which the findings of a rule are ignored
+func ignored(path, rule string, ignore map[string][]string) bool {
+	for service, rules := range ignore {
+		servicePath := "services." + service
+		if slices.Contains(rules, rule) && (path == servicePath || strings.HasPrefix(path, servicePath+".")) {
+			return true
+		}
+	}
+	return false
+}
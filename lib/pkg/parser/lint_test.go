@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// The rule, severity and path of each finding
+func findingPaths(findings []Finding) []string {
+	var paths []string
+	for _, finding := range findings {
+		path := ""
+		if finding.Location != nil {
+			path = finding.Location.Path
+		}
+		paths = append(paths, finding.Rule+" "+finding.Severity+" "+path)
+	}
+	return paths
+}
+
+// Lint compose files parsed as the lint subcommand parses them
+func lint(t *testing.T, options LintOptions, compose string) []Finding {
+	t.Helper()
+	composeFile := filepath.Join(writeFiles(t, map[string]string{"compose.yml": compose}), "compose.yml")
+	result, err := New(Options{ProjectName: "test", RelativePaths: true, BalenaNormalize: true, SkipConsistency: true}).Parse(context.Background(), []string{composeFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := Lint(result.Project, []string{composeFile}, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return findings
+}
+
+func TestLint(t *testing.T) {
+	compose := "services:\n" +
+		"  web:\n    image: nginx:1.25\n    scale: 2\n    mem_limit: 256m\n    cpus: 0.5\n" +
+		"  db:\n    image: postgres:16\n    restart: always\n    mem_limit: 512m\n    cpus: 1\n"
+	findings := lint(t, LintOptions{}, compose)
+	// Findings are in rule order, located in the compose files
+	if paths := findingPaths(findings); !reflect.DeepEqual(paths, []string{"balena-compatibility error services.web.scale", "restart-policy info services.web"}) {
+		t.Errorf("expected the findings of the rules enabled by default, got %v", paths)
+	}
+	if restart := findings[1]; restart.Message != "services.web doesn't set restart, so the supervisor restarts it always" || restart.Location.Line != 2 || restart.Location.File == "" {
+		t.Errorf("expected the located restart-policy finding, got %+v at %+v", restart, restart.Location)
+	}
+
+	findings = lint(t, LintOptions{Disable: []string{"balena-compatibility"}, Severities: map[string]string{"restart-policy": ErrorSeverity}}, compose)
+	if paths := findingPaths(findings); !reflect.DeepEqual(paths, []string{"restart-policy error services.web"}) {
+		t.Errorf("expected the disabled rule not to run and the severity to be overridden, got %v", paths)
+	}
+
+	// Rules disabled by default only run if enabled, unless also disabled
+	for i := range LintRules {
+		if LintRules[i].ID == "restart-policy" {
+			LintRules[i].Disabled = true
+			t.Cleanup(func() { LintRules[i].Disabled = false })
+		}
+	}
+	for _, tt := range []struct {
+		options  LintOptions
+		expected []string
+	}{
+		{options: LintOptions{}, expected: []string{"balena-compatibility error services.web.scale"}},
+		{options: LintOptions{Enable: []string{"restart-policy"}}, expected: []string{"balena-compatibility error services.web.scale", "restart-policy info services.web"}},
+		{options: LintOptions{Enable: []string{"restart-policy"}, Disable: []string{"restart-policy"}}, expected: []string{"balena-compatibility error services.web.scale"}},
+	} {
+		if paths := findingPaths(lint(t, tt.options, compose)); !reflect.DeepEqual(paths, tt.expected) {
+			t.Errorf("expected %v with %+v, got %v", tt.expected, tt.options, paths)
+		}
+	}
+}
+
+func TestLintOptionsErrors(t *testing.T) {
+	project := mustParse(t, Options{}, "services:\n  web:\n    image: nginx\n").Project
+	for _, tt := range []struct {
+		options LintOptions
+		msg     string
+	}{
+		{options: LintOptions{Enable: []string{"tabs"}}, msg: `Unknown lint rule "tabs", expected one of: balena-compatibility, `},
+		{options: LintOptions{Disable: []string{"restart"}}, msg: `Unknown lint rule "restart"`},
+		{options: LintOptions{Severities: map[string]string{"restart-policy": "fatal"}}, msg: `Unsupported severity "fatal" of lint rule restart-policy, expected one of: error, warning, info`},
+	} {
+		_, err := Lint(project, nil, tt.options)
+		expectError(t, err, ArgumentError, tt.msg)
+	}
+}
+
+func TestLintRules(t *testing.T) {
+	// Rules are listed in ID order, each with a known severity
+	for i, rule := range LintRules {
+		if i > 0 && rule.ID <= LintRules[i-1].ID {
+			t.Errorf("expected %s to be listed after %s", rule.ID, LintRules[i-1].ID)
+		}
+		if !slices.Contains(Severities, rule.Severity) || rule.Description == "" || rule.check == nil {
+			t.Errorf("expected %s to have a severity, description and check, got %+v", rule.ID, rule)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// LintConfigFile is the name of the lint configuration file the lint subcommand reads from the project
+// directory
+const LintConfigFile = ".balena-compose-lint.yml"
+
+// InvalidLintConfigCode is reported for unknown rules and severities in a lint configuration file
+const InvalidLintConfigCode = "invalid-lint-config"
+
+// LintConfig is the content of a lint configuration file, which the lint subcommand applies beneath its
+// command line flags
+type LintConfig struct {
+	// Enable are the IDs of rules to run, besides those enabled by default
+	Enable []string `yaml:"enable"`
+	// Disable are the IDs of rules not to run, overriding Enable
+	Disable []string `yaml:"disable"`
+	// Severity overrides the severity of rules by ID
+	Severity map[string]string `yaml:"severity"`
+	// Ignore are the IDs of rules whose findings aren't reported for a service, by service name
+	Ignore map[string][]string `yaml:"ignore"`
+	// AllowMounts are the host paths the security-mount-paths rule allows services to bind mount
+	AllowMounts []string `yaml:"allow_mounts"`
+}
+
+// ReadLintConfig reads a lint configuration file, returning a ValidationError located in the file if it
+// refers to unknown rules or severities
+func ReadLintConfig(path string) (*LintConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to read lint configuration: %v", err), Err: err}
+	}
+	var config LintConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		location := cmp.Or(locate(err.Error(), nil), &Location{})
+		location.File, _ = filepath.Abs(path)
+		return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse lint configuration %s: %v", path, err), Location: location, Err: err}
+	}
+
+	var issues []targetIssue
+	checkRules := func(path string, ids []string) {
+		for i, id := range ids {
+			if !slices.ContainsFunc(LintRules, func(rule LintRule) bool { return rule.ID == id }) {
+				issues = append(issues, targetIssue{code: InvalidLintConfigCode, path: fmt.Sprintf("%s.%d", path, i), message: fmt.Sprintf(
+					"%s: unknown lint rule %q", path, id)})
+			}
+		}
+	}
+	checkRules("enable", config.Enable)
+	checkRules("disable", config.Disable)
+	for _, id := range slices.Sorted(maps.Keys(config.Severity)) {
+		switch severity := config.Severity[id]; {
+		case !slices.ContainsFunc(LintRules, func(rule LintRule) bool { return rule.ID == id }):
+			issues = append(issues, targetIssue{code: InvalidLintConfigCode, path: "severity." + id, message: fmt.Sprintf(
+				"severity: unknown lint rule %q", id)})
+		case !slices.Contains(Severities, severity):
+			issues = append(issues, targetIssue{code: InvalidLintConfigCode, path: "severity." + id, message: fmt.Sprintf(
+				"severity.%s must be error, warning or info, got %q", id, severity)})
+		}
+	}
+	for _, service := range slices.Sorted(maps.Keys(config.Ignore)) {
+		checkRules("ignore."+service, config.Ignore[service])
+	}
+	if err, _ := reportIssues("Invalid lint configuration "+path, issues, []string{path}); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// Options for Lint from the configuration, with the rules enabled and disabled and the severities of
+// options overriding those of the configuration, and the ignored rules and allowed mounts of both
+func (c *LintConfig) Options(options LintOptions) LintOptions {
+	merged := options
+	merged.Enable = slices.Concat(filterOut(c.Enable, options.Disable), options.Enable)
+	merged.Disable = slices.Concat(filterOut(c.Disable, options.Enable), options.Disable)
+	merged.Severities = map[string]string{}
+	maps.Copy(merged.Severities, c.Severity)
+	maps.Copy(merged.Severities, options.Severities)
+	merged.Ignore = map[string][]string{}
+	maps.Copy(merged.Ignore, c.Ignore)
+	for service, ids := range options.Ignore {
+		merged.Ignore[service] = slices.Concat(merged.Ignore[service], ids)
+	}
+	merged.AllowedMounts = slices.Concat(c.AllowMounts, options.AllowedMounts)
+	return merged
+}
+
+// The values which aren't in excluded
+func filterOut(values, excluded []string) []string {
+	return slices.DeleteFunc(slices.Clone(values), func(value string) bool { return slices.Contains(excluded, value) })
+}
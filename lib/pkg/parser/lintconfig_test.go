@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadLintConfig(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		LintConfigFile: "enable: [restart-policy]\ndisable: [resource-limits]\nseverity:\n  image-pinning: error\nignore:\n  db: [security-privileged]\nallow_mounts: [/mnt/boot]\n",
+		"empty.yml":    "",
+	})
+	config, err := ReadLintConfig(filepath.Join(dir, LintConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &LintConfig{
+		Enable:      []string{"restart-policy"},
+		Disable:     []string{"resource-limits"},
+		Severity:    map[string]string{"image-pinning": ErrorSeverity},
+		Ignore:      map[string][]string{"db": {"security-privileged"}},
+		AllowMounts: []string{"/mnt/boot"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("expected %+v, got %+v", expected, config)
+	}
+	if config, err := ReadLintConfig(filepath.Join(dir, "empty.yml")); err != nil || !reflect.DeepEqual(config, &LintConfig{}) {
+		t.Errorf("expected an empty configuration, got %+v: %v", config, err)
+	}
+}
+
+func TestReadLintConfigErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"unknown-field.yml": "enable: [restart-policy]\nrules: [image-pinning]\n",
+		"invalid.yml":       "enable: [restart]\nseverity:\n  image-pinning: fatal\n  tabs: error\nignore:\n  db: [privileged]\n",
+	})
+	_, err := ReadLintConfig(filepath.Join(dir, "missing.yml"))
+	expectError(t, err, IOError, "Failed to read lint configuration")
+
+	_, err = ReadLintConfig(filepath.Join(dir, "unknown-field.yml"))
+	if parserErr := expectError(t, err, ParseError, "Failed to parse lint configuration"); parserErr.Location == nil || parserErr.Location.Line != 2 || parserErr.Location.File != filepath.Join(dir, "unknown-field.yml") {
+		t.Errorf("expected the unknown field to be located, got %+v", parserErr.Location)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yml")
+	_, err = ReadLintConfig(invalid)
+	parserErr := expectError(t, err, ValidationError, "Invalid lint configuration "+invalid)
+	expected := []struct {
+		message string
+		line    int
+	}{
+		{message: `enable: unknown lint rule "restart"`, line: 1},
+		{message: `severity.image-pinning must be error, warning or info, got "fatal"`, line: 3},
+		{message: `severity: unknown lint rule "tabs"`, line: 4},
+		{message: `ignore.db: unknown lint rule "privileged"`, line: 6},
+	}
+	if len(parserErr.Errors) != len(expected) {
+		t.Fatalf("expected an error for each unknown rule and severity, got %v", parserErr.Errors)
+	}
+	for i, e := range parserErr.Errors {
+		if e.Code != InvalidLintConfigCode || e.Message != expected[i].message || e.Location == nil || e.Location.Line != expected[i].line {
+			t.Errorf("expected %q at line %d, got %s: %q at %+v", expected[i].message, expected[i].line, e.Code, e.Message, e.Location)
+		}
+	}
+}
+
+func TestLintConfigOptions(t *testing.T) {
+	config := &LintConfig{
+		Enable:      []string{"a", "b"},
+		Disable:     []string{"c", "d"},
+		Severity:    map[string]string{"a": ErrorSeverity, "c": InfoSeverity},
+		Ignore:      map[string][]string{"web": {"a"}, "db": {"b"}},
+		AllowMounts: []string{"/mnt/boot"},
+	}
+	// Options override the rules enabled and disabled and the severities of the configuration, and add to
+	// its ignored rules and allowed mounts
+	options := config.Options(LintOptions{
+		Enable:        []string{"c"},
+		Disable:       []string{"b"},
+		Severities:    map[string]string{"a": WarningSeverity},
+		Ignore:        map[string][]string{"web": {"c"}},
+		AllowedMounts: []string{"/etc"},
+	})
+	expected := LintOptions{
+		Enable:        []string{"a", "c"},
+		Disable:       []string{"d", "b"},
+		Severities:    map[string]string{"a": WarningSeverity, "c": InfoSeverity},
+		Ignore:        map[string][]string{"web": {"a", "c"}, "db": {"b"}},
+		AllowedMounts: []string{"/mnt/boot", "/etc"},
+	}
+	if !reflect.DeepEqual(options, expected) {
+		t.Errorf("expected %+v, got %+v", expected, options)
+	}
+	if !reflect.DeepEqual(config.Enable, []string{"a", "b"}) {
+		t.Errorf("expected the configuration not to be changed, got %+v", config)
+	}
+}
+
+func TestLintIgnore(t *testing.T) {
+	compose := "services:\n  web:\n    image: nginx:1.25\n    mem_limit: 256m\n    cpus: 0.5\n  webapp:\n    image: nginx:1.25\n    mem_limit: 256m\n    cpus: 0.5\n"
+	// Findings are ignored for the service only, not services whose names it prefixes
+	findings := lint(t, LintOptions{Ignore: map[string][]string{"web": {"restart-policy"}}}, compose)
+	if paths := findingPaths(findings); !reflect.DeepEqual(paths, []string{"restart-policy info services.webapp"}) {
+		t.Errorf("expected the findings of web to be ignored, got %v", paths)
+	}
+}
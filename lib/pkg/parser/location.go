@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Location is the position in a compose file an error refers to, as far as it can be determined
+type Location struct {
+	// File is the absolute path of the compose file
+	File string `json:"file,omitempty"`
+	// Line is the 1-based line number
+	Line int `json:"line,omitempty"`
+	// Column is the 1-based column number
+	Column int `json:"column,omitempty"`
+	// Path is the dot separated YAML path, e.g. services.web.image
+	Path string `json:"path,omitempty"`
+}
+
+// compose-go errors are unstructured, so locations are extracted from the formats of its messages
+var (
+	fileErrorPattern          = regexp.MustCompile(`(?:failed to parse|validating) (\S+?): `)
+	yamlLinePattern           = regexp.MustCompile(`yaml: (?:unmarshal errors:\s*)?line (\d+)`)
+	schemaPathPattern         = regexp.MustCompile(`validating \S+?: (\S+) `)
+	additionalPropertyPattern = regexp.MustCompile(`additional propert(?:y|ies) '([^',]+)'`)
+	interpolationPathPattern  = regexp.MustCompile(`(?:error while interpolating|invalid interpolation format for) (\S+?)(?:: |\.\n)`)
+	servicePattern            = regexp.MustCompile(`(?:^|: )service "?([^"\s:]+)"? `)
+)
+
+// Find the location an error message from compose-go refers to in the given compose files, or nil if unknown
+func locate(msg string, composeFiles []string) *Location {
+	location := &Location{}
+	if match := fileErrorPattern.FindStringSubmatch(msg); match != nil {
+		location.File = match[1]
+	}
+	if match := yamlLinePattern.FindStringSubmatch(msg); match != nil {
+		location.Line, _ = strconv.Atoi(match[1])
+		return location
+	}
+
+	switch {
+	case schemaPathPattern.MatchString(msg):
+		location.Path = schemaPathPattern.FindStringSubmatch(msg)[1]
+		if location.Path == "(root)" {
+			location.Path = ""
+		}
+		if match := additionalPropertyPattern.FindStringSubmatch(msg); match != nil {
+			location.Path = joinPath(location.Path, match[1])
+		}
+	case interpolationPathPattern.MatchString(msg):
+		location.Path = interpolationPathPattern.FindStringSubmatch(msg)[1]
+	case servicePattern.MatchString(msg):
+		location.Path = "services." + servicePattern.FindStringSubmatch(msg)[1]
+	}
+	if location.Path == "" {
+		if location.File == "" {
+			return nil
+		}
+		return location
+	}
+
+	// Resolve the path to a position in the file it was reported for, or else the first file which defines it
+	files := composeFiles
+	if location.File != "" {
+		files = []string{location.File}
+	}
+	for _, file := range files {
+		if file == StdinPath || strings.HasPrefix(file, "https://") {
+			continue
+		}
+		if node := findYAMLPath(file, location.Path); node != nil {
+			location.File, _ = filepath.Abs(file)
+			location.Line, location.Column = node.Line, node.Column
+			break
+		}
+	}
+	return location
+}
+
+// Find the node at a dot separated path in any document of a YAML file. For mapping entries the
+// key node is returned, as that's where the entry starts. List indices, and "[]" which compose-go
+// uses for any index, are resolved as far as possible.
+func findYAMLPath(file, path string) *yaml.Node {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		if err := decoder.Decode(&document); err != nil {
+			return nil
+		}
+		if len(document.Content) == 0 {
+			continue
+		}
+		if node := findNode(document.Content[0], strings.Split(path, ".")); node != nil {
+			return node
+		}
+	}
+}
+
+func findNode(node *yaml.Node, keys []string) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if len(keys) == 0 {
+		return node
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			// Keys such as label names may contain dots, so may be several keys of the path
+			for n := 1; n <= len(keys); n++ {
+				if node.Content[i].Value != strings.Join(keys[:n], ".") {
+					continue
+				}
+				if n == len(keys) {
+					return node.Content[i]
+				}
+				if found := findNode(node.Content[i+1], keys[n:]); found != nil {
+					return found
+				}
+			}
+		}
+	case yaml.SequenceNode:
+		i, err := strconv.Atoi(keys[0])
+		if err != nil && len(keys) == 1 {
+			// Entries of sequences merged by key, e.g. environment, are located by their key
+			for _, entry := range node.Content {
+				if entry.Kind == yaml.ScalarNode && (entry.Value == keys[0] || strings.HasPrefix(entry.Value, keys[0]+"=") || strings.HasPrefix(entry.Value, keys[0]+":")) {
+					return entry
+				}
+			}
+		}
+		if err != nil || i >= len(node.Content) {
+			// The index is unknown, but the error is somewhere in this list
+			return node
+		}
+		return findNode(node.Content[i], keys[1:])
+	}
+	return nil
+}
+
+// Locate a dot separated path in the first local compose file which defines it. Paths of values which
+// compose-go derived, so aren't in any file, are located at their closest ancestor which is.
+func locatePath(path string, composeFiles []string) *Location {
+	for keys := strings.Split(path, "."); len(keys) > 0; keys = keys[:len(keys)-1] {
+		for _, file := range composeFiles {
+			if file == StdinPath || strings.HasPrefix(file, "https://") {
+				continue
+			}
+			if node := findYAMLPath(file, strings.Join(keys, ".")); node != nil {
+				location := &Location{Path: path, Line: node.Line, Column: node.Column}
+				location.File, _ = filepath.Abs(file)
+				return location
+			}
+		}
+	}
+	return &Location{Path: path}
+}
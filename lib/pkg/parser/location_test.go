@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml":          "services:\n  web:\n    image: nginx\n    ports:\n      - 80:80\n    unknown: true\n",
+		"compose.override.yml": "services:\n  db:\n    image: postgres\n    environment:\n      A: ${A\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	override := filepath.Join(dir, "compose.override.yml")
+	composeFiles := []string{composeFile, override}
+
+	tests := []struct {
+		name     string
+		msg      string
+		expected *Location
+	}{
+		{
+			name:     "YAML error",
+			msg:      "failed to parse " + override + ": yaml: line 5: did not find expected key",
+			expected: &Location{File: override, Line: 5},
+		},
+		{
+			name:     "YAML unmarshal error",
+			msg:      "failed to parse " + composeFile + ": yaml: unmarshal errors:\n  line 3: mapping key \"image\" already defined",
+			expected: &Location{File: composeFile, Line: 3},
+		},
+		{
+			name:     "schema error",
+			msg:      "validating " + composeFile + ": services.web.ports.0 must be a mapping",
+			expected: &Location{File: composeFile, Line: 5, Column: 9, Path: "services.web.ports.0"},
+		},
+		{
+			name:     "additional property",
+			msg:      "validating " + composeFile + ": services.web additional properties 'unknown' not allowed",
+			expected: &Location{File: composeFile, Line: 6, Column: 5, Path: "services.web.unknown"},
+		},
+		{
+			name:     "root additional property",
+			msg:      "validating " + composeFile + ": (root) additional properties 'other' not allowed",
+			expected: &Location{File: composeFile, Path: "other"},
+		},
+		{
+			name:     "interpolation error",
+			msg:      "invalid interpolation format for services.db.environment.A: ${A",
+			expected: &Location{File: override, Line: 5, Column: 7, Path: "services.db.environment.A"},
+		},
+		{
+			name:     "service error",
+			msg:      `service "db" depends on undefined service "cache": invalid compose project`,
+			expected: &Location{File: override, Line: 2, Column: 3, Path: "services.db"},
+		},
+		{
+			name:     "unknown path",
+			msg:      `service "cache" refers to undefined network "back": invalid compose project`,
+			expected: &Location{Path: "services.cache"},
+		},
+		{name: "unknown", msg: "context deadline exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location := locate(tt.msg, composeFiles)
+			if tt.expected == nil {
+				if location != nil {
+					t.Errorf("expected no location, got %+v", location)
+				}
+			} else if location == nil || *location != *tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, location)
+			}
+		})
+	}
+}
+
+func TestFindYAMLPath(t *testing.T) {
+	file := filepath.Join(writeFiles(t, map[string]string{
+		"compose.yml": "x-base: &base\n  image: nginx\nservices:\n  web:\n    <<: *base\n    command: [a, b]\n    labels:\n      io.balena.features.kernel-modules: \"1\"\n---\nservices:\n  db:\n    image: postgres\n    environment: [A=1, B]\n",
+	}), "compose.yml")
+	tests := []struct {
+		path   string
+		line   int
+		column int
+	}{
+		{path: "services.web", line: 4, column: 3},
+		{path: "services.web.command.1", line: 6, column: 18},
+		// compose-go reports any index as []
+		{path: "services.web.command.[]", line: 6, column: 14},
+		{path: "x-base.image", line: 2, column: 3},
+		{path: "services.web.labels.io.balena.features.kernel-modules", line: 8, column: 7},
+		{path: "services.db.image", line: 12, column: 5},
+		// Entries of sequences merged by key are found by key
+		{path: "services.db.environment.A", line: 13, column: 19},
+		{path: "services.db.environment.B", line: 13, column: 24},
+		{path: "services.db.environment.C", line: 13, column: 18},
+		{path: "services.cache"},
+	}
+	for _, tt := range tests {
+		node := findYAMLPath(file, tt.path)
+		if tt.line == 0 {
+			if node != nil {
+				t.Errorf("expected %s not to be found, got line %d", tt.path, node.Line)
+			}
+		} else if node == nil || node.Line != tt.line || node.Column != tt.column {
+			t.Errorf("expected %s at %d:%d, got %+v", tt.path, tt.line, tt.column, node)
+		}
+	}
+}
+
+func TestLocatePath(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml":          "services:\n  web:\n    image: nginx\n",
+		"compose.override.yml": "services:\n  web:\n    build: .\n",
+	})
+	composeFiles := []string{StdinPath, filepath.Join(dir, "compose.yml"), filepath.Join(dir, "compose.override.yml")}
+	tests := []struct {
+		path     string
+		expected Location
+	}{
+		{path: "services.web.build", expected: Location{File: composeFiles[2], Line: 3, Column: 5, Path: "services.web.build"}},
+		// Derived values are located at their closest ancestor in the files
+		{path: "services.web.build.context", expected: Location{File: composeFiles[2], Line: 3, Column: 5, Path: "services.web.build.context"}},
+		{path: "services.web.networks", expected: Location{File: composeFiles[1], Line: 2, Column: 3, Path: "services.web.networks"}},
+		{path: "networks.default", expected: Location{Path: "networks.default"}},
+	}
+	for _, tt := range tests {
+		if location := locatePath(tt.path, composeFiles); *location != tt.expected {
+			t.Errorf("expected %+v, got %+v", tt.expected, *location)
+		}
+	}
+}
+
+func TestErrorLocation(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"compose.yml": "services:\n  web:\n    image: nginx\n    ports: true\n"})
+	_, err := New(Options{ProjectName: "test"}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	expected := Location{File: filepath.Join(dir, "compose.yml"), Line: 4, Column: 5, Path: "services.web.ports"}
+	if parserErr := expectError(t, err, ValidationError, ""); parserErr.Location == nil || *parserErr.Location != expected {
+		t.Errorf("expected the error to be located at %+v, got %+v", expected, parserErr.Location)
+	}
+}
@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"errors"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/sirupsen/logrus"
+)
+
+// MaskedValue replaces the values of variables masked with Options.MaskEnv
+const MaskedValue = "***"
+
+// DefaultMaskPatterns are the names of variables which commonly hold secrets
+var DefaultMaskPatterns = []string{"*_TOKEN", "*_PASSWORD", "*_SECRET", "*_API_KEY", "*_PRIVATE_KEY"}
+
+// Values shorter than this aren't masked in logs, as they'd mask unrelated text
+const minMaskedLogValue = 4
+
+// Whether a variable name matches any of the glob patterns, ignoring case
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToUpper(pattern), strings.ToUpper(name)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// The secret values of a parse, i.e. those of variables masked with Options.MaskEnv, which are replaced
+// with MaskedValue in its result and errors, and in logs while it runs
+type masker struct {
+	patterns []string
+	mu       sync.RWMutex
+	values   map[string]bool
+	// Whether the masker is registered with logMask, on adding its first value
+	registered bool
+}
+
+func newMasker(patterns []string) *masker {
+	return &masker{patterns: patterns, values: map[string]bool{}}
+}
+
+// Add the values of the masked variables of an environment
+func (m *masker) addEnvironment(environment types.Mapping) {
+	for name, value := range environment {
+		if matchesAny(m.patterns, name) {
+			m.add(value)
+		}
+	}
+}
+
+func (m *masker) add(value string) {
+	if len(value) < minMaskedLogValue {
+		return
+	}
+	m.mu.Lock()
+	m.values[value] = true
+	register := !m.registered
+	m.registered = true
+	m.mu.Unlock()
+	if register {
+		logMask().register(m)
+	}
+}
+
+// Stop masking the values in logs, once the parse is done
+func (m *masker) close() {
+	m.mu.RLock()
+	registered := m.registered
+	m.mu.RUnlock()
+	if registered {
+		logMask().unregister(m)
+	}
+}
+
+// Replace the values in a string
+func (m *masker) mask(s string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for value := range m.values {
+		s = strings.ReplaceAll(s, value, MaskedValue)
+	}
+	return s
+}
+
+// Replace the values in the messages of an error
+func (m *masker) maskError(err error) error {
+	var e *Error
+	if errors.As(err, &e) {
+		e.Message = m.mask(e.Message)
+		for _, detail := range e.Errors {
+			detail.Message = m.mask(detail.Message)
+		}
+	}
+	return err
+}
+
+// Replace the masked variables in the environment and build args of each service, whatever their
+// values, and add the values to be replaced in every other field
+func (m *masker) maskProject(project *types.Project) {
+	mask := func(mapping types.MappingWithEquals) {
+		for name, value := range mapping {
+			if value != nil && matchesAny(m.patterns, name) {
+				m.add(*value)
+				masked := MaskedValue
+				mapping[name] = &masked
+			}
+		}
+	}
+	for name, service := range project.Services {
+		mask(service.Environment)
+		if service.Build != nil {
+			mask(service.Build.Args)
+		}
+		project.Services[name] = service
+	}
+}
+
+// Replace the values in every string of the result, e.g. interpolated into commands and labels, and the
+// masked build args of Result.Builds
+func (m *masker) maskResult(result *Result) {
+	for _, build := range result.Builds {
+		for name := range build.Args {
+			if matchesAny(m.patterns, name) {
+				build.Args[name] = MaskedValue
+			}
+		}
+	}
+	m.mu.RLock()
+	masked := len(m.values) > 0
+	m.mu.RUnlock()
+	if masked {
+		m.maskStrings(reflect.ValueOf(result).Elem())
+	}
+}
+
+// Replace the values in every string of an addressable value. Map values aren't addressable, so are
+// replaced with masked copies.
+func (m *masker) maskStrings(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(m.mask(v.String()))
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			m.maskStrings(v.Elem())
+		}
+	case reflect.Interface:
+		if !v.IsNil() && v.CanSet() {
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			m.maskStrings(elem)
+			v.Set(elem)
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if field := v.Field(i); field.CanSet() {
+				m.maskStrings(field)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			m.maskStrings(v.Index(i))
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			m.maskStrings(elem)
+			v.SetMapIndex(key, elem)
+		}
+	}
+}
+
+// logrus hook masking the secret values of the parses running in log messages, including compose-go's.
+// Log entries can't be attributed to a parse when several run concurrently, so the values of each are
+// masked in all entries while it runs, and dropped once it's done.
+type maskHook struct {
+	mu      sync.RWMutex
+	maskers map[*masker]bool
+}
+
+var logMask = sync.OnceValue(func() *maskHook {
+	hook := &maskHook{maskers: map[*masker]bool{}}
+	logrus.AddHook(hook)
+	return hook
+})
+
+func (h *maskHook) register(m *masker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maskers[m] = true
+}
+
+func (h *maskHook) unregister(m *masker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.maskers, m)
+}
+
+func (h *maskHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Replace the values of the running parses in a message
+func (h *maskHook) mask(message string) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for m := range h.maskers {
+		message = m.mask(message)
+	}
+	return message
+}
+
+func (h *maskHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.mask(entry.Message)
+	for key, field := range entry.Data {
+		if s, ok := field.(string); ok {
+			entry.Data[key] = h.mask(s)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMatchesAny(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"GITHUB_TOKEN": true,
+		"github_token": true,
+		"DB_PASSWORD":  true,
+		"TOKEN":        false,
+		"TOKEN_FILE":   false,
+		"AWS_API_KEY":  true,
+	} {
+		if matched := matchesAny(DefaultMaskPatterns, name); matched != expected {
+			t.Errorf("expected %s to match %t, got %t", name, expected, matched)
+		}
+	}
+	if matchesAny(nil, "GITHUB_TOKEN") {
+		t.Error("expected no patterns not to match")
+	}
+}
+
+func TestMaskEnv(t *testing.T) {
+	result := mustParse(t, Options{MaskEnv: DefaultMaskPatterns, Builds: true, Environment: map[string]string{"API_TOKEN": "interpolated-token", "TAG": "1.25"}},
+		"services:\n"+
+			"  web:\n"+
+			"    image: nginx:${TAG}\n"+
+			"    command: [serve, --token, \"${API_TOKEN}\"]\n"+
+			"    labels:\n      auth: \"Bearer ${API_TOKEN}\"\n"+
+			"    environment:\n      API_TOKEN: ${API_TOKEN}\n      DB_PASSWORD: literal-password\n      DB_USER: admin\n"+
+			"  db:\n"+
+			"    image: postgres\n"+
+			"    command: [\"--password=literal-password\"]\n"+
+			"    build:\n      context: .\n      args:\n        NPM_TOKEN: build-token\n        VERSION: \"1\"\n",
+	)
+	web, db := result.Project.Services["web"], result.Project.Services["db"]
+	for name, value := range map[string]*string{"API_TOKEN": web.Environment["API_TOKEN"], "DB_PASSWORD": web.Environment["DB_PASSWORD"], "NPM_TOKEN": db.Build.Args["NPM_TOKEN"]} {
+		if value == nil || *value != MaskedValue {
+			t.Errorf("expected %s to be masked, got %v", name, value)
+		}
+	}
+	if user := web.Environment["DB_USER"]; user == nil || *user != "admin" || web.Image != "nginx:1.25" || *db.Build.Args["VERSION"] != "1" {
+		t.Errorf("expected other variables not to be masked, got %+v", web)
+	}
+	// Values of masked variables are masked wherever they occur
+	if command := strings.Join(web.Command, " "); command != "serve --token ***" {
+		t.Errorf("expected the interpolated token to be masked in the command, got %q", command)
+	}
+	if auth := web.Labels["auth"]; auth != "Bearer ***" {
+		t.Errorf("expected the interpolated token to be masked in the labels, got %q", auth)
+	}
+	if command := strings.Join(db.Command, " "); command != "--password=***" {
+		t.Errorf("expected the password of another service to be masked, got %q", command)
+	}
+	if args := result.Builds["db"].Args; args["NPM_TOKEN"] != MaskedValue || args["VERSION"] != "1" {
+		t.Errorf("expected the build arg to be masked in the build report, got %v", args)
+	}
+
+	result = mustParse(t, Options{Environment: map[string]string{"API_TOKEN": "interpolated-token"}}, "services:\n  web:\n    image: nginx\n    environment:\n      API_TOKEN: ${API_TOKEN}\n")
+	if token := result.Project.Services["web"].Environment["API_TOKEN"]; token == nil || *token != "interpolated-token" {
+		t.Errorf("expected nothing to be masked without Options.MaskEnv, got %v", token)
+	}
+}
+
+func TestMaskEnvErrors(t *testing.T) {
+	_, err := parse(t, Options{MaskEnv: []string{"*_TOKEN"}, Environment: map[string]string{"API_TOKEN": "secret-token"}},
+		"services:\n  web:\n    image: nginx\n    ports: [\"${API_TOKEN}\"]\n")
+	parserErr := expectError(t, err, ParseError, "")
+	if strings.Contains(parserErr.Message, "secret-token") || !strings.Contains(parserErr.Message, MaskedValue) {
+		t.Errorf("expected the token to be masked in the error, got %q", parserErr.Message)
+	}
+}
+
+func TestMaskLogs(t *testing.T) {
+	var logs bytes.Buffer
+	logrus.SetOutput(&logs)
+	t.Cleanup(func() { logrus.SetOutput(os.Stderr) })
+
+	masks := newMasker([]string{"*_TOKEN"})
+	masks.addEnvironment(map[string]string{"API_TOKEN": "secret-token", "SHORT_TOKEN": "abc", "TAG": "latest"})
+	logrus.WithField("value", "secret-token").Warn("using secret-token with abc and latest")
+	if output := logs.String(); strings.Contains(output, "secret-token") || !strings.Contains(output, "using *** with abc and latest") || !strings.Contains(output, "value=\"***\"") {
+		t.Errorf("expected the token to be masked in logs, and short values not to be, got %q", output)
+	}
+
+	// Values are only masked while their parse runs
+	masks.close()
+	logs.Reset()
+	logrus.Warn("using secret-token")
+	if output := logs.String(); !strings.Contains(output, "using secret-token") {
+		t.Errorf("expected the token not to be masked once the parse is done, got %q", output)
+	}
+}
@@ -0,0 +1,224 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// MigratedFieldCode is reported as a warning for each field Migrate converts into its compose spec
+// equivalent, other than the fields the compose spec dropped, which are reported with LegacyFieldCode
+const MigratedFieldCode = "migrated-field"
+
+// Top-level keys of the compose spec and the 2.x and 3.x formats, which a file of the 1 format, listing
+// services at the top level, doesn't have
+var composeTopLevelKeys = []string{"version", "name", "include", "services", "networks", "volumes", "configs", "secrets", "models"}
+
+// Service resource limits of the 2.x format and the deploy.resources fields they're moved into
+var migratedResources = []struct {
+	field, section, key string
+}{
+	{"cpus", "limits", "cpus"},
+	{"mem_limit", "limits", "memory"},
+	{"pids_limit", "limits", "pids"},
+	{"mem_reservation", "reservations", "memory"},
+}
+
+// Migrate rewrites the content of a compose file of the 1, 2.x or 3.x format as a compose spec file,
+// returning the YAML and a warning for each field converted, located in composeFile. Comments and the
+// order of keys are kept. Besides the conversions of fields the compose spec dropped made when parsing:
+//
+//   - version is removed, and the services of files of the 1 format are moved under services, with
+//     the named volumes they mount declared as top-level volumes
+//   - links are converted into depends_on, with the aliases they set added to the network aliases
+//     of the linked services on the default network
+//   - cpus, mem_limit, pids_limit and mem_reservation are moved into deploy.resources
+func Migrate(content []byte, composeFile string) ([]byte, []Warning, error) {
+	var documents []*yaml.Node
+	var issues []targetIssue
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		var document yaml.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse %s: %v", composeFile, err), Err: err}
+		}
+		documents = append(documents, &document)
+		if len(document.Content) > 0 && document.Content[0].Kind == yaml.MappingNode {
+			issues = append(issues, migrateDocument(document.Content[0])...)
+		}
+	}
+
+	var migrated bytes.Buffer
+	encoder := yaml.NewEncoder(&migrated)
+	encoder.SetIndent(2)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to write migrated %s: %v", composeFile, err), Err: err}
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to write migrated %s: %v", composeFile, err), Err: err}
+	}
+	_, warnings := reportIssues("", issues, []string{composeFile})
+	return migrated.Bytes(), warnings, nil
+}
+
+// Whether a document is of the 1 format, a mapping of services without any top-level key of later formats
+func isVersion1(document *yaml.Node) bool {
+	if len(document.Content) == 0 {
+		return false
+	}
+	for i := 0; i+1 < len(document.Content); i += 2 {
+		key, value := document.Content[i].Value, document.Content[i+1]
+		if slices.Contains(composeTopLevelKeys, key) || (value.Kind != yaml.MappingNode && !strings.HasPrefix(key, "x-")) {
+			return false
+		}
+	}
+	return true
+}
+
+func migrateDocument(document *yaml.Node) []targetIssue {
+	var issues []targetIssue
+	migrated := func(path, message string, args ...any) {
+		issues = append(issues, targetIssue{code: MigratedFieldCode, path: path, message: fmt.Sprintf(message, args...), warning: true})
+	}
+
+	// Services of the 1 format are at the top level, so their paths in the file aren't prefixed
+	prefix := "services."
+	if isVersion1(document) {
+		prefix = ""
+		services := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: document.Content}
+		volumes := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for i := 0; i+1 < len(services.Content); i += 2 {
+			_, mounts := mappingEntry(services.Content[i+1], "volumes")
+			for _, source := range namedVolumeSources(mounts) {
+				if _, declared := mappingEntry(volumes, source); declared == nil {
+					volumes.Content = append(volumes.Content, scalarNode(source), &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+				}
+			}
+		}
+		document.Content = []*yaml.Node{scalarNode("services"), services}
+		if len(volumes.Content) > 0 {
+			document.Content = append(document.Content, scalarNode("volumes"), volumes)
+		}
+		migrated(services.Content[0].Value, "Services of the version 1 format are moved under services")
+	} else if _, version := mappingEntry(document, "version"); version != nil {
+		removeMappingEntry(document, "version")
+		migrated("version", "version is removed, as the compose spec doesn't use it")
+	}
+
+	_, services := mappingEntry(document, "services")
+	_, volumes := mappingEntry(document, "volumes")
+	issues = append(issues, convertLegacyServices(services, volumes, prefix)...)
+	if services == nil || services.Kind != yaml.MappingNode {
+		return issues
+	}
+
+	// Services on the default network are found before aliases are added to their networks
+	defaultNetwork := map[string]bool{}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		defaultNetwork[services.Content[i].Value] = onDefaultNetwork(services.Content[i+1])
+	}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, service := services.Content[i].Value, services.Content[i+1]
+		if service.Kind != yaml.MappingNode {
+			continue
+		}
+		path := prefix + name
+
+		if _, links := mappingEntry(service, "links"); links != nil && links.Kind == yaml.SequenceNode {
+			for _, link := range links.Content {
+				linked, alias, _ := strings.Cut(link.Value, ":")
+				addDependency(service, linked)
+				if alias == "" || alias == linked {
+					continue
+				}
+				if !defaultNetwork[name] || !defaultNetwork[linked] {
+					migrated(path+".links", "%s.links alias %s of %s isn't converted, as the services aren't both on the default network", path, alias, linked)
+					continue
+				}
+				_, linkedService := mappingEntry(services, linked)
+				addDefaultNetworkAlias(linkedService, alias)
+			}
+			removeMappingEntry(service, "links")
+			migrated(path+".links", "%s.links is converted to depends_on and network aliases", path)
+		}
+
+		for _, resource := range migratedResources {
+			_, value := mappingEntry(service, resource.field)
+			if value == nil {
+				continue
+			}
+			section := childMapping(childMapping(childMapping(service, "deploy"), "resources"), resource.section)
+			if _, set := mappingEntry(section, resource.key); set == nil {
+				section.Content = append(section.Content, scalarNode(resource.key), value)
+			}
+			removeMappingEntry(service, resource.field)
+			migrated(path+"."+resource.field, "%s.%s is moved to deploy.resources.%s.%s", path, resource.field, resource.section, resource.key)
+		}
+	}
+	return issues
+}
+
+// The mapping node of a key of a mapping node, which is added if it isn't set or isn't a mapping
+func childMapping(node *yaml.Node, key string) *yaml.Node {
+	_, child := mappingEntry(node, key)
+	if child == nil {
+		child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		node.Content = append(node.Content, scalarNode(key), child)
+	} else if child.Kind != yaml.MappingNode {
+		*child = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	return child
+}
+
+// Add a service to the depends_on of a service, in its short or long syntax, if it isn't already listed
+func addDependency(service *yaml.Node, dependency string) {
+	_, dependsOn := mappingEntry(service, "depends_on")
+	switch {
+	case dependsOn == nil:
+		service.Content = append(service.Content, scalarNode("depends_on"),
+			&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{scalarNode(dependency)}})
+	case dependsOn.Kind == yaml.SequenceNode:
+		if !slices.ContainsFunc(dependsOn.Content, func(node *yaml.Node) bool { return node.Value == dependency }) {
+			dependsOn.Content = append(dependsOn.Content, scalarNode(dependency))
+		}
+	case dependsOn.Kind == yaml.MappingNode:
+		if _, listed := mappingEntry(dependsOn, dependency); listed == nil {
+			condition := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{scalarNode("condition"), scalarNode("service_started")}}
+			dependsOn.Content = append(dependsOn.Content, scalarNode(dependency), condition)
+		}
+	}
+}
+
+// Whether a service is only attached to the default network, as it doesn't set networks or a network mode
+func onDefaultNetwork(service *yaml.Node) bool {
+	if service.Kind != yaml.MappingNode {
+		return false
+	}
+	_, networks := mappingEntry(service, "networks")
+	_, networkMode := mappingEntry(service, "network_mode")
+	return networks == nil && networkMode == nil
+}
+
+// Add an alias to a service on the default network, which it's only attached to
+func addDefaultNetworkAlias(service *yaml.Node, alias string) {
+	aliases := childMapping(childMapping(service, "networks"), balenaDefaultNetwork)
+	_, list := mappingEntry(aliases, "aliases")
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		aliases.Content = append(aliases.Content, scalarNode("aliases"), list)
+	}
+	if !slices.ContainsFunc(list.Content, func(node *yaml.Node) bool { return node.Value == alias }) {
+		list.Content = append(list.Content, scalarNode(alias))
+	}
+}
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		warnings []string
+	}{
+		{
+			name: "2.x format",
+			content: "version: \"2.1\"\n# the web service\nservices:\n  web:\n    image: nginx\n    links: [\"cache:redis\", db]\n    mem_limit: 512m\n    log_driver: syslog\n" +
+				"  db:\n    image: postgres\n  cache:\n    image: redis\n",
+			expected: "# the web service\nservices:\n  web:\n    image: nginx\n    logging:\n      driver: syslog\n    depends_on:\n      - cache\n      - db\n    deploy:\n      resources:\n        limits:\n          memory: 512m\n" +
+				"  db:\n    image: postgres\n  cache:\n    image: redis\n    networks:\n      default:\n        aliases:\n          - redis\n",
+			warnings: []string{"migrated-field version", "legacy-field services.web.log_driver", "migrated-field services.web.links", "migrated-field services.web.mem_limit"},
+		},
+		{
+			name:     "1 format",
+			content:  "web:\n  image: nginx\n  volumes: [\"data:/data\", \"./config:/config\"]\n  links: [db]\ndb:\n  image: postgres\n",
+			expected: "services:\n  web:\n    image: nginx\n    volumes: [\"data:/data\", \"./config:/config\"]\n    depends_on:\n      - db\n  db:\n    image: postgres\nvolumes:\n  data: {}\n",
+			warnings: []string{"migrated-field web", "migrated-field web.links"},
+		},
+		{
+			// Aliases are only added on the default network, which host networking leaves
+			name:     "links with host networking",
+			content:  "services:\n  web:\n    image: nginx\n    network_mode: host\n    links: [\"db:database\"]\n  db:\n    image: postgres\n",
+			expected: "services:\n  web:\n    image: nginx\n    network_mode: host\n    depends_on:\n      - db\n  db:\n    image: postgres\n",
+			warnings: []string{"migrated-field services.web.links", "migrated-field services.web.links"},
+		},
+		{
+			name:     "compose spec",
+			content:  "services:\n  web:\n    image: nginx\n",
+			expected: "services:\n  web:\n    image: nginx\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composeFile := filepath.Join(writeFiles(t, map[string]string{"compose.yml": tt.content}), "compose.yml")
+			migrated, warnings, err := Migrate([]byte(tt.content), composeFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(migrated) != tt.expected {
+				t.Errorf("expected\n%s\ngot\n%s", tt.expected, migrated)
+			}
+			var issues []string
+			for _, warning := range warnings {
+				issues = append(issues, warning.Code+" "+warning.Location.Path)
+				if warning.Location.File != composeFile || warning.Location.Line == 0 {
+					t.Errorf("expected %s to be located in the compose file, got %+v", warning.Message, warning.Location)
+				}
+			}
+			if !reflect.DeepEqual(issues, tt.warnings) {
+				t.Errorf("expected the warnings %v, got %v", tt.warnings, issues)
+			}
+		})
+	}
+
+	_, _, err := Migrate([]byte("services: [\n"), "compose.yml")
+	expectError(t, err, ParseError, "Failed to parse compose.yml")
+}
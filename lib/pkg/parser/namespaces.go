@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// InvalidNamespaceModeCode is reported for references of pid and ipc to services which don't exist, and with
+// Options.Target balena, for pid, ipc, uts, userns_mode and cgroup values the engine doesn't accept, and
+// combinations of them the engine rejects
+const InvalidNamespaceModeCode = "invalid-namespace-mode"
+
+// The error compose-go fails to load projects with if a service references an undefined service, which
+// is also implied by pid: service:<name> and ipc: service:<name>
+const composeUndefinedServiceError = "depends on undefined service"
+
+// Values of the namespace mode fields besides service:<name> and container:<name>, for the fields which
+// accept those
+var namespaceModes = []struct {
+	field      string
+	modes      []string
+	references bool
+}{
+	{"pid", []string{"host"}, true},
+	{"ipc", []string{"none", "private", "shareable", "host"}, true},
+	{"uts", []string{"host"}, false},
+	{"userns_mode", []string{"host"}, false},
+	{"cgroup", []string{"host", "private"}, false},
+}
+
+// The namespace mode fields of a service, by field
+func serviceNamespaceModes(service types.ServiceConfig) map[string]string {
+	return map[string]string{
+		"pid": service.Pid, "ipc": service.Ipc, "uts": service.Uts, "userns_mode": service.UserNSMode, "cgroup": service.Cgroup,
+	}
+}
+
+// The issues of the namespace modes of every service: the service:<name> form referencing the service itself
+// or, unless skipReferences is set, a service which doesn't exist. For balena, also unknown values, ipc:
+// service:<name> for a service whose IPC namespace isn't shareable, as the balena engine's default-ipc-mode
+// is private, and uts: host with a hostname. cgroup is checked with the others, though the compose spec
+// schema already rejects unknown values when loading.
+func namespaceModeIssues(project *types.Project, skipReferences, balena bool) []targetIssue {
+	var issues []targetIssue
+	fail := func(path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: InvalidNamespaceModeCode, path: path, message: fmt.Sprintf(format, args...)})
+	}
+	services := maps.Clone(project.Services)
+	maps.Copy(services, project.DisabledServices)
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		service := project.Services[name]
+		path := "services." + name
+		modes := serviceNamespaceModes(service)
+		for _, field := range namespaceModes {
+			mode, fieldPath := modes[field.field], path+"."+field.field
+			if mode == "" || slices.Contains(field.modes, mode) {
+				continue
+			}
+			if !field.references {
+				if balena {
+					fail(fieldPath, "%s must be %s, got %q", fieldPath, strings.Join(field.modes, " or "), mode)
+				}
+				continue
+			}
+			if container, ok := strings.CutPrefix(mode, "container:"); ok && container != "" {
+				continue
+			}
+			on, ok := strings.CutPrefix(mode, "service:")
+			_, defined := services[on]
+			switch {
+			case !ok || on == "":
+				if !balena {
+					continue
+				}
+				fail(fieldPath, "%s must be one of %s, service:<name> or container:<name>, got %q", fieldPath, strings.Join(field.modes, ", "), mode)
+			case on == name:
+				fail(fieldPath, "%s can't reference the service itself", fieldPath)
+			case !defined:
+				if !skipReferences {
+					fail(fieldPath, "%s references service %q, which doesn't exist", fieldPath, on)
+				}
+			case balena && field.field == "ipc" && services[on].Ipc != "shareable" && services[on].Ipc != "host":
+				fail(fieldPath, "%s can't join the IPC namespace of service %q, unless it sets ipc: shareable", fieldPath, on)
+			}
+		}
+		if balena && service.Uts == "host" && service.Hostname != "" {
+			fail(path+".hostname", "%s.hostname can't be set with uts: host, as the service has the hostname of the host", path)
+		}
+	}
+	return issues
+}
+
+// Find the references of namespace modes to undefined services compose-go reported, by loading the project
+// again without its consistency checks, returning a ValidationError for them, or nil if there are none or
+// the project fails to load again. The checks of balena aren't reported, as the project isn't loaded.
+func namespaceModeError(ctx context.Context, options *cli.ProjectOptions, composeFiles []string) *Error {
+	if err := cli.WithConsistency(false)(options); err != nil {
+		return nil
+	}
+	project, err := options.LoadProject(ctx)
+	if err != nil {
+		return nil
+	}
+	namespaceErr, _ := reportIssues("Failed to parse compose file", namespaceModeIssues(project, false, false), composeFiles)
+	return namespaceErr
+}
+
+// Check the namespace modes of every service for balena, returning a ValidationError listing each invalid mode
+func checkNamespaceModes(project *types.Project, skipReferences bool, composeFiles []string) *Error {
+	err, _ := reportIssues("Invalid namespace modes", namespaceModeIssues(project, skipReferences, true), composeFiles)
+	return err
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamespaceModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		web      string
+		expected []string
+	}{
+		{name: "supported", web: "pid: host\n    ipc: shareable\n    uts: host\n    userns_mode: host\n    cgroup: private\n"},
+		{
+			name:     "unknown values",
+			web:      "pid: private\n    ipc: shared\n    uts: private\n    userns_mode: keep-id\n",
+			expected: []string{"invalid-namespace-mode services.web.pid", "invalid-namespace-mode services.web.ipc", "invalid-namespace-mode services.web.uts", "invalid-namespace-mode services.web.userns_mode"},
+		},
+		{
+			// The balena engine's default IPC mode is private
+			name:     "unshareable IPC namespace",
+			web:      "ipc: service:db\n",
+			expected: []string{"invalid-namespace-mode services.web.ipc"},
+		},
+		{
+			name:     "host UTS namespace",
+			web:      "uts: host\n    hostname: web\n",
+			expected: []string{"invalid-namespace-mode services.web.hostname"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compose := "services:\n  web:\n    image: nginx\n    " + tt.web + "  db:\n    image: postgres\n"
+			errs, _ := targetIssues(t, Options{Target: BalenaTarget}, compose)
+			if strings.Join(errs, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("expected %v, got %v", tt.expected, errs)
+			}
+		})
+	}
+
+	// compose-go reports references to the service itself as dependency cycles, unless its consistency checks
+	// are skipped
+	_, err := parse(t, Options{Target: BalenaTarget, SkipConsistency: true}, "services:\n  web:\n    image: nginx\n    pid: service:web\n")
+	expectError(t, err, ValidationError, "services.web.pid can't reference the service itself")
+
+	// Values are only checked for balena, while references to undefined services always fail
+	if _, err := parse(t, Options{}, "services:\n  web:\n    image: nginx\n    ipc: service:db\n    pid: container:abc123\n    uts: host\n    hostname: web\n  db:\n    image: postgres\n"); err != nil {
+		t.Errorf("expected namespace modes not to be checked without the balena target, got %v", err)
+	}
+	for _, options := range []Options{{}, {Target: BalenaTarget}} {
+		_, err := parse(t, options, "services:\n  web:\n    image: nginx\n    pid: service:db\n")
+		parserErr := expectError(t, err, ValidationError, `services.web.pid references service "db", which doesn't exist`)
+		if len(parserErr.Errors) != 1 || parserErr.Errors[0].Code != InvalidNamespaceModeCode || parserErr.Location == nil || parserErr.Location.Line != 4 {
+			t.Errorf("expected an invalid namespace mode located at the pid field, got %+v", parserErr.Errors)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package parser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// Media types and annotations of compose projects published as OCI artifacts with `docker compose publish`
+const (
+	ociComposeProjectType    = "application/vnd.docker.compose.project"
+	ociComposeFileType       = "application/vnd.docker.compose.file+yaml"
+	ociComposeEnvFileType    = "application/vnd.docker.compose.envfile"
+	ociComposeFileAnnotation = "com.docker.compose.file"
+	ociComposeEnvAnnotation  = "com.docker.compose.envfile"
+	ociImageManifestType     = "application/vnd.oci.image.manifest.v1+json"
+)
+
+// Max size of an artifact manifest or registry token response
+const maxOCIManifestSize = 4 * 1024 * 1024
+
+// Docker Hub's registry API is served from a different host than its reference domain
+const (
+	dockerHubDomain       = "docker.io"
+	dockerHubRegistryHost = "registry-1.docker.io"
+)
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	MediaType    string          `json:"mediaType"`
+	ArtifactType string          `json:"artifactType,omitempty"`
+	Config       ociDescriptor   `json:"config"`
+	Layers       []ociDescriptor `json:"layers"`
+}
+
+// ParseOCI pulls a compose project published as an OCI artifact, e.g. with `docker compose publish`,
+// and parses its compose files in the order they were published. Registries are accessed with
+// Options.HTTPSClient, or http.DefaultClient if unset, using anonymous token authentication.
+func (p *Parser) ParseOCI(ctx context.Context, ref string) (*Result, error) {
+	dir, err := os.MkdirTemp("", "balena-compose-parser-oci-")
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to create project directory: %v", err), Err: err}
+	}
+	defer os.RemoveAll(dir)
+
+	client := p.options.HTTPSClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	paths, err := pullOCIProject(ctx, client, ref, dir)
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to pull compose artifact %s: %v", ref, err), Err: err}
+	}
+	if len(paths) == 0 {
+		return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Compose artifact %s doesn't contain any compose files", ref)}
+	}
+	return p.Parse(ctx, paths)
+}
+
+// Pull the compose and env files of the artifact into dir, returning the compose file paths in order
+func pullOCIProject(ctx context.Context, client *http.Client, ref, dir string) ([]string, error) {
+	named, err := reference.ParseNormalizedNamed(strings.TrimPrefix(ref, "oci://"))
+	if err != nil {
+		return nil, err
+	}
+	named = reference.TagNameOnly(named)
+	registry := &ociRegistry{client: client, host: reference.Domain(named), repository: reference.Path(named)}
+	if registry.host == dockerHubDomain {
+		registry.host = dockerHubRegistryHost
+	}
+
+	var manifestRef string
+	if digested, ok := named.(reference.Digested); ok {
+		manifestRef = digested.Digest().String()
+	} else {
+		manifestRef = named.(reference.Tagged).Tag()
+	}
+
+	manifestJSON, err := registry.get(ctx, "manifests/"+manifestRef, ociImageManifestType, maxOCIManifestSize)
+	if err != nil {
+		return nil, err
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if manifest.ArtifactType != ociComposeProjectType && manifest.Config.MediaType != ociComposeProjectType {
+		return nil, fmt.Errorf("not a compose project artifact, got artifact type %q", manifest.ArtifactType)
+	}
+
+	var composeFiles []string
+	for i, layer := range manifest.Layers {
+		var name string
+		switch layer.MediaType {
+		case ociComposeFileType:
+			name = layer.Annotations[ociComposeFileAnnotation]
+			if name == "" {
+				name = fmt.Sprintf("compose-%d.yml", i)
+			}
+		case ociComposeEnvFileType:
+			name = layer.Annotations[ociComposeEnvAnnotation]
+			if name == "" {
+				name = ".env"
+			}
+		default:
+			continue
+		}
+
+		content, err := registry.get(ctx, "blobs/"+layer.Digest, "", maxRemoteFileSize)
+		if err != nil {
+			return nil, err
+		}
+		if err := verifyDigest(layer.Digest, content); err != nil {
+			return nil, err
+		}
+
+		// Files keep their published relative paths, so that env_file references resolve
+		local, err := containedPath(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(local), 0o700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(local, content, 0o600); err != nil {
+			return nil, err
+		}
+		if layer.MediaType == ociComposeFileType {
+			composeFiles = append(composeFiles, local)
+		}
+	}
+	return composeFiles, nil
+}
+
+// Check content against a sha256 digest, the only algorithm compose publishes with
+func verifyDigest(digest string, content []byte) error {
+	algorithm, expected, _ := strings.Cut(digest, ":")
+	if algorithm != "sha256" {
+		return fmt.Errorf("unsupported digest algorithm in %s", digest)
+	}
+	actual := sha256.Sum256(content)
+	if hex.EncodeToString(actual[:]) != expected {
+		return fmt.Errorf("digest mismatch for %s", digest)
+	}
+	return nil
+}
+
+// ociRegistry is a minimal OCI distribution API client for pulling public artifacts
+type ociRegistry struct {
+	client     *http.Client
+	host       string
+	repository string
+	token      string
+}
+
+// GET a registry API path under /v2/<repository>/, authenticating with a bearer token if challenged
+func (r *ociRegistry) get(ctx context.Context, apiPath, accept string, maxSize int64) ([]byte, error) {
+	response, err := r.do(ctx, apiPath, accept)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusUnauthorized && r.token == "" {
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+		if err := r.authenticate(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if response, err = r.do(ctx, apiPath, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", apiPath, response.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("%s exceeds %d bytes", apiPath, maxSize)
+	}
+	return content, nil
+}
+
+func (r *ociRegistry) do(ctx context.Context, apiPath, accept string) (*http.Response, error) {
+	u := url.URL{Scheme: r.scheme(), Host: r.host, Path: "/v2/" + r.repository + "/" + apiPath}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if r.token != "" {
+		request.Header.Set("Authorization", "Bearer "+r.token)
+	}
+	return r.client.Do(request)
+}
+
+// Plain HTTP is only used for loopback registries, matching the Docker engine's default insecure registries
+func (r *ociRegistry) scheme() string {
+	host, _, err := net.SplitHostPort(r.host)
+	if err != nil {
+		host = r.host
+	}
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return "http"
+	}
+	return "https"
+}
+
+// Fetch an anonymous bearer token as described by a WWW-Authenticate challenge
+func (r *ociRegistry) authenticate(ctx context.Context, challenge string) error {
+	scheme, rawParams, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "bearer") {
+		return fmt.Errorf("unsupported registry authentication challenge %q", challenge)
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(rawParams, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		params[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("invalid registry authentication realm %q", params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = "repository:" + r.repository + ":pull"
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	response, err := r.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to authenticate with %s: %s", r.host, response.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxOCIManifestSize)).Decode(&token); err != nil {
+		return fmt.Errorf("invalid token response from %s: %w", r.host, err)
+	}
+	r.token = token.Token
+	if r.token == "" {
+		r.token = token.AccessToken
+	}
+	if r.token == "" {
+		return fmt.Errorf("no token returned by %s", r.host)
+	}
+	return nil
+}
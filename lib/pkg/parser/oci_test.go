@@ -0,0 +1,190 @@
+package parser
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// A minimal OCI registry serving one repository, requiring a bearer token if token is set
+type testRegistry struct {
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	token     string
+}
+
+// Add a blob to the registry, returning its descriptor
+func (r *testRegistry) blob(mediaType, content string, annotations map[string]string) ociDescriptor {
+	sum := sha256.Sum256([]byte(content))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.blobs[digest] = []byte(content)
+	return ociDescriptor{MediaType: mediaType, Digest: digest, Size: int64(len(content)), Annotations: annotations}
+}
+
+// Add a manifest to the registry under tag and its digest, returning the digest
+func (r *testRegistry) manifest(t *testing.T, tag string, manifest ociManifest) string {
+	t.Helper()
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	r.manifests[tag] = content
+	r.manifests[digest] = content
+	return digest
+}
+
+func (r *testRegistry) serve(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			if req.URL.Query().Get("scope") != "repository:app/compose:pull" {
+				http.Error(w, "invalid scope", http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": r.token})
+			return
+		}
+		if r.token != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		path, ok := strings.CutPrefix(req.URL.Path, "/v2/app/compose/")
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		var content []byte
+		if ref, ok := strings.CutPrefix(path, "manifests/"); ok {
+			content, ok = r.manifests[ref]
+			if !ok || req.Header.Get("Accept") != ociImageManifestType {
+				http.NotFound(w, req)
+				return
+			}
+		} else if digest, ok := strings.CutPrefix(path, "blobs/"); ok {
+			if content, ok = r.blobs[digest]; !ok {
+				http.NotFound(w, req)
+				return
+			}
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{manifests: map[string][]byte{}, blobs: map[string][]byte{}}
+}
+
+// A compose project manifest of a base and an override compose file, with the env file of the base
+func (r *testRegistry) project(t *testing.T, tag string) string {
+	t.Helper()
+	config := r.blob(ociComposeProjectType, "{}", nil)
+	return r.manifest(t, tag, ociManifest{
+		MediaType:    ociImageManifestType,
+		ArtifactType: ociComposeProjectType,
+		Config:       config,
+		Layers: []ociDescriptor{
+			r.blob(ociComposeFileType, "services:\n  web:\n    image: nginx\n    command: [base]\n    env_file: .env\n", map[string]string{ociComposeFileAnnotation: "compose.yml"}),
+			r.blob(ociComposeEnvFileType, "TAG=1.25\n", map[string]string{ociComposeEnvAnnotation: ".env"}),
+			r.blob(ociComposeFileType, "services:\n  web:\n    command: [override]\n", nil),
+		},
+	})
+}
+
+func TestParseOCI(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		ref   func(host, digest string) string
+	}{
+		{name: "tag", ref: func(host, _ string) string { return host + "/app/compose:v1" }},
+		{name: "scheme", ref: func(host, _ string) string { return "oci://" + host + "/app/compose:v1" }},
+		{name: "digest", ref: func(host, digest string) string { return host + "/app/compose@" + digest }},
+		{name: "token authentication", token: "secret", ref: func(host, _ string) string { return host + "/app/compose:v1" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newTestRegistry()
+			registry.token = tt.token
+			digest := registry.project(t, "v1")
+			server := registry.serve(t)
+
+			result, err := New(Options{ProjectName: "test"}).ParseOCI(context.Background(), tt.ref(strings.TrimPrefix(server.URL, "http://"), digest))
+			if err != nil {
+				t.Fatal(err)
+			}
+			web := result.Project.Services["web"]
+			if web.Environment["TAG"] == nil || *web.Environment["TAG"] != "1.25" || strings.Join(web.Command, " ") != "override" {
+				t.Errorf("expected the compose files to be merged in order with the env file, got %+v", web)
+			}
+		})
+	}
+}
+
+func TestParseOCIErrors(t *testing.T) {
+	registry := newTestRegistry()
+	registry.project(t, "v1")
+	config := registry.blob(ociComposeProjectType, "{}", nil)
+	registry.manifest(t, "image", ociManifest{
+		MediaType: ociImageManifestType,
+		Config:    registry.blob("application/vnd.oci.image.config.v1+json", "{}", nil),
+		Layers:    []ociDescriptor{registry.blob("application/vnd.oci.image.layer.v1.tar+gzip", "layer", nil)},
+	})
+	registry.manifest(t, "empty", ociManifest{MediaType: ociImageManifestType, ArtifactType: ociComposeProjectType, Config: config})
+	tampered := registry.blob(ociComposeFileType, "services:\n  web:\n    image: nginx\n", nil)
+	registry.blobs[tampered.Digest] = []byte("services:\n  web:\n    image: evil\n")
+	registry.manifest(t, "tampered", ociManifest{MediaType: ociImageManifestType, ArtifactType: ociComposeProjectType, Config: config, Layers: []ociDescriptor{tampered}})
+	registry.manifest(t, "escaping", ociManifest{
+		MediaType:    ociImageManifestType,
+		ArtifactType: ociComposeProjectType,
+		Config:       config,
+		Layers:       []ociDescriptor{registry.blob(ociComposeFileType, "services: {}\n", map[string]string{ociComposeFileAnnotation: "../compose.yml"})},
+	})
+	registry.manifests["invalid"] = []byte("not a manifest")
+	server := registry.serve(t)
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name    string
+		ref     string
+		errName string
+		message string
+	}{
+		{name: "invalid reference", ref: "App/Compose", errName: IOError, message: "Failed to pull compose artifact App/Compose"},
+		{name: "missing tag", ref: host + "/app/compose:missing", errName: IOError, message: "404 Not Found"},
+		{name: "invalid manifest", ref: host + "/app/compose:invalid", errName: IOError, message: "invalid manifest"},
+		{name: "not a compose project", ref: host + "/app/compose:image", errName: IOError, message: "not a compose project artifact"},
+		{name: "no compose files", ref: host + "/app/compose:empty", errName: ArgumentError, message: "doesn't contain any compose files"},
+		{name: "digest mismatch", ref: host + "/app/compose:tampered", errName: IOError, message: "digest mismatch"},
+		{name: "escaping file", ref: host + "/app/compose:escaping", errName: IOError, message: "outside of the project"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Options{ProjectName: "test"}).ParseOCI(context.Background(), tt.ref)
+			expectError(t, err, tt.errName, tt.message)
+		})
+	}
+}
+
+func TestVerifyDigest(t *testing.T) {
+	sum := sha256.Sum256([]byte("content"))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if err := verifyDigest(digest, []byte("content")); err != nil {
+		t.Errorf("expected %s to match, got %v", digest, err)
+	}
+	for _, digest := range []string{digest + "0", "sha512:" + hex.EncodeToString(sum[:]), "content"} {
+		if err := verifyDigest(digest, []byte("content")); err == nil {
+			t.Errorf("expected %s not to match", digest)
+		}
+	}
+}
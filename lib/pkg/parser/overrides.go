@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+)
+
+// Override is a field a compose file set which a later compose file, or document of the same file, set to
+// another value, as written in the files
+type Override struct {
+	// Path is the dot separated path of the field, e.g. services.web.image
+	Path string `json:"path"`
+	// Previous is the value the earlier file set
+	Previous any `json:"previous"`
+	// Value is the value of the later file, which overrode the previous value
+	Value any `json:"value"`
+	// PreviousLocation is where the earlier file set the previous value
+	PreviousLocation *Location `json:"previousLocation"`
+	// Location is where the later file set the value
+	Location *Location `json:"location"`
+}
+
+// Sequences compose-go merges by key, as mappings, with entries of the form KEY=value, or KEY: value for
+// extra_hosts
+var keyedSequences = []tree.Path{
+	"services.*.annotations",
+	"services.*.build.args",
+	"services.*.build.labels",
+	"services.*.deploy.labels",
+	"services.*.environment",
+	"services.*.extra_hosts",
+	"services.*.labels",
+	"services.*.sysctls",
+	"networks.*.labels",
+	"volumes.*.labels",
+}
+
+// Sequences and mappings compose-go replaces as a whole, rather than merging them
+var replacedFields = []tree.Path{
+	"services.*.command",
+	"services.*.entrypoint",
+	"services.*.healthcheck.test",
+}
+
+// assignment is the value a compose file set at a path
+type assignment struct {
+	value any
+	file  string
+}
+
+// Find the fields of local compose files which later files override, in the order of the later files
+// then of their fields. Sequences compose-go appends, e.g. ports, aren't overridden, while those merged by
+// key, e.g. environment, are overridden entry by entry, and command, entrypoint and healthcheck.test as a
+// whole. Logging options are compared regardless of the driver.
+func findOverrides(composeFiles []string) []Override {
+	var overrides []Override
+	assigned := map[string]assignment{}
+	for _, composeFile := range composeFiles {
+		if composeFile == StdinPath || strings.HasPrefix(composeFile, "https://") {
+			continue
+		}
+		if abs, err := filepath.Abs(composeFile); err == nil {
+			composeFile = abs
+		}
+		content, err := os.ReadFile(composeFile)
+		if err != nil {
+			continue
+		}
+		// The project loaded, so malformed documents can't occur
+		documents, _ := decodeDocuments(content)
+		for _, document := range documents {
+			for _, field := range assignedFields(document, tree.NewPath()) {
+				path := field.path.String()
+				if previous, ok := assigned[path]; ok && !reflect.DeepEqual(previous.value, field.value) {
+					overrides = append(overrides, Override{
+						Path:             path,
+						Previous:         previous.value,
+						Value:            field.value,
+						PreviousLocation: locatePath(path, []string{previous.file}),
+						Location:         locatePath(path, []string{composeFile}),
+					})
+				}
+				assigned[path] = assignment{field.value, composeFile}
+			}
+		}
+	}
+	return overrides
+}
+
+// assignedField is a value a document sets at a path
+type assignedField struct {
+	path  tree.Path
+	value any
+}
+
+// The fields a document sets which later documents may override, in key order: scalars, the entries of
+// keyed sequences and replaced fields. Sequences which are appended aren't fields.
+func assignedFields(value any, path tree.Path) []assignedField {
+	switch {
+	case slices.ContainsFunc(replacedFields, path.Matches):
+		return []assignedField{{path, value}}
+	case slices.ContainsFunc(keyedSequences, path.Matches):
+		value = keyedEntries(value, path.Last() == "extra_hosts")
+	case path.Matches("services.*.build"):
+		if context, ok := value.(string); ok {
+			value = map[string]any{"context": context}
+		}
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		var fields []assignedField
+		for _, key := range sortedKeys(value) {
+			fields = append(fields, assignedFields(value[key], path.Next(key))...)
+		}
+		return fields
+	case []any:
+		return nil
+	}
+	return []assignedField{{path, value}}
+}
+
+// The entries of a keyed sequence as a mapping of strings, whether written as a mapping or as a list of
+// KEY=value entries, or KEY:value entries for extra hosts. Entries without a value are nil.
+func keyedEntries(value any, hosts bool) map[string]any {
+	entries := map[string]any{}
+	list, ok := value.([]any)
+	if !ok {
+		for key, entryValue := range object(value) {
+			if entries[key] = nil; entryValue != nil {
+				entries[key] = fmt.Sprint(entryValue)
+			}
+		}
+		return entries
+	}
+	for _, entry := range list {
+		key, entryValue, found := strings.Cut(fmt.Sprint(entry), "=")
+		if hosts && !found {
+			key, entryValue, found = strings.Cut(key, ":")
+		}
+		if found {
+			entries[key] = entryValue
+		} else {
+			entries[key] = nil
+		}
+	}
+	return entries
+}
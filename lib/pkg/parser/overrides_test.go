@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOverrides(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx:1.24\n    build: ./web\n    command: [serve, --port, \"80\"]\n    ports: [\"80:80\"]\n" +
+			"    environment:\n      - A=1\n      - B=2\n      - C\n",
+		"compose.override.yml": "services:\n  web:\n    image: nginx:1.25\n    build:\n      context: ./web\n      dockerfile: Dockerfile.dev\n    command: [serve]\n    ports: [\"8080:80\"]\n" +
+			"    environment:\n      A: \"1\"\n      B: \"3\"\n      C: set\n---\nservices:\n  web:\n    image: nginx:1.26\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	override := filepath.Join(dir, "compose.override.yml")
+	result, err := New(Options{ProjectName: "test", Overrides: true}).Parse(context.Background(), []string{composeFile, override})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ports are appended, equal values aren't overridden, keyed sequences are overridden entry by entry and
+	// command as a whole, in the order of the later files then of their fields
+	expected := []struct {
+		path         string
+		previous     any
+		value        any
+		previousLine int
+		line         int
+		previousFile string
+		file         string
+	}{
+		{path: "services.web.command", previous: []any{"serve", "--port", "80"}, value: []any{"serve"}, previousLine: 5, line: 7, previousFile: composeFile, file: override},
+		{path: "services.web.environment.B", previous: "2", value: "3", previousLine: 9, line: 11, previousFile: composeFile, file: override},
+		{path: "services.web.environment.C", previous: nil, value: "set", previousLine: 10, line: 12, previousFile: composeFile, file: override},
+		{path: "services.web.image", previous: "nginx:1.24", value: "nginx:1.25", previousLine: 3, line: 3, previousFile: composeFile, file: override},
+		{path: "services.web.image", previous: "nginx:1.25", value: "nginx:1.26", previousLine: 3, line: 3, previousFile: override, file: override},
+	}
+	if len(result.Overrides) != len(expected) {
+		t.Fatalf("expected %d overrides, got %+v", len(expected), result.Overrides)
+	}
+	for i, o := range result.Overrides {
+		e := expected[i]
+		if o.Path != e.path || !reflect.DeepEqual(o.Previous, e.previous) || !reflect.DeepEqual(o.Value, e.value) {
+			t.Errorf("expected %s overridden from %v to %v, got %s from %v to %v", e.path, e.previous, e.value, o.Path, o.Previous, o.Value)
+		}
+		if o.PreviousLocation == nil || o.PreviousLocation.File != e.previousFile || o.PreviousLocation.Line != e.previousLine || o.Location == nil || o.Location.File != e.file || o.Location.Line != e.line {
+			t.Errorf("expected override %d to be located at lines %d and %d, got %+v and %+v", i, e.previousLine, e.line, o.PreviousLocation, o.Location)
+		}
+	}
+
+	if result := mustParse(t, Options{}, "services:\n  web:\n    image: nginx:1.24\n", "services:\n  web:\n    image: nginx:1.25\n"); result.Overrides != nil {
+		t.Errorf("expected no overrides without Options.Overrides, got %+v", result.Overrides)
+	}
+}
+
+func TestKeyedEntries(t *testing.T) {
+	for _, tt := range []struct {
+		value    any
+		hosts    bool
+		expected map[string]any
+	}{
+		{value: []any{"A=1", "B=x=y", "C"}, expected: map[string]any{"A": "1", "B": "x=y", "C": nil}},
+		{value: map[string]any{"A": 1, "B": true, "C": nil}, expected: map[string]any{"A": "1", "B": "true", "C": nil}},
+		{value: []any{"host:10.0.0.1", "other=10.0.0.2"}, hosts: true, expected: map[string]any{"host": "10.0.0.1", "other": "10.0.0.2"}},
+		{value: []any{"host:10.0.0.1"}, expected: map[string]any{"host:10.0.0.1": nil}},
+	} {
+		if entries := keyedEntries(tt.value, tt.hosts); !reflect.DeepEqual(entries, tt.expected) {
+			t.Errorf("expected %v, got %v", tt.expected, entries)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ParseParsed re-parses projects previously output by the parser, e.g. to normalize and validate
+// stored projects again once the parser is updated. Projects may be wrapped as {"project": {...}},
+// as output with warnings or in batch mode. Variables were already interpolated, so the projects
+// aren't interpolated again, and all services are enabled, as those disabled were already dropped.
+// The project name defaults to that of the first project, and the project directory to the
+// directory of the first file.
+func (p *Parser) ParseParsed(ctx context.Context, composeFiles []string) (*Result, error) {
+	if len(composeFiles) == 0 {
+		return nil, &Error{Name: ArgumentError, Message: "At least one compose file must be specified"}
+	}
+
+	var files []File
+	var name string
+	for _, composeFile := range composeFiles {
+		var content []byte
+		var err error
+		if composeFile == StdinPath {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			content, err = os.ReadFile(composeFile)
+		}
+		if err != nil {
+			return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to read parsed project: %v", err), Err: err}
+		}
+		var document struct {
+			Name    string          `json:"name"`
+			Project json.RawMessage `json:"project"`
+		}
+		if err := json.Unmarshal(content, &document); err != nil {
+			return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse parsed project %s: %v", composeFile, err), Err: err}
+		}
+		if document.Project != nil {
+			content = document.Project
+			if err := json.Unmarshal(content, &document); err != nil {
+				return nil, &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse parsed project %s: %v", composeFile, err), Err: err}
+			}
+		}
+		name = cmp.Or(name, document.Name)
+		files = append(files, File{Name: filepath.Base(composeFile), Content: content})
+	}
+
+	options := p.options
+	options.ProjectName = cmp.Or(options.ProjectName, name)
+	if options.ProjectDirectory == "" && composeFiles[0] != StdinPath {
+		options.ProjectDirectory = filepath.Dir(composeFiles[0])
+	}
+	options.NoInterpolate = true
+	options.Profiles = []string{"*"}
+	// Paths were resolved when the projects were parsed, so may be absolute
+	return (&Parser{options: options}).parseContent(ctx, files, false)
+}
@@ -0,0 +1,656 @@
+// Package parser loads and validates docker-compose files using compose-go,
+// so that balena services can parse compositions in-process.
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// DefaultTimeout is the maximum time spent parsing if Options.Timeout is unset
+const DefaultTimeout = 10 * time.Second
+
+// StdinPath is the compose file path which instructs compose-go to read from stdin
+const StdinPath = "-"
+
+// Options configures a Parser
+type Options struct {
+	// ProjectName is the name of the project to use for the parsed output.
+	// compose-go injects it into several fields, e.g. network and volume names.
+	// It's optional with BalenaNormalize, which removes it from the output.
+	ProjectName string
+
+	// BalenaNormalize removes the project name from the parsed project, along with the network and
+	// volume names compose-go derives from it, producing the composition balena expects
+	BalenaNormalize bool
+
+	// MaxServices and MaxVolumes limit the number of services and volumes of the project, failing with
+	// a ValidationError if exceeded. Zero is unlimited.
+	MaxServices int
+	MaxVolumes  int
+
+	// PrivateLabels are io.balena.private labels added to every service, which only trusted callers such
+	// as the builder may set. The namespace is reserved, so labels in it set in compose files are always
+	// a ValidationError.
+	PrivateLabels map[string]string
+
+	// Policy governs the fields giving services privileged or host access, e.g. StrictPolicy, failing
+	// with a ValidationError listing the fields it rejects and adding warnings for those it warns
+	// about. The decision on each field set is recorded into Result.Policy.
+	Policy string
+
+	// Builds records the build of each service into Result.Builds, as the balena builder takes it,
+	// with the variables of Dockerfile templates, rendered for DeviceType if set
+	Builds bool
+
+	// Defaults is the path of a YAML file of organization-wide defaults, i.e. the restart policy, logging,
+	// labels and tmpfs size, applied to every service beneath the compose files. The fields set in each
+	// service are recorded into Result.Defaults.
+	Defaults string
+
+	// ExtraSchema is the path of a JSON Schema of additional constraints on the project as output, after
+	// normalization, e.g. requiring labels or forbidding ports, failing with a ValidationError listing
+	// each violation
+	ExtraSchema string
+
+	// ExtensionSchemas are the paths of JSON Schemas of extensions by key, e.g. x-balena, which every
+	// extension of the key in the project as output must conform to, at any level, failing with a
+	// ValidationError listing each violation
+	ExtensionSchemas map[string]string
+
+	// BalenaDefaults adds the settings the supervisor gives services which don't set them, e.g.
+	// restart: always, so the output matches what runs on the device
+	BalenaDefaults bool
+
+	// Timeout is the maximum time spent parsing, DefaultTimeout if zero
+	Timeout time.Duration
+
+	// HTTPSClient fetches compose files referenced by https:// URL, whether passed directly
+	// or via include and extends. Remote compose files are not supported if nil.
+	HTTPSClient *http.Client
+
+	// AllErrors collects every error found in a failed parse into Error.Errors,
+	// rather than only the first error compose-go reports
+	AllErrors bool
+
+	// Warnings collects non-fatal issues, e.g. unset variables, into Result.Warnings
+	Warnings bool
+
+	// Environment are variables to interpolate, overriding those of the process environment and env files
+	Environment map[string]string
+
+	// BalenaVariables are the fleet and device variables of a device to interpolate, overridden by
+	// Environment and overriding the process environment and env files
+	BalenaVariables *BalenaVariables
+
+	// NoOSEnv stops variables being interpolated from the environment of the process,
+	// so that the parsed project doesn't depend on the host it's parsed on
+	NoOSEnv bool
+
+	// ProjectDirectory is the directory relative paths in the compose files, e.g. of bind mounts and
+	// build contexts, are resolved against. Defaults to the directory of the first compose file.
+	ProjectDirectory string
+
+	// RelativePaths preserves relative paths in the compose files, e.g. of build contexts, bind mounts
+	// and env files, rather than resolving them to absolute paths against the project directory.
+	// Label files are still read relative to the current directory, as compose-go reads them.
+	RelativePaths bool
+
+	// DockerCompatible makes the parsed project match that of "docker compose config", discarding env_file
+	// entries once resolved into the environment and dropping unused networks, volumes, secrets and configs
+	DockerCompatible bool
+
+	// Profiles are the profiles to enable, with "*" enabling all. Services with profiles are only
+	// included if one of them is enabled. Defaults to COMPOSE_PROFILES, as resolved for interpolation.
+	Profiles []string
+
+	// EnvAllow are glob patterns of the variables in the process environment which are interpolated,
+	// or all variables if empty. Patterns are matched ignoring case.
+	EnvAllow []string
+
+	// EnvDeny are glob patterns of variables in the process environment which aren't interpolated,
+	// even if allowed by EnvAllow. Patterns are matched ignoring case.
+	EnvDeny []string
+
+	// EnvFiles are the env files to interpolate variables from, e.g. the .env file in the project
+	// directory, which isn't read unless listed. Later files override earlier ones, and all must exist.
+	EnvFiles []string
+
+	// NoDotEnv states variables mustn't be interpolated from the .env file in the project directory unless
+	// listed in EnvFiles, e.g. for servers parsing untrusted projects. As it's only read if listed, this is
+	// also the default.
+	NoDotEnv bool
+
+	// NoInterpolate preserves variable references such as ${VAR} verbatim in the parsed project,
+	// e.g. for the supervisor to substitute per device
+	NoInterpolate bool
+
+	// NoNormalize skips compose-go's normalization, e.g. adding the default network and implied
+	// dependencies, so the parsed project stays structurally close to the compose files
+	NoNormalize bool
+
+	// SkipConsistency skips compose-go's consistency checks, e.g. of references to undefined
+	// networks, volumes and secrets, so partial or overlay files that don't stand alone can be parsed
+	SkipConsistency bool
+
+	// StrictEnv fails a parse if any interpolated variable is unset and has no default,
+	// rather than substituting a blank string
+	StrictEnv bool
+
+	// MaskEnv are glob patterns of variable names, e.g. DefaultMaskPatterns, whose values are
+	// replaced with MaskedValue in the environment and build args of services, wherever they're
+	// interpolated in the result, e.g. in commands and labels, in error messages, and in logs while
+	// the parse runs. Patterns are matched ignoring case.
+	MaskEnv []string
+
+	// EnvResolution records the source and value of each substituted variable into Result.EnvResolution
+	EnvResolution bool
+
+	// Overrides records the fields of local compose files which later files override into Result.Overrides
+	Overrides bool
+
+	// GPU records the GPU requests of each service, set with gpus or deploy.resources.reservations.devices,
+	// into Result.GPU
+	GPU bool
+
+	// Target validates the parsed project against a platform, e.g. BalenaTarget, failing with a
+	// ValidationError listing every unsupported field, and adding warnings for fields it ignores
+	Target string
+
+	// SupervisorVersion is the version of the supervisor the project is validated for with BalenaTarget,
+	// e.g. v16.4.0, allowing the depends_on conditions it supports and failing with a ValidationError for
+	// those it's too old for. Only service_started is allowed if unset.
+	SupervisorVersion string
+
+	// OSVersion is the version of balenaOS the project is validated for with BalenaTarget, e.g. 2.113.18,
+	// failing with a ValidationError for capabilities which require privileged on older versions. They're
+	// reported with warnings if unset.
+	OSVersion string
+
+	// ExpandFeatures records the mounts, devices and variables implied by the io.balena.features labels of
+	// each service into Result.Features, failing with a ValidationError for labels with invalid values
+	ExpandFeatures bool
+
+	// Contract is the path of a balena.yml contract to merge into Result.Contract, failing with a
+	// ValidationError if it's invalid or its requirements conflict with the project, e.g. by requiring
+	// an architecture services set another platform for
+	Contract string
+
+	// DeviceType and Arch check every service can run on devices of a balena device type, e.g.
+	// raspberrypi4-64, or an architecture, e.g. aarch64, failing with a ValidationError listing
+	// platforms, images and options for other architectures. Both may be set if they agree.
+	DeviceType string
+	Arch       string
+
+	// Services restricts the parsed project to the named services and the services they
+	// transitively depend on, enabling those with profiles. All services are included if empty.
+	Services []string
+
+	// Progress is called as a parse moves between phases, so long parses can report progress.
+	// It may be called from compose-go's loading goroutine, so must be safe for concurrent use.
+	Progress func(ProgressEvent)
+}
+
+// Parser parses compose files into a normalized project
+type Parser struct {
+	options Options
+}
+
+// Result is the outcome of a successful parse
+type Result struct {
+	// Project is the normalized compose project
+	Project *types.Project
+
+	// Git is the source of the project, if parsed with ParseGit
+	Git *GitSource
+
+	// Warnings are the non-fatal issues found, if Options.Warnings is set
+	Warnings []Warning
+
+	// Features are the io.balena.features enabled in each service, if Options.ExpandFeatures is set
+	Features map[string][]Feature
+
+	// Contract is the balena.yml contract, if Options.Contract is set
+	Contract *Contract
+
+	// Builds are the builds of the services with a build section, if Options.Builds is set
+	Builds map[string]Build
+
+	// Policy are the decisions of Options.Policy on the host access fields of each service, if set
+	Policy map[string][]PolicyDecision
+
+	// EnvResolution is the source and value of each substituted variable, if Options.EnvResolution is set
+	EnvResolution []VariableResolution
+
+	// Defaults are the fields of each service set from Options.Defaults, if set
+	Defaults map[string][]DefaultedField
+
+	// Overrides are the fields of compose files which later files override, if Options.Overrides is set
+	Overrides []Override
+
+	// GPU are the normalized GPU requests of each service requesting GPUs, if Options.GPU is set
+	GPU map[string][]GPURequest
+}
+
+// New creates a Parser with the given options
+func New(options Options) *Parser {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+	return &Parser{options: options}
+}
+
+// Parse loads one or more compose files, with later files overriding earlier ones
+func (p *Parser) Parse(ctx context.Context, composeFiles []string) (*Result, error) {
+	if len(composeFiles) == 0 {
+		return nil, &Error{Name: ArgumentError, Message: "At least one compose file must be specified"}
+	}
+	arch, err := p.checkOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.options.Timeout)
+	defer cancel()
+
+	report := p.progressReporter()
+	report(ProgressEvent{Phase: LoadingPhase, Files: composeFiles})
+	defer report(ProgressEvent{Phase: DonePhase})
+
+	masks := newMasker(p.options.MaskEnv)
+	defer masks.close()
+	state, err := p.load(ctx, composeFiles, masks, report)
+	if err != nil {
+		return nil, masks.maskError(err)
+	}
+	state.arch = arch
+	for _, stage := range p.stages() {
+		if err := stage(state); err != nil {
+			return nil, masks.maskError(err)
+		}
+	}
+	return state.result, nil
+}
+
+// Check the options are valid, returning the architecture of Options.DeviceType and Options.Arch
+func (p *Parser) checkOptions() (string, error) {
+	if p.options.ProjectName == "" && !p.options.BalenaNormalize {
+		return "", &Error{Name: ArgumentError, Message: "Project name is required"}
+	}
+	if p.options.Timeout < 0 {
+		return "", &Error{Name: ArgumentError, Message: fmt.Sprintf("Timeout must be positive, got %s", p.options.Timeout)}
+	}
+	if p.options.Target != "" && !slices.Contains(Targets, p.options.Target) {
+		return "", &Error{Name: ArgumentError, Message: fmt.Sprintf("Unsupported target %q, expected one of: %s", p.options.Target, strings.Join(Targets, ", "))}
+	}
+	if err := checkTargetVersion("supervisor", p.options.SupervisorVersion, p.options.Target); err != nil {
+		return "", err
+	}
+	if err := checkTargetVersion("balenaOS", p.options.OSVersion, p.options.Target); err != nil {
+		return "", err
+	}
+	if p.options.MaxServices < 0 || p.options.MaxVolumes < 0 {
+		return "", &Error{Name: ArgumentError, Message: fmt.Sprintf("Service and volume limits can't be negative, got %d and %d", p.options.MaxServices, p.options.MaxVolumes)}
+	}
+	for label := range p.options.PrivateLabels {
+		if !isPrivateLabel(label) {
+			return "", &Error{Name: ArgumentError, Message: fmt.Sprintf("Private label %s must be in the %q namespace", label, balenaPrivateLabelPrefix)}
+		}
+	}
+	if p.options.Policy != "" && !slices.Contains(Policies, p.options.Policy) {
+		return "", &Error{Name: ArgumentError, Message: fmt.Sprintf("Unsupported policy %q, expected one of: %s", p.options.Policy, strings.Join(Policies, ", "))}
+	}
+	arch, err := deviceArch(p.options.DeviceType, p.options.Arch)
+	if err != nil {
+		return "", err
+	}
+	return arch, nil
+}
+
+// Load the project of the compose files with compose-go, returning the state of the parse to pass along
+// its stages
+func (p *Parser) load(ctx context.Context, composeFiles []string, masks *masker, report func(ProgressEvent)) (*parseState, error) {
+	projectName := p.options.ProjectName
+	if projectName == "" {
+		projectName = placeholderProjectName()
+	}
+	if err := checkJSONComposeFiles(composeFiles); err != nil {
+		return nil, err
+	}
+	// Legacy 2.x files are loaded converted from elsewhere, so the project directory is still theirs
+	loadFiles, legacyDir, restorePaths, legacyWarnings, legacyErr := convertLegacyFiles(composeFiles)
+	if legacyDir != "" {
+		defer os.RemoveAll(legacyDir)
+	}
+	if legacyErr != nil {
+		return nil, legacyErr
+	}
+	if legacyDir != "" {
+		// compose-go logs the paths of the files it loads, e.g. that their version is obsolete
+		logPaths().register(restorePaths)
+		defer logPaths().unregister(restorePaths)
+	}
+	projectDirectory := p.options.ProjectDirectory
+	if projectDirectory == "" && loadFiles[0] != composeFiles[0] {
+		projectDirectory = filepath.Dir(restorePaths.Replace(loadFiles[0]))
+	}
+	projectOptions, err := p.environmentOptions(projectDirectory)
+	if err != nil {
+		return nil, err
+	}
+	projectOptions = append(projectOptions,
+		cli.WithName(projectName),
+		cli.WithInterpolation(!p.options.NoInterpolate),
+		cli.WithNormalization(!p.options.NoNormalize),
+		cli.WithConsistency(!p.options.SkipConsistency),
+		cli.WithResolvedPaths(!p.options.RelativePaths),
+		// Applied once the environment is resolved, to fall back to its COMPOSE_PROFILES
+		cli.WithDefaultProfiles(p.options.Profiles...),
+	)
+	if p.options.DockerCompatible {
+		projectOptions = append(projectOptions, cli.WithDiscardEnvFile)
+	}
+	if p.options.RelativePaths {
+		// compose-go would read relative env files from the current directory, so they're read once loaded
+		projectOptions = append(projectOptions, cli.WithLoadOptions(func(options *loader.Options) {
+			options.SkipResolveEnvironment = true
+		}))
+	}
+	projectOptions = append(projectOptions, p.progressOptions(report)...)
+	substitutions := func() []substitution { return nil }
+	if p.options.StrictEnv || p.options.EnvResolution {
+		var option cli.ProjectOptionsFn
+		option, substitutions = substitutionRecorder()
+		projectOptions = append(projectOptions, option)
+	}
+
+	if p.options.HTTPSClient != nil {
+		// Remote compose files are downloaded for the duration of the parse only
+		remoteDir, err := os.MkdirTemp("", "balena-compose-parser-remote-")
+		if err != nil {
+			return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to create remote file directory: %v", err), Err: err}
+		}
+		defer os.RemoveAll(remoteDir)
+		projectOptions = append(projectOptions, cli.WithResourceLoader(&httpsLoader{client: p.options.HTTPSClient, dir: remoteDir, progress: report}))
+	}
+
+	options, err := cli.NewProjectOptions(loadFiles, projectOptions...)
+	if err != nil {
+		return nil, &Error{
+			Name:    ConfigError,
+			Message: fmt.Sprintf("Failed to create compose project options: %v", err),
+			Err:     err,
+		}
+	}
+
+	// The values of masked variables are masked wherever they're interpolated
+	masks.addEnvironment(options.Environment)
+
+	// Channel to receive the result from the goroutine
+	type loadResult struct {
+		project *types.Project
+		err     error
+	}
+	resultChan := make(chan loadResult, 1)
+
+	// Run LoadProject in a goroutine
+	go func() {
+		project, err := options.LoadProject(ctx)
+		resultChan <- loadResult{project: project, err: err}
+	}()
+
+	// Wait for either the result or timeout
+	select {
+	case result := <-resultChan:
+		if result.err != nil {
+			return nil, p.loadError(ctx, result.err, options, composeFiles, loadFiles, restorePaths, report)
+		}
+		return &parseState{
+			project:        result.project,
+			composeFiles:   composeFiles,
+			options:        options,
+			substitutions:  substitutions(),
+			legacyWarnings: legacyWarnings,
+			masks:          masks,
+			result:         &Result{},
+		}, nil
+	case <-ctx.Done():
+		return nil, &Error{
+			Name:    TimeoutError,
+			Message: fmt.Sprintf("Compose file parsing timed out after %s", p.options.Timeout),
+			Err:     ctx.Err(),
+		}
+	}
+}
+
+// The error of a project compose-go failed to load, with the fields forming dependency cycles and the
+// references of namespace modes to undefined services found, and every error if Options.AllErrors is set
+func (p *Parser) loadError(ctx context.Context, loadErr error, options *cli.ProjectOptions, composeFiles, loadFiles []string, restorePaths *strings.Replacer, report func(ProgressEvent)) error {
+	if strings.Contains(loadErr.Error(), composeCycleError) {
+		// compose-go only names the services forming the cycle, not the fields they depend on each other with
+		if cycleErr := dependencyCycleError(ctx, options, composeFiles); cycleErr != nil {
+			return cycleErr
+		}
+	}
+	if strings.Contains(loadErr.Error(), composeUndefinedServiceError) {
+		// compose-go reports pid and ipc references to undefined services as dependencies of the service
+		if namespaceErr := namespaceModeError(ctx, options, composeFiles); namespaceErr != nil {
+			return namespaceErr
+		}
+	}
+	err := &Error{
+		Name:    loadErrorName(loadErr),
+		Message: restorePaths.Replace(fmt.Sprintf("Failed to parse compose file: %v", loadErr)),
+		Err:     loadErr,
+	}
+	err.Location = locate(err.Message, composeFiles)
+	if p.options.AllErrors {
+		report(ProgressEvent{Phase: ValidatingPhase, Files: composeFiles})
+		err.Errors = collectErrors(loadFiles, options.Environment, !p.options.NoInterpolate, loadErr)
+		for _, e := range err.Errors {
+			e.Message = restorePaths.Replace(e.Message)
+			e.Location = locate(e.Message, composeFiles)
+		}
+	}
+	return err
+}
+
+// compose-go options setting the project directory and resolving the variables to interpolate,
+// from Options.Environment, the process environment and env files in order of precedence. The project
+// directory is Options.ProjectDirectory, unless derived from compose files loaded from elsewhere.
+func (p *Parser) environmentOptions(projectDirectory string) ([]cli.ProjectOptionsFn, error) {
+	if projectDirectory != "" {
+		if info, err := os.Stat(projectDirectory); err != nil {
+			return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to read project directory: %v", err), Err: err}
+		} else if !info.IsDir() {
+			return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Project directory %s is not a directory", projectDirectory)}
+		}
+	}
+	for _, envFile := range p.options.EnvFiles {
+		if _, err := os.Stat(envFile); err != nil {
+			return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to read env file: %v", err), Err: err}
+		}
+	}
+
+	projectOptions := []cli.ProjectOptionsFn{
+		cli.WithWorkingDirectory(projectDirectory),
+		// Variables which are already set take precedence over the process environment and env files
+		func(options *cli.ProjectOptions) error {
+			maps.Copy(options.Environment, p.options.Environment)
+			balenaEnvironment, _ := p.options.BalenaVariables.environment()
+			for name, value := range balenaEnvironment {
+				if _, set := options.Environment[name]; !set {
+					options.Environment[name] = value
+				}
+			}
+			return nil
+		},
+	}
+	if !p.options.NoOSEnv {
+		projectOptions = append(projectOptions, p.withOsEnv)
+	}
+	if len(p.options.EnvFiles) > 0 {
+		// Without explicit env files, cli.WithEnvFiles would select the .env file in the project directory
+		projectOptions = append(projectOptions, cli.WithEnvFiles(p.options.EnvFiles...), cli.WithDotEnv)
+	}
+	return projectOptions, nil
+}
+
+// Like cli.WithOsEnv, only including the variables allowed by Options.EnvAllow and Options.EnvDeny
+func (p *Parser) withOsEnv(options *cli.ProjectOptions) error {
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		if _, set := options.Environment[name]; set || !p.osEnvAllowed(name) {
+			continue
+		}
+		options.Environment[name] = value
+	}
+	return nil
+}
+
+// Whether a variable is interpolated from the environment of the process
+func (p *Parser) osEnvAllowed(name string) bool {
+	if _, ok := os.LookupEnv(name); !ok || p.options.NoOSEnv {
+		return false
+	}
+	return (len(p.options.EnvAllow) == 0 || matchesAny(p.options.EnvAllow, name)) && !matchesAny(p.options.EnvDeny, name)
+}
+
+// Resolve the environment of each service from its env files, which are read relative to the
+// project directory but left relative in the project unless discarded
+func resolveServiceEnvironment(project *types.Project, discardEnvFiles bool) (*types.Project, error) {
+	envFiles := map[string][]types.EnvFile{}
+	for name, service := range project.Services {
+		envFiles[name] = service.EnvFiles
+		service.EnvFiles = slices.Clone(service.EnvFiles)
+		for i, envFile := range service.EnvFiles {
+			if !filepath.IsAbs(envFile.Path) {
+				service.EnvFiles[i].Path = filepath.Join(project.WorkingDir, envFile.Path)
+			}
+		}
+		project.Services[name] = service
+	}
+	resolved, err := project.WithServicesEnvironmentResolved(discardEnvFiles)
+	if err != nil || discardEnvFiles {
+		return resolved, err
+	}
+	for name, service := range resolved.Services {
+		service.EnvFiles = envFiles[name]
+		resolved.Services[name] = service
+	}
+	return resolved, nil
+}
+
+// Restrict a project to the named services and their transitive dependencies, enabling any
+// disabled by their profiles as docker compose does for services named on its command line
+func selectServices(project *types.Project, names []string) (*types.Project, error) {
+	for _, name := range names {
+		if _, ok := project.Services[name]; ok {
+			continue
+		}
+		if _, ok := project.DisabledServices[name]; !ok {
+			return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("No such service: %s", name)}
+		}
+	}
+	project, err := project.WithServicesEnabled(names...)
+	if err == nil {
+		project, err = project.WithSelectedServices(names, types.IncludeDependencies)
+	}
+	if err != nil {
+		return nil, &Error{Name: ValidationError, Message: fmt.Sprintf("Failed to select services: %v", err), Err: err}
+	}
+	return project, nil
+}
+
+// File is an in-memory compose file
+type File struct {
+	// Name is the file name, used for error messages and relative path resolution
+	Name string
+	// Content is the raw compose YAML
+	Content []byte
+}
+
+// ParseContent parses in-memory compose files, with later files overriding earlier ones.
+// compose-go only loads files from disk, so they're written to a temporary project directory
+// which is removed once parsing completes. The files can't read other files outside of it, failing
+// with a ValidationError for env_file, label_file, include, extends.file and build context paths which
+// are absolute, escape it or interpolate variables.
+func (p *Parser) ParseContent(ctx context.Context, files []File) (*Result, error) {
+	return p.parseContent(ctx, files, true)
+}
+
+// Parse in-memory compose files, only checking they can't read files outside of the temporary project
+// directory if checkPaths is set
+func (p *Parser) parseContent(ctx context.Context, files []File, checkPaths bool) (*Result, error) {
+	if len(files) == 0 {
+		return nil, &Error{Name: ArgumentError, Message: "At least one compose file must be specified"}
+	}
+
+	dir, err := os.MkdirTemp("", "balena-compose-parser-")
+	if err != nil {
+		return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to create project directory: %v", err), Err: err}
+	}
+	defer os.RemoveAll(dir)
+
+	var composeFiles []string
+	for i, file := range files {
+		// Only keep the base name so files can't be written outside of the project directory
+		name := filepath.Base(file.Name)
+		if name == "." || name == ".." || name == string(filepath.Separator) || name == StdinPath {
+			name = fmt.Sprintf("compose-%d.yml", i)
+		}
+		path := filepath.Join(dir, name)
+		if slices.Contains(composeFiles, path) {
+			return nil, &Error{Name: ArgumentError, Message: fmt.Sprintf("Duplicate compose file name %q", name)}
+		}
+		if err := os.WriteFile(path, file.Content, 0o600); err != nil {
+			return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to write compose file %q: %v", name, err), Err: err}
+		}
+		composeFiles = append(composeFiles, path)
+	}
+
+	// Locations refer to the files by the names they were given, as the project directory is temporary
+	var locations []*Location
+	var result *Result
+	var pathsErr *Error
+	if checkPaths {
+		pathsErr = checkContentPaths(files, composeFiles)
+	}
+	if pathsErr != nil {
+		err = pathsErr
+	} else {
+		result, err = p.Parse(ctx, composeFiles)
+	}
+	var parserErr *Error
+	if errors.As(err, &parserErr) {
+		for _, e := range append([]*Error{parserErr}, parserErr.Errors...) {
+			locations = append(locations, e.Location)
+		}
+	}
+	if result != nil {
+		for _, warning := range result.Warnings {
+			locations = append(locations, warning.Location)
+		}
+	}
+	for _, location := range locations {
+		if location == nil {
+			continue
+		}
+		if rel, relErr := filepath.Rel(dir, location.File); relErr == nil && !strings.HasPrefix(rel, "..") {
+			location.File = rel
+		}
+	}
+	return result, err
+}
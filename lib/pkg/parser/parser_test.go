@@ -0,0 +1,641 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The fixtures of the TypeScript specs
+const fixtures = "../../../test/fixtures"
+
+// Write files by path into a temporary directory, returning the directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// Parse compose files of contents, written into a temporary directory as compose.yml, compose-1.yml and
+// so on, with the project name "test" unless set
+func parse(t *testing.T, options Options, contents ...string) (*Result, error) {
+	t.Helper()
+	files := map[string]string{}
+	var composeFiles []string
+	for i, content := range contents {
+		name := "compose.yml"
+		if i > 0 {
+			name = fmt.Sprintf("compose-%d.yml", i)
+		}
+		files[name] = content
+		composeFiles = append(composeFiles, name)
+	}
+	dir := writeFiles(t, files)
+	for i, name := range composeFiles {
+		composeFiles[i] = filepath.Join(dir, name)
+	}
+	if options.ProjectName == "" && !options.BalenaNormalize {
+		options.ProjectName = "test"
+	}
+	return New(options).Parse(context.Background(), composeFiles)
+}
+
+// Parse compose files of contents as parse does, failing the test if parsing fails
+func mustParse(t *testing.T, options Options, contents ...string) *Result {
+	t.Helper()
+	result, err := parse(t, options, contents...)
+	if err != nil {
+		t.Fatalf("expected the parse to succeed, got %v", err)
+	}
+	return result
+}
+
+// Check err is an Error of a name, with a message containing message
+func expectError(t *testing.T, err error, name, message string) *Error {
+	t.Helper()
+	var parserErr *Error
+	if !errors.As(err, &parserErr) {
+		t.Fatalf("expected a %s, got %v", name, err)
+	}
+	if parserErr.Name != name || !strings.Contains(parserErr.Message, message) {
+		t.Errorf("expected a %s containing %q, got %s: %q", name, message, parserErr.Name, parserErr.Message)
+	}
+	return parserErr
+}
+
+func TestNew(t *testing.T) {
+	if p := New(Options{}); p.options.Timeout != DefaultTimeout {
+		t.Errorf("expected the default timeout %s, got %s", DefaultTimeout, p.options.Timeout)
+	}
+	if p := New(Options{Timeout: time.Minute}); p.options.Timeout != time.Minute {
+		t.Errorf("expected the timeout %s, got %s", time.Minute, p.options.Timeout)
+	}
+}
+
+func TestParse(t *testing.T) {
+	result, err := New(Options{ProjectName: "simple"}).Parse(context.Background(), []string{filepath.Join(fixtures, "simple.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	project := result.Project
+	if project.Name != "simple" {
+		t.Errorf("expected the project name simple, got %q", project.Name)
+	}
+	web, ok := project.Services["web"]
+	if !ok || web.Image != "nginx:latest" {
+		t.Fatalf("expected the web service of nginx:latest, got %+v", project.Services)
+	}
+	if _, ok := web.Networks["my-network"]; !ok {
+		t.Errorf("expected web to be attached to my-network, got %v", web.Networks)
+	}
+	if network := project.Networks["my-network"]; network.Name != "simple_my-network" {
+		t.Errorf("expected the network name simple_my-network, got %q", network.Name)
+	}
+}
+
+func TestParseOverrides(t *testing.T) {
+	result := mustParse(t, Options{},
+		"services:\n  web:\n    image: nginx\n    environment:\n      A: a\n      B: b\n",
+		"services:\n  web:\n    image: alpine\n    environment:\n      B: overridden\n",
+	)
+	web := result.Project.Services["web"]
+	if web.Image != "alpine" {
+		t.Errorf("expected the later image alpine, got %q", web.Image)
+	}
+	if a, b := web.Environment["A"], web.Environment["B"]; a == nil || *a != "a" || b == nil || *b != "overridden" {
+		t.Errorf("expected the environment to be merged, got %v", web.Environment)
+	}
+}
+
+func TestParseArguments(t *testing.T) {
+	tests := []struct {
+		name         string
+		options      Options
+		composeFiles []string
+		message      string
+	}{
+		{name: "no compose files", options: Options{ProjectName: "test"}, message: "At least one compose file must be specified"},
+		{name: "no project name", composeFiles: []string{"compose.yml"}, message: "Project name is required"},
+		{name: "negative timeout", options: Options{ProjectName: "test", Timeout: -time.Second}, composeFiles: []string{"compose.yml"}, message: "Timeout must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.options).Parse(context.Background(), tt.composeFiles)
+			expectError(t, err, ArgumentError, tt.message)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		_, err := New(Options{ProjectName: "test"}).Parse(context.Background(), []string{filepath.Join(t.TempDir(), "compose.yml")})
+		expectError(t, err, IOError, "")
+	})
+	t.Run("invalid YAML", func(t *testing.T) {
+		_, err := parse(t, Options{}, "services: [\n")
+		expectError(t, err, ParseError, "")
+	})
+	t.Run("invalid compose file", func(t *testing.T) {
+		_, err := parse(t, Options{}, "services:\n  web:\n    image: nginx\n    ports: true\n")
+		expectError(t, err, ValidationError, "ports")
+	})
+}
+
+func TestParseContent(t *testing.T) {
+	p := New(Options{ProjectName: "test"})
+	result, err := p.ParseContent(context.Background(), []File{
+		{Name: "docker-compose.yml", Content: []byte("services:\n  web:\n    image: nginx\n")},
+		{Name: "/etc/override.yml", Content: []byte("services:\n  web:\n    command: [a]\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if web := result.Project.Services["web"]; web.Image != "nginx" || len(web.Command) != 1 {
+		t.Errorf("expected the files to be merged, got %+v", web)
+	}
+
+	_, err = p.ParseContent(context.Background(), nil)
+	expectError(t, err, ArgumentError, "At least one compose file must be specified")
+
+	_, err = p.ParseContent(context.Background(), []File{
+		{Name: "compose.yml", Content: []byte("services: {}\n")},
+		{Name: "other/compose.yml", Content: []byte("services: {}\n")},
+	})
+	expectError(t, err, ArgumentError, "Duplicate compose file name \"compose.yml\"")
+}
+
+func TestEnvFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx:${TAG:-latest}\n    command: [\"${FLEET:-none}\", \"${DEVICE:-none}\"]\n",
+		".env":        "TAG=dotenv\n",
+		"fleet.env":   "TAG=fleet\nFLEET=fleet\nDEVICE=fleet\n",
+		"device.env":  "DEVICE=device\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	tests := []struct {
+		name     string
+		envFiles []string
+		image    string
+		command  string
+	}{
+		// The project's .env file isn't read unless listed
+		{name: "no env files", image: "nginx:latest", command: "none none"},
+		{name: ".env", envFiles: []string{filepath.Join(dir, ".env")}, image: "nginx:dotenv", command: "none none"},
+		{name: "overriding", envFiles: []string{filepath.Join(dir, ".env"), filepath.Join(dir, "fleet.env"), filepath.Join(dir, "device.env")}, image: "nginx:fleet", command: "fleet device"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{ProjectName: "test", EnvFiles: tt.envFiles}).Parse(context.Background(), []string{composeFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			web := result.Project.Services["web"]
+			if web.Image != tt.image || strings.Join(web.Command, " ") != tt.command {
+				t.Errorf("expected %s running %q, got %s running %q", tt.image, tt.command, web.Image, strings.Join(web.Command, " "))
+			}
+			// Variables of env files are only interpolated, not set in the containers
+			if len(web.Environment) != 0 {
+				t.Errorf("expected no environment, got %v", web.Environment)
+			}
+		})
+	}
+
+	t.Run("precedence", func(t *testing.T) {
+		t.Setenv("FLEET", "os")
+		result, err := New(Options{ProjectName: "test", EnvFiles: []string{filepath.Join(dir, "fleet.env")}, Environment: map[string]string{"DEVICE": "option"}}).Parse(context.Background(), []string{composeFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if command := strings.Join(result.Project.Services["web"].Command, " "); command != "os option" {
+			t.Errorf("expected the process environment and Options.Environment to override env files, got %q", command)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := New(Options{ProjectName: "test", EnvFiles: []string{filepath.Join(dir, "missing.env")}}).Parse(context.Background(), []string{composeFile})
+		expectError(t, err, IOError, "Failed to read env file")
+	})
+}
+
+func TestNoOSEnv(t *testing.T) {
+	t.Setenv("BALENA_TEST_TAG", "os")
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx:${BALENA_TEST_TAG:-latest}\n    command: [\"${FROM_FILE:-none}\"]\n",
+		"build.env":   "FROM_FILE=file\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	tests := []struct {
+		name    string
+		options Options
+		image   string
+	}{
+		{name: "process environment", options: Options{}, image: "nginx:os"},
+		{name: "no process environment", options: Options{NoOSEnv: true}, image: "nginx:latest"},
+		{name: "explicit variables", options: Options{NoOSEnv: true, Environment: map[string]string{"BALENA_TEST_TAG": "option"}}, image: "nginx:option"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.ProjectName = "test"
+			tt.options.EnvFiles = []string{filepath.Join(dir, "build.env")}
+			result, err := New(tt.options).Parse(context.Background(), []string{composeFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			web := result.Project.Services["web"]
+			if web.Image != tt.image || strings.Join(web.Command, " ") != "file" {
+				t.Errorf("expected %s with the variables of the env file, got %s running %v", tt.image, web.Image, web.Command)
+			}
+		})
+	}
+}
+func TestEnvAllowDeny(t *testing.T) {
+	t.Setenv("BALENA_TEST_TAG", "os")
+	t.Setenv("BALENA_TEST_VERSION", "16")
+	t.Setenv("AWS_TEST_SECRET", "secret")
+	composeFile := filepath.Join(writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx:${BALENA_TEST_TAG:-latest}\n    command: [\"${BALENA_TEST_VERSION:-none}\", \"${AWS_TEST_SECRET:-none}\"]\n",
+	}), "compose.yml")
+	tests := []struct {
+		name    string
+		options Options
+		image   string
+		command string
+	}{
+		{name: "everything", options: Options{}, image: "nginx:os", command: "16 secret"},
+		{name: "allowed", options: Options{EnvAllow: []string{"BALENA_*"}}, image: "nginx:os", command: "16 none"},
+		{name: "allowed ignoring case", options: Options{EnvAllow: []string{"balena_test_tag"}}, image: "nginx:os", command: "none none"},
+		{name: "denied", options: Options{EnvDeny: []string{"AWS_*"}}, image: "nginx:os", command: "16 none"},
+		{name: "denied even if allowed", options: Options{EnvAllow: []string{"BALENA_*"}, EnvDeny: []string{"*_VERSION"}}, image: "nginx:os", command: "none none"},
+		// Explicit variables aren't from the process environment, so aren't filtered
+		{name: "explicit variables", options: Options{EnvDeny: []string{"*"}, Environment: map[string]string{"BALENA_TEST_TAG": "option"}}, image: "nginx:option", command: "none none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.ProjectName = "test"
+			result, err := New(tt.options).Parse(context.Background(), []string{composeFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			web := result.Project.Services["web"]
+			if web.Image != tt.image || strings.Join(web.Command, " ") != tt.command {
+				t.Errorf("expected %s running %s, got %s running %v", tt.image, tt.command, web.Image, web.Command)
+			}
+		})
+	}
+}
+
+func TestNoDotEnv(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx:${TAG:-latest}\n",
+		".env":        "TAG=dotenv\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	tests := []struct {
+		name     string
+		envFiles []string
+		image    string
+	}{
+		{name: "untrusted .env", image: "nginx:latest"},
+		// Env files which are listed are still read
+		{name: "listed .env", envFiles: []string{filepath.Join(dir, ".env")}, image: "nginx:dotenv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New(Options{ProjectName: "test", NoDotEnv: true, EnvFiles: tt.envFiles}).Parse(context.Background(), []string{composeFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if image := result.Project.Services["web"].Image; image != tt.image {
+				t.Errorf("expected %s, got %s", tt.image, image)
+			}
+		})
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	t.Setenv("TAG", "os")
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx:${TAG}\n    command: [\"${UUID}\", \"${EMPTY-unset}\"]\n",
+		"build.env":   "TAG=file\nUUID=file\n",
+	})
+	result, err := New(Options{
+		ProjectName: "test",
+		EnvFiles:    []string{filepath.Join(dir, "build.env")},
+		Environment: map[string]string{"TAG": "option", "UUID": "a1b2", "EMPTY": ""},
+	}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := result.Project.Services["web"]
+	if web.Image != "nginx:option" || strings.Join(web.Command, ",") != "a1b2," {
+		t.Errorf("expected the variables to override the process environment and env files, got %s running %q", web.Image, web.Command)
+	}
+}
+
+func TestNoInterpolate(t *testing.T) {
+	result := mustParse(t, Options{NoInterpolate: true, Environment: map[string]string{"TAG": "1.25"}},
+		"services:\n  web:\n    image: nginx:${TAG}\n    environment:\n      UUID: ${UUID:?required}\n      URL: https://$HOST/${PATH:-api}\n",
+	)
+	web := result.Project.Services["web"]
+	if web.Image != "nginx:${TAG}" {
+		t.Errorf("expected the image to be preserved verbatim, got %s", web.Image)
+	}
+	// Required variables which are unset don't fail the parse
+	if uuid := web.Environment["UUID"]; uuid == nil || *uuid != "${UUID:?required}" {
+		t.Errorf("expected the required variable to be preserved verbatim, got %v", uuid)
+	}
+	if url := web.Environment["URL"]; url == nil || *url != "https://$HOST/${PATH:-api}" {
+		t.Errorf("expected the URL to be preserved verbatim, got %v", url)
+	}
+
+	// Values are validated before interpolation
+	_, err := parse(t, Options{NoInterpolate: true}, "services:\n  web:\n    image: nginx\n    ports: ${PORTS}\n")
+	expectError(t, err, ValidationError, "ports")
+}
+
+func TestNoNormalize(t *testing.T) {
+	compose := "services:\n  web:\n    build: .\n    links: [db]\n  db:\n    image: postgres\n"
+	project := mustParse(t, Options{}, compose).Project
+	web := project.Services["web"]
+	if _, ok := project.Networks["default"]; !ok || web.Build.Dockerfile != "Dockerfile" || web.DependsOn["db"].Condition != "service_started" {
+		t.Errorf("expected the project to be normalized, got %+v", project)
+	}
+
+	project = mustParse(t, Options{NoNormalize: true}, compose).Project
+	web = project.Services["web"]
+	if len(project.Networks) != 0 || len(web.Networks) != 0 {
+		t.Errorf("expected no default network, got %v and %v", project.Networks, web.Networks)
+	}
+	if web.Build.Dockerfile != "" || len(web.DependsOn) != 0 {
+		t.Errorf("expected no default Dockerfile or implied dependencies, got %q and %v", web.Build.Dockerfile, web.DependsOn)
+	}
+}
+
+func TestSkipConsistency(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose string
+		message string
+	}{
+		{name: "network", compose: "services:\n  web:\n    image: nginx\n    networks: [backend]\n", message: "undefined network backend"},
+		{name: "volume", compose: "services:\n  web:\n    image: nginx\n    volumes: [\"data:/data\"]\n", message: "undefined volume data"},
+		{name: "secret", compose: "services:\n  web:\n    image: nginx\n    secrets: [token]\n", message: "undefined secret token"},
+		{name: "service", compose: "services:\n  web:\n    image: nginx\n    depends_on: [db]\n", message: "depends on undefined service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(t, Options{}, tt.compose)
+			expectError(t, err, ValidationError, tt.message)
+			if _, err := parse(t, Options{SkipConsistency: true}, tt.compose); err != nil {
+				t.Errorf("expected a partial file to parse with Options.SkipConsistency, got %v", err)
+			}
+		})
+	}
+}
+
+func TestProjectDirectory(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose/compose.yml": "services:\n  web:\n    build: ./web\n    volumes:\n      - ./data:/data\n",
+		"root/file":           "",
+	})
+	composeFile := filepath.Join(dir, "compose", "compose.yml")
+	tests := []struct {
+		name    string
+		options Options
+		root    string
+	}{
+		{name: "directory of the compose file", options: Options{}, root: filepath.Join(dir, "compose")},
+		{name: "project directory", options: Options{ProjectDirectory: dir}, root: dir},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.ProjectName = "test"
+			result, err := New(tt.options).Parse(context.Background(), []string{composeFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			web := result.Project.Services["web"]
+			if result.Project.WorkingDir != tt.root || web.Build.Context != filepath.Join(tt.root, "web") || web.Volumes[0].Source != filepath.Join(tt.root, "data") {
+				t.Errorf("expected paths relative to %s, got %s, %s and %s", tt.root, result.Project.WorkingDir, web.Build.Context, web.Volumes[0].Source)
+			}
+		})
+	}
+
+	_, err := New(Options{ProjectName: "test", ProjectDirectory: filepath.Join(dir, "missing")}).Parse(context.Background(), []string{composeFile})
+	expectError(t, err, IOError, "Failed to read project directory")
+	_, err = New(Options{ProjectName: "test", ProjectDirectory: filepath.Join(dir, "root", "file")}).Parse(context.Background(), []string{composeFile})
+	expectError(t, err, ArgumentError, "is not a directory")
+}
+
+func TestRelativePaths(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    build: ./web\n    env_file: ./web.env\n    volumes:\n      - ./data:/data\n      - /var/run/docker.sock:/var/run/docker.sock\n",
+		"web.env":     "FROM_FILE=file\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	tests := []struct {
+		name    string
+		options Options
+		context string
+		source  string
+		envFile string
+	}{
+		{name: "resolved paths", options: Options{}, context: filepath.Join(dir, "web"), source: filepath.Join(dir, "data"), envFile: filepath.Join(dir, "web.env")},
+		{name: "relative paths", options: Options{RelativePaths: true}, context: "./web", source: "./data", envFile: "./web.env"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.ProjectName = "test"
+			result, err := New(tt.options).Parse(context.Background(), []string{composeFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			web := result.Project.Services["web"]
+			if web.Build.Context != tt.context || web.Volumes[0].Source != tt.source || web.EnvFiles[0].Path != tt.envFile {
+				t.Errorf("expected %s, %s and %s, got %s, %s and %s", tt.context, tt.source, tt.envFile, web.Build.Context, web.Volumes[0].Source, web.EnvFiles[0].Path)
+			}
+			if web.Volumes[1].Source != "/var/run/docker.sock" {
+				t.Errorf("expected absolute paths to be kept, got %s", web.Volumes[1].Source)
+			}
+			// Env files are read relative to the project directory either way
+			if value := web.Environment["FROM_FILE"]; value == nil || *value != "file" {
+				t.Errorf("expected the variables of the env file, got %v", web.Environment)
+			}
+		})
+	}
+}
+
+func TestDockerCompatible(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx\n    env_file: web.env\n    networks: [frontend]\n    volumes: [\"data:/data\"]\n" +
+			"networks:\n  frontend: {}\n  unused: {}\nvolumes:\n  data: {}\n  unused: {}\nsecrets:\n  unused:\n    file: ./secret\n",
+		"web.env": "FROM_FILE=file\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	for _, relativePaths := range []bool{false, true} {
+		t.Run(fmt.Sprintf("relative paths %t", relativePaths), func(t *testing.T) {
+			result, err := New(Options{ProjectName: "test", DockerCompatible: true, RelativePaths: relativePaths}).Parse(context.Background(), []string{composeFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			project := result.Project
+			web := project.Services["web"]
+			if value := web.Environment["FROM_FILE"]; value == nil || *value != "file" || len(web.EnvFiles) != 0 {
+				t.Errorf("expected the env file to be discarded once resolved, got %v and %v", web.Environment, web.EnvFiles)
+			}
+			if networks := slices.Sorted(maps.Keys(project.Networks)); !slices.Equal(networks, []string{"frontend"}) {
+				t.Errorf("expected unused networks to be dropped, got %v", networks)
+			}
+			if volumes := slices.Sorted(maps.Keys(project.Volumes)); !slices.Equal(volumes, []string{"data"}) || len(project.Secrets) != 0 {
+				t.Errorf("expected unused volumes and secrets to be dropped, got %v and %v", volumes, project.Secrets)
+			}
+		})
+	}
+
+	project := mustParse(t, Options{}, "services:\n  web:\n    image: nginx\nvolumes:\n  unused: {}\n").Project
+	if _, ok := project.Volumes["unused"]; !ok {
+		t.Error("expected unused volumes to be kept by default")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx\n  debug:\n    image: busybox\n    profiles: [debug]\n  metrics:\n    image: prometheus\n    profiles: [metrics, debug]\n  test:\n    image: node\n    profiles: [test]\n",
+		"build.env":   "COMPOSE_PROFILES=test\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	tests := []struct {
+		name     string
+		options  Options
+		expected []string
+	}{
+		{name: "no profiles", options: Options{}, expected: []string{"web"}},
+		{name: "profile", options: Options{Profiles: []string{"debug"}}, expected: []string{"debug", "metrics", "web"}},
+		{name: "profiles", options: Options{Profiles: []string{"metrics", "test"}}, expected: []string{"metrics", "test", "web"}},
+		{name: "all profiles", options: Options{Profiles: []string{"*"}}, expected: []string{"debug", "metrics", "test", "web"}},
+		{name: "COMPOSE_PROFILES", options: Options{Environment: map[string]string{"COMPOSE_PROFILES": "debug,test"}}, expected: []string{"debug", "metrics", "test", "web"}},
+		{name: "COMPOSE_PROFILES of an env file", options: Options{EnvFiles: []string{filepath.Join(dir, "build.env")}}, expected: []string{"test", "web"}},
+		{name: "profiles overriding COMPOSE_PROFILES", options: Options{Profiles: []string{"metrics"}, Environment: map[string]string{"COMPOSE_PROFILES": "test"}}, expected: []string{"metrics", "web"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.ProjectName = "test"
+			tt.options.NoOSEnv = true
+			result, err := New(tt.options).Parse(context.Background(), []string{composeFile})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if services := slices.Sorted(maps.Keys(result.Project.Services)); !slices.Equal(services, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, services)
+			}
+		})
+	}
+}
+
+func TestServices(t *testing.T) {
+	compose := "services:\n" +
+		"  web:\n    image: nginx\n    depends_on: [api]\n" +
+		"  api:\n    image: node\n    depends_on:\n      db:\n        condition: service_healthy\n" +
+		"  db:\n    image: postgres\n" +
+		"  worker:\n    image: node\n    depends_on: [db]\n" +
+		"  debug:\n    image: busybox\n    profiles: [debug]\n    depends_on: [metrics]\n" +
+		"  metrics:\n    image: prometheus\n    profiles: [metrics]\n" +
+		"volumes:\n  data: {}\n"
+	tests := []struct {
+		name     string
+		services []string
+		expected []string
+	}{
+		{name: "all services", expected: []string{"api", "db", "web", "worker"}},
+		{name: "transitive dependencies", services: []string{"web"}, expected: []string{"api", "db", "web"}},
+		{name: "several services", services: []string{"worker", "api"}, expected: []string{"api", "db", "worker"}},
+		{name: "dependency", services: []string{"db"}, expected: []string{"db"}},
+		// Services disabled by their profiles are enabled when named
+		{name: "services with profiles", services: []string{"metrics"}, expected: []string{"metrics"}},
+		{name: "dependencies with profiles", services: []string{"debug", "metrics"}, expected: []string{"debug", "metrics"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := mustParse(t, Options{Services: tt.services}, compose).Project
+			if services := slices.Sorted(maps.Keys(project.Services)); !slices.Equal(services, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, services)
+			}
+			if _, ok := project.Volumes["data"]; !ok {
+				t.Error("expected the volumes to be kept")
+			}
+		})
+	}
+
+	_, err := parse(t, Options{Services: []string{"missing"}}, compose)
+	expectError(t, err, ArgumentError, "No such service: missing")
+	// As with docker compose, the profiles of dependencies aren't enabled
+	_, err = parse(t, Options{Services: []string{"debug"}}, compose)
+	expectError(t, err, ValidationError, "Failed to select services: no such service: metrics")
+}
+
+func TestParseParsed(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx:${TAG:-1.25}\n    build: ./web\n    env_file: web.env\n    environment:\n      ESCAPED: $$HOME\n" +
+			"  debug:\n    image: busybox\n    profiles: [debug]\n",
+		"web.env": "FROM_FILE=file\n",
+	})
+	parsed, err := New(Options{ProjectName: "stored", Profiles: []string{"debug"}}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	projectJSON, err := parsed.Project.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := writeFiles(t, map[string]string{
+		"project.json":  string(projectJSON),
+		"wrapped.json":  `{"project": ` + string(projectJSON) + `, "warnings": []}`,
+		"override.json": `{"services": {"web": {"environment": {"TAG": "override"}}}}`,
+		"invalid.json":  `{"services": `,
+	})
+
+	for _, file := range []string{"project.json", "wrapped.json"} {
+		t.Run(file, func(t *testing.T) {
+			result, err := New(Options{}).ParseParsed(context.Background(), []string{filepath.Join(stored, file), filepath.Join(stored, "override.json")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			project := result.Project
+			web := project.Services["web"]
+			// Named as stored, with services disabled by profiles kept and variables not interpolated again
+			if project.Name != "stored" || project.Services["debug"].Image != "busybox" {
+				t.Errorf("expected the stored project with every service, got %s with %v", project.Name, project.ServiceNames())
+			}
+			if web.Image != "nginx:1.25" || *web.Environment["ESCAPED"] != "$HOME" || *web.Environment["TAG"] != "override" {
+				t.Errorf("expected the stored values, got %s and %v", web.Image, web.Environment)
+			}
+			// Resolved paths are kept
+			if web.Build.Context != filepath.Join(dir, "web") || *web.Environment["FROM_FILE"] != "file" {
+				t.Errorf("expected the stored build context and env file, got %s and %v", web.Build.Context, web.Environment)
+			}
+		})
+	}
+
+	_, err = New(Options{}).ParseParsed(context.Background(), nil)
+	expectError(t, err, ArgumentError, "At least one compose file must be specified")
+	_, err = New(Options{}).ParseParsed(context.Background(), []string{filepath.Join(stored, "missing.json")})
+	expectError(t, err, IOError, "Failed to read parsed project")
+	_, err = New(Options{}).ParseParsed(context.Background(), []string{filepath.Join(stored, "invalid.json")})
+	expectError(t, err, ParseError, "Failed to parse parsed project")
+}
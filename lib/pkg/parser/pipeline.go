@@ -0,0 +1,281 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// The state of a parse once the project is loaded, which its stages transform, validate and report on
+type parseState struct {
+	project      *types.Project
+	composeFiles []string
+	// The architecture of Options.DeviceType and Options.Arch, if either is set
+	arch string
+	// The compose-go options the project was loaded with, with the environment it was interpolated from
+	options *cli.ProjectOptions
+	// The variables substituted, if recorded for Options.StrictEnv or Options.EnvResolution
+	substitutions []substitution
+	// The warnings of legacy 2.x files and of the validations, added to Result.Warnings with Options.Warnings
+	legacyWarnings []Warning
+	warnings       []Warning
+	// The values of Options.MaskEnv to mask
+	masks *masker
+	// The result of the parse, which stages add their reports to
+	result *Result
+}
+
+// A stage of a parse, failing it if it returns an error
+type parseStage func(state *parseState) error
+
+// The stages of a parse once the project is loaded, in order: transformations of the project as loaded,
+// validations, the reports of Options, then transformations of the project as output and the validations
+// of the output
+func (p *Parser) stages() []parseStage {
+	return []parseStage{
+		p.checkUnsetVariables,
+		p.resolveRelativeEnvFiles,
+		p.restrictServices,
+		p.applyDefaultsFile,
+		p.dropUnnecessaryResources,
+		p.checkProject,
+		p.validateTarget,
+		p.recordFeatures,
+		p.validateDevice,
+		p.mergeContract,
+		p.enforcePolicy,
+		p.recordBuilds,
+		p.rewriteProject,
+		p.validateOutputSchemas,
+		p.recordReports,
+	}
+}
+
+// Fail with Options.StrictEnv if any substituted variable is unset
+func (p *Parser) checkUnsetVariables(state *parseState) error {
+	if names := unsetVariables(state.substitutions); p.options.StrictEnv && len(names) > 0 {
+		return unsetVariablesError(names, state.composeFiles)
+	}
+	return nil
+}
+
+// Resolve the environment of services from their env files with Options.RelativePaths, as compose-go
+// would read them from the current directory
+func (p *Parser) resolveRelativeEnvFiles(state *parseState) error {
+	if !p.options.RelativePaths {
+		return nil
+	}
+	project, err := resolveServiceEnvironment(state.project, p.options.DockerCompatible)
+	if err != nil {
+		return &Error{Name: loadErrorName(err), Message: fmt.Sprintf("Failed to parse compose file: %v", err), Err: err}
+	}
+	state.project = project
+	return nil
+}
+
+// Restrict the project to Options.Services and their dependencies
+func (p *Parser) restrictServices(state *parseState) error {
+	if len(p.options.Services) == 0 {
+		return nil
+	}
+	project, err := selectServices(state.project, p.options.Services)
+	if err != nil {
+		return err
+	}
+	state.project = project
+	return nil
+}
+
+// Apply the defaults of Options.Defaults beneath the compose files
+func (p *Parser) applyDefaultsFile(state *parseState) error {
+	if p.options.Defaults == "" {
+		return nil
+	}
+	defaults, err := readDefaults(p.options.Defaults)
+	if err != nil {
+		return err
+	}
+	state.result.Defaults = applyDefaults(state.project, defaults)
+	return nil
+}
+
+// Drop the resources no service uses with Options.DockerCompatible, as docker compose config does
+func (p *Parser) dropUnnecessaryResources(state *parseState) error {
+	if p.options.DockerCompatible {
+		state.project = state.project.WithoutUnnecessaryResources()
+	}
+	return nil
+}
+
+// Run the validations of every parse, failing with the first ValidationError
+func (p *Parser) checkProject(state *parseState) error {
+	var checks []func(*types.Project, []string) *Error
+	if !p.options.SkipConsistency {
+		checks = append(checks, checkContainerNames)
+	}
+	checks = append(checks,
+		checkPrivateLabels,
+		checkDeviceRequests,
+		func(project *types.Project, composeFiles []string) *Error {
+			return checkLimits(project, p.options.MaxServices, p.options.MaxVolumes, composeFiles)
+		},
+	)
+	for _, check := range checks {
+		if err := check(state.project, state.composeFiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate the project against Options.Target, and for balena, the options the engine rejects
+func (p *Parser) validateTarget(state *parseState) error {
+	if p.options.Target == "" {
+		return nil
+	}
+	if p.options.Target == BalenaTarget {
+		if err := checkKernelOptions(state.project, state.composeFiles); err != nil {
+			return err
+		}
+		if err := checkNamespaceModes(state.project, p.options.SkipConsistency, state.composeFiles); err != nil {
+			return err
+		}
+	}
+	targetErr, warnings := checkTarget(state.project, p.options.Target, p.options.SupervisorVersion, p.options.OSVersion, state.composeFiles)
+	if targetErr != nil {
+		return targetErr
+	}
+	state.warnings = append(state.warnings, warnings...)
+	return nil
+}
+
+// Record the features of the io.balena.features labels with Options.ExpandFeatures
+func (p *Parser) recordFeatures(state *parseState) error {
+	if !p.options.ExpandFeatures {
+		return nil
+	}
+	// Feature labels are already checked when validating for balena
+	if p.options.Target != BalenaTarget {
+		featuresErr, warnings := checkFeatures(state.project, state.composeFiles)
+		if featuresErr != nil {
+			return featuresErr
+		}
+		state.warnings = append(state.warnings, warnings...)
+	}
+	state.result.Features = expandFeatures(state.project)
+	return nil
+}
+
+// Check every service can run on Options.DeviceType or Options.Arch
+func (p *Parser) validateDevice(state *parseState) error {
+	if state.arch == "" {
+		return nil
+	}
+	if err := checkDevice(state.project, state.arch, cmp.Or(p.options.DeviceType, state.arch), state.composeFiles); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Read and check Options.Contract against the project
+func (p *Parser) mergeContract(state *parseState) error {
+	if p.options.Contract == "" {
+		return nil
+	}
+	contract, err := readContract(p.options.Contract)
+	if err != nil {
+		return err
+	}
+	if err := checkContract(contract, p.options.Contract, state.project, state.composeFiles); err != nil {
+		return err
+	}
+	state.result.Contract = contract
+	return nil
+}
+
+// Apply Options.Policy to the host access fields of services
+func (p *Parser) enforcePolicy(state *parseState) error {
+	if p.options.Policy == "" {
+		return nil
+	}
+	policy, policyErr, warnings := applyPolicy(state.project, p.options.Policy, state.composeFiles)
+	if policyErr != nil {
+		return policyErr
+	}
+	state.result.Policy = policy
+	state.warnings = append(state.warnings, warnings...)
+	return nil
+}
+
+// Record the build of each service with Options.Builds
+func (p *Parser) recordBuilds(state *parseState) error {
+	if !p.options.Builds {
+		return nil
+	}
+	builds, buildsErr := describeBuilds(state.project, p.options.DeviceType, state.composeFiles)
+	if buildsErr != nil {
+		return buildsErr
+	}
+	state.result.Builds = builds
+	return nil
+}
+
+// Transform the validated project into the project as output: add Options.PrivateLabels, the balena
+// defaults and normalization, and mask the values of Options.MaskEnv
+func (p *Parser) rewriteProject(state *parseState) error {
+	if len(p.options.PrivateLabels) > 0 {
+		injectPrivateLabels(state.project, p.options.PrivateLabels)
+	}
+	if p.options.BalenaDefaults {
+		balenaDefaults(state.project)
+	}
+	if p.options.BalenaNormalize {
+		balenaNormalize(state.project)
+	}
+	state.masks.maskProject(state.project)
+	return nil
+}
+
+// Validate the project as output against Options.ExtraSchema and Options.ExtensionSchemas
+func (p *Parser) validateOutputSchemas(state *parseState) error {
+	if p.options.ExtraSchema != "" {
+		compiled, err := readExtraSchema(p.options.ExtraSchema)
+		if err != nil {
+			return err
+		}
+		if err := checkExtraSchema(state.project, compiled, p.options.ExtraSchema, state.composeFiles); err != nil {
+			return err
+		}
+	}
+	if len(p.options.ExtensionSchemas) > 0 {
+		schemas, err := readExtensionSchemas(p.options.ExtensionSchemas)
+		if err != nil {
+			return err
+		}
+		if err := checkExtensionSchemas(state.project, schemas, state.composeFiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add the project and the remaining reports of Options to the result, masking the values of Options.MaskEnv
+func (p *Parser) recordReports(state *parseState) error {
+	state.result.Project = state.project
+	if p.options.EnvResolution {
+		state.result.EnvResolution = p.resolveEnvironment(state.substitutions, state.options)
+	}
+	if p.options.GPU {
+		state.result.GPU = gpuRequests(state.project)
+	}
+	if p.options.Overrides {
+		state.result.Overrides = append([]Override{}, findOverrides(state.composeFiles)...)
+	}
+	if p.options.Warnings {
+		state.result.Warnings = append(append(collectWarnings(state.composeFiles, state.options.Environment, !p.options.NoInterpolate), state.legacyWarnings...), state.warnings...)
+	}
+	state.masks.maskResult(state.result)
+	return nil
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Policies governing the fields giving services privileged or host access, set with Options.Policy
+const (
+	// StrictPolicy rejects every host access field
+	StrictPolicy = "strict"
+	// FleetDefaultPolicy allows host networking, which balena fleets commonly use, and warns about the
+	// other host access fields
+	FleetDefaultPolicy = "fleet-default"
+	// PermissivePolicy allows every host access field, only recording them
+	PermissivePolicy = "permissive"
+)
+
+// Policies are the policies Options.Policy accepts
+var Policies = []string{StrictPolicy, FleetDefaultPolicy, PermissivePolicy}
+
+// Decisions of a policy on a host access field
+const (
+	AllowDecision  = "allow"
+	WarnDecision   = "warn"
+	RejectDecision = "reject"
+)
+
+// Codes of the warnings and errors for host access fields the policy warns about or rejects
+const (
+	// PolicyWarningCode is reported as a warning for fields the policy warns about
+	PolicyWarningCode = "policy-warning"
+	// PolicyViolationCode is reported as an error for fields the policy rejects
+	PolicyViolationCode = "policy-violation"
+)
+
+// Capabilities which give broad control of the host, so cap_add lists including them are governed by
+// the policy like privileged
+var broadCapabilities = []string{"ALL", "SYS_ADMIN", "SYS_MODULE", "SYS_RAWIO", "SYS_PTRACE", "SYS_BOOT", "DAC_READ_SEARCH", "NET_ADMIN"}
+
+// The decision of each policy on each host access field, in the order the fields are checked
+var policyRules = []struct {
+	field     string
+	decisions map[string]string
+}{
+	{"privileged", map[string]string{StrictPolicy: RejectDecision, FleetDefaultPolicy: WarnDecision, PermissivePolicy: AllowDecision}},
+	{"network_mode", map[string]string{StrictPolicy: RejectDecision, FleetDefaultPolicy: AllowDecision, PermissivePolicy: AllowDecision}},
+	{"pid", map[string]string{StrictPolicy: RejectDecision, FleetDefaultPolicy: WarnDecision, PermissivePolicy: AllowDecision}},
+	{"cap_add", map[string]string{StrictPolicy: RejectDecision, FleetDefaultPolicy: WarnDecision, PermissivePolicy: AllowDecision}},
+}
+
+// PolicyDecision is the decision of the policy on a host access field set by a service
+type PolicyDecision struct {
+	// Field is the service field, e.g. privileged
+	Field string `json:"field"`
+	// Decision is "allow", "warn" or "reject"
+	Decision string `json:"decision"`
+	// Message describes the access the field gives
+	Message string `json:"message"`
+}
+
+// The host access a service field gives, or "" if it doesn't give any
+func hostAccess(service types.ServiceConfig, field string) string {
+	switch field {
+	case "privileged":
+		if service.Privileged {
+			return "privileged gives access to every device and capability of the host"
+		}
+	case "network_mode":
+		if service.NetworkMode == "host" {
+			return "network_mode: host shares the network stack of the host"
+		}
+	case "pid":
+		if service.Pid == "host" {
+			return "pid: host shares the process namespace of the host"
+		}
+	case "cap_add":
+		var broad []string
+		for _, capability := range service.CapAdd {
+			if slices.Contains(broadCapabilities, strings.TrimPrefix(strings.ToUpper(capability), "CAP_")) {
+				broad = append(broad, capability)
+			}
+		}
+		if len(broad) > 0 {
+			return fmt.Sprintf("cap_add gives broad control of the host with %s", strings.Join(broad, ", "))
+		}
+	}
+	return ""
+}
+
+// Apply a policy to the host access fields of every service, returning the decisions by service name,
+// a ValidationError listing every rejected field, and warnings for those warned about
+func applyPolicy(project *types.Project, policy string, composeFiles []string) (map[string][]PolicyDecision, *Error, []Warning) {
+	decisions := map[string][]PolicyDecision{}
+	var issues []targetIssue
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		for _, rule := range policyRules {
+			access := hostAccess(project.Services[name], rule.field)
+			if access == "" {
+				continue
+			}
+			decision := rule.decisions[policy]
+			decisions[name] = append(decisions[name], PolicyDecision{Field: rule.field, Decision: decision, Message: access})
+			path := "services." + name + "." + rule.field
+			switch decision {
+			case WarnDecision:
+				issues = append(issues, targetIssue{code: PolicyWarningCode, path: path, message: fmt.Sprintf("%s: %s", path, access), warning: true})
+			case RejectDecision:
+				issues = append(issues, targetIssue{code: PolicyViolationCode, path: path, message: fmt.Sprintf("%s: %s", path, access)})
+			}
+		}
+	}
+	err, warnings := reportIssues(fmt.Sprintf("Project violates the %s policy", policy), issues, composeFiles)
+	return decisions, err, warnings
+}
@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	compose := "services:\n" +
+		"  web:\n    image: nginx\n    privileged: true\n    network_mode: host\n" +
+		"  agent:\n    image: alpine\n    pid: host\n    cap_add: [NET_BIND_SERVICE, cap_sys_admin]\n" +
+		"  db:\n    image: postgres\n    cap_add: [NET_BIND_SERVICE]\n"
+	tests := []struct {
+		policy   string
+		errors   []string
+		warnings []string
+	}{
+		{
+			policy: StrictPolicy,
+			errors: []string{"policy-violation services.agent.pid", "policy-violation services.agent.cap_add", "policy-violation services.web.privileged", "policy-violation services.web.network_mode"},
+		},
+		{
+			policy:   FleetDefaultPolicy,
+			warnings: []string{"policy-warning services.agent.pid", "policy-warning services.agent.cap_add", "policy-warning services.web.privileged"},
+		},
+		{policy: PermissivePolicy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			errs, warnings := targetIssues(t, Options{Policy: tt.policy}, compose)
+			if !reflect.DeepEqual(errs, tt.errors) {
+				t.Errorf("expected the errors %v, got %v", tt.errors, errs)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("expected the warnings %v, got %v", tt.warnings, warnings)
+			}
+		})
+	}
+
+	result := mustParse(t, Options{Policy: PermissivePolicy}, compose)
+	expected := map[string][]PolicyDecision{
+		"web": {
+			{Field: "privileged", Decision: AllowDecision, Message: "privileged gives access to every device and capability of the host"},
+			{Field: "network_mode", Decision: AllowDecision, Message: "network_mode: host shares the network stack of the host"},
+		},
+		"agent": {
+			{Field: "pid", Decision: AllowDecision, Message: "pid: host shares the process namespace of the host"},
+			{Field: "cap_add", Decision: AllowDecision, Message: "cap_add gives broad control of the host with cap_sys_admin"},
+		},
+	}
+	if !reflect.DeepEqual(result.Policy, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result.Policy)
+	}
+	if result := mustParse(t, Options{}, compose); result.Policy != nil {
+		t.Errorf("expected no decisions without Options.Policy, got %+v", result.Policy)
+	}
+	_, err := parse(t, Options{Policy: "lax"}, compose)
+	expectError(t, err, ArgumentError, `Unsupported policy "lax", expected one of: strict, fleet-default, permissive`)
+}
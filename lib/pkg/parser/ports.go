@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// PortConflictCode is reported for host ports published by several services, which fail to start once the
+// port is taken
+const PortConflictCode = "port-conflict"
+
+// publishedPort is a range of host ports a service publishes, on a host IP or all if unset
+type publishedPort struct {
+	service  string
+	hostIP   string
+	protocol string
+	first    int
+	last     int
+}
+
+func (p publishedPort) String() string {
+	ports := strconv.Itoa(p.first)
+	if p.last != p.first {
+		ports += fmt.Sprintf("-%d", p.last)
+	}
+	if p.hostIP != "" {
+		ports = p.hostIP + ":" + ports
+	}
+	return ports + "/" + p.protocol
+}
+
+// Whether two published ports take a host port in common. Ports on all host IPs conflict with ports on any.
+func (p publishedPort) conflicts(other publishedPort) bool {
+	wildcard := func(ip string) bool { return ip == "" || ip == "0.0.0.0" || ip == "::" }
+	return p.protocol == other.protocol && p.first <= other.last && other.first <= p.last &&
+		(p.hostIP == other.hostIP || wildcard(p.hostIP) || wildcard(other.hostIP))
+}
+
+// The host ports a service publishes. Ports left for the engine to pick are left out, as are those of
+// services on the host network, which don't publish ports.
+func publishedPorts(name string, service types.ServiceConfig) []publishedPort {
+	if service.NetworkMode == "host" {
+		return nil
+	}
+	var ports []publishedPort
+	for _, port := range service.Ports {
+		start, end, isRange := strings.Cut(port.Published, "-")
+		first, err := strconv.Atoi(start)
+		if err != nil {
+			continue
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(end); err != nil {
+				continue
+			}
+		}
+		ports = append(ports, publishedPort{
+			service:  name,
+			hostIP:   port.HostIP,
+			protocol: cmp.Or(port.Protocol, "tcp"),
+			first:    first,
+			last:     last,
+		})
+	}
+	return ports
+}
+
+// The issues of host ports a service publishes which an earlier service, in name order, also publishes,
+// located at the ports of the later service. compose-go expands port ranges into a port each, so ports
+// aren't located by index, which may not be that of the compose file.
+func portConflictIssues(project *types.Project) []targetIssue {
+	var issues []targetIssue
+	var published []publishedPort
+	for _, name := range slices.Sorted(maps.Keys(project.Services)) {
+		ports := publishedPorts(name, project.Services[name])
+		path := "services." + name + ".ports"
+		for _, port := range ports {
+			for _, other := range published {
+				if port.conflicts(other) {
+					issues = append(issues, targetIssue{code: PortConflictCode, path: path, message: fmt.Sprintf(
+						"%s: host port %s conflicts with %s, published by service %q", path, port, other, other.service)})
+				}
+			}
+		}
+		published = append(published, ports...)
+	}
+	return issues
+}
@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPortConflicts(t *testing.T) {
+	project := mustParse(t, Options{}, "services:\n"+
+		"  a:\n    image: nginx\n    ports: [\"8080:80\", \"127.0.0.1:9000:9000\", \"5000:5000/udp\", \"80\"]\n"+
+		"  b:\n    image: nginx\n    ports: [\"8000-8090:8000-8090\", \"127.0.0.2:9000:9000\", \"5000:5000\", \"80\"]\n"+
+		"  c:\n    image: nginx\n    ports: [\"9000:9000\"]\n"+
+		"  host:\n    image: nginx\n    network_mode: host\n    ports: [\"8080:80\"]\n").Project
+	var messages []string
+	for _, issue := range portConflictIssues(project) {
+		messages = append(messages, issue.message)
+	}
+	// Ports on different host IPs and protocols don't conflict, ports on all host IPs conflict with any, and
+	// ports of services on the host network or left for the engine to pick are left out
+	expected := []string{
+		`services.b.ports: host port 8080/tcp conflicts with 8080/tcp, published by service "a"`,
+		`services.c.ports: host port 9000/tcp conflicts with 127.0.0.1:9000/tcp, published by service "a"`,
+		`services.c.ports: host port 9000/tcp conflicts with 127.0.0.2:9000/tcp, published by service "b"`,
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("expected %q, got %q", expected, messages)
+	}
+}
+
+func TestPublishedPortConflicts(t *testing.T) {
+	for _, tt := range []struct {
+		a, b     publishedPort
+		expected bool
+	}{
+		{a: publishedPort{protocol: "tcp", first: 80, last: 80}, b: publishedPort{protocol: "tcp", first: 80, last: 80}, expected: true},
+		{a: publishedPort{protocol: "tcp", first: 80, last: 80}, b: publishedPort{protocol: "udp", first: 80, last: 80}},
+		{a: publishedPort{protocol: "tcp", first: 80, last: 90}, b: publishedPort{protocol: "tcp", first: 90, last: 100}, expected: true},
+		{a: publishedPort{protocol: "tcp", first: 80, last: 89}, b: publishedPort{protocol: "tcp", first: 90, last: 100}},
+		{a: publishedPort{hostIP: "0.0.0.0", protocol: "tcp", first: 80, last: 80}, b: publishedPort{hostIP: "10.0.0.1", protocol: "tcp", first: 80, last: 80}, expected: true},
+		{a: publishedPort{hostIP: "::", protocol: "tcp", first: 80, last: 80}, b: publishedPort{hostIP: "::1", protocol: "tcp", first: 80, last: 80}, expected: true},
+		{a: publishedPort{hostIP: "10.0.0.2", protocol: "tcp", first: 80, last: 80}, b: publishedPort{hostIP: "10.0.0.1", protocol: "tcp", first: 80, last: 80}},
+	} {
+		if conflicts := tt.a.conflicts(tt.b); conflicts != tt.expected || tt.b.conflicts(tt.a) != tt.expected {
+			t.Errorf("expected %s and %s to conflict %t, got %t", tt.a, tt.b, tt.expected, conflicts)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// PrivateLabelCode is reported for labels in the io.balena.private namespace set in compose files,
+// which is reserved for the supervisor and trusted callers
+const PrivateLabelCode = "private-label"
+
+// Prefix of the label namespace reserved for balena
+const balenaPrivateLabelPrefix = "io.balena.private"
+
+// Whether a label is in the io.balena.private namespace
+func isPrivateLabel(name string) bool {
+	return name == balenaPrivateLabelPrefix || strings.HasPrefix(name, balenaPrivateLabelPrefix+".")
+}
+
+// Check no labels set in the project are in the io.balena.private namespace, returning a ValidationError
+// listing each. Labels of services, their builds, networks, volumes, secrets and configs are checked.
+func checkPrivateLabels(project *types.Project, composeFiles []string) *Error {
+	projectJSON, err := project.MarshalJSON()
+	if err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to marshal compose project: %v", err), Err: err}
+	}
+	var config map[string]any
+	json.Unmarshal(projectJSON, &config)
+
+	var issues []targetIssue
+	checkLabels := func(path string, labels map[string]any) {
+		for _, name := range sortedKeys(labels) {
+			if isPrivateLabel(name) {
+				issues = append(issues, targetIssue{
+					code:    PrivateLabelCode,
+					path:    path + "." + name,
+					message: fmt.Sprintf("%s: label %s can't use the %q namespace", path, name, balenaPrivateLabelPrefix),
+				})
+			}
+		}
+	}
+	services := object(config["services"])
+	for _, name := range sortedKeys(services) {
+		service := object(services[name])
+		checkLabels("services."+name+".labels", object(service["labels"]))
+		checkLabels("services."+name+".build.labels", object(object(service["build"])["labels"]))
+	}
+	for _, resourceType := range externalResources {
+		resources := object(config[resourceType])
+		for _, name := range sortedKeys(resources) {
+			checkLabels(resourceType+"."+name+".labels", object(object(resources[name])["labels"]))
+		}
+	}
+	labelsErr, _ := reportIssues("Project uses reserved labels", issues, composeFiles)
+	return labelsErr
+}
+
+// Add the io.balena.private labels of a trusted caller to every service, overriding any other value
+func injectPrivateLabels(project *types.Project, labels map[string]string) {
+	for name, service := range project.Services {
+		for label, value := range labels {
+			service.Labels = service.Labels.Add(label, value)
+		}
+		project.Services[name] = service
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsPrivateLabel(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"io.balena.private":             true,
+		"io.balena.private.app-id":      true,
+		"io.balena.privateer":           false,
+		"io.balena.features.balena-api": false,
+		"com.example.private":           false,
+	} {
+		if private := isPrivateLabel(name); private != expected {
+			t.Errorf("expected %s to be private %t, got %t", name, expected, private)
+		}
+	}
+}
+
+func TestPrivateLabels(t *testing.T) {
+	errs, _ := targetIssues(t, Options{}, "services:\n"+
+		"  web:\n    image: nginx\n    labels:\n      io.balena.private.app-id: \"1\"\n      io.balena.privateer: \"true\"\n"+
+		"  api:\n    build:\n      context: .\n      labels:\n        io.balena.private: \"true\"\n"+
+		"networks:\n  backend:\n    labels:\n      io.balena.private.network: \"true\"\n")
+	expected := []string{
+		"private-label services.api.build.labels.io.balena.private",
+		"private-label services.web.labels.io.balena.private.app-id",
+		"private-label networks.backend.labels.io.balena.private.network",
+	}
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %v, got %v", expected, errs)
+	}
+
+	// Trusted callers inject them, overriding values in the compose files
+	result := mustParse(t, Options{PrivateLabels: map[string]string{"io.balena.private.app-id": "42"}}, "services:\n  web:\n    image: nginx\n    labels:\n      version: \"1\"\n  db:\n    image: postgres\n")
+	for name, service := range result.Project.Services {
+		if service.Labels["io.balena.private.app-id"] != "42" {
+			t.Errorf("expected the private label to be added to %s, got %v", name, service.Labels)
+		}
+	}
+	if version := result.Project.Services["web"].Labels["version"]; version != "1" {
+		t.Errorf("expected the labels of the compose file to be kept, got %v", result.Project.Services["web"].Labels)
+	}
+	_, err := parse(t, Options{PrivateLabels: map[string]string{"io.balena.app-id": "42"}}, "services:\n  web:\n    image: nginx\n")
+	expectError(t, err, ArgumentError, `Private label io.balena.app-id must be in the "io.balena.private" namespace`)
+}
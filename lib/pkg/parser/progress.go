@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/template"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Progress phases, in the order they occur in a parse
+const (
+	// LoadingPhase starts a parse, reading the compose files
+	LoadingPhase = "loading"
+	// FetchingPhase downloads a remote compose file referenced by https:// URL
+	FetchingPhase = "fetching"
+	// IncludingPhase loads the compose files of an include entry
+	IncludingPhase = "including"
+	// ExtendingPhase loads a service extended from the same or another compose file
+	ExtendingPhase = "extending"
+	// InterpolatingPhase substitutes variables in the compose files
+	InterpolatingPhase = "interpolating"
+	// ValidatingPhase re-checks the compose files of a failed parse, with Options.AllErrors
+	ValidatingPhase = "validating"
+	// DonePhase ends a parse, whether successful or not
+	DonePhase = "done"
+)
+
+// ProgressEvent reports a parse moving to a new phase
+type ProgressEvent struct {
+	// ProjectName is the name of the project being parsed
+	ProjectName string `json:"projectName"`
+	// Phase is the phase the parse has moved to, e.g. LoadingPhase
+	Phase string `json:"phase"`
+	// Files are the compose files the phase applies to, if any
+	Files []string `json:"files,omitempty"`
+	// Service is the service being extended, for ExtendingPhase
+	Service string `json:"service,omitempty"`
+}
+
+// Create the progress reporter for a single parse, which stops reporting once the parse is done,
+// even if compose-go continues loading in the background after a timeout
+func (p *Parser) progressReporter() func(ProgressEvent) {
+	if p.options.Progress == nil {
+		return func(ProgressEvent) {}
+	}
+	var done atomic.Bool
+	return func(event ProgressEvent) {
+		if done.Load() {
+			return
+		}
+		if event.Phase == DonePhase && done.Swap(true) {
+			return
+		}
+		event.ProjectName = p.options.ProjectName
+		p.options.Progress(event)
+	}
+}
+
+// compose-go options reporting the progress of its loader. Interpolation is reported when compose-go
+// first substitutes a variable, as it doesn't otherwise signal moving between phases.
+func (p *Parser) progressOptions(report func(ProgressEvent)) []cli.ProjectOptionsFn {
+	if p.options.Progress == nil {
+		return nil
+	}
+	var interpolating sync.Once
+	return []cli.ProjectOptionsFn{
+		cli.WithLoadOptions(func(options *loader.Options) {
+			options.Listeners = append(options.Listeners, func(event string, metadata map[string]any) {
+				switch event {
+				case "include":
+					paths, _ := metadata["path"].(types.StringList)
+					report(ProgressEvent{Phase: IncludingPhase, Files: paths})
+				case "extends":
+					progressEvent := ProgressEvent{Phase: ExtendingPhase}
+					progressEvent.Service, _ = metadata["service"].(string)
+					if file, ok := metadata["file"].(string); ok {
+						progressEvent.Files = []string{file}
+					}
+					report(progressEvent)
+				}
+			})
+			if options.Interpolate != nil {
+				substitute := options.Interpolate.Substitute
+				options.Interpolate.Substitute = func(value string, mapping template.Mapping) (string, error) {
+					interpolating.Do(func() {
+						report(ProgressEvent{Phase: InterpolatingPhase})
+					})
+					return substitute(value, mapping)
+				}
+			}
+		}),
+	}
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "include:\n  - db.yml\nservices:\n  web:\n    extends:\n      file: base.yml\n      service: base\n    image: nginx:${TAG:-latest}\n",
+		"db.yml":      "services:\n  db:\n    image: postgres\n",
+		"base.yml":    "services:\n  base:\n    command: [base]\n",
+		"invalid.yml": "services:\n  web:\n    ports: true\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+
+	var events []ProgressEvent
+	p := New(Options{ProjectName: "test", Progress: func(event ProgressEvent) { events = append(events, event) }})
+	if _, err := p.Parse(context.Background(), []string{composeFile}); err != nil {
+		t.Fatal(err)
+	}
+	var phases []string
+	for _, event := range events {
+		if event.ProjectName != "test" {
+			t.Errorf("expected events of the test project, got %+v", event)
+		}
+		phases = append(phases, event.Phase)
+		switch event.Phase {
+		case LoadingPhase:
+			if !reflect.DeepEqual(event.Files, []string{composeFile}) {
+				t.Errorf("expected the compose files to be loaded, got %+v", event)
+			}
+		case IncludingPhase:
+			if len(event.Files) != 1 || filepath.Base(event.Files[0]) != "db.yml" {
+				t.Errorf("expected db.yml to be included, got %+v", event)
+			}
+		case ExtendingPhase:
+			if event.Service != "base" || len(event.Files) != 1 || filepath.Base(event.Files[0]) != "base.yml" {
+				t.Errorf("expected base to be extended from base.yml, got %+v", event)
+			}
+		}
+	}
+	expected := []string{LoadingPhase, IncludingPhase, ExtendingPhase, InterpolatingPhase, DonePhase}
+	for _, phase := range expected {
+		if !slices.Contains(phases, phase) {
+			t.Errorf("expected a %s event, got %v", phase, phases)
+		}
+	}
+	if phases[0] != LoadingPhase || phases[len(phases)-1] != DonePhase {
+		t.Errorf("expected the parse to start loading and end done, got %v", phases)
+	}
+
+	events = nil
+	p = New(Options{ProjectName: "test", AllErrors: true, Progress: func(event ProgressEvent) { events = append(events, event) }})
+	if _, err := p.Parse(context.Background(), []string{filepath.Join(dir, "invalid.yml")}); err == nil {
+		t.Fatal("expected the parse to fail")
+	}
+	phases = nil
+	for _, event := range events {
+		phases = append(phases, event.Phase)
+	}
+	if len(phases) < 3 || phases[len(phases)-2] != ValidatingPhase || phases[len(phases)-1] != DonePhase {
+		t.Errorf("expected a failed parse to validate every error and end done, got %v", phases)
+	}
+}
+
+func TestProgressReporter(t *testing.T) {
+	if report := New(Options{}).progressReporter(); report == nil {
+		t.Fatal("expected a reporter without Options.Progress")
+	}
+
+	var events []ProgressEvent
+	report := New(Options{ProjectName: "test", Progress: func(event ProgressEvent) { events = append(events, event) }}).progressReporter()
+	report(ProgressEvent{Phase: LoadingPhase})
+	report(ProgressEvent{Phase: DonePhase})
+	// compose-go may keep loading after a timeout, but the parse is already done
+	report(ProgressEvent{Phase: InterpolatingPhase})
+	report(ProgressEvent{Phase: DonePhase})
+	expected := []ProgressEvent{{ProjectName: "test", Phase: LoadingPhase}, {ProjectName: "test", Phase: DonePhase}}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %+v, got %+v", expected, events)
+	}
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// The fields of each object of a project which the messages of lib/proto/project.proto have, by the path
+// of the object, with * for any name or index. Objects without an entry, e.g. healthchecks, are represented
+// in full.
+var protoFields = map[string][]string{
+	"": {"name", "services", "networks", "volumes", "configs", "secrets"},
+	"services.*": {
+		"annotations", "attach", "blkio_config", "build", "cap_add", "cap_drop", "cgroup", "cgroup_parent", "command",
+		"configs", "container_name", "cpu_count", "cpu_percent", "cpu_period", "cpu_quota", "cpu_rt_period",
+		"cpu_rt_runtime", "cpu_shares", "cpus", "cpuset", "credential_spec", "depends_on", "deploy",
+		"device_cgroup_rules", "devices", "dns", "dns_opt", "dns_search", "domainname", "entrypoint", "env_file",
+		"environment", "expose", "external_links", "extra_hosts", "gpus", "group_add", "healthcheck", "hostname",
+		"image", "init", "ipc", "isolation", "label_file", "labels", "links", "logging", "mac_address",
+		"mem_limit", "mem_reservation", "mem_swappiness", "memswap_limit", "network_mode", "networks",
+		"oom_kill_disable", "oom_score_adj", "pid", "pids_limit", "platform", "ports", "post_start", "pre_stop",
+		"privileged", "profiles", "pull_policy", "read_only", "restart", "runtime", "scale", "secrets",
+		"security_opt", "shm_size", "stdin_open", "stop_grace_period", "stop_signal", "storage_opt", "sysctls",
+		"tmpfs", "tty", "ulimits", "use_api_socket", "user", "userns_mode", "uts", "volumes", "volumes_from",
+		"working_dir",
+	},
+	"services.*.build": {
+		"additional_contexts", "args", "cache_from", "cache_to", "context", "dockerfile", "dockerfile_inline",
+		"entitlements", "extra_hosts", "isolation", "labels", "network", "no_cache", "platforms", "privileged",
+		"provenance", "pull", "sbom", "secrets", "shm_size", "ssh", "tags", "target", "ulimits",
+	},
+	"services.*.deploy":                        {"labels", "mode", "replicas", "resources"},
+	"services.*.deploy.resources":              {"limits", "reservations"},
+	"services.*.deploy.resources.limits":       {"cpus", "devices", "memory", "pids"},
+	"services.*.deploy.resources.reservations": {"cpus", "devices", "memory", "pids"},
+	"services.*.volumes.*":                     {"bind", "consistency", "image", "read_only", "source", "target", "tmpfs", "type", "volume"},
+	"services.*.volumes.*.bind":                {"create_host_path", "propagation", "recursive", "selinux"},
+	"services.*.volumes.*.volume":              {"labels", "nocopy", "subpath"},
+	"services.*.networks.*": {
+		"aliases", "driver_opts", "gw_priority", "interface_name", "ipv4_address", "ipv6_address", "link_local_ips",
+		"mac_address", "priority",
+	},
+	"networks.*": {"attachable", "driver", "driver_opts", "enable_ipv4", "enable_ipv6", "external", "internal", "ipam", "labels", "name"},
+	"volumes.*":  {"driver", "driver_opts", "external", "labels", "name"},
+	"configs.*":  {"content", "driver", "driver_opts", "environment", "external", "file", "labels", "name", "template_driver"},
+	"secrets.*":  {"content", "driver", "driver_opts", "environment", "external", "file", "labels", "name", "template_driver"},
+}
+
+// CheckProto checks every field set in a project has a field in the messages of lib/proto/project.proto,
+// so the project can be output as protobuf without losing any, returning a ValidationError listing each
+// which doesn't, e.g. develop or deploy.placement, located in the compose files. x- extensions and empty
+// objects compose-go normalizes in are always represented.
+func CheckProto(project *types.Project, composeFiles []string) error {
+	projectJSON, err := project.MarshalJSON()
+	if err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to marshal compose project: %v", err), Err: err}
+	}
+	var config any
+	json.Unmarshal(projectJSON, &config)
+
+	var issues []targetIssue
+	var check func(path, pattern string, value any)
+	check = func(path, pattern string, value any) {
+		join := func(path, key string) string {
+			if path == "" {
+				return key
+			}
+			return path + "." + key
+		}
+		if values := array(value); values != nil {
+			for i, entry := range values {
+				check(fmt.Sprintf("%s.%d", path, i), join(pattern, "*"), entry)
+			}
+			return
+		}
+		m := object(value)
+		fields, ok := protoFields[pattern]
+		for _, key := range sortedKeys(m) {
+			child := join(path, key)
+			if _, named := protoFields[join(pattern, "*")]; named {
+				check(child, join(pattern, "*"), m[key])
+				continue
+			}
+			if !ok || strings.HasPrefix(key, "x-") {
+				continue
+			}
+			if !slices.Contains(fields, key) {
+				if !isEmptyMapping(m[key]) {
+					issues = append(issues, targetIssue{code: UnsupportedFieldCode, path: child, message: fmt.Sprintf("%s has no field in the protobuf schema", child)})
+				}
+				continue
+			}
+			check(child, join(pattern, key), m[key])
+		}
+	}
+	check("", "", config)
+	if protoErr, _ := reportIssues("Project can't be output as protobuf", issues, composeFiles); protoErr != nil {
+		return protoErr
+	}
+	return nil
+}
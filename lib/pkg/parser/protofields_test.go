@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckProto(t *testing.T) {
+	tests := []struct {
+		name     string
+		compose  string
+		expected []string
+	}{
+		{
+			name: "supported",
+			compose: "x-defaults: {restart: always}\n" +
+				"services:\n  web:\n    image: nginx\n    x-owner: web-team\n    command: []\n" +
+				"    healthcheck:\n      test: [CMD, curl, -f, http://localhost]\n      interval: 30s\n" +
+				"    deploy:\n      resources:\n        reservations:\n          devices: [{capabilities: [gpu]}]\n" +
+				"    volumes:\n      - type: bind\n        source: /data\n        target: /data\n        bind: {propagation: rshared}\n" +
+				"    networks:\n      backend: {aliases: [api]}\n" +
+				"networks:\n  backend:\n    ipam: {config: [{subnet: 10.0.0.0/24}]}\n" +
+				"volumes:\n  data: {}\n",
+		},
+		{
+			name:     "develop",
+			compose:  "services:\n  web:\n    image: nginx\n    develop:\n      watch: [{action: sync, path: ./src, target: /app}]\n",
+			expected: []string{"services.web.develop"},
+		},
+		{
+			name:     "nested fields",
+			compose:  "services:\n  web:\n    image: nginx\n    deploy:\n      placement: {constraints: [node.role==manager]}\n      resources:\n        limits: {cpus: \"0.5\"}\n    volumes:\n      - type: volume\n        source: data\n        target: /data\n        volume: {nocopy: true}\nvolumes:\n  data: {}\n",
+			expected: []string{"services.web.deploy.placement"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := mustParse(t, Options{}, tt.compose)
+			var paths []string
+			if err := CheckProto(result.Project, nil); err != nil {
+				for _, e := range expectError(t, err, ValidationError, "Project can't be output as protobuf: ").Errors {
+					if e.Code != UnsupportedFieldCode {
+						t.Errorf("expected %s, got %s", UnsupportedFieldCode, e.Code)
+					}
+					paths = append(paths, e.Location.Path)
+				}
+			}
+			if strings.Join(paths, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("expected %v, got %v", tt.expected, paths)
+			}
+		})
+	}
+}
+
+func TestCheckProtoLocations(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"compose.yml": "services:\n  web:\n    image: nginx\n    develop:\n      watch: [{action: rebuild, path: .}]\n"})
+	composeFile := filepath.Join(dir, "compose.yml")
+	result, err := New(Options{ProjectName: "test"}).Parse(context.Background(), []string{composeFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parserErr := expectError(t, CheckProto(result.Project, []string{composeFile}), ValidationError, "services.web.develop has no field in the protobuf schema")
+	if parserErr.Location == nil || parserErr.Location.File != composeFile || parserErr.Location.Line != 4 {
+		t.Errorf("expected the field to be located in the compose file, got %+v", parserErr.Location)
+	}
+}
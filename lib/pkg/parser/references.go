@@ -0,0 +1,163 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Codes of the findings of the unused-resources and undefined-references lint rules
+const (
+	// UnusedResourceCode is reported for top-level volumes, networks, configs and secrets no service uses
+	UnusedResourceCode = "unused-resource"
+	// UndefinedResourceCode is reported for volumes, networks, configs and secrets services use which
+	// aren't declared
+	UndefinedResourceCode = "undefined-resource"
+)
+
+// resourceReference is the use of a top-level resource by a service, located by the path of the field
+type resourceReference struct {
+	kind string
+	name string
+	path string
+}
+
+// The top-level volumes, networks, configs and secrets each service uses, including services disabled by
+// their profiles, in service name order
+func resourceReferences(project *types.Project) []resourceReference {
+	services := maps.Clone(project.Services)
+	maps.Copy(services, project.DisabledServices)
+	var references []resourceReference
+	for _, name := range slices.Sorted(maps.Keys(services)) {
+		service := services[name]
+		path := "services." + name
+		for i, volume := range service.Volumes {
+			if volume.Type == types.VolumeTypeVolume && volume.Source != "" {
+				references = append(references, resourceReference{"volumes", volume.Source, fmt.Sprintf("%s.volumes.%d", path, i)})
+			}
+		}
+		for _, network := range slices.Sorted(maps.Keys(service.Networks)) {
+			references = append(references, resourceReference{"networks", network, path + ".networks." + network})
+		}
+		for i, config := range service.Configs {
+			references = append(references, resourceReference{"configs", config.Source, fmt.Sprintf("%s.configs.%d", path, i)})
+		}
+		for i, secret := range service.Secrets {
+			references = append(references, resourceReference{"secrets", secret.Source, fmt.Sprintf("%s.secrets.%d", path, i)})
+		}
+		if service.Build != nil {
+			for i, secret := range service.Build.Secrets {
+				references = append(references, resourceReference{"secrets", secret.Source, fmt.Sprintf("%s.build.secrets.%d", path, i)})
+			}
+		}
+	}
+	return references
+}
+
+// The names of the top-level resources of each kind a project declares
+func declaredResources(project *types.Project) map[string][]string {
+	return map[string][]string{
+		"volumes":  slices.Collect(maps.Keys(project.Volumes)),
+		"networks": slices.Collect(maps.Keys(project.Networks)),
+		"configs":  slices.Collect(maps.Keys(project.Configs)),
+		"secrets":  slices.Collect(maps.Keys(project.Secrets)),
+	}
+}
+
+// The issues of top-level resources no service uses, by kind then name. The default network is left out,
+// as it's declared implicitly.
+func unusedResourceIssues(project *types.Project) []targetIssue {
+	used := map[string]bool{}
+	for _, reference := range resourceReferences(project) {
+		used[reference.kind+"."+reference.name] = true
+	}
+	var issues []targetIssue
+	declared := declaredResources(project)
+	for _, kind := range externalResources {
+		for _, name := range slices.Sorted(slices.Values(declared[kind])) {
+			path := kind + "." + name
+			if used[path] || path == "networks.default" {
+				continue
+			}
+			message := fmt.Sprintf("%s isn't used by any service", path)
+			if similar := similarName(name, undefinedNames(project, kind)); similar != "" {
+				message += fmt.Sprintf(", though services use %s", similar)
+			}
+			issues = append(issues, targetIssue{code: UnusedResourceCode, path: path, message: message})
+		}
+	}
+	return issues
+}
+
+// The issues of resources services use which the project doesn't declare, suggesting a declared resource
+// of a similar name as the likely intent
+func undefinedReferenceIssues(project *types.Project) []targetIssue {
+	declared := declaredResources(project)
+	var issues []targetIssue
+	for _, reference := range resourceReferences(project) {
+		if slices.Contains(declared[reference.kind], reference.name) || reference.kind == "networks" && reference.name == "default" {
+			continue
+		}
+		message := fmt.Sprintf("%s: %s isn't declared in the top-level %s", reference.path, reference.name, reference.kind)
+		if similar := similarName(reference.name, declared[reference.kind]); similar != "" {
+			message += fmt.Sprintf(", did you mean %s?", similar)
+		}
+		issues = append(issues, targetIssue{code: UndefinedResourceCode, path: reference.path, message: message})
+	}
+	return issues
+}
+
+// The names of the resources of a kind services use which the project doesn't declare
+func undefinedNames(project *types.Project, kind string) []string {
+	declared := declaredResources(project)[kind]
+	var names []string
+	for _, reference := range resourceReferences(project) {
+		if reference.kind == kind && !slices.Contains(declared, reference.name) {
+			names = append(names, reference.name)
+		}
+	}
+	return names
+}
+
+// The first of names, in sorted order, which is likely a typo of name, as it only differs by case and
+// separators, or by at most two edits. Names of one or two characters are only similar to each other up
+// to case and separators.
+func similarName(name string, names []string) string {
+	normalize := strings.NewReplacer("-", "", "_", "", ".", "").Replace
+	for _, candidate := range slices.Sorted(slices.Values(names)) {
+		if candidate == name {
+			continue
+		}
+		if strings.EqualFold(normalize(candidate), normalize(name)) {
+			return candidate
+		}
+		if len(name) > 2 && editDistance(strings.ToLower(candidate), strings.ToLower(name)) <= 2 {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// The Levenshtein distance between two strings, in bytes
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := range len(a) {
+		current := make([]int, len(b)+1)
+		current[0] = i + 1
+		for j := range len(b) {
+			substitution := previous[j]
+			if a[i] != b[j] {
+				substitution++
+			}
+			current[j+1] = min(previous[j+1]+1, current[j]+1, substitution)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
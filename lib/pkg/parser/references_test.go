@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResourceReferences(t *testing.T) {
+	findings := lint(t, LintOptions{}, "services:\n"+
+		"  web:\n    image: nginx\n    volumes: [data-vol:/data, ./config:/config]\n    networks: [default, front]\n    secrets: [token]\n"+
+		"  db:\n    image: postgres\n    profiles: [debug]\n    volumes: [db:/var/lib/postgresql]\n"+
+		"volumes:\n  data_vol: {}\n  db: {}\n  logs: {}\n"+
+		"networks:\n  front: {}\n  back: {}\n"+
+		"secrets:\n  api_token:\n    file: ./token\n")
+	var messages []string
+	for _, finding := range findings {
+		if finding.Rule == "undefined-references" || finding.Rule == "unused-resources" {
+			messages = append(messages, finding.Code+": "+finding.Message)
+		}
+	}
+	// Services disabled by their profiles use resources too, and the default network is declared implicitly
+	expected := []string{
+		"undefined-resource: services.web.volumes.0: data-vol isn't declared in the top-level volumes, did you mean data_vol?",
+		"undefined-resource: services.web.secrets.0: token isn't declared in the top-level secrets",
+		"unused-resource: networks.back isn't used by any service",
+		"unused-resource: volumes.data_vol isn't used by any service, though services use data-vol",
+		"unused-resource: volumes.logs isn't used by any service",
+		"unused-resource: secrets.api_token isn't used by any service",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("expected %q, got %q", expected, messages)
+	}
+}
+
+func TestSimilarName(t *testing.T) {
+	names := []string{"data_vol", "db", "frontend", "x"}
+	for name, expected := range map[string]string{
+		"data-vol":  "data_vol",
+		"DataVol":   "data_vol",
+		"frontedn":  "frontend",
+		"front":     "",
+		"DB":        "db",
+		"da":        "",
+		"y":         "",
+		"data_vol":  "",
+		"databases": "",
+	} {
+		if similar := similarName(name, names); similar != expected {
+			t.Errorf("expected %q to be similar to %q, got %q", name, expected, similar)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "", b: "abc", expected: 3},
+		{a: "kitten", b: "sitting", expected: 3},
+		{a: "frontend", b: "frontedn", expected: 2},
+		{a: "same", b: "same", expected: 0},
+	} {
+		if distance := editDistance(tt.a, tt.b); distance != tt.expected {
+			t.Errorf("expected the distance between %q and %q to be %d, got %d", tt.a, tt.b, tt.expected, distance)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Release is the composition document stored with a balenaCloud release, with the contract of the
+// release if it has one
+type Release struct {
+	Composition map[string]any `json:"composition"`
+	Contract    *Contract      `json:"contract,omitempty"`
+}
+
+// NewRelease derives the release document of a project parsed with BalenaTarget and BalenaNormalize, as
+// the builder does. Build contexts are made relative to the project directory with forward slashes, as
+// the builder resolves them in the uploaded project, and null commands and entrypoints are removed.
+func NewRelease(result *Result) (*Release, error) {
+	projectJSON, err := result.Project.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var composition map[string]any
+	if err := json.Unmarshal(projectJSON, &composition); err != nil {
+		return nil, err
+	}
+	delete(composition, "name")
+
+	services := object(composition["services"])
+	for _, name := range sortedKeys(services) {
+		service := object(services[name])
+		for _, field := range []string{"command", "entrypoint"} {
+			if value, ok := service[field]; ok && value == nil {
+				delete(service, field)
+			}
+		}
+		build := object(service["build"])
+		if build == nil {
+			continue
+		}
+		if context := text(build["context"]); isLocalContext(context) {
+			if build["context"], err = projectRelativePath(context, result.Project.WorkingDir); err != nil {
+				return nil, fmt.Errorf("services.%s.build.context: %w", name, err)
+			}
+		}
+	}
+	return &Release{Composition: composition, Contract: result.Contract}, nil
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewRelease(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml":    "name: app\nservices:\n  web:\n    build: ./web\n    command: null\n  api:\n    build:\n      context: https://github.com/balena-io/api.git#main\n  db:\n    image: postgres\n    entrypoint: [docker-entrypoint.sh]\n",
+		"web/Dockerfile": "FROM nginx\n",
+		"balena.yml":     "type: sw.application\nslug: balenalabs/browser\n",
+	})
+	p := New(Options{ProjectName: "test", BalenaNormalize: true, Target: BalenaTarget, Contract: filepath.Join(dir, "balena.yml")})
+	result, err := p.Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := NewRelease(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := release.Composition["name"]; ok {
+		t.Error("expected the release not to be named")
+	}
+	services := object(release.Composition["services"])
+	web, api, db := object(services["web"]), object(services["api"]), object(services["db"])
+	// Local contexts are relative to the project directory, even when the project was parsed with absolute paths
+	if context := object(web["build"])["context"]; context != "web" {
+		t.Errorf("expected the project relative context web, got %v", context)
+	}
+	if context := object(api["build"])["context"]; context != "https://github.com/balena-io/api.git#main" {
+		t.Errorf("expected the remote context to be unchanged, got %v", context)
+	}
+	if _, ok := web["command"]; ok {
+		t.Errorf("expected the null command to be removed, got %v", web["command"])
+	}
+	if _, ok := db["entrypoint"]; !ok {
+		t.Error("expected the entrypoint to be kept")
+	}
+	if release.Contract == nil || release.Contract.Slug != "balenalabs/browser" {
+		t.Errorf("expected the contract of the project, got %+v", release.Contract)
+	}
+}
+
+func TestNewReleaseErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app/compose.yml": "services:\n  web:\n    build: ../web\n", "web/Dockerfile": "FROM nginx\n"})
+	result, err := New(Options{ProjectName: "test"}).Parse(context.Background(), []string{filepath.Join(dir, "app", "compose.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The builder only receives the project directory
+	if _, err := NewRelease(result); err == nil || err.Error() != "services.web.build.context: ../web is outside the project directory" {
+		t.Errorf("expected the context outside the project directory to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Max size of a compose file fetched over HTTPS
+const maxRemoteFileSize = 10 * 1024 * 1024
+
+// httpsLoader is a compose-go resource loader which fetches compose files referenced by
+// https:// URL, either directly with -f or via include and extends, into a local directory.
+type httpsLoader struct {
+	client *http.Client
+	dir    string
+	// progress reports fetches as progress events
+	progress func(ProgressEvent)
+}
+
+func (l *httpsLoader) Accept(p string) bool {
+	return strings.HasPrefix(p, "https://")
+}
+
+func (l *httpsLoader) Load(ctx context.Context, p string) (string, error) {
+	u, err := url.Parse(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid compose file URL %q: %w", p, err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	l.progress(ProgressEvent{Phase: FetchingPhase, Files: []string{p}})
+	response, err := l.client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", p, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch %s: %s", p, response.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, maxRemoteFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", p, err)
+	}
+	if len(content) > maxRemoteFileSize {
+		return "", fmt.Errorf("failed to fetch %s: file exceeds %d bytes", p, maxRemoteFileSize)
+	}
+
+	// Each file gets its own directory so that files with the same name from different URLs don't collide,
+	// while keeping the file name so error messages remain recognizable
+	dir, err := os.MkdirTemp(l.dir, "remote-")
+	if err != nil {
+		return "", err
+	}
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		name = "compose.yml"
+	}
+	local := filepath.Join(dir, name)
+	if err := os.WriteFile(local, content, 0o600); err != nil {
+		return "", err
+	}
+	return local, nil
+}
+
+func (l *httpsLoader) Dir(p string) string {
+	u, err := url.Parse(p)
+	if err != nil {
+		return p
+	}
+	u.Path = path.Dir(u.Path)
+	return u.String()
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Serve compose files by path over HTTPS
+func serveComposeFiles(t *testing.T, files map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	// Clients which don't trust the certificate fail their handshakes
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestParseRemote(t *testing.T) {
+	files := map[string]string{
+		"/base.yml":       "services:\n  base:\n    image: nginx\n    command: [base]\n",
+		"/db/compose.yml": "services:\n  db:\n    image: postgres\n",
+		"/override.yml":   "services:\n  web:\n    command: [override]\n",
+	}
+	server := serveComposeFiles(t, files)
+	files["/app/compose.yml"] = "include:\n  - " + server.URL + "/db/compose.yml\nservices:\n  web:\n    extends:\n      file: " + server.URL + "/base.yml\n      service: base\n"
+	local := writeFiles(t, map[string]string{"compose.yml": "services:\n  web:\n    environment:\n      LOCAL: \"true\"\n"})
+
+	var events []ProgressEvent
+	p := New(Options{ProjectName: "test", HTTPSClient: server.Client(), Progress: func(event ProgressEvent) { events = append(events, event) }})
+	result, err := p.Parse(context.Background(), []string{server.URL + "/app/compose.yml", local + "/compose.yml", server.URL + "/override.yml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := result.Project.Services["web"]
+	if web.Image != "nginx" || strings.Join(web.Command, " ") != "override" || web.Environment["LOCAL"] == nil {
+		t.Errorf("expected the extended, local and overriding files to be merged, got %+v", web)
+	}
+	if db, ok := result.Project.Services["db"]; !ok || db.Image != "postgres" {
+		t.Errorf("expected the included file, got %+v", result.Project.Services)
+	}
+	fetched := 0
+	for _, event := range events {
+		if event.Phase == FetchingPhase {
+			fetched++
+		}
+	}
+	if fetched != 4 {
+		t.Errorf("expected a fetching event for each of the 4 files, got %+v", events)
+	}
+}
+
+func TestParseRemoteErrors(t *testing.T) {
+	server := serveComposeFiles(t, map[string]string{"/compose.yml": "services:\n  web:\n    image: nginx\n"})
+	tests := []struct {
+		name    string
+		client  *http.Client
+		url     string
+		message string
+	}{
+		{name: "not found", client: server.Client(), url: server.URL + "/missing.yml", message: "404 Not Found"},
+		{name: "untrusted certificate", client: &http.Client{}, url: server.URL + "/compose.yml", message: "certificate"},
+		{name: "unsupported", url: server.URL + "/compose.yml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Options{ProjectName: "test", HTTPSClient: tt.client}).Parse(context.Background(), []string{tt.url})
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("expected an error containing %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"path/filepath"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/dotenv"
+)
+
+// Sources of the value of a substituted variable, in order of precedence
+const (
+	// OverrideSource is Options.Environment, e.g. --env and --env-json
+	OverrideSource = "override"
+	// BalenaDeviceSource and BalenaFleetSource are the device and fleet variables of Options.BalenaVariables
+	BalenaDeviceSource = "balena-device"
+	BalenaFleetSource  = "balena-fleet"
+	// OSEnvSource is the environment of the process
+	OSEnvSource = "os-env"
+	// EnvFileSource is an env file of Options.EnvFiles
+	EnvFileSource = "env-file"
+	// DefaultSource is the default of an unset variable, e.g. ${VAR:-default}
+	DefaultSource = "default"
+	// UnsetSource is an unset variable without a default, which is substituted with a blank string
+	UnsetSource = "unset"
+)
+
+// VariableResolution records where the value of a substituted variable came from
+type VariableResolution struct {
+	// Name is the name of the variable
+	Name string `json:"name"`
+	// Source is where the value came from, e.g. OSEnvSource
+	Source string `json:"source"`
+	// File is the absolute path of the env file the value was read from, for EnvFileSource
+	File string `json:"file,omitempty"`
+	// Value is the value substituted for the variable, or MaskedValue if masked with Options.MaskEnv
+	Value string `json:"value"`
+}
+
+// Find the source of each substituted variable. A variable referenced with several defaults while
+// unset is listed once per default.
+func (p *Parser) resolveEnvironment(substitutions []substitution, options *cli.ProjectOptions) []VariableResolution {
+	// Env files are read again one at a time, attributing each variable to the last file which sets it
+	envFiles := map[string]string{}
+	for _, envFile := range options.EnvFiles {
+		variables, err := dotenv.GetEnvFromFile(options.Environment, []string{envFile})
+		if err != nil {
+			continue
+		}
+		if abs, err := filepath.Abs(envFile); err == nil {
+			envFile = abs
+		}
+		for name := range variables {
+			envFiles[name] = envFile
+		}
+	}
+
+	_, balenaSources := p.options.BalenaVariables.environment()
+	resolutions := []VariableResolution{}
+	for _, s := range substitutions {
+		resolution := VariableResolution{Name: s.Name, Value: s.value}
+		_, override := p.options.Environment[s.Name]
+		balenaSource, balena := balenaSources[s.Name]
+		switch {
+		case !s.set && s.DefaultValue != "":
+			resolution.Source, resolution.Value = DefaultSource, s.DefaultValue
+		case !s.set:
+			resolution.Source = UnsetSource
+		case override:
+			resolution.Source = OverrideSource
+		case balena:
+			resolution.Source = balenaSource
+		case p.osEnvAllowed(s.Name):
+			resolution.Source = OSEnvSource
+		default:
+			resolution.Source, resolution.File = EnvFileSource, envFiles[s.Name]
+		}
+		if matchesAny(p.options.MaskEnv, s.Name) {
+			resolution.Value = MaskedValue
+		}
+		resolutions = append(resolutions, resolution)
+	}
+	// Substitutions are sorted by name, so the same resolution from several references is adjacent
+	return slices.Compact(resolutions)
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEnvResolution(t *testing.T) {
+	t.Setenv("BALENA_TEST_OS", "os")
+	t.Setenv("BALENA_TEST_OVERRIDDEN", "os")
+	dir := writeFiles(t, map[string]string{
+		"build.env":    "FROM_FILE=file\nFROM_BOTH=first\n",
+		"override.env": "FROM_BOTH=second\n",
+	})
+	options := Options{
+		EnvResolution: true,
+		EnvFiles:      []string{filepath.Join(dir, "build.env"), filepath.Join(dir, "override.env")},
+		Environment:   map[string]string{"BALENA_TEST_OVERRIDDEN": "override", "API_TOKEN": "secret"},
+		MaskEnv:       []string{"*_TOKEN"},
+	}
+	result := mustParse(t, options, "services:\n"+
+		"  web:\n"+
+		"    image: nginx:${TAG:-latest}\n"+
+		"    command: [\"${BALENA_TEST_OS}\", \"${BALENA_TEST_OVERRIDDEN}\", \"${FROM_FILE}\", \"${FROM_BOTH}\", \"${UNSET}\", \"${API_TOKEN}\"]\n"+
+		"    environment:\n      TAG: ${TAG:-latest}\n      OTHER: ${TAG-other}\n")
+	// Sorted by name, with each variable listed once per default while unset
+	expected := []VariableResolution{
+		{Name: "API_TOKEN", Source: OverrideSource, Value: MaskedValue},
+		{Name: "BALENA_TEST_OS", Source: OSEnvSource, Value: "os"},
+		{Name: "BALENA_TEST_OVERRIDDEN", Source: OverrideSource, Value: "override"},
+		{Name: "FROM_BOTH", Source: EnvFileSource, File: filepath.Join(dir, "override.env"), Value: "second"},
+		{Name: "FROM_FILE", Source: EnvFileSource, File: filepath.Join(dir, "build.env"), Value: "file"},
+		{Name: "TAG", Source: DefaultSource, Value: "latest"},
+		{Name: "TAG", Source: DefaultSource, Value: "other"},
+		{Name: "UNSET", Source: UnsetSource},
+	}
+	if !reflect.DeepEqual(result.EnvResolution, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result.EnvResolution)
+	}
+
+	if result := mustParse(t, Options{}, "services:\n  web:\n    image: nginx:${TAG:-latest}\n"); result.EnvResolution != nil {
+		t.Errorf("expected no resolution without Options.EnvResolution, got %+v", result.EnvResolution)
+	}
+	if result := mustParse(t, Options{EnvResolution: true}, "services:\n  web:\n    image: nginx\n"); result.EnvResolution == nil || len(result.EnvResolution) != 0 {
+		t.Errorf("expected an empty resolution without variables, got %#v", result.EnvResolution)
+	}
+}
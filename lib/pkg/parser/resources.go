@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Resource is a volume, network, secret or config declared by a project
+type Resource struct {
+	// Name is the key of the resource in the compose files
+	Name string `json:"name"`
+	// ResourceName is the name of the resource on the engine, e.g. prefixed by the project name
+	ResourceName string `json:"resourceName"`
+	// Driver is the driver of the resource, if set
+	Driver string `json:"driver,omitempty"`
+	// External is whether the resource must already exist, rather than being created for the project
+	External bool `json:"external"`
+	// File is the path of the content of a secret or config, if read from a file
+	File string `json:"file,omitempty"`
+	// Environment is the variable holding the content of a secret or config, if read from the environment
+	Environment string `json:"environment,omitempty"`
+}
+
+// Resources are the resources declared by a project, each in name order
+type Resources struct {
+	Volumes  []Resource `json:"volumes"`
+	Networks []Resource `json:"networks"`
+	Secrets  []Resource `json:"secrets"`
+	Configs  []Resource `json:"configs"`
+}
+
+// ListResources lists the volumes, networks, secrets and configs declared by a project
+func ListResources(project *types.Project) Resources {
+	resources := Resources{Volumes: []Resource{}, Networks: []Resource{}, Secrets: []Resource{}, Configs: []Resource{}}
+	for _, name := range slices.Sorted(maps.Keys(project.Volumes)) {
+		volume := project.Volumes[name]
+		resources.Volumes = append(resources.Volumes, Resource{Name: name, ResourceName: volume.Name, Driver: volume.Driver, External: bool(volume.External)})
+	}
+	for _, name := range slices.Sorted(maps.Keys(project.Networks)) {
+		network := project.Networks[name]
+		resources.Networks = append(resources.Networks, Resource{Name: name, ResourceName: network.Name, Driver: network.Driver, External: bool(network.External)})
+	}
+	fileResource := func(name string, config types.FileObjectConfig) Resource {
+		return Resource{
+			Name:         name,
+			ResourceName: config.Name,
+			Driver:       config.Driver,
+			External:     bool(config.External),
+			File:         config.File,
+			Environment:  config.Environment,
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(project.Secrets)) {
+		resources.Secrets = append(resources.Secrets, fileResource(name, types.FileObjectConfig(project.Secrets[name])))
+	}
+	for _, name := range slices.Sorted(maps.Keys(project.Configs)) {
+		resources.Configs = append(resources.Configs, fileResource(name, types.FileObjectConfig(project.Configs[name])))
+	}
+	return resources
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListResources(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "services:\n  web:\n    image: nginx\n" +
+			"volumes:\n  data: {}\n  cache:\n    driver: local\n  shared:\n    external: true\n    name: fleet-shared\n" +
+			"networks:\n  backend:\n    driver: bridge\n  host-net:\n    external: true\n    name: host\n" +
+			"secrets:\n  token:\n    file: ./token\n  key:\n    environment: API_KEY\n" +
+			"configs:\n  app:\n    file: ./app.conf\n  existing:\n    external: true\n",
+	})
+	result, err := New(Options{ProjectName: "test"}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Resources{
+		Volumes: []Resource{
+			{Name: "cache", ResourceName: "test_cache", Driver: "local"},
+			{Name: "data", ResourceName: "test_data"},
+			{Name: "shared", ResourceName: "fleet-shared", External: true},
+		},
+		Networks: []Resource{
+			{Name: "backend", ResourceName: "test_backend", Driver: "bridge"},
+			{Name: "default", ResourceName: "test_default"},
+			{Name: "host-net", ResourceName: "host", External: true},
+		},
+		Secrets: []Resource{
+			{Name: "key", ResourceName: "test_key", Environment: "API_KEY"},
+			{Name: "token", ResourceName: "test_token", File: filepath.Join(dir, "token")},
+		},
+		Configs: []Resource{
+			{Name: "app", ResourceName: "test_app", File: filepath.Join(dir, "app.conf")},
+			{Name: "existing", ResourceName: "existing", External: true},
+		},
+	}
+	if resources := ListResources(result.Project); !reflect.DeepEqual(resources, expected) {
+		t.Errorf("expected %+v, got %+v", expected, resources)
+	}
+
+	// No resources are listed as empty arrays rather than null
+	resources := ListResources(mustParse(t, Options{NoNormalize: true}, "services:\n  web:\n    image: nginx\n").Project)
+	if resources.Volumes == nil || resources.Networks == nil || resources.Secrets == nil || resources.Configs == nil {
+		t.Errorf("expected empty lists, got %#v", resources)
+	}
+}
@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/schema"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Dialect of the output schema. The input schema is the compose spec's, which declares its own.
+const outputSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// InputSchema returns the JSON Schema of the compose files the parser accepts, which is the compose spec
+// schema compose-go validates them against
+func InputSchema() (map[string]any, error) {
+	var inputSchema map[string]any
+	if err := json.Unmarshal([]byte(schema.Schema), &inputSchema); err != nil {
+		return nil, fmt.Errorf("failed to decode compose spec schema: %w", err)
+	}
+	return inputSchema, nil
+}
+
+// OutputSchema returns a JSON Schema of documents output as JSON, given as a zero value of each by name,
+// e.g. types.Project{}. Each document is defined by its name, the root matching any of them, and each
+// struct type they include is defined by its type name. The schemas of the compose-go types which marshal
+// themselves are known, and the project's top-level x- extensions are allowed.
+func OutputSchema(title string, documents map[string]any) map[string]any {
+	r := &schemaReflector{defs: map[string]any{}, types: map[string]reflect.Type{}}
+	var refs []any
+	for _, name := range slices.Sorted(maps.Keys(documents)) {
+		ref := "#/$defs/" + name
+		// Documents named as their type are defined by the definition of the type
+		if document := r.schema(reflect.TypeOf(documents[name])); document["$ref"] != ref {
+			r.defs[name] = document
+		}
+		refs = append(refs, map[string]any{"$ref": ref})
+	}
+	return map[string]any{"$schema": outputSchemaDialect, "title": title, "anyOf": refs, "$defs": r.defs}
+}
+
+// schemaReflector derives JSON Schemas of Go types as encoding/json marshals them, collecting the schema of
+// each named struct type into defs
+type schemaReflector struct {
+	defs  map[string]any
+	types map[string]reflect.Type
+}
+
+func (r *schemaReflector) schema(t reflect.Type) map[string]any {
+	// compose-go types which marshal themselves to JSON in another shape than their fields
+	switch t {
+	case reflect.TypeFor[json.RawMessage]():
+		return map[string]any{}
+	case reflect.TypeFor[types.UnitBytes]():
+		return map[string]any{"type": "string", "pattern": `^\d+$`, "description": "Size in bytes"}
+	case reflect.TypeFor[types.Duration]():
+		return map[string]any{"type": "string", "description": "Duration, e.g. 1m30s"}
+	case reflect.TypeFor[types.FileMode]():
+		return map[string]any{"type": "string", "pattern": "^0[0-7]*$", "description": "Octal file mode"}
+	case reflect.TypeFor[types.SSHKey]():
+		return map[string]any{"type": "string"}
+	case reflect.TypeFor[types.HostsList]():
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+	case reflect.TypeFor[types.EnvFile]():
+		// Required env files are marshalled as their path
+		return map[string]any{"oneOf": []any{map[string]any{"type": "string"}, r.structSchema(t)}}
+	case reflect.TypeFor[types.UlimitsConfig]():
+		// Ulimits with a single limit are marshalled as the limit
+		return map[string]any{"oneOf": []any{map[string]any{"type": "integer"}, r.structSchema(t)}}
+	case reflect.TypeFor[types.SecretConfig](), reflect.TypeFor[types.ConfigObjConfig]():
+		return r.schema(reflect.TypeFor[types.FileObjectConfig]())
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return r.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are marshalled as base64
+			return map[string]any{"type": "string"}
+		}
+		return map[string]any{"type": "array", "items": r.schema(t.Elem())}
+	case reflect.Map:
+		values := r.schema(t.Elem())
+		if t.Elem().Kind() == reflect.Pointer {
+			values = map[string]any{"anyOf": []any{values, map[string]any{"type": "null"}}}
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name := t.Name()
+		if defined, ok := r.types[name]; ok && defined != t {
+			// Types of different packages with the same name are qualified by package, e.g. parser.Resources
+			name = path.Base(t.PkgPath()) + "." + name
+		}
+		if _, ok := r.types[name]; !ok {
+			// Defined before its fields, so recursive types refer to themselves
+			r.types[name] = t
+			r.defs[name] = r.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + name}
+	}
+	return map[string]any{}
+}
+
+// The schema of the fields of a struct type. Fields without omitempty are always marshalled, so required, and
+// null if nil.
+func (r *schemaReflector) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" || !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			name = cmp.Or(name, field.Name)
+			properties[name] = r.schema(field.Type)
+			if !slices.Contains(strings.Split(options, ","), "omitempty") {
+				required = append(required, name)
+				if kind := field.Type.Kind(); kind == reflect.Pointer || kind == reflect.Slice || kind == reflect.Map {
+					// Nil values are marshalled as null
+					properties[name] = map[string]any{"anyOf": []any{properties[name], map[string]any{"type": "null"}}}
+				}
+			}
+		}
+	}
+	addFields(t)
+	structSchema := map[string]any{"type": "object", "properties": properties, "additionalProperties": false}
+	if len(required) > 0 {
+		structSchema["required"] = required
+	}
+	if t == reflect.TypeFor[types.Project]() {
+		// Top-level extensions are marshalled along with the project's fields
+		structSchema["patternProperties"] = map[string]any{"^x-": map[string]any{}}
+	}
+	return structSchema
+}
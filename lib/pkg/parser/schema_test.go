@@ -0,0 +1,151 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// compileSchema compiles a schema so documents can be validated against it
+func compileSchema(t *testing.T, schema map[string]any) *jsonschema.Schema {
+	t.Helper()
+	content, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", document); err != nil {
+		t.Fatal(err)
+	}
+	compiled, err := compiler.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("expected a valid schema, got %v", err)
+	}
+	return compiled
+}
+
+// validateDocument validates the JSON of a document against a compiled schema
+func validateDocument(t *testing.T, schema *jsonschema.Schema, document any) error {
+	t.Helper()
+	content, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema.Validate(decoded)
+}
+
+func TestInputSchema(t *testing.T) {
+	schema, err := InputSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := schema["$schema"].(string); !ok || lookupSchema(schema, "properties.services") == nil {
+		t.Errorf("expected the compose spec schema, got %v", schema["properties"])
+	}
+	compileSchema(t, schema)
+}
+
+type schemaNode struct {
+	Name     string             `json:"name"`
+	Size     int                `json:"size,omitempty"`
+	Children []*schemaNode      `json:"children,omitempty"`
+	Labels   map[string]*string `json:"labels,omitempty"`
+	Data     []byte             `json:"data,omitempty"`
+	Hidden   string             `json:"-"`
+	secret   string
+}
+
+func TestOutputSchema(t *testing.T) {
+	schema := OutputSchema("test", map[string]any{"node": schemaNode{}, "error": ErrorResponse{}})
+	if schema["$schema"] != outputSchemaDialect || schema["title"] != "test" {
+		t.Errorf("expected a titled 2020-12 schema, got %v", schema)
+	}
+	// The root matches any document, by name order
+	if refs := schema["anyOf"]; !reflect.DeepEqual(refs, []any{map[string]any{"$ref": "#/$defs/error"}, map[string]any{"$ref": "#/$defs/node"}}) {
+		t.Errorf("expected the root to refer to each document, got %v", refs)
+	}
+	// Documents of named struct types refer to the definition of their type
+	if document := lookupSchema(schema, "$defs.node"); !reflect.DeepEqual(document, map[string]any{"$ref": "#/$defs/schemaNode"}) {
+		t.Errorf("expected the document to refer to its type, got %v", document)
+	}
+	node := lookupSchema(schema, "$defs.schemaNode")
+	if required := node["required"]; !reflect.DeepEqual(required, []string{"name"}) {
+		t.Errorf("expected only fields without omitempty to be required, got %v", required)
+	}
+	properties := node["properties"].(map[string]any)
+	if len(properties) != 5 || node["additionalProperties"] != false {
+		t.Errorf("expected only the marshalled fields as properties, got %v", properties)
+	}
+	// Named struct types are defined once, so recursive types refer to themselves
+	if items := lookupSchema(schema, "$defs.schemaNode.properties.children.items"); !reflect.DeepEqual(items, map[string]any{"$ref": "#/$defs/schemaNode"}) {
+		t.Errorf("expected the children to refer to their type, got %v", items)
+	}
+	if values := lookupSchema(schema, "$defs.schemaNode.properties.labels.additionalProperties"); values["anyOf"] == nil {
+		t.Errorf("expected values of maps of pointers to be nullable, got %v", values)
+	}
+	if data := lookupSchema(schema, "$defs.schemaNode.properties.data"); data["type"] != "string" {
+		t.Errorf("expected byte slices to be base64 strings, got %v", data)
+	}
+
+	// Documents named as their type don't refer to themselves
+	named := OutputSchema("test", map[string]any{"schemaNode": schemaNode{}})
+	if node := lookupSchema(named, "$defs.schemaNode"); node["type"] != "object" {
+		t.Errorf("expected the document to be defined by its type, got %v", node)
+	}
+	compileSchema(t, named)
+
+	compiled := compileSchema(t, schema)
+	value := "v"
+	for _, document := range []any{
+		schemaNode{Name: "root", Children: []*schemaNode{{Name: "child", Size: 1}}, Labels: map[string]*string{"a": &value, "b": nil}, Data: []byte("data")},
+		ErrorResponse{Error: true, Name: ParseError, Message: "failed", Location: &Location{File: "compose.yml", Line: 1}},
+	} {
+		if err := validateDocument(t, compiled, document); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", document, err)
+		}
+	}
+	if err := validateDocument(t, compiled, map[string]any{"name": 1}); err == nil {
+		t.Error("expected a document of no definition to be invalid")
+	}
+}
+
+func TestOutputSchemaProject(t *testing.T) {
+	// Types of different packages with the same name are qualified
+	schema := OutputSchema("project", map[string]any{"project": types.Project{}, "resources": Resources{}})
+	defs := schema["$defs"].(map[string]any)
+	if defs["Resources"] == nil || defs["parser.Resources"] == nil {
+		t.Errorf("expected both Resources types to be defined, got %v", schema["$defs"])
+	}
+
+	project := mustParse(t, Options{}, "services:\n"+
+		"  web:\n    image: nginx\n    ports: [\"8080:80\"]\n    mem_limit: 256m\n    healthcheck:\n      test: [CMD, \"true\"]\n      interval: 30s\n"+
+		"    env_file: [{path: web.env, required: false}]\n    ulimits:\n      nofile: 1024\n    volumes: [data:/data]\n"+
+		"volumes:\n  data: {}\nx-custom:\n  key: value\n").Project
+	if err := validateDocument(t, compileSchema(t, schema), project); err != nil {
+		t.Errorf("expected the project to match the schema, got %v", err)
+	}
+}
+
+// lookupSchema returns the schema at a dotted path of keys, or nil
+func lookupSchema(schema map[string]any, path string) map[string]any {
+	for key := range strings.SplitSeq(path, ".") {
+		var ok bool
+		if schema, ok = schema[key].(map[string]any); !ok {
+			return nil
+		}
+	}
+	return schema
+}
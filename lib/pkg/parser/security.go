@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Codes of the findings of the security lint rules
+const (
+	// PrivilegedServiceCode is reported for privileged services
+	PrivilegedServiceCode = "privileged-service"
+	// DangerousCapabilityCode is reported for cap_add entries giving broad control of the host
+	DangerousCapabilityCode = "dangerous-capability"
+	// DockerSocketCode is reported for bind mounts of the engine socket, which give root access to the host
+	DockerSocketCode = "docker-socket"
+	// IgnoredPortsCode is reported for ports published by services on the host network, which expose
+	// every port they listen on rather than those published
+	IgnoredPortsCode = "ignored-ports"
+	// WritableHostMountCode is reported for bind mounts of host paths which aren't read-only
+	WritableHostMountCode = "writable-host-mount"
+	// MountEscapeCode is reported for relative bind mounts of paths outside the project directory
+	MountEscapeCode = "mount-escape"
+	// SensitiveMountCode is reported for bind mounts of balenaOS paths whose changes may break the device
+	SensitiveMountCode = "sensitive-mount"
+)
+
+// Host paths of balenaOS which services mustn't bind mount, as changing them may break the device, e.g.
+// config.json on the boot partition, paths beneath them included. The root is only matched itself.
+var sensitiveHostPaths = []string{"/", "/boot", "/etc", "/mnt/boot", "/mnt/data", "/mnt/state", "/var/lib/balena-engine", "/var/lib/docker"}
+
+// Host paths of the engine socket, which balenaOS also exposes as the balena socket
+var dockerSockets = []string{"/var/run/docker.sock", "/run/docker.sock", "/var/run/balena-engine.sock", "/run/balena-engine.sock"}
+
+func privilegedIssues(path string, service types.ServiceConfig) []targetIssue {
+	if !service.Privileged {
+		return nil
+	}
+	return []targetIssue{{code: PrivilegedServiceCode, path: path + ".privileged", message: fmt.Sprintf(
+		"%s.privileged gives access to every device and capability of the host; add the devices and capabilities it needs instead", path)}}
+}
+
+func dangerousCapabilityIssues(path string, service types.ServiceConfig) []targetIssue {
+	var issues []targetIssue
+	for i, capability := range service.CapAdd {
+		if name := strings.TrimPrefix(strings.ToUpper(capability), "CAP_"); slices.Contains(broadCapabilities, name) {
+			issues = append(issues, targetIssue{code: DangerousCapabilityCode, path: fmt.Sprintf("%s.cap_add.%d", path, i), message: fmt.Sprintf(
+				"%s.cap_add: %s gives broad control of the host", path, capability)})
+		}
+	}
+	return issues
+}
+
+func dockerSocketIssues(servicePath string, service types.ServiceConfig) []targetIssue {
+	var issues []targetIssue
+	for i, volume := range service.Volumes {
+		if volume.Type == types.VolumeTypeBind && slices.Contains(dockerSockets, path.Clean(volume.Source)) {
+			issues = append(issues, targetIssue{code: DockerSocketCode, path: fmt.Sprintf("%s.volumes.%d", servicePath, i), message: fmt.Sprintf(
+				"%s.volumes: %s gives root access to the host through the engine; use the io.balena.features.balena-socket label if the service needs it", servicePath, volume.Source)})
+		}
+	}
+	return issues
+}
+
+func ignoredPortsIssues(path string, service types.ServiceConfig) []targetIssue {
+	if service.NetworkMode != "host" || len(service.Ports) == 0 {
+		return nil
+	}
+	return []targetIssue{{code: IgnoredPortsCode, path: path + ".ports", message: fmt.Sprintf(
+		"%s.ports are ignored with network_mode: host, which exposes every port the service listens on", path)}}
+}
+
+func writableHostMountIssues(servicePath string, service types.ServiceConfig) []targetIssue {
+	var issues []targetIssue
+	for i, volume := range service.Volumes {
+		if volume.Type == types.VolumeTypeBind && !volume.ReadOnly && !slices.Contains(dockerSockets, path.Clean(volume.Source)) {
+			issues = append(issues, targetIssue{code: WritableHostMountCode, path: fmt.Sprintf("%s.volumes.%d", servicePath, i), message: fmt.Sprintf(
+				"%s.volumes: host path %s is mounted writable; mount it read-only unless the service writes to it", servicePath, volume.Source)})
+		}
+	}
+	return issues
+}
+
+// The issues of the bind mounts of a service of relative paths resolving outside the project directory, or
+// of sensitive balenaOS paths, except for those allowed and paths beneath them. Relative paths are compared
+// as written, as the projects linted keep them relative.
+func mountPathIssues(servicePath string, service types.ServiceConfig, allowed []string) []targetIssue {
+	var issues []targetIssue
+	for i, volume := range service.Volumes {
+		source := path.Clean(volume.Source)
+		if volume.Type != types.VolumeTypeBind || volume.Source == "" || slices.ContainsFunc(allowed, func(allowed string) bool {
+			return isPathBeneath(source, path.Clean(allowed))
+		}) {
+			continue
+		}
+		volumePath := fmt.Sprintf("%s.volumes.%d", servicePath, i)
+		switch {
+		case !path.IsAbs(source) && !strings.HasPrefix(source, "~") && (source == ".." || strings.HasPrefix(source, "../")):
+			issues = append(issues, targetIssue{code: MountEscapeCode, path: volumePath, message: fmt.Sprintf(
+				"%s.volumes: %s resolves outside the project directory, which it doesn't contain", servicePath, volume.Source)})
+		case slices.ContainsFunc(sensitiveHostPaths, func(sensitive string) bool {
+			return source == sensitive || sensitive != "/" && isPathBeneath(source, sensitive)
+		}):
+			issues = append(issues, targetIssue{code: SensitiveMountCode, path: volumePath, message: fmt.Sprintf(
+				"%s.volumes: %s is a balenaOS path whose changes may break the device; allow it if the service needs it", servicePath, volume.Source)})
+		}
+	}
+	return issues
+}
+
+// Whether a clean path is another or beneath it
+func isPathBeneath(p, parent string) bool {
+	return p == parent || strings.HasPrefix(p, strings.TrimSuffix(parent, "/")+"/")
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSecurityRules(t *testing.T) {
+	findings := lint(t, LintOptions{Disable: []string{"security-mount-paths"}}, "services:\n"+
+		"  web:\n    image: nginx:1.25\n    privileged: true\n    cap_add: [NET_ADMIN, cap_sys_admin, CHOWN]\n"+
+		"    volumes:\n      - /var/run/balena-engine.sock:/var/run/docker.sock\n      - /data:/data:ro\n      - ./config:/config\n"+
+		"  proxy:\n    image: nginx:1.25\n    network_mode: host\n    ports: [\"80:80\"]\n")
+	var issues []string
+	for _, finding := range findings {
+		if strings.HasPrefix(finding.Rule, "security-") {
+			issues = append(issues, finding.Rule+" "+finding.Severity+" "+finding.Location.Path+": "+finding.Message)
+		}
+	}
+	// The engine socket isn't also reported as writable, nor read-only mounts
+	expected := []string{
+		"security-capabilities warning services.web.cap_add.0: services.web.cap_add: NET_ADMIN gives broad control of the host",
+		"security-capabilities warning services.web.cap_add.1: services.web.cap_add: cap_sys_admin gives broad control of the host",
+		"security-docker-socket error services.web.volumes.0: services.web.volumes: /var/run/balena-engine.sock gives root access to the host through the engine; use the io.balena.features.balena-socket label if the service needs it",
+		"security-host-network-ports warning services.proxy.ports: services.proxy.ports are ignored with network_mode: host, which exposes every port the service listens on",
+		"security-privileged warning services.web.privileged: services.web.privileged gives access to every device and capability of the host; add the devices and capabilities it needs instead",
+		"security-writable-mounts info services.web.volumes.2: services.web.volumes: host path ./config is mounted writable; mount it read-only unless the service writes to it",
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("expected %q, got %q", expected, issues)
+	}
+}
+
+func TestMountPaths(t *testing.T) {
+	tests := []struct {
+		name     string
+		volumes  string
+		allowed  []string
+		expected []string
+	}{
+		{name: "project paths", volumes: "[\"./data:/data\", \"./config/../data:/other\", \"/srv/app:/app\", \"data:/volume\"]"},
+		{
+			name:     "outside the project directory",
+			volumes:  "[\"../shared:/shared\", \"./config/../../secrets:/secrets\"]",
+			expected: []string{"mount-escape services.web.volumes.0", "mount-escape services.web.volumes.1"},
+		},
+		{
+			name:     "sensitive paths",
+			volumes:  "[\"/mnt/boot:/boot\", \"/etc/hostname:/etc/hostname\", \"/:/host\", \"/var/lib/balena-engine/volumes:/volumes\"]",
+			expected: []string{"sensitive-mount services.web.volumes.0", "sensitive-mount services.web.volumes.1", "sensitive-mount services.web.volumes.2", "sensitive-mount services.web.volumes.3"},
+		},
+		// The root is only sensitive itself, and paths sharing a prefix with a sensitive path aren't beneath it
+		{name: "beneath the root", volumes: "[\"/srv:/srv\", \"/etcetera:/etcetera\"]"},
+		{
+			name:     "allowed",
+			volumes:  "[\"/mnt/boot/config.json:/config.json\", \"../shared/assets:/assets\", \"/etc/hostname:/etc/hostname\"]",
+			allowed:  []string{"/mnt/boot/", "../shared"},
+			expected: []string{"sensitive-mount services.web.volumes.2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := lint(t, LintOptions{AllowedMounts: tt.allowed}, "services:\n  web:\n    image: nginx:1.25\n    volumes: "+tt.volumes+"\n")
+			var issues []string
+			for _, finding := range findings {
+				if finding.Rule == "security-mount-paths" {
+					issues = append(issues, finding.Code+" "+finding.Location.Path)
+				}
+			}
+			if strings.Join(issues, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("expected %v, got %v", tt.expected, issues)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+)
+
+// Fields with their default values, which are removed from stable output as whether compose-go sets
+// them depends on its version and how the compose files are written
+var (
+	serviceFieldDefaults = map[string]any{"command": nil, "entrypoint": nil, "privileged": false, "read_only": false, "stdin_open": false, "tty": false}
+	buildFieldDefaults   = map[string]any{"context": ".", "dockerfile": "Dockerfile"}
+	portFieldDefaults    = map[string]any{"protocol": "tcp", "mode": "ingress"}
+	mountFieldDefaults   = map[string]any{"read_only": false}
+	bindFieldDefaults    = map[string]any{"create_host_path": true}
+	dependencyDefaults   = map[string]any{"condition": "service_started", "required": true, "restart": false}
+	networkFieldDefaults = map[string]any{"external": false, "internal": false, "attachable": false}
+	volumeFieldDefaults  = map[string]any{"external": false}
+)
+
+// StableJSON converts the project JSON into canonical JSON which is also unchanged by non-semantic
+// differences between parser versions: fields set to their default, empty lists and objects, and
+// unclean paths such as ./app/ are removed or cleaned, so that projects can be diffed without spurious
+// changes. Names in the compose files, e.g. of networks a service is attached to, are always kept.
+func StableJSON(projectJSON []byte) ([]byte, error) {
+	canonical, err := canonicalValue(projectJSON)
+	if err != nil {
+		return nil, err
+	}
+	value := object(canonical)
+	for _, service := range object(value["services"]) {
+		stabilizeService(object(service))
+	}
+	for _, network := range object(value["networks"]) {
+		stabilizeFields(object(network), networkFieldDefaults)
+	}
+	for _, volume := range object(value["volumes"]) {
+		stabilizeFields(object(volume), volumeFieldDefaults)
+	}
+	return encodeCanonical(value)
+}
+
+// Stabilize removes the non-semantic variance from the target state as StableJSON does from projects
+func (state *TargetState) Stabilize() {
+	for _, service := range state.Services {
+		stabilizeService(service.Composition)
+		// Set-like fields of compositions are sorted as in canonical JSON
+		sortSetFields(service.Composition, serviceSetFields)
+	}
+	for _, network := range state.Networks {
+		stabilizeFields(network, networkFieldDefaults)
+	}
+	for _, volume := range state.Volumes {
+		stabilizeFields(volume, volumeFieldDefaults)
+	}
+}
+
+func stabilizeService(service map[string]any) {
+	if service == nil {
+		return
+	}
+	cleanPath(service, "working_dir")
+	if build := object(service["build"]); build != nil {
+		if isLocalContext(text(build["context"])) {
+			cleanPath(build, "context")
+		}
+		cleanPath(build, "dockerfile")
+		stabilizeFields(build, buildFieldDefaults)
+	}
+	for _, port := range array(service["ports"]) {
+		stabilizeFields(object(port), portFieldDefaults)
+	}
+	for _, mount := range array(service["volumes"]) {
+		mount := object(mount)
+		if mount == nil {
+			continue
+		}
+		if mount["type"] == "bind" {
+			cleanPath(mount, "source")
+		}
+		cleanPath(mount, "target")
+		if bind := object(mount["bind"]); bind != nil {
+			stabilizeFields(bind, bindFieldDefaults)
+		}
+		stabilizeFields(mount, mountFieldDefaults)
+	}
+	for _, dependency := range object(service["depends_on"]) {
+		stabilizeFields(object(dependency), dependencyDefaults)
+	}
+	stabilizeFields(service, serviceFieldDefaults)
+}
+
+// Remove the fields of an object which are set to their default, or to null or an empty list or object
+func stabilizeFields(obj map[string]any, defaults map[string]any) {
+	for field, value := range obj {
+		switch value := value.(type) {
+		case nil:
+			delete(obj, field)
+		case map[string]any:
+			if len(value) == 0 {
+				delete(obj, field)
+			}
+		case []any:
+			if len(value) == 0 {
+				delete(obj, field)
+			}
+		default:
+			if defaultValue, ok := defaults[field]; ok && value == defaultValue {
+				delete(obj, field)
+			}
+		}
+	}
+}
+
+// Clean the path of a field, e.g. ./app/ to app, if set
+func cleanPath(obj map[string]any, field string) {
+	if p, ok := obj[field].(string); ok && p != "" {
+		obj[field] = path.Clean(p)
+	}
+}
+
+// Encode canonical values without insignificant whitespace
+func encodeCanonical(value any) ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{})
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	// Encode terminates the document with a newline, which isn't significant
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
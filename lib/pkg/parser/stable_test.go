@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStableJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "removes fields set to their default",
+			input:    `{"services": {"a": {"image": "a", "command": null, "privileged": false, "tty": true, "ports": [{"target": 80, "protocol": "tcp", "mode": "ingress"}], "depends_on": {"b": {"condition": "service_started", "required": true, "restart": false}}}}}`,
+			expected: `{"services":{"a":{"depends_on":{"b":{}},"image":"a","ports":[{"target":80}],"tty":true}}}`,
+		},
+		{
+			name:     "removes empty lists and objects",
+			input:    `{"services": {"a": {"image": "a", "environment": {}, "volumes": [], "cap_add": []}}, "networks": {"default": {"ipam": {}, "external": false}}, "volumes": {"data": {"external": false}}}`,
+			expected: `{"networks":{"default":{}},"services":{"a":{"image":"a"}},"volumes":{"data":{}}}`,
+		},
+		{
+			name:     "cleans paths",
+			input:    `{"services": {"a": {"working_dir": "/app/", "build": {"context": "./web/", "dockerfile": "./Dockerfile.prod"}, "volumes": [{"type": "bind", "source": "./data/", "target": "/data/", "bind": {"create_host_path": true}}]}}}`,
+			expected: `{"services":{"a":{"build":{"context":"web","dockerfile":"Dockerfile.prod"},"volumes":[{"source":"data","target":"/data","type":"bind"}],"working_dir":"/app"}}}`,
+		},
+		{
+			name:     "keeps remote contexts and names",
+			input:    `{"services": {"a": {"build": {"context": "https://github.com/balena-io/api.git#main:./dir/"}, "networks": {"default": null}}}}`,
+			expected: `{"services":{"a":{"build":{"context":"https://github.com/balena-io/api.git#main:./dir/"},"networks":{"default":null}}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := StableJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(output) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestTargetStateStabilize(t *testing.T) {
+	state := &TargetState{
+		Services: map[string]TargetStateService{
+			"web": {Image: "nginx", Composition: map[string]any{"cap_add": []any{"SYS_ADMIN", "NET_ADMIN"}, "tty": false, "working_dir": "/app/"}},
+		},
+		Volumes:  map[string]map[string]any{"data": {"external": false, "driver": "local"}},
+		Networks: map[string]map[string]any{"default": {"internal": false}},
+	}
+	state.Stabilize()
+	if composition := state.Services["web"].Composition; !reflect.DeepEqual(composition, map[string]any{"cap_add": []any{"NET_ADMIN", "SYS_ADMIN"}, "working_dir": "/app"}) {
+		t.Errorf("expected the composition to be stabilized and its set fields sorted, got %v", composition)
+	}
+	if !reflect.DeepEqual(state.Volumes["data"], map[string]any{"driver": "local"}) || len(state.Networks["default"]) != 0 {
+		t.Errorf("expected the defaults of volumes and networks to be removed, got %v and %v", state.Volumes, state.Networks)
+	}
+}
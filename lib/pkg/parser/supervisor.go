@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// IncompatibleSupervisorCode is reported for depends_on conditions and healthcheck fields the supervisor
+// version set with Options.SupervisorVersion is too old to support
+const IncompatibleSupervisorCode = "incompatible-supervisor"
+
+// A supervisor or balenaOS version, e.g. v16.4.2 or 2.113.18+rev1, optionally with a prerelease or build
+// suffix which isn't compared
+var versionPattern = regexp.MustCompile(`^v?(\d+)\.(\d+)\.(\d+)([-+][0-9A-Za-z.+-]*)?$`)
+
+// The first supervisor version supporting each depends_on condition. Dependencies must still be required
+// and not restart the service, which no supervisor supports.
+var supervisorDependencyConditions = map[string]string{
+	types.ServiceConditionStarted:               "0.0.0",
+	types.ServiceConditionHealthy:               "16.4.0",
+	types.ServiceConditionCompletedSuccessfully: "16.7.0",
+}
+
+// The major, minor and patch numbers of a version, or false if it isn't one
+func parseVersion(version string) ([3]int, bool) {
+	var numbers [3]int
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return numbers, false
+	}
+	for i := range numbers {
+		numbers[i], _ = strconv.Atoi(match[i+1])
+	}
+	return numbers, true
+}
+
+// Check an option setting the version of software on devices, e.g. of the supervisor, which validates
+// against BalenaTarget
+func checkTargetVersion(software, version, target string) *Error {
+	if version == "" {
+		return nil
+	}
+	if target != BalenaTarget {
+		return &Error{Name: ArgumentError, Message: fmt.Sprintf("The %s version can only be set with the %s target", software, BalenaTarget)}
+	}
+	if _, ok := parseVersion(version); !ok {
+		return &Error{Name: ArgumentError, Message: fmt.Sprintf("Invalid %s version %q, expected e.g. v16.4.0", software, version)}
+	}
+	return nil
+}
+
+// The issues of the depends_on entries of a service as output. Without a supervisor version only the
+// service_started condition is supported, as on every supervisor.
+func dependencyIssues(path string, dependsOn map[string]any, supervisorVersion string) []targetIssue {
+	var issues []targetIssue
+	fail := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...)})
+	}
+	version, _ := parseVersion(supervisorVersion)
+	for _, dependency := range sortedKeys(dependsOn) {
+		config := object(dependsOn[dependency])
+		dependencyPath := path + ".depends_on." + dependency
+		condition := text(config["condition"])
+		minimum, known := supervisorDependencyConditions[condition]
+		required, _ := parseVersion(minimum)
+		switch {
+		case supervisorVersion == "":
+			if condition != types.ServiceConditionStarted || config["required"] != true {
+				fail(UnsupportedDependencyCode, dependencyPath, "%s only supports condition service_started and required true", dependencyPath)
+			}
+		case !known || config["required"] != true || config["restart"] == true:
+			fail(UnsupportedDependencyCode, dependencyPath,
+				"%s only supports conditions service_started, service_healthy and service_completed_successfully, with required true and without restart", dependencyPath)
+		case compareVersions(version, required) < 0:
+			fail(IncompatibleSupervisorCode, dependencyPath, "%s condition %s requires supervisor v%s or later, targeting %s", dependencyPath, condition, minimum, supervisorVersion)
+		}
+	}
+	return issues
+}
+
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected [3]int
+		ok       bool
+	}{
+		{version: "v16.4.2", expected: [3]int{16, 4, 2}, ok: true},
+		{version: "2.113.18+rev1", expected: [3]int{2, 113, 18}, ok: true},
+		{version: "17.0.0-beta.1", expected: [3]int{17, 0, 0}, ok: true},
+		{version: "16.4"},
+		{version: "latest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			if version, ok := parseVersion(tt.version); version != tt.expected || ok != tt.ok {
+				t.Errorf("expected %v %t, got %v %t", tt.expected, tt.ok, version, ok)
+			}
+		})
+	}
+	if compareVersions([3]int{16, 4, 0}, [3]int{16, 10, 0}) >= 0 || compareVersions([3]int{17, 0, 0}, [3]int{16, 10, 3}) <= 0 || compareVersions([3]int{1, 2, 3}, [3]int{1, 2, 3}) != 0 {
+		t.Error("expected versions to be compared numerically")
+	}
+}
+
+func TestSupervisorDependencies(t *testing.T) {
+	compose := "services:\n" +
+		"  web:\n    image: nginx\n    depends_on:\n      db:\n        condition: service_healthy\n      migrate:\n        condition: service_completed_successfully\n      cache:\n        condition: service_started\n" +
+		"  db:\n    image: postgres\n    healthcheck:\n      test: [CMD, pg_isready]\n" +
+		"  migrate:\n    image: alpine\n" +
+		"  cache:\n    image: redis\n"
+	tests := []struct {
+		version string
+		errors  []string
+	}{
+		// Without a version only service_started is supported, as on every supervisor
+		{errors: []string{"unsupported-dependency services.web.depends_on.db", "unsupported-dependency services.web.depends_on.migrate"}},
+		{version: "v14.0.0", errors: []string{"incompatible-supervisor services.web.depends_on.db", "incompatible-supervisor services.web.depends_on.migrate"}},
+		{version: "16.4.0", errors: []string{"incompatible-supervisor services.web.depends_on.migrate"}},
+		{version: "v16.7.0"},
+		{version: "17.0.0+rev1"},
+	}
+	for _, tt := range tests {
+		t.Run("supervisor "+tt.version, func(t *testing.T) {
+			errs, _ := targetIssues(t, Options{Target: BalenaTarget, SupervisorVersion: tt.version}, compose)
+			if !reflect.DeepEqual(errs, tt.errors) {
+				t.Errorf("expected %v, got %v", tt.errors, errs)
+			}
+		})
+	}
+
+	// No supervisor supports optional dependencies, or restarting services with them
+	errs, _ := targetIssues(t, Options{Target: BalenaTarget, SupervisorVersion: "v17.0.0"}, "services:\n"+
+		"  web:\n    image: nginx\n    depends_on:\n      db:\n        condition: service_started\n        required: false\n      cache:\n        condition: service_started\n        restart: true\n"+
+		"  db:\n    image: postgres\n  cache:\n    image: redis\n")
+	if expected := []string{"unsupported-dependency services.web.depends_on.cache", "unsupported-dependency services.web.depends_on.db"}; !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %v, got %v", expected, errs)
+	}
+
+	for _, tt := range []struct {
+		options Options
+		msg     string
+	}{
+		{options: Options{SupervisorVersion: "v16.4.0"}, msg: "The supervisor version can only be set with the balena target"},
+		{options: Options{Target: BalenaTarget, SupervisorVersion: "16.4"}, msg: `Invalid supervisor version "16.4", expected e.g. v16.4.0`},
+		{options: Options{Target: BalenaTarget, OSVersion: "balenaOS 2.113"}, msg: `Invalid balenaOS version "balenaOS 2.113"`},
+	} {
+		_, err := parse(t, tt.options, "services:\n  web:\n    image: nginx\n")
+		expectError(t, err, ArgumentError, tt.msg)
+	}
+}
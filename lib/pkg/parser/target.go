@@ -0,0 +1,333 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// BalenaTarget validates projects against the subset of the compose spec balenaOS and the supervisor support
+const BalenaTarget = "balena"
+
+// Targets are the platforms Options.Target can validate projects against
+var Targets = []string{BalenaTarget}
+
+// Codes of the issues making projects unsupported by a target, reported as the code of each error,
+// or of each warning for issues which aren't fatal. The location path identifies the field.
+const (
+	// UnsupportedFieldCode is reported for fields the target doesn't support at all
+	UnsupportedFieldCode = "unsupported-field"
+	// UnsupportedValueCode is reported for supported fields with a value the target doesn't support
+	UnsupportedValueCode = "unsupported-value"
+	// ContainerReferenceCode is reported for network_mode, pid and volumes_from referencing a container
+	// by ID, which isn't stable on devices
+	ContainerReferenceCode = "container-reference"
+	// UnsupportedVolumeCode is reported for service volumes which aren't named volumes or tmpfs mounts,
+	// or which set options, except for the bind mounts implied by io.balena.features labels
+	UnsupportedVolumeCode = "unsupported-volume"
+	// UnsupportedDependencyCode is reported for depends_on entries other than required service_started,
+	// or with Options.SupervisorVersion, for those of conditions no supervisor supports
+	UnsupportedDependencyCode = "unsupported-dependency"
+	// CDIDeviceCode is reported for devices using Container Device Interface names rather than paths
+	CDIDeviceCode = "cdi-device"
+	// RemoteBuildContextCode is reported for build contexts in git repositories
+	RemoteBuildContextCode = "remote-build-context"
+	// IgnoredFieldCode is reported for fields the target ignores, which are removed by the supervisor
+	IgnoredFieldCode = "ignored-field"
+	// DeviceRiskCode is reported for values which risk breaking the functionality of devices
+	DeviceRiskCode = "device-risk"
+)
+
+// Service fields which balena doesn't support
+var balenaServiceDenyList = []string{
+	"blkio_config", "configs", "cpu_count", "cpu_percent", "cpu_period", "credential_spec", "develop",
+	"external_links", "gpus", "isolation", "links", "logging", "mem_swappiness", "memswap_limit",
+	"oom_kill_disable", "platform", "pull_policy", "runtime", "scale", "secrets", "stdin_open", "storage_opt",
+}
+
+// The only deploy field balena supports, the device requests of GPUs, e.g. on Jetson device types
+var balenaDeployAllowList = []string{"resources", "reservations", "devices"}
+
+// Build fields which balena doesn't support
+var balenaBuildDenyList = []string{
+	"additional_contexts", "cache_to", "dockerfile_inline", "entitlements", "isolation", "network", "no_cache",
+	"platforms", "privileged", "pull", "secrets", "ssh", "tags", "ulimits",
+}
+
+// Values of oom_score_adj at or below this risk the supervisor or engine being killed first
+const balenaOOMScoreAdjThreshold = -900
+
+var containerReferencePattern = regexp.MustCompile(`^container:`)
+
+// An issue found validating a project against a target
+type targetIssue struct {
+	code    string
+	path    string
+	message string
+	warning bool
+	// replacement is what to use instead of a legacy field, for warnings
+	replacement string
+}
+
+// Validate a project against a target, returning a ValidationError listing every fatal issue, and the
+// warnings for the others
+func checkTarget(project *types.Project, target, supervisorVersion, osVersion string, composeFiles []string) (*Error, []Warning) {
+	projectJSON, err := project.MarshalJSON()
+	if err != nil {
+		return &Error{Name: ParseError, Message: fmt.Sprintf("Failed to marshal compose project: %v", err), Err: err}, nil
+	}
+	// Fields are checked as output, where unset fields are omitted
+	var config map[string]any
+	json.Unmarshal(projectJSON, &config)
+
+	var issues []targetIssue
+	switch target {
+	case BalenaTarget:
+		issues = balenaIssues(config, supervisorVersion, osVersion)
+	}
+	return reportIssues(fmt.Sprintf("Project isn't supported by %s", target), issues, composeFiles)
+}
+
+// Convert issues into a ValidationError listing every fatal issue after the summary, and the warnings
+// for the others
+func reportIssues(summary string, issues []targetIssue, composeFiles []string) (*Error, []Warning) {
+	var errs []*Error
+	var warnings []Warning
+	for _, issue := range issues {
+		location := locatePath(issue.path, composeFiles)
+		if issue.warning {
+			warnings = append(warnings, Warning{Code: issue.code, Message: issue.message, Location: location, Replacement: issue.replacement})
+		} else {
+			errs = append(errs, &Error{Name: ValidationError, Code: issue.code, Message: issue.message, Location: location})
+		}
+	}
+	if len(errs) == 0 {
+		return nil, warnings
+	}
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.Message)
+	}
+	return &Error{
+		Name:     ValidationError,
+		Code:     errs[0].Code,
+		Message:  fmt.Sprintf("%s: %s", summary, strings.Join(messages, "; ")),
+		Location: errs[0].Location,
+		Errors:   errs,
+	}, warnings
+}
+
+// Accessors for the generic JSON of a project, which return zero values for missing or mistyped fields
+func object(value any) map[string]any {
+	m, _ := value.(map[string]any)
+	return m
+}
+
+func array(value any) []any {
+	a, _ := value.([]any)
+	return a
+}
+
+func text(value any) string {
+	s, _ := value.(string)
+	return s
+}
+
+func sortedKeys(m map[string]any) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// The paths of the fields set in deploy outside of the allowed path, e.g. deploy.resources.limits beside
+// deploy.resources.reservations.devices. Empty mappings compose-go normalizes in, e.g. placement, are
+// ignored.
+func unsupportedDeployFields(path string, value any, allowed []string) []string {
+	m, ok := value.(map[string]any)
+	if !ok {
+		if value == nil {
+			return nil
+		}
+		return []string{path}
+	}
+	var fields []string
+	for _, key := range sortedKeys(m) {
+		switch {
+		case len(allowed) > 0 && key == allowed[0]:
+			if len(allowed) > 1 {
+				fields = append(fields, unsupportedDeployFields(path+"."+key, m[key], allowed[1:])...)
+			}
+		case !isEmptyMapping(m[key]):
+			fields = append(fields, path+"."+key)
+		}
+	}
+	return fields
+}
+
+// Whether a value is an empty mapping
+func isEmptyMapping(value any) bool {
+	m, ok := value.(map[string]any)
+	return ok && len(m) == 0
+}
+
+func balenaIssues(config map[string]any, supervisorVersion, osVersion string) []targetIssue {
+	var issues []targetIssue
+	fail := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...), warning: true})
+	}
+
+	for _, field := range []string{"secrets", "configs"} {
+		if _, ok := config[field]; ok {
+			fail(UnsupportedFieldCode, field, "top-level %s are not supported", field)
+		}
+	}
+
+	services := object(config["services"])
+	for _, name := range sortedKeys(services) {
+		service := object(services[name])
+		path := "services." + name
+		if issue, ok := serviceNameIssue(name); ok {
+			issues = append(issues, issue)
+		}
+		if image := text(service["image"]); image != "" {
+			if issue, ok := imageIssue(path+".image", image); ok {
+				issues = append(issues, issue)
+			}
+		}
+		for _, field := range balenaServiceDenyList {
+			if _, ok := service[field]; ok {
+				fail(UnsupportedFieldCode, path+"."+field, "%s.%s is not supported", path, field)
+			}
+		}
+		for _, field := range unsupportedDeployFields(path+".deploy", service["deploy"], balenaDeployAllowList) {
+			fail(UnsupportedFieldCode, field, "%s is not supported", field)
+		}
+		issues = append(issues, featureIssues(path+".labels", object(service["labels"]))...)
+
+		if build := object(service["build"]); build != nil {
+			for _, field := range balenaBuildDenyList {
+				if _, ok := build[field]; ok {
+					fail(UnsupportedFieldCode, path+".build."+field, "%s.build.%s is not supported", path, field)
+				}
+			}
+			if strings.HasSuffix(text(build["context"]), ".git") {
+				fail(RemoteBuildContextCode, path+".build.context", "%s.build.context can't be a remote context", path)
+			}
+		}
+
+		for _, field := range []string{"network_mode", "pid"} {
+			if containerReferencePattern.MatchString(text(service[field])) {
+				fail(ContainerReferenceCode, path+"."+field, "%s.%s can't reference a container", path, field)
+			}
+		}
+		for i, from := range array(service["volumes_from"]) {
+			if containerReferencePattern.MatchString(text(from)) {
+				fail(ContainerReferenceCode, fmt.Sprintf("%s.volumes_from.%d", path, i), "%s.volumes_from can't reference a container", path)
+			}
+		}
+		if ipc, ok := service["ipc"]; ok && ipc != "shareable" {
+			fail(UnsupportedValueCode, path+".ipc", "%s.ipc only supports \"shareable\", got %q", path, text(ipc))
+		}
+		for i, opt := range array(service["security_opt"]) {
+			if !strings.Contains(text(opt), "no-new-privileges") {
+				fail(UnsupportedValueCode, fmt.Sprintf("%s.security_opt.%d", path, i), "%s.security_opt only supports no-new-privileges, got %q", path, text(opt))
+			}
+		}
+		networks := object(service["networks"])
+		for _, network := range sortedKeys(networks) {
+			if _, ok := object(networks[network])["link_local_ips"]; ok {
+				fail(UnsupportedFieldCode, path+".networks."+network+".link_local_ips", "%s.networks.%s.link_local_ips is not supported", path, network)
+			}
+		}
+		if limit, ok := service["pids_limit"].(float64); ok && limit < 0 {
+			fail(UnsupportedValueCode, path+".pids_limit", "%s.pids_limit can't be negative", path)
+		}
+
+		issues = append(issues, dependencyIssues(path, object(service["depends_on"]), supervisorVersion)...)
+		issues = append(issues, supervisorHealthcheckIssues(path, object(service["healthcheck"]), supervisorVersion)...)
+		issues = append(issues, capabilityIssues(path, service, osVersion)...)
+		for i, device := range array(service["devices"]) {
+			device := object(device)
+			if !strings.HasPrefix(text(device["source"]), "/") || !strings.HasPrefix(text(device["target"]), "/") {
+				fail(CDIDeviceCode, fmt.Sprintf("%s.devices.%d", path, i), "%s.devices can't use CDI syntax", path)
+			}
+		}
+		for i, volume := range array(service["volumes"]) {
+			volume := object(volume)
+			volumePath := fmt.Sprintf("%s.volumes.%d", path, i)
+			volumeType := text(volume["type"])
+			switch {
+			case isBalenaFeatureMount(text(volume["source"])):
+				// Converted to the io.balena.features label implying it
+			case volumeType != types.VolumeTypeVolume && volumeType != types.VolumeTypeTmpfs:
+				fail(UnsupportedVolumeCode, volumePath, "%s.volumes can't be of type %q", path, volumeType)
+			case len(object(volume["volume"])) > 0 || len(object(volume["tmpfs"])) > 0:
+				fail(UnsupportedVolumeCode, volumePath, "%s.volumes can't set volume or tmpfs options", path)
+			case volumeType == types.VolumeTypeVolume && (text(volume["source"]) == "" || text(volume["target"]) == ""):
+				fail(UnsupportedVolumeCode, volumePath, "%s.volumes must specify a source and target", path)
+			}
+		}
+
+		for _, field := range []string{"expose", "container_name"} {
+			if _, ok := service[field]; ok {
+				warn(IgnoredFieldCode, path+"."+field, "%s.%s is not supported and is removed", path, field)
+			}
+		}
+		for i, port := range array(service["ports"]) {
+			for _, field := range []string{"name", "app_protocol"} {
+				if _, ok := object(port)[field]; ok {
+					warn(IgnoredFieldCode, fmt.Sprintf("%s.ports.%d.%s", path, i, field), "%s.ports.%s is not supported and is removed", path, field)
+				}
+			}
+		}
+		if score, ok := service["oom_score_adj"].(float64); ok && score <= balenaOOMScoreAdjThreshold {
+			warn(DeviceRiskCode, path+".oom_score_adj", "%s.oom_score_adj values of %d or under may break device functionality", path, balenaOOMScoreAdjThreshold)
+		}
+	}
+
+	networks := object(config["networks"])
+	for _, name := range sortedKeys(networks) {
+		network := object(networks[name])
+		path := "networks." + name
+		for _, field := range []string{"attachable", "external"} {
+			if _, ok := network[field]; ok {
+				fail(UnsupportedFieldCode, path+"."+field, "%s.%s is not supported", path, field)
+			}
+		}
+		if driver, ok := network["driver"]; ok && driver != "bridge" && driver != "default" {
+			fail(UnsupportedValueCode, path+".driver", "%s.driver only supports \"bridge\" and \"default\", got %q", path, text(driver))
+		}
+		for i, ipamConfig := range array(object(network["ipam"])["config"]) {
+			if _, ok := object(ipamConfig)["aux_addresses"]; ok {
+				fail(UnsupportedFieldCode, fmt.Sprintf("%s.ipam.config.%d.aux_addresses", path, i), "%s.ipam.config.aux_addresses is not supported", path)
+			}
+		}
+		if network["enable_ipv6"] == true {
+			fail(UnsupportedValueCode, path+".enable_ipv6", "%s.enable_ipv6 is not supported", path)
+		}
+		if _, ok := object(network["driver_opts"])["com.docker.network.bridge.name"]; ok {
+			warn(DeviceRiskCode, path+".driver_opts.com.docker.network.bridge.name", "%s.driver_opts.com.docker.network.bridge.name may interfere with the device firewall", path)
+		}
+	}
+
+	volumes := object(config["volumes"])
+	for _, name := range sortedKeys(volumes) {
+		volume := object(volumes[name])
+		path := "volumes." + name
+		if _, ok := volume["external"]; ok {
+			fail(UnsupportedFieldCode, path+".external", "%s.external is not supported", path)
+		}
+		if driver, ok := volume["driver"]; ok && driver != "local" && driver != "default" {
+			fail(UnsupportedValueCode, path+".driver", "%s.driver only supports \"local\" and \"default\", got %q", path, text(driver))
+		} else {
+			issues = append(issues, volumeDriverIssues(path, volume)...)
+		}
+	}
+	return issues
+}
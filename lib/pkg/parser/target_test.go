@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The codes and paths of the errors of a failed parse, or of the warnings of a successful one
+func targetIssues(t *testing.T, options Options, compose string) (errs, warnings []string) {
+	t.Helper()
+	issue := func(code string, location *Location) string {
+		if location == nil {
+			return code
+		}
+		return code + " " + location.Path
+	}
+	options.Warnings = true
+	result, err := parse(t, options, compose)
+	if err != nil {
+		parserErr := expectError(t, err, ValidationError, "")
+		for _, e := range parserErr.Errors {
+			errs = append(errs, issue(e.Code, e.Location))
+		}
+		return errs, nil
+	}
+	for _, warning := range result.Warnings {
+		warnings = append(warnings, issue(warning.Code, warning.Location))
+	}
+	return nil, warnings
+}
+
+func TestBalenaTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		compose  string
+		errors   []string
+		warnings []string
+	}{
+		{name: "supported", compose: "services:\n  web:\n    image: nginx\n    ports: [\"80:80\"]\n    ipc: shareable\n    security_opt: [no-new-privileges:true]\n    volumes: [\"data:/data\"]\nvolumes:\n  data: {}\n"},
+		{
+			name:    "unsupported service fields",
+			compose: "services:\n  web:\n    image: nginx\n    links: [db]\n    scale: 2\n    stdin_open: true\n  db:\n    image: postgres\n",
+			errors:  []string{"unsupported-field services.web.links", "unsupported-field services.web.scale", "unsupported-field services.web.stdin_open"},
+		},
+		{
+			name:    "deploy",
+			compose: "services:\n  web:\n    image: nginx\n    deploy:\n      replicas: 2\n      resources:\n        limits:\n          memory: 512M\n",
+			errors:  []string{"unsupported-field services.web.deploy.replicas", "unsupported-field services.web.deploy.resources.limits"},
+		},
+		{
+			name:    "build",
+			compose: "services:\n  web:\n    build:\n      context: .\n      no_cache: true\n      tags: [web:latest]\n",
+			errors:  []string{"unsupported-field services.web.build.no_cache", "unsupported-field services.web.build.tags"},
+		},
+		{
+			name:    "container references",
+			compose: "services:\n  web:\n    image: nginx\n    network_mode: container:abc123\n    volumes_from: [container:def456]\n",
+			errors:  []string{"container-reference services.web.network_mode", "container-reference services.web.volumes_from.0"},
+		},
+		{
+			name:    "unsupported values",
+			compose: "services:\n  web:\n    image: nginx\n    ipc: host\n    security_opt: [seccomp:unconfined]\n",
+			errors:  []string{"unsupported-value services.web.ipc", "unsupported-value services.web.security_opt.0"},
+		},
+		{
+			name:    "volumes",
+			compose: "services:\n  web:\n    image: nginx\n    volumes: [\"./data:/data\", \"/mnt/host:/host\"]\n",
+			errors:  []string{"unsupported-volume services.web.volumes.0", "unsupported-volume services.web.volumes.1"},
+		},
+		{
+			name:    "networks and volumes",
+			compose: "services:\n  web:\n    image: nginx\nnetworks:\n  backend:\n    external: true\n  overlay:\n    driver: overlay\nvolumes:\n  data:\n    external: true\n",
+			errors:  []string{"unsupported-field networks.backend.external", "unsupported-value networks.overlay.driver", "unsupported-field volumes.data.external"},
+		},
+		{
+			name:    "top-level secrets",
+			compose: "services:\n  web:\n    image: nginx\nsecrets:\n  token:\n    file: ./token\n",
+			errors:  []string{"unsupported-field secrets"},
+		},
+		{
+			name:     "ignored fields",
+			compose:  "services:\n  web:\n    image: nginx\n    expose: [\"80\"]\n    container_name: web\n",
+			warnings: []string{"ignored-field services.web.expose", "ignored-field services.web.container_name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, warnings := targetIssues(t, Options{Target: BalenaTarget}, tt.compose)
+			if strings.Join(errs, ", ") != strings.Join(tt.errors, ", ") {
+				t.Errorf("expected the errors %v, got %v", tt.errors, errs)
+			}
+			if strings.Join(warnings, ", ") != strings.Join(tt.warnings, ", ") {
+				t.Errorf("expected the warnings %v, got %v", tt.warnings, warnings)
+			}
+			// Nothing is checked without a target
+			if _, err := parse(t, Options{}, tt.compose); err != nil {
+				t.Errorf("expected no errors without a target, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBalenaTargetError(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"compose.yml": "services:\n  web:\n    image: nginx\n    links: [db]\n    scale: 2\n  db:\n    image: postgres\n"})
+	_, err := New(Options{ProjectName: "test", Target: BalenaTarget}).Parse(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	parserErr := expectError(t, err, ValidationError, "Project isn't supported by balena: services.web.links is not supported; services.web.scale is not supported")
+	if parserErr.Code != UnsupportedFieldCode || parserErr.Location == nil || parserErr.Location.Line != 4 || parserErr.Location.Path != "services.web.links" {
+		t.Errorf("expected the error to be located at the first issue, got %s at %+v", parserErr.Code, parserErr.Location)
+	}
+
+	_, err = parse(t, Options{Target: "swarm"}, "services:\n  web:\n    image: nginx\n")
+	expectError(t, err, ArgumentError, `Unsupported target "swarm", expected one of: balena`)
+}
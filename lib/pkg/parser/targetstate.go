@@ -0,0 +1,245 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// TargetState is a project in the representation of a release in the balena supervisor's target state
+type TargetState struct {
+	Services map[string]TargetStateService `json:"services"`
+	Volumes  map[string]map[string]any     `json:"volumes"`
+	Networks map[string]map[string]any     `json:"networks"`
+}
+
+// TargetStateService is a service of a release in the supervisor's target state
+type TargetStateService struct {
+	// Image is the image the service runs, or "" if it's built without one, as the built image is only
+	// known once the release is built
+	Image string `json:"image"`
+	// Environment are the variables set in the service, without those which are unset
+	Environment map[string]string `json:"environment"`
+	// Labels include the io.balena.features labels implied by the service's bind mounts
+	Labels map[string]string `json:"labels"`
+	// Composition is the service's compose configuration, in the short syntaxes legacy supervisors support
+	Composition map[string]any `json:"composition"`
+}
+
+// Fields of services the supervisor ignores, or which compose-go resolves into other fields
+var targetStateIgnoredFields = []string{"build", "env_file", "label_file", "expose", "container_name"}
+
+// ToTargetState converts a project into the supervisor's target state representation of a release.
+// The project should be validated with BalenaTarget, as fields balena doesn't support are kept,
+// and normalized for balena, as the supervisor names networks and volumes itself.
+func ToTargetState(project *types.Project) (*TargetState, error) {
+	projectJSON, err := project.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var config map[string]any
+	if err := json.Unmarshal(projectJSON, &config); err != nil {
+		return nil, err
+	}
+
+	state := &TargetState{Services: map[string]TargetStateService{}, Volumes: map[string]map[string]any{}, Networks: map[string]map[string]any{}}
+	for _, name := range sortedKeys(object(config["services"])) {
+		service, err := targetStateService(name, object(object(config["services"])[name]))
+		if err != nil {
+			return nil, err
+		}
+		state.Services[name] = service
+	}
+	for name, network := range object(config["networks"]) {
+		state.Networks[name] = object(network)
+	}
+	for name, volume := range object(config["volumes"]) {
+		state.Volumes[name] = object(volume)
+	}
+	return state, nil
+}
+
+func targetStateService(name string, composition map[string]any) (TargetStateService, error) {
+	service := TargetStateService{
+		Image:       text(composition["image"]),
+		Environment: map[string]string{},
+		Labels:      map[string]string{},
+		Composition: composition,
+	}
+	for _, field := range targetStateIgnoredFields {
+		delete(composition, field)
+	}
+	// compose-go outputs null for an unset command and entrypoint, which the supervisor would use to
+	// override those of the image
+	for _, field := range []string{"command", "entrypoint"} {
+		if value, ok := composition[field]; ok && value == nil {
+			delete(composition, field)
+		}
+	}
+
+	for key, value := range object(composition["environment"]) {
+		if value, ok := value.(string); ok {
+			service.Environment[key] = value
+		}
+	}
+	if _, ok := composition["environment"]; ok {
+		composition["environment"] = maps.Clone(service.Environment)
+	}
+	for key, value := range object(composition["labels"]) {
+		service.Labels[key] = text(value)
+	}
+
+	// Networks without options, e.g. the default network, are listed by name
+	if networks := object(composition["networks"]); networks != nil && !slices.ContainsFunc(slices.Collect(maps.Values(networks)), func(network any) bool {
+		return network != nil
+	}) {
+		var short []any
+		for _, network := range sortedKeys(networks) {
+			short = append(short, network)
+		}
+		composition["networks"] = short
+	}
+
+	if ports := array(composition["ports"]); ports != nil {
+		var short []any
+		for _, port := range ports {
+			port := object(port)
+			spec := fmt.Sprint(port["target"])
+			if published := text(port["published"]); published != "" {
+				spec = published + ":" + spec
+			}
+			if hostIP := text(port["host_ip"]); hostIP != "" {
+				spec = hostIP + ":" + spec
+			}
+			if protocol := text(port["protocol"]); protocol != "" && protocol != "tcp" {
+				spec += "/" + protocol
+			}
+			short = append(short, spec)
+		}
+		composition["ports"] = short
+	}
+
+	// Dependencies are listed by name, unless some wait for a condition other than the service starting,
+	// as allowed for newer supervisors
+	if dependsOn := object(composition["depends_on"]); dependsOn != nil {
+		var short []any
+		conditions := map[string]any{}
+		started := true
+		for _, dependency := range sortedKeys(dependsOn) {
+			config := object(dependsOn[dependency])
+			if config["required"] == false {
+				continue
+			}
+			short = append(short, dependency)
+			conditions[dependency] = map[string]any{"condition": config["condition"]}
+			started = started && config["condition"] == types.ServiceConditionStarted
+		}
+		composition["depends_on"] = short
+		if !started {
+			composition["depends_on"] = conditions
+		}
+	}
+
+	if devices := array(composition["devices"]); devices != nil {
+		var short []any
+		for _, device := range devices {
+			device := object(device)
+			short = append(short, fmt.Sprintf("%s:%s:%s", text(device["source"]), text(device["target"]), text(device["permissions"])))
+		}
+		composition["devices"] = short
+	}
+
+	if volumes := array(composition["volumes"]); volumes != nil {
+		var short, tmpfs []any
+		var featureSources []string
+		for _, volume := range volumes {
+			volume := object(volume)
+			source, target := text(volume["source"]), text(volume["target"])
+			switch {
+			case isBalenaFeatureMount(source):
+				featureSources = append(featureSources, source)
+			case volume["type"] == types.VolumeTypeTmpfs:
+				tmpfs = append(tmpfs, target)
+			case volume["type"] == types.VolumeTypeVolume:
+				spec := source + ":" + target
+				if volume["read_only"] == true {
+					spec += ":ro"
+				}
+				short = append(short, spec)
+			default:
+				return service, fmt.Errorf("services.%s.volumes of type %q can't be converted to the target state", name, text(volume["type"]))
+			}
+		}
+		for _, label := range balenaFeatureLabels(featureSources) {
+			service.Labels[label] = "1"
+		}
+		delete(composition, "volumes")
+		if short != nil {
+			composition["volumes"] = short
+		}
+		if tmpfs != nil {
+			composition["tmpfs"] = append(array(composition["tmpfs"]), tmpfs...)
+		}
+	}
+
+	delete(composition, "labels")
+	if len(service.Labels) > 0 {
+		composition["labels"] = maps.Clone(service.Labels)
+	}
+	if len(service.Environment) == 0 {
+		delete(composition, "environment")
+	}
+	if service.Image == "" {
+		delete(composition, "image")
+	}
+	return service, nil
+}
+
+// Versions of the target state schema, the shapes of the target state of successive generations of the supervisor
+const (
+	// TargetStateV1 lists services in name order, each with its name and composition inline
+	TargetStateV1 = "v1"
+	// TargetStateV2 keys services by name, each with its composition inline
+	TargetStateV2 = "v2"
+	// TargetStateV3 keys services by name, each with its composition in a separate field, as in TargetState
+	TargetStateV3 = "v3"
+)
+
+// TargetStateSchemas are the supported schema versions, the latest last
+var TargetStateSchemas = []string{TargetStateV1, TargetStateV2, TargetStateV3}
+
+// Schema returns the target state in the shape of a schema version, for supervisors which don't take
+// the latest. The image, environment and labels of services override those of their composition when
+// it's inline.
+func (state *TargetState) Schema(version string) (any, error) {
+	inline := func(name string) map[string]any {
+		service := state.Services[name]
+		composition := maps.Clone(service.Composition)
+		composition["image"] = service.Image
+		composition["environment"] = service.Environment
+		composition["labels"] = service.Labels
+		return composition
+	}
+	switch version {
+	case TargetStateV3:
+		return state, nil
+	case TargetStateV2:
+		services := map[string]any{}
+		for name := range state.Services {
+			services[name] = inline(name)
+		}
+		return map[string]any{"services": services, "volumes": state.Volumes, "networks": state.Networks}, nil
+	case TargetStateV1:
+		services := []any{}
+		for _, name := range slices.Sorted(maps.Keys(state.Services)) {
+			service := inline(name)
+			service["serviceName"] = name
+			services = append(services, service)
+		}
+		return map[string]any{"services": services, "volumes": state.Volumes, "networks": state.Networks}, nil
+	}
+	return nil, fmt.Errorf("unsupported target state schema %q", version)
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToTargetState(t *testing.T) {
+	project := mustParse(t, Options{BalenaNormalize: true, Environment: map[string]string{"SET": "value"}}, "services:\n"+
+		"  web:\n"+
+		"    image: nginx\n"+
+		"    restart: always\n"+
+		"    expose: [\"80\"]\n"+
+		"    environment:\n      SET: $SET\n      UNSET:\n"+
+		"    labels:\n      com.example.role: frontend\n"+
+		"    ports: [\"8080:80\", \"127.0.0.1:5353:53/udp\", \"9000\"]\n"+
+		"    devices: [\"/dev/ttyUSB0:/dev/ttyUSB0\"]\n"+
+		"    volumes: [\"data:/data:ro\", {type: tmpfs, target: /run}]\n"+
+		"    depends_on: [api]\n"+
+		"  api:\n"+
+		"    build: .\n"+
+		"    command: [serve]\n"+
+		"    networks: [backend]\n"+
+		"    depends_on:\n      db:\n        condition: service_healthy\n"+
+		"  db:\n"+
+		"    image: postgres\n"+
+		"    networks:\n      backend:\n        aliases: [database]\n"+
+		"networks:\n  backend: {}\n"+
+		"volumes:\n  data:\n    driver: local\n",
+	).Project
+	state, err := ToTargetState(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	web := state.Services["web"]
+	if web.Image != "nginx" || !reflect.DeepEqual(web.Environment, map[string]string{"SET": "value"}) || !reflect.DeepEqual(web.Labels, map[string]string{"com.example.role": "frontend"}) {
+		t.Errorf("expected the image, set variables and labels of web, got %+v", web)
+	}
+	expected := map[string]any{
+		"restart":     "always",
+		"environment": map[string]string{"SET": "value"},
+		"labels":      map[string]string{"com.example.role": "frontend"},
+		"image":       "nginx",
+		"ports":       []any{"8080:80", "127.0.0.1:5353:53/udp", "9000"},
+		"devices":     []any{"/dev/ttyUSB0:/dev/ttyUSB0:rwm"},
+		"volumes":     []any{"data:/data:ro"},
+		"tmpfs":       []any{"/run"},
+		"depends_on":  []any{"api"},
+		"networks":    []any{"default"},
+	}
+	if !reflect.DeepEqual(web.Composition, expected) {
+		t.Errorf("expected the composition in the short syntaxes\n%#v, got\n%#v", expected, web.Composition)
+	}
+
+	// Built services have no image until built, and dependencies waiting for a condition keep it
+	api := state.Services["api"]
+	if _, ok := api.Composition["image"]; ok || api.Image != "" || api.Composition["build"] != nil {
+		t.Errorf("expected no image or build for a built service, got %+v", api)
+	}
+	if dependsOn := api.Composition["depends_on"]; !reflect.DeepEqual(dependsOn, map[string]any{"db": map[string]any{"condition": "service_healthy"}}) {
+		t.Errorf("expected the condition of the dependency, got %v", dependsOn)
+	}
+	if !reflect.DeepEqual(api.Composition["command"], []any{"serve"}) || !reflect.DeepEqual(api.Composition["networks"], []any{"backend"}) {
+		t.Errorf("expected the command and networks of api, got %v", api.Composition)
+	}
+	if _, ok := state.Services["db"].Composition["command"]; ok {
+		t.Error("expected an unset command to be removed")
+	}
+	// Networks with options keep the long syntax
+	if networks := state.Services["db"].Composition["networks"]; !reflect.DeepEqual(networks, map[string]any{"backend": map[string]any{"aliases": []any{"database"}}}) {
+		t.Errorf("expected the network options of db, got %v", networks)
+	}
+
+	if _, ok := state.Networks["backend"]; !ok || !reflect.DeepEqual(state.Volumes["data"], map[string]any{"driver": "local"}) {
+		t.Errorf("expected the networks and volumes, got %v and %v", state.Networks, state.Volumes)
+	}
+}
+
+func TestToTargetStateErrors(t *testing.T) {
+	project := mustParse(t, Options{}, "services:\n  web:\n    image: nginx\n    volumes: [\"./data:/data\"]\n").Project
+	if _, err := ToTargetState(project); err == nil {
+		t.Error("expected bind mounts not to be converted")
+	}
+}
+
+func TestTargetStateSchema(t *testing.T) {
+	state := &TargetState{
+		Services: map[string]TargetStateService{
+			"web": {Image: "nginx", Environment: map[string]string{"PORT": "80"}, Labels: map[string]string{"role": "frontend"}, Composition: map[string]any{"image": "nginx:latest", "restart": "always"}},
+			"db":  {Image: "postgres", Environment: map[string]string{}, Labels: map[string]string{}, Composition: map[string]any{}},
+		},
+		Volumes:  map[string]map[string]any{"data": {}},
+		Networks: map[string]map[string]any{"default": {}},
+	}
+	web := map[string]any{"image": "nginx", "restart": "always", "environment": map[string]string{"PORT": "80"}, "labels": map[string]string{"role": "frontend"}}
+	db := map[string]any{"image": "postgres", "environment": map[string]string{}, "labels": map[string]string{}}
+
+	if v3, err := state.Schema(TargetStateV3); err != nil || v3 != state {
+		t.Errorf("expected the target state as is for v3, got %v: %v", v3, err)
+	}
+	v2, err := state.Schema(TargetStateV2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The image, environment and labels of the service override those of its composition
+	expected := map[string]any{"services": map[string]any{"web": web, "db": db}, "volumes": state.Volumes, "networks": state.Networks}
+	if !reflect.DeepEqual(v2, expected) {
+		t.Errorf("expected %v, got %v", expected, v2)
+	}
+	v1, err := state.Schema(TargetStateV1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db["serviceName"], web["serviceName"] = "db", "web"
+	expected = map[string]any{"services": []any{db, web}, "volumes": state.Volumes, "networks": state.Networks}
+	if !reflect.DeepEqual(v1, expected) {
+		t.Errorf("expected services listed in name order, got %v", v1)
+	}
+	if state.Services["web"].Composition["image"] != "nginx:latest" {
+		t.Error("expected the composition of the target state not to be modified")
+	}
+	if _, err := state.Schema("v4"); err == nil || err.Error() != `unsupported target state schema "v4"` {
+		t.Errorf("expected the unsupported schema to be rejected, got %v", err)
+	}
+}
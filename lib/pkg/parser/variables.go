@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/template"
+)
+
+// Variable is a reference to a variable in a compose file
+type Variable struct {
+	// Name is the name of the variable
+	Name string `json:"name"`
+	// Resolved is whether the variable is set, so its value is interpolated rather than the default
+	Resolved bool `json:"resolved"`
+	// DefaultValue is the value interpolated if the variable is unset, e.g. with ${VAR:-default}
+	DefaultValue string `json:"defaultValue,omitempty"`
+	// Required is whether parsing fails if the variable is unset, e.g. with ${VAR:?error}
+	Required bool `json:"required"`
+	// Location is where in the compose files the variable is referenced
+	Location *Location `json:"location,omitempty"`
+}
+
+// variableReference is a variable interpolated in the string value at a YAML path
+type variableReference struct {
+	template.Variable
+	path string
+}
+
+// ListVariables finds every variable referenced in the compose files, and whether it's resolved from the
+// environment and env files. The project isn't loaded, so variables are listed even if required ones are
+// unset, but only from the given compose files and not those they include, extend or fetch from https:// URLs.
+func (p *Parser) ListVariables(ctx context.Context, composeFiles []string) ([]Variable, error) {
+	if len(composeFiles) == 0 {
+		return nil, &Error{Name: ArgumentError, Message: "At least one compose file must be specified"}
+	}
+
+	projectOptions, err := p.environmentOptions(p.options.ProjectDirectory)
+	if err != nil {
+		return nil, err
+	}
+	options, err := cli.NewProjectOptions(composeFiles, projectOptions...)
+	if err != nil {
+		return nil, &Error{
+			Name:    ConfigError,
+			Message: fmt.Sprintf("Failed to create compose project options: %v", err),
+			Err:     err,
+		}
+	}
+
+	variables := []Variable{}
+	for _, composeFile := range composeFiles {
+		if strings.HasPrefix(composeFile, "https://") {
+			continue
+		}
+		var content []byte
+		if composeFile == StdinPath {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			composeFile, _ = filepath.Abs(composeFile)
+			content, err = os.ReadFile(composeFile)
+		}
+		if err != nil {
+			return nil, &Error{Name: IOError, Message: fmt.Sprintf("Failed to read compose file: %v", err), Err: err}
+		}
+		documents, err := decodeDocuments(content)
+		if err != nil {
+			parseErr := &Error{Name: ParseError, Message: fmt.Sprintf("Failed to parse compose file: failed to parse %s: %v", composeFile, err), Err: err}
+			parseErr.Location = locate(parseErr.Message, composeFiles)
+			return nil, parseErr
+		}
+		for _, document := range documents {
+			for _, reference := range variableReferences(document, "") {
+				_, resolved := options.Environment[reference.Name]
+				location := &Location{Path: reference.path}
+				if composeFile != StdinPath {
+					location.File = composeFile
+					if node := findYAMLPath(composeFile, reference.path); node != nil {
+						location.Line, location.Column = node.Line, node.Column
+					}
+				}
+				variables = append(variables, Variable{
+					Name:         reference.Name,
+					Resolved:     resolved,
+					DefaultValue: reference.DefaultValue,
+					Required:     reference.Required,
+					Location:     location,
+				})
+			}
+		}
+	}
+	return variables, nil
+}
+
+// Find the variables interpolated in string values, ordered by path
+func variableReferences(value any, path string) []variableReference {
+	switch value := value.(type) {
+	case string:
+		var references []variableReference
+		variables := template.ExtractVariables(map[string]any{"": value}, template.DefaultPattern)
+		for _, name := range slices.Sorted(maps.Keys(variables)) {
+			references = append(references, variableReference{Variable: variables[name], path: path})
+		}
+		return references
+	case map[string]any:
+		var references []variableReference
+		for _, k := range slices.Sorted(maps.Keys(value)) {
+			references = append(references, variableReferences(value[k], joinPath(path, k))...)
+		}
+		return references
+	case []any:
+		var references []variableReference
+		for i, v := range value {
+			references = append(references, variableReferences(v, joinPath(path, fmt.Sprint(i)))...)
+		}
+		return references
+	default:
+		return nil
+	}
+}
+
+// substitution is a variable substituted by compose-go, with its value if set
+type substitution struct {
+	template.Variable
+	value string
+	set   bool
+}
+
+// compose-go option recording each variable as it's substituted, for Options.StrictEnv and
+// Options.EnvResolution. Variables are recorded as compose-go substitutes them, so those of
+// included and extended compose files are covered too.
+func substitutionRecorder() (cli.ProjectOptionsFn, func() []substitution) {
+	var mu sync.Mutex
+	substitutions := map[substitution]bool{}
+	option := cli.WithLoadOptions(func(options *loader.Options) {
+		if options.Interpolate == nil {
+			return
+		}
+		substitute := options.Interpolate.Substitute
+		options.Interpolate.Substitute = func(value string, mapping template.Mapping) (string, error) {
+			for name, variable := range template.ExtractVariables(map[string]any{"": value}, template.DefaultPattern) {
+				value, set := mapping(name)
+				mu.Lock()
+				substitutions[substitution{Variable: variable, value: value, set: set}] = true
+				mu.Unlock()
+			}
+			return substitute(value, mapping)
+		}
+	})
+	return option, func() []substitution {
+		mu.Lock()
+		defer mu.Unlock()
+		// Substitutions are made in map order, so are sorted for deterministic output
+		return slices.SortedFunc(maps.Keys(substitutions), func(a, b substitution) int {
+			return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.DefaultValue, b.DefaultValue), cmp.Compare(a.PresenceValue, b.PresenceValue))
+		})
+	}
+}
+
+// Names of the substituted variables which are unset and have no default
+func unsetVariables(substitutions []substitution) []string {
+	var names []string
+	for _, s := range substitutions {
+		// Required variables already fail to parse, and ${VAR:+value} is only substituted if set
+		if s.set || s.DefaultValue != "" || s.PresenceValue != "" || s.Required {
+			continue
+		}
+		if !slices.Contains(names, s.Name) {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// Create the error for variables which are unset with Options.StrictEnv, listing each variable
+// at its first reference in the local compose files
+func unsetVariablesError(names []string, composeFiles []string) *Error {
+	locations := map[string]*Location{}
+	for _, composeFile := range composeFiles {
+		if composeFile == StdinPath || strings.HasPrefix(composeFile, "https://") {
+			continue
+		}
+		composeFile, _ = filepath.Abs(composeFile)
+		content, err := os.ReadFile(composeFile)
+		if err != nil {
+			continue
+		}
+		// The project loaded, so malformed documents can't occur
+		documents, _ := decodeDocuments(content)
+		for _, document := range documents {
+			for _, reference := range variableReferences(document, "") {
+				if locations[reference.Name] != nil {
+					continue
+				}
+				location := &Location{File: composeFile, Path: reference.path}
+				if node := findYAMLPath(composeFile, reference.path); node != nil {
+					location.Line, location.Column = node.Line, node.Column
+				}
+				locations[reference.Name] = location
+			}
+		}
+	}
+
+	err := &Error{
+		Name:    ParseError,
+		Message: fmt.Sprintf("Failed to parse compose file: variables are not set and have no default value: %s", strings.Join(names, ", ")),
+	}
+	for _, name := range names {
+		err.Errors = append(err.Errors, &Error{
+			Name:     ParseError,
+			Message:  fmt.Sprintf("The %q variable is not set and has no default value", name),
+			Location: locations[name],
+		})
+	}
+	err.Location = err.Errors[0].Location
+	return err
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestListVariables(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml":          "services:\n  web:\n    image: nginx:${TAG:-latest}\n    environment:\n      UUID: ${UUID:?device UUID is required}\n      URL: https://${HOST}:${PORT-80}\n",
+		"compose.override.yml": "services:\n  db:\n    image: postgres:${PG_VERSION}\n---\nservices:\n  db:\n    command: [\"$${ESCAPED}\"]\n",
+		"build.env":            "HOST=example.com\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+	override := filepath.Join(dir, "compose.override.yml")
+
+	p := New(Options{ProjectName: "test", EnvFiles: []string{filepath.Join(dir, "build.env")}, NoOSEnv: true, Environment: map[string]string{"PG_VERSION": "16"}})
+	variables, err := p.ListVariables(context.Background(), []string{composeFile, override})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Variable{
+		{Name: "HOST", Resolved: true, Location: &Location{File: composeFile, Line: 6, Column: 7, Path: "services.web.environment.URL"}},
+		{Name: "PORT", DefaultValue: "80", Location: &Location{File: composeFile, Line: 6, Column: 7, Path: "services.web.environment.URL"}},
+		{Name: "UUID", Required: true, Location: &Location{File: composeFile, Line: 5, Column: 7, Path: "services.web.environment.UUID"}},
+		{Name: "TAG", DefaultValue: "latest", Location: &Location{File: composeFile, Line: 3, Column: 5, Path: "services.web.image"}},
+		{Name: "PG_VERSION", Resolved: true, Location: &Location{File: override, Line: 3, Column: 5, Path: "services.db.image"}},
+	}
+	if !reflect.DeepEqual(variables, expected) {
+		for _, variable := range variables {
+			t.Logf("%+v at %+v", variable, variable.Location)
+		}
+		t.Error("expected the variables of each compose file in path order")
+	}
+
+	variables, err = p.ListVariables(context.Background(), []string{filepath.Join(dir, "build.env")})
+	if err != nil || len(variables) != 0 {
+		t.Errorf("expected no variables, got %+v: %v", variables, err)
+	}
+}
+
+func TestListVariablesErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"compose.yml": "services: [\n"})
+	p := New(Options{ProjectName: "test"})
+
+	_, err := p.ListVariables(context.Background(), nil)
+	expectError(t, err, ArgumentError, "At least one compose file must be specified")
+	_, err = p.ListVariables(context.Background(), []string{filepath.Join(dir, "missing.yml")})
+	expectError(t, err, IOError, "Failed to read compose file")
+	_, err = p.ListVariables(context.Background(), []string{filepath.Join(dir, "compose.yml")})
+	if parserErr := expectError(t, err, ParseError, "failed to parse"); parserErr.Location == nil || parserErr.Location.Line == 0 {
+		t.Errorf("expected the parse error to be located, got %+v", parserErr.Location)
+	}
+}
+
+func TestStrictEnv(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"compose.yml": "include:\n  - db.yml\nservices:\n  web:\n    image: nginx:${TAG}\n    command: [\"${DEFAULTED:-default}\", \"${PRESENT:+value}\", \"${EMPTY}\", \"${UNSET}\", \"${TAG}\"]\n",
+		"db.yml":      "services:\n  db:\n    image: postgres:${PG_VERSION}\n",
+	})
+	composeFile := filepath.Join(dir, "compose.yml")
+
+	_, err := New(Options{ProjectName: "test", StrictEnv: true, NoOSEnv: true, Environment: map[string]string{"EMPTY": ""}}).Parse(context.Background(), []string{composeFile})
+	parserErr := expectError(t, err, ParseError, "variables are not set and have no default value: PG_VERSION, TAG, UNSET")
+	// Variables are located at their first reference in path order, and those of included files only if
+	// referenced in the given compose files
+	expected := []struct {
+		message string
+		path    string
+	}{
+		{message: `The "PG_VERSION" variable is not set and has no default value`},
+		{message: `The "TAG" variable is not set and has no default value`, path: "services.web.command.4"},
+		{message: `The "UNSET" variable is not set and has no default value`, path: "services.web.command.3"},
+	}
+	if len(parserErr.Errors) != len(expected) {
+		t.Fatalf("expected an error for each unset variable, got %v", parserErr.Errors)
+	}
+	for i, e := range parserErr.Errors {
+		if e.Name != ParseError || e.Message != expected[i].message {
+			t.Errorf("expected %q, got %s: %q", expected[i].message, e.Name, e.Message)
+		}
+		if expected[i].path == "" {
+			if e.Location != nil {
+				t.Errorf("expected %q not to be located, got %+v", e.Message, e.Location)
+			}
+		} else if e.Location == nil || e.Location.Path != expected[i].path || e.Location.File != composeFile {
+			t.Errorf("expected %q to be located at %s, got %+v", e.Message, expected[i].path, e.Location)
+		}
+	}
+	if parserErr.Location != parserErr.Errors[0].Location {
+		t.Errorf("expected the error to be located at the first variable, got %+v", parserErr.Location)
+	}
+
+	result, err := New(Options{ProjectName: "test", StrictEnv: true, NoOSEnv: true, Environment: map[string]string{"TAG": "1.25", "EMPTY": "", "UNSET": "", "PG_VERSION": "16"}}).Parse(context.Background(), []string{composeFile})
+	if err != nil {
+		t.Fatalf("expected the parse to succeed once every variable is set, got %v", err)
+	}
+	if image := result.Project.Services["web"].Image; image != "nginx:1.25" {
+		t.Errorf("expected nginx:1.25, got %s", image)
+	}
+}
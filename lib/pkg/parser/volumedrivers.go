@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Codes of the issues of the driver options of top-level volumes found validating for BalenaTarget
+const (
+	// InvalidDriverOptionCode is reported for driver_opts the local driver rejects when the supervisor
+	// creates the volume, or whose mount fails when a container starts
+	InvalidDriverOptionCode = "invalid-driver-option"
+	// IgnoredDriverOptionCode is reported for mount options of driver_opts.o which have no effect
+	IgnoredDriverOptionCode = "ignored-driver-option"
+)
+
+// The driver_opts of the local volume driver, which rejects any other
+var localDriverOptions = []string{"type", "o", "device", "size"}
+
+// Mount options of bind mounts, which ignore any other, e.g. uid=1000
+var bindMountOptions = []string{
+	"bind", "rbind", "ro", "rw", "nosuid", "suid", "nodev", "dev", "noexec", "exec", "private", "rprivate", "shared",
+	"rshared", "slave", "rslave", "unbindable", "runbindable",
+}
+
+// The issues of the driver options of a top-level volume of the local driver, the only one of balenaOS:
+// unknown options, size, which requires quotas the data partition doesn't enable, mounts without a type
+// or device, and bind mounts of relative paths or with options bind mounts ignore
+func volumeDriverIssues(path string, volume map[string]any) []targetIssue {
+	var issues []targetIssue
+	fail := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(code, path, format string, args ...any) {
+		issues = append(issues, targetIssue{code: code, path: path, message: fmt.Sprintf(format, args...), warning: true})
+	}
+	opts := object(volume["driver_opts"])
+	if len(opts) == 0 {
+		return nil
+	}
+	optsPath := path + ".driver_opts"
+	for _, key := range sortedKeys(opts) {
+		if !slices.Contains(localDriverOptions, key) {
+			fail(InvalidDriverOptionCode, optsPath+"."+key, "%s: the local driver has no option %q, expected one of: %s", optsPath, key, strings.Join(localDriverOptions, ", "))
+		}
+	}
+	if _, ok := opts["size"]; ok {
+		fail(InvalidDriverOptionCode, optsPath+".size", "%s.size requires quotas, which the data partition of balenaOS doesn't enable", optsPath)
+	}
+	mountType, device, o := text(opts["type"]), text(opts["device"]), text(opts["o"])
+	if mountType == "" && device == "" && o == "" {
+		return issues
+	}
+	if mountType == "" {
+		fail(InvalidDriverOptionCode, optsPath, "%s: type must be set to mount the volume, e.g. none for bind mounts", optsPath)
+	}
+	if device == "" {
+		fail(InvalidDriverOptionCode, optsPath, "%s: device must be set to mount the volume, e.g. the host path of bind mounts", optsPath)
+	}
+	mountOptions := strings.Split(o, ",")
+	if !slices.Contains(mountOptions, "bind") && !slices.Contains(mountOptions, "rbind") {
+		if mountType == "none" {
+			fail(InvalidDriverOptionCode, optsPath+".o", "%s.o must include bind for volumes of type none", optsPath)
+		}
+		return issues
+	}
+	if device != "" && !strings.HasPrefix(device, "/") {
+		fail(InvalidDriverOptionCode, optsPath+".device", "%s.device must be an absolute host path for bind mounts, got %q", optsPath, device)
+	}
+	for _, option := range mountOptions {
+		if option != "" && !slices.Contains(bindMountOptions, option) {
+			warn(IgnoredDriverOptionCode, optsPath+".o", "%s.o: %s has no effect on bind mounts", optsPath, option)
+		}
+	}
+	return issues
+}
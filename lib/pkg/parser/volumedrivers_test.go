@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVolumeDriverOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		volume   string
+		errors   []string
+		warnings []string
+	}{
+		{name: "no options", volume: "driver: local\n"},
+		{name: "bind mount", volume: "driver_opts:\n      type: none\n      o: bind,ro\n      device: /mnt/data\n"},
+		{name: "tmpfs", volume: "driver_opts:\n      type: tmpfs\n      o: size=100m,uid=1000\n      device: tmpfs\n"},
+		{
+			name:   "unknown option",
+			volume: "driver_opts:\n      mountpoint: /mnt/data\n",
+			errors: []string{"invalid-driver-option volumes.data.driver_opts.mountpoint"},
+		},
+		{
+			name:   "size",
+			volume: "driver_opts:\n      size: 10G\n",
+			errors: []string{"invalid-driver-option volumes.data.driver_opts.size"},
+		},
+		{
+			name:   "missing type and device",
+			volume: "driver_opts:\n      o: bind\n",
+			errors: []string{"invalid-driver-option volumes.data.driver_opts", "invalid-driver-option volumes.data.driver_opts"},
+		},
+		{
+			name:   "type none without bind",
+			volume: "driver_opts:\n      type: none\n      o: ro\n      device: /mnt/data\n",
+			errors: []string{"invalid-driver-option volumes.data.driver_opts.o"},
+		},
+		{
+			name:   "relative device",
+			volume: "driver_opts:\n      type: none\n      o: bind\n      device: ./data\n",
+			errors: []string{"invalid-driver-option volumes.data.driver_opts.device"},
+		},
+		{
+			name:     "ignored mount options",
+			volume:   "driver_opts:\n      type: none\n      o: bind,uid=1000\n      device: /mnt/data\n",
+			warnings: []string{"ignored-driver-option volumes.data.driver_opts.o"},
+		},
+		{
+			name:   "other driver",
+			volume: "driver: nfs\n    driver_opts:\n      size: 10G\n",
+			errors: []string{"unsupported-value volumes.data.driver"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, warnings := targetIssues(t, Options{Target: BalenaTarget}, "services:\n  web:\n    image: nginx\n    volumes: [data:/data]\nvolumes:\n  data:\n    "+tt.volume)
+			if strings.Join(errs, ", ") != strings.Join(tt.errors, ", ") {
+				t.Errorf("expected the errors %v, got %v", tt.errors, errs)
+			}
+			var driverWarnings []string
+			for _, warning := range warnings {
+				if strings.HasPrefix(warning, IgnoredDriverOptionCode) {
+					driverWarnings = append(driverWarnings, warning)
+				}
+			}
+			if strings.Join(driverWarnings, ", ") != strings.Join(tt.warnings, ", ") {
+				t.Errorf("expected the warnings %v, got %v", tt.warnings, driverWarnings)
+			}
+		})
+	}
+
+	_, err := parse(t, Options{Target: BalenaTarget}, "services:\n  web:\n    image: nginx\nvolumes:\n  data:\n    driver_opts:\n      type: none\n      o: bind\n      device: data\n")
+	expectError(t, err, ValidationError, `volumes.data.driver_opts.device must be an absolute host path for bind mounts, got "data"`)
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Warning codes, identifying the kind of non-fatal issue
+const (
+	// ObsoleteVersionWarning is reported for the top-level version attribute, which compose-go ignores
+	ObsoleteVersionWarning = "obsolete-version"
+	// DeprecatedExternalNameWarning is reported for external.name, superseded by name with external: true
+	DeprecatedExternalNameWarning = "deprecated-external-name"
+	// DeprecatedFieldWarning is reported for other fields which are deprecated, e.g. links
+	DeprecatedFieldWarning = "deprecated-field"
+	// CoercedValueWarning is reported for unquoted values of environment, labels and the other string
+	// mappings which YAML coerces into another string than written, e.g. 0755 into 493
+	CoercedValueWarning = "coerced-value"
+	// UnsetVariableWarning is reported for variables which are interpolated with a default because they're unset
+	UnsetVariableWarning = "unset-variable"
+)
+
+// Warning is a non-fatal issue found while parsing a composition
+type Warning struct {
+	// Code identifies the kind of issue, e.g. UnsetVariableWarning
+	Code string `json:"code"`
+	// Message is the human readable description of the issue
+	Message string `json:"message"`
+	// Location is where in the compose files the issue was found, if known
+	Location *Location `json:"location,omitempty"`
+	// Replacement is what to use instead of an obsolete or deprecated field, or of a value YAML coerces,
+	// if any
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Top-level resources which may be declared external
+var externalResources = []string{"networks", "volumes", "configs", "secrets"}
+
+// compose-go only logs warnings, which can't be attributed to a parse when several run concurrently,
+// so they're found by checking each local compose file again
+func collectWarnings(composeFiles []string, environment types.Mapping, interpolation bool) []Warning {
+	var warnings []Warning
+	for _, composeFile := range composeFiles {
+		if composeFile == StdinPath || strings.HasPrefix(composeFile, "https://") {
+			continue
+		}
+		if abs, err := filepath.Abs(composeFile); err == nil {
+			composeFile = abs
+		}
+		content, err := os.ReadFile(composeFile)
+		if err != nil {
+			continue
+		}
+		// The project loaded, so malformed documents can't occur
+		documents, _ := decodeDocuments(content)
+		for _, document := range documents {
+			config, ok := document.(map[string]any)
+			if !ok {
+				continue
+			}
+			for _, warning := range documentWarnings(config, environment, interpolation) {
+				warning.Location.File = composeFile
+				if node := findYAMLPath(composeFile, warning.Location.Path); node != nil {
+					warning.Location.Line, warning.Location.Column = node.Line, node.Column
+				}
+				warnings = append(warnings, warning)
+			}
+		}
+		for _, warning := range coercedValueWarnings(content) {
+			warning.Location.File = composeFile
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+func documentWarnings(config map[string]any, environment types.Mapping, interpolation bool) []Warning {
+	var warnings []Warning
+	if _, ok := config["version"]; ok {
+		warnings = append(warnings, Warning{
+			Code:        ObsoleteVersionWarning,
+			Message:     "the attribute `version` is obsolete, it will be ignored, please remove it to avoid potential confusion",
+			Location:    &Location{Path: "version"},
+			Replacement: "no version, as the compose spec isn't versioned",
+		})
+	}
+
+	for _, resourceType := range externalResources {
+		resources, _ := config[resourceType].(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(resources)) {
+			resource, _ := resources[name].(map[string]any)
+			if external, ok := resource["external"].(map[string]any); ok {
+				if _, ok := external["name"]; ok {
+					path := fmt.Sprintf("%s.%s.external.name", resourceType, name)
+					warnings = append(warnings, Warning{
+						Code:        DeprecatedExternalNameWarning,
+						Message:     fmt.Sprintf("%s.%s: external.name is deprecated. Please set name and external: true", resourceType, name),
+						Location:    &Location{Path: path},
+						Replacement: "name with external: true",
+					})
+				}
+			}
+		}
+	}
+
+	warnings = append(warnings, deprecatedFieldWarnings(config)...)
+
+	if !interpolation {
+		return warnings
+	}
+	return append(warnings, unsetVariableWarnings(config, environment)...)
+}
+
+// Find variables interpolated in string values which are unset, so are replaced with their default or a blank string
+func unsetVariableWarnings(config map[string]any, environment types.Mapping) []Warning {
+	var warnings []Warning
+	for _, reference := range variableReferences(config, "") {
+		// Required variables fail to parse, and ${VAR:+value} is only substituted if set
+		if _, ok := environment[reference.Name]; ok || reference.Required || reference.PresenceValue != "" {
+			continue
+		}
+		message := fmt.Sprintf("The %q variable is not set. Defaulting to a blank string.", reference.Name)
+		if reference.DefaultValue != "" {
+			message = fmt.Sprintf("The %q variable is not set. Defaulting to %q.", reference.Name, reference.DefaultValue)
+		}
+		warnings = append(warnings, Warning{Code: UnsetVariableWarning, Message: message, Location: &Location{Path: reference.path}})
+	}
+	return warnings
+}
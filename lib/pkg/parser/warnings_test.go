@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	result := mustParse(t, Options{Warnings: true, Environment: map[string]string{"SET": "1.25"}},
+		"version: \"3.8\"\nservices:\n  web:\n    image: nginx:${SET}\n    command: [\"${UNSET}\", \"${DEFAULTED:-default}\", \"${PRESENT:+value}\"]\nnetworks:\n  front:\n    external:\n      name: front\n",
+		"services:\n  web:\n    environment:\n      A: ${OVERRIDE_UNSET}\n",
+	)
+	expected := []struct {
+		code    string
+		message string
+		file    string
+		path    string
+		line    int
+	}{
+		{code: ObsoleteVersionWarning, message: "the attribute `version` is obsolete, it will be ignored, please remove it to avoid potential confusion", file: "compose.yml", path: "version", line: 1},
+		{code: DeprecatedExternalNameWarning, message: "networks.front: external.name is deprecated. Please set name and external: true", file: "compose.yml", path: "networks.front.external.name", line: 9},
+		{code: UnsetVariableWarning, message: `The "UNSET" variable is not set. Defaulting to a blank string.`, file: "compose.yml", path: "services.web.command.0", line: 5},
+		{code: UnsetVariableWarning, message: `The "DEFAULTED" variable is not set. Defaulting to "default".`, file: "compose.yml", path: "services.web.command.1", line: 5},
+		{code: UnsetVariableWarning, message: `The "OVERRIDE_UNSET" variable is not set. Defaulting to a blank string.`, file: "compose-1.yml", path: "services.web.environment.A", line: 4},
+	}
+	if len(result.Warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, got %+v", len(expected), result.Warnings)
+	}
+	for i, warning := range result.Warnings {
+		location := warning.Location
+		if warning.Code != expected[i].code || warning.Message != expected[i].message {
+			t.Errorf("expected the %s warning %q, got %s: %q", expected[i].code, expected[i].message, warning.Code, warning.Message)
+		}
+		if location == nil || filepath.Base(location.File) != expected[i].file || location.Path != expected[i].path || location.Line != expected[i].line {
+			t.Errorf("expected warning %d to be located at %s:%d %s, got %+v", i, expected[i].file, expected[i].line, expected[i].path, location)
+		}
+	}
+	if result.Warnings[0].Replacement == "" || result.Warnings[1].Replacement != "name with external: true" {
+		t.Errorf("expected the replacements of the obsolete and deprecated fields, got %+v", result.Warnings[:2])
+	}
+
+	if result := mustParse(t, Options{}, "version: \"3.8\"\nservices:\n  web:\n    image: nginx:${UNSET}\n"); result.Warnings != nil {
+		t.Errorf("expected no warnings without Options.Warnings, got %+v", result.Warnings)
+	}
+}
+
+func TestWarningsWithoutInterpolation(t *testing.T) {
+	// Variables are kept as is, so aren't defaulted
+	result := mustParse(t, Options{Warnings: true, NoInterpolate: true}, "services:\n  web:\n    image: nginx:${UNSET}\n")
+	for _, warning := range result.Warnings {
+		if warning.Code == UnsetVariableWarning {
+			t.Errorf("expected no unset variable warnings, got %+v", warning)
+		}
+	}
+}
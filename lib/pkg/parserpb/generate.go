@@ -0,0 +1,4 @@
+// Package parserpb contains the protobuf and gRPC bindings generated from lib/proto/parser.proto and lib/proto/project.proto
+package parserpb
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative parser.proto project.proto
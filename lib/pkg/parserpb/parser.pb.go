@@ -0,0 +1,433 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.28.3
+// source: parser.proto
+
+package parserpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// An in-memory compose file
+type ComposeFile struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// File name, used for error messages and relative path resolution
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Raw compose YAML
+	Content       []byte `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComposeFile) Reset() {
+	*x = ComposeFile{}
+	mi := &file_parser_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComposeFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComposeFile) ProtoMessage() {}
+
+func (x *ComposeFile) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComposeFile.ProtoReflect.Descriptor instead.
+func (*ComposeFile) Descriptor() ([]byte, []int) {
+	return file_parser_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ComposeFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ComposeFile) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+type ParseRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the project to use for the parsed output
+	ProjectName string `protobuf:"bytes,1,opt,name=project_name,json=projectName,proto3" json:"project_name,omitempty"`
+	// Compose files, with later files overriding earlier ones
+	Files []*ComposeFile `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+	// Maximum time to spend parsing, defaulting to the server's --timeout
+	Timeout *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// Emit canonical JSON, with sorted keys and no insignificant whitespace
+	Canonical     bool `protobuf:"varint,4,opt,name=canonical,proto3" json:"canonical,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseRequest) Reset() {
+	*x = ParseRequest{}
+	mi := &file_parser_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseRequest) ProtoMessage() {}
+
+func (x *ParseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseRequest.ProtoReflect.Descriptor instead.
+func (*ParseRequest) Descriptor() ([]byte, []int) {
+	return file_parser_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ParseRequest) GetProjectName() string {
+	if x != nil {
+		return x.ProjectName
+	}
+	return ""
+}
+
+func (x *ParseRequest) GetFiles() []*ComposeFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ParseRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *ParseRequest) GetCanonical() bool {
+	if x != nil {
+		return x.Canonical
+	}
+	return false
+}
+
+// Error returned when parsing fails, mirroring the JSON error responses of the CLI
+type Error struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Error category, e.g. "ParseError"
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Human readable description of the error
+	Message       string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_parser_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_parser_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Error) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type ParseResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*ParseResponse_ProjectJson
+	//	*ParseResponse_Error
+	Result        isParseResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseResponse) Reset() {
+	*x = ParseResponse{}
+	mi := &file_parser_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseResponse) ProtoMessage() {}
+
+func (x *ParseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseResponse.ProtoReflect.Descriptor instead.
+func (*ParseResponse) Descriptor() ([]byte, []int) {
+	return file_parser_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ParseResponse) GetResult() isParseResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ParseResponse) GetProjectJson() []byte {
+	if x != nil {
+		if x, ok := x.Result.(*ParseResponse_ProjectJson); ok {
+			return x.ProjectJson
+		}
+	}
+	return nil
+}
+
+func (x *ParseResponse) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*ParseResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isParseResponse_Result interface {
+	isParseResponse_Result()
+}
+
+type ParseResponse_ProjectJson struct {
+	// Normalized project as JSON
+	ProjectJson []byte `protobuf:"bytes,1,opt,name=project_json,json=projectJson,proto3,oneof"`
+}
+
+type ParseResponse_Error struct {
+	Error *Error `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*ParseResponse_ProjectJson) isParseResponse_Result() {}
+
+func (*ParseResponse_Error) isParseResponse_Result() {}
+
+type ValidateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Valid bool                   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// Set if the composition is invalid
+	Error         *Error `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateResponse) Reset() {
+	*x = ValidateResponse{}
+	mi := &file_parser_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateResponse) ProtoMessage() {}
+
+func (x *ValidateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_parser_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateResponse.ProtoReflect.Descriptor instead.
+func (*ValidateResponse) Descriptor() ([]byte, []int) {
+	return file_parser_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ValidateResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateResponse) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+var File_parser_proto protoreflect.FileDescriptor
+
+const file_parser_proto_rawDesc = "" +
+	"\n" +
+	"\fparser.proto\x12\x17balena.composeparser.v1\x1a\x1egoogle/protobuf/duration.proto\";\n" +
+	"\vComposeFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\fR\acontent\"\xc0\x01\n" +
+	"\fParseRequest\x12!\n" +
+	"\fproject_name\x18\x01 \x01(\tR\vprojectName\x12:\n" +
+	"\x05files\x18\x02 \x03(\v2$.balena.composeparser.v1.ComposeFileR\x05files\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12\x1c\n" +
+	"\tcanonical\x18\x04 \x01(\bR\tcanonical\"5\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"v\n" +
+	"\rParseResponse\x12#\n" +
+	"\fproject_json\x18\x01 \x01(\fH\x00R\vprojectJson\x126\n" +
+	"\x05error\x18\x02 \x01(\v2\x1e.balena.composeparser.v1.ErrorH\x00R\x05errorB\b\n" +
+	"\x06result\"^\n" +
+	"\x10ValidateResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x124\n" +
+	"\x05error\x18\x02 \x01(\v2\x1e.balena.composeparser.v1.ErrorR\x05error2\xc5\x01\n" +
+	"\rComposeParser\x12V\n" +
+	"\x05Parse\x12%.balena.composeparser.v1.ParseRequest\x1a&.balena.composeparser.v1.ParseResponse\x12\\\n" +
+	"\bValidate\x12%.balena.composeparser.v1.ParseRequest\x1a).balena.composeparser.v1.ValidateResponseB$Z\"balena-compose-parser/pkg/parserpbb\x06proto3"
+
+var (
+	file_parser_proto_rawDescOnce sync.Once
+	file_parser_proto_rawDescData []byte
+)
+
+func file_parser_proto_rawDescGZIP() []byte {
+	file_parser_proto_rawDescOnce.Do(func() {
+		file_parser_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_parser_proto_rawDesc), len(file_parser_proto_rawDesc)))
+	})
+	return file_parser_proto_rawDescData
+}
+
+var file_parser_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_parser_proto_goTypes = []any{
+	(*ComposeFile)(nil),         // 0: balena.composeparser.v1.ComposeFile
+	(*ParseRequest)(nil),        // 1: balena.composeparser.v1.ParseRequest
+	(*Error)(nil),               // 2: balena.composeparser.v1.Error
+	(*ParseResponse)(nil),       // 3: balena.composeparser.v1.ParseResponse
+	(*ValidateResponse)(nil),    // 4: balena.composeparser.v1.ValidateResponse
+	(*durationpb.Duration)(nil), // 5: google.protobuf.Duration
+}
+var file_parser_proto_depIdxs = []int32{
+	0, // 0: balena.composeparser.v1.ParseRequest.files:type_name -> balena.composeparser.v1.ComposeFile
+	5, // 1: balena.composeparser.v1.ParseRequest.timeout:type_name -> google.protobuf.Duration
+	2, // 2: balena.composeparser.v1.ParseResponse.error:type_name -> balena.composeparser.v1.Error
+	2, // 3: balena.composeparser.v1.ValidateResponse.error:type_name -> balena.composeparser.v1.Error
+	1, // 4: balena.composeparser.v1.ComposeParser.Parse:input_type -> balena.composeparser.v1.ParseRequest
+	1, // 5: balena.composeparser.v1.ComposeParser.Validate:input_type -> balena.composeparser.v1.ParseRequest
+	3, // 6: balena.composeparser.v1.ComposeParser.Parse:output_type -> balena.composeparser.v1.ParseResponse
+	4, // 7: balena.composeparser.v1.ComposeParser.Validate:output_type -> balena.composeparser.v1.ValidateResponse
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_parser_proto_init() }
+func file_parser_proto_init() {
+	if File_parser_proto != nil {
+		return
+	}
+	file_parser_proto_msgTypes[3].OneofWrappers = []any{
+		(*ParseResponse_ProjectJson)(nil),
+		(*ParseResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_parser_proto_rawDesc), len(file_parser_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_parser_proto_goTypes,
+		DependencyIndexes: file_parser_proto_depIdxs,
+		MessageInfos:      file_parser_proto_msgTypes,
+	}.Build()
+	File_parser_proto = out.File
+	file_parser_proto_goTypes = nil
+	file_parser_proto_depIdxs = nil
+}
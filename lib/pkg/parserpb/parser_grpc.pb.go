@@ -0,0 +1,167 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: parser.proto
+
+package parserpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ComposeParser_Parse_FullMethodName    = "/balena.composeparser.v1.ComposeParser/Parse"
+	ComposeParser_Validate_FullMethodName = "/balena.composeparser.v1.ComposeParser/Validate"
+)
+
+// ComposeParserClient is the client API for ComposeParser service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ComposeParser parses docker-compose files into a normalized project
+type ComposeParserClient interface {
+	// Parse compose files and return the normalized project
+	Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error)
+	// Validate compose files without returning the normalized project
+	Validate(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
+}
+
+type composeParserClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewComposeParserClient(cc grpc.ClientConnInterface) ComposeParserClient {
+	return &composeParserClient{cc}
+}
+
+func (c *composeParserClient) Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ParseResponse)
+	err := c.cc.Invoke(ctx, ComposeParser_Parse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *composeParserClient) Validate(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, ComposeParser_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ComposeParserServer is the server API for ComposeParser service.
+// All implementations must embed UnimplementedComposeParserServer
+// for forward compatibility.
+//
+// ComposeParser parses docker-compose files into a normalized project
+type ComposeParserServer interface {
+	// Parse compose files and return the normalized project
+	Parse(context.Context, *ParseRequest) (*ParseResponse, error)
+	// Validate compose files without returning the normalized project
+	Validate(context.Context, *ParseRequest) (*ValidateResponse, error)
+	mustEmbedUnimplementedComposeParserServer()
+}
+
+// UnimplementedComposeParserServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedComposeParserServer struct{}
+
+func (UnimplementedComposeParserServer) Parse(context.Context, *ParseRequest) (*ParseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Parse not implemented")
+}
+func (UnimplementedComposeParserServer) Validate(context.Context, *ParseRequest) (*ValidateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
+}
+func (UnimplementedComposeParserServer) mustEmbedUnimplementedComposeParserServer() {}
+func (UnimplementedComposeParserServer) testEmbeddedByValue()                       {}
+
+// UnsafeComposeParserServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ComposeParserServer will
+// result in compilation errors.
+type UnsafeComposeParserServer interface {
+	mustEmbedUnimplementedComposeParserServer()
+}
+
+func RegisterComposeParserServer(s grpc.ServiceRegistrar, srv ComposeParserServer) {
+	// If the following call pancis, it indicates UnimplementedComposeParserServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ComposeParser_ServiceDesc, srv)
+}
+
+func _ComposeParser_Parse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComposeParserServer).Parse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ComposeParser_Parse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComposeParserServer).Parse(ctx, req.(*ParseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ComposeParser_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ParseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComposeParserServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ComposeParser_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComposeParserServer).Validate(ctx, req.(*ParseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ComposeParser_ServiceDesc is the grpc.ServiceDesc for ComposeParser service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ComposeParser_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "balena.composeparser.v1.ComposeParser",
+	HandlerType: (*ComposeParserServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Parse",
+			Handler:    _ComposeParser_Parse_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _ComposeParser_Validate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "parser.proto",
+}
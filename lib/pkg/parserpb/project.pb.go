@@ -0,0 +1,3887 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v5.28.3
+// source: project.proto
+
+package parserpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// A normalized project, as output with --output-format proto or proto-text. Field names are those of the
+// compose spec, and x- extensions are kept as JSON values. Fields without an effect on balena devices, e.g.
+// develop and the swarm fields of deploy, are omitted, and projects setting them fail to be output.
+type Project struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the project, unset with --balena-normalize
+	Name          string                     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Services      map[string]*Service        `protobuf:"bytes,2,rep,name=services,proto3" json:"services,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Networks      map[string]*Network        `protobuf:"bytes,3,rep,name=networks,proto3" json:"networks,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Volumes       map[string]*Volume         `protobuf:"bytes,4,rep,name=volumes,proto3" json:"volumes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Configs       map[string]*FileObject     `protobuf:"bytes,5,rep,name=configs,proto3" json:"configs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Secrets       map[string]*FileObject     `protobuf:"bytes,6,rep,name=secrets,proto3" json:"secrets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Extensions    map[string]*structpb.Value `protobuf:"bytes,7,rep,name=extensions,proto3" json:"extensions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Project) Reset() {
+	*x = Project{}
+	mi := &file_project_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Project) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Project) ProtoMessage() {}
+
+func (x *Project) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Project.ProtoReflect.Descriptor instead.
+func (*Project) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Project) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Project) GetServices() map[string]*Service {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *Project) GetNetworks() map[string]*Network {
+	if x != nil {
+		return x.Networks
+	}
+	return nil
+}
+
+func (x *Project) GetVolumes() map[string]*Volume {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+func (x *Project) GetConfigs() map[string]*FileObject {
+	if x != nil {
+		return x.Configs
+	}
+	return nil
+}
+
+func (x *Project) GetSecrets() map[string]*FileObject {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+func (x *Project) GetExtensions() map[string]*structpb.Value {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+type Service struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Image string                 `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Build *Build                 `protobuf:"bytes,2,opt,name=build,proto3" json:"build,omitempty"`
+	// Unset to keep the command of the image, and set without arguments to clear it
+	Command *Command `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	// Unset to keep the entrypoint of the image, and set without arguments to clear it
+	Entrypoint *Command `protobuf:"bytes,4,opt,name=entrypoint,proto3" json:"entrypoint,omitempty"`
+	// Variables with a value
+	Environment map[string]string `protobuf:"bytes,5,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Names of the variables without a value, taken from the environment of the engine
+	UnsetEnvironment  []string                   `protobuf:"bytes,6,rep,name=unset_environment,json=unsetEnvironment,proto3" json:"unset_environment,omitempty"`
+	Labels            map[string]string          `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Ports             []*Port                    `protobuf:"bytes,8,rep,name=ports,proto3" json:"ports,omitempty"`
+	Expose            []string                   `protobuf:"bytes,9,rep,name=expose,proto3" json:"expose,omitempty"`
+	Volumes           []*ServiceVolume           `protobuf:"bytes,10,rep,name=volumes,proto3" json:"volumes,omitempty"`
+	Networks          map[string]*ServiceNetwork `protobuf:"bytes,11,rep,name=networks,proto3" json:"networks,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	DependsOn         map[string]*Dependency     `protobuf:"bytes,12,rep,name=depends_on,json=dependsOn,proto3" json:"depends_on,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Restart           string                     `protobuf:"bytes,13,opt,name=restart,proto3" json:"restart,omitempty"`
+	Privileged        bool                       `protobuf:"varint,14,opt,name=privileged,proto3" json:"privileged,omitempty"`
+	NetworkMode       string                     `protobuf:"bytes,15,opt,name=network_mode,json=networkMode,proto3" json:"network_mode,omitempty"`
+	CapAdd            []string                   `protobuf:"bytes,16,rep,name=cap_add,json=capAdd,proto3" json:"cap_add,omitempty"`
+	CapDrop           []string                   `protobuf:"bytes,17,rep,name=cap_drop,json=capDrop,proto3" json:"cap_drop,omitempty"`
+	Devices           []*Device                  `protobuf:"bytes,18,rep,name=devices,proto3" json:"devices,omitempty"`
+	DeviceCgroupRules []string                   `protobuf:"bytes,19,rep,name=device_cgroup_rules,json=deviceCgroupRules,proto3" json:"device_cgroup_rules,omitempty"`
+	Gpus              []*DeviceRequest           `protobuf:"bytes,20,rep,name=gpus,proto3" json:"gpus,omitempty"`
+	Healthcheck       *Healthcheck               `protobuf:"bytes,21,opt,name=healthcheck,proto3" json:"healthcheck,omitempty"`
+	WorkingDir        string                     `protobuf:"bytes,22,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	User              string                     `protobuf:"bytes,23,opt,name=user,proto3" json:"user,omitempty"`
+	GroupAdd          []string                   `protobuf:"bytes,24,rep,name=group_add,json=groupAdd,proto3" json:"group_add,omitempty"`
+	Hostname          string                     `protobuf:"bytes,25,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Domainname        string                     `protobuf:"bytes,26,opt,name=domainname,proto3" json:"domainname,omitempty"`
+	ContainerName     string                     `protobuf:"bytes,27,opt,name=container_name,json=containerName,proto3" json:"container_name,omitempty"`
+	// Entries of the form host:ip, in the order of the hosts
+	ExtraHosts      []string             `protobuf:"bytes,28,rep,name=extra_hosts,json=extraHosts,proto3" json:"extra_hosts,omitempty"`
+	Dns             []string             `protobuf:"bytes,29,rep,name=dns,proto3" json:"dns,omitempty"`
+	DnsOpt          []string             `protobuf:"bytes,30,rep,name=dns_opt,json=dnsOpt,proto3" json:"dns_opt,omitempty"`
+	DnsSearch       []string             `protobuf:"bytes,31,rep,name=dns_search,json=dnsSearch,proto3" json:"dns_search,omitempty"`
+	Sysctls         map[string]string    `protobuf:"bytes,32,rep,name=sysctls,proto3" json:"sysctls,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Ulimits         map[string]*Ulimit   `protobuf:"bytes,33,rep,name=ulimits,proto3" json:"ulimits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Tmpfs           []string             `protobuf:"bytes,34,rep,name=tmpfs,proto3" json:"tmpfs,omitempty"`
+	Pid             string               `protobuf:"bytes,35,opt,name=pid,proto3" json:"pid,omitempty"`
+	Ipc             string               `protobuf:"bytes,36,opt,name=ipc,proto3" json:"ipc,omitempty"`
+	Uts             string               `protobuf:"bytes,37,opt,name=uts,proto3" json:"uts,omitempty"`
+	UsernsMode      string               `protobuf:"bytes,38,opt,name=userns_mode,json=usernsMode,proto3" json:"userns_mode,omitempty"`
+	Cgroup          string               `protobuf:"bytes,39,opt,name=cgroup,proto3" json:"cgroup,omitempty"`
+	SecurityOpt     []string             `protobuf:"bytes,40,rep,name=security_opt,json=securityOpt,proto3" json:"security_opt,omitempty"`
+	ReadOnly        bool                 `protobuf:"varint,41,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	Tty             bool                 `protobuf:"varint,42,opt,name=tty,proto3" json:"tty,omitempty"`
+	StdinOpen       bool                 `protobuf:"varint,43,opt,name=stdin_open,json=stdinOpen,proto3" json:"stdin_open,omitempty"`
+	Init            *bool                `protobuf:"varint,44,opt,name=init,proto3,oneof" json:"init,omitempty"`
+	StopSignal      string               `protobuf:"bytes,45,opt,name=stop_signal,json=stopSignal,proto3" json:"stop_signal,omitempty"`
+	StopGracePeriod *durationpb.Duration `protobuf:"bytes,46,opt,name=stop_grace_period,json=stopGracePeriod,proto3" json:"stop_grace_period,omitempty"`
+	// Sizes are in bytes
+	MemLimit       int64                      `protobuf:"varint,47,opt,name=mem_limit,json=memLimit,proto3" json:"mem_limit,omitempty"`
+	MemReservation int64                      `protobuf:"varint,48,opt,name=mem_reservation,json=memReservation,proto3" json:"mem_reservation,omitempty"`
+	MemswapLimit   int64                      `protobuf:"varint,49,opt,name=memswap_limit,json=memswapLimit,proto3" json:"memswap_limit,omitempty"`
+	ShmSize        int64                      `protobuf:"varint,50,opt,name=shm_size,json=shmSize,proto3" json:"shm_size,omitempty"`
+	Cpus           float32                    `protobuf:"fixed32,51,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	CpuShares      int64                      `protobuf:"varint,52,opt,name=cpu_shares,json=cpuShares,proto3" json:"cpu_shares,omitempty"`
+	CpuPeriod      int64                      `protobuf:"varint,53,opt,name=cpu_period,json=cpuPeriod,proto3" json:"cpu_period,omitempty"`
+	CpuQuota       int64                      `protobuf:"varint,54,opt,name=cpu_quota,json=cpuQuota,proto3" json:"cpu_quota,omitempty"`
+	Cpuset         string                     `protobuf:"bytes,55,opt,name=cpuset,proto3" json:"cpuset,omitempty"`
+	PidsLimit      int64                      `protobuf:"varint,56,opt,name=pids_limit,json=pidsLimit,proto3" json:"pids_limit,omitempty"`
+	OomScoreAdj    int64                      `protobuf:"varint,57,opt,name=oom_score_adj,json=oomScoreAdj,proto3" json:"oom_score_adj,omitempty"`
+	OomKillDisable bool                       `protobuf:"varint,58,opt,name=oom_kill_disable,json=oomKillDisable,proto3" json:"oom_kill_disable,omitempty"`
+	Runtime        string                     `protobuf:"bytes,59,opt,name=runtime,proto3" json:"runtime,omitempty"`
+	Platform       string                     `protobuf:"bytes,60,opt,name=platform,proto3" json:"platform,omitempty"`
+	PullPolicy     string                     `protobuf:"bytes,61,opt,name=pull_policy,json=pullPolicy,proto3" json:"pull_policy,omitempty"`
+	Profiles       []string                   `protobuf:"bytes,62,rep,name=profiles,proto3" json:"profiles,omitempty"`
+	Logging        *Logging                   `protobuf:"bytes,63,opt,name=logging,proto3" json:"logging,omitempty"`
+	Deploy         *Deploy                    `protobuf:"bytes,64,opt,name=deploy,proto3" json:"deploy,omitempty"`
+	Configs        []*FileReference           `protobuf:"bytes,65,rep,name=configs,proto3" json:"configs,omitempty"`
+	Secrets        []*FileReference           `protobuf:"bytes,66,rep,name=secrets,proto3" json:"secrets,omitempty"`
+	Extensions     map[string]*structpb.Value `protobuf:"bytes,67,rep,name=extensions,proto3" json:"extensions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Annotations    map[string]string          `protobuf:"bytes,68,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Attach         *bool                      `protobuf:"varint,69,opt,name=attach,proto3,oneof" json:"attach,omitempty"`
+	CgroupParent   string                     `protobuf:"bytes,70,opt,name=cgroup_parent,json=cgroupParent,proto3" json:"cgroup_parent,omitempty"`
+	CpuCount       int64                      `protobuf:"varint,71,opt,name=cpu_count,json=cpuCount,proto3" json:"cpu_count,omitempty"`
+	CpuPercent     float32                    `protobuf:"fixed32,72,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"`
+	CpuRtPeriod    int64                      `protobuf:"varint,73,opt,name=cpu_rt_period,json=cpuRtPeriod,proto3" json:"cpu_rt_period,omitempty"`
+	CpuRtRuntime   int64                      `protobuf:"varint,74,opt,name=cpu_rt_runtime,json=cpuRtRuntime,proto3" json:"cpu_rt_runtime,omitempty"`
+	MemSwappiness  int64                      `protobuf:"varint,75,opt,name=mem_swappiness,json=memSwappiness,proto3" json:"mem_swappiness,omitempty"`
+	MacAddress     string                     `protobuf:"bytes,76,opt,name=mac_address,json=macAddress,proto3" json:"mac_address,omitempty"`
+	Links          []string                   `protobuf:"bytes,77,rep,name=links,proto3" json:"links,omitempty"`
+	ExternalLinks  []string                   `protobuf:"bytes,78,rep,name=external_links,json=externalLinks,proto3" json:"external_links,omitempty"`
+	VolumesFrom    []string                   `protobuf:"bytes,79,rep,name=volumes_from,json=volumesFrom,proto3" json:"volumes_from,omitempty"`
+	PostStart      []*Hook                    `protobuf:"bytes,80,rep,name=post_start,json=postStart,proto3" json:"post_start,omitempty"`
+	PreStop        []*Hook                    `protobuf:"bytes,81,rep,name=pre_stop,json=preStop,proto3" json:"pre_stop,omitempty"`
+	StorageOpt     map[string]string          `protobuf:"bytes,82,rep,name=storage_opt,json=storageOpt,proto3" json:"storage_opt,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Isolation      string                     `protobuf:"bytes,83,opt,name=isolation,proto3" json:"isolation,omitempty"`
+	Scale          *int64                     `protobuf:"varint,84,opt,name=scale,proto3,oneof" json:"scale,omitempty"`
+	UseApiSocket   bool                       `protobuf:"varint,85,opt,name=use_api_socket,json=useApiSocket,proto3" json:"use_api_socket,omitempty"`
+	BlkioConfig    *BlkioConfig               `protobuf:"bytes,86,opt,name=blkio_config,json=blkioConfig,proto3" json:"blkio_config,omitempty"`
+	CredentialSpec *CredentialSpec            `protobuf:"bytes,87,opt,name=credential_spec,json=credentialSpec,proto3" json:"credential_spec,omitempty"`
+	// Env files which were read into the environment, unless discarded with --compat-docker
+	EnvFile       []*EnvFile `protobuf:"bytes,88,rep,name=env_file,json=envFile,proto3" json:"env_file,omitempty"`
+	LabelFile     []string   `protobuf:"bytes,89,rep,name=label_file,json=labelFile,proto3" json:"label_file,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_project_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Service) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *Service) GetBuild() *Build {
+	if x != nil {
+		return x.Build
+	}
+	return nil
+}
+
+func (x *Service) GetCommand() *Command {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *Service) GetEntrypoint() *Command {
+	if x != nil {
+		return x.Entrypoint
+	}
+	return nil
+}
+
+func (x *Service) GetEnvironment() map[string]string {
+	if x != nil {
+		return x.Environment
+	}
+	return nil
+}
+
+func (x *Service) GetUnsetEnvironment() []string {
+	if x != nil {
+		return x.UnsetEnvironment
+	}
+	return nil
+}
+
+func (x *Service) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Service) GetPorts() []*Port {
+	if x != nil {
+		return x.Ports
+	}
+	return nil
+}
+
+func (x *Service) GetExpose() []string {
+	if x != nil {
+		return x.Expose
+	}
+	return nil
+}
+
+func (x *Service) GetVolumes() []*ServiceVolume {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+func (x *Service) GetNetworks() map[string]*ServiceNetwork {
+	if x != nil {
+		return x.Networks
+	}
+	return nil
+}
+
+func (x *Service) GetDependsOn() map[string]*Dependency {
+	if x != nil {
+		return x.DependsOn
+	}
+	return nil
+}
+
+func (x *Service) GetRestart() string {
+	if x != nil {
+		return x.Restart
+	}
+	return ""
+}
+
+func (x *Service) GetPrivileged() bool {
+	if x != nil {
+		return x.Privileged
+	}
+	return false
+}
+
+func (x *Service) GetNetworkMode() string {
+	if x != nil {
+		return x.NetworkMode
+	}
+	return ""
+}
+
+func (x *Service) GetCapAdd() []string {
+	if x != nil {
+		return x.CapAdd
+	}
+	return nil
+}
+
+func (x *Service) GetCapDrop() []string {
+	if x != nil {
+		return x.CapDrop
+	}
+	return nil
+}
+
+func (x *Service) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+func (x *Service) GetDeviceCgroupRules() []string {
+	if x != nil {
+		return x.DeviceCgroupRules
+	}
+	return nil
+}
+
+func (x *Service) GetGpus() []*DeviceRequest {
+	if x != nil {
+		return x.Gpus
+	}
+	return nil
+}
+
+func (x *Service) GetHealthcheck() *Healthcheck {
+	if x != nil {
+		return x.Healthcheck
+	}
+	return nil
+}
+
+func (x *Service) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *Service) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Service) GetGroupAdd() []string {
+	if x != nil {
+		return x.GroupAdd
+	}
+	return nil
+}
+
+func (x *Service) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *Service) GetDomainname() string {
+	if x != nil {
+		return x.Domainname
+	}
+	return ""
+}
+
+func (x *Service) GetContainerName() string {
+	if x != nil {
+		return x.ContainerName
+	}
+	return ""
+}
+
+func (x *Service) GetExtraHosts() []string {
+	if x != nil {
+		return x.ExtraHosts
+	}
+	return nil
+}
+
+func (x *Service) GetDns() []string {
+	if x != nil {
+		return x.Dns
+	}
+	return nil
+}
+
+func (x *Service) GetDnsOpt() []string {
+	if x != nil {
+		return x.DnsOpt
+	}
+	return nil
+}
+
+func (x *Service) GetDnsSearch() []string {
+	if x != nil {
+		return x.DnsSearch
+	}
+	return nil
+}
+
+func (x *Service) GetSysctls() map[string]string {
+	if x != nil {
+		return x.Sysctls
+	}
+	return nil
+}
+
+func (x *Service) GetUlimits() map[string]*Ulimit {
+	if x != nil {
+		return x.Ulimits
+	}
+	return nil
+}
+
+func (x *Service) GetTmpfs() []string {
+	if x != nil {
+		return x.Tmpfs
+	}
+	return nil
+}
+
+func (x *Service) GetPid() string {
+	if x != nil {
+		return x.Pid
+	}
+	return ""
+}
+
+func (x *Service) GetIpc() string {
+	if x != nil {
+		return x.Ipc
+	}
+	return ""
+}
+
+func (x *Service) GetUts() string {
+	if x != nil {
+		return x.Uts
+	}
+	return ""
+}
+
+func (x *Service) GetUsernsMode() string {
+	if x != nil {
+		return x.UsernsMode
+	}
+	return ""
+}
+
+func (x *Service) GetCgroup() string {
+	if x != nil {
+		return x.Cgroup
+	}
+	return ""
+}
+
+func (x *Service) GetSecurityOpt() []string {
+	if x != nil {
+		return x.SecurityOpt
+	}
+	return nil
+}
+
+func (x *Service) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *Service) GetTty() bool {
+	if x != nil {
+		return x.Tty
+	}
+	return false
+}
+
+func (x *Service) GetStdinOpen() bool {
+	if x != nil {
+		return x.StdinOpen
+	}
+	return false
+}
+
+func (x *Service) GetInit() bool {
+	if x != nil && x.Init != nil {
+		return *x.Init
+	}
+	return false
+}
+
+func (x *Service) GetStopSignal() string {
+	if x != nil {
+		return x.StopSignal
+	}
+	return ""
+}
+
+func (x *Service) GetStopGracePeriod() *durationpb.Duration {
+	if x != nil {
+		return x.StopGracePeriod
+	}
+	return nil
+}
+
+func (x *Service) GetMemLimit() int64 {
+	if x != nil {
+		return x.MemLimit
+	}
+	return 0
+}
+
+func (x *Service) GetMemReservation() int64 {
+	if x != nil {
+		return x.MemReservation
+	}
+	return 0
+}
+
+func (x *Service) GetMemswapLimit() int64 {
+	if x != nil {
+		return x.MemswapLimit
+	}
+	return 0
+}
+
+func (x *Service) GetShmSize() int64 {
+	if x != nil {
+		return x.ShmSize
+	}
+	return 0
+}
+
+func (x *Service) GetCpus() float32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *Service) GetCpuShares() int64 {
+	if x != nil {
+		return x.CpuShares
+	}
+	return 0
+}
+
+func (x *Service) GetCpuPeriod() int64 {
+	if x != nil {
+		return x.CpuPeriod
+	}
+	return 0
+}
+
+func (x *Service) GetCpuQuota() int64 {
+	if x != nil {
+		return x.CpuQuota
+	}
+	return 0
+}
+
+func (x *Service) GetCpuset() string {
+	if x != nil {
+		return x.Cpuset
+	}
+	return ""
+}
+
+func (x *Service) GetPidsLimit() int64 {
+	if x != nil {
+		return x.PidsLimit
+	}
+	return 0
+}
+
+func (x *Service) GetOomScoreAdj() int64 {
+	if x != nil {
+		return x.OomScoreAdj
+	}
+	return 0
+}
+
+func (x *Service) GetOomKillDisable() bool {
+	if x != nil {
+		return x.OomKillDisable
+	}
+	return false
+}
+
+func (x *Service) GetRuntime() string {
+	if x != nil {
+		return x.Runtime
+	}
+	return ""
+}
+
+func (x *Service) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *Service) GetPullPolicy() string {
+	if x != nil {
+		return x.PullPolicy
+	}
+	return ""
+}
+
+func (x *Service) GetProfiles() []string {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+func (x *Service) GetLogging() *Logging {
+	if x != nil {
+		return x.Logging
+	}
+	return nil
+}
+
+func (x *Service) GetDeploy() *Deploy {
+	if x != nil {
+		return x.Deploy
+	}
+	return nil
+}
+
+func (x *Service) GetConfigs() []*FileReference {
+	if x != nil {
+		return x.Configs
+	}
+	return nil
+}
+
+func (x *Service) GetSecrets() []*FileReference {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+func (x *Service) GetExtensions() map[string]*structpb.Value {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *Service) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *Service) GetAttach() bool {
+	if x != nil && x.Attach != nil {
+		return *x.Attach
+	}
+	return false
+}
+
+func (x *Service) GetCgroupParent() string {
+	if x != nil {
+		return x.CgroupParent
+	}
+	return ""
+}
+
+func (x *Service) GetCpuCount() int64 {
+	if x != nil {
+		return x.CpuCount
+	}
+	return 0
+}
+
+func (x *Service) GetCpuPercent() float32 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *Service) GetCpuRtPeriod() int64 {
+	if x != nil {
+		return x.CpuRtPeriod
+	}
+	return 0
+}
+
+func (x *Service) GetCpuRtRuntime() int64 {
+	if x != nil {
+		return x.CpuRtRuntime
+	}
+	return 0
+}
+
+func (x *Service) GetMemSwappiness() int64 {
+	if x != nil {
+		return x.MemSwappiness
+	}
+	return 0
+}
+
+func (x *Service) GetMacAddress() string {
+	if x != nil {
+		return x.MacAddress
+	}
+	return ""
+}
+
+func (x *Service) GetLinks() []string {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *Service) GetExternalLinks() []string {
+	if x != nil {
+		return x.ExternalLinks
+	}
+	return nil
+}
+
+func (x *Service) GetVolumesFrom() []string {
+	if x != nil {
+		return x.VolumesFrom
+	}
+	return nil
+}
+
+func (x *Service) GetPostStart() []*Hook {
+	if x != nil {
+		return x.PostStart
+	}
+	return nil
+}
+
+func (x *Service) GetPreStop() []*Hook {
+	if x != nil {
+		return x.PreStop
+	}
+	return nil
+}
+
+func (x *Service) GetStorageOpt() map[string]string {
+	if x != nil {
+		return x.StorageOpt
+	}
+	return nil
+}
+
+func (x *Service) GetIsolation() string {
+	if x != nil {
+		return x.Isolation
+	}
+	return ""
+}
+
+func (x *Service) GetScale() int64 {
+	if x != nil && x.Scale != nil {
+		return *x.Scale
+	}
+	return 0
+}
+
+func (x *Service) GetUseApiSocket() bool {
+	if x != nil {
+		return x.UseApiSocket
+	}
+	return false
+}
+
+func (x *Service) GetBlkioConfig() *BlkioConfig {
+	if x != nil {
+		return x.BlkioConfig
+	}
+	return nil
+}
+
+func (x *Service) GetCredentialSpec() *CredentialSpec {
+	if x != nil {
+		return x.CredentialSpec
+	}
+	return nil
+}
+
+func (x *Service) GetEnvFile() []*EnvFile {
+	if x != nil {
+		return x.EnvFile
+	}
+	return nil
+}
+
+func (x *Service) GetLabelFile() []string {
+	if x != nil {
+		return x.LabelFile
+	}
+	return nil
+}
+
+// A post_start or pre_stop lifecycle hook
+type Hook struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Command          *Command               `protobuf:"bytes,1,opt,name=command,proto3" json:"command,omitempty"`
+	User             string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Privileged       bool                   `protobuf:"varint,3,opt,name=privileged,proto3" json:"privileged,omitempty"`
+	WorkingDir       string                 `protobuf:"bytes,4,opt,name=working_dir,json=workingDir,proto3" json:"working_dir,omitempty"`
+	Environment      map[string]string      `protobuf:"bytes,5,rep,name=environment,proto3" json:"environment,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	UnsetEnvironment []string               `protobuf:"bytes,6,rep,name=unset_environment,json=unsetEnvironment,proto3" json:"unset_environment,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Hook) Reset() {
+	*x = Hook{}
+	mi := &file_project_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hook) ProtoMessage() {}
+
+func (x *Hook) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hook.ProtoReflect.Descriptor instead.
+func (*Hook) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Hook) GetCommand() *Command {
+	if x != nil {
+		return x.Command
+	}
+	return nil
+}
+
+func (x *Hook) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Hook) GetPrivileged() bool {
+	if x != nil {
+		return x.Privileged
+	}
+	return false
+}
+
+func (x *Hook) GetWorkingDir() string {
+	if x != nil {
+		return x.WorkingDir
+	}
+	return ""
+}
+
+func (x *Hook) GetEnvironment() map[string]string {
+	if x != nil {
+		return x.Environment
+	}
+	return nil
+}
+
+func (x *Hook) GetUnsetEnvironment() []string {
+	if x != nil {
+		return x.UnsetEnvironment
+	}
+	return nil
+}
+
+type BlkioConfig struct {
+	state        protoimpl.MessageState      `protogen:"open.v1"`
+	Weight       uint32                      `protobuf:"varint,1,opt,name=weight,proto3" json:"weight,omitempty"`
+	WeightDevice []*BlkioConfig_WeightDevice `protobuf:"bytes,2,rep,name=weight_device,json=weightDevice,proto3" json:"weight_device,omitempty"`
+	// Rates are in bytes or operations per second
+	DeviceReadBps   []*BlkioConfig_ThrottleDevice `protobuf:"bytes,3,rep,name=device_read_bps,json=deviceReadBps,proto3" json:"device_read_bps,omitempty"`
+	DeviceReadIops  []*BlkioConfig_ThrottleDevice `protobuf:"bytes,4,rep,name=device_read_iops,json=deviceReadIops,proto3" json:"device_read_iops,omitempty"`
+	DeviceWriteBps  []*BlkioConfig_ThrottleDevice `protobuf:"bytes,5,rep,name=device_write_bps,json=deviceWriteBps,proto3" json:"device_write_bps,omitempty"`
+	DeviceWriteIops []*BlkioConfig_ThrottleDevice `protobuf:"bytes,6,rep,name=device_write_iops,json=deviceWriteIops,proto3" json:"device_write_iops,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *BlkioConfig) Reset() {
+	*x = BlkioConfig{}
+	mi := &file_project_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlkioConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlkioConfig) ProtoMessage() {}
+
+func (x *BlkioConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlkioConfig.ProtoReflect.Descriptor instead.
+func (*BlkioConfig) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BlkioConfig) GetWeight() uint32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *BlkioConfig) GetWeightDevice() []*BlkioConfig_WeightDevice {
+	if x != nil {
+		return x.WeightDevice
+	}
+	return nil
+}
+
+func (x *BlkioConfig) GetDeviceReadBps() []*BlkioConfig_ThrottleDevice {
+	if x != nil {
+		return x.DeviceReadBps
+	}
+	return nil
+}
+
+func (x *BlkioConfig) GetDeviceReadIops() []*BlkioConfig_ThrottleDevice {
+	if x != nil {
+		return x.DeviceReadIops
+	}
+	return nil
+}
+
+func (x *BlkioConfig) GetDeviceWriteBps() []*BlkioConfig_ThrottleDevice {
+	if x != nil {
+		return x.DeviceWriteBps
+	}
+	return nil
+}
+
+func (x *BlkioConfig) GetDeviceWriteIops() []*BlkioConfig_ThrottleDevice {
+	if x != nil {
+		return x.DeviceWriteIops
+	}
+	return nil
+}
+
+type CredentialSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Config        string                 `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	File          string                 `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Registry      string                 `protobuf:"bytes,3,opt,name=registry,proto3" json:"registry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CredentialSpec) Reset() {
+	*x = CredentialSpec{}
+	mi := &file_project_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CredentialSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CredentialSpec) ProtoMessage() {}
+
+func (x *CredentialSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CredentialSpec.ProtoReflect.Descriptor instead.
+func (*CredentialSpec) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CredentialSpec) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+func (x *CredentialSpec) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *CredentialSpec) GetRegistry() string {
+	if x != nil {
+		return x.Registry
+	}
+	return ""
+}
+
+type EnvFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Required      bool                   `protobuf:"varint,2,opt,name=required,proto3" json:"required,omitempty"`
+	Format        string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnvFile) Reset() {
+	*x = EnvFile{}
+	mi := &file_project_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnvFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnvFile) ProtoMessage() {}
+
+func (x *EnvFile) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnvFile.ProtoReflect.Descriptor instead.
+func (*EnvFile) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EnvFile) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *EnvFile) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *EnvFile) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+type Command struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Args          []string               `protobuf:"bytes,1,rep,name=args,proto3" json:"args,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Command) Reset() {
+	*x = Command{}
+	mi := &file_project_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Command) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Command) ProtoMessage() {}
+
+func (x *Command) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Command.ProtoReflect.Descriptor instead.
+func (*Command) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Command) GetArgs() []string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+type Build struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Context          string                 `protobuf:"bytes,1,opt,name=context,proto3" json:"context,omitempty"`
+	Dockerfile       string                 `protobuf:"bytes,2,opt,name=dockerfile,proto3" json:"dockerfile,omitempty"`
+	DockerfileInline string                 `protobuf:"bytes,3,opt,name=dockerfile_inline,json=dockerfileInline,proto3" json:"dockerfile_inline,omitempty"`
+	// Arguments with a value
+	Args map[string]string `protobuf:"bytes,4,rep,name=args,proto3" json:"args,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Names of the arguments without a value, taken from the environment of the builder
+	UnsetArgs          []string                   `protobuf:"bytes,5,rep,name=unset_args,json=unsetArgs,proto3" json:"unset_args,omitempty"`
+	Labels             map[string]string          `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Target             string                     `protobuf:"bytes,7,opt,name=target,proto3" json:"target,omitempty"`
+	Network            string                     `protobuf:"bytes,8,opt,name=network,proto3" json:"network,omitempty"`
+	CacheFrom          []string                   `protobuf:"bytes,9,rep,name=cache_from,json=cacheFrom,proto3" json:"cache_from,omitempty"`
+	Platforms          []string                   `protobuf:"bytes,10,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	Tags               []string                   `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	AdditionalContexts map[string]string          `protobuf:"bytes,12,rep,name=additional_contexts,json=additionalContexts,proto3" json:"additional_contexts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ExtraHosts         []string                   `protobuf:"bytes,13,rep,name=extra_hosts,json=extraHosts,proto3" json:"extra_hosts,omitempty"`
+	ShmSize            int64                      `protobuf:"varint,14,opt,name=shm_size,json=shmSize,proto3" json:"shm_size,omitempty"`
+	Ulimits            map[string]*Ulimit         `protobuf:"bytes,15,rep,name=ulimits,proto3" json:"ulimits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Extensions         map[string]*structpb.Value `protobuf:"bytes,16,rep,name=extensions,proto3" json:"extensions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Entitlements       []string                   `protobuf:"bytes,17,rep,name=entitlements,proto3" json:"entitlements,omitempty"`
+	Ssh                []*Build_SshKey            `protobuf:"bytes,18,rep,name=ssh,proto3" json:"ssh,omitempty"`
+	CacheTo            []string                   `protobuf:"bytes,19,rep,name=cache_to,json=cacheTo,proto3" json:"cache_to,omitempty"`
+	NoCache            bool                       `protobuf:"varint,20,opt,name=no_cache,json=noCache,proto3" json:"no_cache,omitempty"`
+	Pull               bool                       `protobuf:"varint,21,opt,name=pull,proto3" json:"pull,omitempty"`
+	Isolation          string                     `protobuf:"bytes,22,opt,name=isolation,proto3" json:"isolation,omitempty"`
+	Secrets            []*FileReference           `protobuf:"bytes,23,rep,name=secrets,proto3" json:"secrets,omitempty"`
+	Privileged         bool                       `protobuf:"varint,24,opt,name=privileged,proto3" json:"privileged,omitempty"`
+	Provenance         string                     `protobuf:"bytes,25,opt,name=provenance,proto3" json:"provenance,omitempty"`
+	Sbom               string                     `protobuf:"bytes,26,opt,name=sbom,proto3" json:"sbom,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Build) Reset() {
+	*x = Build{}
+	mi := &file_project_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Build) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Build) ProtoMessage() {}
+
+func (x *Build) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Build.ProtoReflect.Descriptor instead.
+func (*Build) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Build) GetContext() string {
+	if x != nil {
+		return x.Context
+	}
+	return ""
+}
+
+func (x *Build) GetDockerfile() string {
+	if x != nil {
+		return x.Dockerfile
+	}
+	return ""
+}
+
+func (x *Build) GetDockerfileInline() string {
+	if x != nil {
+		return x.DockerfileInline
+	}
+	return ""
+}
+
+func (x *Build) GetArgs() map[string]string {
+	if x != nil {
+		return x.Args
+	}
+	return nil
+}
+
+func (x *Build) GetUnsetArgs() []string {
+	if x != nil {
+		return x.UnsetArgs
+	}
+	return nil
+}
+
+func (x *Build) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Build) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Build) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Build) GetCacheFrom() []string {
+	if x != nil {
+		return x.CacheFrom
+	}
+	return nil
+}
+
+func (x *Build) GetPlatforms() []string {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+func (x *Build) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Build) GetAdditionalContexts() map[string]string {
+	if x != nil {
+		return x.AdditionalContexts
+	}
+	return nil
+}
+
+func (x *Build) GetExtraHosts() []string {
+	if x != nil {
+		return x.ExtraHosts
+	}
+	return nil
+}
+
+func (x *Build) GetShmSize() int64 {
+	if x != nil {
+		return x.ShmSize
+	}
+	return 0
+}
+
+func (x *Build) GetUlimits() map[string]*Ulimit {
+	if x != nil {
+		return x.Ulimits
+	}
+	return nil
+}
+
+func (x *Build) GetExtensions() map[string]*structpb.Value {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *Build) GetEntitlements() []string {
+	if x != nil {
+		return x.Entitlements
+	}
+	return nil
+}
+
+func (x *Build) GetSsh() []*Build_SshKey {
+	if x != nil {
+		return x.Ssh
+	}
+	return nil
+}
+
+func (x *Build) GetCacheTo() []string {
+	if x != nil {
+		return x.CacheTo
+	}
+	return nil
+}
+
+func (x *Build) GetNoCache() bool {
+	if x != nil {
+		return x.NoCache
+	}
+	return false
+}
+
+func (x *Build) GetPull() bool {
+	if x != nil {
+		return x.Pull
+	}
+	return false
+}
+
+func (x *Build) GetIsolation() string {
+	if x != nil {
+		return x.Isolation
+	}
+	return ""
+}
+
+func (x *Build) GetSecrets() []*FileReference {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+func (x *Build) GetPrivileged() bool {
+	if x != nil {
+		return x.Privileged
+	}
+	return false
+}
+
+func (x *Build) GetProvenance() string {
+	if x != nil {
+		return x.Provenance
+	}
+	return ""
+}
+
+func (x *Build) GetSbom() string {
+	if x != nil {
+		return x.Sbom
+	}
+	return ""
+}
+
+type Port struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Target uint32                 `protobuf:"varint,1,opt,name=target,proto3" json:"target,omitempty"`
+	// A port or range, e.g. 8080-8081, or unset for a random port
+	Published     string `protobuf:"bytes,2,opt,name=published,proto3" json:"published,omitempty"`
+	HostIp        string `protobuf:"bytes,3,opt,name=host_ip,json=hostIp,proto3" json:"host_ip,omitempty"`
+	Protocol      string `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Mode          string `protobuf:"bytes,5,opt,name=mode,proto3" json:"mode,omitempty"`
+	Name          string `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	AppProtocol   string `protobuf:"bytes,7,opt,name=app_protocol,json=appProtocol,proto3" json:"app_protocol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Port) Reset() {
+	*x = Port{}
+	mi := &file_project_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Port) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Port) ProtoMessage() {}
+
+func (x *Port) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Port.ProtoReflect.Descriptor instead.
+func (*Port) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Port) GetTarget() uint32 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *Port) GetPublished() string {
+	if x != nil {
+		return x.Published
+	}
+	return ""
+}
+
+func (x *Port) GetHostIp() string {
+	if x != nil {
+		return x.HostIp
+	}
+	return ""
+}
+
+func (x *Port) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *Port) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Port) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Port) GetAppProtocol() string {
+	if x != nil {
+		return x.AppProtocol
+	}
+	return ""
+}
+
+type ServiceVolume struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// bind, volume or tmpfs
+	Type          string                       `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Source        string                       `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Target        string                       `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	ReadOnly      bool                         `protobuf:"varint,4,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	Bind          *ServiceVolume_BindOptions   `protobuf:"bytes,5,opt,name=bind,proto3" json:"bind,omitempty"`
+	Volume        *ServiceVolume_VolumeOptions `protobuf:"bytes,6,opt,name=volume,proto3" json:"volume,omitempty"`
+	Tmpfs         *ServiceVolume_TmpfsOptions  `protobuf:"bytes,7,opt,name=tmpfs,proto3" json:"tmpfs,omitempty"`
+	Consistency   string                       `protobuf:"bytes,8,opt,name=consistency,proto3" json:"consistency,omitempty"`
+	Image         *ServiceVolume_ImageOptions  `protobuf:"bytes,9,opt,name=image,proto3" json:"image,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceVolume) Reset() {
+	*x = ServiceVolume{}
+	mi := &file_project_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceVolume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceVolume) ProtoMessage() {}
+
+func (x *ServiceVolume) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceVolume.ProtoReflect.Descriptor instead.
+func (*ServiceVolume) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ServiceVolume) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ServiceVolume) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ServiceVolume) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *ServiceVolume) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *ServiceVolume) GetBind() *ServiceVolume_BindOptions {
+	if x != nil {
+		return x.Bind
+	}
+	return nil
+}
+
+func (x *ServiceVolume) GetVolume() *ServiceVolume_VolumeOptions {
+	if x != nil {
+		return x.Volume
+	}
+	return nil
+}
+
+func (x *ServiceVolume) GetTmpfs() *ServiceVolume_TmpfsOptions {
+	if x != nil {
+		return x.Tmpfs
+	}
+	return nil
+}
+
+func (x *ServiceVolume) GetConsistency() string {
+	if x != nil {
+		return x.Consistency
+	}
+	return ""
+}
+
+func (x *ServiceVolume) GetImage() *ServiceVolume_ImageOptions {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+type ServiceNetwork struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Aliases       []string               `protobuf:"bytes,1,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	Ipv4Address   string                 `protobuf:"bytes,2,opt,name=ipv4_address,json=ipv4Address,proto3" json:"ipv4_address,omitempty"`
+	Ipv6Address   string                 `protobuf:"bytes,3,opt,name=ipv6_address,json=ipv6Address,proto3" json:"ipv6_address,omitempty"`
+	LinkLocalIps  []string               `protobuf:"bytes,4,rep,name=link_local_ips,json=linkLocalIps,proto3" json:"link_local_ips,omitempty"`
+	MacAddress    string                 `protobuf:"bytes,5,opt,name=mac_address,json=macAddress,proto3" json:"mac_address,omitempty"`
+	Priority      int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	GwPriority    int32                  `protobuf:"varint,7,opt,name=gw_priority,json=gwPriority,proto3" json:"gw_priority,omitempty"`
+	DriverOpts    map[string]string      `protobuf:"bytes,8,rep,name=driver_opts,json=driverOpts,proto3" json:"driver_opts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	InterfaceName string                 `protobuf:"bytes,9,opt,name=interface_name,json=interfaceName,proto3" json:"interface_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceNetwork) Reset() {
+	*x = ServiceNetwork{}
+	mi := &file_project_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceNetwork) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceNetwork) ProtoMessage() {}
+
+func (x *ServiceNetwork) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceNetwork.ProtoReflect.Descriptor instead.
+func (*ServiceNetwork) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ServiceNetwork) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *ServiceNetwork) GetIpv4Address() string {
+	if x != nil {
+		return x.Ipv4Address
+	}
+	return ""
+}
+
+func (x *ServiceNetwork) GetIpv6Address() string {
+	if x != nil {
+		return x.Ipv6Address
+	}
+	return ""
+}
+
+func (x *ServiceNetwork) GetLinkLocalIps() []string {
+	if x != nil {
+		return x.LinkLocalIps
+	}
+	return nil
+}
+
+func (x *ServiceNetwork) GetMacAddress() string {
+	if x != nil {
+		return x.MacAddress
+	}
+	return ""
+}
+
+func (x *ServiceNetwork) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *ServiceNetwork) GetGwPriority() int32 {
+	if x != nil {
+		return x.GwPriority
+	}
+	return 0
+}
+
+func (x *ServiceNetwork) GetDriverOpts() map[string]string {
+	if x != nil {
+		return x.DriverOpts
+	}
+	return nil
+}
+
+func (x *ServiceNetwork) GetInterfaceName() string {
+	if x != nil {
+		return x.InterfaceName
+	}
+	return ""
+}
+
+type Dependency struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Condition     string                 `protobuf:"bytes,1,opt,name=condition,proto3" json:"condition,omitempty"`
+	Restart       bool                   `protobuf:"varint,2,opt,name=restart,proto3" json:"restart,omitempty"`
+	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dependency) Reset() {
+	*x = Dependency{}
+	mi := &file_project_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dependency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dependency) ProtoMessage() {}
+
+func (x *Dependency) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dependency.ProtoReflect.Descriptor instead.
+func (*Dependency) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Dependency) GetCondition() string {
+	if x != nil {
+		return x.Condition
+	}
+	return ""
+}
+
+func (x *Dependency) GetRestart() bool {
+	if x != nil {
+		return x.Restart
+	}
+	return false
+}
+
+func (x *Dependency) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+type Device struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Permissions   string                 `protobuf:"bytes,3,opt,name=permissions,proto3" json:"permissions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_project_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Device) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Device) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Device) GetPermissions() string {
+	if x != nil {
+		return x.Permissions
+	}
+	return ""
+}
+
+type DeviceRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Driver string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
+	// -1 for all the devices
+	Count         int64             `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	DeviceIds     []string          `protobuf:"bytes,3,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
+	Capabilities  []string          `protobuf:"bytes,4,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	Options       map[string]string `protobuf:"bytes,5,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceRequest) Reset() {
+	*x = DeviceRequest{}
+	mi := &file_project_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceRequest) ProtoMessage() {}
+
+func (x *DeviceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceRequest.ProtoReflect.Descriptor instead.
+func (*DeviceRequest) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeviceRequest) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *DeviceRequest) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *DeviceRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *DeviceRequest) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+func (x *DeviceRequest) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type Healthcheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Test          []string               `protobuf:"bytes,1,rep,name=test,proto3" json:"test,omitempty"`
+	Interval      *durationpb.Duration   `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	StartPeriod   *durationpb.Duration   `protobuf:"bytes,4,opt,name=start_period,json=startPeriod,proto3" json:"start_period,omitempty"`
+	StartInterval *durationpb.Duration   `protobuf:"bytes,5,opt,name=start_interval,json=startInterval,proto3" json:"start_interval,omitempty"`
+	Retries       *uint64                `protobuf:"varint,6,opt,name=retries,proto3,oneof" json:"retries,omitempty"`
+	Disable       bool                   `protobuf:"varint,7,opt,name=disable,proto3" json:"disable,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Healthcheck) Reset() {
+	*x = Healthcheck{}
+	mi := &file_project_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Healthcheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Healthcheck) ProtoMessage() {}
+
+func (x *Healthcheck) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Healthcheck.ProtoReflect.Descriptor instead.
+func (*Healthcheck) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Healthcheck) GetTest() []string {
+	if x != nil {
+		return x.Test
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetStartPeriod() *durationpb.Duration {
+	if x != nil {
+		return x.StartPeriod
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetStartInterval() *durationpb.Duration {
+	if x != nil {
+		return x.StartInterval
+	}
+	return nil
+}
+
+func (x *Healthcheck) GetRetries() uint64 {
+	if x != nil && x.Retries != nil {
+		return *x.Retries
+	}
+	return 0
+}
+
+func (x *Healthcheck) GetDisable() bool {
+	if x != nil {
+		return x.Disable
+	}
+	return false
+}
+
+type Ulimit struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Both are set to the single limit, if set as such
+	Soft          int64 `protobuf:"varint,1,opt,name=soft,proto3" json:"soft,omitempty"`
+	Hard          int64 `protobuf:"varint,2,opt,name=hard,proto3" json:"hard,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ulimit) Reset() {
+	*x = Ulimit{}
+	mi := &file_project_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ulimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ulimit) ProtoMessage() {}
+
+func (x *Ulimit) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ulimit.ProtoReflect.Descriptor instead.
+func (*Ulimit) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Ulimit) GetSoft() int64 {
+	if x != nil {
+		return x.Soft
+	}
+	return 0
+}
+
+func (x *Ulimit) GetHard() int64 {
+	if x != nil {
+		return x.Hard
+	}
+	return 0
+}
+
+type Logging struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Driver        string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
+	Options       map[string]string      `protobuf:"bytes,2,rep,name=options,proto3" json:"options,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Logging) Reset() {
+	*x = Logging{}
+	mi := &file_project_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Logging) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Logging) ProtoMessage() {}
+
+func (x *Logging) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Logging.ProtoReflect.Descriptor instead.
+func (*Logging) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Logging) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Logging) GetOptions() map[string]string {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type Deploy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Labels        map[string]string      `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Limits        *Deploy_Resource       `protobuf:"bytes,2,opt,name=limits,proto3" json:"limits,omitempty"`
+	Reservations  *Deploy_Resource       `protobuf:"bytes,3,opt,name=reservations,proto3" json:"reservations,omitempty"`
+	Mode          string                 `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	Replicas      *int64                 `protobuf:"varint,5,opt,name=replicas,proto3,oneof" json:"replicas,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Deploy) Reset() {
+	*x = Deploy{}
+	mi := &file_project_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Deploy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Deploy) ProtoMessage() {}
+
+func (x *Deploy) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Deploy.ProtoReflect.Descriptor instead.
+func (*Deploy) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Deploy) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Deploy) GetLimits() *Deploy_Resource {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *Deploy) GetReservations() *Deploy_Resource {
+	if x != nil {
+		return x.Reservations
+	}
+	return nil
+}
+
+func (x *Deploy) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Deploy) GetReplicas() int64 {
+	if x != nil && x.Replicas != nil {
+		return *x.Replicas
+	}
+	return 0
+}
+
+type FileReference struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	Uid           string                 `protobuf:"bytes,3,opt,name=uid,proto3" json:"uid,omitempty"`
+	Gid           string                 `protobuf:"bytes,4,opt,name=gid,proto3" json:"gid,omitempty"`
+	Mode          *uint32                `protobuf:"varint,5,opt,name=mode,proto3,oneof" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileReference) Reset() {
+	*x = FileReference{}
+	mi := &file_project_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileReference) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileReference) ProtoMessage() {}
+
+func (x *FileReference) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileReference.ProtoReflect.Descriptor instead.
+func (*FileReference) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FileReference) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *FileReference) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *FileReference) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *FileReference) GetGid() string {
+	if x != nil {
+		return x.Gid
+	}
+	return ""
+}
+
+func (x *FileReference) GetMode() uint32 {
+	if x != nil && x.Mode != nil {
+		return *x.Mode
+	}
+	return 0
+}
+
+type Network struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Name          string                     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Driver        string                     `protobuf:"bytes,2,opt,name=driver,proto3" json:"driver,omitempty"`
+	DriverOpts    map[string]string          `protobuf:"bytes,3,rep,name=driver_opts,json=driverOpts,proto3" json:"driver_opts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Labels        map[string]string          `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	External      bool                       `protobuf:"varint,5,opt,name=external,proto3" json:"external,omitempty"`
+	Internal      bool                       `protobuf:"varint,6,opt,name=internal,proto3" json:"internal,omitempty"`
+	Attachable    bool                       `protobuf:"varint,7,opt,name=attachable,proto3" json:"attachable,omitempty"`
+	EnableIpv6    *bool                      `protobuf:"varint,8,opt,name=enable_ipv6,json=enableIpv6,proto3,oneof" json:"enable_ipv6,omitempty"`
+	Ipam          *Network_Ipam              `protobuf:"bytes,9,opt,name=ipam,proto3" json:"ipam,omitempty"`
+	Extensions    map[string]*structpb.Value `protobuf:"bytes,10,rep,name=extensions,proto3" json:"extensions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	EnableIpv4    *bool                      `protobuf:"varint,11,opt,name=enable_ipv4,json=enableIpv4,proto3,oneof" json:"enable_ipv4,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Network) Reset() {
+	*x = Network{}
+	mi := &file_project_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Network) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Network) ProtoMessage() {}
+
+func (x *Network) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Network.ProtoReflect.Descriptor instead.
+func (*Network) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Network) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Network) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Network) GetDriverOpts() map[string]string {
+	if x != nil {
+		return x.DriverOpts
+	}
+	return nil
+}
+
+func (x *Network) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Network) GetExternal() bool {
+	if x != nil {
+		return x.External
+	}
+	return false
+}
+
+func (x *Network) GetInternal() bool {
+	if x != nil {
+		return x.Internal
+	}
+	return false
+}
+
+func (x *Network) GetAttachable() bool {
+	if x != nil {
+		return x.Attachable
+	}
+	return false
+}
+
+func (x *Network) GetEnableIpv6() bool {
+	if x != nil && x.EnableIpv6 != nil {
+		return *x.EnableIpv6
+	}
+	return false
+}
+
+func (x *Network) GetIpam() *Network_Ipam {
+	if x != nil {
+		return x.Ipam
+	}
+	return nil
+}
+
+func (x *Network) GetExtensions() map[string]*structpb.Value {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *Network) GetEnableIpv4() bool {
+	if x != nil && x.EnableIpv4 != nil {
+		return *x.EnableIpv4
+	}
+	return false
+}
+
+type Volume struct {
+	state         protoimpl.MessageState     `protogen:"open.v1"`
+	Name          string                     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Driver        string                     `protobuf:"bytes,2,opt,name=driver,proto3" json:"driver,omitempty"`
+	DriverOpts    map[string]string          `protobuf:"bytes,3,rep,name=driver_opts,json=driverOpts,proto3" json:"driver_opts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Labels        map[string]string          `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	External      bool                       `protobuf:"varint,5,opt,name=external,proto3" json:"external,omitempty"`
+	Extensions    map[string]*structpb.Value `protobuf:"bytes,6,rep,name=extensions,proto3" json:"extensions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Volume) Reset() {
+	*x = Volume{}
+	mi := &file_project_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Volume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Volume) ProtoMessage() {}
+
+func (x *Volume) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Volume.ProtoReflect.Descriptor instead.
+func (*Volume) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Volume) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Volume) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Volume) GetDriverOpts() map[string]string {
+	if x != nil {
+		return x.DriverOpts
+	}
+	return nil
+}
+
+func (x *Volume) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Volume) GetExternal() bool {
+	if x != nil {
+		return x.External
+	}
+	return false
+}
+
+func (x *Volume) GetExtensions() map[string]*structpb.Value {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+// A top-level config or secret
+type FileObject struct {
+	state          protoimpl.MessageState     `protogen:"open.v1"`
+	Name           string                     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	File           string                     `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Environment    string                     `protobuf:"bytes,3,opt,name=environment,proto3" json:"environment,omitempty"`
+	Content        string                     `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	External       bool                       `protobuf:"varint,5,opt,name=external,proto3" json:"external,omitempty"`
+	Labels         map[string]string          `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Extensions     map[string]*structpb.Value `protobuf:"bytes,7,rep,name=extensions,proto3" json:"extensions,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Driver         string                     `protobuf:"bytes,8,opt,name=driver,proto3" json:"driver,omitempty"`
+	DriverOpts     map[string]string          `protobuf:"bytes,9,rep,name=driver_opts,json=driverOpts,proto3" json:"driver_opts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	TemplateDriver string                     `protobuf:"bytes,10,opt,name=template_driver,json=templateDriver,proto3" json:"template_driver,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *FileObject) Reset() {
+	*x = FileObject{}
+	mi := &file_project_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileObject) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileObject) ProtoMessage() {}
+
+func (x *FileObject) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileObject.ProtoReflect.Descriptor instead.
+func (*FileObject) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *FileObject) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileObject) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *FileObject) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *FileObject) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *FileObject) GetExternal() bool {
+	if x != nil {
+		return x.External
+	}
+	return false
+}
+
+func (x *FileObject) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *FileObject) GetExtensions() map[string]*structpb.Value {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *FileObject) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *FileObject) GetDriverOpts() map[string]string {
+	if x != nil {
+		return x.DriverOpts
+	}
+	return nil
+}
+
+func (x *FileObject) GetTemplateDriver() string {
+	if x != nil {
+		return x.TemplateDriver
+	}
+	return ""
+}
+
+type BlkioConfig_WeightDevice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Weight        uint32                 `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlkioConfig_WeightDevice) Reset() {
+	*x = BlkioConfig_WeightDevice{}
+	mi := &file_project_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlkioConfig_WeightDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlkioConfig_WeightDevice) ProtoMessage() {}
+
+func (x *BlkioConfig_WeightDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlkioConfig_WeightDevice.ProtoReflect.Descriptor instead.
+func (*BlkioConfig_WeightDevice) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *BlkioConfig_WeightDevice) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *BlkioConfig_WeightDevice) GetWeight() uint32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type BlkioConfig_ThrottleDevice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Rate          int64                  `protobuf:"varint,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlkioConfig_ThrottleDevice) Reset() {
+	*x = BlkioConfig_ThrottleDevice{}
+	mi := &file_project_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlkioConfig_ThrottleDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlkioConfig_ThrottleDevice) ProtoMessage() {}
+
+func (x *BlkioConfig_ThrottleDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlkioConfig_ThrottleDevice.ProtoReflect.Descriptor instead.
+func (*BlkioConfig_ThrottleDevice) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{3, 1}
+}
+
+func (x *BlkioConfig_ThrottleDevice) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *BlkioConfig_ThrottleDevice) GetRate() int64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type Build_SshKey struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Unset for the default agent socket or keys
+	Path          string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Build_SshKey) Reset() {
+	*x = Build_SshKey{}
+	mi := &file_project_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Build_SshKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Build_SshKey) ProtoMessage() {}
+
+func (x *Build_SshKey) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Build_SshKey.ProtoReflect.Descriptor instead.
+func (*Build_SshKey) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{7, 5}
+}
+
+func (x *Build_SshKey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Build_SshKey) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ServiceVolume_BindOptions struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Propagation    string                 `protobuf:"bytes,1,opt,name=propagation,proto3" json:"propagation,omitempty"`
+	CreateHostPath bool                   `protobuf:"varint,2,opt,name=create_host_path,json=createHostPath,proto3" json:"create_host_path,omitempty"`
+	Selinux        string                 `protobuf:"bytes,3,opt,name=selinux,proto3" json:"selinux,omitempty"`
+	Recursive      string                 `protobuf:"bytes,4,opt,name=recursive,proto3" json:"recursive,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ServiceVolume_BindOptions) Reset() {
+	*x = ServiceVolume_BindOptions{}
+	mi := &file_project_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceVolume_BindOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceVolume_BindOptions) ProtoMessage() {}
+
+func (x *ServiceVolume_BindOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceVolume_BindOptions.ProtoReflect.Descriptor instead.
+func (*ServiceVolume_BindOptions) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{9, 0}
+}
+
+func (x *ServiceVolume_BindOptions) GetPropagation() string {
+	if x != nil {
+		return x.Propagation
+	}
+	return ""
+}
+
+func (x *ServiceVolume_BindOptions) GetCreateHostPath() bool {
+	if x != nil {
+		return x.CreateHostPath
+	}
+	return false
+}
+
+func (x *ServiceVolume_BindOptions) GetSelinux() string {
+	if x != nil {
+		return x.Selinux
+	}
+	return ""
+}
+
+func (x *ServiceVolume_BindOptions) GetRecursive() string {
+	if x != nil {
+		return x.Recursive
+	}
+	return ""
+}
+
+type ServiceVolume_VolumeOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nocopy        bool                   `protobuf:"varint,1,opt,name=nocopy,proto3" json:"nocopy,omitempty"`
+	Subpath       string                 `protobuf:"bytes,2,opt,name=subpath,proto3" json:"subpath,omitempty"`
+	Labels        map[string]string      `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceVolume_VolumeOptions) Reset() {
+	*x = ServiceVolume_VolumeOptions{}
+	mi := &file_project_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceVolume_VolumeOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceVolume_VolumeOptions) ProtoMessage() {}
+
+func (x *ServiceVolume_VolumeOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceVolume_VolumeOptions.ProtoReflect.Descriptor instead.
+func (*ServiceVolume_VolumeOptions) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{9, 1}
+}
+
+func (x *ServiceVolume_VolumeOptions) GetNocopy() bool {
+	if x != nil {
+		return x.Nocopy
+	}
+	return false
+}
+
+func (x *ServiceVolume_VolumeOptions) GetSubpath() string {
+	if x != nil {
+		return x.Subpath
+	}
+	return ""
+}
+
+func (x *ServiceVolume_VolumeOptions) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type ServiceVolume_ImageOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subpath       string                 `protobuf:"bytes,1,opt,name=subpath,proto3" json:"subpath,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceVolume_ImageOptions) Reset() {
+	*x = ServiceVolume_ImageOptions{}
+	mi := &file_project_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceVolume_ImageOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceVolume_ImageOptions) ProtoMessage() {}
+
+func (x *ServiceVolume_ImageOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceVolume_ImageOptions.ProtoReflect.Descriptor instead.
+func (*ServiceVolume_ImageOptions) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{9, 2}
+}
+
+func (x *ServiceVolume_ImageOptions) GetSubpath() string {
+	if x != nil {
+		return x.Subpath
+	}
+	return ""
+}
+
+type ServiceVolume_TmpfsOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	Mode          uint32                 `protobuf:"varint,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServiceVolume_TmpfsOptions) Reset() {
+	*x = ServiceVolume_TmpfsOptions{}
+	mi := &file_project_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServiceVolume_TmpfsOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServiceVolume_TmpfsOptions) ProtoMessage() {}
+
+func (x *ServiceVolume_TmpfsOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServiceVolume_TmpfsOptions.ProtoReflect.Descriptor instead.
+func (*ServiceVolume_TmpfsOptions) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{9, 3}
+}
+
+func (x *ServiceVolume_TmpfsOptions) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ServiceVolume_TmpfsOptions) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+type Deploy_Resource struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Cpus  float32                `protobuf:"fixed32,1,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	// In bytes
+	Memory        int64            `protobuf:"varint,2,opt,name=memory,proto3" json:"memory,omitempty"`
+	Pids          int64            `protobuf:"varint,3,opt,name=pids,proto3" json:"pids,omitempty"`
+	Devices       []*DeviceRequest `protobuf:"bytes,4,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Deploy_Resource) Reset() {
+	*x = Deploy_Resource{}
+	mi := &file_project_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Deploy_Resource) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Deploy_Resource) ProtoMessage() {}
+
+func (x *Deploy_Resource) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Deploy_Resource.ProtoReflect.Descriptor instead.
+func (*Deploy_Resource) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{17, 1}
+}
+
+func (x *Deploy_Resource) GetCpus() float32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *Deploy_Resource) GetMemory() int64 {
+	if x != nil {
+		return x.Memory
+	}
+	return 0
+}
+
+func (x *Deploy_Resource) GetPids() int64 {
+	if x != nil {
+		return x.Pids
+	}
+	return 0
+}
+
+func (x *Deploy_Resource) GetDevices() []*DeviceRequest {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type Network_Ipam struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Driver        string                 `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
+	Config        []*Network_Pool        `protobuf:"bytes,2,rep,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Network_Ipam) Reset() {
+	*x = Network_Ipam{}
+	mi := &file_project_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Network_Ipam) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Network_Ipam) ProtoMessage() {}
+
+func (x *Network_Ipam) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Network_Ipam.ProtoReflect.Descriptor instead.
+func (*Network_Ipam) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{19, 3}
+}
+
+func (x *Network_Ipam) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Network_Ipam) GetConfig() []*Network_Pool {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type Network_Pool struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subnet        string                 `protobuf:"bytes,1,opt,name=subnet,proto3" json:"subnet,omitempty"`
+	Gateway       string                 `protobuf:"bytes,2,opt,name=gateway,proto3" json:"gateway,omitempty"`
+	IpRange       string                 `protobuf:"bytes,3,opt,name=ip_range,json=ipRange,proto3" json:"ip_range,omitempty"`
+	AuxAddresses  map[string]string      `protobuf:"bytes,4,rep,name=aux_addresses,json=auxAddresses,proto3" json:"aux_addresses,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Network_Pool) Reset() {
+	*x = Network_Pool{}
+	mi := &file_project_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Network_Pool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Network_Pool) ProtoMessage() {}
+
+func (x *Network_Pool) ProtoReflect() protoreflect.Message {
+	mi := &file_project_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Network_Pool.ProtoReflect.Descriptor instead.
+func (*Network_Pool) Descriptor() ([]byte, []int) {
+	return file_project_proto_rawDescGZIP(), []int{19, 4}
+}
+
+func (x *Network_Pool) GetSubnet() string {
+	if x != nil {
+		return x.Subnet
+	}
+	return ""
+}
+
+func (x *Network_Pool) GetGateway() string {
+	if x != nil {
+		return x.Gateway
+	}
+	return ""
+}
+
+func (x *Network_Pool) GetIpRange() string {
+	if x != nil {
+		return x.IpRange
+	}
+	return ""
+}
+
+func (x *Network_Pool) GetAuxAddresses() map[string]string {
+	if x != nil {
+		return x.AuxAddresses
+	}
+	return nil
+}
+
+var File_project_proto protoreflect.FileDescriptor
+
+const file_project_proto_rawDesc = "" +
+	"\n" +
+	"\rproject.proto\x12\x17balena.composeparser.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\"\x96\b\n" +
+	"\aProject\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12J\n" +
+	"\bservices\x18\x02 \x03(\v2..balena.composeparser.v1.Project.ServicesEntryR\bservices\x12J\n" +
+	"\bnetworks\x18\x03 \x03(\v2..balena.composeparser.v1.Project.NetworksEntryR\bnetworks\x12G\n" +
+	"\avolumes\x18\x04 \x03(\v2-.balena.composeparser.v1.Project.VolumesEntryR\avolumes\x12G\n" +
+	"\aconfigs\x18\x05 \x03(\v2-.balena.composeparser.v1.Project.ConfigsEntryR\aconfigs\x12G\n" +
+	"\asecrets\x18\x06 \x03(\v2-.balena.composeparser.v1.Project.SecretsEntryR\asecrets\x12P\n" +
+	"\n" +
+	"extensions\x18\a \x03(\v20.balena.composeparser.v1.Project.ExtensionsEntryR\n" +
+	"extensions\x1a]\n" +
+	"\rServicesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x126\n" +
+	"\x05value\x18\x02 \x01(\v2 .balena.composeparser.v1.ServiceR\x05value:\x028\x01\x1a]\n" +
+	"\rNetworksEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x126\n" +
+	"\x05value\x18\x02 \x01(\v2 .balena.composeparser.v1.NetworkR\x05value:\x028\x01\x1a[\n" +
+	"\fVolumesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.balena.composeparser.v1.VolumeR\x05value:\x028\x01\x1a_\n" +
+	"\fConfigsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x129\n" +
+	"\x05value\x18\x02 \x01(\v2#.balena.composeparser.v1.FileObjectR\x05value:\x028\x01\x1a_\n" +
+	"\fSecretsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x129\n" +
+	"\x05value\x18\x02 \x01(\v2#.balena.composeparser.v1.FileObjectR\x05value:\x028\x01\x1aU\n" +
+	"\x0fExtensionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value:\x028\x01\"\xe0\"\n" +
+	"\aService\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\tR\x05image\x124\n" +
+	"\x05build\x18\x02 \x01(\v2\x1e.balena.composeparser.v1.BuildR\x05build\x12:\n" +
+	"\acommand\x18\x03 \x01(\v2 .balena.composeparser.v1.CommandR\acommand\x12@\n" +
+	"\n" +
+	"entrypoint\x18\x04 \x01(\v2 .balena.composeparser.v1.CommandR\n" +
+	"entrypoint\x12S\n" +
+	"\venvironment\x18\x05 \x03(\v21.balena.composeparser.v1.Service.EnvironmentEntryR\venvironment\x12+\n" +
+	"\x11unset_environment\x18\x06 \x03(\tR\x10unsetEnvironment\x12D\n" +
+	"\x06labels\x18\a \x03(\v2,.balena.composeparser.v1.Service.LabelsEntryR\x06labels\x123\n" +
+	"\x05ports\x18\b \x03(\v2\x1d.balena.composeparser.v1.PortR\x05ports\x12\x16\n" +
+	"\x06expose\x18\t \x03(\tR\x06expose\x12@\n" +
+	"\avolumes\x18\n" +
+	" \x03(\v2&.balena.composeparser.v1.ServiceVolumeR\avolumes\x12J\n" +
+	"\bnetworks\x18\v \x03(\v2..balena.composeparser.v1.Service.NetworksEntryR\bnetworks\x12N\n" +
+	"\n" +
+	"depends_on\x18\f \x03(\v2/.balena.composeparser.v1.Service.DependsOnEntryR\tdependsOn\x12\x18\n" +
+	"\arestart\x18\r \x01(\tR\arestart\x12\x1e\n" +
+	"\n" +
+	"privileged\x18\x0e \x01(\bR\n" +
+	"privileged\x12!\n" +
+	"\fnetwork_mode\x18\x0f \x01(\tR\vnetworkMode\x12\x17\n" +
+	"\acap_add\x18\x10 \x03(\tR\x06capAdd\x12\x19\n" +
+	"\bcap_drop\x18\x11 \x03(\tR\acapDrop\x129\n" +
+	"\adevices\x18\x12 \x03(\v2\x1f.balena.composeparser.v1.DeviceR\adevices\x12.\n" +
+	"\x13device_cgroup_rules\x18\x13 \x03(\tR\x11deviceCgroupRules\x12:\n" +
+	"\x04gpus\x18\x14 \x03(\v2&.balena.composeparser.v1.DeviceRequestR\x04gpus\x12F\n" +
+	"\vhealthcheck\x18\x15 \x01(\v2$.balena.composeparser.v1.HealthcheckR\vhealthcheck\x12\x1f\n" +
+	"\vworking_dir\x18\x16 \x01(\tR\n" +
+	"workingDir\x12\x12\n" +
+	"\x04user\x18\x17 \x01(\tR\x04user\x12\x1b\n" +
+	"\tgroup_add\x18\x18 \x03(\tR\bgroupAdd\x12\x1a\n" +
+	"\bhostname\x18\x19 \x01(\tR\bhostname\x12\x1e\n" +
+	"\n" +
+	"domainname\x18\x1a \x01(\tR\n" +
+	"domainname\x12%\n" +
+	"\x0econtainer_name\x18\x1b \x01(\tR\rcontainerName\x12\x1f\n" +
+	"\vextra_hosts\x18\x1c \x03(\tR\n" +
+	"extraHosts\x12\x10\n" +
+	"\x03dns\x18\x1d \x03(\tR\x03dns\x12\x17\n" +
+	"\adns_opt\x18\x1e \x03(\tR\x06dnsOpt\x12\x1d\n" +
+	"\n" +
+	"dns_search\x18\x1f \x03(\tR\tdnsSearch\x12G\n" +
+	"\asysctls\x18  \x03(\v2-.balena.composeparser.v1.Service.SysctlsEntryR\asysctls\x12G\n" +
+	"\aulimits\x18! \x03(\v2-.balena.composeparser.v1.Service.UlimitsEntryR\aulimits\x12\x14\n" +
+	"\x05tmpfs\x18\" \x03(\tR\x05tmpfs\x12\x10\n" +
+	"\x03pid\x18# \x01(\tR\x03pid\x12\x10\n" +
+	"\x03ipc\x18$ \x01(\tR\x03ipc\x12\x10\n" +
+	"\x03uts\x18% \x01(\tR\x03uts\x12\x1f\n" +
+	"\vuserns_mode\x18& \x01(\tR\n" +
+	"usernsMode\x12\x16\n" +
+	"\x06cgroup\x18' \x01(\tR\x06cgroup\x12!\n" +
+	"\fsecurity_opt\x18( \x03(\tR\vsecurityOpt\x12\x1b\n" +
+	"\tread_only\x18) \x01(\bR\breadOnly\x12\x10\n" +
+	"\x03tty\x18* \x01(\bR\x03tty\x12\x1d\n" +
+	"\n" +
+	"stdin_open\x18+ \x01(\bR\tstdinOpen\x12\x17\n" +
+	"\x04init\x18, \x01(\bH\x00R\x04init\x88\x01\x01\x12\x1f\n" +
+	"\vstop_signal\x18- \x01(\tR\n" +
+	"stopSignal\x12E\n" +
+	"\x11stop_grace_period\x18. \x01(\v2\x19.google.protobuf.DurationR\x0fstopGracePeriod\x12\x1b\n" +
+	"\tmem_limit\x18/ \x01(\x03R\bmemLimit\x12'\n" +
+	"\x0fmem_reservation\x180 \x01(\x03R\x0ememReservation\x12#\n" +
+	"\rmemswap_limit\x181 \x01(\x03R\fmemswapLimit\x12\x19\n" +
+	"\bshm_size\x182 \x01(\x03R\ashmSize\x12\x12\n" +
+	"\x04cpus\x183 \x01(\x02R\x04cpus\x12\x1d\n" +
+	"\n" +
+	"cpu_shares\x184 \x01(\x03R\tcpuShares\x12\x1d\n" +
+	"\n" +
+	"cpu_period\x185 \x01(\x03R\tcpuPeriod\x12\x1b\n" +
+	"\tcpu_quota\x186 \x01(\x03R\bcpuQuota\x12\x16\n" +
+	"\x06cpuset\x187 \x01(\tR\x06cpuset\x12\x1d\n" +
+	"\n" +
+	"pids_limit\x188 \x01(\x03R\tpidsLimit\x12\"\n" +
+	"\room_score_adj\x189 \x01(\x03R\voomScoreAdj\x12(\n" +
+	"\x10oom_kill_disable\x18: \x01(\bR\x0eoomKillDisable\x12\x18\n" +
+	"\aruntime\x18; \x01(\tR\aruntime\x12\x1a\n" +
+	"\bplatform\x18< \x01(\tR\bplatform\x12\x1f\n" +
+	"\vpull_policy\x18= \x01(\tR\n" +
+	"pullPolicy\x12\x1a\n" +
+	"\bprofiles\x18> \x03(\tR\bprofiles\x12:\n" +
+	"\alogging\x18? \x01(\v2 .balena.composeparser.v1.LoggingR\alogging\x127\n" +
+	"\x06deploy\x18@ \x01(\v2\x1f.balena.composeparser.v1.DeployR\x06deploy\x12@\n" +
+	"\aconfigs\x18A \x03(\v2&.balena.composeparser.v1.FileReferenceR\aconfigs\x12@\n" +
+	"\asecrets\x18B \x03(\v2&.balena.composeparser.v1.FileReferenceR\asecrets\x12P\n" +
+	"\n" +
+	"extensions\x18C \x03(\v20.balena.composeparser.v1.Service.ExtensionsEntryR\n" +
+	"extensions\x12S\n" +
+	"\vannotations\x18D \x03(\v21.balena.composeparser.v1.Service.AnnotationsEntryR\vannotations\x12\x1b\n" +
+	"\x06attach\x18E \x01(\bH\x01R\x06attach\x88\x01\x01\x12#\n" +
+	"\rcgroup_parent\x18F \x01(\tR\fcgroupParent\x12\x1b\n" +
+	"\tcpu_count\x18G \x01(\x03R\bcpuCount\x12\x1f\n" +
+	"\vcpu_percent\x18H \x01(\x02R\n" +
+	"cpuPercent\x12\"\n" +
+	"\rcpu_rt_period\x18I \x01(\x03R\vcpuRtPeriod\x12$\n" +
+	"\x0ecpu_rt_runtime\x18J \x01(\x03R\fcpuRtRuntime\x12%\n" +
+	"\x0emem_swappiness\x18K \x01(\x03R\rmemSwappiness\x12\x1f\n" +
+	"\vmac_address\x18L \x01(\tR\n" +
+	"macAddress\x12\x14\n" +
+	"\x05links\x18M \x03(\tR\x05links\x12%\n" +
+	"\x0eexternal_links\x18N \x03(\tR\rexternalLinks\x12!\n" +
+	"\fvolumes_from\x18O \x03(\tR\vvolumesFrom\x12<\n" +
+	"\n" +
+	"post_start\x18P \x03(\v2\x1d.balena.composeparser.v1.HookR\tpostStart\x128\n" +
+	"\bpre_stop\x18Q \x03(\v2\x1d.balena.composeparser.v1.HookR\apreStop\x12Q\n" +
+	"\vstorage_opt\x18R \x03(\v20.balena.composeparser.v1.Service.StorageOptEntryR\n" +
+	"storageOpt\x12\x1c\n" +
+	"\tisolation\x18S \x01(\tR\tisolation\x12\x19\n" +
+	"\x05scale\x18T \x01(\x03H\x02R\x05scale\x88\x01\x01\x12$\n" +
+	"\x0euse_api_socket\x18U \x01(\bR\fuseApiSocket\x12G\n" +
+	"\fblkio_config\x18V \x01(\v2$.balena.composeparser.v1.BlkioConfigR\vblkioConfig\x12P\n" +
+	"\x0fcredential_spec\x18W \x01(\v2'.balena.composeparser.v1.CredentialSpecR\x0ecredentialSpec\x12;\n" +
+	"\benv_file\x18X \x03(\v2 .balena.composeparser.v1.EnvFileR\aenvFile\x12\x1d\n" +
+	"\n" +
+	"label_file\x18Y \x03(\tR\tlabelFile\x1a>\n" +
+	"\x10EnvironmentEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1ad\n" +
+	"\rNetworksEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12=\n" +
+	"\x05value\x18\x02 \x01(\v2'.balena.composeparser.v1.ServiceNetworkR\x05value:\x028\x01\x1aa\n" +
+	"\x0eDependsOnEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x129\n" +
+	"\x05value\x18\x02 \x01(\v2#.balena.composeparser.v1.DependencyR\x05value:\x028\x01\x1a:\n" +
+	"\fSysctlsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a[\n" +
+	"\fUlimitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.balena.composeparser.v1.UlimitR\x05value:\x028\x01\x1aU\n" +
+	"\x0fExtensionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value:\x028\x01\x1a>\n" +
+	"\x10AnnotationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a=\n" +
+	"\x0fStorageOptEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\a\n" +
+	"\x05_initB\t\n" +
+	"\a_attachB\b\n" +
+	"\x06_scale\"\xd6\x02\n" +
+	"\x04Hook\x12:\n" +
+	"\acommand\x18\x01 \x01(\v2 .balena.composeparser.v1.CommandR\acommand\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x1e\n" +
+	"\n" +
+	"privileged\x18\x03 \x01(\bR\n" +
+	"privileged\x12\x1f\n" +
+	"\vworking_dir\x18\x04 \x01(\tR\n" +
+	"workingDir\x12P\n" +
+	"\venvironment\x18\x05 \x03(\v2..balena.composeparser.v1.Hook.EnvironmentEntryR\venvironment\x12+\n" +
+	"\x11unset_environment\x18\x06 \x03(\tR\x10unsetEnvironment\x1a>\n" +
+	"\x10EnvironmentEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xef\x04\n" +
+	"\vBlkioConfig\x12\x16\n" +
+	"\x06weight\x18\x01 \x01(\rR\x06weight\x12V\n" +
+	"\rweight_device\x18\x02 \x03(\v21.balena.composeparser.v1.BlkioConfig.WeightDeviceR\fweightDevice\x12[\n" +
+	"\x0fdevice_read_bps\x18\x03 \x03(\v23.balena.composeparser.v1.BlkioConfig.ThrottleDeviceR\rdeviceReadBps\x12]\n" +
+	"\x10device_read_iops\x18\x04 \x03(\v23.balena.composeparser.v1.BlkioConfig.ThrottleDeviceR\x0edeviceReadIops\x12]\n" +
+	"\x10device_write_bps\x18\x05 \x03(\v23.balena.composeparser.v1.BlkioConfig.ThrottleDeviceR\x0edeviceWriteBps\x12_\n" +
+	"\x11device_write_iops\x18\x06 \x03(\v23.balena.composeparser.v1.BlkioConfig.ThrottleDeviceR\x0fdeviceWriteIops\x1a:\n" +
+	"\fWeightDevice\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\rR\x06weight\x1a8\n" +
+	"\x0eThrottleDevice\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\x03R\x04rate\"X\n" +
+	"\x0eCredentialSpec\x12\x16\n" +
+	"\x06config\x18\x01 \x01(\tR\x06config\x12\x12\n" +
+	"\x04file\x18\x02 \x01(\tR\x04file\x12\x1a\n" +
+	"\bregistry\x18\x03 \x01(\tR\bregistry\"Q\n" +
+	"\aEnvFile\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x1a\n" +
+	"\brequired\x18\x02 \x01(\bR\brequired\x12\x16\n" +
+	"\x06format\x18\x03 \x01(\tR\x06format\"\x1d\n" +
+	"\aCommand\x12\x12\n" +
+	"\x04args\x18\x01 \x03(\tR\x04args\"\xc6\v\n" +
+	"\x05Build\x12\x18\n" +
+	"\acontext\x18\x01 \x01(\tR\acontext\x12\x1e\n" +
+	"\n" +
+	"dockerfile\x18\x02 \x01(\tR\n" +
+	"dockerfile\x12+\n" +
+	"\x11dockerfile_inline\x18\x03 \x01(\tR\x10dockerfileInline\x12<\n" +
+	"\x04args\x18\x04 \x03(\v2(.balena.composeparser.v1.Build.ArgsEntryR\x04args\x12\x1d\n" +
+	"\n" +
+	"unset_args\x18\x05 \x03(\tR\tunsetArgs\x12B\n" +
+	"\x06labels\x18\x06 \x03(\v2*.balena.composeparser.v1.Build.LabelsEntryR\x06labels\x12\x16\n" +
+	"\x06target\x18\a \x01(\tR\x06target\x12\x18\n" +
+	"\anetwork\x18\b \x01(\tR\anetwork\x12\x1d\n" +
+	"\n" +
+	"cache_from\x18\t \x03(\tR\tcacheFrom\x12\x1c\n" +
+	"\tplatforms\x18\n" +
+	" \x03(\tR\tplatforms\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\x12g\n" +
+	"\x13additional_contexts\x18\f \x03(\v26.balena.composeparser.v1.Build.AdditionalContextsEntryR\x12additionalContexts\x12\x1f\n" +
+	"\vextra_hosts\x18\r \x03(\tR\n" +
+	"extraHosts\x12\x19\n" +
+	"\bshm_size\x18\x0e \x01(\x03R\ashmSize\x12E\n" +
+	"\aulimits\x18\x0f \x03(\v2+.balena.composeparser.v1.Build.UlimitsEntryR\aulimits\x12N\n" +
+	"\n" +
+	"extensions\x18\x10 \x03(\v2..balena.composeparser.v1.Build.ExtensionsEntryR\n" +
+	"extensions\x12\"\n" +
+	"\fentitlements\x18\x11 \x03(\tR\fentitlements\x127\n" +
+	"\x03ssh\x18\x12 \x03(\v2%.balena.composeparser.v1.Build.SshKeyR\x03ssh\x12\x19\n" +
+	"\bcache_to\x18\x13 \x03(\tR\acacheTo\x12\x19\n" +
+	"\bno_cache\x18\x14 \x01(\bR\anoCache\x12\x12\n" +
+	"\x04pull\x18\x15 \x01(\bR\x04pull\x12\x1c\n" +
+	"\tisolation\x18\x16 \x01(\tR\tisolation\x12@\n" +
+	"\asecrets\x18\x17 \x03(\v2&.balena.composeparser.v1.FileReferenceR\asecrets\x12\x1e\n" +
+	"\n" +
+	"privileged\x18\x18 \x01(\bR\n" +
+	"privileged\x12\x1e\n" +
+	"\n" +
+	"provenance\x18\x19 \x01(\tR\n" +
+	"provenance\x12\x12\n" +
+	"\x04sbom\x18\x1a \x01(\tR\x04sbom\x1a7\n" +
+	"\tArgsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aE\n" +
+	"\x17AdditionalContextsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a[\n" +
+	"\fUlimitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x125\n" +
+	"\x05value\x18\x02 \x01(\v2\x1f.balena.composeparser.v1.UlimitR\x05value:\x028\x01\x1aU\n" +
+	"\x0fExtensionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value:\x028\x01\x1a,\n" +
+	"\x06SshKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04path\x18\x02 \x01(\tR\x04path\"\xbc\x01\n" +
+	"\x04Port\x12\x16\n" +
+	"\x06target\x18\x01 \x01(\rR\x06target\x12\x1c\n" +
+	"\tpublished\x18\x02 \x01(\tR\tpublished\x12\x17\n" +
+	"\ahost_ip\x18\x03 \x01(\tR\x06hostIp\x12\x1a\n" +
+	"\bprotocol\x18\x04 \x01(\tR\bprotocol\x12\x12\n" +
+	"\x04mode\x18\x05 \x01(\tR\x04mode\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x12!\n" +
+	"\fapp_protocol\x18\a \x01(\tR\vappProtocol\"\x8d\a\n" +
+	"\rServiceVolume\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x1b\n" +
+	"\tread_only\x18\x04 \x01(\bR\breadOnly\x12F\n" +
+	"\x04bind\x18\x05 \x01(\v22.balena.composeparser.v1.ServiceVolume.BindOptionsR\x04bind\x12L\n" +
+	"\x06volume\x18\x06 \x01(\v24.balena.composeparser.v1.ServiceVolume.VolumeOptionsR\x06volume\x12I\n" +
+	"\x05tmpfs\x18\a \x01(\v23.balena.composeparser.v1.ServiceVolume.TmpfsOptionsR\x05tmpfs\x12 \n" +
+	"\vconsistency\x18\b \x01(\tR\vconsistency\x12I\n" +
+	"\x05image\x18\t \x01(\v23.balena.composeparser.v1.ServiceVolume.ImageOptionsR\x05image\x1a\x91\x01\n" +
+	"\vBindOptions\x12 \n" +
+	"\vpropagation\x18\x01 \x01(\tR\vpropagation\x12(\n" +
+	"\x10create_host_path\x18\x02 \x01(\bR\x0ecreateHostPath\x12\x18\n" +
+	"\aselinux\x18\x03 \x01(\tR\aselinux\x12\x1c\n" +
+	"\trecursive\x18\x04 \x01(\tR\trecursive\x1a\xd6\x01\n" +
+	"\rVolumeOptions\x12\x16\n" +
+	"\x06nocopy\x18\x01 \x01(\bR\x06nocopy\x12\x18\n" +
+	"\asubpath\x18\x02 \x01(\tR\asubpath\x12X\n" +
+	"\x06labels\x18\x03 \x03(\v2@.balena.composeparser.v1.ServiceVolume.VolumeOptions.LabelsEntryR\x06labels\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a(\n" +
+	"\fImageOptions\x12\x18\n" +
+	"\asubpath\x18\x01 \x01(\tR\asubpath\x1a6\n" +
+	"\fTmpfsOptions\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\rR\x04mode\"\xb4\x03\n" +
+	"\x0eServiceNetwork\x12\x18\n" +
+	"\aaliases\x18\x01 \x03(\tR\aaliases\x12!\n" +
+	"\fipv4_address\x18\x02 \x01(\tR\vipv4Address\x12!\n" +
+	"\fipv6_address\x18\x03 \x01(\tR\vipv6Address\x12$\n" +
+	"\x0elink_local_ips\x18\x04 \x03(\tR\flinkLocalIps\x12\x1f\n" +
+	"\vmac_address\x18\x05 \x01(\tR\n" +
+	"macAddress\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\x05R\bpriority\x12\x1f\n" +
+	"\vgw_priority\x18\a \x01(\x05R\n" +
+	"gwPriority\x12X\n" +
+	"\vdriver_opts\x18\b \x03(\v27.balena.composeparser.v1.ServiceNetwork.DriverOptsEntryR\n" +
+	"driverOpts\x12%\n" +
+	"\x0einterface_name\x18\t \x01(\tR\rinterfaceName\x1a=\n" +
+	"\x0fDriverOptsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"`\n" +
+	"\n" +
+	"Dependency\x12\x1c\n" +
+	"\tcondition\x18\x01 \x01(\tR\tcondition\x12\x18\n" +
+	"\arestart\x18\x02 \x01(\bR\arestart\x12\x1a\n" +
+	"\brequired\x18\x03 \x01(\bR\brequired\"Z\n" +
+	"\x06Device\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12 \n" +
+	"\vpermissions\x18\x03 \x01(\tR\vpermissions\"\x8b\x02\n" +
+	"\rDeviceRequest\x12\x16\n" +
+	"\x06driver\x18\x01 \x01(\tR\x06driver\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x03 \x03(\tR\tdeviceIds\x12\"\n" +
+	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\x12M\n" +
+	"\aoptions\x18\x05 \x03(\v23.balena.composeparser.v1.DeviceRequest.OptionsEntryR\aoptions\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xd2\x02\n" +
+	"\vHealthcheck\x12\x12\n" +
+	"\x04test\x18\x01 \x03(\tR\x04test\x125\n" +
+	"\binterval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\binterval\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x12<\n" +
+	"\fstart_period\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\vstartPeriod\x12@\n" +
+	"\x0estart_interval\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\rstartInterval\x12\x1d\n" +
+	"\aretries\x18\x06 \x01(\x04H\x00R\aretries\x88\x01\x01\x12\x18\n" +
+	"\adisable\x18\a \x01(\bR\adisableB\n" +
+	"\n" +
+	"\b_retries\"0\n" +
+	"\x06Ulimit\x12\x12\n" +
+	"\x04soft\x18\x01 \x01(\x03R\x04soft\x12\x12\n" +
+	"\x04hard\x18\x02 \x01(\x03R\x04hard\"\xa6\x01\n" +
+	"\aLogging\x12\x16\n" +
+	"\x06driver\x18\x01 \x01(\tR\x06driver\x12G\n" +
+	"\aoptions\x18\x02 \x03(\v2-.balena.composeparser.v1.Logging.OptionsEntryR\aoptions\x1a:\n" +
+	"\fOptionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe9\x03\n" +
+	"\x06Deploy\x12C\n" +
+	"\x06labels\x18\x01 \x03(\v2+.balena.composeparser.v1.Deploy.LabelsEntryR\x06labels\x12@\n" +
+	"\x06limits\x18\x02 \x01(\v2(.balena.composeparser.v1.Deploy.ResourceR\x06limits\x12L\n" +
+	"\freservations\x18\x03 \x01(\v2(.balena.composeparser.v1.Deploy.ResourceR\freservations\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\tR\x04mode\x12\x1f\n" +
+	"\breplicas\x18\x05 \x01(\x03H\x00R\breplicas\x88\x01\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a\x8c\x01\n" +
+	"\bResource\x12\x12\n" +
+	"\x04cpus\x18\x01 \x01(\x02R\x04cpus\x12\x16\n" +
+	"\x06memory\x18\x02 \x01(\x03R\x06memory\x12\x12\n" +
+	"\x04pids\x18\x03 \x01(\x03R\x04pids\x12@\n" +
+	"\adevices\x18\x04 \x03(\v2&.balena.composeparser.v1.DeviceRequestR\adevicesB\v\n" +
+	"\t_replicas\"\x85\x01\n" +
+	"\rFileReference\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\x12\x10\n" +
+	"\x03uid\x18\x03 \x01(\tR\x03uid\x12\x10\n" +
+	"\x03gid\x18\x04 \x01(\tR\x03gid\x12\x17\n" +
+	"\x04mode\x18\x05 \x01(\rH\x00R\x04mode\x88\x01\x01B\a\n" +
+	"\x05_mode\"\xc4\b\n" +
+	"\aNetwork\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06driver\x18\x02 \x01(\tR\x06driver\x12Q\n" +
+	"\vdriver_opts\x18\x03 \x03(\v20.balena.composeparser.v1.Network.DriverOptsEntryR\n" +
+	"driverOpts\x12D\n" +
+	"\x06labels\x18\x04 \x03(\v2,.balena.composeparser.v1.Network.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bexternal\x18\x05 \x01(\bR\bexternal\x12\x1a\n" +
+	"\binternal\x18\x06 \x01(\bR\binternal\x12\x1e\n" +
+	"\n" +
+	"attachable\x18\a \x01(\bR\n" +
+	"attachable\x12$\n" +
+	"\venable_ipv6\x18\b \x01(\bH\x00R\n" +
+	"enableIpv6\x88\x01\x01\x129\n" +
+	"\x04ipam\x18\t \x01(\v2%.balena.composeparser.v1.Network.IpamR\x04ipam\x12P\n" +
+	"\n" +
+	"extensions\x18\n" +
+	" \x03(\v20.balena.composeparser.v1.Network.ExtensionsEntryR\n" +
+	"extensions\x12$\n" +
+	"\venable_ipv4\x18\v \x01(\bH\x01R\n" +
+	"enableIpv4\x88\x01\x01\x1a=\n" +
+	"\x0fDriverOptsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aU\n" +
+	"\x0fExtensionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value:\x028\x01\x1a]\n" +
+	"\x04Ipam\x12\x16\n" +
+	"\x06driver\x18\x01 \x01(\tR\x06driver\x12=\n" +
+	"\x06config\x18\x02 \x03(\v2%.balena.composeparser.v1.Network.PoolR\x06config\x1a\xf2\x01\n" +
+	"\x04Pool\x12\x16\n" +
+	"\x06subnet\x18\x01 \x01(\tR\x06subnet\x12\x18\n" +
+	"\agateway\x18\x02 \x01(\tR\agateway\x12\x19\n" +
+	"\bip_range\x18\x03 \x01(\tR\aipRange\x12\\\n" +
+	"\raux_addresses\x18\x04 \x03(\v27.balena.composeparser.v1.Network.Pool.AuxAddressesEntryR\fauxAddresses\x1a?\n" +
+	"\x11AuxAddressesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0e\n" +
+	"\f_enable_ipv6B\x0e\n" +
+	"\f_enable_ipv4\"\x89\x04\n" +
+	"\x06Volume\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06driver\x18\x02 \x01(\tR\x06driver\x12P\n" +
+	"\vdriver_opts\x18\x03 \x03(\v2/.balena.composeparser.v1.Volume.DriverOptsEntryR\n" +
+	"driverOpts\x12C\n" +
+	"\x06labels\x18\x04 \x03(\v2+.balena.composeparser.v1.Volume.LabelsEntryR\x06labels\x12\x1a\n" +
+	"\bexternal\x18\x05 \x01(\bR\bexternal\x12O\n" +
+	"\n" +
+	"extensions\x18\x06 \x03(\v2/.balena.composeparser.v1.Volume.ExtensionsEntryR\n" +
+	"extensions\x1a=\n" +
+	"\x0fDriverOptsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aU\n" +
+	"\x0fExtensionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value:\x028\x01\"\x92\x05\n" +
+	"\n" +
+	"FileObject\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04file\x18\x02 \x01(\tR\x04file\x12 \n" +
+	"\venvironment\x18\x03 \x01(\tR\venvironment\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12\x1a\n" +
+	"\bexternal\x18\x05 \x01(\bR\bexternal\x12G\n" +
+	"\x06labels\x18\x06 \x03(\v2/.balena.composeparser.v1.FileObject.LabelsEntryR\x06labels\x12S\n" +
+	"\n" +
+	"extensions\x18\a \x03(\v23.balena.composeparser.v1.FileObject.ExtensionsEntryR\n" +
+	"extensions\x12\x16\n" +
+	"\x06driver\x18\b \x01(\tR\x06driver\x12T\n" +
+	"\vdriver_opts\x18\t \x03(\v23.balena.composeparser.v1.FileObject.DriverOptsEntryR\n" +
+	"driverOpts\x12'\n" +
+	"\x0ftemplate_driver\x18\n" +
+	" \x01(\tR\x0etemplateDriver\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1aU\n" +
+	"\x0fExtensionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value:\x028\x01\x1a=\n" +
+	"\x0fDriverOptsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B$Z\"balena-compose-parser/pkg/parserpbb\x06proto3"
+
+var (
+	file_project_proto_rawDescOnce sync.Once
+	file_project_proto_rawDescData []byte
+)
+
+func file_project_proto_rawDescGZIP() []byte {
+	file_project_proto_rawDescOnce.Do(func() {
+		file_project_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_project_proto_rawDesc), len(file_project_proto_rawDesc)))
+	})
+	return file_project_proto_rawDescData
+}
+
+var file_project_proto_msgTypes = make([]protoimpl.MessageInfo, 68)
+var file_project_proto_goTypes = []any{
+	(*Project)(nil),                     // 0: balena.composeparser.v1.Project
+	(*Service)(nil),                     // 1: balena.composeparser.v1.Service
+	(*Hook)(nil),                        // 2: balena.composeparser.v1.Hook
+	(*BlkioConfig)(nil),                 // 3: balena.composeparser.v1.BlkioConfig
+	(*CredentialSpec)(nil),              // 4: balena.composeparser.v1.CredentialSpec
+	(*EnvFile)(nil),                     // 5: balena.composeparser.v1.EnvFile
+	(*Command)(nil),                     // 6: balena.composeparser.v1.Command
+	(*Build)(nil),                       // 7: balena.composeparser.v1.Build
+	(*Port)(nil),                        // 8: balena.composeparser.v1.Port
+	(*ServiceVolume)(nil),               // 9: balena.composeparser.v1.ServiceVolume
+	(*ServiceNetwork)(nil),              // 10: balena.composeparser.v1.ServiceNetwork
+	(*Dependency)(nil),                  // 11: balena.composeparser.v1.Dependency
+	(*Device)(nil),                      // 12: balena.composeparser.v1.Device
+	(*DeviceRequest)(nil),               // 13: balena.composeparser.v1.DeviceRequest
+	(*Healthcheck)(nil),                 // 14: balena.composeparser.v1.Healthcheck
+	(*Ulimit)(nil),                      // 15: balena.composeparser.v1.Ulimit
+	(*Logging)(nil),                     // 16: balena.composeparser.v1.Logging
+	(*Deploy)(nil),                      // 17: balena.composeparser.v1.Deploy
+	(*FileReference)(nil),               // 18: balena.composeparser.v1.FileReference
+	(*Network)(nil),                     // 19: balena.composeparser.v1.Network
+	(*Volume)(nil),                      // 20: balena.composeparser.v1.Volume
+	(*FileObject)(nil),                  // 21: balena.composeparser.v1.FileObject
+	nil,                                 // 22: balena.composeparser.v1.Project.ServicesEntry
+	nil,                                 // 23: balena.composeparser.v1.Project.NetworksEntry
+	nil,                                 // 24: balena.composeparser.v1.Project.VolumesEntry
+	nil,                                 // 25: balena.composeparser.v1.Project.ConfigsEntry
+	nil,                                 // 26: balena.composeparser.v1.Project.SecretsEntry
+	nil,                                 // 27: balena.composeparser.v1.Project.ExtensionsEntry
+	nil,                                 // 28: balena.composeparser.v1.Service.EnvironmentEntry
+	nil,                                 // 29: balena.composeparser.v1.Service.LabelsEntry
+	nil,                                 // 30: balena.composeparser.v1.Service.NetworksEntry
+	nil,                                 // 31: balena.composeparser.v1.Service.DependsOnEntry
+	nil,                                 // 32: balena.composeparser.v1.Service.SysctlsEntry
+	nil,                                 // 33: balena.composeparser.v1.Service.UlimitsEntry
+	nil,                                 // 34: balena.composeparser.v1.Service.ExtensionsEntry
+	nil,                                 // 35: balena.composeparser.v1.Service.AnnotationsEntry
+	nil,                                 // 36: balena.composeparser.v1.Service.StorageOptEntry
+	nil,                                 // 37: balena.composeparser.v1.Hook.EnvironmentEntry
+	(*BlkioConfig_WeightDevice)(nil),    // 38: balena.composeparser.v1.BlkioConfig.WeightDevice
+	(*BlkioConfig_ThrottleDevice)(nil),  // 39: balena.composeparser.v1.BlkioConfig.ThrottleDevice
+	nil,                                 // 40: balena.composeparser.v1.Build.ArgsEntry
+	nil,                                 // 41: balena.composeparser.v1.Build.LabelsEntry
+	nil,                                 // 42: balena.composeparser.v1.Build.AdditionalContextsEntry
+	nil,                                 // 43: balena.composeparser.v1.Build.UlimitsEntry
+	nil,                                 // 44: balena.composeparser.v1.Build.ExtensionsEntry
+	(*Build_SshKey)(nil),                // 45: balena.composeparser.v1.Build.SshKey
+	(*ServiceVolume_BindOptions)(nil),   // 46: balena.composeparser.v1.ServiceVolume.BindOptions
+	(*ServiceVolume_VolumeOptions)(nil), // 47: balena.composeparser.v1.ServiceVolume.VolumeOptions
+	(*ServiceVolume_ImageOptions)(nil),  // 48: balena.composeparser.v1.ServiceVolume.ImageOptions
+	(*ServiceVolume_TmpfsOptions)(nil),  // 49: balena.composeparser.v1.ServiceVolume.TmpfsOptions
+	nil,                                 // 50: balena.composeparser.v1.ServiceVolume.VolumeOptions.LabelsEntry
+	nil,                                 // 51: balena.composeparser.v1.ServiceNetwork.DriverOptsEntry
+	nil,                                 // 52: balena.composeparser.v1.DeviceRequest.OptionsEntry
+	nil,                                 // 53: balena.composeparser.v1.Logging.OptionsEntry
+	nil,                                 // 54: balena.composeparser.v1.Deploy.LabelsEntry
+	(*Deploy_Resource)(nil),             // 55: balena.composeparser.v1.Deploy.Resource
+	nil,                                 // 56: balena.composeparser.v1.Network.DriverOptsEntry
+	nil,                                 // 57: balena.composeparser.v1.Network.LabelsEntry
+	nil,                                 // 58: balena.composeparser.v1.Network.ExtensionsEntry
+	(*Network_Ipam)(nil),                // 59: balena.composeparser.v1.Network.Ipam
+	(*Network_Pool)(nil),                // 60: balena.composeparser.v1.Network.Pool
+	nil,                                 // 61: balena.composeparser.v1.Network.Pool.AuxAddressesEntry
+	nil,                                 // 62: balena.composeparser.v1.Volume.DriverOptsEntry
+	nil,                                 // 63: balena.composeparser.v1.Volume.LabelsEntry
+	nil,                                 // 64: balena.composeparser.v1.Volume.ExtensionsEntry
+	nil,                                 // 65: balena.composeparser.v1.FileObject.LabelsEntry
+	nil,                                 // 66: balena.composeparser.v1.FileObject.ExtensionsEntry
+	nil,                                 // 67: balena.composeparser.v1.FileObject.DriverOptsEntry
+	(*durationpb.Duration)(nil),         // 68: google.protobuf.Duration
+	(*structpb.Value)(nil),              // 69: google.protobuf.Value
+}
+var file_project_proto_depIdxs = []int32{
+	22, // 0: balena.composeparser.v1.Project.services:type_name -> balena.composeparser.v1.Project.ServicesEntry
+	23, // 1: balena.composeparser.v1.Project.networks:type_name -> balena.composeparser.v1.Project.NetworksEntry
+	24, // 2: balena.composeparser.v1.Project.volumes:type_name -> balena.composeparser.v1.Project.VolumesEntry
+	25, // 3: balena.composeparser.v1.Project.configs:type_name -> balena.composeparser.v1.Project.ConfigsEntry
+	26, // 4: balena.composeparser.v1.Project.secrets:type_name -> balena.composeparser.v1.Project.SecretsEntry
+	27, // 5: balena.composeparser.v1.Project.extensions:type_name -> balena.composeparser.v1.Project.ExtensionsEntry
+	7,  // 6: balena.composeparser.v1.Service.build:type_name -> balena.composeparser.v1.Build
+	6,  // 7: balena.composeparser.v1.Service.command:type_name -> balena.composeparser.v1.Command
+	6,  // 8: balena.composeparser.v1.Service.entrypoint:type_name -> balena.composeparser.v1.Command
+	28, // 9: balena.composeparser.v1.Service.environment:type_name -> balena.composeparser.v1.Service.EnvironmentEntry
+	29, // 10: balena.composeparser.v1.Service.labels:type_name -> balena.composeparser.v1.Service.LabelsEntry
+	8,  // 11: balena.composeparser.v1.Service.ports:type_name -> balena.composeparser.v1.Port
+	9,  // 12: balena.composeparser.v1.Service.volumes:type_name -> balena.composeparser.v1.ServiceVolume
+	30, // 13: balena.composeparser.v1.Service.networks:type_name -> balena.composeparser.v1.Service.NetworksEntry
+	31, // 14: balena.composeparser.v1.Service.depends_on:type_name -> balena.composeparser.v1.Service.DependsOnEntry
+	12, // 15: balena.composeparser.v1.Service.devices:type_name -> balena.composeparser.v1.Device
+	13, // 16: balena.composeparser.v1.Service.gpus:type_name -> balena.composeparser.v1.DeviceRequest
+	14, // 17: balena.composeparser.v1.Service.healthcheck:type_name -> balena.composeparser.v1.Healthcheck
+	32, // 18: balena.composeparser.v1.Service.sysctls:type_name -> balena.composeparser.v1.Service.SysctlsEntry
+	33, // 19: balena.composeparser.v1.Service.ulimits:type_name -> balena.composeparser.v1.Service.UlimitsEntry
+	68, // 20: balena.composeparser.v1.Service.stop_grace_period:type_name -> google.protobuf.Duration
+	16, // 21: balena.composeparser.v1.Service.logging:type_name -> balena.composeparser.v1.Logging
+	17, // 22: balena.composeparser.v1.Service.deploy:type_name -> balena.composeparser.v1.Deploy
+	18, // 23: balena.composeparser.v1.Service.configs:type_name -> balena.composeparser.v1.FileReference
+	18, // 24: balena.composeparser.v1.Service.secrets:type_name -> balena.composeparser.v1.FileReference
+	34, // 25: balena.composeparser.v1.Service.extensions:type_name -> balena.composeparser.v1.Service.ExtensionsEntry
+	35, // 26: balena.composeparser.v1.Service.annotations:type_name -> balena.composeparser.v1.Service.AnnotationsEntry
+	2,  // 27: balena.composeparser.v1.Service.post_start:type_name -> balena.composeparser.v1.Hook
+	2,  // 28: balena.composeparser.v1.Service.pre_stop:type_name -> balena.composeparser.v1.Hook
+	36, // 29: balena.composeparser.v1.Service.storage_opt:type_name -> balena.composeparser.v1.Service.StorageOptEntry
+	3,  // 30: balena.composeparser.v1.Service.blkio_config:type_name -> balena.composeparser.v1.BlkioConfig
+	4,  // 31: balena.composeparser.v1.Service.credential_spec:type_name -> balena.composeparser.v1.CredentialSpec
+	5,  // 32: balena.composeparser.v1.Service.env_file:type_name -> balena.composeparser.v1.EnvFile
+	6,  // 33: balena.composeparser.v1.Hook.command:type_name -> balena.composeparser.v1.Command
+	37, // 34: balena.composeparser.v1.Hook.environment:type_name -> balena.composeparser.v1.Hook.EnvironmentEntry
+	38, // 35: balena.composeparser.v1.BlkioConfig.weight_device:type_name -> balena.composeparser.v1.BlkioConfig.WeightDevice
+	39, // 36: balena.composeparser.v1.BlkioConfig.device_read_bps:type_name -> balena.composeparser.v1.BlkioConfig.ThrottleDevice
+	39, // 37: balena.composeparser.v1.BlkioConfig.device_read_iops:type_name -> balena.composeparser.v1.BlkioConfig.ThrottleDevice
+	39, // 38: balena.composeparser.v1.BlkioConfig.device_write_bps:type_name -> balena.composeparser.v1.BlkioConfig.ThrottleDevice
+	39, // 39: balena.composeparser.v1.BlkioConfig.device_write_iops:type_name -> balena.composeparser.v1.BlkioConfig.ThrottleDevice
+	40, // 40: balena.composeparser.v1.Build.args:type_name -> balena.composeparser.v1.Build.ArgsEntry
+	41, // 41: balena.composeparser.v1.Build.labels:type_name -> balena.composeparser.v1.Build.LabelsEntry
+	42, // 42: balena.composeparser.v1.Build.additional_contexts:type_name -> balena.composeparser.v1.Build.AdditionalContextsEntry
+	43, // 43: balena.composeparser.v1.Build.ulimits:type_name -> balena.composeparser.v1.Build.UlimitsEntry
+	44, // 44: balena.composeparser.v1.Build.extensions:type_name -> balena.composeparser.v1.Build.ExtensionsEntry
+	45, // 45: balena.composeparser.v1.Build.ssh:type_name -> balena.composeparser.v1.Build.SshKey
+	18, // 46: balena.composeparser.v1.Build.secrets:type_name -> balena.composeparser.v1.FileReference
+	46, // 47: balena.composeparser.v1.ServiceVolume.bind:type_name -> balena.composeparser.v1.ServiceVolume.BindOptions
+	47, // 48: balena.composeparser.v1.ServiceVolume.volume:type_name -> balena.composeparser.v1.ServiceVolume.VolumeOptions
+	49, // 49: balena.composeparser.v1.ServiceVolume.tmpfs:type_name -> balena.composeparser.v1.ServiceVolume.TmpfsOptions
+	48, // 50: balena.composeparser.v1.ServiceVolume.image:type_name -> balena.composeparser.v1.ServiceVolume.ImageOptions
+	51, // 51: balena.composeparser.v1.ServiceNetwork.driver_opts:type_name -> balena.composeparser.v1.ServiceNetwork.DriverOptsEntry
+	52, // 52: balena.composeparser.v1.DeviceRequest.options:type_name -> balena.composeparser.v1.DeviceRequest.OptionsEntry
+	68, // 53: balena.composeparser.v1.Healthcheck.interval:type_name -> google.protobuf.Duration
+	68, // 54: balena.composeparser.v1.Healthcheck.timeout:type_name -> google.protobuf.Duration
+	68, // 55: balena.composeparser.v1.Healthcheck.start_period:type_name -> google.protobuf.Duration
+	68, // 56: balena.composeparser.v1.Healthcheck.start_interval:type_name -> google.protobuf.Duration
+	53, // 57: balena.composeparser.v1.Logging.options:type_name -> balena.composeparser.v1.Logging.OptionsEntry
+	54, // 58: balena.composeparser.v1.Deploy.labels:type_name -> balena.composeparser.v1.Deploy.LabelsEntry
+	55, // 59: balena.composeparser.v1.Deploy.limits:type_name -> balena.composeparser.v1.Deploy.Resource
+	55, // 60: balena.composeparser.v1.Deploy.reservations:type_name -> balena.composeparser.v1.Deploy.Resource
+	56, // 61: balena.composeparser.v1.Network.driver_opts:type_name -> balena.composeparser.v1.Network.DriverOptsEntry
+	57, // 62: balena.composeparser.v1.Network.labels:type_name -> balena.composeparser.v1.Network.LabelsEntry
+	59, // 63: balena.composeparser.v1.Network.ipam:type_name -> balena.composeparser.v1.Network.Ipam
+	58, // 64: balena.composeparser.v1.Network.extensions:type_name -> balena.composeparser.v1.Network.ExtensionsEntry
+	62, // 65: balena.composeparser.v1.Volume.driver_opts:type_name -> balena.composeparser.v1.Volume.DriverOptsEntry
+	63, // 66: balena.composeparser.v1.Volume.labels:type_name -> balena.composeparser.v1.Volume.LabelsEntry
+	64, // 67: balena.composeparser.v1.Volume.extensions:type_name -> balena.composeparser.v1.Volume.ExtensionsEntry
+	65, // 68: balena.composeparser.v1.FileObject.labels:type_name -> balena.composeparser.v1.FileObject.LabelsEntry
+	66, // 69: balena.composeparser.v1.FileObject.extensions:type_name -> balena.composeparser.v1.FileObject.ExtensionsEntry
+	67, // 70: balena.composeparser.v1.FileObject.driver_opts:type_name -> balena.composeparser.v1.FileObject.DriverOptsEntry
+	1,  // 71: balena.composeparser.v1.Project.ServicesEntry.value:type_name -> balena.composeparser.v1.Service
+	19, // 72: balena.composeparser.v1.Project.NetworksEntry.value:type_name -> balena.composeparser.v1.Network
+	20, // 73: balena.composeparser.v1.Project.VolumesEntry.value:type_name -> balena.composeparser.v1.Volume
+	21, // 74: balena.composeparser.v1.Project.ConfigsEntry.value:type_name -> balena.composeparser.v1.FileObject
+	21, // 75: balena.composeparser.v1.Project.SecretsEntry.value:type_name -> balena.composeparser.v1.FileObject
+	69, // 76: balena.composeparser.v1.Project.ExtensionsEntry.value:type_name -> google.protobuf.Value
+	10, // 77: balena.composeparser.v1.Service.NetworksEntry.value:type_name -> balena.composeparser.v1.ServiceNetwork
+	11, // 78: balena.composeparser.v1.Service.DependsOnEntry.value:type_name -> balena.composeparser.v1.Dependency
+	15, // 79: balena.composeparser.v1.Service.UlimitsEntry.value:type_name -> balena.composeparser.v1.Ulimit
+	69, // 80: balena.composeparser.v1.Service.ExtensionsEntry.value:type_name -> google.protobuf.Value
+	15, // 81: balena.composeparser.v1.Build.UlimitsEntry.value:type_name -> balena.composeparser.v1.Ulimit
+	69, // 82: balena.composeparser.v1.Build.ExtensionsEntry.value:type_name -> google.protobuf.Value
+	50, // 83: balena.composeparser.v1.ServiceVolume.VolumeOptions.labels:type_name -> balena.composeparser.v1.ServiceVolume.VolumeOptions.LabelsEntry
+	13, // 84: balena.composeparser.v1.Deploy.Resource.devices:type_name -> balena.composeparser.v1.DeviceRequest
+	69, // 85: balena.composeparser.v1.Network.ExtensionsEntry.value:type_name -> google.protobuf.Value
+	60, // 86: balena.composeparser.v1.Network.Ipam.config:type_name -> balena.composeparser.v1.Network.Pool
+	61, // 87: balena.composeparser.v1.Network.Pool.aux_addresses:type_name -> balena.composeparser.v1.Network.Pool.AuxAddressesEntry
+	69, // 88: balena.composeparser.v1.Volume.ExtensionsEntry.value:type_name -> google.protobuf.Value
+	69, // 89: balena.composeparser.v1.FileObject.ExtensionsEntry.value:type_name -> google.protobuf.Value
+	90, // [90:90] is the sub-list for method output_type
+	90, // [90:90] is the sub-list for method input_type
+	90, // [90:90] is the sub-list for extension type_name
+	90, // [90:90] is the sub-list for extension extendee
+	0,  // [0:90] is the sub-list for field type_name
+}
+
+func init() { file_project_proto_init() }
+func file_project_proto_init() {
+	if File_project_proto != nil {
+		return
+	}
+	file_project_proto_msgTypes[1].OneofWrappers = []any{}
+	file_project_proto_msgTypes[14].OneofWrappers = []any{}
+	file_project_proto_msgTypes[17].OneofWrappers = []any{}
+	file_project_proto_msgTypes[18].OneofWrappers = []any{}
+	file_project_proto_msgTypes[19].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_project_proto_rawDesc), len(file_project_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   68,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_project_proto_goTypes,
+		DependencyIndexes: file_project_proto_depIdxs,
+		MessageInfos:      file_project_proto_msgTypes,
+	}.Build()
+	File_project_proto = out.File
+	file_project_proto_goTypes = nil
+	file_project_proto_depIdxs = nil
+}
@@ -0,0 +1,523 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"balena-compose-parser/pkg/parserpb"
+)
+
+// Serialize the project as a parserpb.Project, in the protobuf binary encoding, or the text format if text.
+// Binary output is deterministic, with map entries sorted by key.
+func marshalProjectProto(project *types.Project, text bool) ([]byte, error) {
+	message, err := projectProto(project)
+	if err != nil {
+		return nil, err
+	}
+	if text {
+		return prototext.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(message)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(message)
+}
+
+// Convert a normalized project into its protobuf message
+func projectProto(project *types.Project) (*parserpb.Project, error) {
+	extensions, err := extensionsProto(project.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	message := &parserpb.Project{
+		Name:       project.Name,
+		Services:   map[string]*parserpb.Service{},
+		Networks:   map[string]*parserpb.Network{},
+		Volumes:    map[string]*parserpb.Volume{},
+		Configs:    map[string]*parserpb.FileObject{},
+		Secrets:    map[string]*parserpb.FileObject{},
+		Extensions: extensions,
+	}
+	for name, service := range project.Services {
+		if message.Services[name], err = serviceProto(service); err != nil {
+			return nil, err
+		}
+	}
+	for name, network := range project.Networks {
+		if message.Networks[name], err = networkProto(network); err != nil {
+			return nil, err
+		}
+	}
+	for name, volume := range project.Volumes {
+		if extensions, err = extensionsProto(volume.Extensions); err != nil {
+			return nil, err
+		}
+		message.Volumes[name] = &parserpb.Volume{
+			Name:       volume.Name,
+			Driver:     volume.Driver,
+			DriverOpts: volume.DriverOpts,
+			Labels:     volume.Labels,
+			External:   bool(volume.External),
+			Extensions: extensions,
+		}
+	}
+	for name, config := range project.Configs {
+		if message.Configs[name], err = fileObjectProto(types.FileObjectConfig(config)); err != nil {
+			return nil, err
+		}
+	}
+	for name, secret := range project.Secrets {
+		if message.Secrets[name], err = fileObjectProto(types.FileObjectConfig(secret)); err != nil {
+			return nil, err
+		}
+	}
+	return message, nil
+}
+
+func serviceProto(service types.ServiceConfig) (*parserpb.Service, error) {
+	extensions, err := extensionsProto(service.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	environment, unsetEnvironment := mappingProto(service.Environment)
+	message := &parserpb.Service{
+		Image:             service.Image,
+		Command:           commandProto(service.Command),
+		Entrypoint:        commandProto(service.Entrypoint),
+		Environment:       environment,
+		UnsetEnvironment:  unsetEnvironment,
+		Labels:            service.Labels,
+		Expose:            service.Expose,
+		Networks:          map[string]*parserpb.ServiceNetwork{},
+		DependsOn:         map[string]*parserpb.Dependency{},
+		Restart:           service.Restart,
+		Privileged:        service.Privileged,
+		NetworkMode:       service.NetworkMode,
+		CapAdd:            service.CapAdd,
+		CapDrop:           service.CapDrop,
+		DeviceCgroupRules: service.DeviceCgroupRules,
+		Gpus:              deviceRequestsProto(service.Gpus),
+		WorkingDir:        service.WorkingDir,
+		User:              service.User,
+		GroupAdd:          service.GroupAdd,
+		Hostname:          service.Hostname,
+		Domainname:        service.DomainName,
+		ContainerName:     service.ContainerName,
+		ExtraHosts:        service.ExtraHosts.AsList(":"),
+		Dns:               service.DNS,
+		DnsOpt:            service.DNSOpts,
+		DnsSearch:         service.DNSSearch,
+		Sysctls:           service.Sysctls,
+		Ulimits:           ulimitsProto(service.Ulimits),
+		Tmpfs:             service.Tmpfs,
+		Pid:               service.Pid,
+		Ipc:               service.Ipc,
+		Uts:               service.Uts,
+		UsernsMode:        service.UserNSMode,
+		Cgroup:            service.Cgroup,
+		SecurityOpt:       service.SecurityOpt,
+		ReadOnly:          service.ReadOnly,
+		Tty:               service.Tty,
+		StdinOpen:         service.StdinOpen,
+		Init:              service.Init,
+		StopSignal:        service.StopSignal,
+		StopGracePeriod:   durationProto(service.StopGracePeriod),
+		MemLimit:          int64(service.MemLimit),
+		MemReservation:    int64(service.MemReservation),
+		MemswapLimit:      int64(service.MemSwapLimit),
+		ShmSize:           int64(service.ShmSize),
+		Cpus:              service.CPUS,
+		CpuShares:         service.CPUShares,
+		CpuPeriod:         service.CPUPeriod,
+		CpuQuota:          service.CPUQuota,
+		Cpuset:            service.CPUSet,
+		PidsLimit:         service.PidsLimit,
+		OomScoreAdj:       service.OomScoreAdj,
+		OomKillDisable:    service.OomKillDisable,
+		Runtime:           service.Runtime,
+		Platform:          service.Platform,
+		PullPolicy:        service.PullPolicy,
+		Profiles:          service.Profiles,
+		Extensions:        extensions,
+		Annotations:       service.Annotations,
+		Attach:            service.Attach,
+		CgroupParent:      service.CgroupParent,
+		CpuCount:          service.CPUCount,
+		CpuPercent:        service.CPUPercent,
+		CpuRtPeriod:       service.CPURTPeriod,
+		CpuRtRuntime:      service.CPURTRuntime,
+		MemSwappiness:     int64(service.MemSwappiness),
+		MacAddress:        service.MacAddress,
+		Links:             service.Links,
+		ExternalLinks:     service.ExternalLinks,
+		VolumesFrom:       service.VolumesFrom,
+		PostStart:         hooksProto(service.PostStart),
+		PreStop:           hooksProto(service.PreStop),
+		StorageOpt:        service.StorageOpt,
+		Isolation:         service.Isolation,
+		UseApiSocket:      service.UseAPISocket,
+		LabelFile:         service.LabelFiles,
+	}
+	if service.Scale != nil {
+		scale := int64(*service.Scale)
+		message.Scale = &scale
+	}
+	if blkio := service.BlkioConfig; blkio != nil {
+		message.BlkioConfig = &parserpb.BlkioConfig{
+			Weight:          uint32(blkio.Weight),
+			DeviceReadBps:   throttleDevicesProto(blkio.DeviceReadBps),
+			DeviceReadIops:  throttleDevicesProto(blkio.DeviceReadIOps),
+			DeviceWriteBps:  throttleDevicesProto(blkio.DeviceWriteBps),
+			DeviceWriteIops: throttleDevicesProto(blkio.DeviceWriteIOps),
+		}
+		for _, device := range blkio.WeightDevice {
+			message.BlkioConfig.WeightDevice = append(message.BlkioConfig.WeightDevice, &parserpb.BlkioConfig_WeightDevice{
+				Path:   device.Path,
+				Weight: uint32(device.Weight),
+			})
+		}
+	}
+	if spec := service.CredentialSpec; spec != nil {
+		message.CredentialSpec = &parserpb.CredentialSpec{Config: spec.Config, File: spec.File, Registry: spec.Registry}
+	}
+	for _, envFile := range service.EnvFiles {
+		message.EnvFile = append(message.EnvFile, &parserpb.EnvFile{Path: envFile.Path, Required: envFile.Required, Format: envFile.Format})
+	}
+	if service.Build != nil {
+		if message.Build, err = buildProto(*service.Build); err != nil {
+			return nil, err
+		}
+	}
+	for _, port := range service.Ports {
+		message.Ports = append(message.Ports, &parserpb.Port{
+			Target:      port.Target,
+			Published:   port.Published,
+			HostIp:      port.HostIP,
+			Protocol:    port.Protocol,
+			Mode:        port.Mode,
+			Name:        port.Name,
+			AppProtocol: port.AppProtocol,
+		})
+	}
+	for _, volume := range service.Volumes {
+		message.Volumes = append(message.Volumes, serviceVolumeProto(volume))
+	}
+	for name, network := range service.Networks {
+		// Networks attached without options are empty messages, as maps can't have unset values
+		message.Networks[name] = &parserpb.ServiceNetwork{}
+		if network != nil {
+			message.Networks[name] = &parserpb.ServiceNetwork{
+				Aliases:       network.Aliases,
+				Ipv4Address:   network.Ipv4Address,
+				Ipv6Address:   network.Ipv6Address,
+				LinkLocalIps:  network.LinkLocalIPs,
+				MacAddress:    network.MacAddress,
+				Priority:      int32(network.Priority),
+				GwPriority:    int32(network.GatewayPriority),
+				DriverOpts:    network.DriverOpts,
+				InterfaceName: network.InterfaceName,
+			}
+		}
+	}
+	for name, dependency := range service.DependsOn {
+		message.DependsOn[name] = &parserpb.Dependency{
+			Condition: dependency.Condition,
+			Restart:   dependency.Restart,
+			Required:  dependency.Required,
+		}
+	}
+	for _, device := range service.Devices {
+		message.Devices = append(message.Devices, &parserpb.Device{
+			Source:      device.Source,
+			Target:      device.Target,
+			Permissions: device.Permissions,
+		})
+	}
+	if healthcheck := service.HealthCheck; healthcheck != nil {
+		message.Healthcheck = &parserpb.Healthcheck{
+			Test:          healthcheck.Test,
+			Interval:      durationProto(healthcheck.Interval),
+			Timeout:       durationProto(healthcheck.Timeout),
+			StartPeriod:   durationProto(healthcheck.StartPeriod),
+			StartInterval: durationProto(healthcheck.StartInterval),
+			Retries:       healthcheck.Retries,
+			Disable:       healthcheck.Disable,
+		}
+	}
+	if service.Logging != nil {
+		message.Logging = &parserpb.Logging{Driver: service.Logging.Driver, Options: service.Logging.Options}
+	}
+	if deploy := service.Deploy; deploy != nil {
+		message.Deploy = &parserpb.Deploy{
+			Labels:       deploy.Labels,
+			Limits:       resourceProto(deploy.Resources.Limits),
+			Reservations: resourceProto(deploy.Resources.Reservations),
+			Mode:         deploy.Mode,
+		}
+		if deploy.Replicas != nil {
+			replicas := int64(*deploy.Replicas)
+			message.Deploy.Replicas = &replicas
+		}
+	}
+	for _, config := range service.Configs {
+		message.Configs = append(message.Configs, fileReferenceProto(types.FileReferenceConfig(config)))
+	}
+	for _, secret := range service.Secrets {
+		message.Secrets = append(message.Secrets, fileReferenceProto(types.FileReferenceConfig(secret)))
+	}
+	return message, nil
+}
+
+func buildProto(build types.BuildConfig) (*parserpb.Build, error) {
+	extensions, err := extensionsProto(build.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	args, unsetArgs := mappingProto(build.Args)
+	message := &parserpb.Build{
+		Context:            build.Context,
+		Dockerfile:         build.Dockerfile,
+		DockerfileInline:   build.DockerfileInline,
+		Args:               args,
+		UnsetArgs:          unsetArgs,
+		Labels:             build.Labels,
+		Target:             build.Target,
+		Network:            build.Network,
+		CacheFrom:          build.CacheFrom,
+		Platforms:          build.Platforms,
+		Tags:               build.Tags,
+		AdditionalContexts: build.AdditionalContexts,
+		ExtraHosts:         build.ExtraHosts.AsList(":"),
+		ShmSize:            int64(build.ShmSize),
+		Ulimits:            ulimitsProto(build.Ulimits),
+		Extensions:         extensions,
+		Entitlements:       build.Entitlements,
+		CacheTo:            build.CacheTo,
+		NoCache:            build.NoCache,
+		Pull:               build.Pull,
+		Isolation:          build.Isolation,
+		Privileged:         build.Privileged,
+		Provenance:         build.Provenance,
+		Sbom:               build.SBOM,
+	}
+	for _, key := range build.SSH {
+		message.Ssh = append(message.Ssh, &parserpb.Build_SshKey{Id: key.ID, Path: key.Path})
+	}
+	for _, secret := range build.Secrets {
+		message.Secrets = append(message.Secrets, fileReferenceProto(types.FileReferenceConfig(secret)))
+	}
+	return message, nil
+}
+
+func serviceVolumeProto(volume types.ServiceVolumeConfig) *parserpb.ServiceVolume {
+	message := &parserpb.ServiceVolume{
+		Type:        volume.Type,
+		Source:      volume.Source,
+		Target:      volume.Target,
+		ReadOnly:    volume.ReadOnly,
+		Consistency: volume.Consistency,
+	}
+	if volume.Bind != nil {
+		message.Bind = &parserpb.ServiceVolume_BindOptions{
+			Propagation:    volume.Bind.Propagation,
+			CreateHostPath: volume.Bind.CreateHostPath,
+			Selinux:        volume.Bind.SELinux,
+			Recursive:      volume.Bind.Recursive,
+		}
+	}
+	if volume.Volume != nil {
+		message.Volume = &parserpb.ServiceVolume_VolumeOptions{
+			Nocopy:  volume.Volume.NoCopy,
+			Subpath: volume.Volume.Subpath,
+			Labels:  volume.Volume.Labels,
+		}
+	}
+	if volume.Image != nil {
+		message.Image = &parserpb.ServiceVolume_ImageOptions{Subpath: volume.Image.SubPath}
+	}
+	if volume.Tmpfs != nil {
+		message.Tmpfs = &parserpb.ServiceVolume_TmpfsOptions{Size: int64(volume.Tmpfs.Size), Mode: volume.Tmpfs.Mode}
+	}
+	return message
+}
+
+func networkProto(network types.NetworkConfig) (*parserpb.Network, error) {
+	extensions, err := extensionsProto(network.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	message := &parserpb.Network{
+		Name:       network.Name,
+		Driver:     network.Driver,
+		DriverOpts: network.DriverOpts,
+		Labels:     network.Labels,
+		External:   bool(network.External),
+		Internal:   network.Internal,
+		Attachable: network.Attachable,
+		EnableIpv4: network.EnableIPv4,
+		EnableIpv6: network.EnableIPv6,
+		Extensions: extensions,
+	}
+	if network.Ipam.Driver != "" || len(network.Ipam.Config) > 0 {
+		message.Ipam = &parserpb.Network_Ipam{Driver: network.Ipam.Driver}
+		for _, pool := range network.Ipam.Config {
+			message.Ipam.Config = append(message.Ipam.Config, &parserpb.Network_Pool{
+				Subnet:       pool.Subnet,
+				Gateway:      pool.Gateway,
+				IpRange:      pool.IPRange,
+				AuxAddresses: pool.AuxiliaryAddresses,
+			})
+		}
+	}
+	return message, nil
+}
+
+func fileObjectProto(object types.FileObjectConfig) (*parserpb.FileObject, error) {
+	extensions, err := extensionsProto(object.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	return &parserpb.FileObject{
+		Name:           object.Name,
+		File:           object.File,
+		Environment:    object.Environment,
+		Content:        object.Content,
+		External:       bool(object.External),
+		Labels:         object.Labels,
+		Extensions:     extensions,
+		Driver:         object.Driver,
+		DriverOpts:     object.DriverOpts,
+		TemplateDriver: object.TemplateDriver,
+	}, nil
+}
+
+func fileReferenceProto(reference types.FileReferenceConfig) *parserpb.FileReference {
+	message := &parserpb.FileReference{
+		Source: reference.Source,
+		Target: reference.Target,
+		Uid:    reference.UID,
+		Gid:    reference.GID,
+	}
+	if reference.Mode != nil {
+		mode := uint32(*reference.Mode)
+		message.Mode = &mode
+	}
+	return message
+}
+
+func resourceProto(resource *types.Resource) *parserpb.Deploy_Resource {
+	if resource == nil {
+		return nil
+	}
+	return &parserpb.Deploy_Resource{
+		Cpus:    resource.NanoCPUs.Value(),
+		Memory:  int64(resource.MemoryBytes),
+		Pids:    resource.Pids,
+		Devices: deviceRequestsProto(resource.Devices),
+	}
+}
+
+func deviceRequestsProto(requests []types.DeviceRequest) []*parserpb.DeviceRequest {
+	var messages []*parserpb.DeviceRequest
+	for _, request := range requests {
+		messages = append(messages, &parserpb.DeviceRequest{
+			Driver:       request.Driver,
+			Count:        int64(request.Count),
+			DeviceIds:    request.IDs,
+			Capabilities: request.Capabilities,
+			Options:      request.Options,
+		})
+	}
+	return messages
+}
+
+func throttleDevicesProto(devices []types.ThrottleDevice) []*parserpb.BlkioConfig_ThrottleDevice {
+	var messages []*parserpb.BlkioConfig_ThrottleDevice
+	for _, device := range devices {
+		messages = append(messages, &parserpb.BlkioConfig_ThrottleDevice{Path: device.Path, Rate: int64(device.Rate)})
+	}
+	return messages
+}
+
+func hooksProto(hooks []types.ServiceHook) []*parserpb.Hook {
+	var messages []*parserpb.Hook
+	for _, hook := range hooks {
+		environment, unsetEnvironment := mappingProto(hook.Environment)
+		messages = append(messages, &parserpb.Hook{
+			Command:          commandProto(hook.Command),
+			User:             hook.User,
+			Privileged:       hook.Privileged,
+			WorkingDir:       hook.WorkingDir,
+			Environment:      environment,
+			UnsetEnvironment: unsetEnvironment,
+		})
+	}
+	return messages
+}
+
+func ulimitsProto(ulimits map[string]*types.UlimitsConfig) map[string]*parserpb.Ulimit {
+	messages := map[string]*parserpb.Ulimit{}
+	for name, ulimit := range ulimits {
+		if ulimit == nil {
+			continue
+		}
+		soft, hard := ulimit.Soft, ulimit.Hard
+		if ulimit.Single != 0 {
+			soft, hard = ulimit.Single, ulimit.Single
+		}
+		messages[name] = &parserpb.Ulimit{Soft: int64(soft), Hard: int64(hard)}
+	}
+	return messages
+}
+
+// A command, unset if the image's is kept and without arguments if cleared
+func commandProto(command types.ShellCommand) *parserpb.Command {
+	if command == nil {
+		return nil
+	}
+	return &parserpb.Command{Args: command}
+}
+
+// The variables of a mapping with a value, and the sorted names of those without
+func mappingProto(mapping types.MappingWithEquals) (map[string]string, []string) {
+	values := map[string]string{}
+	var unset []string
+	for _, name := range slices.Sorted(maps.Keys(mapping)) {
+		if value := mapping[name]; value != nil {
+			values[name] = *value
+		} else {
+			unset = append(unset, name)
+		}
+	}
+	return values, unset
+}
+
+func durationProto(duration *types.Duration) *durationpb.Duration {
+	if duration == nil {
+		return nil
+	}
+	return durationpb.New(time.Duration(*duration))
+}
+
+// The x- extensions as JSON values, converted through their JSON encoding, as extensions aren't limited to
+// the types structpb converts, e.g. map[string]string
+func extensionsProto(extensions types.Extensions) (map[string]*structpb.Value, error) {
+	values := map[string]*structpb.Value{}
+	for name, extension := range extensions {
+		extensionJSON, err := json.Marshal(extension)
+		if err != nil {
+			return nil, err
+		}
+		value := &structpb.Value{}
+		if err := value.UnmarshalJSON(extensionJSON); err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	"balena-compose-parser/pkg/parser"
+	"balena-compose-parser/pkg/parserpb"
+)
+
+func TestProtoOutput(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "x-release: {channel: beta}\n"+
+		"services:\n  web:\n    image: nginx\n    command: []\n    environment: {MODE: production, TOKEN: null}\n"+
+		"    stop_grace_period: 30s\n    scale: 2\n    ulimits: {nofile: 1024}\n    networks: [backend]\n"+
+		"  db:\n    image: postgres\n    depends_on: {web: {condition: service_started}}\n"+
+		"networks:\n  backend: {}\n")
+	result := runCLI(t, "", "--output-format", "proto", "-f", composeFile, "p")
+	if result.code != 0 {
+		t.Fatalf("expected the protobuf project, got %d: %s", result.code, result.stderr)
+	}
+	var project parserpb.Project
+	if err := proto.Unmarshal([]byte(result.stdout), &project); err != nil {
+		t.Fatal(err)
+	}
+	web, db := project.Services["web"], project.Services["db"]
+	if project.Name != "p" || web.Image != "nginx" || db.Image != "postgres" || project.Networks["backend"].Name != "p_backend" {
+		t.Errorf("expected the project, got %v", &project)
+	}
+	// Commands are unset if the image's is kept, and without arguments if cleared
+	if web.Command == nil || len(web.Command.Args) != 0 || db.Command != nil {
+		t.Errorf("expected the cleared command of web only, got %v and %v", web.Command, db.Command)
+	}
+	if web.Environment["MODE"] != "production" || strings.Join(web.UnsetEnvironment, ",") != "TOKEN" {
+		t.Errorf("expected the variables without a value to be listed apart, got %v and %v", web.Environment, web.UnsetEnvironment)
+	}
+	if web.StopGracePeriod.AsDuration() != 30*time.Second || web.GetScale() != 2 || web.Ulimits["nofile"].Soft != 1024 || web.Ulimits["nofile"].Hard != 1024 {
+		t.Errorf("expected the durations, scale and ulimits, got %v", web)
+	}
+	if _, ok := web.Networks["backend"]; !ok || db.DependsOn["web"].Condition != "service_started" {
+		t.Errorf("expected the networks and dependencies, got %v and %v", web.Networks, db.DependsOn)
+	}
+	if channel := project.Extensions["x-release"].GetStructValue().GetFields()["channel"].GetStringValue(); channel != "beta" {
+		t.Errorf("expected the extensions as JSON values, got %v", project.Extensions)
+	}
+	// Map entries are sorted, so the output is the same every run
+	for range 3 {
+		if again := runCLI(t, "", "--output-format", "proto", "-f", composeFile, "p"); again.stdout != result.stdout {
+			t.Fatal("expected deterministic output")
+		}
+	}
+
+	// The text format isn't stable, so it's compared as the message it encodes
+	text := runCLI(t, "", "--output-format", "proto-text", "-f", composeFile, "p")
+	var textProject parserpb.Project
+	if err := prototext.Unmarshal([]byte(text.stdout), &textProject); err != nil || !proto.Equal(&textProject, &project) {
+		t.Errorf("expected the project in the protobuf text format, got %s: %v", text.stdout, err)
+	}
+}
+
+func TestProtoOutputErrors(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    develop:\n      watch: [{action: rebuild, path: .}]\n")
+	response := runCLI(t, "", "--output-format", "proto", "-f", composeFile, "p").expectError(t, parser.ValidationError, "services.web.develop has no field in the protobuf schema")
+	if len(response.Errors) != 1 || response.Errors[0].Code != parser.UnsupportedFieldCode || response.Errors[0].Location == nil || response.Errors[0].Location.Line != 4 {
+		t.Errorf("expected an unsupported field located at develop, got %+v", response.Errors)
+	}
+	if result := runCLI(t, "", "-f", composeFile, "p"); result.code != 0 {
+		t.Errorf("expected the JSON output to represent every field, got %d: %s", result.code, result.stderr)
+	}
+}
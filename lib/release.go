@@ -0,0 +1,129 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Contract files the builder merges into releases from the project directory, in order of precedence
+var releaseContractFiles = []string{"balena.yml", "balena.yaml"}
+
+// releaseFlags are the command line flags of the release subcommand
+type releaseFlags struct {
+	composeFiles     composeFileFlag
+	projectDirectory string
+	contract         string
+	envFiles         stringListFlag
+	timeout          time.Duration
+	outputPath       string
+	logLevel         string
+	quiet            bool
+}
+
+// Create the flag set of the release subcommand, storing values in o
+func newReleaseFlagSet(o *releaseFlags) *flag.FlagSet {
+	flags := flag.NewFlagSet("release", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Var(&o.composeFiles, "f", "Path to a `compose-file` of the release, later files overriding earlier ones")
+	flags.StringVar(&o.projectDirectory, "project-directory", "", "Resolve build contexts against the `directory`, instead of that of the first compose file")
+	flags.StringVar(&o.contract, "contract", "", "Merge the contract at `path`, instead of the balena.yml in the project directory")
+	flags.Var(&o.envFiles, "env-file", "Interpolate variables from an env `file`, e.g. the project's .env file")
+	flags.DurationVar(&o.timeout, "timeout", parser.DefaultTimeout, "Maximum `duration` to spend parsing")
+	flags.StringVar(&o.outputPath, "o", "", "Write output atomically to `path` instead of stdout")
+	flags.StringVar(&o.logLevel, "log-level", logrus.InfoLevel.String(), "Minimum `level` of logs written to stderr")
+	flags.BoolVar(&o.quiet, "quiet", false, "Don't write any logs to stderr")
+	return flags
+}
+
+// Run the release subcommand, writing the composition document and contract of a balenaCloud release. The project is parsed as the builder parses it, validated for balena and normalized,
+// and variables are only interpolated from env files, as the builder doesn't see the local environment.
+func runRelease(args []string) {
+	var o releaseFlags
+	flags := newReleaseFlagSet(&o)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fmt.Fprint(os.Stdout, usage)
+			return
+		}
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if err := configureLogging(o.logLevel, o.quiet); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if flags.NArg() > 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Unexpected arguments: %v\n", flags.Args())+usage)
+	}
+	if len(o.composeFiles) == 0 {
+		fail(parser.ArgumentError, "At least one compose file must be specified with -f\n"+usage)
+	}
+	if err := applyTimeoutEnv(flags, &o.timeout); err != nil {
+		fail(parser.ArgumentError, err.Error()+"\n"+usage)
+	}
+	if o.timeout <= 0 {
+		fail(parser.ArgumentError, fmt.Sprintf("Timeout must be positive, got %s\n", o.timeout)+usage)
+	}
+
+	if o.contract == "" {
+		projectDirectory := cmp.Or(o.projectDirectory, filepath.Dir(o.composeFiles[0]))
+		for _, name := range releaseContractFiles {
+			if _, err := os.Stat(filepath.Join(projectDirectory, name)); err == nil {
+				o.contract = filepath.Join(projectDirectory, name)
+				break
+			}
+		}
+	}
+
+	p := parser.New(parser.Options{
+		ProjectDirectory: o.projectDirectory,
+		Timeout:          o.timeout,
+		EnvFiles:         o.envFiles,
+		NoOSEnv:          true,
+		RelativePaths:    true,
+		BalenaNormalize:  true,
+		Target:           parser.BalenaTarget,
+		Contract:         o.contract,
+	})
+	result, err := p.Parse(context.Background(), o.composeFiles)
+	if err != nil {
+		exitWithError(err)
+	}
+	release, err := parser.NewRelease(result)
+	if err != nil {
+		fail(parser.ParseError, fmt.Sprintf("Failed to derive release: %v", err))
+	}
+	output, err := marshalRelease(release)
+	if err != nil {
+		fail(parser.ParseError, fmt.Sprintf("Failed to marshal release to JSON: %v", err))
+	}
+	if err := writeOutput(o.outputPath, output, false); err != nil {
+		fail(parser.IOError, err.Error())
+	}
+}
+
+// Marshal a release with its composition in canonical form, so releases of equivalent projects are
+// byte-identical
+func marshalRelease(release *parser.Release) ([]byte, error) {
+	compositionJSON, err := json.Marshal(release.Composition)
+	if err != nil {
+		return nil, err
+	}
+	if compositionJSON, err = parser.CanonicalJSON(compositionJSON); err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Composition json.RawMessage  `json:"composition"`
+		Contract    *parser.Contract `json:"contract,omitempty"`
+	}{compositionJSON, release.Contract})
+}
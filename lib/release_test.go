@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestRelease(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    build: ./web\n    labels:\n      version: ${VERSION:-unset}\n      tag: ${TAG:-unset}\n")
+	writeFile(t, dir, "web/Dockerfile", "FROM nginx\n")
+	writeFile(t, dir, "balena.yml", "type: sw.application\nslug: balenalabs/browser\n")
+	envFile := writeFile(t, dir, "release.env", "VERSION=1.2.3\n")
+	// The builder doesn't see the local environment
+	t.Setenv("TAG", "local")
+
+	output := runCLI(t, "", "release", "-f", composeFile, "--env-file", envFile).output(t)
+	if lookup(output, "composition.services.web.build.context") != "web" || lookup(output, "composition.name") != nil {
+		t.Errorf("expected the unnamed composition with a project relative context, got %v", output)
+	}
+	if lookup(output, "composition.services.web.labels.version") != "1.2.3" || lookup(output, "composition.services.web.labels.tag") != "unset" {
+		t.Errorf("expected variables to only be interpolated from env files, got %v", lookup(output, "composition.services.web.labels"))
+	}
+	if lookup(output, "contract.slug") != "balenalabs/browser" {
+		t.Errorf("expected the contract in the project directory, got %v", output["contract"])
+	}
+
+	other := writeFile(t, dir, "other.yml", "type: sw.block\nslug: balenablocks/dashboard\n")
+	outputPath := filepath.Join(dir, "release.json")
+	if result := runCLI(t, "", "release", "-f", composeFile, "--contract", other, "-o", outputPath); result.code != 0 || result.stdout != "" {
+		t.Fatalf("expected the release to be written to the output file, got %d: %s", result.code, result.stderr)
+	}
+	written, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var release map[string]any
+	if err := json.Unmarshal(written, &release); err != nil || lookup(release, "contract.slug") != "balenablocks/dashboard" {
+		t.Errorf("expected the given contract, got %s: %v", written, err)
+	}
+}
+
+func TestReleaseErrors(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    network_mode: container:db\n")
+
+	runCLI(t, "", "release").expectError(t, parser.ArgumentError, "At least one compose file must be specified with -f")
+	runCLI(t, "", "release", "-f", composeFile, "extra").expectError(t, parser.ArgumentError, "Unexpected arguments: [extra]")
+	runCLI(t, "", "release", "-f", composeFile, "--timeout", "0s").expectError(t, parser.ArgumentError, "Timeout must be positive, got 0s")
+	// Projects are validated for balena
+	runCLI(t, "", "release", "-f", composeFile).expectError(t, parser.ValidationError, "")
+}
+
+func TestMarshalRelease(t *testing.T) {
+	first, err := marshalRelease(&parser.Release{Composition: map[string]any{"services": map[string]any{"web": map[string]any{"image": "nginx", "command": []any{"serve"}}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := marshalRelease(&parser.Release{Composition: map[string]any{"services": map[string]any{"web": map[string]any{"command": []any{"serve"}, "image": "nginx"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) || string(first) != `{"composition":{"services":{"web":{"command":["serve"],"image":"nginx"}}}}` {
+		t.Errorf("expected equivalent releases to be byte-identical without a contract, got %s and %s", first, second)
+	}
+}
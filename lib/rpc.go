@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// JSON-RPC 2.0 error codes, see https://www.jsonrpc.org/specification#error_object
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	// Server error code used for all parser failures, with the parser error name in data
+	rpcParserError = -32000
+)
+
+// Max size of a single request line, as compose files are referenced by path this is generous
+const rpcMaxRequestSize = 1024 * 1024
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    *rpcErrorData `json:"data,omitempty"`
+}
+
+type rpcErrorData struct {
+	Name     string           `json:"name"`
+	Location *parser.Location `json:"location,omitempty"`
+}
+
+// Params accepted by the parse and validate methods
+type rpcParseParams struct {
+	Files       []string `json:"files"`
+	ProjectName string   `json:"projectName"`
+	// Timeout as a duration string, e.g. "30s", defaulting to the --timeout value
+	Timeout   string `json:"timeout,omitempty"`
+	Canonical bool   `json:"canonical,omitempty"`
+}
+
+// Result of the validate method
+type rpcValidateResult struct {
+	Valid bool `json:"valid"`
+}
+
+// Serve newline-delimited JSON-RPC requests from r until EOF, writing one response line per request to w.
+// Requests are handled concurrently, so responses may be written in a different order than requests were received.
+// Lines over rpcMaxRequestSize are answered with an invalid request error and skipped, and batches aren't supported.
+func serveStdio(r io.Reader, w io.Writer, defaultTimeout time.Duration) error {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	respond := func(response rpcResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		response.JSONRPC = "2.0"
+		if response.ID == nil {
+			response.ID = json.RawMessage("null")
+		}
+		encoder.Encode(response)
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	reader := bufio.NewReader(r)
+	for {
+		line, oversized, err := readRequestLine(reader)
+		switch {
+		case oversized:
+			respond(rpcResponse{Error: &rpcError{Code: rpcInvalidRequest, Message: fmt.Sprintf("Request exceeds %d bytes", rpcMaxRequestSize)}})
+		case len(line) == 0:
+		case line[0] == '[':
+			respond(rpcResponse{Error: &rpcError{Code: rpcInvalidRequest, Message: "Batch requests are not supported"}})
+		default:
+			var request rpcRequest
+			if err := json.Unmarshal(line, &request); err != nil {
+				respond(rpcResponse{Error: &rpcError{Code: rpcParseError, Message: fmt.Sprintf("Invalid JSON: %v", err)}})
+				break
+			}
+			if request.JSONRPC != "2.0" || request.Method == "" {
+				respond(rpcResponse{ID: request.ID, Error: &rpcError{Code: rpcInvalidRequest, Message: "Invalid JSON-RPC 2.0 request"}})
+				break
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, rpcErr := handleRPC(request, defaultTimeout)
+				// Requests without an id are notifications, which don't receive a response
+				if request.ID == nil {
+					return
+				}
+				respond(rpcResponse{ID: request.ID, Result: result, Error: rpcErr})
+			}()
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Read the next line from reader without surrounding whitespace, discarding it rather than buffering more than
+// rpcMaxRequestSize bytes if it's oversized
+func readRequestLine(reader *bufio.Reader) (line []byte, oversized bool, err error) {
+	for {
+		var chunk []byte
+		chunk, err = reader.ReadSlice('\n')
+		if !oversized {
+			line = append(line, chunk...)
+			if len(bytes.TrimRight(line, "\r\n")) > rpcMaxRequestSize {
+				line, oversized = nil, true
+			}
+		}
+		if err != bufio.ErrBufferFull {
+			return bytes.TrimSpace(line), oversized, err
+		}
+	}
+}
+
+// Dispatch a single JSON-RPC request to its method
+func handleRPC(request rpcRequest, defaultTimeout time.Duration) (any, *rpcError) {
+	switch request.Method {
+	case "parse", "validate":
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("Method %q not found", request.Method)}
+	}
+
+	var params rpcParseParams
+	if err := json.Unmarshal(request.Params, &params); err != nil {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("Invalid params: %v", err)}
+	}
+
+	// Stdin is used for the RPC stream, so can't also be a compose file
+	if slices.Contains(params.Files, parser.StdinPath) {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "Reading compose files from stdin is not supported in --serve-stdio mode"}
+	}
+
+	timeout := defaultTimeout
+	if params.Timeout != "" {
+		parsed, err := time.ParseDuration(params.Timeout)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("Invalid timeout %q: %v", params.Timeout, err)}
+		}
+		timeout = parsed
+	}
+
+	result, err := parser.New(parser.Options{
+		ProjectName: params.ProjectName,
+		Timeout:     timeout,
+	}).Parse(context.Background(), params.Files)
+	if err != nil {
+		return nil, toRPCError(err)
+	}
+
+	if request.Method == "validate" {
+		return rpcValidateResult{Valid: true}, nil
+	}
+
+	output, err := marshalProject(result.Project, formatJSON, params.Canonical)
+	if err != nil {
+		return nil, toRPCError(fmt.Errorf("Failed to marshal compose project to JSON: %w", err))
+	}
+	return json.RawMessage(output), nil
+}
+
+// Convert a parser error into a JSON-RPC error, preserving the error name
+func toRPCError(err error) *rpcError {
+	var parserErr *parser.Error
+	if errors.As(err, &parserErr) {
+		code := rpcParserError
+		if parserErr.Name == parser.ArgumentError {
+			code = rpcInvalidParams
+		}
+		return &rpcError{Code: code, Message: parserErr.Message, Data: &rpcErrorData{Name: parserErr.Name, Location: parserErr.Location}}
+	}
+	return &rpcError{Code: rpcParserError, Message: err.Error(), Data: &rpcErrorData{Name: parser.ParseError}}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Serve requests, one per line, returning the responses by the JSON of their id
+func serveRequests(t *testing.T, requests ...string) map[string]rpcResponse {
+	t.Helper()
+	var output bytes.Buffer
+	if err := serveStdio(strings.NewReader(strings.Join(requests, "\n")), &output, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	responses := map[string]rpcResponse{}
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		var response rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			t.Fatalf("invalid response %q: %v", scanner.Text(), err)
+		}
+		if response.JSONRPC != "2.0" {
+			t.Errorf("expected a JSON-RPC 2.0 response, got %q", scanner.Text())
+		}
+		responses[string(response.ID)] = response
+	}
+	return responses
+}
+
+func TestServeStdio(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n")
+	invalidFile := writeFile(t, dir, "invalid.yml", "services:\n  web:\n    image: nginx\n    ports: true\n")
+	request := func(id any, method string, params any) string {
+		request, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+		return string(request)
+	}
+
+	responses := serveRequests(t,
+		request(1, "parse", rpcParseParams{Files: []string{composeFile}, ProjectName: "p", Canonical: true}),
+		request(2, "validate", rpcParseParams{Files: []string{composeFile}, ProjectName: "p"}),
+		request(3, "validate", rpcParseParams{Files: []string{invalidFile}, ProjectName: "p"}),
+		request(4, "parse", rpcParseParams{Files: []string{composeFile}}),
+		request(5, "parse", rpcParseParams{Files: []string{parser.StdinPath}, ProjectName: "p"}),
+		request(6, "parse", rpcParseParams{Files: []string{composeFile}, ProjectName: "p", Timeout: "soon"}),
+		request(7, "format", nil),
+		request("eight", "parse", "files"),
+		`{"jsonrpc": "1.0", "id": 9, "method": "parse"}`,
+		`{"jsonrpc": "2.0", "method": "parse", "params": {"files": ["`+composeFile+`"], "projectName": "p"}}`,
+		`{"jsonrpc":`,
+		`[{"jsonrpc": "2.0", "id": 10, "method": "validate"}]`,
+		"",
+	)
+
+	if response := responses["1"]; response.Error != nil || lookup(response.Result, "services.web.image") != "nginx" {
+		t.Errorf("expected the project, got %+v", response)
+	}
+	if response := responses["2"]; response.Error != nil || lookup(response.Result, "valid") != true {
+		t.Errorf("expected a valid result, got %+v", response)
+	}
+	for id, expected := range map[string]struct {
+		code int
+		name string
+	}{
+		"3":       {rpcParserError, parser.ValidationError},
+		"4":       {rpcInvalidParams, parser.ArgumentError},
+		"5":       {rpcInvalidParams, ""},
+		"6":       {rpcInvalidParams, ""},
+		"7":       {rpcMethodNotFound, ""},
+		`"eight"`: {rpcInvalidParams, ""},
+		"9":       {rpcInvalidRequest, ""},
+	} {
+		response := responses[id]
+		if response.Error == nil || response.Error.Code != expected.code {
+			t.Errorf("expected request %s to fail with code %d, got %+v", id, expected.code, response)
+			continue
+		}
+		var name string
+		if response.Error.Data != nil {
+			name = response.Error.Data.Name
+		}
+		if name != expected.name {
+			t.Errorf("expected request %s to fail with the error name %q, got %q", id, expected.name, name)
+		}
+	}
+	// Notifications don't receive a response, and the malformed and batch requests have a null id
+	if len(responses) != 10 {
+		t.Errorf("expected 10 responses, got %d", len(responses))
+	}
+}
+
+func TestServeStdioInvalidLines(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n")
+	requests := strings.Join([]string{
+		`{"jsonrpc":`,
+		`{"jsonrpc": "2.0", "id": 1, "method": "validate", "params": {"projectName": "` + strings.Repeat("p", rpcMaxRequestSize) + `"}}`,
+		`  [{"jsonrpc": "2.0", "id": 2, "method": "validate"}]`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "validate", "params": {"files": ["` + composeFile + `"], "projectName": "p"}}`,
+	}, "\r\n")
+	var output bytes.Buffer
+	if err := serveStdio(strings.NewReader(requests), &output, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// The server keeps serving after lines it can't handle, answering each with a null id
+	var codes []int
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		var response rpcResponse
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			t.Fatalf("invalid response %q: %v", scanner.Text(), err)
+		}
+		if string(response.ID) == "3" {
+			if response.Error != nil || lookup(response.Result, "valid") != true {
+				t.Errorf("expected a valid result, got %+v", response)
+			}
+			continue
+		}
+		if string(response.ID) != "null" || response.Error == nil {
+			t.Errorf("expected an error without an id, got %s", scanner.Text())
+			continue
+		}
+		codes = append(codes, response.Error.Code)
+	}
+	if !slices.Equal(codes, []int{rpcParseError, rpcInvalidRequest, rpcInvalidRequest}) {
+		t.Errorf("expected a parse error then two invalid requests, got %v", codes)
+	}
+}
+
+func TestServeStdioCLI(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n")
+	result := runCLI(t, `{"jsonrpc": "2.0", "id": 1, "method": "validate", "params": {"files": ["`+composeFile+`"], "projectName": "p"}}`+"\n", "--serve-stdio")
+	if result.code != 0 || strings.TrimSpace(result.stdout) != `{"jsonrpc":"2.0","id":1,"result":{"valid":true}}` {
+		t.Errorf("expected a valid result, got %d: %s%s", result.code, result.stdout, result.stderr)
+	}
+
+	runCLI(t, "", "--serve-stdio", "-f", composeFile).expectError(t, parser.ArgumentError, "Compose files and project name must be provided per request")
+	runCLI(t, "", "--serve-stdio", "-o", "out.json").expectError(t, parser.ArgumentError, "-o and --compress can't be used with --serve-stdio")
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"cmp"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Formats --report-format accepts for lint findings and validation reports
+const (
+	reportFormatJSON  = "json"
+	reportFormatSARIF = "sarif"
+)
+
+var reportFormats = []string{reportFormatJSON, reportFormatSARIF}
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifLog is a SARIF 2.1.0 log of a single run of the parser, as uploaded to GitHub code scanning
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     *sarifMessage       `json:"shortDescription,omitempty"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// The YAML path of a location, e.g. services.web.image, as a logical location
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// Build the SARIF log of a run with the given rules and results. Rules of results which aren't among the
+// given rules, such as the codes of warnings, are added by ID.
+func newSarifLog(rules []sarifRule, results []sarifResult) sarifLog {
+	for _, result := range results {
+		if !slices.ContainsFunc(rules, func(rule sarifRule) bool { return rule.ID == result.RuleID }) {
+			rules = append(rules, sarifRule{ID: result.RuleID})
+		}
+	}
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "balena-compose-parser",
+				Version:        version,
+				InformationURI: repositoryURL,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// The SARIF log of the findings of the lint subcommand, with the lint rules and the parser's warnings as
+// results of the rules of their codes
+func lintSarif(report lintReport) sarifLog {
+	rules := make([]sarifRule, len(parser.LintRules))
+	for i, rule := range parser.LintRules {
+		rules[i] = sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     &sarifMessage{Text: rule.Description},
+			DefaultConfiguration: &sarifConfiguration{Level: sarifLevel(rule.Severity)},
+		}
+	}
+	results := []sarifResult{}
+	for _, finding := range report.Findings {
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule,
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: sarifLocations(finding.Location),
+		})
+	}
+	return newSarifLog(rules, append(results, warningResults(report.Warnings)...))
+}
+
+// The SARIF log of a validation report, with each error as a result of the rule of its code, or name if
+// it has none, and the warnings as results of the rules of their codes
+func validationSarif(report validationReport) sarifLog {
+	results := []sarifResult{}
+	if report.Error != nil {
+		details := report.Error.Errors
+		if len(details) == 0 {
+			details = []parser.ErrorDetail{{Name: report.Error.Name, Message: report.Error.Message, Code: report.Error.Code, Location: report.Error.Location}}
+		}
+		for _, e := range details {
+			results = append(results, sarifResult{
+				RuleID:    cmp.Or(e.Code, e.Name),
+				Level:     "error",
+				Message:   sarifMessage{Text: e.Message},
+				Locations: sarifLocations(e.Location),
+			})
+		}
+	}
+	return newSarifLog([]sarifRule{}, append(results, warningResults(report.Warnings)...))
+}
+
+func warningResults(warnings []parser.Warning) []sarifResult {
+	var results []sarifResult
+	for _, warning := range warnings {
+		results = append(results, sarifResult{
+			RuleID:    warning.Code,
+			Level:     "warning",
+			Message:   sarifMessage{Text: warning.Message},
+			Locations: sarifLocations(warning.Location),
+		})
+	}
+	return results
+}
+
+// The SARIF level of a finding severity, which calls info note
+func sarifLevel(severity string) string {
+	if severity == parser.InfoSeverity {
+		return "note"
+	}
+	return severity
+}
+
+// The SARIF locations of a location in the compose files, as the file and region and the YAML path. Files
+// beneath the working directory are relative to it, as code scanning resolves them against the checkout.
+func sarifLocations(location *parser.Location) []sarifLocation {
+	if location == nil || location.File == "" && location.Path == "" {
+		return nil
+	}
+	var sarif sarifLocation
+	if location.File != "" {
+		sarif.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: sarifURI(location.File)}}
+		if location.Line > 0 {
+			sarif.PhysicalLocation.Region = &sarifRegion{StartLine: location.Line, StartColumn: location.Column}
+		}
+	}
+	if location.Path != "" {
+		sarif.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: location.Path, Kind: "member"}}
+	}
+	return []sarifLocation{sarif}
+}
+
+// The URI of a compose file, relative to the working directory if beneath it, and otherwise a file URI
+func sarifURI(file string) string {
+	if strings.HasPrefix(file, "https://") {
+		return file
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if relative, err := filepath.Rel(wd, file); err == nil && filepath.IsLocal(relative) {
+			return filepath.ToSlash(relative)
+		}
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(file)}).String()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestLintSarif(t *testing.T) {
+	report := lintReport{
+		Findings: []parser.Finding{{Rule: "restart-policy", Severity: parser.InfoSeverity, Message: "no restart", Location: &parser.Location{File: "/project/compose.yml", Line: 2, Column: 3, Path: "services.web"}}},
+		Warnings: []parser.Warning{{Code: "obsolete-version", Message: "version is obsolete"}},
+	}
+	log := lintSarif(report)
+	if log.Version != sarifVersion || log.Schema != sarifSchema || len(log.Runs) != 1 {
+		t.Fatalf("expected a SARIF 2.1.0 log of one run, got %+v", log)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.InformationURI != repositoryURL {
+		t.Errorf("expected the repository URL, got %q", run.Tool.Driver.InformationURI)
+	}
+	// Every lint rule is described, and warnings are results of the rules of their codes
+	rules := run.Tool.Driver.Rules
+	if len(rules) != len(parser.LintRules)+1 || rules[len(rules)-1].ID != "obsolete-version" || rules[0].DefaultConfiguration == nil || rules[0].ShortDescription == nil {
+		t.Errorf("expected the lint rules and the rule of the warning, got %+v", rules)
+	}
+	expected := []sarifResult{
+		{RuleID: "restart-policy", Level: "note", Message: sarifMessage{Text: "no restart"}, Locations: []sarifLocation{{
+			PhysicalLocation: &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: "file:///project/compose.yml"}, Region: &sarifRegion{StartLine: 2, StartColumn: 3}},
+			LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "services.web", Kind: "member"}},
+		}}},
+		{RuleID: "obsolete-version", Level: "warning", Message: sarifMessage{Text: "version is obsolete"}},
+	}
+	if !reflect.DeepEqual(run.Results, expected) {
+		t.Errorf("expected %+v, got %+v", expected, run.Results)
+	}
+}
+
+func TestValidationSarif(t *testing.T) {
+	// Errors without details are a result of the rule of their code, or name
+	log := validationSarif(validationReport{Error: &parser.ErrorResponse{Error: true, Name: parser.ParseError, Message: "failed"}})
+	if results := log.Runs[0].Results; len(results) != 1 || results[0].RuleID != parser.ParseError || results[0].Level != "error" || results[0].Locations != nil {
+		t.Errorf("expected a result of the error, got %+v", results)
+	}
+	log = validationSarif(validationReport{Error: &parser.ErrorResponse{Error: true, Name: parser.ValidationError, Message: "2 errors", Errors: []parser.ErrorDetail{
+		{Name: parser.ValidationError, Code: "unsupported-field", Message: "a"},
+		{Name: parser.ValidationError, Message: "b"},
+	}}})
+	var rules []string
+	for _, result := range log.Runs[0].Results {
+		rules = append(rules, result.RuleID)
+	}
+	if !reflect.DeepEqual(rules, []string{"unsupported-field", parser.ValidationError}) || len(log.Runs[0].Tool.Driver.Rules) != 2 {
+		t.Errorf("expected a result of each error, got %v", rules)
+	}
+	if log := validationSarif(validationReport{Valid: true}); log.Runs[0].Results == nil || len(log.Runs[0].Results) != 0 {
+		t.Errorf("expected no results of a valid report, got %+v", log.Runs[0].Results)
+	}
+}
+
+func TestSarifLocations(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		file     string
+		expected string
+	}{
+		// Files beneath the working directory are relative to it
+		{file: filepath.Join(wd, "testdata", "compose.yml"), expected: "testdata/compose.yml"},
+		{file: "/elsewhere/compose.yml", expected: "file:///elsewhere/compose.yml"},
+		{file: "https://example.com/compose.yml", expected: "https://example.com/compose.yml"},
+	} {
+		if uri := sarifURI(tt.file); uri != tt.expected {
+			t.Errorf("expected %s to be %s, got %s", tt.file, tt.expected, uri)
+		}
+	}
+
+	if locations := sarifLocations(nil); locations != nil {
+		t.Errorf("expected no locations, got %+v", locations)
+	}
+	// Locations of a path only are logical locations, and of a file only physical ones without a region
+	if locations := sarifLocations(&parser.Location{Path: "services.web"}); len(locations) != 1 || locations[0].PhysicalLocation != nil || len(locations[0].LogicalLocations) != 1 {
+		t.Errorf("expected a logical location, got %+v", locations)
+	}
+	if locations := sarifLocations(&parser.Location{File: "/compose.yml"}); len(locations) != 1 || locations[0].PhysicalLocation.Region != nil || locations[0].LogicalLocations != nil {
+		t.Errorf("expected a physical location without a region, got %+v", locations)
+	}
+}
+
+func TestReportFormatSarif(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:1.25\n    scale: 2\n")
+	decode := func(result cliResult) sarifLog {
+		t.Helper()
+		var log sarifLog
+		if err := json.Unmarshal([]byte(result.stdout), &log); err != nil || len(log.Runs) != 1 {
+			t.Fatalf("expected a SARIF log, got %d: %s: %v", result.code, result.stdout, err)
+		}
+		return log
+	}
+
+	result := runCLI(t, "", "lint", "--report-format", "sarif", "-f", composeFile)
+	if result.code != exitCode(parser.ValidationError) {
+		t.Errorf("expected the ValidationError exit code, got %d", result.code)
+	}
+	log := decode(result)
+	if results := log.Runs[0].Results; len(results) == 0 || results[0].RuleID != "balena-compatibility" || results[0].Level != "error" || results[0].Locations[0].PhysicalLocation.Region.StartLine != 4 {
+		t.Errorf("expected the located lint findings, got %+v", results)
+	}
+
+	invalid := writeFile(t, dir, "invalid.yml", "services:\n  web:\n    image: nginx\n    ports: [\"x\"]\n")
+	result = runCLI(t, "", "--validate", "--report-format", "sarif", "-f", invalid, "p")
+	if log := decode(result); result.code != exitCode(parser.ParseError) || len(log.Runs[0].Results) != 1 || log.Runs[0].Results[0].Level != "error" {
+		t.Errorf("expected the parse error as a result, got %d: %+v", result.code, log.Runs[0].Results)
+	}
+
+	runCLI(t, "", "--report-format", "sarif", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--report-format is only supported with --validate")
+	runCLI(t, "", "lint", "--report-format", "xml", "-f", composeFile).expectError(t, parser.ArgumentError, `Unsupported report format "xml", expected one of: json, sarif`)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// wrappedProject is the shape of the output of wrapProject, for the output schema
+type wrappedProject struct {
+	Project       types.Project                      `json:"project"`
+	Warnings      []parser.Warning                   `json:"warnings,omitempty"`
+	EnvResolution []parser.VariableResolution        `json:"env_resolution,omitempty"`
+	Overrides     []parser.Override                  `json:"overrides,omitempty"`
+	Features      map[string][]parser.Feature        `json:"features,omitempty"`
+	Builds        map[string]parser.Build            `json:"builds,omitempty"`
+	GPU           map[string][]parser.GPURequest     `json:"gpu,omitempty"`
+	Contract      *parser.Contract                   `json:"contract,omitempty"`
+	Policy        map[string][]parser.PolicyDecision `json:"policy,omitempty"`
+	Defaults      map[string][]parser.DefaultedField `json:"defaults,omitempty"`
+}
+
+// Documents the parser outputs, by name, described by the output schema. The project of batch results is
+// a project document in the requested format.
+var outputDocuments = map[string]any{
+	"project":          types.Project{},
+	"wrappedProject":   wrappedProject{},
+	"targetState":      parser.TargetState{},
+	"images":           []parser.Image{},
+	"resources":        parser.Resources{},
+	"hostAccess":       []parser.HostAccess{},
+	"batchResult":      batchResult{},
+	"lintReport":       lintReport{},
+	"validationReport": validationReport{},
+	"error":            parser.ErrorResponse{},
+}
+
+// Print the JSON Schemas of the compose files the parser accepts and of the documents it outputs, as
+// {"input": {...}, "output": {...}}
+func printSchema() {
+	input, err := parser.InputSchema()
+	if err != nil {
+		fail(parser.ConfigError, err.Error())
+	}
+	output := parser.OutputSchema("balena-compose-parser output", outputDocuments)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(map[string]any{"input": input, "output": output})
+}
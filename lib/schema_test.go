@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+func TestPrintSchema(t *testing.T) {
+	result := runCLI(t, "", "--print-schema")
+	if result.code != 0 {
+		t.Fatalf("expected the schemas, got %d: %s", result.code, result.stderr)
+	}
+	var schemas map[string]any
+	if err := json.Unmarshal([]byte(result.stdout), &schemas); err != nil {
+		t.Fatalf("expected the schemas as JSON, got %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	for _, name := range []string{"input", "output"} {
+		if err := compiler.AddResource(name+".json", schemas[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	input, err := compiler.Compile("input.json")
+	if err != nil {
+		t.Fatalf("expected a valid input schema, got %v", err)
+	}
+	output, err := compiler.Compile("output.json")
+	if err != nil {
+		t.Fatalf("expected a valid output schema, got %v", err)
+	}
+
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    ports: [\"8080:80\"]\n    labels:\n      io.balena.features.kernel-modules: \"1\"\nvolumes:\n  data: {}\n")
+	for _, args := range [][]string{
+		{"-f", composeFile, "p"},
+		{"--expand-features", "-f", composeFile, "p"},
+		{"--output-format", "target-state", "-f", composeFile},
+	} {
+		result := runCLI(t, "", args...)
+		if err := output.Validate(decodeJSON(t, result.stdout)); err != nil {
+			t.Errorf("expected the output of %v to match the output schema, got %v", args, err)
+		}
+	}
+	result = runCLI(t, "", "-f", writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    ports: [\"x\"]\n"), "p")
+	if err := output.Validate(decodeJSON(t, result.stderr)); result.code == 0 || err != nil {
+		t.Errorf("expected the error response to match the output schema, got %d: %v", result.code, err)
+	}
+
+	// The input schema is the compose spec's
+	if err := input.Validate(map[string]any{"services": map[string]any{"web": map[string]any{"image": "nginx"}}}); err != nil {
+		t.Errorf("expected a compose file to match the input schema, got %v", err)
+	}
+	if err := input.Validate(map[string]any{"services": map[string]any{"web": map[string]any{"colour": "blue"}}}); err == nil {
+		t.Error("expected an unknown service field not to match the input schema")
+	}
+}
+
+// decodeJSON decodes JSON output as jsonschema validates it, keeping numbers exact
+func decodeJSON(t *testing.T, content string) any {
+	t.Helper()
+	document, err := jsonschema.UnmarshalJSON(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("expected JSON, got %q: %v", content, err)
+	}
+	return document
+}
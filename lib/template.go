@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Functions available to --format templates, as in docker's --format
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join":  strings.Join,
+	"split": strings.Split,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+	"println": fmt.Sprintln,
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid --format template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// Execute a --format template against the project, with a trailing newline as docker outputs
+func executeTemplate(tmpl *template.Template, project *types.Project) ([]byte, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, project); err != nil {
+		return nil, fmt.Errorf("Failed to execute --format template: %v", err)
+	}
+	if !bytes.HasSuffix(b.Bytes(), []byte("\n")) {
+		b.WriteByte('\n')
+	}
+	return b.Bytes(), nil
+}
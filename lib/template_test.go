@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+func TestExecuteTemplate(t *testing.T) {
+	project := &types.Project{Name: "p", Services: types.Services{
+		"web": {Name: "web", Image: "nginx", Ports: []types.ServicePortConfig{{Target: 80, Published: "8080"}, {Target: 443, Published: "8443"}}},
+		"db":  {Name: "db", Image: "postgres"},
+	}}
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{template: "{{.Name}}", expected: "p\n"},
+		{template: "{{range $name, $s := .Services}}{{$name}} {{range .Ports}}{{.Published}} {{end}}{{println}}{{end}}", expected: "db \nweb 8080 8443 \n"},
+		{template: "{{json .Services.web.Image}}", expected: "\"nginx\"\n"},
+		{template: "{{join (split \"a,b\" \",\") \" \"}} {{upper .Name}} {{lower \"DB\"}} {{title .Services.db.Image}}", expected: "a b P db Postgres\n"},
+		// A trailing newline isn't doubled
+		{template: "{{.Name}}\n", expected: "p\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			tmpl, err := parseTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			output, err := executeTemplate(tmpl, project)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(output) != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, output)
+			}
+		})
+	}
+
+	if _, err := parseTemplate("{{.Name"); err == nil || !strings.HasPrefix(err.Error(), "Invalid --format template") {
+		t.Errorf("expected an invalid template, got %v", err)
+	}
+	tmpl, _ := parseTemplate("{{.Services.missing.Image}}")
+	if _, err := executeTemplate(tmpl, project); err == nil || !strings.HasPrefix(err.Error(), "Failed to execute --format template") {
+		t.Errorf("expected a missing key to fail, got %v", err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n    ports: [\"8080:80\"]\n")
+	result := runCLI(t, "", "--format", "{{range .Services}}{{.Image}} {{(index .Ports 0).Published}}{{end}}", "-f", composeFile, "p")
+	if result.code != 0 || result.stdout != "nginx 8080\n" {
+		t.Errorf("expected the template output, got %d: %q %s", result.code, result.stdout, result.stderr)
+	}
+	runCLI(t, "", "--format", "{{.Name", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Invalid --format template")
+	runCLI(t, "", "--format", "{{.Missing}}", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "Failed to execute --format template")
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/compose-spec/compose-go/v2/schema"
+)
+
+// Parser version, set at build time with -ldflags "-X main.version=<version>"
+var version = "dev"
+
+const composeGoModule = "github.com/compose-spec/compose-go/v2"
+
+// Home page of the parser, the repository URL of package.json
+const repositoryURL = "https://github.com/balena-io-modules/balena-compose-parser"
+
+// VersionInfo is the output of --version
+type VersionInfo struct {
+	Version   string `json:"version"`
+	ComposeGo string `json:"composeGo"`
+	// Digest of the compose-spec JSON schema embedded in compose-go, which the parser validates against.
+	// The schema doesn't carry a version of its own, so the digest identifies the enforced schema.
+	ComposeSpecSchema string `json:"composeSpecSchema"`
+	Commit            string `json:"commit,omitempty"`
+	GoVersion         string `json:"goVersion"`
+}
+
+// Collect version information from the build info embedded by the Go toolchain
+func versionInfo() VersionInfo {
+	schemaDigest := sha256.Sum256([]byte(schema.Schema))
+	info := VersionInfo{
+		Version:           version,
+		ComposeSpecSchema: "sha256:" + hex.EncodeToString(schemaDigest[:]),
+		GoVersion:         runtime.Version(),
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == composeGoModule {
+			info.ComposeGo = dep.Version
+			if dep.Replace != nil {
+				info.ComposeGo = dep.Replace.Version
+			}
+		}
+	}
+	modified := false
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified && info.Commit != "" {
+		info.Commit += "-dirty"
+	}
+	return info
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestVersionInfo(t *testing.T) {
+	info := versionInfo()
+	if info.Version != version || info.GoVersion != runtime.Version() {
+		t.Errorf("expected the version %s of Go %s, got %+v", version, runtime.Version(), info)
+	}
+	if !strings.HasPrefix(info.ComposeGo, "v2.") {
+		t.Errorf("expected the compose-go version of the build, got %q", info.ComposeGo)
+	}
+	if !regexp.MustCompile(`^sha256:[0-9a-f]{64}$`).MatchString(info.ComposeSpecSchema) {
+		t.Errorf("expected the digest of the schema, got %q", info.ComposeSpecSchema)
+	}
+}
+
+func TestVersionFlag(t *testing.T) {
+	result := runCLI(t, "", "--version")
+	if result.code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", result.code, result.stderr)
+	}
+	var info VersionInfo
+	if err := json.Unmarshal([]byte(result.stdout), &info); err != nil {
+		t.Fatalf("expected the version info, got %q", result.stdout)
+	}
+	if info != versionInfo() {
+		t.Errorf("expected %+v, got %+v", versionInfo(), info)
+	}
+}
+
+func TestRepositoryURL(t *testing.T) {
+	packageJSON, err := os.ReadFile("../package.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest struct {
+		Repository struct {
+			URL string `json:"url"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(packageJSON, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if url := strings.TrimSuffix(strings.TrimPrefix(manifest.Repository.URL, "git+"), ".git"); url != repositoryURL {
+		t.Errorf("expected the repository URL of package.json, %s, got %s", url, repositoryURL)
+	}
+}
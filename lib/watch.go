@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Time to wait for changes to settle before re-parsing, as editors typically write a file in several operations
+const watchDebounce = 100 * time.Millisecond
+
+// A line of --watch output, emitted on start and after every change to the watched files
+type watchResult struct {
+	Project       json.RawMessage                    `json:"project,omitempty"`
+	Warnings      []parser.Warning                   `json:"warnings,omitempty"`
+	EnvResolution []parser.VariableResolution        `json:"env_resolution,omitempty"`
+	Overrides     []parser.Override                  `json:"overrides,omitempty"`
+	Features      map[string][]parser.Feature        `json:"features,omitempty"`
+	Contract      *parser.Contract                   `json:"contract,omitempty"`
+	Builds        map[string]parser.Build            `json:"builds,omitempty"`
+	GPU           map[string][]parser.GPURequest     `json:"gpu,omitempty"`
+	Policy        map[string][]parser.PolicyDecision `json:"policy,omitempty"`
+	Defaults      map[string][]parser.DefaultedField `json:"defaults,omitempty"`
+	Error         *parser.ErrorResponse              `json:"error,omitempty"`
+}
+
+// Parse the compose files, then re-parse whenever they or the other files the parse reads change, e.g.
+// env files, writing each result to w as an NDJSON line.
+// Only returns if the files can't be watched.
+func runWatch(p *parser.Parser, composeFiles, files []string, canonical bool, w io.Writer) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// Directories are watched rather than the files themselves, so that files replaced by
+	// editors on save, or removed and later recreated, continue to be watched
+	watched := map[string]bool{}
+	for _, file := range append(slices.Clone(composeFiles), files...) {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+		watched[abs] = true
+	}
+	for path := range watched {
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			return fmt.Errorf("Failed to watch %s: %v", filepath.Dir(path), err)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	parse := func() {
+		var output watchResult
+		result, err := p.Parse(context.Background(), composeFiles)
+		if err == nil {
+			output.Project, err = marshalProject(result.Project, formatJSON, canonical)
+			output.Warnings, output.EnvResolution = result.Warnings, result.EnvResolution
+			output.Features, output.Contract, output.Builds, output.Policy = result.Features, result.Contract, result.Builds, result.Policy
+			output.Overrides, output.GPU, output.Defaults = result.Overrides, result.GPU, result.Defaults
+		}
+		if err != nil {
+			output = watchResult{Error: parser.NewErrorResponse(err)}
+		}
+		encoder.Encode(output)
+	}
+	parse()
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if watched[filepath.Clean(event.Name)] && event.Op != fsnotify.Chmod {
+				debounce.Reset(watchDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case <-debounce.C:
+			parse()
+		}
+	}
+}
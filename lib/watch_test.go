@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"balena-compose-parser/pkg/parser"
+)
+
+// Run runWatch in the background, also watching files, returning a function reading its next result.
+// The watcher runs until the test binary exits, writing to a pipe closed once the test is done.
+func startWatch(t *testing.T, options parser.Options, composeFiles, files []string) func() watchResult {
+	t.Helper()
+	r, w := io.Pipe()
+	t.Cleanup(func() { r.Close() })
+	options.ProjectName = "p"
+	p := parser.New(options)
+	go runWatch(p, composeFiles, files, false, w)
+
+	results := make(chan watchResult)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			var result watchResult
+			json.Unmarshal(scanner.Bytes(), &result)
+			results <- result
+		}
+	}()
+	return func() watchResult {
+		t.Helper()
+		select {
+		case result := <-results:
+			return result
+		case <-time.After(10 * time.Second):
+			t.Fatal("expected the project to be parsed again")
+			return watchResult{}
+		}
+	}
+}
+
+func projectImage(t *testing.T, result watchResult) any {
+	t.Helper()
+	var project map[string]any
+	if err := json.Unmarshal(result.Project, &project); err != nil {
+		t.Fatalf("expected a project, got %+v", result)
+	}
+	return lookup(project, "services.web.image")
+}
+
+func TestRunWatch(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx:${TAG}\n")
+	envFile := writeFile(t, dir, "web.env", "TAG=1.25\n")
+	next := startWatch(t, parser.Options{EnvFiles: []string{envFile}}, []string{composeFile}, []string{envFile})
+
+	if image := projectImage(t, next()); image != "nginx:1.25" {
+		t.Errorf("expected the project to be parsed on start, got %v", image)
+	}
+
+	writeFile(t, dir, "web.env", "TAG=1.26\n")
+	if image := projectImage(t, next()); image != "nginx:1.26" {
+		t.Errorf("expected the project to be parsed again when the env file changes, got %v", image)
+	}
+
+	writeFile(t, dir, "compose.yml", "services: [\n")
+	if result := next(); result.Error == nil || result.Error.Name != parser.ParseError || result.Project != nil {
+		t.Errorf("expected the parse error of the invalid compose file, got %+v", result)
+	}
+
+	// Editors may replace files on save, which continue to be watched
+	if err := os.Remove(composeFile); err != nil {
+		t.Fatal(err)
+	}
+	if result := next(); result.Error == nil || result.Error.Name != parser.IOError {
+		t.Errorf("expected an IOError when the compose file is removed, got %+v", result)
+	}
+	writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: alpine\n")
+	if image := projectImage(t, next()); image != "alpine" {
+		t.Errorf("expected the recreated compose file to be parsed, got %v", image)
+	}
+}
+
+func TestRunWatchReports(t *testing.T) {
+	dir := t.TempDir()
+	composeFile := writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    gpus: all\n")
+	next := startWatch(t, parser.Options{GPU: true}, []string{composeFile}, nil)
+
+	// Every line has the reports of the parse, as the output without --watch does
+	if result := next(); len(result.GPU["web"]) != 1 || result.GPU["web"][0].Count != -1 {
+		t.Errorf("expected the GPU requests, got %+v", result)
+	}
+	writeFile(t, dir, "compose.yml", "services:\n  web:\n    image: nginx\n    gpus: [{count: 2}]\n")
+	if result := next(); len(result.GPU["web"]) != 1 || result.GPU["web"][0].Count != 2 {
+		t.Errorf("expected the GPU requests of the changed compose file, got %+v", result)
+	}
+
+	override := writeFile(t, dir, "compose.override.yml", "services:\n  web:\n    image: nginx:1.25\n")
+	next = startWatch(t, parser.Options{Overrides: true}, []string{composeFile, override}, nil)
+	if result := next(); len(result.Overrides) != 1 || result.Overrides[0].Path != "services.web.image" || result.Overrides[0].Value != "nginx:1.25" {
+		t.Errorf("expected the overridden image, got %+v", result)
+	}
+
+	// The defaults are read on every parse, so are watched like the env files
+	defaults := writeFile(t, dir, "defaults.yml", "restart: always\n")
+	next = startWatch(t, parser.Options{Defaults: defaults}, []string{composeFile}, []string{defaults})
+	if result := next(); len(result.Defaults["web"]) != 1 || result.Defaults["web"][0].Value != "always" {
+		t.Errorf("expected the defaulted restart policy, got %+v", result)
+	}
+	writeFile(t, dir, "defaults.yml", "restart: unless-stopped\n")
+	if result := next(); len(result.Defaults["web"]) != 1 || result.Defaults["web"][0].Value != "unless-stopped" {
+		t.Errorf("expected the project to be parsed again when the defaults change, got %+v", result)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	composeFile := writeFile(t, t.TempDir(), "compose.yml", "services:\n  web:\n    image: nginx\n")
+	runCLI(t, "", "--watch", "-o", "output.json", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--watch only supports local compose files specified with -f")
+	runCLI(t, "", "--watch", "-f", "https://example.com/compose.yml", "p").expectError(t, parser.ArgumentError, "Can't watch https://example.com/compose.yml")
+	runCLI(t, "", "--watch", "--validate", "-f", composeFile, "p").expectError(t, parser.ArgumentError, "--validate can't be used with --batch, --watch")
+}